@@ -6,9 +6,14 @@ require (
 	github.com/edgexfoundry/app-functions-sdk-go/v3 v3.2.0-dev.57
 	github.com/edgexfoundry/go-mod-bootstrap/v3 v3.2.0-dev.66
 	github.com/edgexfoundry/go-mod-core-contracts/v3 v3.2.0-dev.53
+	github.com/edgexfoundry/go-mod-messaging/v3 v3.2.0-dev.40
+	github.com/edgexfoundry/go-mod-registry/v3 v3.2.0-dev.18
+	github.com/fxamacker/cbor/v2 v2.7.0
 	github.com/google/uuid v1.6.0
 	github.com/labstack/echo/v4 v4.12.0
+	github.com/rcrowley/go-metrics v0.0.0-20201227073835-cf1acfcdf475
 	github.com/stretchr/testify v1.9.0
+	github.com/yuin/gopher-lua v1.1.1
 )
 
 require (
@@ -22,13 +27,10 @@ require (
 	github.com/diegoholiveira/jsonlogic/v3 v3.5.3 // indirect
 	github.com/eclipse/paho.mqtt.golang v1.5.0 // indirect
 	github.com/edgexfoundry/go-mod-configuration/v3 v3.2.0-dev.19 // indirect
-	github.com/edgexfoundry/go-mod-messaging/v3 v3.2.0-dev.40 // indirect
-	github.com/edgexfoundry/go-mod-registry/v3 v3.2.0-dev.18 // indirect
 	github.com/edgexfoundry/go-mod-secrets/v3 v3.2.0-dev.18 // indirect
 	github.com/fatih/color v1.16.0 // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/fullsailor/pkcs7 v0.0.0-20190404230743-d7302db945fa // indirect
-	github.com/fxamacker/cbor/v2 v2.7.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.3 // indirect
 	github.com/go-jose/go-jose/v4 v4.0.4 // indirect
 	github.com/go-kit/log v0.2.1 // indirect
@@ -110,7 +112,6 @@ require (
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/power-devops/perfstat v0.0.0-20210106213030-5aafc221ea8c // indirect
-	github.com/rcrowley/go-metrics v0.0.0-20201227073835-cf1acfcdf475 // indirect
 	github.com/shirou/gopsutil/v3 v3.24.5 // indirect
 	github.com/shoenig/go-m1cpu v0.1.6 // indirect
 	github.com/sirupsen/logrus v1.9.3 // indirect
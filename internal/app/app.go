@@ -17,17 +17,26 @@
 package app
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
 	"github.com/edgexfoundry/app-record-replay/internal/application"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
 	"github.com/edgexfoundry/app-record-replay/internal/controller"
+	"github.com/edgexfoundry/app-record-replay/internal/coordinator"
+	appInterfaces "github.com/edgexfoundry/app-record-replay/internal/interfaces"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
 )
 
 const (
 	MaxReplayDelayAppSetting = "MaxReplayDelay"
-	defaultMaxReplayDelay    = time.Minute
+	// VirtualClockAppSetting enables the virtual clock, whose time only moves when advanced via the clock routes.
+	// Intended for deterministic tests and for driving the service from simulation frameworks.
+	VirtualClockAppSetting = "VirtualClock"
+	defaultMaxReplayDelay  = time.Minute
+
+	opaquePublisherCapacity = 100
 )
 
 type recordReplayApp struct {
@@ -68,11 +77,50 @@ func (app *recordReplayApp) CreateAndRunAppService(serviceKey string, newService
 		app.lc.Warnf("%s not set in ApplicationSetting configuration. Using default of %s", MaxReplayDelayAppSetting, defaultMaxReplayDelay.String())
 	}
 
-	if err := controller.New(application.NewManager(app.service, maxReplayDelay), app.service).AddRoutes(); err != nil {
+	timeSource := clock.New()
+	var virtualClock appInterfaces.VirtualClock
+	virtualClockValue := app.service.ApplicationSettings()[VirtualClockAppSetting]
+	if len(virtualClockValue) > 0 {
+		enabled, err := strconv.ParseBool(virtualClockValue)
+		if err != nil {
+			app.lc.Errorf("Invalid %s value: %v", VirtualClockAppSetting, err)
+			return -1
+		}
+
+		if enabled {
+			virtualClock = clock.NewVirtual(time.Now())
+			timeSource = virtualClock
+		}
+	}
+
+	// Background publishers publish the payload as is, which opaque replays need, but must be added before the
+	// service is run. Replaying opaque recordings is unavailable if background publishing isn't supported.
+	opaquePublisher, err := app.service.AddBackgroundPublisherWithTopic(opaquePublisherCapacity, application.OpaqueTopicPlaceholder)
+	if err != nil {
+		app.lc.Warnf("Replay of opaque recordings unavailable: %v", err)
+	}
+
+	// The ApplicationSettings can't be changed at runtime, so those which can be are overridden by the custom
+	// configuration, which is watched for changes.
+	settings := newSettingsOverlay(app.service, app.lc)
+	dataManager := application.NewManager(settings, maxReplayDelay, timeSource, opaquePublisher, app.service.MetricsManager())
+	settings.isIdle = func() bool {
+		return !dataManager.RecordingStatus().InProgress && !dataManager.ReplayStatus().Running
+	}
+	app.watchCustomConfig(settings)
+
+	clusterCoordinator := coordinator.New(settings, serviceKey)
+
+	if err := controller.New(dataManager, clusterCoordinator, virtualClock, settings).AddRoutes(); err != nil {
 		app.lc.Errorf("Adding routes failed: %v", err)
 		return -1
 	}
 
+	if err := dataManager.AddCommandPipeline(); err != nil {
+		app.lc.Errorf("Adding command pipeline failed: %v", err)
+		return -1
+	}
+
 	if err := app.service.Run(); err != nil {
 		app.lc.Errorf("Running app service failed: %v", err)
 		return -1
@@ -80,3 +128,26 @@ func (app *recordReplayApp) CreateAndRunAppService(serviceKey string, newService
 
 	return 0
 }
+
+// watchCustomConfig loads the custom configuration and listens for changes to it. The service runs without runtime
+// overrides if it can't be loaded.
+func (app *recordReplayApp) watchCustomConfig(settings *settingsOverlay) {
+	config := &ServiceConfig{}
+	if err := app.service.LoadCustomConfig(config, AppCustomSection); err != nil {
+		app.lc.Warnf("Failed to load %s configuration, runtime changes of settings unavailable: %v", AppCustomSection, err)
+		return
+	}
+
+	settings.update(config.AppCustom.Settings)
+
+	if err := app.service.ListenForCustomConfigChanges(&config.AppCustom, AppCustomSection, func(rawConfig interface{}) {
+		appCustom, ok := rawConfig.(*AppCustomConfig)
+		if !ok {
+			app.lc.Errorf("Unexpected %s configuration type %T received", AppCustomSection, rawConfig)
+			return
+		}
+		settings.update(appCustom.Settings)
+	}); err != nil {
+		app.lc.Warnf("Failed to listen for %s configuration changes: %v", AppCustomSection, err)
+	}
+}
@@ -23,12 +23,14 @@ import (
 	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
 	loggerMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/application"
 )
 
 // This is an example of how to test the code that would typically be in the main() function use mocks
@@ -42,6 +44,10 @@ func TestCreateAndRunService_Success(t *testing.T) {
 		mockAppService.On("LoggingClient").Return(logger.NewMockClient())
 		mockAppService.Mock.On("ApplicationSettings").Return(map[string]string{MaxReplayDelayAppSetting: "1s"})
 		mockAppService.On("DeviceClient").Return(&clientMocks.DeviceClient{})
+		mockAppService.On("AddBackgroundPublisherWithTopic", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAppService.On("MetricsManager").Return(nil)
+		mockAppService.On("LoadCustomConfig", mock.Anything, AppCustomSection).Return(nil)
+		mockAppService.On("ListenForCustomConfigChanges", mock.Anything, AppCustomSection, mock.Anything).Return(nil)
 		mockAppService.On("AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 		mockAppService.On("Run").Return(nil)
 		return mockAppService, true
@@ -83,6 +89,53 @@ func TestCreateAndRunService_MaxReplayDelayAppSetting_Failed(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+func TestCreateAndRunService_VirtualClock(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Value          string
+		ExpectedResult int
+		ExpectedRoutes int
+	}{
+		{"Enabled", "true", 0, 1},
+		{"Disabled", "false", 0, 0},
+		{"Invalid", "junk", -1, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			app := New()
+
+			mockAppService := &mocks.ApplicationService{}
+			mockFactory := func(_ string) (interfaces.ApplicationService, bool) {
+				mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+				mockAppService.Mock.On("ApplicationSettings").Return(map[string]string{
+					MaxReplayDelayAppSetting: "1s",
+					VirtualClockAppSetting:   test.Value,
+				})
+				mockAppService.On("DeviceClient").Return(&clientMocks.DeviceClient{})
+				mockAppService.On("AddBackgroundPublisherWithTopic", mock.Anything, mock.Anything).Return(nil, nil)
+				mockAppService.On("MetricsManager").Return(nil)
+				mockAppService.On("LoadCustomConfig", mock.Anything, AppCustomSection).Return(nil)
+				mockAppService.On("ListenForCustomConfigChanges", mock.Anything, AppCustomSection, mock.Anything).Return(nil)
+				mockAppService.On("AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				mockAppService.On("Run").Return(nil)
+				return mockAppService, true
+			}
+
+			actual := app.CreateAndRunAppService("TestKey", mockFactory)
+			assert.Equal(t, test.ExpectedResult, actual)
+
+			clockRoutes := 0
+			for _, call := range mockAppService.Calls {
+				if call.Method == "AddCustomRoute" && call.Arguments.String(0) == common.ApiBase+"/clock" {
+					clockRoutes++
+				}
+			}
+			assert.Equal(t, test.ExpectedRoutes, clockRoutes)
+		})
+	}
+}
+
 func TestCreateAndRunService_DeviceClient_Failed(t *testing.T) {
 	app := New()
 
@@ -116,6 +169,10 @@ func TestCreateAndRunService_Run_Failed(t *testing.T) {
 		mockAppService.On("LoggingClient").Return(logger.NewMockClient())
 		mockAppService.Mock.On("ApplicationSettings").Return(map[string]string{MaxReplayDelayAppSetting: "1s"})
 		mockAppService.On("DeviceClient").Return(&clientMocks.DeviceClient{})
+		mockAppService.On("AddBackgroundPublisherWithTopic", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAppService.On("MetricsManager").Return(nil)
+		mockAppService.On("LoadCustomConfig", mock.Anything, AppCustomSection).Return(nil)
+		mockAppService.On("ListenForCustomConfigChanges", mock.Anything, AppCustomSection, mock.Anything).Return(nil)
 		mockAppService.On("AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 		mockAppService.On("Run").Return(fmt.Errorf("failed")).Run(func(args mock.Arguments) {
 			RunCalled = true
@@ -129,3 +186,28 @@ func TestCreateAndRunService_Run_Failed(t *testing.T) {
 	require.True(t, RunCalled, "Run never called")
 	assert.Equal(t, expected, actual)
 }
+
+func TestCreateAndRunService_CommandPipeline_Failed(t *testing.T) {
+	app := New()
+
+	mockFactory := func(_ string) (interfaces.ApplicationService, bool) {
+		mockAppService := &mocks.ApplicationService{}
+		mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+		mockAppService.Mock.On("ApplicationSettings").Return(map[string]string{
+			MaxReplayDelayAppSetting:           "1s",
+			application.CommandTopicAppSetting: "arr/#",
+		})
+		mockAppService.On("DeviceClient").Return(&clientMocks.DeviceClient{})
+		mockAppService.On("AddBackgroundPublisherWithTopic", mock.Anything, mock.Anything).Return(nil, nil)
+		mockAppService.On("MetricsManager").Return(nil)
+		mockAppService.On("LoadCustomConfig", mock.Anything, AppCustomSection).Return(nil)
+		mockAppService.On("ListenForCustomConfigChanges", mock.Anything, AppCustomSection, mock.Anything).Return(nil)
+		mockAppService.On("AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		return mockAppService, true
+	}
+
+	expected := -1
+	actual := app.CreateAndRunAppService("TestKey", mockFactory)
+	assert.Equal(t, expected, actual)
+}
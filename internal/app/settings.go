@@ -0,0 +1,190 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/internal/application"
+	"github.com/edgexfoundry/app-record-replay/internal/controller"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+)
+
+const (
+	// AppCustomSection is the custom configuration section holding the settings which can be changed at runtime
+	AppCustomSection = "AppCustom"
+
+	defaultReloadPollInterval = time.Second
+)
+
+// reloadableSettings are the ApplicationSettings which may be overridden at runtime. The others are only read when
+// the service starts, so changing them requires a restart.
+var reloadableSettings = map[string]bool{
+	application.SegmentStoreDirAppSetting:          true,
+	controller.ImportPathsAppSetting:               true,
+	controller.ExportPathsAppSetting:               true,
+	controller.ImportMaxEventsAppSetting:           true,
+	controller.ImportMaxBytesAppSetting:            true,
+	controller.ImportMaxRequestBytesAppSetting:     true,
+	controller.ImportMaxCompressionRatioAppSetting: true,
+	application.ImportBatchSizeAppSetting:          true,
+	application.ImportConcurrencyAppSetting:        true,
+	application.MaxConcurrentSessionsAppSetting:    true,
+	application.MaxQueuedSessionsAppSetting:        true,
+	application.SessionIdleTimeoutAppSetting:       true,
+	application.CloudSyncChunkSizeAppSetting:       true,
+	application.ReplayPublishWorkersAppSetting:     true,
+	application.ReplaySourcesAppSetting:            true,
+	application.ReplayValidationPolicyAppSetting:   true,
+	application.ReplayProfileDriftPolicyAppSetting: true,
+	application.RecordingNameTemplateAppSetting:    true,
+	application.SourceClockOffsetsAppSetting:       true,
+	controller.AnonymizeMaxTimeShiftAppSetting:     true,
+	controller.AnonymizeTagPatternsAppSetting:      true,
+}
+
+// ServiceConfig is the service's custom configuration
+type ServiceConfig struct {
+	AppCustom AppCustomConfig
+}
+
+// AppCustomConfig holds overrides of the ApplicationSettings, which are watched for changes when the Configuration
+// Provider is used
+type AppCustomConfig struct {
+	Settings map[string]string
+}
+
+// UpdateFromRaw updates the service's custom configuration from the raw configuration received from the
+// Configuration Provider
+func (c *ServiceConfig) UpdateFromRaw(rawConfig interface{}) bool {
+	configuration, ok := rawConfig.(*ServiceConfig)
+	if !ok {
+		return false
+	}
+
+	*c = *configuration
+	return true
+}
+
+// settingsOverlay wraps the application service so the ApplicationSettings it returns include the overrides from
+// the custom configuration. Changes received while a recording or replay is in progress are held until it ends,
+// so a session never sees its settings change part way through.
+type settingsOverlay struct {
+	interfaces.ApplicationService
+	lc           logger.LoggingClient
+	isIdle       func() bool
+	pollInterval time.Duration
+
+	mutex     sync.RWMutex
+	overrides map[string]string
+	pending   map[string]string
+	waiting   bool
+}
+
+func newSettingsOverlay(service interfaces.ApplicationService, lc logger.LoggingClient) *settingsOverlay {
+	return &settingsOverlay{
+		ApplicationService: service,
+		lc:                 lc,
+		isIdle:             func() bool { return true },
+		pollInterval:       defaultReloadPollInterval,
+	}
+}
+
+// ApplicationSettings returns the service's ApplicationSettings with the overrides applied
+func (s *settingsOverlay) ApplicationSettings() map[string]string {
+	settings := s.ApplicationService.ApplicationSettings()
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if len(s.overrides) == 0 {
+		return settings
+	}
+
+	merged := make(map[string]string, len(settings)+len(s.overrides))
+	for key, value := range settings {
+		merged[key] = value
+	}
+	for key, value := range s.overrides {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// update sets the overrides, dropping those of settings which can't be changed at runtime. The overrides are
+// applied immediately when idle, otherwise once the recording or replay in progress has ended.
+func (s *settingsOverlay) update(settings map[string]string) {
+	overrides := make(map[string]string, len(settings))
+	for key, value := range settings {
+		if !reloadableSettings[key] {
+			s.lc.Warnf("%s can't be changed at runtime and is ignored. Set it in ApplicationSettings and restart the service", key)
+			continue
+		}
+		overrides[key] = value
+	}
+
+	idle := s.isIdle()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if idle {
+		s.apply(overrides)
+		s.pending = nil
+		return
+	}
+
+	s.pending = overrides
+	if s.waiting {
+		return
+	}
+
+	s.waiting = true
+	s.lc.Infof("%s changes will be applied once the session in progress ends", AppCustomSection)
+	go s.applyWhenIdle()
+}
+
+// applyWhenIdle applies the pending overrides once no recording or replay is in progress
+func (s *settingsOverlay) applyWhenIdle() {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !s.isIdle() {
+			continue
+		}
+
+		s.mutex.Lock()
+		// Changes received when idle while waiting have already been applied
+		if s.pending != nil {
+			s.apply(s.pending)
+			s.pending = nil
+		}
+		s.waiting = false
+		s.mutex.Unlock()
+		return
+	}
+}
+
+// apply must be called with the mutex locked
+func (s *settingsOverlay) apply(overrides map[string]string) {
+	s.overrides = overrides
+	s.lc.Infof("%s applied with %d setting overrides", AppCustomSection, len(overrides))
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package app
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-record-replay/internal/application"
+	"github.com/edgexfoundry/app-record-replay/internal/controller"
+)
+
+func newTestSettingsOverlay() *settingsOverlay {
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("ApplicationSettings").Return(map[string]string{
+		MaxReplayDelayAppSetting:              "1s",
+		application.SegmentStoreDirAppSetting: "/data/segments",
+	})
+
+	settings := newSettingsOverlay(mockAppService, logger.NewMockClient())
+	settings.pollInterval = time.Millisecond
+	return settings
+}
+
+func TestSettingsOverlay_Update(t *testing.T) {
+	settings := newTestSettingsOverlay()
+
+	settings.update(map[string]string{
+		application.SegmentStoreDirAppSetting: "/data/other",
+		controller.ImportMaxEventsAppSetting:  "10",
+		MaxReplayDelayAppSetting:              "1h",
+	})
+
+	actual := settings.ApplicationSettings()
+	assert.Equal(t, "/data/other", actual[application.SegmentStoreDirAppSetting])
+	assert.Equal(t, "10", actual[controller.ImportMaxEventsAppSetting])
+	assert.Equal(t, "1s", actual[MaxReplayDelayAppSetting], "non reloadable setting must not be overridden")
+
+	settings.update(nil)
+	actual = settings.ApplicationSettings()
+	assert.Equal(t, "/data/segments", actual[application.SegmentStoreDirAppSetting])
+	assert.NotContains(t, actual, controller.ImportMaxEventsAppSetting)
+}
+
+func TestSettingsOverlay_Update_SessionInProgress(t *testing.T) {
+	settings := newTestSettingsOverlay()
+
+	var busy atomic.Bool
+	busy.Store(true)
+	settings.isIdle = func() bool { return !busy.Load() }
+
+	settings.update(map[string]string{application.SegmentStoreDirAppSetting: "/data/first"})
+	settings.update(map[string]string{application.SegmentStoreDirAppSetting: "/data/second"})
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, "/data/segments", settings.ApplicationSettings()[application.SegmentStoreDirAppSetting])
+
+	busy.Store(false)
+	require.Eventually(t, func() bool {
+		return settings.ApplicationSettings()[application.SegmentStoreDirAppSetting] == "/data/second"
+	}, time.Second, time.Millisecond)
+}
+
+func TestCreateAndRunService_CustomConfigChanges(t *testing.T) {
+	app := New()
+
+	var changedCallback func(interface{})
+	mockAppService := &mocks.ApplicationService{}
+	mockAppService.On("LoggingClient").Return(logger.NewMockClient())
+	mockAppService.On("ApplicationSettings").Return(map[string]string{MaxReplayDelayAppSetting: "1s"})
+	mockAppService.On("LoadCustomConfig", mock.Anything, AppCustomSection).Return(nil).Run(func(args mock.Arguments) {
+		config := args.Get(0).(*ServiceConfig)
+		config.AppCustom.Settings = map[string]string{controller.ExportPathsAppSetting: "/export"}
+	})
+	mockAppService.On("ListenForCustomConfigChanges", mock.Anything, AppCustomSection, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		changedCallback = args.Get(2).(func(interface{}))
+	})
+	app.service = mockAppService
+	app.lc = logger.NewMockClient()
+
+	settings := newSettingsOverlay(mockAppService, app.lc)
+	app.watchCustomConfig(settings)
+	assert.Equal(t, "/export", settings.ApplicationSettings()[controller.ExportPathsAppSetting])
+
+	require.NotNil(t, changedCallback)
+	changedCallback(&AppCustomConfig{Settings: map[string]string{controller.ExportPathsAppSetting: "/other"}})
+	assert.Equal(t, "/other", settings.ApplicationSettings()[controller.ExportPathsAppSetting])
+}
@@ -0,0 +1,213 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+var (
+	invalidAnnotationLabelError = errors.New("annotation label must be set")
+	invalidAnnotationRangeError = errors.New("annotation end must not be before its timestamp")
+	startAnnotationOptionsError = errors.New("StartAnnotation can't be used with TimeWarpDuration or AlignTimeOfDay")
+)
+
+// AddAnnotation adds the annotation to the recording in progress or, if none, the recorded data. Annotations without
+// a timestamp mark the current time. An error is returned if the label isn't set, the end is before the timestamp,
+// there is no recording or the recorded data is locked.
+func (m *dataManager) AddAnnotation(annotation dtos.Annotation) (*dtos.Annotation, error) {
+	if len(annotation.Label) == 0 {
+		return nil, invalidAnnotationLabelError
+	}
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if annotation.Timestamp == 0 {
+		annotation.Timestamp = m.clock.Now().UnixNano()
+	}
+
+	if annotation.End != 0 && annotation.End < annotation.Timestamp {
+		return nil, invalidAnnotationRangeError
+	}
+
+	lc := m.appSvc.LoggingClient()
+
+	if m.recordingStartedAt != nil {
+		m.recordedAnnotations = append(m.recordedAnnotations, annotation)
+		lc.Debugf("ARR Annotate: Annotation '%s' added to the recording in progress", annotation.Label)
+		return &annotation, nil
+	}
+
+	if m.recordedData == nil {
+		return nil, noRecordedData
+	}
+
+	if m.recordedDataLocked {
+		return nil, recordedDataLockedError
+	}
+
+	// The annotations are copied rather than appended in place, since the recorded data may be in use outside the lock
+	annotations := append([]dtos.Annotation(nil), m.recordedData.Annotations...)
+	m.recordedData.Annotations = append(annotations, annotation)
+
+	lc.Debugf("ARR Annotate: Annotation '%s' added to the recorded data", annotation.Label)
+
+	return &annotation, nil
+}
+
+// SearchAnnotations returns the annotations with labels matching the pattern, which uses the path.Match syntax,
+// in the recording in progress, the recorded data and the recordings in the segment store. An empty pattern matches
+// all the annotations. An error is returned if the pattern is malformed or the segment store can't be read.
+func (m *dataManager) SearchAnnotations(pattern string) (*dtos.AnnotationSearchResult, error) {
+	if len(pattern) == 0 {
+		pattern = "*"
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid label pattern '%s': %w", pattern, err)
+	}
+
+	result := &dtos.AnnotationSearchResult{Matches: []dtos.AnnotationMatch{}}
+	addMatches := func(annotations []dtos.Annotation, match dtos.AnnotationMatch) {
+		for _, annotation := range annotations {
+			if matched, _ := path.Match(pattern, annotation.Label); matched {
+				match.Annotation = annotation
+				result.Matches = append(result.Matches, match)
+			}
+		}
+	}
+
+	m.recordingMutex.Lock()
+	if m.recordingStartedAt != nil {
+		addMatches(m.recordedAnnotations, dtos.AnnotationMatch{RecordingName: m.recordingName, InProgress: true})
+	}
+	if m.recordedData != nil {
+		addMatches(m.recordedData.Annotations, dtos.AnnotationMatch{RecordingName: m.recordedData.Name})
+	}
+	m.recordingMutex.Unlock()
+
+	// The segments are read without holding the lock, since they are only ever replaced whole by a rename
+	storeDir := m.appSvc.ApplicationSettings()[SegmentStoreDirAppSetting]
+	if len(storeDir) == 0 {
+		return result, nil
+	}
+
+	segmentPaths, err := filepath.Glob(filepath.Join(storeDir, "*", "*"+segmentFileExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, segmentPath := range segmentPaths {
+		annotations, name, err := readSegmentAnnotations(segmentPath)
+		if errors.Is(err, os.ErrNotExist) {
+			// Deleted by the retention limits since the glob
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		addMatches(annotations, dtos.AnnotationMatch{RecordingName: name, SegmentPath: segmentPath})
+	}
+
+	return result, nil
+}
+
+// readSegmentAnnotations returns the annotations and recording name of the segment
+func readSegmentAnnotations(segmentPath string) ([]dtos.Annotation, string, error) {
+	data, err := os.ReadFile(segmentPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Only the annotations and name are decoded, the rest of the segment is skipped
+	segment := struct {
+		Name        string            `json:"name"`
+		Annotations []dtos.Annotation `json:"annotations"`
+	}{}
+	if err := json.Unmarshal(data, &segment); err != nil {
+		return nil, "", fmt.Errorf("unable to read segment %s: %v", segmentPath, err)
+	}
+
+	return segment.Annotations, segment.Name, nil
+}
+
+// annotationStartIndex returns the index of the first Event at or after the first annotation with the request's
+// StartAnnotation label, or 0 if not set. The Events are timed by their envelopes when the request uses envelope
+// timing. An error is returned if there is no such annotation or no Events after it.
+func annotationStartIndex(request dtos.ReplayRequest, data *recordedData) (int, error) {
+	if len(request.StartAnnotation) == 0 {
+		return 0, nil
+	}
+
+	if request.TimeWarpDuration > 0 || request.AlignTimeOfDay {
+		return 0, startAnnotationOptionsError
+	}
+
+	return annotationEventIndex(data, request.StartAnnotation, request.UseEnvelopeTiming)
+}
+
+// annotationEventIndex returns the index of the first Event at or after the first annotation with the label.
+// An error is returned if there is no such annotation or no Events after it.
+func annotationEventIndex(data *recordedData, label string, useEnvelopeTiming bool) (int, error) {
+	for _, annotation := range data.Annotations {
+		if annotation.Label != label {
+			continue
+		}
+
+		index, ok := eventIndexAt(data, annotation.Timestamp, useEnvelopeTiming)
+		if !ok {
+			return 0, fmt.Errorf("no recorded events after annotation '%s'", label)
+		}
+
+		return index, nil
+	}
+
+	return 0, fmt.Errorf("annotation '%s' not found in the recorded data", label)
+}
+
+// eventIndexAt returns the index of the first Event with an event time at or after the time, or false if there is
+// none. The Events are timed by their envelopes when using envelope timing.
+func eventIndexAt(data *recordedData, eventTime int64, useEnvelopeTiming bool) (int, bool) {
+	for index := range data.Events.len() {
+		if recordedEventTime(data, index, useEnvelopeTiming) >= eventTime {
+			return index, true
+		}
+	}
+
+	return 0, false
+}
+
+// recordedEventTime returns the time of the Event at the index, which is its envelope's received time when using
+// envelope timing and the Event has an envelope, otherwise its Origin
+func recordedEventTime(data *recordedData, index int, useEnvelopeTiming bool) int64 {
+	if useEnvelopeTiming {
+		if envelope, ok := data.Envelopes[data.Events.id(index)]; ok {
+			return envelope.ReceivedAt
+		}
+	}
+
+	return data.Events.origins[index]
+}
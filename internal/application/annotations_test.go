@@ -0,0 +1,233 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	interfaceMocks "github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_AddAnnotation(t *testing.T) {
+	now := time.Now()
+	mockClock := &interfaceMocks.Clock{}
+	mockClock.On("Now").Return(now)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, mockClock, nil, nil).(*dataManager)
+
+	_, err := target.AddAnnotation(dtos.Annotation{})
+	require.Equal(t, invalidAnnotationLabelError, err)
+
+	_, err = target.AddAnnotation(dtos.Annotation{Label: "valve-opened"})
+	require.Equal(t, noRecordedData, err)
+
+	// Annotations without a timestamp mark the current time
+	target.recordingStartedAt = &now
+	annotation, err := target.AddAnnotation(dtos.Annotation{Label: "valve-opened", Note: "manual"})
+	require.NoError(t, err)
+	assert.Equal(t, &dtos.Annotation{Label: "valve-opened", Timestamp: now.UnixNano(), Note: "manual"}, annotation)
+	assert.Equal(t, []dtos.Annotation{*annotation}, target.recordedAnnotations)
+
+	target.recordingStartedAt = nil
+	existing := []dtos.Annotation{{Label: "start", Timestamp: 1}}
+	target.recordedData = &recordedData{Annotations: existing}
+	annotation, err = target.AddAnnotation(dtos.Annotation{Label: "valve-closed", Timestamp: 2000})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), annotation.Timestamp)
+	assert.Equal(t, []dtos.Annotation{existing[0], *annotation}, target.recordedData.Annotations)
+	assert.Len(t, existing, 1)
+
+	// Annotations with an end label the time range
+	_, err = target.AddAnnotation(dtos.Annotation{Label: "fault", Timestamp: 3000, End: 2999})
+	require.Equal(t, invalidAnnotationRangeError, err)
+	annotation, err = target.AddAnnotation(dtos.Annotation{Label: "fault", Timestamp: 3000, End: 4000})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4000), annotation.End)
+	assert.Len(t, target.recordedData.Annotations, 3)
+
+	target.recordedDataLocked = true
+	_, err = target.AddAnnotation(dtos.Annotation{Label: "locked"})
+	require.Equal(t, recordedDataLockedError, err)
+	assert.Len(t, target.recordedData.Annotations, 3)
+}
+
+func TestDataManager_SearchAnnotations(t *testing.T) {
+	storeDir := t.TempDir()
+	segmentDir := filepath.Join(storeDir, "line-1")
+	require.NoError(t, os.MkdirAll(segmentDir, 0750))
+
+	segment, err := json.Marshal(dtos.RecordedData{
+		Name:        "line-1",
+		Annotations: []dtos.Annotation{{Label: "valve-opened", Timestamp: 10}, {Label: "pump-started", Timestamp: 20}},
+	})
+	require.NoError(t, err)
+	segmentPath := filepath.Join(segmentDir, "20240101T000000.000000000Z"+segmentFileExtension)
+	require.NoError(t, os.WriteFile(segmentPath, segment, 0640))
+	require.NoError(t, os.WriteFile(filepath.Join(segmentDir, "partial"+segmentFileExtension+segmentTempFileExtension), []byte("{"), 0640))
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{SegmentStoreDirAppSetting: storeDir})
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	now := time.Now()
+	target.recordingStartedAt = &now
+	target.recordingName = "line-2"
+	target.recordedAnnotations = []dtos.Annotation{{Label: "valve-closed", Timestamp: 30}}
+	target.recordedData = &recordedData{Name: "bench", Annotations: []dtos.Annotation{{Label: "valve-opened", Timestamp: 40}}}
+
+	result, err := target.SearchAnnotations("valve-*")
+	require.NoError(t, err)
+	assert.Equal(t, []dtos.AnnotationMatch{
+		{Annotation: dtos.Annotation{Label: "valve-closed", Timestamp: 30}, RecordingName: "line-2", InProgress: true},
+		{Annotation: dtos.Annotation{Label: "valve-opened", Timestamp: 40}, RecordingName: "bench"},
+		{Annotation: dtos.Annotation{Label: "valve-opened", Timestamp: 10}, RecordingName: "line-1", SegmentPath: segmentPath},
+	}, result.Matches)
+
+	result, err = target.SearchAnnotations("")
+	require.NoError(t, err)
+	assert.Len(t, result.Matches, 4)
+
+	result, err = target.SearchAnnotations("missing")
+	require.NoError(t, err)
+	assert.Empty(t, result.Matches)
+
+	_, err = target.SearchAnnotations("[")
+	require.Error(t, err)
+}
+
+func TestAnnotationStartIndex(t *testing.T) {
+	events := []coreDtos.Event{newAppendEvent("D1", 10*time.Second), newAppendEvent("D1", 20*time.Second),
+		newAppendEvent("D1", 30*time.Second)}
+	data := &recordedData{
+		Events:      newEventStore(events),
+		Envelopes:   map[string]dtos.EnvelopeMetadata{events[1].Id: {ReceivedAt: int64(12 * time.Second)}},
+		Annotations: []dtos.Annotation{{Label: "fault", Timestamp: int64(15 * time.Second)}, {Label: "fault", Timestamp: int64(25 * time.Second)}, {Label: "end", Timestamp: int64(40 * time.Second)}},
+	}
+
+	tests := []struct {
+		Name          string
+		Request       dtos.ReplayRequest
+		ExpectedIndex int
+		ExpectError   bool
+	}{
+		{"Not set", dtos.ReplayRequest{}, 0, false},
+		{"First matching annotation", dtos.ReplayRequest{StartAnnotation: "fault"}, 1, false},
+		{"Envelope timing", dtos.ReplayRequest{StartAnnotation: "fault", UseEnvelopeTiming: true}, 2, false},
+		{"Not found", dtos.ReplayRequest{StartAnnotation: "missing"}, 0, true},
+		{"No events after", dtos.ReplayRequest{StartAnnotation: "end"}, 0, true},
+		{"Time warp", dtos.ReplayRequest{StartAnnotation: "fault", TimeWarpDuration: time.Hour}, 0, true},
+		{"Align time of day", dtos.ReplayRequest{StartAnnotation: "fault", AlignTimeOfDay: true}, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			index, err := annotationStartIndex(test.Request, data)
+			if test.ExpectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedIndex, index)
+		})
+	}
+}
+
+func TestDataManager_StartReplay_StartAnnotation(t *testing.T) {
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+	var published []string
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).Return(nil).
+		Run(func(args mock.Arguments) {
+			published = append(published, args.String(0))
+		})
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	events := []coreDtos.Event{newAppendEvent("D1", time.Millisecond), newAppendEvent("D1", 2*time.Millisecond),
+		newAppendEvent("D1", 3*time.Millisecond)}
+	target.recordedData = &recordedData{
+		Events:      newEventStore(events),
+		Annotations: []dtos.Annotation{{Label: "valve-opened", Timestamp: int64(2 * time.Millisecond)}},
+	}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, RepeatCount: 2, StartAnnotation: "missing"})
+	require.Error(t, err)
+	assert.Nil(t, target.replayStartedAt)
+
+	err = target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, RepeatCount: 2, StartAnnotation: "valve-opened"})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	assert.Empty(t, status.Message)
+	assert.Equal(t, 4, status.EventCount)
+	assert.Len(t, published, 4)
+}
+
+func TestDataManager_ProcessBatchedData_Annotations(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+	mockSdk.On("RemoveAllFunctionPipelines")
+	mockSdk.On("NotificationClient").Return(nil)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	now := time.Now()
+	target.recordingStartedAt = &now
+
+	_, err := target.AddAnnotation(dtos.Annotation{Label: "valve-opened"})
+	require.NoError(t, err)
+
+	continuePipeline, result := target.processBatchedData(nil, []coreDtos.Event{newAppendEvent("D1", time.Second)})
+	require.False(t, continuePipeline)
+	require.Nil(t, result)
+
+	require.NotNil(t, target.recordedData)
+	require.Len(t, target.recordedData.Annotations, 1)
+	assert.Equal(t, "valve-opened", target.recordedData.Annotations[0].Label)
+	assert.Nil(t, target.recordedAnnotations)
+}
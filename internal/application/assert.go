@@ -0,0 +1,193 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+var noAssertionsError = errors.New("no assertions specified")
+
+// AssertRecordedData checks the assertions against the recorded data in the request or, if not set,
+// the last recorded or imported data. An error is returned if there is no data to check.
+func (m *dataManager) AssertRecordedData(request dtos.AssertRequest) (*dtos.AssertResponse, error) {
+	if len(request.Assertions) == 0 {
+		return nil, noAssertionsError
+	}
+
+	var events *eventStore
+	if request.Data != nil {
+		events = newEventStore(request.Data.RecordedEvents)
+	} else {
+		m.recordingMutex.Lock()
+		if m.recordedData != nil {
+			events = m.recordedData.Events
+		}
+		m.recordingMutex.Unlock()
+	}
+
+	if events.len() == 0 {
+		return nil, noRecordedData
+	}
+
+	response := &dtos.AssertResponse{
+		Passed:  true,
+		Results: make([]dtos.AssertionResult, 0, len(request.Assertions)),
+	}
+
+	for _, assertion := range request.Assertions {
+		result := dtos.AssertionResult{Assertion: assertion}
+
+		var err error
+		switch assertion.Type {
+		case dtos.AssertMinValue:
+			err = assertValues(events, assertion, func(value float64) bool { return value >= assertion.Value })
+		case dtos.AssertMaxValue:
+			err = assertValues(events, assertion, func(value float64) bool { return value <= assertion.Value })
+		case dtos.AssertExpectedDevices:
+			err = assertExpectedDevices(events, assertion)
+		case dtos.AssertEventCount:
+			err = assertEventCount(events, assertion)
+		case dtos.AssertMaxGap:
+			err = assertMaxGap(events, assertion)
+		default:
+			err = fmt.Errorf("unknown assertion type '%s'", assertion.Type)
+		}
+
+		result.Passed = err == nil
+		if err != nil {
+			result.Message = err.Error()
+			response.Passed = false
+		}
+
+		response.Results = append(response.Results, result)
+	}
+
+	m.appSvc.LoggingClient().Debugf("ARR Assert: %d assertions checked against %d events, passed=%v",
+		len(request.Assertions), events.len(), response.Passed)
+
+	return response, nil
+}
+
+func assertValues(events *eventStore, assertion dtos.Assertion, withinBound func(float64) bool) error {
+	checked := 0
+	var err error
+	events.eachReadingValue(assertion.DeviceName, assertion.ResourceName, func(deviceName string, resourceName string, value string) bool {
+		number, parseErr := strconv.ParseFloat(value, 64)
+		if parseErr != nil {
+			return true
+		}
+
+		checked++
+		if !withinBound(number) {
+			err = fmt.Errorf("reading for %s/%s has value %v which exceeds %s of %v",
+				deviceName, resourceName, number, assertion.Type, assertion.Value)
+			return false
+		}
+
+		return true
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if checked == 0 {
+		return errors.New("no numeric readings match the assertion")
+	}
+
+	return nil
+}
+
+func assertExpectedDevices(events *eventStore, assertion dtos.Assertion) error {
+	if len(assertion.Devices) == 0 {
+		return errors.New("no devices specified")
+	}
+
+	recorded := make(map[string]bool)
+	for _, name := range events.uniqueDeviceNames() {
+		recorded[name] = true
+	}
+
+	var missing []string
+	for _, name := range assertion.Devices {
+		if !recorded[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("no events recorded for devices: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func assertEventCount(events *eventStore, assertion dtos.Assertion) error {
+	count := len(eventOrigins(events, assertion.DeviceName))
+
+	if count < assertion.MinCount {
+		return fmt.Errorf("event count %d is less than minimum of %d", count, assertion.MinCount)
+	}
+
+	if assertion.MaxCount > 0 && count > assertion.MaxCount {
+		return fmt.Errorf("event count %d is greater than maximum of %d", count, assertion.MaxCount)
+	}
+
+	return nil
+}
+
+func assertMaxGap(events *eventStore, assertion dtos.Assertion) error {
+	if assertion.MaxGap <= 0 {
+		return errors.New("maxGap must be greater than 0")
+	}
+
+	origins := eventOrigins(events, assertion.DeviceName)
+	sort.Slice(origins, func(i, j int) bool { return origins[i] < origins[j] })
+
+	for i := 1; i < len(origins); i++ {
+		gap := time.Duration(origins[i] - origins[i-1])
+		if gap > assertion.MaxGap {
+			return fmt.Errorf("gap of %s between events exceeds maximum of %s", gap.String(), assertion.MaxGap.String())
+		}
+	}
+
+	return nil
+}
+
+// eventOrigins returns a copy of the origins of the Events for the device, or of all Events if not set
+func eventOrigins(events *eventStore, deviceName string) []int64 {
+	if len(deviceName) == 0 {
+		return append([]int64(nil), events.origins...)
+	}
+
+	var origins []int64
+	for index, name := range events.deviceNames {
+		if name == deviceName {
+			origins = append(origins, events.origins[index])
+		}
+	}
+
+	return origins
+}
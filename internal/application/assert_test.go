@@ -0,0 +1,108 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_AssertRecordedData(t *testing.T) {
+	newEvent := func(deviceName string, origin time.Duration, value string) coreDtos.Event {
+		event := coreDtos.NewEvent(expectedProfileName, deviceName, expectedSourceName)
+		_ = event.AddSimpleReading("Temperature", common.ValueTypeString, value)
+		event.Origin = int64(origin)
+		return event
+	}
+
+	events := []coreDtos.Event{
+		newEvent("D1", 0, "10"),
+		newEvent("D1", time.Second, "20"),
+		newEvent("D2", 3*time.Second, "50"),
+		newEvent("D1", 4*time.Second, "30"),
+	}
+
+	tests := []struct {
+		Name            string
+		Assertion       dtos.Assertion
+		ExpectedPassed  bool
+		ExpectedMessage string
+	}{
+		{"minValue pass", dtos.Assertion{Type: dtos.AssertMinValue, Value: 10}, true, ""},
+		{"minValue fail", dtos.Assertion{Type: dtos.AssertMinValue, Value: 15}, false, "exceeds minValue"},
+		{"minValue no match", dtos.Assertion{Type: dtos.AssertMinValue, ResourceName: "Humidity"}, false, "no numeric readings"},
+		{"maxValue pass with device", dtos.Assertion{Type: dtos.AssertMaxValue, DeviceName: "D1", Value: 30}, true, ""},
+		{"maxValue fail", dtos.Assertion{Type: dtos.AssertMaxValue, Value: 30}, false, "exceeds maxValue"},
+		{"expectedDevices pass", dtos.Assertion{Type: dtos.AssertExpectedDevices, Devices: []string{"D1", "D2"}}, true, ""},
+		{"expectedDevices fail", dtos.Assertion{Type: dtos.AssertExpectedDevices, Devices: []string{"D1", "D3"}}, false, "D3"},
+		{"eventCount pass", dtos.Assertion{Type: dtos.AssertEventCount, MinCount: 4, MaxCount: 4}, true, ""},
+		{"eventCount fail min", dtos.Assertion{Type: dtos.AssertEventCount, DeviceName: "D2", MinCount: 2}, false, "less than minimum"},
+		{"eventCount fail max", dtos.Assertion{Type: dtos.AssertEventCount, MaxCount: 3}, false, "greater than maximum"},
+		{"maxGap pass", dtos.Assertion{Type: dtos.AssertMaxGap, MaxGap: 2 * time.Second}, true, ""},
+		{"maxGap fail", dtos.Assertion{Type: dtos.AssertMaxGap, DeviceName: "D1", MaxGap: 2 * time.Second}, false, "exceeds maximum"},
+		{"maxGap not set", dtos.Assertion{Type: dtos.AssertMaxGap}, false, "must be greater than 0"},
+		{"unknown type", dtos.Assertion{Type: "bogus"}, false, "unknown assertion type"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+			target.recordedData = &recordedData{Events: newEventStore(events)}
+
+			response, err := target.AssertRecordedData(dtos.AssertRequest{Assertions: []dtos.Assertion{test.Assertion}})
+			require.NoError(t, err)
+			require.Len(t, response.Results, 1)
+			assert.Equal(t, test.ExpectedPassed, response.Passed)
+			assert.Equal(t, test.ExpectedPassed, response.Results[0].Passed)
+			assert.Contains(t, response.Results[0].Message, test.ExpectedMessage)
+		})
+	}
+}
+
+func TestDataManager_AssertRecordedData_Errors(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	assertions := []dtos.Assertion{{Type: dtos.AssertEventCount, MinCount: 1}}
+
+	_, err := target.AssertRecordedData(dtos.AssertRequest{})
+	require.Equal(t, noAssertionsError, err)
+
+	_, err = target.AssertRecordedData(dtos.AssertRequest{Assertions: assertions})
+	require.Equal(t, noRecordedData, err)
+
+	// Uploaded data is used in place of the recorded data
+	response, err := target.AssertRecordedData(dtos.AssertRequest{
+		Data:       &dtos.RecordedData{RecordedEvents: expectedEventData},
+		Assertions: assertions,
+	})
+	require.NoError(t, err)
+	assert.True(t, response.Passed)
+}
@@ -0,0 +1,171 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+var (
+	restoreNoSegmentStoreError = fmt.Errorf("the backup's recordings can't be restored since the %s App Setting isn't set", SegmentStoreDirAppSetting)
+	invalidBackupEntryError    = errors.New("invalid recording or segment name in backup")
+)
+
+// Backup returns the content of the recording store, i.e. the recorded data held in memory and every named
+// recording in the segment store, so it can be restored on another instance. Partially written segments aren't
+// included. An error is returned if a recording is in progress, since its data isn't complete.
+func (m *dataManager) Backup() (*dtos.StoreBackup, error) {
+	m.recordingMutex.Lock()
+	if m.recordingStartedAt != nil {
+		m.recordingMutex.Unlock()
+		return nil, recordingInProgressError
+	}
+
+	current, err := m.exportRecordedData()
+	m.recordingMutex.Unlock()
+	if err != nil && !errors.Is(err, noRecordedData) && !errors.Is(err, noEventsRecorded) {
+		return nil, err
+	}
+
+	backup := &dtos.StoreBackup{CreatedAt: m.clock.Now().UnixNano(), Current: current}
+
+	storeDir := m.appSvc.ApplicationSettings()[SegmentStoreDirAppSetting]
+	if len(storeDir) == 0 {
+		return backup, nil
+	}
+
+	recordingDirs, err := os.ReadDir(storeDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return backup, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The segments are listed without holding the lock, since they are only ever replaced whole by a rename. Their
+	// files are copied into the backup as it is written, rather than read into memory.
+	for _, recordingDir := range recordingDirs {
+		if !recordingDir.IsDir() {
+			continue
+		}
+
+		recording := dtos.RecordingBackup{Name: recordingDir.Name()}
+		paths, err := segmentPaths(filepath.Join(storeDir, recording.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, segmentPath := range paths {
+			recording.Segments = append(recording.Segments, dtos.SegmentBackup{FileName: filepath.Base(segmentPath), Path: segmentPath})
+		}
+
+		if len(recording.Segments) > 0 {
+			backup.Recordings = append(backup.Recordings, recording)
+		}
+	}
+
+	m.appSvc.LoggingClient().Debugf("ARR Backup: Backed up %d recordings from the segment store", len(backup.Recordings))
+
+	return backup, nil
+}
+
+// Restore writes the backup's recordings into the segment store, replacing any segments of the same name, and
+// imports its recorded data in place of the recorded data, the same as ImportRecordedData. An error is returned if
+// a record or replay session is in progress, the backup has recordings but the segment store isn't configured, or
+// any of its recording or segment names are invalid, in which case nothing is restored.
+func (m *dataManager) Restore(backup *dtos.StoreBackup, overwrite bool) (*dtos.RestoreResult, error) {
+	m.recordingMutex.Lock()
+	switch {
+	case m.recordingStartedAt != nil:
+		m.recordingMutex.Unlock()
+		return nil, recordingInProgressError
+	case m.replayStartedAt != nil:
+		m.recordingMutex.Unlock()
+		return nil, replayInProgressError
+	case backup.Current != nil && m.recordedDataLocked:
+		m.recordingMutex.Unlock()
+		return nil, recordedDataLockedError
+	}
+	m.recordingMutex.Unlock()
+
+	storeDir := m.appSvc.ApplicationSettings()[SegmentStoreDirAppSetting]
+	if len(backup.Recordings) > 0 && len(storeDir) == 0 {
+		return nil, restoreNoSegmentStoreError
+	}
+
+	for _, recording := range backup.Recordings {
+		if !isStoreEntryName(recording.Name) {
+			return nil, fmt.Errorf("%w: recording '%s'", invalidBackupEntryError, recording.Name)
+		}
+		for _, segment := range recording.Segments {
+			if !isStoreEntryName(segment.FileName) || !strings.HasSuffix(segment.FileName, segmentFileExtension) {
+				return nil, fmt.Errorf("%w: segment '%s' of recording '%s'", invalidBackupEntryError, segment.FileName, recording.Name)
+			}
+		}
+	}
+
+	result := &dtos.RestoreResult{}
+	for _, recording := range backup.Recordings {
+		dir := filepath.Join(storeDir, recording.Name)
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return nil, fmt.Errorf("failed to create recording directory %s: %v", dir, err)
+		}
+
+		// Each segment is written to a temporary file first, the same as when rotated, so partially written
+		// segments are never seen
+		for _, segment := range recording.Segments {
+			path := filepath.Join(dir, segment.FileName)
+			tempPath := path + segmentTempFileExtension
+			if err := os.WriteFile(tempPath, segment.Data, 0640); err != nil {
+				return nil, fmt.Errorf("failed to write segment %s: %v", path, err)
+			}
+
+			if err := os.Rename(tempPath, path); err != nil {
+				_ = os.Remove(tempPath)
+				return nil, fmt.Errorf("failed to write segment %s: %v", path, err)
+			}
+			result.SegmentCount++
+		}
+		result.RecordingCount++
+	}
+
+	m.appSvc.LoggingClient().Debugf("ARR Restore: Restored %d segments of %d recordings into the segment store",
+		result.SegmentCount, result.RecordingCount)
+
+	if backup.Current == nil {
+		return result, nil
+	}
+
+	// The recorded data is imported even if some of its Device Profiles and Devices fail to be provisioned, which are
+	// reported in the result
+	err := m.ImportRecordedData(backup.Current, overwrite, false)
+	var provisioning *provisioningError
+	if errors.As(err, &provisioning) {
+		result.ProvisioningFailures = provisioning.ProvisioningFailures()
+	} else if err != nil {
+		return nil, err
+	}
+	result.CurrentRestored = true
+
+	return result, nil
+}
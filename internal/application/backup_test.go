@@ -0,0 +1,138 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createBackupTarget(storeDir string) *dataManager {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{SegmentStoreDirAppSetting: storeDir})
+
+	return NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+}
+
+func TestDataManager_BackupAndRestore(t *testing.T) {
+	storeDir := t.TempDir()
+	recordingDir := filepath.Join(storeDir, "line-1")
+	require.NoError(t, os.MkdirAll(recordingDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(recordingDir, "20240301T120000.000000000Z.json"), []byte(`{"name":"line-1"}`), 0640))
+	// Partially written segments and recordings without segments aren't backed up
+	require.NoError(t, os.WriteFile(filepath.Join(recordingDir, "20240301T130000.000000000Z.json.tmp"), []byte("{"), 0640))
+	require.NoError(t, os.MkdirAll(filepath.Join(storeDir, "line-2"), 0750))
+	// Glob metacharacters in a recording's name don't change which segments are backed up
+	require.NoError(t, os.MkdirAll(filepath.Join(storeDir, "line[3]"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(storeDir, "line[3]", "20240301T140000.000000000Z.json"), []byte(`{"name":"line[3]"}`), 0640))
+
+	source := createBackupTarget(storeDir)
+	source.recordedData = &recordedData{Name: "opaque", Messages: []dtos.OpaqueMessage{{Payload: []byte("payload")}}}
+
+	backup, err := source.Backup()
+	require.NoError(t, err)
+	require.NotNil(t, backup.Current)
+	assert.Equal(t, "opaque", backup.Current.Name)
+	// The segments are listed by path, to be copied into the backup as it is written
+	assert.Equal(t, []dtos.RecordingBackup{
+		{
+			Name:     "line-1",
+			Segments: []dtos.SegmentBackup{{FileName: "20240301T120000.000000000Z.json", Path: filepath.Join(recordingDir, "20240301T120000.000000000Z.json")}},
+		},
+		{
+			Name:     "line[3]",
+			Segments: []dtos.SegmentBackup{{FileName: "20240301T140000.000000000Z.json", Path: filepath.Join(storeDir, "line[3]", "20240301T140000.000000000Z.json")}},
+		},
+	}, backup.Recordings)
+
+	// Restoring reads the segments from the backup archive, which holds their data
+	for i, recording := range backup.Recordings {
+		for j, segment := range recording.Segments {
+			data, err := os.ReadFile(segment.Path)
+			require.NoError(t, err)
+			backup.Recordings[i].Segments[j] = dtos.SegmentBackup{FileName: segment.FileName, Data: data}
+		}
+	}
+
+	restoreDir := filepath.Join(t.TempDir(), "store")
+	target := createBackupTarget(restoreDir)
+
+	result, err := target.Restore(backup, true)
+	require.NoError(t, err)
+	assert.Equal(t, &dtos.RestoreResult{CurrentRestored: true, RecordingCount: 2, SegmentCount: 2}, result)
+
+	data, err := os.ReadFile(filepath.Join(restoreDir, "line-1", "20240301T120000.000000000Z.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"line-1"}`, string(data))
+	require.NotNil(t, target.recordedData)
+	assert.Equal(t, "opaque", target.recordedData.Name)
+	assert.Len(t, target.recordedData.Messages, 1)
+}
+
+func TestDataManager_Backup_RecordingInProgress(t *testing.T) {
+	target := createBackupTarget(t.TempDir())
+	now := time.Now()
+	target.recordingStartedAt = &now
+
+	_, err := target.Backup()
+	require.ErrorIs(t, err, recordingInProgressError)
+}
+
+func TestDataManager_Restore_Errors(t *testing.T) {
+	segment := dtos.SegmentBackup{FileName: "20240301T120000.000000000Z.json", Data: []byte("{}")}
+
+	tests := []struct {
+		Name          string
+		StoreDir      string
+		Recording     dtos.RecordingBackup
+		ExpectedError error
+	}{
+		{"Segment store not configured", "", dtos.RecordingBackup{Name: "line-1", Segments: []dtos.SegmentBackup{segment}}, restoreNoSegmentStoreError},
+		{"Recording outside store", "store", dtos.RecordingBackup{Name: "..", Segments: []dtos.SegmentBackup{segment}}, invalidBackupEntryError},
+		{"Segment outside recording", "store", dtos.RecordingBackup{Name: "line-1", Segments: []dtos.SegmentBackup{{FileName: "../line-2.json"}}}, invalidBackupEntryError},
+		{"Segment not JSON", "store", dtos.RecordingBackup{Name: "line-1", Segments: []dtos.SegmentBackup{{FileName: "run.sh"}}}, invalidBackupEntryError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			storeDir := test.StoreDir
+			if len(storeDir) > 0 {
+				storeDir = filepath.Join(t.TempDir(), storeDir)
+			}
+			target := createBackupTarget(storeDir)
+
+			_, err := target.Restore(&dtos.StoreBackup{Recordings: []dtos.RecordingBackup{test.Recording}}, true)
+			require.ErrorIs(t, err, test.ExpectedError)
+
+			// Nothing is restored when any of the backup is invalid
+			if len(storeDir) > 0 {
+				_, err = os.Stat(storeDir)
+				assert.ErrorIs(t, err, os.ErrNotExist)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+var (
+	invalidBreakpointError = errors.New("each breakpoint must set at least one of DeviceName, ResourceName, Operator or Offset, Offset must be greater than or equal 0 and Operator must be one of ==, !=, >, >=, < or <=")
+	breakpointOptionsError = errors.New("Breakpoints can't be used with TimeWarpDuration, AlignTimeOfDay or Telemetry")
+	noReplayPausedError    = errors.New("no replay currently paused")
+)
+
+// validateBreakpoints checks the replay request's breakpoints. Only replays paced from Event to Event can pause,
+// since the others time the Events against fixed windows.
+func validateBreakpoints(request dtos.ReplayRequest) error {
+	if len(request.Breakpoints) == 0 {
+		return nil
+	}
+
+	if request.TimeWarpDuration > 0 || request.AlignTimeOfDay || request.Telemetry {
+		return breakpointOptionsError
+	}
+
+	for _, breakpoint := range request.Breakpoints {
+		if breakpoint.Offset < 0 || (len(breakpoint.DeviceName) == 0 && len(breakpoint.ResourceName) == 0 &&
+			len(breakpoint.Operator) == 0 && breakpoint.Offset == 0) {
+			return invalidBreakpointError
+		}
+
+		switch breakpoint.Operator {
+		case "", dtos.ReplayBreakpointEqual, dtos.ReplayBreakpointNotEqual, dtos.ReplayBreakpointGreater,
+			dtos.ReplayBreakpointGreaterOrEqual, dtos.ReplayBreakpointLess, dtos.ReplayBreakpointLessOrEqual:
+		default:
+			return invalidBreakpointError
+		}
+	}
+
+	return nil
+}
+
+// replayBreakpoints matches the replayed Events against the breakpoints of a replay session
+type replayBreakpoints struct {
+	breakpoints       []dtos.ReplayBreakpoint
+	firstEventTime    int64
+	startEventTime    int64
+	previousEventTime int64
+	// hasPrevious is false after a seek, so an offset isn't reached by jumping past it
+	hasPrevious bool
+}
+
+// newReplayBreakpoints returns the breakpoints of the replay, or nil if it has none
+func newReplayBreakpoints(request dtos.ReplayRequest, data *recordedData, startIndex int) *replayBreakpoints {
+	if len(request.Breakpoints) == 0 || data.Events.len() == 0 {
+		return nil
+	}
+
+	b := &replayBreakpoints{
+		breakpoints:    request.Breakpoints,
+		firstEventTime: recordedEventTime(data, 0, request.UseEnvelopeTiming),
+		startEventTime: recordedEventTime(data, startIndex, request.UseEnvelopeTiming),
+	}
+	b.restart()
+
+	return b
+}
+
+// restart re-arms the offset breakpoints for the next iteration of the replay
+func (b *replayBreakpoints) restart() {
+	b.previousEventTime = b.startEventTime - 1
+	b.hasPrevious = true
+}
+
+// jumped re-arms the offset breakpoints after the replay seeks, from the Event jumped to
+func (b *replayBreakpoints) jumped() {
+	b.hasPrevious = false
+}
+
+// match returns the index of the first breakpoint the Event to be published at the time given matches, or false if
+// none match
+func (b *replayBreakpoints) match(event coreDtos.Event, eventTime int64) (int, bool) {
+	previousEventTime, hasPrevious := b.previousEventTime, b.hasPrevious
+	b.previousEventTime, b.hasPrevious = eventTime, true
+
+	for i, breakpoint := range b.breakpoints {
+		offsetTime := b.firstEventTime + int64(breakpoint.Offset)
+		if eventTime < offsetTime {
+			continue
+		}
+
+		if len(breakpoint.DeviceName) == 0 && len(breakpoint.ResourceName) == 0 && len(breakpoint.Operator) == 0 {
+			if hasPrevious && previousEventTime < offsetTime {
+				return i, true
+			}
+			continue
+		}
+
+		if breakpointMatches(breakpoint, event) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// breakpointMatches checks the Event against the breakpoint's device, resource and value conditions
+func breakpointMatches(breakpoint dtos.ReplayBreakpoint, event coreDtos.Event) bool {
+	if len(breakpoint.DeviceName) > 0 && event.DeviceName != breakpoint.DeviceName {
+		return false
+	}
+
+	if len(breakpoint.ResourceName) == 0 && len(breakpoint.Operator) == 0 {
+		return true
+	}
+
+	for _, reading := range event.Readings {
+		if len(breakpoint.ResourceName) > 0 && reading.ResourceName != breakpoint.ResourceName {
+			continue
+		}
+
+		if len(breakpoint.Operator) == 0 || compareBreakpointValue(breakpoint.Operator, reading.Value, breakpoint.Value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compareBreakpointValue compares the Reading's value with the breakpoint's value by the operator, as numbers when
+// both are numeric and otherwise as strings, for which only the equality operators match
+func compareBreakpointValue(operator string, value string, target string) bool {
+	number, valueErr := strconv.ParseFloat(value, 64)
+	targetNumber, targetErr := strconv.ParseFloat(target, 64)
+	if valueErr != nil || targetErr != nil {
+		switch operator {
+		case dtos.ReplayBreakpointEqual:
+			return value == target
+		case dtos.ReplayBreakpointNotEqual:
+			return value != target
+		default:
+			return false
+		}
+	}
+
+	switch operator {
+	case dtos.ReplayBreakpointEqual:
+		return number == targetNumber
+	case dtos.ReplayBreakpointNotEqual:
+		return number != targetNumber
+	case dtos.ReplayBreakpointGreater:
+		return number > targetNumber
+	case dtos.ReplayBreakpointGreaterOrEqual:
+		return number >= targetNumber
+	case dtos.ReplayBreakpointLess:
+		return number < targetNumber
+	case dtos.ReplayBreakpointLessOrEqual:
+		return number <= targetNumber
+	default:
+		return false
+	}
+}
+
+// replayPause is a replay session waiting to publish its next Event until it is resumed or stepped
+type replayPause struct {
+	status dtos.ReplayPauseStatus
+	// step receives true to publish the Event and pause again before the next, or false to resume the replay
+	step chan bool
+}
+
+// pauseReplay holds the replay goroutine until the replay is resumed, stepped or stopped, returning true if the
+// replay pauses again before the next Event
+func (m *dataManager) pauseReplay(status dtos.ReplayPauseStatus, lc logger.LoggingClient) bool {
+	pause := &replayPause{status: status, step: make(chan bool, 1)}
+
+	m.recordingMutex.Lock()
+	m.replayPause = pause
+	replayContext := m.replayContext
+	m.recordingMutex.Unlock()
+
+	if status.Stepped {
+		lc.Infof("ARR Replay: Replay paused after step before event %d for device %s", status.EventIndex, status.DeviceName)
+	} else {
+		lc.Infof("ARR Replay: Replay paused at breakpoint %d before event %d for device %s", status.Breakpoint,
+			status.EventIndex, status.DeviceName)
+	}
+
+	select {
+	case step := <-pause.step:
+		return step
+	case <-replayContext.Done():
+	case <-m.appSvc.AppContext().Done():
+	}
+
+	m.recordingMutex.Lock()
+	if m.replayPause == pause {
+		m.replayPause = nil
+	}
+	m.recordingMutex.Unlock()
+
+	return false
+}
+
+// ResumeReplay continues the paused replay session until the next Event matching a breakpoint. An error is returned
+// if no replay is paused.
+func (m *dataManager) ResumeReplay() error {
+	return m.continueReplay(false)
+}
+
+// StepReplay publishes the Event the replay session is paused before and pauses again before the next Event.
+// An error is returned if no replay is paused.
+func (m *dataManager) StepReplay() error {
+	return m.continueReplay(true)
+}
+
+func (m *dataManager) continueReplay(step bool) error {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.replayPause == nil {
+		return noReplayPausedError
+	}
+
+	m.replayPause.step <- step
+	m.replayPause = nil
+
+	if step {
+		m.sessionLogger(m.replayLabel, m.replayCorrelationID).Debug("ARR Replay: Paused replay stepped")
+	} else {
+		m.sessionLogger(m.replayLabel, m.replayCorrelationID).Debug("ARR Replay: Paused replay resumed")
+	}
+
+	return nil
+}
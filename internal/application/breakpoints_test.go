@@ -0,0 +1,209 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	interfaceMocks "github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBreakpoints(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Request       dtos.ReplayRequest
+		ExpectedError error
+	}{
+		{"No breakpoints", dtos.ReplayRequest{TimeWarpDuration: time.Hour}, nil},
+		{"Valid", dtos.ReplayRequest{Breakpoints: []dtos.ReplayBreakpoint{{DeviceName: "D1"},
+			{ResourceName: "R1", Operator: dtos.ReplayBreakpointGreater, Value: "5"}, {Offset: time.Minute}}}, nil},
+		{"Empty", dtos.ReplayRequest{Breakpoints: []dtos.ReplayBreakpoint{{Value: "5"}}}, invalidBreakpointError},
+		{"Negative offset", dtos.ReplayRequest{Breakpoints: []dtos.ReplayBreakpoint{{DeviceName: "D1", Offset: -1}}}, invalidBreakpointError},
+		{"Unknown operator", dtos.ReplayRequest{Breakpoints: []dtos.ReplayBreakpoint{{Operator: "=~"}}}, invalidBreakpointError},
+		{"Time warp", dtos.ReplayRequest{TimeWarpDuration: time.Hour, Breakpoints: []dtos.ReplayBreakpoint{{DeviceName: "D1"}}}, breakpointOptionsError},
+		{"Daily", dtos.ReplayRequest{AlignTimeOfDay: true, Breakpoints: []dtos.ReplayBreakpoint{{DeviceName: "D1"}}}, breakpointOptionsError},
+		{"Telemetry", dtos.ReplayRequest{Telemetry: true, Breakpoints: []dtos.ReplayBreakpoint{{DeviceName: "D1"}}}, breakpointOptionsError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedError, validateBreakpoints(test.Request))
+		})
+	}
+}
+
+func TestCompareBreakpointValue(t *testing.T) {
+	tests := []struct {
+		Operator string
+		Value    string
+		Target   string
+		Expected bool
+	}{
+		{dtos.ReplayBreakpointEqual, "5.0", "5", true},
+		{dtos.ReplayBreakpointNotEqual, "5.0", "5", false},
+		{dtos.ReplayBreakpointGreater, "10", "9.5", true},
+		{dtos.ReplayBreakpointGreater, "9.5", "9.5", false},
+		{dtos.ReplayBreakpointGreaterOrEqual, "9.5", "9.5", true},
+		{dtos.ReplayBreakpointLess, "-1", "0", true},
+		{dtos.ReplayBreakpointLessOrEqual, "1", "0", false},
+		{dtos.ReplayBreakpointEqual, "open", "open", true},
+		{dtos.ReplayBreakpointNotEqual, "open", "closed", true},
+		{dtos.ReplayBreakpointGreater, "open", "closed", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Value+test.Operator+test.Target, func(t *testing.T) {
+			assert.Equal(t, test.Expected, compareBreakpointValue(test.Operator, test.Value, test.Target))
+		})
+	}
+}
+
+func newBreakpointEvent(deviceName string, origin time.Duration, value string) coreDtos.Event {
+	event := newAppendEvent(deviceName, origin)
+	event.Readings[0].Value = value
+	return event
+}
+
+func TestReplayBreakpoints_Match(t *testing.T) {
+	events := []coreDtos.Event{newBreakpointEvent("D1", 10*time.Second, "1"), newBreakpointEvent("D2", 20*time.Second, "7"),
+		newBreakpointEvent("D1", 30*time.Second, "9"), newBreakpointEvent("D1", 40*time.Second, "2")}
+	data := &recordedData{Events: newEventStore(events)}
+
+	request := dtos.ReplayRequest{Breakpoints: []dtos.ReplayBreakpoint{
+		{DeviceName: "D1", ResourceName: expectedSourceName, Operator: dtos.ReplayBreakpointGreater, Value: "5"},
+		{Offset: 15 * time.Second},
+		{DeviceName: "D2"},
+	}}
+
+	assert.Nil(t, newReplayBreakpoints(dtos.ReplayRequest{}, data, 0))
+
+	target := newReplayBreakpoints(request, data, 0)
+	require.NotNil(t, target)
+
+	matchAll := func() []int {
+		var matches []int
+		for _, event := range events {
+			breakpoint, ok := target.match(event, event.Origin)
+			if !ok {
+				breakpoint = -1
+			}
+			matches = append(matches, breakpoint)
+		}
+		return matches
+	}
+
+	// The offset breakpoint, 15s from the first Event, is reached by the Event at 30s, which also matches the value
+	// breakpoint listed first
+	assert.Equal(t, []int{-1, 2, 0, -1}, matchAll())
+
+	// Each iteration re-arms the offset breakpoint
+	request.Breakpoints[0].Value = "10"
+	target.restart()
+	assert.Equal(t, []int{-1, 2, 1, -1}, matchAll())
+
+	// Jumping past the offset doesn't reach it
+	target.jumped()
+	_, ok := target.match(events[3], events[3].Origin)
+	assert.False(t, ok)
+}
+
+func TestDataManager_StartReplay_Breakpoints(t *testing.T) {
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+	var publishedMutex sync.Mutex
+	var published []string
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).Return(nil).
+		Run(func(args mock.Arguments) {
+			publishedMutex.Lock()
+			defer publishedMutex.Unlock()
+			published = append(published, args.Get(1).(requests.AddEventRequest).Event.DeviceName)
+		})
+
+	publishedDevices := func() []string {
+		publishedMutex.Lock()
+		defer publishedMutex.Unlock()
+		return append([]string(nil), published...)
+	}
+
+	mockClock := &interfaceMocks.Clock{}
+	mockClock.On("Now").Return(time.Now())
+	mockClock.On("Since", mock.Anything).Return(time.Second).Maybe()
+	mockClock.On("Sleep", mock.Anything)
+
+	target := NewManager(mockSdk, time.Minute, mockClock, nil, nil).(*dataManager)
+	target.recordedData = &recordedData{Events: newEventStore([]coreDtos.Event{
+		newAppendEvent("D1", 0), newAppendEvent("D2", time.Second), newAppendEvent("D3", 2*time.Second),
+		newAppendEvent("D4", 3*time.Second),
+	})}
+
+	require.Equal(t, noReplayPausedError, target.ResumeReplay())
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, RepeatCount: 2,
+		Breakpoints: []dtos.ReplayBreakpoint{{DeviceName: "D2"}}})
+	require.NoError(t, err)
+
+	waitForPause := func(expected dtos.ReplayPauseStatus) {
+		require.Eventually(t, func() bool {
+			status := target.ReplayStatus()
+			return status.Paused != nil && *status.Paused == expected
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	waitForPause(dtos.ReplayPauseStatus{EventIndex: 1, DeviceName: "D2"})
+	assert.Equal(t, []string{"D1"}, publishedDevices())
+	assert.True(t, target.ReplayStatus().Running)
+
+	// A step publishes the Event paused before and pauses before the next
+	require.NoError(t, target.StepReplay())
+	waitForPause(dtos.ReplayPauseStatus{EventIndex: 2, DeviceName: "D3", Stepped: true})
+	assert.Equal(t, []string{"D1", "D2"}, publishedDevices())
+
+	// Resuming continues to the breakpoint in the next iteration
+	require.NoError(t, target.ResumeReplay())
+	waitForPause(dtos.ReplayPauseStatus{EventIndex: 1, DeviceName: "D2"})
+	assert.Equal(t, []string{"D1", "D2", "D3", "D4", "D1"}, publishedDevices())
+
+	// Canceling a paused replay stops it
+	require.NoError(t, target.CancelReplay())
+	require.Eventually(t, func() bool {
+		status := target.ReplayStatus()
+		return !status.Running && status.Paused == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"D1", "D2", "D3", "D4", "D1"}, publishedDevices())
+	assert.Equal(t, noReplayPausedError, target.StepReplay())
+}
@@ -0,0 +1,180 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+)
+
+// BusProbeIntervalAppSetting is the interval at which the MessageBus connection is probed during a recording, by
+// publishing a probe message to the BusProbeTopic. A failed probe marks the start of a gap in the recording, which
+// ends at the next successful probe. Disabled when not set.
+const BusProbeIntervalAppSetting = "BusProbeInterval"
+
+// BusProbeTopic is the topic, relative to the base topic, the MessageBus probe messages are published to. It must
+// not match the SubscribeTopics, otherwise the probes are received by the recording.
+const BusProbeTopic = "arr/probe"
+
+// busProbe is the message published to probe the MessageBus connection
+type busProbe struct {
+	ProbedAt int64 `json:"probedAt"`
+}
+
+// busWatch tracks the intervals the MessageBus is disconnected during a recording. The recording's pipeline is kept
+// through a disconnect and the MessageBus client resubscribes on reconnect, so the recording resumes by itself.
+type busWatch struct {
+	lc    logger.LoggingClient
+	mutex sync.Mutex
+	gaps  []dtos.RecordingGap
+}
+
+// probe publishes a probe message, opening a gap if it fails while connected and closing the open gap if it
+// succeeds while disconnected
+func (w *busWatch) probe(appSvc appInterfaces.ApplicationService, clock interfaces.Clock) {
+	now := clock.Now().UnixNano()
+	err := appSvc.PublishWithTopic(BusProbeTopic, busProbe{ProbedAt: now}, common.ContentTypeJSON)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	disconnected := len(w.gaps) > 0 && w.gaps[len(w.gaps)-1].End == 0
+
+	switch {
+	case err != nil && !disconnected:
+		w.gaps = append(w.gaps, dtos.RecordingGap{Start: now})
+		w.lc.Warnf("ARR Bus Watch: MessageBus disconnected, recording gap started: %v", err)
+	case err == nil && disconnected:
+		gap := &w.gaps[len(w.gaps)-1]
+		gap.End = now
+		w.lc.Infof("ARR Bus Watch: MessageBus reconnected, recording resumed after gap of %s",
+			time.Duration(gap.End-gap.Start).String())
+	}
+}
+
+// snapshot returns a copy of the gaps so far
+func (w *busWatch) snapshot() []dtos.RecordingGap {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.gaps) == 0 {
+		return nil
+	}
+
+	return append([]dtos.RecordingGap(nil), w.gaps...)
+}
+
+// getBusProbeInterval returns the configured MessageBus probe interval. Zero is returned if probing is disabled.
+func (m *dataManager) getBusProbeInterval() (time.Duration, error) {
+	value := m.appSvc.ApplicationSettings()[BusProbeIntervalAppSetting]
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid %s value '%s', must be a duration greater than 0", BusProbeIntervalAppSetting, value)
+	}
+
+	return interval, nil
+}
+
+// startBusWatch starts periodically probing the MessageBus connection for the current recording.
+// Must be called while holding the recording mutex.
+func (m *dataManager) startBusWatch(interval time.Duration) {
+	lc := m.sessionLogger(m.recordingLabel, m.recordingCorrelationID)
+	watch := &busWatch{lc: lc}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.busWatch = watch
+	m.busWatchCancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				watch.probe(m.appSvc, m.clock)
+			}
+		}
+	}()
+
+	lc.Debugf("ARR Bus Watch: started with interval of %s", interval.String())
+}
+
+// stopBusWatch stops the MessageBus watch, if running, and returns the gaps found. A gap still open is closed at the
+// time the watch is stopped. Must be called while holding the recording mutex.
+func (m *dataManager) stopBusWatch() []dtos.RecordingGap {
+	watch := m.busWatch
+	if m.busWatchCancel != nil {
+		m.busWatchCancel()
+	}
+
+	m.busWatch = nil
+	m.busWatchCancel = nil
+
+	if watch == nil {
+		return nil
+	}
+
+	gaps := watch.snapshot()
+	if len(gaps) > 0 && gaps[len(gaps)-1].End == 0 {
+		gaps[len(gaps)-1].End = m.clock.Now().UnixNano()
+	}
+
+	return gaps
+}
+
+// recordingGaps returns the gaps of the recording in progress or, if none, the last recorded or imported data.
+// Must be called while holding the recording mutex.
+func (m *dataManager) recordingGaps() []dtos.RecordingGap {
+	if m.recordingStartedAt != nil {
+		if m.busWatch == nil {
+			return nil
+		}
+		return m.busWatch.snapshot()
+	}
+
+	if m.recordedData != nil && m.recordedData.Metadata != nil {
+		return m.recordedData.Metadata.Gaps
+	}
+
+	return nil
+}
+
+// withGaps returns the recording metadata with the gaps added. A copy is returned since the metadata may be shared
+// with a status or metadata response.
+func withGaps(metadata *dtos.RecordingMetadata, gaps []dtos.RecordingGap) *dtos.RecordingMetadata {
+	if metadata == nil || len(gaps) == 0 {
+		return metadata
+	}
+
+	stamped := *metadata
+	stamped.Gaps = gaps
+	return &stamped
+}
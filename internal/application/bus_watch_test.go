@@ -0,0 +1,104 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusWatch_Probe(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("PublishWithTopic", BusProbeTopic, mock.Anything, common.ContentTypeJSON).Return(nil).Once()
+	mockSdk.On("PublishWithTopic", BusProbeTopic, mock.Anything, common.ContentTypeJSON).Return(errors.New("not connected")).Twice()
+	mockSdk.On("PublishWithTopic", BusProbeTopic, mock.Anything, common.ContentTypeJSON).Return(nil).Once()
+	mockSdk.On("PublishWithTopic", BusProbeTopic, mock.Anything, common.ContentTypeJSON).Return(errors.New("not connected")).Once()
+
+	watch := &busWatch{lc: logger.NewMockClient()}
+	virtualClock := clock.NewVirtual(time.Unix(0, 0))
+
+	// Connected, so no gap
+	watch.probe(mockSdk, virtualClock)
+	assert.Empty(t, watch.snapshot())
+
+	// Disconnected, with the gap started by the first failed probe
+	virtualClock.Advance(time.Second)
+	watch.probe(mockSdk, virtualClock)
+	virtualClock.Advance(time.Second)
+	watch.probe(mockSdk, virtualClock)
+	require.Equal(t, []dtos.RecordingGap{{Start: time.Second.Nanoseconds()}}, watch.snapshot())
+
+	// Reconnected
+	virtualClock.Advance(time.Second)
+	watch.probe(mockSdk, virtualClock)
+	require.Equal(t, []dtos.RecordingGap{{Start: time.Second.Nanoseconds(), End: 3 * time.Second.Nanoseconds()}}, watch.snapshot())
+
+	// Disconnected again
+	virtualClock.Advance(time.Second)
+	watch.probe(mockSdk, virtualClock)
+	require.Len(t, watch.snapshot(), 2)
+	assert.Zero(t, watch.snapshot()[1].End)
+
+	mockSdk.AssertExpectations(t)
+}
+
+func TestDataManager_BusWatch(t *testing.T) {
+	virtualClock := clock.NewVirtual(time.Unix(0, 0))
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("PublishWithTopic", BusProbeTopic, mock.Anything, common.ContentTypeJSON).Return(errors.New("not connected"))
+
+	target := NewManager(mockSdk, 0, virtualClock, nil, nil).(*dataManager)
+	now := virtualClock.Now()
+	target.recordingStartedAt = &now
+	target.recordingMetadata = &dtos.RecordingMetadata{Hostname: "edge-node-1"}
+
+	target.startBusWatch(time.Hour)
+	target.busWatch.probe(mockSdk, virtualClock)
+
+	actual := target.RecordingStatus()
+	require.Len(t, actual.Gaps, 1)
+	assert.Zero(t, actual.Gaps[0].End)
+
+	metadata, err := target.RecordingMetadata()
+	require.NoError(t, err)
+	require.Len(t, metadata.Gaps, 1)
+	assert.Nil(t, target.recordingMetadata.Gaps)
+
+	// The open gap is closed when the watch is stopped
+	virtualClock.Advance(time.Minute)
+	target.recordingMutex.Lock()
+	gaps := target.stopBusWatch()
+	target.recordingMutex.Unlock()
+	require.Len(t, gaps, 1)
+	assert.Equal(t, time.Minute.Nanoseconds(), gaps[0].End)
+	assert.Nil(t, target.busWatch)
+
+	stamped := withGaps(target.recordingMetadata, gaps)
+	assert.Equal(t, "edge-node-1", stamped.Hostname)
+	assert.Equal(t, gaps, stamped.Gaps)
+}
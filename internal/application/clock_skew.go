@@ -0,0 +1,99 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+// SourceClockOffsetsAppSetting is the comma separated list of clock offsets of the Device Services recorded from, each
+// as <service name>=<duration>, e.g. "device-modbus=-1.5s, device-onvif=250ms". The offset is added to the Origins of
+// the Events recorded from the service, so Events from services whose clocks are skewed are ordered correctly.
+const SourceClockOffsetsAppSetting = "SourceClockOffsets"
+
+// getSourceClockOffsets returns the clock offsets keyed by Device Service name, or nil if none are configured
+func (m *dataManager) getSourceClockOffsets() (map[string]time.Duration, error) {
+	value := m.appSvc.ApplicationSettings()[SourceClockOffsetsAppSetting]
+	if len(strings.TrimSpace(value)) == 0 {
+		return nil, nil
+	}
+
+	offsets := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		serviceName, setting, found := strings.Cut(strings.TrimSpace(entry), "=")
+		serviceName = strings.TrimSpace(serviceName)
+		if !found || len(serviceName) == 0 {
+			return nil, fmt.Errorf("invalid %s entry '%s', must be <service name>=<duration>", SourceClockOffsetsAppSetting, entry)
+		}
+
+		offset, err := time.ParseDuration(strings.TrimSpace(setting))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s offset for %s: %v", SourceClockOffsetsAppSetting, serviceName, err)
+		}
+
+		offsets[serviceName] = offset
+	}
+
+	return offsets, nil
+}
+
+// compensateClockSkew returns the Event with the clock offset of the Device Service it was received from added to its
+// Origins. The service is identified by the topic the Event was received on, so Events received on other topics are
+// returned unchanged. Must be called while holding the recording mutex.
+func (m *dataManager) compensateClockSkew(ctx appInterfaces.AppFunctionContext, event coreDtos.Event) coreDtos.Event {
+	if len(m.clockOffsets) == 0 {
+		return event
+	}
+
+	receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+	topic, ok := relativeEventTopic(receivedTopic)
+	if !ok {
+		return event
+	}
+
+	offset, found := m.clockOffsets[eventTopicServiceName(topic)]
+	if !found || offset == 0 {
+		return event
+	}
+
+	// The Readings are shared with the received Event, so they are copied rather than changed in place
+	event.Origin += int64(offset)
+	event.Readings = slices.Clone(event.Readings)
+	for index := range event.Readings {
+		event.Readings[index].Origin += int64(offset)
+	}
+
+	return event
+}
+
+// orderByOrigin sorts the batch of Events by their compensated Origins when clock offsets are configured, since
+// Events from services with skewed clocks may have been received out of order. Events are only ordered within a
+// batch. Must be called while holding the recording mutex.
+func (m *dataManager) orderByOrigin(events []coreDtos.Event) {
+	if len(m.clockOffsets) == 0 {
+		return
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Origin < events[j].Origin })
+}
@@ -0,0 +1,116 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_GetSourceClockOffsets(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Setting       string
+		Expected      map[string]time.Duration
+		ExpectedError bool
+	}{
+		{"Not set", "", nil, false},
+		{"One service", "device-modbus=-1.5s", map[string]time.Duration{"device-modbus": -1500 * time.Millisecond}, false},
+		{"Services", "device-modbus=-1.5s, device-onvif = 250ms", map[string]time.Duration{
+			"device-modbus": -1500 * time.Millisecond,
+			"device-onvif":  250 * time.Millisecond,
+		}, false},
+		{"No offset", "device-modbus", nil, true},
+		{"No service", "=1s", nil, true},
+		{"Bad offset", "device-modbus=soon", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{SourceClockOffsetsAppSetting: test.Setting})
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+			actual, err := target.getSourceClockOffsets()
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestDataManager_CompensateClockSkew(t *testing.T) {
+	lc := logger.NewMockClient()
+	target := NewManager(nil, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.clockOffsets = map[string]time.Duration{"device-modbus": -time.Second}
+
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	event.Origin = int64(10 * time.Second)
+	require.NoError(t, event.AddSimpleReading(expectedSourceName, common.ValueTypeInt32, int32(1)))
+	event.Readings[0].Origin = event.Origin
+
+	tests := []struct {
+		Name     string
+		Topic    string
+		Expected int64
+	}{
+		{"Offset service", "edgex/events/device/device-modbus/profile/device/source", int64(9 * time.Second)},
+		{"Other service", "edgex/events/device/device-virtual/profile/device/source", int64(10 * time.Second)},
+		{"Not an Event topic", "edgex/other", int64(10 * time.Second)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx := pkg.NewAppFuncContextForTest("123", lc)
+			ctx.AddValue(appInterfaces.RECEIVEDTOPIC, test.Topic)
+
+			actual := target.compensateClockSkew(ctx, event)
+			assert.Equal(t, test.Expected, actual.Origin)
+			assert.Equal(t, test.Expected, actual.Readings[0].Origin)
+
+			// The received Event is left unchanged
+			assert.Equal(t, int64(10*time.Second), event.Readings[0].Origin)
+		})
+	}
+}
+
+func TestDataManager_OrderByOrigin(t *testing.T) {
+	target := NewManager(nil, time.Minute, clock.New(), nil, nil).(*dataManager)
+	events := []coreDtos.Event{{Id: "1", Origin: 30}, {Id: "2", Origin: 10}, {Id: "3", Origin: 10}}
+
+	// Batches are left in the order received unless clock offsets are configured
+	target.orderByOrigin(events)
+	assert.Equal(t, "1", events[0].Id)
+
+	target.clockOffsets = map[string]time.Duration{"device-modbus": time.Second}
+	target.orderByOrigin(events)
+	assert.Equal(t, []string{"2", "3", "1"}, []string{events[0].Id, events[1].Id, events[2].Id})
+}
@@ -0,0 +1,177 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/google/uuid"
+)
+
+const (
+	// CloudSyncTopicAppSetting is the MessageBus topic, relative to the base topic prefix, each completed recording
+	// is published to in chunks, so it can be synchronized to the cloud by the existing north-bound pipelines, e.g.
+	// app-service-configurable. Disabled when not set.
+	CloudSyncTopicAppSetting = "CloudSyncTopic"
+	// CloudSyncChunkSizeAppSetting is the max number of Events, or messages, in each chunk published for cloud sync
+	CloudSyncChunkSizeAppSetting = "CloudSyncChunkSize"
+
+	defaultCloudSyncChunkSize = 500
+)
+
+var cloudSyncUnavailableError = errors.New(CloudSyncTopicAppSetting + " can't be used since background publishing is unavailable")
+
+// cloudSync holds the cloud sync settings of a recording, which are read when the recording starts
+type cloudSync struct {
+	topic     string
+	chunkSize int
+}
+
+// newCloudSync returns the cloud sync settings, or nil if cloud sync isn't configured
+func (m *dataManager) newCloudSync() (*cloudSync, error) {
+	topic := m.appSvc.ApplicationSettings()[CloudSyncTopicAppSetting]
+	if len(topic) == 0 {
+		return nil, nil
+	}
+
+	if m.opaquePublisher == nil {
+		return nil, cloudSyncUnavailableError
+	}
+
+	chunkSize := defaultCloudSyncChunkSize
+	if setting := m.appSvc.ApplicationSettings()[CloudSyncChunkSizeAppSetting]; len(setting) > 0 {
+		var err error
+		chunkSize, err = strconv.Atoi(setting)
+		if err != nil || chunkSize < 1 {
+			return nil, fmt.Errorf("invalid %s value '%s', must be a number greater than 0", CloudSyncChunkSizeAppSetting, setting)
+		}
+	}
+
+	return &cloudSync{topic: topic, chunkSize: chunkSize}, nil
+}
+
+// startCloudSync publishes the just completed recording in chunks to the cloud sync topic, if configured. The
+// recording is exported and published asynchronously since the devices and profiles may need to be loaded from
+// Core Metadata. Must be called while holding the recording mutex.
+func (m *dataManager) startCloudSync() {
+	if m.cloudSync == nil {
+		return
+	}
+
+	lc := m.appSvc.LoggingClient()
+	topic := m.cloudSync.topic
+	chunkSize := m.cloudSync.chunkSize
+	completed := m.recordedData
+	go func() {
+		m.recordingMutex.Lock()
+		// A later recording may have completed in the meantime, which is synchronized by its own completion
+		if m.recordedData != completed {
+			m.recordingMutex.Unlock()
+			return
+		}
+		recordedData, err := m.exportRecordedData()
+		m.recordingMutex.Unlock()
+
+		if err != nil {
+			lc.Errorf("ARR Cloud Sync: failed to export the completed recording: %v", err)
+			return
+		}
+
+		chunks := chunkRecordedData(recordedData, chunkSize)
+		if err := m.publishChunks(topic, chunks); err != nil {
+			lc.Errorf("ARR Cloud Sync: failed to publish recording %s: %v", chunks[0].RecordingId, err)
+			return
+		}
+
+		lc.Infof("ARR Cloud Sync: Published recording %s in %d chunks to %s", chunks[0].RecordingId, len(chunks), topic)
+	}()
+}
+
+// chunkRecordedData splits the recorded data into chunks of up to the chunk size Events, or messages, each. The
+// first chunk also holds the other recorded data fields and each chunk holds the Envelopes of its Events. There is
+// always at least one chunk.
+func chunkRecordedData(recordedData *dtos.RecordedData, chunkSize int) []dtos.RecordingChunk {
+	total := len(recordedData.RecordedEvents)
+	if len(recordedData.Messages) > 0 {
+		total = len(recordedData.Messages)
+	}
+
+	count := (total + chunkSize - 1) / chunkSize
+	if count == 0 {
+		count = 1
+	}
+
+	recordingId := uuid.NewString()
+	chunks := make([]dtos.RecordingChunk, count)
+	for index := range chunks {
+		start := min(index*chunkSize, total)
+		end := min(start+chunkSize, total)
+
+		chunk := dtos.RecordedData{Name: recordedData.Name}
+		if index == 0 {
+			chunk = *recordedData
+			chunk.Envelopes = nil
+		}
+
+		if len(recordedData.Messages) > 0 {
+			chunk.Messages = recordedData.Messages[start:end]
+		} else {
+			chunk.RecordedEvents = recordedData.RecordedEvents[start:end]
+			for _, event := range chunk.RecordedEvents {
+				if envelope, ok := recordedData.Envelopes[event.Id]; ok {
+					if chunk.Envelopes == nil {
+						chunk.Envelopes = make(map[string]dtos.EnvelopeMetadata)
+					}
+					chunk.Envelopes[event.Id] = envelope
+				}
+			}
+		}
+
+		chunks[index] = dtos.RecordingChunk{
+			RecordingId:  recordingId,
+			Index:        index,
+			Count:        count,
+			RecordedData: chunk,
+		}
+	}
+
+	return chunks
+}
+
+// publishChunks publishes the chunks in order to the topic with the background publisher, using the recording id as
+// the correlation id. Publishing stops at the first failure since the recording can't be reassembled without it.
+func (m *dataManager) publishChunks(topic string, chunks []dtos.RecordingChunk) error {
+	for _, chunk := range chunks {
+		payload, err := json.Marshal(chunk)
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", chunk.Index, err)
+		}
+
+		ctx := m.appSvc.BuildContext(chunk.RecordingId, common.ContentTypeJSON)
+		ctx.AddValue(opaqueTopicKey, topic)
+		if err := m.opaquePublisher.Publish(payload, ctx); err != nil {
+			return fmt.Errorf("chunk %d: %w", chunk.Index, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_NewCloudSync(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Settings      map[string]string
+		Publisher     bool
+		Expected      *cloudSync
+		ExpectedError bool
+	}{
+		{"Not set", map[string]string{}, true, nil, false},
+		{"Default chunk size", map[string]string{CloudSyncTopicAppSetting: "sync"}, true, &cloudSync{"sync", defaultCloudSyncChunkSize}, false},
+		{"Chunk size", map[string]string{CloudSyncTopicAppSetting: "sync", CloudSyncChunkSizeAppSetting: "10"}, true, &cloudSync{"sync", 10}, false},
+		{"Bad chunk size", map[string]string{CloudSyncTopicAppSetting: "sync", CloudSyncChunkSizeAppSetting: "0"}, true, nil, true},
+		{"No publisher", map[string]string{CloudSyncTopicAppSetting: "sync"}, false, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(test.Settings)
+
+			var publisher interfaces.BackgroundPublisher
+			if test.Publisher {
+				publisher = &mocks.BackgroundPublisher{}
+			}
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), publisher, nil).(*dataManager)
+			actual, err := target.newCloudSync()
+			if test.ExpectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestChunkRecordedData(t *testing.T) {
+	var events []coreDtos.Event
+	envelopes := make(map[string]dtos.EnvelopeMetadata)
+	for i := 0; i < 5; i++ {
+		event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+		events = append(events, event)
+		envelopes[event.Id] = dtos.EnvelopeMetadata{CorrelationID: event.Id}
+	}
+
+	recordedData := &dtos.RecordedData{
+		Name:           "line-1",
+		RecordedEvents: events,
+		Devices:        []coreDtos.Device{{Name: expectedDeviceName}},
+		Profiles:       []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: expectedProfileName}}},
+		Envelopes:      envelopes,
+		Metadata:       &dtos.RecordingMetadata{Hostname: "gateway-1"},
+	}
+
+	chunks := chunkRecordedData(recordedData, 2)
+	require.Len(t, chunks, 3)
+
+	var reassembled []coreDtos.Event
+	merged := make(map[string]dtos.EnvelopeMetadata)
+	for index, chunk := range chunks {
+		assert.Equal(t, chunks[0].RecordingId, chunk.RecordingId)
+		assert.Equal(t, index, chunk.Index)
+		assert.Equal(t, 3, chunk.Count)
+		assert.Equal(t, "line-1", chunk.RecordedData.Name)
+		assert.Equal(t, index == 0, chunk.RecordedData.Metadata != nil)
+		assert.Equal(t, index == 0, len(chunk.RecordedData.Devices) > 0)
+
+		reassembled = append(reassembled, chunk.RecordedData.RecordedEvents...)
+		for id, envelope := range chunk.RecordedData.Envelopes {
+			merged[id] = envelope
+		}
+		assert.Len(t, chunk.RecordedData.Envelopes, len(chunk.RecordedData.RecordedEvents))
+	}
+	assert.Equal(t, events, reassembled)
+	assert.Equal(t, envelopes, merged)
+
+	messages := &dtos.RecordedData{Messages: []dtos.OpaqueMessage{{Payload: []byte("1")}, {Payload: []byte("2")}}}
+	chunks = chunkRecordedData(messages, 2)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, messages.Messages, chunks[0].RecordedData.Messages)
+
+	chunks = chunkRecordedData(&dtos.RecordedData{Name: "empty"}, 2)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, 1, chunks[0].Count)
+}
+
+func TestDataManager_StartCloudSync(t *testing.T) {
+	lc := logger.NewMockClient()
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(lc)
+	mockSdk.On("BuildContext", mock.Anything, common.ContentTypeJSON).
+		Return(func(correlationId string, _ string) interfaces.AppFunctionContext {
+			return pkg.NewAppFuncContextForTest(correlationId, lc)
+		})
+
+	var mutex sync.Mutex
+	var chunks []dtos.RecordingChunk
+	var topics []string
+	mockPublisher := &mocks.BackgroundPublisher{}
+	mockPublisher.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		chunk := dtos.RecordingChunk{}
+		assert.NoError(t, json.Unmarshal(args.Get(0).([]byte), &chunk))
+		topic, _ := args.Get(1).(interfaces.AppFunctionContext).GetValue(opaqueTopicKey)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		chunks = append(chunks, chunk)
+		topics = append(topics, topic)
+	}).Return(nil)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), mockPublisher, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore([]coreDtos.Event{
+			coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+			coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+			coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+		}),
+		Devices: map[string]*coreDtos.Device{expectedDeviceName: {Name: expectedDeviceName, ProfileName: expectedProfileName}},
+		Profiles: map[string]*coreDtos.DeviceProfile{
+			expectedProfileName: {DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: expectedProfileName}},
+		},
+	}
+	target.cloudSync = &cloudSync{topic: "sync", chunkSize: 2}
+
+	target.recordingMutex.Lock()
+	target.startCloudSync()
+	target.recordingMutex.Unlock()
+
+	require.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(chunks) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []string{"sync", "sync"}, topics)
+	assert.Equal(t, 0, chunks[0].Index)
+	assert.Len(t, chunks[0].RecordedData.RecordedEvents, 2)
+	assert.Len(t, chunks[0].RecordedData.Profiles, 1)
+	assert.Equal(t, 1, chunks[1].Index)
+	assert.Len(t, chunks[1].RecordedData.RecordedEvents, 1)
+}
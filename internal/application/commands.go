@@ -0,0 +1,186 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+// CommandTopicAppSetting is the topic, relative to the base topic, under which the record and replay commands are
+// received from the MessageBus, e.g. "arr/command", so sessions can be controlled without HTTP access. A command is
+// sent to the command topic followed by its name, i.e. arr/command/record/start, and the start commands take the
+// same JSON request as the HTTP API. The command topic must be matched by the SubscribeTopics. Disabled when not set.
+const CommandTopicAppSetting = "CommandTopic"
+
+const (
+	commandRecordStart   = "record/start"
+	commandRecordStop    = "record/stop"
+	commandReplayStart   = "replay/start"
+	commandReplayStop    = "replay/stop"
+	commandReplayTrigger = "replay/trigger"
+
+	commandPipelineId = "arr-commands"
+)
+
+var invalidCommandTopicError = errors.New("invalid command topic")
+var unknownCommandError = errors.New("unknown command")
+
+// AddCommandPipeline adds the functions pipeline which handles the record and replay commands received on the
+// MessageBus, if the CommandTopic App Setting is set. An error is returned if the setting is invalid or the
+// pipeline can't be added.
+func (m *dataManager) AddCommandPipeline() error {
+	topic := strings.Trim(m.appSvc.ApplicationSettings()[CommandTopicAppSetting], "/")
+	if len(topic) == 0 {
+		return nil
+	}
+
+	if err := validateTopicPattern(topic); err != nil || strings.ContainsAny(topic, singleLevelWildcard+multiLevelWildcard) {
+		return fmt.Errorf("%w '%s': must be a topic without wildcards", invalidCommandTopicError, topic)
+	}
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	m.commandTopic = topic
+	if err := m.addCommandPipeline(); err != nil {
+		m.commandTopic = ""
+		return err
+	}
+
+	m.appSvc.LoggingClient().Infof("ARR Commands: Listening for record and replay commands on %s/#", topic)
+
+	return nil
+}
+
+// addCommandPipeline adds the command pipeline if commands are enabled
+func (m *dataManager) addCommandPipeline() error {
+	if len(m.commandTopic) == 0 {
+		return nil
+	}
+
+	if err := m.appSvc.AddFunctionsPipelineForTopics(commandPipelineId, []string{m.commandTopic + "/" + multiLevelWildcard},
+		m.handleCommand); err != nil {
+		return fmt.Errorf("failed to add the command pipeline: %v", err)
+	}
+
+	return nil
+}
+
+// setSessionPipeline sets the default functions pipeline of a recording or shadow capture. The default pipeline
+// receives every message, so the commands are skipped ahead of the functions. The functions are observed for the
+// pipeline statistics. Must be called while holding the recording mutex.
+func (m *dataManager) setSessionPipeline(functions ...appInterfaces.AppFunction) error {
+	if len(m.commandTopic) > 0 {
+		functions = append([]appInterfaces.AppFunction{m.skipCommands}, functions...)
+	}
+
+	return m.appSvc.SetDefaultFunctionsPipeline(m.pipelineStats.observe(functions, m.clock)...)
+}
+
+// removeSessionPipelines removes the functions pipelines of the recording, shadow capture or replay in standby.
+// The pipelines can only be removed all at once, so the command pipeline is added back.
+func (m *dataManager) removeSessionPipelines() {
+	m.appSvc.RemoveAllFunctionPipelines()
+
+	if err := m.addCommandPipeline(); err != nil {
+		m.appSvc.LoggingClient().Errorf("ARR Commands: %v", err)
+	}
+}
+
+// commandName returns the name of the command received on the topic, or false if the topic isn't a command topic
+func (m *dataManager) commandName(receivedTopic string) (string, bool) {
+	prefix := m.commandTopic + "/"
+	index := strings.Index(receivedTopic, prefix)
+	if len(m.commandTopic) == 0 || index < 0 || (index > 0 && receivedTopic[index-1] != '/') {
+		return "", false
+	}
+
+	return receivedTopic[index+len(prefix):], true
+}
+
+// skipCommands is the pipeline function which stops the pipeline for the messages received on the command topics
+func (m *dataManager) skipCommands(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+	if _, isCommand := m.commandName(receivedTopic); isCommand {
+		return false, nil
+	}
+
+	return true, data
+}
+
+// handleCommand is the pipeline function which runs the record or replay command received. The outcome is logged
+// since there is no requester to respond to.
+func (m *dataManager) handleCommand(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+	command, _ := m.commandName(receivedTopic)
+	payload, _ := data.([]byte)
+
+	if err := m.runCommand(command, payload, ctx.CorrelationID()); err != nil {
+		ctx.LoggingClient().Errorf("ARR Commands: %s command failed: %v", command, err)
+		return false, nil
+	}
+
+	ctx.LoggingClient().Infof("ARR Commands: %s command accepted", command)
+
+	return false, nil
+}
+
+// runCommand runs the command, passing the correlation ID of the command message on to the session it starts
+func (m *dataManager) runCommand(command string, payload []byte, correlationID string) error {
+	switch command {
+	case commandRecordStart:
+		request := dtos.RecordRequest{}
+		if err := decodeCommandRequest(payload, &request); err != nil {
+			return err
+		}
+		request.CorrelationID = correlationID
+		return m.StartRecording(request)
+	case commandRecordStop:
+		return m.CancelRecording()
+	case commandReplayStart:
+		request := dtos.ReplayRequest{}
+		if err := decodeCommandRequest(payload, &request); err != nil {
+			return err
+		}
+		request.CorrelationID = correlationID
+		return m.StartReplay(request)
+	case commandReplayStop:
+		return m.CancelReplay()
+	case commandReplayTrigger:
+		return m.TriggerReplay()
+	default:
+		return unknownCommandError
+	}
+}
+
+func decodeCommandRequest(payload []byte, request any) error {
+	if len(payload) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(payload, request); err != nil {
+		return fmt.Errorf("unable to process request JSON: %v", err)
+	}
+
+	return nil
+}
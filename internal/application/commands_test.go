@@ -0,0 +1,182 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_AddCommandPipeline(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Topic         string
+		AddError      error
+		ExpectedTopic string
+		ExpectedError bool
+	}{
+		{"Disabled", "", nil, "", false},
+		{"Enabled", "arr/command", nil, "arr/command", false},
+		{"Enabled - Trailing separator", "arr/command/", nil, "arr/command", false},
+		{"Error - Wildcard", "arr/#", nil, "", true},
+		{"Error - Add failed", "arr/command", errors.New("failed"), "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{CommandTopicAppSetting: test.Topic})
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("AddFunctionsPipelineForTopics", commandPipelineId, []string{"arr/command/#"}, mock.Anything).
+				Return(test.AddError).Maybe()
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+			err := target.AddCommandPipeline()
+			if test.ExpectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.ExpectedTopic, target.commandTopic)
+			if len(test.ExpectedTopic) == 0 && test.AddError == nil {
+				mockSdk.AssertNotCalled(t, "AddFunctionsPipelineForTopics", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDataManager_CommandName(t *testing.T) {
+	target := &dataManager{commandTopic: "arr/command"}
+
+	tests := []struct {
+		Topic           string
+		ExpectedCommand string
+		ExpectedOk      bool
+	}{
+		{"edgex/arr/command/record/start", commandRecordStart, true},
+		{"arr/command/replay/trigger", commandReplayTrigger, true},
+		{"edgex/events/device/device-virtual/P1/D1/S1", "", false},
+		{"edgex/xarr/command/record/start", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Topic, func(t *testing.T) {
+			command, ok := target.commandName(test.Topic)
+			assert.Equal(t, test.ExpectedOk, ok)
+			assert.Equal(t, test.ExpectedCommand, command)
+		})
+	}
+
+	_, ok := (&dataManager{}).commandName("edgex/arr/command/record/start")
+	assert.False(t, ok)
+}
+
+func TestDataManager_SkipCommands(t *testing.T) {
+	target := &dataManager{commandTopic: "arr/command"}
+
+	ctx := pkg.NewAppFuncContextForTest("", logger.NewMockClient())
+	ctx.AddValue(appInterfaces.RECEIVEDTOPIC, "edgex/arr/command/record/start")
+	continuePipeline, result := target.skipCommands(ctx, []byte("{}"))
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+
+	ctx.AddValue(appInterfaces.RECEIVEDTOPIC, "edgex/events/device/device-virtual/P1/D1/S1")
+	continuePipeline, result = target.skipCommands(ctx, []byte("{}"))
+	assert.True(t, continuePipeline)
+	assert.Equal(t, []byte("{}"), result)
+}
+
+func TestDataManager_RunCommand(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Command       string
+		Payload       string
+		ExpectedError error
+	}{
+		{"Record Start - Bad JSON", commandRecordStart, "{", nil},
+		{"Record Start - Invalid request", commandRecordStart, "{}", nil},
+		{"Record Stop", commandRecordStop, "", noRecordingRunningToCancelError},
+		{"Replay Start", commandReplayStart, `{"replayRate":1}`, noRecordedData},
+		{"Replay Start - Bad JSON", commandReplayStart, "[]", nil},
+		{"Replay Stop", commandReplayStop, "", noReplayRunningToCancelError},
+		{"Replay Trigger", commandReplayTrigger, "", noReplayInStandbyError},
+		{"Unknown", "record/pause", "", unknownCommandError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient()).Maybe()
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+			err := target.runCommand(test.Command, []byte(test.Payload), "")
+			require.Error(t, err)
+			if test.ExpectedError != nil {
+				assert.ErrorIs(t, err, test.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestDataManager_HandleCommand(t *testing.T) {
+	target, mockSdk := newStandbyTarget(map[string]string{})
+	target.commandTopic = "arr/command"
+
+	ctx := pkg.NewAppFuncContextForTest("test-id", logger.NewMockClient())
+	ctx.AddValue(appInterfaces.RECEIVEDTOPIC, "edgex/arr/command/replay/start")
+	continuePipeline, result := target.handleCommand(ctx, []byte(`{"replayRate":10,"standby":true}`))
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+	assert.True(t, target.ReplayStatus().Standby)
+	assert.Equal(t, "test-id", target.ReplayStatus().CorrelationID)
+
+	ctx.AddValue(appInterfaces.RECEIVEDTOPIC, "edgex/arr/command/replay/stop")
+	target.handleCommand(ctx, nil)
+	assert.False(t, target.ReplayStatus().Standby)
+	assert.Equal(t, replayCanceled.Error(), target.ReplayStatus().Message)
+	mockSdk.AssertNotCalled(t, "PublishWithTopic", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDataManager_SessionPipelines(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	// The command filter is added ahead of the session's function
+	mockSdk.On("SetDefaultFunctionsPipeline", mock.Anything, mock.Anything).Return(nil).Once()
+	mockSdk.On("RemoveAllFunctionPipelines").Once()
+	mockSdk.On("AddFunctionsPipelineForTopics", commandPipelineId, []string{"arr/command/#"}, mock.Anything).Return(nil).Once()
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.commandTopic = "arr/command"
+
+	require.NoError(t, target.startShadowCapture())
+	target.removeSessionPipelines()
+	mockSdk.AssertExpectations(t)
+}
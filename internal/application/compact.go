@@ -0,0 +1,236 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+// segmentStoreScan is the content of the segment store found by a scan
+type segmentStoreScan struct {
+	stats     dtos.SegmentStoreStats
+	tempFiles []string
+	emptyDirs []string
+}
+
+// StoreStats returns the memory and storage statistics of the recording store
+func (m *dataManager) StoreStats() (dtos.StoreStats, error) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	stats, _, err := m.storeStats()
+	return stats, err
+}
+
+// CompactStore releases the unused memory held by the recorded data and deletes the partially written segments and
+// empty recording directories left in the segment store. An error is returned if a replay is in progress, since
+// replays read the recorded data without holding the recording mutex.
+func (m *dataManager) CompactStore() (*dtos.CompactResult, error) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.replayStartedAt != nil {
+		return nil, replayInProgressError
+	}
+
+	lc := m.appSvc.LoggingClient()
+	before := m.memoryStats()
+
+	if m.recordedData != nil {
+		m.recordedData.Events.compact()
+		m.recordedData.Messages = clip(m.recordedData.Messages)
+		m.recordedData.DeadLetters = clip(m.recordedData.DeadLetters)
+		m.recordedData.Telemetry = clip(m.recordedData.Telemetry)
+		m.recordedData.SystemEvents = clip(m.recordedData.SystemEvents)
+	}
+	m.recordedMessages = clip(m.recordedMessages)
+	m.recordedDeadLetters = clip(m.recordedDeadLetters)
+	m.recordedTelemetry = clip(m.recordedTelemetry)
+	m.recordedSystemEvents = clip(m.recordedSystemEvents)
+
+	// Maps never shrink, so the envelopes a continuous recording has rotated out still hold their space until the
+	// map is rebuilt
+	if m.recordedEnvelopes != nil {
+		envelopes := make(map[string]dtos.EnvelopeMetadata, len(m.recordedEnvelopes))
+		for id, envelope := range m.recordedEnvelopes {
+			envelopes[id] = envelope
+		}
+		m.recordedEnvelopes = envelopes
+	}
+
+	result := &dtos.CompactResult{}
+	_, scan, err := m.storeStats()
+	if err != nil {
+		return nil, err
+	}
+
+	if scan != nil {
+		for _, path := range scan.tempFiles {
+			info, err := os.Stat(path)
+			if err == nil {
+				err = os.Remove(path)
+			}
+			if err != nil {
+				lc.Errorf("ARR Compact: unable to delete partially written segment %s: %v", path, err)
+				continue
+			}
+			result.RemovedFileCount++
+			result.ReclaimedStorageBytes += info.Size()
+		}
+
+		for _, dir := range scan.emptyDirs {
+			if err := os.Remove(dir); err != nil {
+				lc.Errorf("ARR Compact: unable to delete empty recording directory %s: %v", dir, err)
+				continue
+			}
+			result.RemovedDirCount++
+		}
+	}
+
+	result.Stats, _, err = m.storeStats()
+	if err != nil {
+		return nil, err
+	}
+
+	result.ReclaimedMemoryBytes = before.AllocatedBytes - result.Stats.Memory.AllocatedBytes
+
+	lc.Infof("ARR Compact: reclaimed %d bytes of memory and %d bytes of storage, deleting %d files and %d directories",
+		result.ReclaimedMemoryBytes, result.ReclaimedStorageBytes, result.RemovedFileCount, result.RemovedDirCount)
+
+	return result, nil
+}
+
+// storeStats returns the statistics of the recording store along with the segment store scan they were taken from,
+// which is nil if the segment store isn't configured.
+// Must be called while holding the recording mutex.
+func (m *dataManager) storeStats() (dtos.StoreStats, *segmentStoreScan, error) {
+	stats := dtos.StoreStats{Memory: m.memoryStats()}
+
+	storeDir := m.appSvc.ApplicationSettings()[SegmentStoreDirAppSetting]
+	if len(storeDir) == 0 {
+		return stats, nil, nil
+	}
+
+	// The directory of a continuous recording in progress isn't empty, it just hasn't completed a segment yet
+	var activeDir string
+	if m.recordingStartedAt != nil && m.segmentRotation != nil {
+		activeDir = m.segmentRotation.dir
+	}
+
+	scan, err := scanSegmentStore(storeDir, activeDir)
+	if err != nil {
+		return dtos.StoreStats{}, nil, err
+	}
+
+	stats.Segments = &scan.stats
+	return stats, scan, nil
+}
+
+// memoryStats returns the statistics of the recorded data held in memory.
+// Must be called while holding the recording mutex.
+func (m *dataManager) memoryStats() dtos.MemoryStoreStats {
+	stats := dtos.MemoryStoreStats{}
+	usage := &memoryUsage{}
+
+	if m.recordedData != nil {
+		if events := m.recordedData.Events; events != nil {
+			stats.EventCount = events.len()
+			stats.ReadingCount = len(events.readings)
+			stats.ResourceColumnCount = len(events.columns)
+			stats.WholeReadingCount = len(events.wholeReadings)
+			stats.InternedStringCount = len(events.interned)
+			events.memoryUsage(usage)
+		}
+
+		stats.MessageCount = len(m.recordedData.Messages)
+		addSlice(usage, m.recordedData.Messages)
+		addSlice(usage, m.recordedData.DeadLetters)
+		addSlice(usage, m.recordedData.Telemetry)
+		addSlice(usage, m.recordedData.SystemEvents)
+	}
+
+	stats.MessageCount += len(m.recordedMessages)
+	addSlice(usage, m.recordedMessages)
+	addSlice(usage, m.recordedDeadLetters)
+	addSlice(usage, m.recordedTelemetry)
+	addSlice(usage, m.recordedSystemEvents)
+
+	stats.UsedBytes = usage.used
+	stats.AllocatedBytes = usage.allocated
+	if usage.allocated > 0 {
+		stats.Fragmentation = 1 - float64(usage.used)/float64(usage.allocated)
+	}
+
+	return stats
+}
+
+// scanSegmentStore scans the recording directories in the segment store for segments, partially written segments
+// and directories with no segments. The active directory is never reported as empty. A missing store is empty.
+func scanSegmentStore(storeDir string, activeDir string) (*segmentStoreScan, error) {
+	scan := &segmentStoreScan{}
+
+	recordingDirs, err := os.ReadDir(storeDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return scan, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, recordingDir := range recordingDirs {
+		if !recordingDir.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(storeDir, recordingDir.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		scan.stats.RecordingCount++
+		if len(entries) == 0 && dir != activeDir {
+			scan.stats.EmptyDirCount++
+			scan.emptyDirs = append(scan.emptyDirs, dir)
+			continue
+		}
+
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+
+			switch {
+			case strings.HasSuffix(entry.Name(), segmentFileExtension):
+				scan.stats.SegmentCount++
+				scan.stats.SegmentBytes += info.Size()
+			case strings.HasSuffix(entry.Name(), segmentTempFileExtension):
+				scan.stats.TempFileCount++
+				scan.stats.TempFileBytes += info.Size()
+				scan.tempFiles = append(scan.tempFiles, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	return scan, nil
+}
@@ -0,0 +1,136 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStore_Compact(t *testing.T) {
+	store := newEventStore(nil)
+	for i := 0; i < 100; i++ {
+		store.add(coreDtos.Event{
+			Id:         "event",
+			DeviceName: "device-1",
+			Origin:     int64(i),
+			Readings: []coreDtos.BaseReading{
+				{Id: "reading", DeviceName: "device-1", ResourceName: "temperature", SimpleReading: coreDtos.SimpleReading{Value: "21.5"}},
+				{Id: "binary", DeviceName: "device-1", ResourceName: "image", BinaryReading: coreDtos.BinaryReading{BinaryValue: []byte{1}}},
+			},
+		})
+	}
+	expected := store.events()
+
+	before := &memoryUsage{}
+	store.memoryUsage(before)
+	require.Greater(t, before.allocated, before.used, "appending leaves unused capacity")
+
+	store.compact()
+
+	after := &memoryUsage{}
+	store.memoryUsage(after)
+	assert.Equal(t, before.used, after.used)
+	assert.Equal(t, after.used, after.allocated)
+	assert.Equal(t, expected, store.events())
+
+	// Compacting a nil store, as when nothing is recorded, is a no-op
+	var empty *eventStore
+	empty.compact()
+	empty.memoryUsage(after)
+}
+
+func TestDataManager_CompactStore(t *testing.T) {
+	storeDir := t.TempDir()
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{SegmentStoreDirAppSetting: storeDir})
+
+	// A recording with segments and a partially written segment, a recording with no segments left and the
+	// directory of the continuous recording in progress, which hasn't completed a segment yet
+	recordingDir := filepath.Join(storeDir, "line-1")
+	require.NoError(t, os.MkdirAll(recordingDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(recordingDir, "20240301T120000.000000000Z.json"), []byte("0123456789"), 0640))
+	require.NoError(t, os.WriteFile(filepath.Join(recordingDir, "20240301T130000.000000000Z.json.tmp"), []byte("01234"), 0640))
+	require.NoError(t, os.MkdirAll(filepath.Join(storeDir, "line-2"), 0750))
+	activeDir := filepath.Join(storeDir, "line-3")
+	require.NoError(t, os.MkdirAll(activeDir, 0750))
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+	now := time.Now()
+	target.recordingStartedAt = &now
+	target.segmentRotation = &segmentRotation{dir: activeDir}
+	target.recordedEnvelopes = map[string]dtos.EnvelopeMetadata{"event-1": {}}
+
+	var messages []dtos.OpaqueMessage
+	for i := 0; i < 9; i++ {
+		messages = append(messages, dtos.OpaqueMessage{Payload: []byte("payload")})
+	}
+	target.recordedData = &recordedData{Messages: messages}
+
+	stats, err := target.StoreStats()
+	require.NoError(t, err)
+	assert.Equal(t, 9, stats.Memory.MessageCount)
+	assert.Greater(t, stats.Memory.Fragmentation, 0.0)
+	require.NotNil(t, stats.Segments)
+	assert.Equal(t, dtos.SegmentStoreStats{
+		RecordingCount: 3,
+		SegmentCount:   1,
+		SegmentBytes:   10,
+		TempFileCount:  1,
+		TempFileBytes:  5,
+		EmptyDirCount:  1,
+	}, *stats.Segments)
+
+	result, err := target.CompactStore()
+	require.NoError(t, err)
+	assert.Equal(t, stats.Memory.AllocatedBytes-stats.Memory.UsedBytes, result.ReclaimedMemoryBytes)
+	assert.Equal(t, int64(5), result.ReclaimedStorageBytes)
+	assert.Equal(t, 1, result.RemovedFileCount)
+	assert.Equal(t, 1, result.RemovedDirCount)
+	assert.Zero(t, result.Stats.Memory.Fragmentation)
+	assert.Equal(t, dtos.SegmentStoreStats{RecordingCount: 2, SegmentCount: 1, SegmentBytes: 10}, *result.Stats.Segments)
+	assert.Len(t, target.recordedData.Messages, 9)
+	assert.Contains(t, target.recordedEnvelopes, "event-1")
+	assert.DirExists(t, activeDir)
+
+	// Replays read the recorded data without holding the mutex, so it can't be compacted during one
+	target.replayStartedAt = &now
+	_, err = target.CompactStore()
+	require.Equal(t, replayInProgressError, err)
+}
+
+func TestDataManager_StoreStats_NoSegmentStore(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+
+	stats, err := target.StoreStats()
+	require.NoError(t, err)
+	assert.Equal(t, dtos.StoreStats{}, stats)
+}
@@ -0,0 +1,180 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+// currentRecordingName names the recorded data held in memory when comparing recordings, whatever its own name
+const currentRecordingName = "current"
+
+var (
+	compareResourceRequiredError = errors.New("resource name must be set")
+	compareRecordingNotFound     = errors.New("recording not found in the recorded data or the segment store")
+)
+
+// CompareSeries returns the numeric Readings of the resource, limited to the device when set, from the two
+// recordings as paired time series. Each recording is either the recorded data held in memory, named current or by
+// its own name, or all the segments of the recording with the name in the segment store. An error is returned if
+// the resource isn't set or either recording isn't found or can't be read.
+func (m *dataManager) CompareSeries(a string, b string, resourceName string, deviceName string) (*dtos.ComparisonSeries, error) {
+	if len(resourceName) == 0 {
+		return nil, compareResourceRequiredError
+	}
+
+	result := &dtos.ComparisonSeries{
+		ResourceName: resourceName,
+		A:            dtos.ComparedRecording{Name: a},
+		B:            dtos.ComparedRecording{Name: b},
+		Series:       []dtos.PairedSeries{},
+	}
+
+	series := make(map[string]*dtos.PairedSeries)
+	for index, recording := range []*dtos.ComparedRecording{&result.A, &result.B} {
+		events, err := m.comparedEvents(recording.Name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load recording '%s': %w", recording.Name, err)
+		}
+
+		for _, event := range events {
+			if recording.Start == 0 || event.Origin < recording.Start {
+				recording.Start = event.Origin
+			}
+		}
+
+		for _, event := range events {
+			for _, reading := range event.Readings {
+				if reading.ResourceName != resourceName || (len(deviceName) > 0 && reading.DeviceName != deviceName) {
+					continue
+				}
+
+				value, ok := numericReadingValue(reading)
+				if !ok {
+					continue
+				}
+
+				paired, ok := series[reading.DeviceName]
+				if !ok {
+					paired = &dtos.PairedSeries{DeviceName: reading.DeviceName, A: []dtos.SeriesPoint{}, B: []dtos.SeriesPoint{}}
+					series[reading.DeviceName] = paired
+				}
+
+				points := &paired.A
+				if index == 1 {
+					points = &paired.B
+				}
+				*points = append(*points, dtos.SeriesPoint{
+					Offset: time.Duration(reading.Origin - recording.Start),
+					Origin: reading.Origin,
+					Value:  value,
+				})
+				recording.PointCount++
+			}
+		}
+	}
+
+	for _, paired := range series {
+		sort.SliceStable(paired.A, func(i, j int) bool { return paired.A[i].Origin < paired.A[j].Origin })
+		sort.SliceStable(paired.B, func(i, j int) bool { return paired.B[i].Origin < paired.B[j].Origin })
+		result.Series = append(result.Series, *paired)
+	}
+	sort.Slice(result.Series, func(i, j int) bool { return result.Series[i].DeviceName < result.Series[j].DeviceName })
+
+	return result, nil
+}
+
+// comparedEvents returns the Events of the recording with the name, which is the recorded data held in memory when
+// named current or by its own name, otherwise the recording's segments in the segment store in time order
+func (m *dataManager) comparedEvents(name string) ([]coreDtos.Event, error) {
+	m.recordingMutex.Lock()
+	data := m.recordedData
+	m.recordingMutex.Unlock()
+
+	if data != nil && (name == currentRecordingName || (len(data.Name) > 0 && name == data.Name)) {
+		return data.Events.events(), nil
+	}
+
+	storeDir := m.appSvc.ApplicationSettings()[SegmentStoreDirAppSetting]
+	if len(storeDir) == 0 || !isStoreEntryName(name) {
+		return nil, compareRecordingNotFound
+	}
+
+	paths, err := segmentPaths(filepath.Join(storeDir, name))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, compareRecordingNotFound
+	}
+
+	var events []coreDtos.Event
+	for _, segmentPath := range paths {
+		segment, err := os.ReadFile(segmentPath)
+		if errors.Is(err, os.ErrNotExist) {
+			// Deleted by the retention limits since the directory was read
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Only the Events are decoded, the rest of the segment is skipped
+		data := struct {
+			RecordedEvents     []coreDtos.Event         `json:"recordedEvents"`
+			ReadingsOnlyEvents []dtos.ReadingsOnlyEvent `json:"readingsOnlyEvents"`
+		}{}
+		if err := json.Unmarshal(segment, &data); err != nil {
+			return nil, fmt.Errorf("unable to read segment %s: %v", segmentPath, err)
+		}
+
+		events = append(events, data.RecordedEvents...)
+		events = append(events, utils.ExpandEvents(data.ReadingsOnlyEvents)...)
+	}
+
+	return events, nil
+}
+
+// numericReadingValue returns the value of the Reading if it is of a numeric type
+func numericReadingValue(reading coreDtos.BaseReading) (float64, bool) {
+	switch reading.ValueType {
+	case common.ValueTypeFloat32, common.ValueTypeFloat64,
+		common.ValueTypeInt8, common.ValueTypeInt16, common.ValueTypeInt32, common.ValueTypeInt64,
+		common.ValueTypeUint8, common.ValueTypeUint16, common.ValueTypeUint32, common.ValueTypeUint64:
+	default:
+		return 0, false
+	}
+
+	value, err := strconv.ParseFloat(reading.Value, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
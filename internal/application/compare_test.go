@@ -0,0 +1,126 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompareEvent(deviceName string, origin time.Duration, value string) coreDtos.Event {
+	event := newAppendEvent(deviceName, origin)
+	event.Readings[0].Value = value
+	return event
+}
+
+func writeCompareSegment(t *testing.T, dir string, name string, events ...coreDtos.Event) {
+	segment, err := json.Marshal(dtos.RecordedData{Name: filepath.Base(dir), RecordedEvents: events})
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(dir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+segmentFileExtension), segment, 0640))
+}
+
+func TestDataManager_CompareSeries(t *testing.T) {
+	storeDir := t.TempDir()
+	baselineDir := filepath.Join(storeDir, "baseline")
+	writeCompareSegment(t, baselineDir, "20240101T000000.000000000Z",
+		newCompareEvent("D1", 100*time.Second, "1"),
+		newCompareEvent("D2", 101*time.Second, "5"))
+	writeCompareSegment(t, baselineDir, "20240101T000100.000000000Z",
+		newCompareEvent("D1", 160*time.Second, "2"))
+	require.NoError(t, os.WriteFile(filepath.Join(baselineDir, "partial"+segmentFileExtension+segmentTempFileExtension), []byte("{"), 0640))
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{SegmentStoreDirAppSetting: storeDir})
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	// The value of the non-numeric Reading isn't plotted
+	text := newCompareEvent("D1", 12*time.Second, "high")
+	text.Readings[0].ValueType = common.ValueTypeString
+	target.recordedData = &recordedData{
+		Name: "run-2",
+		Events: newEventStore([]coreDtos.Event{
+			newCompareEvent("D1", 10*time.Second, "1.5"),
+			newCompareEvent("D1", 11*time.Second, "2.5"),
+			text,
+		}),
+	}
+
+	actual, err := target.CompareSeries("baseline", "run-2", expectedSourceName, "")
+	require.NoError(t, err)
+
+	expected := &dtos.ComparisonSeries{
+		ResourceName: expectedSourceName,
+		A:            dtos.ComparedRecording{Name: "baseline", Start: int64(100 * time.Second), PointCount: 3},
+		B:            dtos.ComparedRecording{Name: "run-2", Start: int64(10 * time.Second), PointCount: 2},
+		Series: []dtos.PairedSeries{
+			{
+				DeviceName: "D1",
+				A: []dtos.SeriesPoint{
+					{Offset: 0, Origin: int64(100 * time.Second), Value: 1},
+					{Offset: 60 * time.Second, Origin: int64(160 * time.Second), Value: 2},
+				},
+				B: []dtos.SeriesPoint{
+					{Offset: 0, Origin: int64(10 * time.Second), Value: 1.5},
+					{Offset: time.Second, Origin: int64(11 * time.Second), Value: 2.5},
+				},
+			},
+			{
+				DeviceName: "D2",
+				A:          []dtos.SeriesPoint{{Offset: time.Second, Origin: int64(101 * time.Second), Value: 5}},
+				B:          []dtos.SeriesPoint{},
+			},
+		},
+	}
+	assert.Equal(t, expected, actual)
+
+	// The recorded data can also be named current, and the series limited to a device
+	actual, err = target.CompareSeries(currentRecordingName, "baseline", expectedSourceName, "D2")
+	require.NoError(t, err)
+	require.Len(t, actual.Series, 1)
+	assert.Equal(t, "D2", actual.Series[0].DeviceName)
+	assert.Empty(t, actual.Series[0].A)
+	assert.Len(t, actual.Series[0].B, 1)
+
+	_, err = target.CompareSeries("baseline", "run-2", "", "")
+	require.Equal(t, compareResourceRequiredError, err)
+
+	// Glob metacharacters only match a recording of that exact name
+	for _, name := range []string{"missing", "..", "", "baseline/..", "*", "base*", "[b]aseline"} {
+		_, err = target.CompareSeries("baseline", name, expectedSourceName, "")
+		require.ErrorIs(t, err, compareRecordingNotFound, name)
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(storeDir, "corrupt"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(storeDir, "corrupt", "20240101T000000.000000000Z"+segmentFileExtension), []byte("{"), 0640))
+	_, err = target.CompareSeries("baseline", "corrupt", expectedSourceName, "")
+	require.Error(t, err)
+}
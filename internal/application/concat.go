@@ -0,0 +1,190 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+var (
+	appendOpaqueError      = errors.New("opaque recordings can't be appended since their messages aren't decoded")
+	appendNoEventsError    = errors.New("appended data has no recorded events")
+	invalidAppendGapError  = errors.New("append gap must be greater than or equal 0")
+	appendNoRecordingError = errors.New("no recorded data present to append to")
+)
+
+// AppendRecordedData appends the Events of the data after the last recorded or imported Events, shifting their
+// Origins so the first appended Event follows the last recorded Event after the gap. The data's Device Profiles and
+// Devices are added to Core Metadata, as for an import, and to the recorded data where not already present. An error
+// is returned if there is no recorded data, either the recorded or appended data is opaque, or the recorded data
+// can't be replaced.
+func (m *dataManager) AppendRecordedData(data *dtos.RecordedData, gap time.Duration, overwrite bool, rollback bool) error {
+	if gap < 0 {
+		return invalidAppendGapError
+	}
+
+	if len(data.Messages) > 0 {
+		return appendOpaqueError
+	}
+
+	if len(data.RecordedEvents) == 0 {
+		return appendNoEventsError
+	}
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.recordingStartedAt != nil {
+		return recordingInProgressError
+	}
+
+	if m.replayStartedAt != nil {
+		return replayInProgressError
+	}
+
+	if m.recordedDataLocked {
+		return recordedDataLockedError
+	}
+
+	existing := m.recordedData
+	if existing == nil {
+		return appendNoRecordingError
+	}
+
+	if len(existing.Messages) > 0 {
+		return appendOpaqueError
+	}
+
+	// As for an import, the data is appended even if some of its Device Profiles and Devices fail to be provisioned
+	provisioningErr := m.provisionImport(data, overwrite, rollback)
+	if provisioningErr != nil && !isProvisioningError(provisioningErr) {
+		return provisioningErr
+	}
+
+	// A recording which captured no Events has no end, so the appended Events are kept as is
+	events := existing.Events.events()
+	var offset int64
+	if len(events) > 0 {
+		_, end := originRange(events)
+		start, _ := originRange(data.RecordedEvents)
+		offset = end + int64(gap) - start
+	}
+
+	for _, event := range data.RecordedEvents {
+		events = append(events, shiftEvent(event, offset))
+	}
+
+	// Telemetry is replayed relative to the first Event, so it is shifted along with the Events
+	telemetry := append([]dtos.OpaqueMessage(nil), existing.Telemetry...)
+	for _, message := range data.Telemetry {
+		message.ReceivedAt += offset
+		telemetry = append(telemetry, message)
+	}
+
+	// As are the system events, which are replayed in sequence with the Events
+	systemEvents := append([]dtos.OpaqueMessage(nil), existing.SystemEvents...)
+	for _, message := range data.SystemEvents {
+		message.ReceivedAt += offset
+		systemEvents = append(systemEvents, message)
+	}
+
+	// Annotations mark points and ranges among the Events, so they are shifted along with them too
+	annotations := append([]dtos.Annotation(nil), existing.Annotations...)
+	for _, annotation := range data.Annotations {
+		annotation.Timestamp += offset
+		if annotation.End != 0 {
+			annotation.End += offset
+		}
+		annotations = append(annotations, annotation)
+	}
+
+	// The recorded data is replaced rather than changed in place, since it may be in use outside the lock
+	appended := &recordedData{
+		Name:         existing.Name,
+		Label:        existing.Label,
+		Duration:     existing.Duration,
+		Events:       newEventStore(events),
+		Devices:      mergeMap(existing.Devices, utils.SliceToMap(data.Devices, func(d coreDtos.Device) string { return d.Name })),
+		Profiles:     mergeMap(existing.Profiles, utils.SliceToMap(data.Profiles, func(dp coreDtos.DeviceProfile) string { return dp.Name })),
+		Envelopes:    mergeMap(existing.Envelopes, data.Envelopes),
+		DeadLetters:  append(append([]dtos.DeadLetter(nil), existing.DeadLetters...), data.DeadLetters...),
+		Telemetry:    telemetry,
+		SystemEvents: systemEvents,
+		Annotations:  annotations,
+		Metadata:     existing.Metadata,
+	}
+	m.recordedData = appended
+
+	m.appSvc.LoggingClient().Debugf("ARR Append: Appended %d events shifted by %s, now %d events",
+		len(data.RecordedEvents), time.Duration(offset), appended.Events.len())
+	return provisioningErr
+}
+
+// originRange returns the earliest and latest Origin of the Events and their Readings
+func originRange(events []coreDtos.Event) (int64, int64) {
+	start := int64(math.MaxInt64)
+	end := int64(math.MinInt64)
+	update := func(origin int64) {
+		start = min(start, origin)
+		end = max(end, origin)
+	}
+
+	for _, event := range events {
+		update(event.Origin)
+		for _, reading := range event.Readings {
+			update(reading.Origin)
+		}
+	}
+
+	return start, end
+}
+
+// shiftEvent returns a copy of the Event with the Origins of it and its Readings shifted by the offset
+func shiftEvent(event coreDtos.Event, offset int64) coreDtos.Event {
+	event.Origin += offset
+	readings := make([]coreDtos.BaseReading, len(event.Readings))
+	for index, reading := range event.Readings {
+		reading.Origin += offset
+		readings[index] = reading
+	}
+	event.Readings = readings
+	return event
+}
+
+// mergeMap returns a new map with the entries of both maps, keeping the existing entry for keys in both. Nil is
+// returned if both are empty.
+func mergeMap[V any](existing map[string]V, added map[string]V) map[string]V {
+	if len(existing) == 0 && len(added) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]V, len(existing)+len(added))
+	for key, value := range added {
+		merged[key] = value
+	}
+	for key, value := range existing {
+		merged[key] = value
+	}
+
+	return merged
+}
@@ -0,0 +1,173 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newAppendEvent(deviceName string, origin time.Duration) coreDtos.Event {
+	event := coreDtos.NewEvent(expectedProfileName, deviceName, expectedSourceName)
+	event.Origin = int64(origin)
+	_ = event.AddSimpleReading(expectedSourceName, common.ValueTypeInt32, int32(1))
+	event.Readings[0].Origin = int64(origin)
+	return event
+}
+
+func createAppendTarget() *dataManager {
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceNameExists", mock.Anything, mock.Anything).
+		Return(commonDTO.BaseResponse{StatusCode: 200}, nil)
+	mockProfileClient := &clientMocks.DeviceProfileClient{}
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceProfileResponse{}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+
+	return NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+}
+
+func TestDataManager_AppendRecordedData(t *testing.T) {
+	target := createAppendTarget()
+
+	first := newAppendEvent("D1", 10*time.Second)
+	last := newAppendEvent("D1", 20*time.Second)
+	target.recordedData = &recordedData{
+		Name:      "scenario",
+		Events:    newEventStore([]coreDtos.Event{first, last}),
+		Devices:   map[string]*coreDtos.Device{"D1": {Name: "D1", ProfileName: expectedProfileName}},
+		Envelopes: map[string]dtos.EnvelopeMetadata{first.Id: {CorrelationID: "first"}},
+	}
+
+	appended := &dtos.RecordedData{
+		Name: "other",
+		RecordedEvents: []coreDtos.Event{
+			newAppendEvent("D2", 100*time.Second),
+			newAppendEvent("D2", 103*time.Second),
+		},
+		Devices:   []coreDtos.Device{{Name: "D1", ProfileName: "other"}, {Name: "D2", ProfileName: expectedProfileName}},
+		Profiles:  []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: expectedProfileName}}},
+		Envelopes: map[string]dtos.EnvelopeMetadata{"other-event": {CorrelationID: "other"}},
+		Annotations: []dtos.Annotation{
+			{Label: "valve-opened", Timestamp: int64(101 * time.Second)},
+			{Label: "fault", Timestamp: int64(100 * time.Second), End: int64(102 * time.Second)},
+		},
+	}
+
+	require.NoError(t, target.AppendRecordedData(appended, 5*time.Second, false, false))
+
+	data := target.recordedData
+	assert.Equal(t, "scenario", data.Name)
+	events := data.Events.events()
+	require.Len(t, events, 4)
+	assert.Equal(t, first.Id, events[0].Id)
+	assert.Equal(t, last.Id, events[1].Id)
+	assert.Equal(t, appended.RecordedEvents[0].Id, events[2].Id)
+	assert.Equal(t, int64(25*time.Second), events[2].Origin)
+	assert.Equal(t, int64(25*time.Second), events[2].Readings[0].Origin)
+	assert.Equal(t, int64(28*time.Second), events[3].Origin)
+
+	// The recorded Device is kept over the appended one of the same name
+	require.Len(t, data.Devices, 2)
+	assert.Equal(t, expectedProfileName, data.Devices["D1"].ProfileName)
+	assert.Len(t, data.Profiles, 1)
+	assert.Len(t, data.Envelopes, 2)
+
+	// The annotations are shifted along with the Events
+	assert.Equal(t, []dtos.Annotation{
+		{Label: "valve-opened", Timestamp: int64(26 * time.Second)},
+		{Label: "fault", Timestamp: int64(25 * time.Second), End: int64(27 * time.Second)},
+	}, data.Annotations)
+
+	// The appended data isn't changed
+	assert.Equal(t, int64(100*time.Second), appended.RecordedEvents[0].Origin)
+	assert.Equal(t, int64(100*time.Second), appended.RecordedEvents[0].Readings[0].Origin)
+}
+
+func TestDataManager_AppendRecordedData_Errors(t *testing.T) {
+	events := &dtos.RecordedData{RecordedEvents: []coreDtos.Event{newAppendEvent("D1", time.Second)}}
+	now := time.Now()
+
+	tests := []struct {
+		Name          string
+		Existing      *recordedData
+		Data          *dtos.RecordedData
+		Gap           time.Duration
+		Locked        bool
+		Recording     bool
+		ExpectedError error
+	}{
+		{"Negative gap", &recordedData{}, events, -time.Second, false, false, invalidAppendGapError},
+		{"Opaque appended", &recordedData{}, &dtos.RecordedData{Messages: []dtos.OpaqueMessage{{}}}, 0, false, false, appendOpaqueError},
+		{"No appended events", &recordedData{}, &dtos.RecordedData{}, 0, false, false, appendNoEventsError},
+		{"No recorded data", nil, events, 0, false, false, appendNoRecordingError},
+		{"Opaque recorded", &recordedData{Messages: []dtos.OpaqueMessage{{}}}, events, 0, false, false, appendOpaqueError},
+		{"Locked", &recordedData{}, events, 0, true, false, recordedDataLockedError},
+		{"Recording", &recordedData{}, events, 0, false, true, recordingInProgressError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := createAppendTarget()
+			target.recordedData = test.Existing
+			target.recordedDataLocked = test.Locked
+			if test.Recording {
+				target.recordingStartedAt = &now
+			}
+
+			err := target.AppendRecordedData(test.Data, test.Gap, true, false)
+			require.Equal(t, test.ExpectedError, err)
+			assert.Equal(t, test.Existing, target.recordedData)
+		})
+	}
+}
+
+func TestDataManager_AppendRecordedData_NoRecordedEvents(t *testing.T) {
+	target := createAppendTarget()
+	target.recordedData = &recordedData{Events: newEventStore(nil)}
+
+	appended := &dtos.RecordedData{RecordedEvents: []coreDtos.Event{newAppendEvent("D1", time.Minute)}}
+	require.NoError(t, target.AppendRecordedData(appended, time.Second, true, false))
+
+	events := target.recordedData.Events.events()
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(time.Minute), events[0].Origin)
+}
+
+func TestMergeMap(t *testing.T) {
+	assert.Nil(t, mergeMap[int](nil, map[string]int{}))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 4},
+		mergeMap(map[string]int{"a": 1, "b": 2}, map[string]int{"b": 3, "c": 4}))
+}
@@ -0,0 +1,105 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+)
+
+const oneDay = 24 * time.Hour
+
+var dailyReplayOptionsError = errors.New("ReplayRate, TimeWarpDuration and DevicePriorities can't be used with AlignTimeOfDay")
+
+// dailyAlignment aligns the replay of a recording, typically of 24 hours, with the wall-clock time-of-day so the
+// replayed data matches the current hour, looping it daily. The recording start is aligned to its time-of-day in the
+// local time zone on the day the replay starts, or the day before if that is still to come, and each following day
+// repeats the recording from the same time-of-day. Only the first 24 hours of the recording are replayed each day.
+type dailyAlignment struct {
+	sourceStart int64
+	alignedAt   time.Time
+	startedAt   time.Time
+}
+
+// newDailyAlignment returns the alignment for the request, or nil if the request doesn't set AlignTimeOfDay.
+func newDailyAlignment(request dtos.ReplayRequest, events *eventStore, envelopes map[string]dtos.EnvelopeMetadata,
+	replayStart time.Time) *dailyAlignment {
+	if !request.AlignTimeOfDay || events.len() == 0 {
+		return nil
+	}
+
+	sourceStart, _ := recordedWindow(request, events, envelopes)
+
+	recorded := time.Unix(0, sourceStart).In(replayStart.Location())
+	alignedAt := time.Date(replayStart.Year(), replayStart.Month(), replayStart.Day(), recorded.Hour(), recorded.Minute(),
+		recorded.Second(), recorded.Nanosecond(), replayStart.Location())
+	if alignedAt.After(replayStart) {
+		alignedAt = alignedAt.AddDate(0, 0, -1)
+	}
+
+	return &dailyAlignment{
+		sourceStart: sourceStart,
+		alignedAt:   alignedAt,
+		startedAt:   replayStart,
+	}
+}
+
+// dayStart returns the time the given day of the replay starts replaying from the start of the recording
+func (a *dailyAlignment) dayStart(day int) time.Time {
+	return a.alignedAt.AddDate(0, 0, day)
+}
+
+// target returns the wall-clock time to replay the Event with the given event time on the given day of the replay,
+// or false if the Event isn't replayed on that day, either because it is past the first 24 hours of the recording
+// or because its time-of-day had already passed when the replay started.
+func (a *dailyAlignment) target(eventTime int64, replayDay int) (time.Time, bool) {
+	offset := time.Duration(eventTime - a.sourceStart)
+	if offset >= oneDay {
+		return time.Time{}, false
+	}
+
+	target := a.dayStart(replayDay).Add(offset)
+	if target.Before(a.startedAt) {
+		return time.Time{}, false
+	}
+
+	return target, true
+}
+
+// sleepUntil sleeps until the given time, waking at least every maximum replay delay to check if the replay has
+// been stopped, since the wait of a daily replay can span hours. Returns false if the replay was stopped.
+func (m *dataManager) sleepUntil(until time.Time, lc logger.LoggingClient) bool {
+	for {
+		wait := until.Sub(m.clock.Now())
+		if wait <= 0 {
+			return true
+		}
+
+		if m.maxReplayDelay > 0 && wait > m.maxReplayDelay {
+			wait = m.maxReplayDelay
+		}
+
+		m.clock.Sleep(wait)
+
+		if m.replayStopped(lc) {
+			return false
+		}
+	}
+}
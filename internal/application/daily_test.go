@@ -0,0 +1,200 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var dailyRecordingStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func dailyEvents(offsets ...time.Duration) []coreDtos.Event {
+	var events []coreDtos.Event
+	for _, offset := range offsets {
+		event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+		event.Origin = dailyRecordingStart.Add(offset).UnixNano()
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestNewDailyAlignment(t *testing.T) {
+	events := newEventStore(dailyEvents(6*time.Hour, 12*time.Hour))
+
+	tests := []struct {
+		Name              string
+		Request           dtos.ReplayRequest
+		Events            *eventStore
+		ReplayStart       time.Time
+		ExpectedNil       bool
+		ExpectedAlignedAt time.Time
+	}{
+		{"Not aligned", dtos.ReplayRequest{ReplayRate: 1}, events, time.Now(), true, time.Time{}},
+		{"No events", dtos.ReplayRequest{AlignTimeOfDay: true}, newEventStore(nil), time.Now(), true, time.Time{}},
+		{"Aligned today", dtos.ReplayRequest{AlignTimeOfDay: true}, events,
+			time.Date(2026, 3, 10, 9, 30, 0, 0, time.UTC), false, time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)},
+		{"Aligned yesterday", dtos.ReplayRequest{AlignTimeOfDay: true}, events,
+			time.Date(2026, 3, 10, 5, 0, 0, 0, time.UTC), false, time.Date(2026, 3, 9, 6, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			daily := newDailyAlignment(test.Request, test.Events, nil, test.ReplayStart)
+			if test.ExpectedNil {
+				assert.Nil(t, daily)
+				return
+			}
+
+			require.NotNil(t, daily)
+			assert.Equal(t, test.ExpectedAlignedAt, daily.alignedAt)
+		})
+	}
+}
+
+func TestDailyAlignment_Target(t *testing.T) {
+	replayStart := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	daily := newDailyAlignment(dtos.ReplayRequest{AlignTimeOfDay: true}, newEventStore(dailyEvents(0)), nil, replayStart)
+	require.NotNil(t, daily)
+
+	tests := []struct {
+		Name           string
+		Offset         time.Duration
+		Day            int
+		ExpectedOK     bool
+		ExpectedTarget time.Time
+	}{
+		{"Already passed today", 6 * time.Hour, 0, false, time.Time{}},
+		{"Later today", 12 * time.Hour, 0, true, time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)},
+		{"Tomorrow", 6 * time.Hour, 1, true, time.Date(2026, 3, 11, 6, 0, 0, 0, time.UTC)},
+		{"Past first 24 hours", 30 * time.Hour, 1, false, time.Time{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, ok := daily.target(dailyRecordingStart.Add(test.Offset).UnixNano(), test.Day)
+			require.Equal(t, test.ExpectedOK, ok)
+			assert.Equal(t, test.ExpectedTarget, target)
+		})
+	}
+}
+
+func newDailyReplayTarget(virtualClock interfaces.VirtualClock, origins *[]int64) *dataManager {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).
+		Run(func(args mock.Arguments) {
+			*origins = append(*origins, args.Get(1).(requests.AddEventRequest).Event.Origin)
+		}).
+		Return(nil)
+
+	target := NewManager(mockSdk, time.Hour, virtualClock, nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events:  newEventStore(dailyEvents(0, 6*time.Hour, 12*time.Hour, 18*time.Hour)),
+		Devices: map[string]*coreDtos.Device{expectedDeviceName: {Name: expectedDeviceName}},
+	}
+
+	return target
+}
+
+func TestDataManager_StartReplay_AlignTimeOfDay(t *testing.T) {
+	var origins []int64
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	virtualClock := clock.NewVirtual(start)
+	target := newDailyReplayTarget(virtualClock, &origins)
+
+	err := target.StartReplay(dtos.ReplayRequest{AlignTimeOfDay: true, RepeatCount: 2})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		virtualClock.Advance(time.Hour)
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, time.Millisecond)
+
+	require.NoError(t, target.replayError)
+	assert.Equal(t, 2, target.ReplayStatus().RepeatCount)
+
+	// The Events before 09:00 are skipped on the first day and replayed at their time-of-day on the second
+	expected := []int64{
+		time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC).UnixNano(),
+		time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC).UnixNano(),
+		time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC).UnixNano(),
+		time.Date(2026, 3, 11, 6, 0, 0, 0, time.UTC).UnixNano(),
+		time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC).UnixNano(),
+		time.Date(2026, 3, 11, 18, 0, 0, 0, time.UTC).UnixNano(),
+	}
+	assert.Equal(t, expected, origins)
+}
+
+func TestDataManager_StartReplay_AlignTimeOfDay_Loop(t *testing.T) {
+	var origins []int64
+	virtualClock := clock.NewVirtual(time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC))
+	target := newDailyReplayTarget(virtualClock, &origins)
+
+	err := target.StartReplay(dtos.ReplayRequest{AlignTimeOfDay: true})
+	require.NoError(t, err)
+
+	// Without a RepeatCount the replay keeps looping daily until canceled
+	require.Eventually(t, func() bool {
+		virtualClock.Advance(time.Hour)
+		return target.ReplayStatus().RepeatCount >= 3
+	}, 5*time.Second, time.Millisecond)
+	assert.True(t, target.ReplayStatus().Running)
+
+	require.NoError(t, target.CancelReplay())
+	assert.False(t, target.ReplayStatus().Running)
+
+	// Wake the replay so it sees the cancel and exits
+	virtualClock.Advance(time.Hour)
+}
+
+func TestDataManager_StartReplay_AlignTimeOfDayErrors(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Request dtos.ReplayRequest
+	}{
+		{"Replay rate set", dtos.ReplayRequest{AlignTimeOfDay: true, ReplayRate: 1}},
+		{"Time warp set", dtos.ReplayRequest{AlignTimeOfDay: true, TimeWarpDuration: time.Hour}},
+		{"Priorities set", dtos.ReplayRequest{AlignTimeOfDay: true, DevicePriorities: map[string]int{"D1": 1}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), nil, nil).(*dataManager)
+			target.recordedData = &recordedData{Events: newEventStore(dailyEvents(0))}
+
+			err := target.StartReplay(test.Request)
+			require.ErrorIs(t, err, dailyReplayOptionsError)
+		})
+	}
+}
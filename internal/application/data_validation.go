@@ -0,0 +1,152 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+)
+
+// maxReportedInvalidEvents is the number of invalid Events listed in a validation report, so the report of a
+// corrupt recording stays a manageable size
+const maxReportedInvalidEvents = 1000
+
+var validateOpaqueError = errors.New("opaque messages can't be validated since they aren't decoded")
+
+// ValidateRecordedData checks every recorded Event against the EdgeX contract validation rules and, if recorded,
+// the Device Profiles and Devices, returning the errors of each invalid Event. An error is returned if there is
+// no recorded data or it is opaque.
+func (m *dataManager) ValidateRecordedData() (*dtos.ValidationReport, error) {
+	m.recordingMutex.Lock()
+	data := m.recordedData
+	m.recordingMutex.Unlock()
+
+	if data == nil {
+		return nil, noRecordedData
+	}
+
+	if len(data.Messages) > 0 {
+		return nil, validateOpaqueError
+	}
+
+	events := data.Events
+	report := &dtos.ValidationReport{
+		Valid:           true,
+		EventCount:      events.len(),
+		ProfilesChecked: len(data.Profiles) > 0,
+	}
+
+	for index := range events.len() {
+		event := events.event(index)
+		errs := validateRecordedEvent(event, data.Devices, data.Profiles)
+		if len(errs) == 0 {
+			continue
+		}
+
+		report.Valid = false
+		report.InvalidEventCount++
+		if len(report.InvalidEvents) == maxReportedInvalidEvents {
+			report.Truncated = true
+			continue
+		}
+
+		report.InvalidEvents = append(report.InvalidEvents, dtos.EventValidation{
+			Index:      index,
+			Id:         events.id(index),
+			DeviceName: event.DeviceName,
+			Errors:     errs,
+		})
+	}
+
+	m.appSvc.LoggingClient().Debugf("ARR Validate: %d of %d events failed validation",
+		report.InvalidEventCount, report.EventCount)
+
+	return report, nil
+}
+
+// validateRecordedEvent returns the validation errors of the Event. The Devices and Profiles are only checked
+// against if set.
+func validateRecordedEvent(event coreDtos.Event, devices map[string]*coreDtos.Device,
+	profiles map[string]*coreDtos.DeviceProfile) []string {
+	var errs []string
+
+	// Readings-only recordings drop the Ids and API version, which are restored when replayed
+	restoreEvent(&event)
+	if err := requests.NewAddEventRequest(event).Validate(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	for index, reading := range event.Readings {
+		if reading.DeviceName != event.DeviceName || reading.ProfileName != event.ProfileName {
+			errs = append(errs, fmt.Sprintf("reading %d for %s has device %s and profile %s which differ from the event's",
+				index, reading.ResourceName, reading.DeviceName, reading.ProfileName))
+		}
+	}
+
+	if device, found := devices[event.DeviceName]; found && device.ProfileName != event.ProfileName {
+		errs = append(errs, fmt.Sprintf("device %s has profile %s rather than the event's profile %s",
+			event.DeviceName, device.ProfileName, event.ProfileName))
+	}
+
+	if len(profiles) == 0 {
+		return errs
+	}
+
+	profile, found := profiles[event.ProfileName]
+	if !found {
+		return append(errs, fmt.Sprintf("device profile %s is not in the recorded data", event.ProfileName))
+	}
+
+	resources := make(map[string]coreDtos.DeviceResource, len(profile.DeviceResources))
+	for _, resource := range profile.DeviceResources {
+		resources[resource.Name] = resource
+	}
+
+	if _, isResource := resources[event.SourceName]; !isResource && !hasDeviceCommand(profile, event.SourceName) {
+		errs = append(errs, fmt.Sprintf("source %s is not a resource or command of device profile %s",
+			event.SourceName, profile.Name))
+	}
+
+	for _, reading := range event.Readings {
+		resource, found := resources[reading.ResourceName]
+		if !found {
+			errs = append(errs, fmt.Sprintf("resource %s is not in device profile %s", reading.ResourceName, profile.Name))
+			continue
+		}
+
+		if reading.ValueType != resource.Properties.ValueType {
+			errs = append(errs, fmt.Sprintf("reading for %s has value type %s rather than the profile's %s",
+				reading.ResourceName, reading.ValueType, resource.Properties.ValueType))
+		}
+	}
+
+	return errs
+}
+
+func hasDeviceCommand(profile *coreDtos.DeviceProfile, name string) bool {
+	for _, command := range profile.DeviceCommands {
+		if command.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,138 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_ValidateRecordedData(t *testing.T) {
+	newEvent := func(resourceName string, valueType string, value any) coreDtos.Event {
+		event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+		_ = event.AddSimpleReading(resourceName, valueType, value)
+		return event
+	}
+
+	profile := &coreDtos.DeviceProfile{
+		DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: expectedProfileName},
+		DeviceResources: []coreDtos.DeviceResource{
+			{Name: expectedSourceName, Properties: coreDtos.ResourceProperties{ValueType: common.ValueTypeInt32}},
+		},
+	}
+	profiles := map[string]*coreDtos.DeviceProfile{expectedProfileName: profile}
+	devices := map[string]*coreDtos.Device{
+		expectedDeviceName: {Name: expectedDeviceName, ProfileName: expectedProfileName},
+	}
+
+	noSourceName := newEvent(expectedSourceName, common.ValueTypeInt32, int32(1))
+	noSourceName.SourceName = ""
+
+	otherDevice := newEvent(expectedSourceName, common.ValueTypeInt32, int32(1))
+	otherDevice.Readings[0].DeviceName = "otherDevice"
+
+	otherSource := newEvent(expectedSourceName, common.ValueTypeInt32, int32(1))
+	otherSource.SourceName = "otherSource"
+
+	tests := []struct {
+		Name           string
+		Event          coreDtos.Event
+		Devices        map[string]*coreDtos.Device
+		Profiles       map[string]*coreDtos.DeviceProfile
+		ExpectedErrors []string
+	}{
+		{"Valid", newEvent(expectedSourceName, common.ValueTypeInt32, int32(1)), devices, profiles, nil},
+		{"Valid without profiles", newEvent("unknown", common.ValueTypeString, "on"), nil, nil, nil},
+		{"Valid readings-only", stripEvent(newEvent(expectedSourceName, common.ValueTypeInt32, int32(1))), devices, profiles, nil},
+		{"Contract violation", noSourceName, nil, nil, []string{"SourceName"}},
+		{"Reading device differs", otherDevice, nil, nil, []string{"differ from the event's"}},
+		{"Device profile differs", newEvent(expectedSourceName, common.ValueTypeInt32, int32(1)),
+			map[string]*coreDtos.Device{expectedDeviceName: {Name: expectedDeviceName, ProfileName: "otherProfile"}},
+			nil, []string{"rather than the event's profile"}},
+		{"Profile missing", newEvent(expectedSourceName, common.ValueTypeInt32, int32(1)), devices,
+			map[string]*coreDtos.DeviceProfile{"otherProfile": profile}, []string{"is not in the recorded data"}},
+		{"Unknown source", otherSource, devices, profiles, []string{"is not a resource or command"}},
+		{"Unknown resource", newEvent("unknown", common.ValueTypeInt32, int32(1)), devices, profiles,
+			[]string{"resource unknown is not in device profile"}},
+		{"Wrong value type", newEvent(expectedSourceName, common.ValueTypeFloat64, 1.5), devices, profiles,
+			[]string{"has value type Float64 rather than the profile's Int32"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			errs := validateRecordedEvent(test.Event, test.Devices, test.Profiles)
+			require.Len(t, errs, len(test.ExpectedErrors))
+			for index, expected := range test.ExpectedErrors {
+				assert.Contains(t, errs[index], expected)
+			}
+		})
+	}
+}
+
+func TestDataManager_ValidateRecordedData_Report(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	_, err := target.ValidateRecordedData()
+	require.Equal(t, noRecordedData, err)
+
+	target.recordedData = &recordedData{Messages: []dtos.OpaqueMessage{{}}}
+	_, err = target.ValidateRecordedData()
+	require.Equal(t, validateOpaqueError, err)
+
+	validEvent := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	_ = validEvent.AddSimpleReading(expectedSourceName, common.ValueTypeInt32, int32(1))
+	invalidEvent := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, "")
+
+	target.recordedData = &recordedData{Events: newEventStore([]coreDtos.Event{validEvent, invalidEvent, validEvent})}
+	report, err := target.ValidateRecordedData()
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, 3, report.EventCount)
+	assert.Equal(t, 1, report.InvalidEventCount)
+	assert.False(t, report.ProfilesChecked)
+	assert.False(t, report.Truncated)
+	require.Len(t, report.InvalidEvents, 1)
+	assert.Equal(t, 1, report.InvalidEvents[0].Index)
+	assert.Equal(t, invalidEvent.Id, report.InvalidEvents[0].Id)
+	assert.Equal(t, expectedDeviceName, report.InvalidEvents[0].DeviceName)
+	assert.NotEmpty(t, report.InvalidEvents[0].Errors)
+
+	invalidEvents := make([]coreDtos.Event, maxReportedInvalidEvents+1)
+	for index := range invalidEvents {
+		invalidEvents[index] = coreDtos.NewEvent(expectedProfileName, fmt.Sprintf("device-%d", index), "")
+	}
+
+	target.recordedData = &recordedData{Events: newEventStore(invalidEvents)}
+	report, err = target.ValidateRecordedData()
+	require.NoError(t, err)
+	assert.Equal(t, maxReportedInvalidEvents+1, report.InvalidEventCount)
+	assert.Len(t, report.InvalidEvents, maxReportedInvalidEvents)
+	assert.True(t, report.Truncated)
+}
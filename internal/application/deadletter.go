@@ -0,0 +1,59 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+// maxDeadLetters is the maximum number of dead letters captured per recording, so a misbehaving publisher can't
+// exhaust memory. Further messages that fail to decode are only logged.
+const maxDeadLetters = 1000
+
+// captureDeadLetter captures the raw message that failed to decode as an Event, along with its envelope metadata
+// and the decode error, into the dead letters of the current recording.
+func (m *dataManager) captureDeadLetter(ctx appInterfaces.AppFunctionContext, payload []byte, err error) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.recordingStartedAt == nil {
+		return
+	}
+
+	lc := m.sessionLogger(m.recordingLabel, m.recordingCorrelationID)
+
+	if len(m.recordedDeadLetters) >= maxDeadLetters {
+		lc.Debugf("ARR Dead Letter: dead letter limit of %d reached, message not captured: %v", maxDeadLetters, err)
+		return
+	}
+
+	receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+	m.recordedDeadLetters = append(m.recordedDeadLetters, dtos.DeadLetter{
+		EnvelopeMetadata: dtos.EnvelopeMetadata{
+			ReceivedTopic: receivedTopic,
+			CorrelationID: ctx.CorrelationID(),
+			ContentType:   ctx.InputContentType(),
+			ReceivedAt:    m.clock.Now().UnixNano(),
+		},
+		// The payload is copied since the SDK may reuse it
+		Payload: append([]byte(nil), payload...),
+		Error:   err.Error(),
+	})
+
+	lc.Debugf("ARR Dead Letter: captured message received on topic '%s' that failed to decode: %v", receivedTopic, err)
+}
@@ -0,0 +1,103 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_CaptureDeadLetter(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("RemoveAllFunctionPipelines")
+	mockSdk.On("NotificationClient").Return(nil)
+
+	mockContext := &mocks.AppFunctionContext{}
+	mockContext.On("GetValue", interfaces.RECEIVEDTOPIC).Return("edgex/events/device/bad", true)
+	mockContext.On("CorrelationID").Return("123")
+	mockContext.On("InputContentType").Return(common.ContentTypeJSON)
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+
+	// Messages received when not recording aren't captured
+	continuePipeline, _ := target.decodeEvent(mockContext, []byte("bad"))
+	require.False(t, continuePipeline)
+	require.Empty(t, target.recordedDeadLetters)
+
+	now := time.Now()
+	target.recordingStartedAt = &now
+
+	continuePipeline, result := target.decodeEvent(mockContext, []byte("bad"))
+	require.False(t, continuePipeline)
+	require.Error(t, result.(error))
+
+	require.Len(t, target.recordedDeadLetters, 1)
+	actual := target.recordedDeadLetters[0]
+	assert.Equal(t, "edgex/events/device/bad", actual.ReceivedTopic)
+	assert.Equal(t, "123", actual.CorrelationID)
+	assert.Equal(t, common.ContentTypeJSON, actual.ContentType)
+	assert.NotZero(t, actual.ReceivedAt)
+	assert.Equal(t, []byte("bad"), actual.Payload)
+	assert.NotEmpty(t, actual.Error)
+	assert.Equal(t, 1, target.RecordingStatus().DeadLetterCount)
+
+	// The dead letters are kept with the recorded data once the recording completes
+	continuePipeline, _ = target.processBatchedData(mockContext, []coreDtos.Event{coreDtos.NewEvent("p", "d", "s")})
+	require.False(t, continuePipeline)
+	require.NotNil(t, target.recordedData)
+	assert.Len(t, target.recordedData.DeadLetters, 1)
+	assert.Nil(t, target.recordedDeadLetters)
+	assert.Equal(t, 1, target.RecordingStatus().DeadLetterCount)
+
+	// Avoids loading the Devices and Device Profiles from Core Metadata
+	target.recordedData.Devices = map[string]*coreDtos.Device{"d": {Name: "d", ProfileName: "p"}}
+	target.recordedData.Profiles = map[string]*coreDtos.DeviceProfile{"p": {}}
+
+	exported, err := target.ExportRecordedData()
+	require.NoError(t, err)
+	assert.Equal(t, target.recordedData.DeadLetters, exported.DeadLetters)
+}
+
+func TestDataManager_CaptureDeadLetter_Limit(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	mockContext := &mocks.AppFunctionContext{}
+	mockContext.On("GetValue", interfaces.RECEIVEDTOPIC).Return("edgex/events/device/bad", true)
+	mockContext.On("CorrelationID").Return("123")
+	mockContext.On("InputContentType").Return(common.ContentTypeJSON)
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+	now := time.Now()
+	target.recordingStartedAt = &now
+
+	for i := 0; i < maxDeadLetters+10; i++ {
+		target.decodeEvent(mockContext, []byte("bad"))
+	}
+
+	assert.Len(t, target.recordedDeadLetters, maxDeadLetters)
+}
@@ -0,0 +1,113 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+var (
+	invalidDeleteRangeError = errors.New("delete range end must be after its start")
+	deleteOpaqueError       = errors.New("opaque recordings can't have Events deleted since their messages aren't decoded")
+)
+
+// DeleteRecordedEvents deletes the recorded Events with Origins from the start up to, but not including, the end,
+// limited to the device when the device name is set, along with their Envelopes. The rest of the recorded data is
+// kept as is. An error is returned if the range is empty, there is no recorded data, it is opaque, or it can't be
+// replaced.
+func (m *dataManager) DeleteRecordedEvents(start int64, end int64, deviceName string) (*dtos.DeleteEventsResult, error) {
+	if end <= start {
+		return nil, invalidDeleteRangeError
+	}
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.recordingStartedAt != nil {
+		return nil, recordingInProgressError
+	}
+
+	if m.replayStartedAt != nil {
+		return nil, replayInProgressError
+	}
+
+	if m.recordedDataLocked {
+		return nil, recordedDataLockedError
+	}
+
+	existing := m.recordedData
+	if existing == nil {
+		return nil, noRecordedData
+	}
+
+	if len(existing.Messages) > 0 {
+		return nil, deleteOpaqueError
+	}
+
+	var kept []coreDtos.Event
+	var envelopes map[string]dtos.EnvelopeMetadata
+	if existing.Envelopes != nil {
+		envelopes = make(map[string]dtos.EnvelopeMetadata, len(existing.Envelopes))
+	}
+
+	for index := range existing.Events.len() {
+		event := existing.Events.event(index)
+		if event.Origin >= start && event.Origin < end && (len(deviceName) == 0 || event.DeviceName == deviceName) {
+			continue
+		}
+
+		kept = append(kept, event)
+		if envelope, ok := existing.Envelopes[event.Id]; ok {
+			envelopes[event.Id] = envelope
+		}
+	}
+
+	result := &dtos.DeleteEventsResult{
+		DeletedEventCount:   existing.Events.len() - len(kept),
+		RemainingEventCount: len(kept),
+	}
+
+	if result.DeletedEventCount == 0 {
+		return result, nil
+	}
+
+	// The recorded data is replaced rather than changed in place, since it may be in use outside the lock
+	m.recordedData = &recordedData{
+		Name:         existing.Name,
+		Label:        existing.Label,
+		Duration:     existing.Duration,
+		Events:       newEventStore(kept),
+		Devices:      existing.Devices,
+		Profiles:     existing.Profiles,
+		Envelopes:    envelopes,
+		DeadLetters:  existing.DeadLetters,
+		Telemetry:    existing.Telemetry,
+		SystemEvents: existing.SystemEvents,
+		Annotations:  existing.Annotations,
+		Metadata:     existing.Metadata,
+	}
+
+	m.appSvc.LoggingClient().Debugf("ARR Delete: Deleted %d events from %s to %s for device '%s', now %d events",
+		result.DeletedEventCount, time.Unix(0, start).UTC().Format(time.RFC3339Nano),
+		time.Unix(0, end).UTC().Format(time.RFC3339Nano), deviceName, result.RemainingEventCount)
+
+	return result, nil
+}
@@ -0,0 +1,106 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_DeleteRecordedEvents(t *testing.T) {
+	d1Before := newAppendEvent("D1", 10*time.Second)
+	d1During := newAppendEvent("D1", 20*time.Second)
+	d2During := newAppendEvent("D2", 25*time.Second)
+	d1End := newAppendEvent("D1", 30*time.Second)
+	all := []coreDtos.Event{d1Before, d1During, d2During, d1End}
+
+	tests := []struct {
+		Name              string
+		DeviceName        string
+		ExpectedDeleted   int
+		ExpectedRemaining []coreDtos.Event
+		ExpectedEnvelopes int
+	}{
+		{"All devices", "", 2, []coreDtos.Event{d1Before, d1End}, 1},
+		{"One device", "D1", 1, []coreDtos.Event{d1Before, d2During, d1End}, 1},
+		{"Unknown device", "D3", 0, all, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := createAppendTarget()
+			existing := &recordedData{
+				Name:      "scenario",
+				Events:    newEventStore(all),
+				Envelopes: map[string]dtos.EnvelopeMetadata{d1Before.Id: {CorrelationID: "1"}, d1During.Id: {CorrelationID: "2"}},
+				Telemetry: []dtos.OpaqueMessage{{Payload: []byte("metric")}},
+			}
+			target.recordedData = existing
+
+			result, err := target.DeleteRecordedEvents(int64(20*time.Second), int64(30*time.Second), test.DeviceName)
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedDeleted, result.DeletedEventCount)
+			assert.Equal(t, len(test.ExpectedRemaining), result.RemainingEventCount)
+
+			data := target.recordedData
+			assert.Equal(t, "scenario", data.Name)
+			assert.Equal(t, existing.Telemetry, data.Telemetry)
+			assert.Equal(t, test.ExpectedRemaining, data.Events.events())
+			assert.Len(t, data.Envelopes, test.ExpectedEnvelopes)
+			assert.Contains(t, data.Envelopes, d1Before.Id)
+		})
+	}
+}
+
+func TestDataManager_DeleteRecordedEvents_Errors(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		Name          string
+		Existing      *recordedData
+		Start         int64
+		Locked        bool
+		Recording     bool
+		ExpectedError error
+	}{
+		{"Empty range", &recordedData{}, 100, false, false, invalidDeleteRangeError},
+		{"No recorded data", nil, 0, false, false, noRecordedData},
+		{"Opaque", &recordedData{Messages: []dtos.OpaqueMessage{{}}}, 0, false, false, deleteOpaqueError},
+		{"Locked", &recordedData{}, 0, true, false, recordedDataLockedError},
+		{"Recording", &recordedData{}, 0, false, true, recordingInProgressError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := createAppendTarget()
+			target.recordedData = test.Existing
+			target.recordedDataLocked = test.Locked
+			if test.Recording {
+				target.recordingStartedAt = &now
+			}
+
+			_, err := target.DeleteRecordedEvents(test.Start, 100, "")
+			require.Equal(t, test.ExpectedError, err)
+			assert.Equal(t, test.Existing, target.recordedData)
+		})
+	}
+}
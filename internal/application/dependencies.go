@@ -0,0 +1,148 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+// MissingDependencies returns the Device Profiles and Device Services which don't exist in Core Metadata, so an
+// import can report all of its missing dependencies before Core Metadata is changed.
+// An error is returned if Core Metadata can't be checked
+func (m *dataManager) MissingDependencies(profiles []string, deviceServices []string) (*dtos.MissingDependencies, error) {
+	missing := &dtos.MissingDependencies{}
+
+	for _, profileName := range profiles {
+		_, err := m.appSvc.DeviceProfileClient().DeviceProfileByName(context.Background(), profileName)
+		if err != nil && err.Code() == http.StatusNotFound {
+			missing.Profiles = append(missing.Profiles, profileName)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check if device profile %s exists: %w", profileName, err)
+		}
+	}
+
+	for _, serviceName := range deviceServices {
+		_, err := m.appSvc.DeviceServiceClient().DeviceServiceByName(context.Background(), serviceName)
+		if err != nil && err.Code() == http.StatusNotFound {
+			missing.DeviceServices = append(missing.DeviceServices, serviceName)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check if device service %s exists: %w", serviceName, err)
+		}
+	}
+
+	return missing, nil
+}
+
+// DependencyGraph returns the Device Profile and Device Service each recorded Device depends on, and which of them
+// are missing from Core Metadata. Devices only referenced by the recorded Events are included with the Events'
+// Device Profile. An error is returned if there is no recorded data or Core Metadata can't be checked
+func (m *dataManager) DependencyGraph() (*dtos.DependencyGraph, error) {
+	m.recordingMutex.Lock()
+	data := m.recordedData
+	m.recordingMutex.Unlock()
+
+	if data == nil {
+		return nil, noRecordedData
+	}
+
+	devices := make(map[string]dtos.DeviceDependencies)
+	for name, device := range data.Devices {
+		devices[name] = dtos.DeviceDependencies{
+			DeviceName:  name,
+			ProfileName: device.ProfileName,
+			ServiceName: device.ServiceName,
+			Recorded:    true,
+		}
+	}
+
+	for index := range data.Events.len() {
+		name := data.Events.deviceNames[index]
+		if _, found := devices[name]; !found {
+			devices[name] = dtos.DeviceDependencies{DeviceName: name, ProfileName: data.Events.profileNames[index]}
+		}
+	}
+
+	graph := &dtos.DependencyGraph{
+		Devices:        make([]dtos.DeviceDependencies, 0, len(devices)),
+		Profiles:       []dtos.ProfileDependency{},
+		DeviceServices: []dtos.DeviceServiceDependency{},
+	}
+
+	for _, device := range devices {
+		graph.Devices = append(graph.Devices, device)
+	}
+	sort.Slice(graph.Devices, func(i, j int) bool { return graph.Devices[i].DeviceName < graph.Devices[j].DeviceName })
+
+	// The Devices are sorted, so the names of those using each profile and service are too
+	profileDevices := make(map[string][]string)
+	serviceDevices := make(map[string][]string)
+	for _, device := range graph.Devices {
+		profileDevices[device.ProfileName] = append(profileDevices[device.ProfileName], device.DeviceName)
+		if len(device.ServiceName) > 0 {
+			serviceDevices[device.ServiceName] = append(serviceDevices[device.ServiceName], device.DeviceName)
+		}
+	}
+
+	profiles := sortedKeys(profileDevices)
+	services := sortedKeys(serviceDevices)
+	missing, err := m.MissingDependencies(profiles, services)
+	if err != nil {
+		return nil, err
+	}
+	graph.Missing = *missing
+
+	for _, name := range profiles {
+		_, recorded := data.Profiles[name]
+		graph.Profiles = append(graph.Profiles, dtos.ProfileDependency{
+			Name:        name,
+			DeviceNames: profileDevices[name],
+			Recorded:    recorded,
+			Missing:     slices.Contains(missing.Profiles, name),
+		})
+	}
+
+	for _, name := range services {
+		graph.DeviceServices = append(graph.DeviceServices, dtos.DeviceServiceDependency{
+			Name:        name,
+			DeviceNames: serviceDevices[name],
+			Missing:     slices.Contains(missing.DeviceServices, name),
+		})
+	}
+
+	return graph, nil
+}
+
+// sortedKeys returns the keys of the map in order
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
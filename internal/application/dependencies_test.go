@@ -0,0 +1,129 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_MissingDependencies(t *testing.T) {
+	notFound := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "not found", nil)
+	serverError := edgexErr.NewCommonEdgeX(edgexErr.KindServerError, "failed", nil)
+
+	tests := []struct {
+		Name          string
+		ProfileError  edgexErr.EdgeX
+		ServiceError  edgexErr.EdgeX
+		Expected      *dtos.MissingDependencies
+		ExpectedError bool
+	}{
+		{"None missing", nil, nil, &dtos.MissingDependencies{}, false},
+		{"Missing", notFound, notFound, &dtos.MissingDependencies{Profiles: []string{"p2"}, DeviceServices: []string{"s1"}}, false},
+		{"Profile check failed", serverError, nil, nil, true},
+		{"Service check failed", nil, serverError, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockProfileClient := &clientMocks.DeviceProfileClient{}
+			mockProfileClient.On("DeviceProfileByName", mock.Anything, "p1").Return(responses.DeviceProfileResponse{}, nil)
+			mockProfileClient.On("DeviceProfileByName", mock.Anything, "p2").Return(responses.DeviceProfileResponse{}, test.ProfileError)
+
+			mockServiceClient := &clientMocks.DeviceServiceClient{}
+			mockServiceClient.On("DeviceServiceByName", mock.Anything, "s1").Return(responses.DeviceServiceResponse{}, test.ServiceError)
+
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+			mockSdk.On("DeviceServiceClient").Return(mockServiceClient)
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil)
+			actual, err := target.MissingDependencies([]string{"p1", "p2"}, []string{"s1"})
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestDataManager_DependencyGraph(t *testing.T) {
+	notFound := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "not found", nil)
+
+	mockProfileClient := &clientMocks.DeviceProfileClient{}
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, "p1").Return(responses.DeviceProfileResponse{}, nil)
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, "p2").Return(responses.DeviceProfileResponse{}, notFound)
+
+	mockServiceClient := &clientMocks.DeviceServiceClient{}
+	mockServiceClient.On("DeviceServiceByName", mock.Anything, "s1").Return(responses.DeviceServiceResponse{}, notFound)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+	mockSdk.On("DeviceServiceClient").Return(mockServiceClient)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	_, err := target.DependencyGraph()
+	require.ErrorIs(t, err, noRecordedData)
+
+	// d3 isn't recorded, so only the profile of its Events is known
+	target.recordedData = &recordedData{
+		Events: newEventStore([]coreDtos.Event{
+			coreDtos.NewEvent("p2", "d3", "source"),
+			coreDtos.NewEvent("p1", "d1", "source"),
+		}),
+		Devices: map[string]*coreDtos.Device{
+			"d2": {Name: "d2", ProfileName: "p1", ServiceName: "s1"},
+			"d1": {Name: "d1", ProfileName: "p1", ServiceName: "s1"},
+		},
+		Profiles: map[string]*coreDtos.DeviceProfile{
+			"p1": {DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "p1"}},
+		},
+	}
+
+	graph, err := target.DependencyGraph()
+	require.NoError(t, err)
+	assert.Equal(t, &dtos.DependencyGraph{
+		Devices: []dtos.DeviceDependencies{
+			{DeviceName: "d1", ProfileName: "p1", ServiceName: "s1", Recorded: true},
+			{DeviceName: "d2", ProfileName: "p1", ServiceName: "s1", Recorded: true},
+			{DeviceName: "d3", ProfileName: "p2"},
+		},
+		Profiles: []dtos.ProfileDependency{
+			{Name: "p1", DeviceNames: []string{"d1", "d2"}, Recorded: true},
+			{Name: "p2", DeviceNames: []string{"d3"}, Missing: true},
+		},
+		DeviceServices: []dtos.DeviceServiceDependency{
+			{Name: "s1", DeviceNames: []string{"d1", "d2"}, Missing: true},
+		},
+		Missing: dtos.MissingDependencies{Profiles: []string{"p2"}, DeviceServices: []string{"s1"}},
+	}, graph)
+}
@@ -0,0 +1,91 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/google/uuid"
+)
+
+// defaultFanOutOffset is the default offset between the Origins of the clones of a fanned out Event, which keeps
+// them distinct
+const defaultFanOutOffset = time.Millisecond
+
+var invalidFanOut = errors.New("invalid FanOut, value must be equal or greater than 0 and FanOutOffset must not be negative")
+
+// replayedEvent is an Event along with the topic, relative to the base topic, it is replayed to
+type replayedEvent struct {
+	event coreDtos.Event
+	topic string
+}
+
+// cloneDeviceName returns the name of the clone, numbered from 1, of the device
+func cloneDeviceName(deviceName string, clone int) string {
+	return fmt.Sprintf("%s-%d", deviceName, clone)
+}
+
+// fanOut returns the Events to replay for the Event. Without FanOut that is the Event itself, otherwise it is the
+// Event's FanOut clones, each for a clone of the Event's device and with its Origins offset from the previous clone's
+// by the FanOutOffset, so a single device recording simulates the traffic of a fleet of devices.
+func fanOut(request dtos.ReplayRequest, event coreDtos.Event, topic string) []replayedEvent {
+	if request.FanOut == 0 {
+		return []replayedEvent{{event: event, topic: topic}}
+	}
+
+	offset := request.FanOutOffset
+	if offset == 0 {
+		offset = defaultFanOutOffset
+	}
+
+	clones := make([]replayedEvent, 0, request.FanOut)
+	for clone := 1; clone <= request.FanOut; clone++ {
+		cloneOffset := int64(clone-1) * int64(offset)
+
+		cloned := event
+		cloned.Id = uuid.NewString()
+		cloned.DeviceName = cloneDeviceName(event.DeviceName, clone)
+		cloned.Origin += cloneOffset
+		cloned.Readings = slices.Clone(event.Readings)
+		for index := range cloned.Readings {
+			cloned.Readings[index].Id = uuid.NewString()
+			cloned.Readings[index].DeviceName = cloned.DeviceName
+			cloned.Readings[index].Origin += cloneOffset
+		}
+
+		clones = append(clones, replayedEvent{event: cloned, topic: cloneEventTopic(topic, cloned)})
+	}
+
+	return clones
+}
+
+// cloneEventTopic returns the Event topic for the cloned Event, which is published under the same Device Service as
+// the original. Topics other than the Event topics built by Device Services are used as they are.
+func cloneEventTopic(topic string, cloned coreDtos.Event) string {
+	if !strings.HasPrefix(topic, strings.Replace(common.CoreDataEventSubscribeTopic, "#", "", 1)) {
+		return topic
+	}
+
+	return buildEventTopic(eventTopicServiceName(topic), cloned)
+}
@@ -0,0 +1,88 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanOut(t *testing.T) {
+	event := coreDtos.NewEvent(expectedProfileName, "device-A", expectedSourceName)
+	require.NoError(t, event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21)))
+	event.Readings[0].Origin = event.Origin
+	topic := buildEventTopic("device-virtual", event)
+
+	// Without FanOut the Event is replayed as it is
+	actual := fanOut(dtos.ReplayRequest{}, event, topic)
+	require.Len(t, actual, 1)
+	assert.Equal(t, event, actual[0].event)
+	assert.Equal(t, topic, actual[0].topic)
+
+	tests := []struct {
+		Name           string
+		Offset         time.Duration
+		ExpectedOffset time.Duration
+	}{
+		{"Default offset", 0, defaultFanOutOffset},
+		{"Offset", time.Second, time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual := fanOut(dtos.ReplayRequest{FanOut: 3, FanOutOffset: test.Offset}, event, topic)
+			require.Len(t, actual, 3)
+
+			ids := map[string]bool{event.Id: true}
+			for index, clone := range actual {
+				deviceName := cloneDeviceName("device-A", index+1)
+				origin := event.Origin + int64(index)*int64(test.ExpectedOffset)
+
+				assert.Equal(t, deviceName, clone.event.DeviceName)
+				assert.Equal(t, origin, clone.event.Origin)
+				assert.Equal(t, buildEventTopic("device-virtual", clone.event), clone.topic)
+				assert.False(t, ids[clone.event.Id])
+				ids[clone.event.Id] = true
+
+				require.Len(t, clone.event.Readings, 1)
+				assert.Equal(t, deviceName, clone.event.Readings[0].DeviceName)
+				assert.Equal(t, origin, clone.event.Readings[0].Origin)
+				assert.NotEqual(t, event.Readings[0].Id, clone.event.Readings[0].Id)
+			}
+
+			assert.Equal(t, "device-A-1", actual[0].event.DeviceName)
+			assert.Equal(t, "device-A-3", actual[2].event.DeviceName)
+
+			// The original Event is left unchanged
+			assert.Equal(t, "device-A", event.Readings[0].DeviceName)
+		})
+	}
+}
+
+func TestCloneEventTopic(t *testing.T) {
+	cloned := coreDtos.NewEvent(expectedProfileName, "device-A-2", expectedSourceName)
+
+	assert.Equal(t, buildEventTopic("device-modbus", cloned),
+		cloneEventTopic("events/device/device-modbus/"+expectedProfileName+"/device-A/"+expectedSourceName, cloned))
+	assert.Equal(t, "events/custom/device-A", cloneEventTopic("events/custom/device-A", cloned))
+}
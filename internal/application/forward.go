@@ -0,0 +1,178 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/transforms"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+)
+
+var forwardUnavailableError = errors.New("ForwardTopic can't be used since background publishing is unavailable")
+var invalidForwardSinkType = fmt.Errorf("invalid ForwardSink, Type must be '%s' or '%s'", dtos.ReplaySinkMQTT, dtos.ReplaySinkHTTP)
+
+// recordForwarder relays the messages received while recording, unmodified, to the forward topic and sink, so the
+// service can act as a tap inline in a pipeline. The raw payloads are published with the background publisher,
+// since publishing them with the topic would marshal them.
+type recordForwarder struct {
+	topic     string
+	publisher appInterfaces.BackgroundPublisher
+
+	sinkName string
+	mqtt     *transforms.MQTTSecretSender
+	url      string
+
+	// HTTP senders are created per content type so the forwarded payloads are posted with their own content type
+	mutex       sync.Mutex
+	httpSenders map[string]*transforms.HTTPSender
+}
+
+// newRecordForwarder returns the forwarder for the request, or nil if the request doesn't forward.
+// Must be called while holding the recording mutex.
+func (m *dataManager) newRecordForwarder(request dtos.RecordRequest) (*recordForwarder, error) {
+	if len(request.ForwardTopic) == 0 && request.ForwardSink == nil {
+		return nil, nil
+	}
+
+	forwarder := &recordForwarder{
+		topic:       request.ForwardTopic,
+		httpSenders: make(map[string]*transforms.HTTPSender),
+	}
+
+	if len(forwarder.topic) > 0 {
+		if m.opaquePublisher == nil {
+			return nil, forwardUnavailableError
+		}
+
+		forwarder.publisher = m.opaquePublisher
+	}
+
+	if config := request.ForwardSink; config != nil {
+		forwarder.sinkName = config.Name
+		if len(forwarder.sinkName) == 0 {
+			forwarder.sinkName = config.Type
+		}
+
+		switch config.Type {
+		case dtos.ReplaySinkMQTT:
+			if len(config.BrokerAddress) == 0 || len(config.Topic) == 0 {
+				return nil, fmt.Errorf("forward sink %s: %w", forwarder.sinkName, invalidReplaySinkMQTT)
+			}
+
+			forwarder.mqtt = m.mqttSender(*config, forwarder.sinkName)
+		case dtos.ReplaySinkHTTP:
+			if len(config.URL) == 0 {
+				return nil, fmt.Errorf("forward sink %s: %w", forwarder.sinkName, invalidReplaySinkHTTP)
+			}
+
+			forwarder.url = config.URL
+		default:
+			return nil, invalidForwardSinkType
+		}
+	}
+
+	return forwarder, nil
+}
+
+// forwardTopic returns the topic to forward the message received on the topic to
+func (f *recordForwarder) forwardTopic(receivedTopic string) string {
+	if base, ok := strings.CutSuffix(f.topic, "/#"); ok {
+		return common.BuildTopic(base, relativeMessageTopic(receivedTopic))
+	}
+
+	return f.topic
+}
+
+// forward relays the raw payload to the forward topic and sink
+func (f *recordForwarder) forward(appSvc appInterfaces.ApplicationService, ctx appInterfaces.AppFunctionContext, payload []byte) error {
+	if f.publisher != nil {
+		receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+		publishCtx := appSvc.BuildContext(ctx.CorrelationID(), ctx.InputContentType())
+		publishCtx.AddValue(opaqueTopicKey, f.forwardTopic(receivedTopic))
+
+		if err := f.publisher.Publish(payload, publishCtx); err != nil {
+			return fmt.Errorf("forward topic: %w", err)
+		}
+	}
+
+	var send appInterfaces.AppFunction
+	if f.mqtt != nil {
+		send = f.mqtt.MQTTSend
+	} else if len(f.url) > 0 {
+		send = f.httpSender(ctx.InputContentType()).HTTPPost
+	}
+
+	if send == nil {
+		return nil
+	}
+
+	if ok, result := send(ctx, payload); !ok {
+		return fmt.Errorf("forward sink %s: %v", f.sinkName, result)
+	}
+
+	return nil
+}
+
+func (f *recordForwarder) httpSender(contentType string) *transforms.HTTPSender {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	sender, ok := f.httpSenders[contentType]
+	if !ok {
+		sender = transforms.NewHTTPSender(f.url, contentType, false)
+		f.httpSenders[contentType] = sender
+	}
+
+	return sender
+}
+
+// forwardMessage is the first function of a forwarding recording's pipeline. It forwards the raw message and
+// passes it on unchanged, so a failure to forward doesn't stop the message from being recorded.
+func (m *dataManager) forwardMessage(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	payload, ok := data.([]byte)
+	if !ok {
+		return false, decodeDataNotBytesError
+	}
+
+	m.recordingMutex.Lock()
+	forwarder := m.forwarder
+	m.recordingMutex.Unlock()
+
+	if forwarder == nil {
+		return true, data
+	}
+
+	err := forwarder.forward(m.appSvc, ctx, payload)
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if err != nil {
+		m.sessionLogger(m.recordingLabel, m.recordingCorrelationID).Warnf("ARR Record: Failed to forward message: %v", err)
+		m.forwardFailedCount++
+		return true, data
+	}
+
+	m.forwardedCount++
+	return true, data
+}
@@ -0,0 +1,187 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_NewRecordForwarder(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Request       dtos.RecordRequest
+		HasPublisher  bool
+		ExpectedNil   bool
+		ExpectedError error
+	}{
+		{"Not forwarding", dtos.RecordRequest{}, true, true, nil},
+		{"Topic", dtos.RecordRequest{ForwardTopic: "relay/#"}, true, false, nil},
+		{"Topic without publisher", dtos.RecordRequest{ForwardTopic: "relay/#"}, false, false, forwardUnavailableError},
+		{"HTTP sink", dtos.RecordRequest{ForwardSink: &dtos.ReplaySink{Type: dtos.ReplaySinkHTTP, URL: "http://localhost/relay"}}, false, false, nil},
+		{"MQTT sink", dtos.RecordRequest{ForwardSink: &dtos.ReplaySink{Type: dtos.ReplaySinkMQTT, BrokerAddress: "tcp://localhost:1883", Topic: "relay"}}, false, false, nil},
+		{"MessageBus sink", dtos.RecordRequest{ForwardSink: &dtos.ReplaySink{Type: dtos.ReplaySinkMessageBus}}, true, false, invalidForwardSinkType},
+		{"MQTT sink missing broker", dtos.RecordRequest{ForwardSink: &dtos.ReplaySink{Type: dtos.ReplaySinkMQTT, Topic: "relay"}}, false, false, invalidReplaySinkMQTT},
+		{"HTTP sink missing URL", dtos.RecordRequest{ForwardSink: &dtos.ReplaySink{Type: dtos.ReplaySinkHTTP}}, false, false, invalidReplaySinkHTTP},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var publisher interfaces.BackgroundPublisher
+			if test.HasPublisher {
+				publisher = &mocks.BackgroundPublisher{}
+			}
+
+			target := NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), publisher, nil).(*dataManager)
+			forwarder, err := target.newRecordForwarder(test.Request)
+			if test.ExpectedError != nil {
+				require.ErrorIs(t, err, test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedNil, forwarder == nil)
+		})
+	}
+}
+
+func TestRecordForwarder_ForwardTopic(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Topic         string
+		ReceivedTopic string
+		Expected      string
+	}{
+		{"Fixed topic", "relay", "edgex/events/device/svc/p/d/s", "relay"},
+		{"Event topic", "relay/#", "edgex/events/device/svc/p/d/s", "relay/events/device/svc/p/d/s"},
+		{"Other topic", "relay/#", "edgex/app/custom", "relay/app/custom"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			forwarder := &recordForwarder{topic: test.Topic}
+			assert.Equal(t, test.Expected, forwarder.forwardTopic(test.ReceivedTopic))
+		})
+	}
+}
+
+func TestDataManager_ForwardMessage_Topic(t *testing.T) {
+	payload := []byte(`{"raw":"payload"}`)
+
+	pipelineCtx := &mocks.AppFunctionContext{}
+	pipelineCtx.On("GetValue", interfaces.RECEIVEDTOPIC).Return("edgex/events/device/svc/p/d/s", true)
+	pipelineCtx.On("CorrelationID").Return("c1")
+	pipelineCtx.On("InputContentType").Return(common.ContentTypeJSON)
+
+	publishCtx := &mocks.AppFunctionContext{}
+	publishCtx.On("AddValue", opaqueTopicKey, "relay/events/device/svc/p/d/s")
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("BuildContext", "c1", common.ContentTypeJSON).Return(publishCtx)
+
+	mockPublisher := &mocks.BackgroundPublisher{}
+	mockPublisher.On("Publish", payload, publishCtx).Return(nil).Once()
+	mockPublisher.On("Publish", payload, publishCtx).Return(errors.New("queue full")).Once()
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), mockPublisher, nil).(*dataManager)
+	forwarder, err := target.newRecordForwarder(dtos.RecordRequest{ForwardTopic: "relay/#"})
+	require.NoError(t, err)
+	target.forwarder = forwarder
+
+	// The message is passed on unchanged whether or not it was forwarded
+	for range 2 {
+		ok, result := target.forwardMessage(pipelineCtx, payload)
+		require.True(t, ok)
+		assert.Equal(t, payload, result)
+	}
+
+	status := target.RecordingStatus()
+	assert.Equal(t, 1, status.ForwardedCount)
+	assert.Equal(t, 1, status.ForwardFailedCount)
+	publishCtx.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+// cborContext is an SDK test context which received a CBOR message
+type cborContext struct {
+	interfaces.AppFunctionContext
+}
+
+func (cborContext) InputContentType() string {
+	return common.ContentTypeCBOR
+}
+
+func TestDataManager_ForwardMessage_HTTPSink(t *testing.T) {
+	var received []byte
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		received, _ = io.ReadAll(request.Body)
+		contentType = request.Header.Get(common.ContentType)
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	payload := []byte{0xa1, 0x61, 0x61, 0x01}
+	lc := logger.NewMockClient()
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(lc)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	forwarder, err := target.newRecordForwarder(dtos.RecordRequest{ForwardSink: &dtos.ReplaySink{Type: dtos.ReplaySinkHTTP, URL: server.URL}})
+	require.NoError(t, err)
+	target.forwarder = forwarder
+
+	ctx := cborContext{pkg.NewAppFuncContextForTest("c1", lc)}
+
+	ok, result := target.forwardMessage(ctx, payload)
+	require.True(t, ok)
+	assert.Equal(t, payload, result)
+
+	// The payload is forwarded unmodified with its own content type
+	assert.Equal(t, payload, received)
+	assert.Equal(t, common.ContentTypeCBOR, contentType)
+	assert.Equal(t, 1, target.RecordingStatus().ForwardedCount)
+}
+
+func TestDataManager_StartRecording_ForwardUnavailable(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	err := target.StartRecording(dtos.RecordRequest{Duration: time.Minute, ForwardTopic: "relay"})
+	require.ErrorIs(t, err, forwardUnavailableError)
+	mockSdk.AssertNotCalled(t, "SetDefaultFunctionsPipeline", mock.Anything)
+}
@@ -0,0 +1,153 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+// maxReportedGaps is the number of gaps listed in a gap report, so the report of a flapping device stays a
+// manageable size
+const maxReportedGaps = 1000
+
+var (
+	invalidGapThreshold = errors.New("gap threshold must be greater than zero")
+	gapsOpaqueError     = errors.New("opaque messages can't be checked for gaps since they aren't decoded")
+)
+
+type gapResourceKey struct {
+	deviceName   string
+	resourceName string
+}
+
+// RecordedDataGaps returns the periods of the recorded data where a device resource produced no Readings for longer
+// than the threshold. The start and end of the recording are taken from the first and last Readings of any resource.
+// An error is returned if the threshold isn't positive or there is no recorded data or it is opaque.
+func (m *dataManager) RecordedDataGaps(threshold time.Duration) (*dtos.GapReport, error) {
+	if threshold <= 0 {
+		return nil, invalidGapThreshold
+	}
+
+	m.recordingMutex.Lock()
+	data := m.recordedData
+	m.recordingMutex.Unlock()
+
+	if data == nil {
+		return nil, noRecordedData
+	}
+
+	if len(data.Messages) > 0 {
+		return nil, gapsOpaqueError
+	}
+
+	origins := make(map[gapResourceKey][]int64)
+	var first, last int64
+	data.Events.eachReadingOrigin(func(deviceName string, resourceName string, origin int64) {
+		key := gapResourceKey{deviceName: deviceName, resourceName: resourceName}
+		origins[key] = append(origins[key], origin)
+		if first == 0 || origin < first {
+			first = origin
+		}
+		if origin > last {
+			last = origin
+		}
+	})
+
+	var disconnects []dtos.RecordingGap
+	if data.Metadata != nil {
+		disconnects = data.Metadata.Gaps
+	}
+
+	var gaps []dtos.DataGap
+	for key, resourceOrigins := range origins {
+		slices.Sort(resourceOrigins)
+
+		addGap := func(start int64, end int64) *dtos.DataGap {
+			if time.Duration(end-start) <= threshold {
+				return nil
+			}
+
+			gaps = append(gaps, dtos.DataGap{
+				DeviceName:   key.deviceName,
+				ResourceName: key.resourceName,
+				Start:        start,
+				End:          end,
+				Duration:     time.Duration(end - start),
+				Disconnected: overlapsDisconnect(start, end, disconnects),
+			})
+			return &gaps[len(gaps)-1]
+		}
+
+		if gap := addGap(first, resourceOrigins[0]); gap != nil {
+			gap.Leading = true
+		}
+		for index := 1; index < len(resourceOrigins); index++ {
+			addGap(resourceOrigins[index-1], resourceOrigins[index])
+		}
+		if gap := addGap(resourceOrigins[len(resourceOrigins)-1], last); gap != nil {
+			gap.Trailing = true
+		}
+	}
+
+	sort.Slice(gaps, func(i, j int) bool {
+		if gaps[i].Start != gaps[j].Start {
+			return gaps[i].Start < gaps[j].Start
+		}
+		if gaps[i].DeviceName != gaps[j].DeviceName {
+			return gaps[i].DeviceName < gaps[j].DeviceName
+		}
+		return gaps[i].ResourceName < gaps[j].ResourceName
+	})
+
+	report := &dtos.GapReport{
+		Threshold:     threshold,
+		ResourceCount: len(origins),
+		GapCount:      len(gaps),
+		Gaps:          gaps,
+	}
+
+	if len(gaps) > maxReportedGaps {
+		report.Gaps = gaps[:maxReportedGaps]
+		report.Truncated = true
+	}
+
+	if report.Gaps == nil {
+		report.Gaps = []dtos.DataGap{}
+	}
+
+	m.appSvc.LoggingClient().Debugf("ARR Gaps: found %d gaps longer than %s across %d device resources",
+		report.GapCount, threshold, report.ResourceCount)
+
+	return report, nil
+}
+
+// overlapsDisconnect returns true if the interval overlaps any of the MessageBus disconnects. A disconnect without an
+// end is still in progress.
+func overlapsDisconnect(start int64, end int64, disconnects []dtos.RecordingGap) bool {
+	for _, disconnect := range disconnects {
+		if disconnect.Start < end && (disconnect.End == 0 || disconnect.End > start) {
+			return true
+		}
+	}
+
+	return false
+}
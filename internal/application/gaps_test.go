@@ -0,0 +1,130 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_RecordedDataGaps(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	_, err := target.RecordedDataGaps(0)
+	require.Equal(t, invalidGapThreshold, err)
+
+	_, err = target.RecordedDataGaps(time.Second)
+	require.Equal(t, noRecordedData, err)
+
+	target.recordedData = &recordedData{Messages: []dtos.OpaqueMessage{{}}}
+	_, err = target.RecordedDataGaps(time.Second)
+	require.Equal(t, gapsOpaqueError, err)
+
+	second := int64(time.Second)
+	newEvent := func(deviceName string, origin int64) coreDtos.Event {
+		event := coreDtos.NewEvent(expectedProfileName, deviceName, expectedSourceName)
+		event.Origin = origin
+		_ = event.AddSimpleReading(expectedSourceName, common.ValueTypeInt32, int32(1))
+		event.Readings[0].Origin = origin
+		return event
+	}
+
+	// D1 reports every second except for a 10 second dropout, D2 starts late and stops early
+	var events []coreDtos.Event
+	for origin := second; origin <= 30*second; origin += second {
+		if origin <= 10*second || origin >= 20*second {
+			events = append(events, newEvent("D1", origin))
+		}
+		if origin >= 5*second && origin <= 25*second {
+			events = append(events, newEvent("D2", origin))
+		}
+	}
+
+	target.recordedData = &recordedData{
+		Events: newEventStore(events),
+		Metadata: &dtos.RecordingMetadata{
+			Gaps: []dtos.RecordingGap{{Start: 12 * second, End: 14 * second}},
+		},
+	}
+
+	report, err := target.RecordedDataGaps(3 * time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, report.Threshold)
+	assert.Equal(t, 2, report.ResourceCount)
+	assert.Equal(t, 3, report.GapCount)
+	assert.False(t, report.Truncated)
+
+	expected := []dtos.DataGap{
+		{DeviceName: "D2", ResourceName: expectedSourceName, Start: second, End: 5 * second,
+			Duration: 4 * time.Second, Leading: true},
+		{DeviceName: "D1", ResourceName: expectedSourceName, Start: 10 * second, End: 20 * second,
+			Duration: 10 * time.Second, Disconnected: true},
+		{DeviceName: "D2", ResourceName: expectedSourceName, Start: 25 * second, End: 30 * second,
+			Duration: 5 * time.Second, Trailing: true},
+	}
+	assert.Equal(t, expected, report.Gaps)
+
+	report, err = target.RecordedDataGaps(time.Minute)
+	require.NoError(t, err)
+	assert.Zero(t, report.GapCount)
+	assert.Empty(t, report.Gaps)
+	assert.NotNil(t, report.Gaps)
+}
+
+func TestDataManager_RecordedDataGaps_Truncated(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	events := make([]coreDtos.Event, maxReportedGaps+2)
+	for index := range events {
+		events[index] = coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+		_ = events[index].AddSimpleReading(expectedSourceName, common.ValueTypeInt32, int32(1))
+		events[index].Readings[0].Origin = int64(index) * int64(time.Minute)
+	}
+
+	target.recordedData = &recordedData{Events: newEventStore(events)}
+	report, err := target.RecordedDataGaps(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, maxReportedGaps+1, report.GapCount)
+	assert.Len(t, report.Gaps, maxReportedGaps)
+	assert.True(t, report.Truncated)
+}
+
+func TestOverlapsDisconnect(t *testing.T) {
+	disconnects := []dtos.RecordingGap{{Start: 10, End: 20}, {Start: 50}}
+
+	assert.False(t, overlapsDisconnect(0, 10, disconnects))
+	assert.True(t, overlapsDisconnect(5, 15, disconnects))
+	assert.True(t, overlapsDisconnect(12, 18, disconnects))
+	assert.False(t, overlapsDisconnect(20, 40, disconnects))
+	assert.True(t, overlapsDisconnect(40, 60, disconnects))
+	assert.False(t, overlapsDisconnect(0, 100, nil))
+}
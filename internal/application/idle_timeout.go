@@ -0,0 +1,168 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SessionIdleTimeoutAppSetting is how long a session may stall before it is canceled, i.e. a recording which hasn't
+// received any Events, or messages, or a replay which hasn't been able to publish the Event, or message, due.
+// Disabled when not set.
+const SessionIdleTimeoutAppSetting = "SessionIdleTimeout"
+
+// maxIdleCheckInterval is the longest interval between the checks of whether the session has stalled
+const maxIdleCheckInterval = time.Second
+
+const (
+	recordingIdleTimeout = "recording canceled after receiving no Events for %s"
+	replayIdleTimeout    = "replay canceled after being unable to publish for %s"
+)
+
+// getSessionIdleTimeout returns the configured session idle timeout. Zero is returned if the timeout is disabled.
+func (m *dataManager) getSessionIdleTimeout() (time.Duration, error) {
+	value := m.appSvc.ApplicationSettings()[SessionIdleTimeoutAppSetting]
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s value '%s', must be a duration greater than 0", SessionIdleTimeoutAppSetting, value)
+	}
+
+	return timeout, nil
+}
+
+// idleCheckInterval returns the interval the session is checked at for the timeout
+func idleCheckInterval(timeout time.Duration) time.Duration {
+	return max(min(timeout/10, maxIdleCheckInterval), time.Millisecond)
+}
+
+// startRecordingIdleWatch cancels the current recording once it hasn't received any Events for the timeout.
+// Must be called while holding the recording mutex after the recording has started.
+func (m *dataManager) startRecordingIdleWatch(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	startedAt := m.recordingStartedAt
+	m.recordingActiveAt = m.clock.Now()
+
+	go func() {
+		ticker := time.NewTicker(idleCheckInterval(timeout))
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if !m.checkRecordingIdle(startedAt, timeout) {
+				return
+			}
+		}
+	}()
+}
+
+// checkRecordingIdle cancels the recording if it has been idle for the timeout. False is returned once the
+// recording has ended, so the watch stops.
+func (m *dataManager) checkRecordingIdle(startedAt *time.Time, timeout time.Duration) bool {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	// The recording has ended, or a later recording has been started since
+	if m.recordingStartedAt != startedAt {
+		return false
+	}
+
+	if m.clock.Since(m.recordingActiveAt) < timeout {
+		return true
+	}
+
+	m.cancelRecording()
+	m.recordingMessage = fmt.Sprintf(recordingIdleTimeout, timeout)
+	m.sessionLogger(m.recordingLabel, m.recordingCorrelationID).Warnf("ARR Recording: Recording canceled after receiving no Events for %s", timeout)
+
+	return false
+}
+
+// recordingActive marks the recording as having received an Event, or message, now.
+// Must be called while holding the recording mutex.
+func (m *dataManager) recordingActive() {
+	m.recordingActiveAt = m.clock.Now()
+}
+
+// startReplayIdleWatch cancels the current replay once a publish has failed, or not completed, for the timeout.
+// Replays waiting for their next Event to be due, paused or in standby aren't idle. Must be called while holding the
+// recording mutex after the replay state has been reset.
+func (m *dataManager) startReplayIdleWatch(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	replayContext := m.replayContext
+
+	go func() {
+		ticker := time.NewTicker(idleCheckInterval(timeout))
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if !m.checkReplayIdle(replayContext, timeout) {
+				return
+			}
+		}
+	}()
+}
+
+// checkReplayIdle cancels the replay if a publish has been stalled for the timeout. False is returned once the
+// replay has ended, so the watch stops.
+func (m *dataManager) checkReplayIdle(replayContext context.Context, timeout time.Duration) bool {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	// The replay has ended, or a later replay has been started since
+	if m.replayContext != replayContext || replayContext.Err() != nil {
+		return false
+	}
+
+	if m.replayPublishingSince == nil || m.clock.Since(*m.replayPublishingSince) < timeout {
+		return true
+	}
+
+	m.stopReplay(fmt.Errorf(replayIdleTimeout, timeout))
+	m.sessionLogger(m.replayLabel, m.replayCorrelationID).Warnf("ARR Replay: Replay canceled after being unable to publish for %s", timeout)
+
+	return false
+}
+
+// startPublishing marks a publish of the replay as in progress, unless one already is
+func (m *dataManager) startPublishing() {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.replayPublishingSince == nil {
+		now := m.clock.Now()
+		m.replayPublishingSince = &now
+	}
+}
+
+// endPublishing marks the publish of the replay as no longer in progress, whether it succeeded or not
+func (m *dataManager) endPublishing() {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	m.replayPublishingSince = nil
+}
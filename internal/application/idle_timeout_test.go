@@ -0,0 +1,196 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_GetSessionIdleTimeout(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Value         string
+		Expected      time.Duration
+		ExpectedError bool
+	}{
+		{"Not set", "", 0, false},
+		{"Valid", "10m", 10 * time.Minute, false},
+		{"Zero", "0s", 0, true},
+		{"Invalid", "junk", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{SessionIdleTimeoutAppSetting: test.Value})
+			target := dataManager{appSvc: mockSdk}
+
+			actual, err := target.getSessionIdleTimeout()
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestDataManager_CheckRecordingIdle(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+	mockSdk.On("RemoveAllFunctionPipelines")
+
+	virtualClock := clock.NewVirtual(time.Unix(0, 0))
+	target := NewManager(mockSdk, time.Minute, virtualClock, nil, nil).(*dataManager)
+
+	startedAt := virtualClock.Now()
+	target.recordingStartedAt = &startedAt
+	target.recordingActiveAt = startedAt
+
+	virtualClock.Advance(30 * time.Second)
+	target.recordingMutex.Lock()
+	target.recordingActive()
+	target.recordingMutex.Unlock()
+
+	virtualClock.Advance(45 * time.Second)
+	require.True(t, target.checkRecordingIdle(&startedAt, time.Minute), "recording received an Event within the timeout")
+	require.True(t, target.RecordingStatus().InProgress)
+
+	virtualClock.Advance(15 * time.Second)
+	require.False(t, target.checkRecordingIdle(&startedAt, time.Minute))
+
+	status := target.RecordingStatus()
+	assert.False(t, status.InProgress)
+	assert.Equal(t, fmt.Sprintf(recordingIdleTimeout, time.Minute), status.Message)
+	mockSdk.AssertCalled(t, "RemoveAllFunctionPipelines")
+
+	// A watch of an earlier recording stops without affecting the current one
+	restartedAt := virtualClock.Now()
+	target.recordingStartedAt = &restartedAt
+	assert.False(t, target.checkRecordingIdle(&startedAt, time.Minute))
+	assert.True(t, target.RecordingStatus().InProgress)
+}
+
+func TestDataManager_StartReplay_IdleTimeout(t *testing.T) {
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{SessionIdleTimeoutAppSetting: "10s"})
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("failed"))
+
+	virtualClock := clock.NewVirtual(time.Unix(0, 0))
+	target := NewManager(mockSdk, time.Minute, virtualClock, nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore(expectedEventData),
+	}
+
+	err := target.StartReplay(dtos.ReplayRequest{
+		ReplayRate:           1,
+		OnPublishError:       dtos.ReplayPublishErrorRetry,
+		MaxPublishRetries:    100,
+		PublishRetryInterval: time.Second,
+	})
+	require.NoError(t, err)
+
+	// The retries would take far longer than the timeout to be exhausted
+	require.Eventually(t, func() bool {
+		virtualClock.Advance(time.Second)
+		return !target.ReplayStatus().Running
+	}, 10*time.Second, time.Millisecond)
+
+	expected := fmt.Sprintf(replayIdleTimeout, 10*time.Second)
+	assert.Equal(t, expected, target.ReplayStatus().Message)
+
+	// The replay goroutine keeps the reason once it notices the replay was canceled
+	require.Eventually(t, func() bool {
+		virtualClock.Advance(time.Second)
+		target.recordingMutex.Lock()
+		defer target.recordingMutex.Unlock()
+		return target.replayPublishingSince == nil
+	}, 10*time.Second, time.Millisecond)
+	assert.Equal(t, expected, target.ReplayStatus().Message)
+}
+
+func TestDataManager_StartReplay_ContextCanceledOnEnd(t *testing.T) {
+	tests := []struct {
+		Name        string
+		DeviceError edgexErr.EdgeX
+	}{
+		{"Completed", nil},
+		{"Devices fail to load", edgexErr.NewCommonEdgeX(edgexErr.KindServerError, "unavailable", nil)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDeviceClient := &clientMocks.DeviceClient{}
+			mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+				Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, test.DeviceError)
+
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{SessionIdleTimeoutAppSetting: "10s"})
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("DeviceClient").Return(mockDeviceClient)
+			mockSdk.On("AppContext").Return(context.Background())
+			mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+			target.recordedData = &recordedData{
+				Events: newEventStore(expectedEventData),
+			}
+
+			err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1000, MaxDuration: time.Hour})
+			if test.DeviceError != nil {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Eventually(t, func() bool {
+				return !target.ReplayStatus().Running
+			}, 5*time.Second, time.Millisecond)
+
+			// The idle and MaxDuration watches stop with the replay's context rather than once their timers fire
+			target.recordingMutex.Lock()
+			defer target.recordingMutex.Unlock()
+			assert.Error(t, target.replayContext.Err())
+		})
+	}
+}
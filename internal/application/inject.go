@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/google/uuid"
+)
+
+var injectMessagesUnavailableError = errors.New("messages can't be injected since background publishing is unavailable")
+
+// Inject publishes the one-off Events and raw messages in the request immediately, independent of any record or replay
+// session. An error is returned if a publish fails, in which case the response has the counts published before it.
+func (m *dataManager) Inject(request dtos.InjectRequest) (dtos.InjectResponse, error) {
+	var response dtos.InjectResponse
+
+	if len(request.Messages) > 0 && m.opaquePublisher == nil {
+		return response, injectMessagesUnavailableError
+	}
+
+	lc := m.appSvc.LoggingClient()
+	sink := messageBusSink{appSvc: m.appSvc}
+	now := m.clock.Now().UnixNano()
+
+	for _, event := range request.Events {
+		fillInjectedEvent(&event, now)
+
+		topic := request.Topic
+		if len(topic) == 0 {
+			topic = buildEventTopic(m.injectServiceName(request.ServiceName, event.DeviceName), event)
+		}
+
+		if err := sink.publish(topic, newReplayAddEventRequest(event, request.CorrelationID)); err != nil {
+			return response, fmt.Errorf("failed to publish Event for device %s to topic %s: %w", event.DeviceName, topic, err)
+		}
+
+		lc.Debugf("ARR Inject: Injected Event for device %s to topic: %s", event.DeviceName, topic)
+		response.PublishedEventCount++
+	}
+
+	for _, message := range request.Messages {
+		contentType := message.ContentType
+		if len(contentType) == 0 {
+			contentType = common.ContentTypeJSON
+		}
+
+		correlationID := request.CorrelationID
+		if len(correlationID) == 0 {
+			correlationID = uuid.NewString()
+		}
+
+		ctx := m.appSvc.BuildContext(correlationID, contentType)
+		ctx.AddValue(opaqueTopicKey, message.Topic)
+
+		if err := m.opaquePublisher.Publish(message.Payload, ctx); err != nil {
+			return response, fmt.Errorf("failed to publish message to topic %s: %w", message.Topic, err)
+		}
+
+		lc.Debugf("ARR Inject: Injected message to topic: %s", message.Topic)
+		response.PublishedMessageCount++
+	}
+
+	return response, nil
+}
+
+// fillInjectedEvent sets the blank Event and Reading Ids and zero Origins of a hand-crafted Event
+func fillInjectedEvent(event *coreDtos.Event, now int64) {
+	if len(event.Id) == 0 {
+		event.Id = uuid.NewString()
+	}
+
+	if event.Origin == 0 {
+		event.Origin = now
+	}
+
+	for index := range event.Readings {
+		reading := &event.Readings[index]
+		if len(reading.Id) == 0 {
+			reading.Id = uuid.NewString()
+		}
+
+		if reading.Origin == 0 {
+			reading.Origin = event.Origin
+		}
+	}
+}
+
+// injectServiceName returns the Device Service name for the topic of an injected Event, which is the requested name,
+// or the service of the device in the recorded data if not requested
+func (m *dataManager) injectServiceName(serviceName string, deviceName string) string {
+	if len(serviceName) > 0 {
+		return serviceName
+	}
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.recordedData == nil || m.recordedData.Devices[deviceName] == nil {
+		return unknownServiceName
+	}
+
+	return m.recordedData.Devices[deviceName].ServiceName
+}
@@ -0,0 +1,162 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	interfaceMocks "github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_Inject(t *testing.T) {
+	now := time.Now()
+	mockClock := &interfaceMocks.Clock{}
+	mockClock.On("Now").Return(now)
+
+	recorded := coreDtos.NewEvent(expectedProfileName, "D1", expectedSourceName)
+	crafted := coreDtos.Event{DeviceName: "D2", ProfileName: expectedProfileName, SourceName: expectedSourceName,
+		Readings: []coreDtos.BaseReading{{DeviceName: "D2", ResourceName: "Int8", SimpleReading: coreDtos.SimpleReading{Value: "bad"}}}}
+
+	mockContext := &mocks.AppFunctionContext{}
+	mockContext.On("AddValue", opaqueTopicKey, mock.Anything)
+
+	var published []requests.AddEventRequest
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("BuildContext", mock.Anything, mock.Anything).Return(mockContext)
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).Return(nil).
+		Run(func(args mock.Arguments) {
+			published = append(published, args.Get(1).(requests.AddEventRequest))
+		})
+
+	mockPublisher := &mocks.BackgroundPublisher{}
+	mockPublisher.On("Publish", mock.Anything, mockContext).Return(nil)
+
+	target := NewManager(mockSdk, time.Minute, mockClock, mockPublisher, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Devices: map[string]*coreDtos.Device{"D1": {Name: "D1", ServiceName: expectedServiceName}},
+	}
+
+	response, err := target.Inject(dtos.InjectRequest{
+		Events: []coreDtos.Event{recorded, crafted},
+		Messages: []dtos.InjectMessage{
+			{Topic: "events/device/bad", Payload: []byte("{not json")},
+			{Topic: "events/device/cbor", ContentType: common.ContentTypeCBOR, Payload: []byte{0xff}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, dtos.InjectResponse{PublishedEventCount: 2, PublishedMessageCount: 2}, response)
+
+	mockSdk.AssertCalled(t, "PublishWithTopic", buildEventTopic(expectedServiceName, recorded), mock.Anything, common.ContentTypeJSON)
+	mockSdk.AssertCalled(t, "PublishWithTopic", buildEventTopic(unknownServiceName, crafted), mock.Anything, common.ContentTypeJSON)
+
+	require.Len(t, published, 2)
+	assert.Equal(t, recorded.Id, published[0].Event.Id)
+	assert.Equal(t, recorded.Origin, published[0].Event.Origin)
+	assert.NotEmpty(t, published[1].Event.Id)
+	assert.Equal(t, now.UnixNano(), published[1].Event.Origin)
+	assert.NotEmpty(t, published[1].Event.Readings[0].Id)
+	assert.Equal(t, now.UnixNano(), published[1].Event.Readings[0].Origin)
+	assert.Equal(t, "bad", published[1].Event.Readings[0].Value)
+
+	mockSdk.AssertCalled(t, "BuildContext", mock.Anything, common.ContentTypeJSON)
+	mockSdk.AssertCalled(t, "BuildContext", mock.Anything, common.ContentTypeCBOR)
+	mockContext.AssertCalled(t, "AddValue", opaqueTopicKey, "events/device/bad")
+	mockContext.AssertCalled(t, "AddValue", opaqueTopicKey, "events/device/cbor")
+	mockPublisher.AssertCalled(t, "Publish", []byte("{not json"), mockContext)
+	mockPublisher.AssertCalled(t, "Publish", []byte{0xff}, mockContext)
+}
+
+func TestDataManager_Inject_Topic(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("PublishWithTopic", "custom/topic", mock.Anything, common.ContentTypeJSON).Return(nil)
+
+	mockClock := &interfaceMocks.Clock{}
+	mockClock.On("Now").Return(time.Now())
+
+	target := NewManager(mockSdk, time.Minute, mockClock, nil, nil).(*dataManager)
+
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	response, err := target.Inject(dtos.InjectRequest{Events: []coreDtos.Event{event}, Topic: "custom/topic"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.PublishedEventCount)
+	mockSdk.AssertExpectations(t)
+}
+
+func TestDataManager_Inject_Errors(t *testing.T) {
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	message := dtos.InjectMessage{Topic: "events/device/bad", Payload: []byte("bad")}
+	publishError := errors.New("publish error")
+
+	tests := []struct {
+		Name             string
+		Request          dtos.InjectRequest
+		Publisher        bool
+		ExpectedResponse dtos.InjectResponse
+		ExpectedError    error
+	}{
+		{"No publisher", dtos.InjectRequest{Events: []coreDtos.Event{event}, Messages: []dtos.InjectMessage{message}},
+			false, dtos.InjectResponse{}, injectMessagesUnavailableError},
+		{"Event publish fails", dtos.InjectRequest{Events: []coreDtos.Event{event, event}, ServiceName: "fail"},
+			true, dtos.InjectResponse{PublishedEventCount: 1}, publishError},
+		{"Message publish fails", dtos.InjectRequest{Messages: []dtos.InjectMessage{message}},
+			true, dtos.InjectResponse{}, publishError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockContext := &mocks.AppFunctionContext{}
+			mockContext.On("AddValue", opaqueTopicKey, mock.Anything)
+
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("BuildContext", mock.Anything, mock.Anything).Return(mockContext)
+			mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+			mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(publishError)
+
+			var publisher *mocks.BackgroundPublisher
+			if test.Publisher {
+				publisher = &mocks.BackgroundPublisher{}
+				publisher.On("Publish", mock.Anything, mock.Anything).Return(publishError)
+			}
+
+			mockClock := &interfaceMocks.Clock{}
+			mockClock.On("Now").Return(time.Now())
+
+			target := NewManager(mockSdk, time.Minute, mockClock, nil, nil).(*dataManager)
+			if publisher != nil {
+				target.opaquePublisher = publisher
+			}
+
+			response, err := target.Inject(test.Request)
+			require.ErrorIs(t, err, test.ExpectedError)
+			assert.Equal(t, test.ExpectedResponse, response)
+		})
+	}
+}
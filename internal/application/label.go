@@ -0,0 +1,100 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+)
+
+// labeledLogger appends the session label and correlation ID to each logged message, so the log lines from
+// concurrent or historic record and replay sessions can be correlated.
+type labeledLogger struct {
+	logger.LoggingClient
+	suffix string
+}
+
+// sessionLogger returns the logging client for the record or replay session with the specified label and the
+// correlation ID of the request which started it. The service's logging client is returned when the session has
+// neither.
+func (m *dataManager) sessionLogger(label string, correlationID string) logger.LoggingClient {
+	if len(label) == 0 && len(correlationID) == 0 {
+		return m.appSvc.LoggingClient()
+	}
+
+	suffix := ""
+	if len(label) > 0 {
+		suffix += fmt.Sprintf(" [label=%s]", label)
+	}
+
+	if len(correlationID) > 0 {
+		suffix += fmt.Sprintf(" [%s=%s]", common.CorrelationHeader, correlationID)
+	}
+
+	return &labeledLogger{
+		LoggingClient: m.appSvc.LoggingClient(),
+		suffix:        suffix,
+	}
+}
+
+// formatSuffix returns the suffix escaped for use in a format string
+func (l *labeledLogger) formatSuffix() string {
+	return strings.ReplaceAll(l.suffix, "%", "%%")
+}
+
+func (l *labeledLogger) Debug(msg string, args ...interface{}) {
+	l.LoggingClient.Debug(msg+l.suffix, args...)
+}
+
+func (l *labeledLogger) Error(msg string, args ...interface{}) {
+	l.LoggingClient.Error(msg+l.suffix, args...)
+}
+
+func (l *labeledLogger) Info(msg string, args ...interface{}) {
+	l.LoggingClient.Info(msg+l.suffix, args...)
+}
+
+func (l *labeledLogger) Trace(msg string, args ...interface{}) {
+	l.LoggingClient.Trace(msg+l.suffix, args...)
+}
+
+func (l *labeledLogger) Warn(msg string, args ...interface{}) {
+	l.LoggingClient.Warn(msg+l.suffix, args...)
+}
+
+func (l *labeledLogger) Debugf(msg string, args ...interface{}) {
+	l.LoggingClient.Debugf(msg+l.formatSuffix(), args...)
+}
+
+func (l *labeledLogger) Errorf(msg string, args ...interface{}) {
+	l.LoggingClient.Errorf(msg+l.formatSuffix(), args...)
+}
+
+func (l *labeledLogger) Infof(msg string, args ...interface{}) {
+	l.LoggingClient.Infof(msg+l.formatSuffix(), args...)
+}
+
+func (l *labeledLogger) Tracef(msg string, args ...interface{}) {
+	l.LoggingClient.Tracef(msg+l.formatSuffix(), args...)
+}
+
+func (l *labeledLogger) Warnf(msg string, args ...interface{}) {
+	l.LoggingClient.Warnf(msg+l.formatSuffix(), args...)
+}
@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	loggerMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger/mocks"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataManager_SessionLogger(t *testing.T) {
+	mockLogger := &loggerMocks.LoggingClient{}
+	mockLogger.On("Debug", "ARR Replay: started [label=test-run]")
+	mockLogger.On("Debugf", "ARR Replay: %d events [label=50%% run]", 10)
+	mockLogger.On("Info", "ARR Record: started [X-Correlation-ID=c1]")
+	mockLogger.On("Errorf", "ARR Record: %v [label=test-run] [X-Correlation-ID=c1]", "failed")
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(mockLogger)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	assert.Equal(t, logger.LoggingClient(mockLogger), target.sessionLogger("", ""))
+
+	target.sessionLogger("test-run", "").Debug("ARR Replay: started")
+	target.sessionLogger("50% run", "").Debugf("ARR Replay: %d events", 10)
+	target.sessionLogger("", "c1").Info("ARR Record: started")
+	target.sessionLogger("test-run", "c1").Errorf("ARR Record: %v", "failed")
+
+	mockLogger.AssertExpectations(t)
+}
+
+func TestDataManager_RecordingStatus_Label(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	now := time.Now()
+	target.recordingStartedAt = &now
+	target.recordingLabel = "in-progress"
+	target.recordingCorrelationID = "c1"
+	assert.Equal(t, "in-progress", target.RecordingStatus().Label)
+	assert.Equal(t, "c1", target.RecordingStatus().CorrelationID)
+
+	target.recordingStartedAt = nil
+	target.recordedData = &recordedData{Label: "completed", Events: newEventStore(expectedEventData)}
+	assert.Equal(t, "completed", target.RecordingStatus().Label)
+}
+
+func TestNewReplayAddEventRequest(t *testing.T) {
+	event := coreDtos.NewEvent("P1", "D1", "S1")
+
+	addEvent := newReplayAddEventRequest(event, "c1")
+	assert.Equal(t, "c1", addEvent.RequestId)
+	assert.Equal(t, event, addEvent.Event)
+
+	addEvent = newReplayAddEventRequest(event, "")
+	assert.NotEmpty(t, addEvent.RequestId)
+	assert.NotEqual(t, "c1", addEvent.RequestId)
+}
@@ -0,0 +1,275 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+const (
+	// latencyPublishedAtTag is the Event tag with the time the replayed Event was published in nanoseconds since the
+	// epoch, as a string so the precision isn't lost when decoded as a JSON number
+	latencyPublishedAtTag = "arr-published-at"
+	// latencyReplayIdTag is the Event tag identifying the replayed Event, which is kept when the pipeline replaces the
+	// Event's Id
+	latencyReplayIdTag = "arr-replay-id"
+	// latencySampleSize is the number of latencies the histogram samples
+	latencySampleSize = 10000
+	// defaultLatencyTimeout is how long to wait for the last Events to be received when the request doesn't say
+	defaultLatencyTimeout = 5 * time.Second
+)
+
+var noLatencyReplayExists = errors.New("no latency measurement replay running or previously run")
+var invalidLatencyTopic = errors.New("invalid LatencyTopic")
+var invalidLatencyTimeout = errors.New("invalid LatencyTimeout, value must be greater than or equal 0")
+var latencyShadowModeError = errors.New("LatencyTopic can't be used with ShadowMode")
+
+// latencyCapture times the replayed Events from being published to being received back on the latency topic
+type latencyCapture struct {
+	mutex      sync.Mutex
+	clock      interfaces.Clock
+	topic      string
+	inProgress bool
+	publishing bool
+	published  int64
+	received   map[string]struct{}
+	duplicates int64
+	latencies  gometrics.Histogram
+	// drained is closed once publishing has finished and all the published Events have been received
+	drained chan struct{}
+}
+
+func newLatencyCapture(topic string, clock interfaces.Clock) *latencyCapture {
+	return &latencyCapture{
+		clock:      clock,
+		topic:      topic,
+		inProgress: true,
+		publishing: true,
+		received:   make(map[string]struct{}),
+		latencies:  gometrics.NewHistogram(gometrics.NewUniformSample(latencySampleSize)),
+		drained:    make(chan struct{}),
+	}
+}
+
+// stamp tags the Event with its replay Id and the time it is published, so it can be timed when received back. Must
+// be called immediately before the Event is published.
+func (l *latencyCapture) stamp(event *coreDtos.Event) {
+	tags := maps.Clone(event.Tags)
+	if tags == nil {
+		tags = make(coreDtos.Tags)
+	}
+	tags[latencyReplayIdTag] = event.Id
+	tags[latencyPublishedAtTag] = strconv.FormatInt(l.clock.Now().UnixNano(), 10)
+	event.Tags = tags
+}
+
+// eventPublished counts a stamped Event as published
+func (l *latencyCapture) eventPublished() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.published++
+}
+
+// captureEvent is the pipeline function which times the stamped Events received on the latency topic. Events
+// received on other topics, or which weren't stamped by the replay, are ignored.
+func (l *latencyCapture) captureEvent(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	receivedAt := l.clock.Now()
+
+	receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+	if !topicMatches(l.topic, relativeMessageTopic(receivedTopic)) {
+		return false, nil
+	}
+
+	event, ok := data.(coreDtos.Event)
+	if !ok {
+		return false, fmt.Errorf("function captureEvent: expected Event received %T", data)
+	}
+
+	replayId, _ := event.Tags[latencyReplayIdTag].(string)
+	publishedAtTag, _ := event.Tags[latencyPublishedAtTag].(string)
+	publishedAt, err := strconv.ParseInt(publishedAtTag, 10, 64)
+	if len(replayId) == 0 || err != nil {
+		return false, nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.inProgress {
+		return false, nil
+	}
+
+	if _, received := l.received[replayId]; received {
+		l.duplicates++
+		return false, nil
+	}
+
+	l.received[replayId] = struct{}{}
+	l.latencies.Update(receivedAt.UnixNano() - publishedAt)
+	l.checkDrained()
+	return false, nil
+}
+
+// checkDrained signals the replay once publishing has finished and all the published Events have been received.
+// Must be called while holding the mutex.
+func (l *latencyCapture) checkDrained() {
+	if l.publishing || int64(len(l.received)) < l.published {
+		return
+	}
+
+	select {
+	case <-l.drained:
+	default:
+		close(l.drained)
+	}
+}
+
+// drain waits for the published Events still in the pipeline to be received, until the timeout or the replay is
+// canceled
+func (l *latencyCapture) drain(ctx context.Context, timeout time.Duration) {
+	l.mutex.Lock()
+	l.publishing = false
+	l.checkDrained()
+	l.mutex.Unlock()
+
+	if timeout == 0 {
+		timeout = defaultLatencyTimeout
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-l.drained:
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+func (l *latencyCapture) stop() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.inProgress = false
+}
+
+func (l *latencyCapture) report() *dtos.LatencyReport {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	report := &dtos.LatencyReport{
+		InProgress:          l.inProgress,
+		Topic:               l.topic,
+		PublishedEventCount: l.published,
+		ReceivedEventCount:  int64(len(l.received)),
+		DuplicateEventCount: l.duplicates,
+	}
+
+	if !l.inProgress {
+		report.LostEventCount = max(report.PublishedEventCount-report.ReceivedEventCount, 0)
+	}
+
+	snapshot := l.latencies.Snapshot()
+	if snapshot.Count() > 0 {
+		percentiles := snapshot.Percentiles([]float64{0.5, 0.9, 0.95, 0.99})
+		report.Latency = &dtos.LatencyDistribution{
+			Count: snapshot.Count(),
+			Mean:  time.Duration(snapshot.Mean()),
+			Min:   time.Duration(snapshot.Min()),
+			Max:   time.Duration(snapshot.Max()),
+			P50:   time.Duration(percentiles[0]),
+			P90:   time.Duration(percentiles[1]),
+			P95:   time.Duration(percentiles[2]),
+			P99:   time.Duration(percentiles[3]),
+		}
+	}
+
+	return report
+}
+
+// validateLatencyRequest checks the latency measurement options of the replay request
+func validateLatencyRequest(request dtos.ReplayRequest) error {
+	if request.LatencyTimeout < 0 {
+		return invalidLatencyTimeout
+	}
+
+	if len(request.LatencyTopic) == 0 {
+		return nil
+	}
+
+	if request.ShadowMode {
+		return latencyShadowModeError
+	}
+
+	if err := validateTopicPattern(request.LatencyTopic); err != nil {
+		return fmt.Errorf("%w '%s': %v", invalidLatencyTopic, request.LatencyTopic, err)
+	}
+
+	return nil
+}
+
+// startLatencyCapture sets the functions pipeline to time the replayed Events received back on the latency topic
+// while the replay is running. Must be called while holding the recording mutex.
+func (m *dataManager) startLatencyCapture(topic string) error {
+	m.latency = newLatencyCapture(topic, m.clock)
+	if err := m.setSessionPipeline(m.latency.captureEvent); err != nil {
+		m.latency = nil
+		return fmt.Errorf("%s: %v", setPipelineFailedMessage, err)
+	}
+
+	m.sessionLogger(m.replayLabel, m.replayCorrelationID).Debugf("ARR Replay: Latency measurement on topic %s started", topic)
+	return nil
+}
+
+// stopLatencyCapture stops timing the replayed Events once the replay has ended for any reason
+func (m *dataManager) stopLatencyCapture(latency *latencyCapture) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	// Only remove the pipeline if it hasn't since been replaced by a newer latency measurement replay
+	if m.latency == latency {
+		m.removeSessionPipelines()
+	}
+	latency.stop()
+
+	m.sessionLogger(m.replayLabel, m.replayCorrelationID).Debug("ARR Replay: Latency measurement stopped")
+}
+
+// LatencyReport returns the end-to-end latency report for the current or last latency measurement replay session.
+// An error is returned if no latency measurement replay has been run
+func (m *dataManager) LatencyReport() (*dtos.LatencyReport, error) {
+	m.recordingMutex.Lock()
+	latency := m.latency
+	m.recordingMutex.Unlock()
+
+	if latency == nil {
+		return nil, noLatencyReplayExists
+	}
+
+	return latency.report(), nil
+}
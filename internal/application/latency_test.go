@@ -0,0 +1,291 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	interfaceMocks "github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	loggerMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger/mocks"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const latencyTestTopic = "events/core/#"
+
+func latencyTestContext(receivedTopic string) appInterfaces.AppFunctionContext {
+	ctx := pkg.NewAppFuncContextForTest("", logger.NewMockClient())
+	ctx.AddValue(appInterfaces.RECEIVEDTOPIC, receivedTopic)
+	return ctx
+}
+
+func TestLatencyCapture_Stamp(t *testing.T) {
+	now := time.Now()
+	mockClock := &interfaceMocks.Clock{}
+	mockClock.On("Now").Return(now)
+
+	target := newLatencyCapture(latencyTestTopic, mockClock)
+
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	event.Tags = coreDtos.Tags{"site": "A"}
+	original := event.Tags
+
+	target.stamp(&event)
+
+	assert.Equal(t, "A", event.Tags["site"])
+	assert.Equal(t, event.Id, event.Tags[latencyReplayIdTag])
+	assert.Equal(t, strconv.FormatInt(now.UnixNano(), 10), event.Tags[latencyPublishedAtTag])
+	assert.Len(t, original, 1, "shared tags must not be modified")
+}
+
+func TestLatencyCapture_CaptureEvent(t *testing.T) {
+	publishedAt := time.Now()
+	mockClock := &interfaceMocks.Clock{}
+	mockClock.On("Now").Return(publishedAt).Once()
+	mockClock.On("Now").Return(publishedAt.Add(10 * time.Millisecond))
+
+	target := newLatencyCapture(latencyTestTopic, mockClock)
+
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	target.stamp(&event)
+	target.eventPublished()
+
+	// Core Data replaces the Id, so the Event is matched by the tag
+	persisted := event
+	persisted.Id = "persisted"
+	unstamped := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+
+	tests := []struct {
+		Name          string
+		Topic         string
+		Data          any
+		ExpectedError bool
+	}{
+		{"Other topic", "edgex/events/device/device-virtual/P1/D1/S1", persisted, false},
+		{"Not stamped", "edgex/events/core/device-virtual/P1/D1/S1", unstamped, false},
+		{"Bad data", "edgex/events/core/device-virtual/P1/D1/S1", "bad data", true},
+		{"Received", "edgex/events/core/device-virtual/P1/D1/S1", persisted, false},
+		{"Duplicate", "edgex/events/core/device-virtual/P1/D1/S1", persisted, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			continuePipeline, result := target.captureEvent(latencyTestContext(test.Topic), test.Data)
+			assert.False(t, continuePipeline)
+			if test.ExpectedError {
+				require.IsType(t, errors.New(""), result)
+				return
+			}
+			assert.Nil(t, result)
+		})
+	}
+
+	report := target.report()
+	assert.True(t, report.InProgress)
+	assert.Equal(t, int64(1), report.PublishedEventCount)
+	assert.Equal(t, int64(1), report.ReceivedEventCount)
+	assert.Equal(t, int64(1), report.DuplicateEventCount)
+	require.NotNil(t, report.Latency)
+	assert.Equal(t, int64(1), report.Latency.Count)
+	assert.Equal(t, 10*time.Millisecond, report.Latency.Min)
+	assert.Equal(t, 10*time.Millisecond, report.Latency.P99)
+}
+
+func TestLatencyCapture_Report_Lost(t *testing.T) {
+	target := newLatencyCapture(latencyTestTopic, clock.New())
+	target.eventPublished()
+	target.eventPublished()
+
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	target.stamp(&event)
+	_, _ = target.captureEvent(latencyTestContext("edgex/events/core/device-virtual/P1/D1/S1"), event)
+
+	assert.Equal(t, int64(0), target.report().LostEventCount, "events aren't lost while in progress")
+
+	target.stop()
+	_, _ = target.captureEvent(latencyTestContext("edgex/events/core/device-virtual/P1/D1/S1"), event)
+
+	expected := &dtos.LatencyReport{
+		Topic:               latencyTestTopic,
+		PublishedEventCount: 2,
+		ReceivedEventCount:  1,
+		LostEventCount:      1,
+	}
+
+	report := target.report()
+	require.NotNil(t, report.Latency)
+	report.Latency = nil
+	assert.Equal(t, expected, report)
+}
+
+func TestLatencyCapture_Drain(t *testing.T) {
+	t.Run("All received", func(t *testing.T) {
+		target := newLatencyCapture(latencyTestTopic, clock.New())
+		event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+		target.stamp(&event)
+		target.eventPublished()
+		_, _ = target.captureEvent(latencyTestContext("edgex/events/core/device-virtual/P1/D1/S1"), event)
+
+		start := time.Now()
+		target.drain(context.Background(), time.Minute)
+		assert.Less(t, time.Since(start), time.Minute)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		target := newLatencyCapture(latencyTestTopic, clock.New())
+		target.eventPublished()
+
+		start := time.Now()
+		target.drain(context.Background(), 50*time.Millisecond)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		target := newLatencyCapture(latencyTestTopic, clock.New())
+		target.eventPublished()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		start := time.Now()
+		target.drain(ctx, time.Minute)
+		assert.Less(t, time.Since(start), time.Minute)
+	})
+}
+
+func TestValidateLatencyRequest(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Request       dtos.ReplayRequest
+		ExpectedError error
+	}{
+		{"Valid - no latency", dtos.ReplayRequest{}, nil},
+		{"Valid", dtos.ReplayRequest{LatencyTopic: latencyTestTopic, LatencyTimeout: time.Second}, nil},
+		{"Bad timeout", dtos.ReplayRequest{LatencyTimeout: -1}, invalidLatencyTimeout},
+		{"Shadow mode", dtos.ReplayRequest{LatencyTopic: latencyTestTopic, ShadowMode: true}, latencyShadowModeError},
+		{"Bad topic", dtos.ReplayRequest{LatencyTopic: "events/#/core"}, invalidLatencyTopic},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := validateLatencyRequest(test.Request)
+			if test.ExpectedError == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, test.ExpectedError)
+		})
+	}
+}
+
+func TestDataManager_StartReplay_LatencyTopic(t *testing.T) {
+	mockLogger := &loggerMocks.LoggingClient{}
+	mockLogger.On("Debug", mock.Anything)
+	mockLogger.On("Debugf", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(mockLogger)
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("SetDefaultFunctionsPipeline", mock.Anything).Return(nil).Once()
+	mockSdk.On("RemoveAllFunctionPipelines").Once()
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	// Core Data persists and republishes all but the first replayed Event, which is lost
+	var publishedCount atomic.Int32
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		if publishedCount.Add(1) == 1 {
+			return
+		}
+
+		event := args.Get(1).(requests.AddEventRequest).Event
+		_, _ = target.latency.captureEvent(latencyTestContext("edgex/events/core/device-virtual/P1/D1/S1"), event)
+	})
+
+	_, err := target.LatencyReport()
+	require.Equal(t, noLatencyReplayExists, err)
+
+	target.recordedData = &recordedData{
+		Events: newEventStore(expectedEventData),
+	}
+
+	err = target.StartReplay(dtos.ReplayRequest{ReplayRate: 10, LatencyTopic: latencyTestTopic,
+		LatencyTimeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	var report *dtos.LatencyReport
+	require.Eventually(t, func() bool {
+		report, err = target.LatencyReport()
+		return err == nil && !report.InProgress
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, int64(len(expectedEventData)), report.PublishedEventCount)
+	assert.Equal(t, int64(len(expectedEventData)-1), report.ReceivedEventCount)
+	assert.Equal(t, int64(1), report.LostEventCount)
+	require.NotNil(t, report.Latency)
+	assert.Equal(t, int64(len(expectedEventData)-1), report.Latency.Count)
+	mockSdk.AssertExpectations(t)
+}
+
+func TestDataManager_StartReplay_LatencyTopic_PipelineError(t *testing.T) {
+	mockLogger := &loggerMocks.LoggingClient{}
+	mockLogger.On("Debugf", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(mockLogger)
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("SetDefaultFunctionsPipeline", mock.Anything).Return(errors.New("pipeline error"))
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore(expectedEventData),
+	}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 10, LatencyTopic: latencyTestTopic})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), setPipelineFailedMessage)
+	assert.Nil(t, target.replayStartedAt)
+	assert.Nil(t, target.latency)
+}
@@ -0,0 +1,48 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import "errors"
+
+var recordedDataLockedError = errors.New("recorded data is locked, it must be unlocked before it can be replaced")
+
+// LockRecordedData marks the recorded data as read-only so it can't be overwritten by a new recording or import
+// until it is unlocked. An error is returned if there is no recorded data to lock
+func (m *dataManager) LockRecordedData() error {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.recordedData == nil {
+		return noRecordedData
+	}
+
+	m.recordedDataLocked = true
+
+	m.appSvc.LoggingClient().Debug("ARR Lock: Recorded data has been locked")
+
+	return nil
+}
+
+// UnlockRecordedData removes the read-only lock from the recorded data
+func (m *dataManager) UnlockRecordedData() {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	m.recordedDataLocked = false
+
+	m.appSvc.LoggingClient().Debug("ARR Lock: Recorded data has been unlocked")
+}
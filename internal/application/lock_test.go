@@ -0,0 +1,57 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_LockRecordedData(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	err := target.LockRecordedData()
+	require.Equal(t, noRecordedData, err)
+
+	target.recordedData = &recordedData{Events: newEventStore(expectedEventData)}
+
+	err = target.LockRecordedData()
+	require.NoError(t, err)
+	assert.True(t, target.RecordingStatus().Locked)
+
+	err = target.StartRecording(dtos.RecordRequest{EventLimit: 10})
+	require.Equal(t, recordedDataLockedError, err)
+
+	err = target.ImportRecordedData(&dtos.RecordedData{}, false, false)
+	require.Equal(t, recordedDataLockedError, err)
+
+	// Locked data is still available for replay and export
+	require.Equal(t, expectedEventData, target.recordedData.Events.events())
+
+	target.UnlockRecordedData()
+	assert.False(t, target.RecordingStatus().Locked)
+}
@@ -30,11 +30,15 @@ import (
 	"github.com/edgexfoundry/app-record-replay/internal/interfaces"
 	"github.com/edgexfoundry/app-record-replay/internal/utils"
 	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	bootstrapInterfaces "github.com/edgexfoundry/go-mod-bootstrap/v3/bootstrap/interfaces"
 	bootstrapUtils "github.com/edgexfoundry/go-mod-bootstrap/v3/bootstrap/utils"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
 	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/models"
 	"github.com/google/uuid"
+	gometrics "github.com/rcrowley/go-metrics"
 )
 
 const (
@@ -45,71 +49,255 @@ const (
 	replayExiting                      = "ARR Replay: Replay exiting due to App termination"
 	replayPublishFailed                = "failed to publish replay event: %v"
 	replayDeepCopyFailed               = "deep copy of event to be replayed failed: %v"
+	replayScriptFailed                 = "failed to evaluate replay script: %v"
 	maxReplayDelayExceeded             = "%s delay exceeds the maximum replay delay of %s. Maximum replay delay is configurable using MaxReplayDelay App Setting"
 	noReplayExists                     = "no replay running or previously run"
 	deviceLoadFailed                   = "failed to load device %s for replay/export: %v"
+	unknownServiceName                 = "unknown-service"
 	profileLoadFailed                  = "failed to load device profile %s for export: %v"
 )
 
 type recordedData struct {
-	Duration time.Duration
-	Events   []coreDtos.Event
-	Devices  map[string]*coreDtos.Device
-	Profiles map[string]*coreDtos.DeviceProfile
+	Name         string
+	Label        string
+	Duration     time.Duration
+	Events       *eventStore
+	Devices      map[string]*coreDtos.Device
+	Profiles     map[string]*coreDtos.DeviceProfile
+	Envelopes    map[string]dtos.EnvelopeMetadata
+	Messages     []dtos.OpaqueMessage
+	DeadLetters  []dtos.DeadLetter
+	Telemetry    []dtos.OpaqueMessage
+	SystemEvents []dtos.OpaqueMessage
+	Annotations  []dtos.Annotation
+	Metadata     *dtos.RecordingMetadata
 }
 
 // dataManager implements interface that records and replays captured data
 type dataManager struct {
 	appSvc         appInterfaces.ApplicationService
+	clock          interfaces.Clock
 	recordingMutex sync.Mutex
 
-	recordedEventCount int
-	recordingStartedAt *time.Time
-	recordedData       *recordedData
+	recordedEventCount     int
+	recordedEnvelopes      map[string]dtos.EnvelopeMetadata
+	payloadSizes           *payloadSizeStats
+	recordedMessages       []dtos.OpaqueMessage
+	recordedDeadLetters    []dtos.DeadLetter
+	recordedTelemetry      []dtos.OpaqueMessage
+	recordedSystemEvents   []dtos.OpaqueMessage
+	recordedAnnotations    []dtos.Annotation
+	recordingStartedAt     *time.Time
+	recordingName          string
+	recordingLabel         string
+	recordingCorrelationID string
+	recordingMetadata      *dtos.RecordingMetadata
+	recordPipeline         []appInterfaces.AppFunction
+	recordingSequence      int
+	recordingActiveAt      time.Time
+	recordingMessage       string
+
+	metadataSnapshot    *metadataSnapshot
+	metadataWatchCancel context.CancelFunc
+
+	busWatch       *busWatch
+	busWatchCancel context.CancelFunc
+
+	forwarder          *recordForwarder
+	forwardedCount     int
+	forwardFailedCount int
+
+	segmentRotation *segmentRotation
 
-	maxReplayDelay      time.Duration
-	replayStartedAt     *time.Time
-	replayedDuration    time.Duration
-	replayedEventCount  int
-	replayedRepeatCount int
-	replayError         error
-	replayContext       context.Context
-	replayCancelFunc    context.CancelFunc
+	recordedData       *recordedData
+	recordedDataLocked bool
+
+	maxReplayDelay                time.Duration
+	replayStartedAt               *time.Time
+	replayData                    *recordedData
+	replayUsesPipelines           bool
+	replayedDuration              time.Duration
+	replayedEventCount            int
+	replayedRepeatCount           int
+	replaySkippedEventCount       int
+	replayDriftedProfiles         []string
+	replayIterations              []dtos.ReplayIterationStatus
+	replaySchedulingErrors        gometrics.Histogram
+	replayDroppedEventCount       int
+	replayPublishRetryCount       int
+	replayPublishFailedEventCount int
+	replayClampedReadingCount     int
+	replayRejectedReadingCount    int
+	replayLabel                   string
+	replayCorrelationID           string
+	replayError                   error
+	replayContext                 context.Context
+	replayCancelFunc              context.CancelFunc
+	replayMaxDuration             time.Duration
+	replayStopCause               error
+	replayPublishingSince         *time.Time
+	replayStandby                 func() error
+	replayTriggerTopic            string
+	replaySeek                    *replaySeek
+	replayPause                   *replayPause
+	commandTopic                  string
+	shadow                        *shadowCapture
+	latency                       *latencyCapture
+	replaySinks                   []*replaySinkState
+	mqttSinkSenders               map[string]*transforms.MQTTSecretSender
+	opaquePublisher               appInterfaces.BackgroundPublisher
+	cloudSync                     *cloudSync
+	clockOffsets                  map[string]time.Duration
+	readingsOnly                  bool
+	metrics                       *recordingMetrics
+	pipelineStats                 pipelineStats
+
+	sessionQueue []queuedSession
 }
 
 // NewManager is the factory function which instantiates a Data Manager
-func NewManager(service appInterfaces.ApplicationService, maxReplayDelay time.Duration) interfaces.DataManager {
-	return &dataManager{
-		appSvc:         service,
-		maxReplayDelay: maxReplayDelay,
+// The opaquePublisher is used to replay opaque recordings and may be nil if background publishing is unavailable.
+// The metricsManager is used to publish the recording metrics and may be nil, in which case they aren't published.
+func NewManager(service appInterfaces.ApplicationService, maxReplayDelay time.Duration, clock interfaces.Clock,
+	opaquePublisher appInterfaces.BackgroundPublisher, metricsManager bootstrapInterfaces.MetricsManager) interfaces.DataManager {
+	m := &dataManager{
+		appSvc:          service,
+		clock:           clock,
+		maxReplayDelay:  maxReplayDelay,
+		opaquePublisher: opaquePublisher,
+	}
+
+	if metricsManager != nil {
+		m.metrics = newRecordingMetrics(metricsManager, service.LoggingClient())
 	}
+
+	return m
 }
 
 var recordingInProgressError = errors.New("a recording is in progress")
 var batchParametersNotSetError = errors.New("duration and/or count not set")
 var noRecordingRunningToCancelError = errors.New("no recording currently running")
 
-// StartRecording starts a recording session based on the values in the request.
-// An error is returned if the request data is incomplete or a record or replay session is currently running.
+// StartRecording starts a recording session based on the values in the request, or queues it if it can't run
+// alongside the running sessions and queueing is enabled. An error is returned if the request data is incomplete or
+// the session can't run alongside the running sessions and can't be queued.
 func (m *dataManager) StartRecording(request dtos.RecordRequest) error {
-	lc := m.appSvc.LoggingClient()
-
 	m.recordingMutex.Lock()
 	defer m.recordingMutex.Unlock()
 
-	if m.recordingStartedAt != nil {
-		return recordingInProgressError
+	busy, err := m.sessionBusy(dtos.SessionKindRecord, dtos.ReplayRequest{})
+	if err != nil {
+		return err
 	}
 
-	if m.replayStartedAt != nil {
-		return replayInProgressError
+	if busy {
+		busyErr := recordingInProgressError
+		if m.recordingStartedAt == nil && m.replayStartedAt != nil {
+			busyErr = replayInProgressError
+		}
+
+		return m.queueSession(queuedSession{kind: dtos.SessionKindRecord, record: request, label: request.Label,
+			correlationID: request.CorrelationID}, busyErr)
+	}
+
+	return m.startRecording(request)
+}
+
+// startRecording starts a recording session based on the values in the request.
+// Must be called while holding the recording mutex when the session can run alongside the running sessions.
+func (m *dataManager) startRecording(request dtos.RecordRequest) error {
+	lc := m.sessionLogger(request.Label, request.CorrelationID)
+
+	if m.recordedDataLocked {
+		return recordedDataLockedError
+	}
+
+	if request.Opaque && len(request.IncludeDeviceProfiles)+len(request.IncludeDevices)+len(request.IncludeSources)+
+		len(request.ExcludeDeviceProfiles)+len(request.ExcludeDevices)+len(request.ExcludeSources) > 0 {
+		return opaqueFiltersError
+	}
+
+	if request.Opaque && request.ReadingsOnly {
+		return readingsOnlyOpaqueError
+	}
+
+	if request.Opaque && request.Telemetry {
+		return telemetryOpaqueError
+	}
+
+	if request.Opaque && request.SystemEvents {
+		return systemEventsOpaqueError
+	}
+
+	router, err := newTopicRouter(request.Topics, request.Opaque)
+	if err != nil {
+		return err
+	}
+
+	forwarder, err := m.newRecordForwarder(request)
+	if err != nil {
+		return err
+	}
+
+	segmentStoreDir, err := m.getSegmentStoreDir(request)
+	if err != nil {
+		return err
 	}
 
 	m.recordedData = nil
 	m.recordedEventCount = 0
+	m.recordedEnvelopes = nil
+	m.payloadSizes = nil
+	m.recordedMessages = nil
+	m.recordedDeadLetters = nil
+	m.recordedTelemetry = nil
+	m.recordedSystemEvents = nil
+	m.recordedAnnotations = nil
+	if m.metrics != nil {
+		m.metrics.reset()
+	}
+	m.pipelineStats.reset()
+
+	// Opaque recordings don't decode the Events, so their payload sizes aren't tracked per device
+	if !request.Opaque {
+		m.payloadSizes = newPayloadSizeStats()
+	}
+
+	// Readings-only recordings drop the Event Ids the envelope metadata is keyed by
+	if !request.ReadingsOnly {
+		m.recordedEnvelopes = make(map[string]dtos.EnvelopeMetadata)
+	}
 
 	var pipeline []appInterfaces.AppFunction
 
+	// Messages are forwarded as received, before they are decoded or filtered
+	if forwarder != nil {
+		pipeline = append(pipeline, m.forwardMessage)
+	}
+
+	// Telemetry messages aren't Events, so they are captured before the decode
+	if request.Telemetry {
+		pipeline = append(pipeline, m.captureTelemetry)
+	}
+
+	// Likewise the system events, which are published by Core Metadata rather than devices
+	if request.SystemEvents {
+		pipeline = append(pipeline, m.captureSystemEvent)
+	}
+
+	if !request.Opaque {
+		// The service receives raw payloads, so the Events must be decoded before they can be filtered
+		pipeline = append(pipeline, m.decodeEvent)
+	}
+
+	// Pushed Events are already decoded, so they join the pipeline after the decode
+	pushStart := len(pipeline)
+
+	if router != nil {
+		pipeline = append(pipeline, router.route)
+		lc.Debugf("ARR Start Recording: routing on %d topic rules", len(request.Topics))
+	}
+
 	if len(request.IncludeDeviceProfiles) > 0 {
 		includeFilter := transforms.NewFilterFor(request.IncludeDeviceProfiles)
 		pipeline = append(pipeline, includeFilter.FilterByProfileName)
@@ -147,7 +335,6 @@ func (m *dataManager) StartRecording(request dtos.RecordRequest) error {
 	}
 
 	var batch *transforms.BatchConfig
-	var err error
 
 	if request.Duration > 0 && request.EventLimit > 0 {
 		batch, err = transforms.NewBatchByTimeAndCount(request.Duration.String(), request.EventLimit)
@@ -163,22 +350,100 @@ func (m *dataManager) StartRecording(request dtos.RecordRequest) error {
 		return fmt.Errorf("%s: %v", createBatchFailedMessage, err)
 	}
 
-	// processBatchedData expects slice of Events, so configure batch to return slice of Events
-	batch.IsEventData = true
+	// processBatchedData expects slice of Events, so configure batch to return slice of Events. Opaque recordings
+	// batch the raw payloads instead, and readings-only recordings batch the Events' reading tuples, so the batch
+	// never holds the full Events.
+	batch.IsEventData = !request.Opaque && !request.ReadingsOnly
 
-	pipeline = append(pipeline, m.countEvents, batch.Batch, m.processBatchedData)
+	metadataWatchInterval, err := m.getMetadataWatchInterval()
+	if err != nil {
+		return err
+	}
+
+	busProbeInterval, err := m.getBusProbeInterval()
+	if err != nil {
+		return err
+	}
+
+	idleTimeout, err := m.getSessionIdleTimeout()
+	if err != nil {
+		return err
+	}
+
+	cloudSync, err := m.newCloudSync()
+	if err != nil {
+		return err
+	}
+
+	clockOffsets, err := m.getSourceClockOffsets()
+	if err != nil {
+		return err
+	}
+
+	if request.Opaque {
+		pipeline = append(pipeline, m.captureMessage, batch.Batch, m.processBatchedMessages)
+	} else {
+		pipeline = append(pipeline, m.countEvents, batch.Batch, m.processBatchedData)
+	}
 	lc.Debug(debugPipelineFunctionsAddedMessage)
 
 	// Setting the Functions Pipeline starts the recording of Events
-	err = m.appSvc.SetDefaultFunctionsPipeline(pipeline...)
+	err = m.setSessionPipeline(pipeline...)
 	if err != nil {
 		return fmt.Errorf("%s: %v", setPipelineFailedMessage, err)
 	}
 
-	now := time.Now()
+	now := m.clock.Now()
+	recordingName := m.buildRecordingName(request, now)
+
+	var rotation *segmentRotation
+	if request.Rotation != nil {
+		rotation, err = newSegmentRotation(segmentStoreDir, *request.Rotation, recordingName, now)
+		if err != nil {
+			m.removeSessionPipelines()
+			return err
+		}
+	}
+
 	m.recordingStartedAt = &now
+	m.recordingMessage = ""
+	m.forwarder = forwarder
+	m.forwardedCount = 0
+	m.forwardFailedCount = 0
+	m.segmentRotation = rotation
+	m.recordingName = recordingName
+	m.recordingLabel = request.Label
+	m.recordingCorrelationID = request.CorrelationID
+	m.recordingMetadata = newRecordingMetadata(request, now)
+	m.recordingMetadata.ClockOffsets = clockOffsets
+	m.cloudSync = cloudSync
+	m.clockOffsets = clockOffsets
+	m.readingsOnly = request.ReadingsOnly
+
+	// Opaque recordings capture raw payloads, so there is no pipeline for pushed Events
+	m.recordPipeline = nil
+	if !request.Opaque {
+		m.recordPipeline = pipeline[pushStart:]
+	}
+
+	// Opaque messages aren't Events, so there is no device metadata to watch
+	if metadataWatchInterval > 0 && !request.Opaque {
+		m.startMetadataWatch(metadataWatchInterval)
+	}
+
+	if busProbeInterval > 0 {
+		m.startBusWatch(busProbeInterval)
+	}
+
+	m.startRecordingIdleWatch(idleTimeout)
 
 	lc.Debugf("ARR Start Recording: Recording of Events has started with EventLimit=%d and Duration=%s", request.EventLimit, request.Duration.String())
+	if rotation != nil {
+		lc.Debugf("ARR Start Recording: Recording continuously, rotating segments into %s", rotation.dir)
+	}
+	if len(m.recordingName) > 0 {
+		lc.Debugf("ARR Start Recording: Recording named '%s'", m.recordingName)
+	}
 
 	return nil
 }
@@ -192,29 +457,66 @@ func (m *dataManager) CancelRecording() error {
 		return noRecordingRunningToCancelError
 	}
 
-	// This stops recording of Events
-	m.appSvc.RemoveAllFunctionPipelines()
-	m.recordingStartedAt = nil
-
-	m.appSvc.LoggingClient().Debug("ARR Cancel Recording: Recording of Events has been canceled")
+	m.cancelRecording()
+	m.sessionLogger(m.recordingLabel, m.recordingCorrelationID).Debug("ARR Cancel Recording: Recording of Events has been canceled")
 
 	return nil
 }
 
+// cancelRecording stops the current recording, discarding the data recorded so far.
+// Must be called while holding the recording mutex while a recording is in progress.
+func (m *dataManager) cancelRecording() {
+	// This stops recording of Events
+	m.removeSessionPipelines()
+	m.recordingStartedAt = nil
+	m.stopMetadataWatch()
+	m.stopBusWatch()
+	m.scheduleNextSession()
+	if m.metrics != nil {
+		m.metrics.reset()
+	}
+}
+
 // RecordingStatus returns the status of the current recording session
 func (m *dataManager) RecordingStatus() dtos.RecordStatus {
 	m.recordingMutex.Lock()
 	defer m.recordingMutex.Unlock()
 
-	status := dtos.RecordStatus{}
+	status := dtos.RecordStatus{
+		Locked: m.recordedDataLocked,
+	}
 
 	if m.recordingStartedAt != nil {
 		status.InProgress = true
-		status.Duration = time.Since(*m.recordingStartedAt)
+		status.Name = m.recordingName
+		status.Label = m.recordingLabel
+		status.CorrelationID = m.recordingCorrelationID
+		status.Duration = m.clock.Since(*m.recordingStartedAt)
 		status.EventCount = m.recordedEventCount
+		status.DeadLetterCount = len(m.recordedDeadLetters)
+		status.TelemetryCount = len(m.recordedTelemetry)
+		status.SystemEventCount = len(m.recordedSystemEvents)
 	} else if m.recordedData != nil {
+		status.Name = m.recordedData.Name
+		status.Label = m.recordedData.Label
 		status.Duration = m.recordedData.Duration
-		status.EventCount = len(m.recordedData.Events)
+		// Only one of these is set, depending on whether the recording is opaque
+		status.EventCount = m.recordedData.Events.len() + len(m.recordedData.Messages)
+		status.DeadLetterCount = len(m.recordedData.DeadLetters)
+		status.TelemetryCount = len(m.recordedData.Telemetry)
+		status.SystemEventCount = len(m.recordedData.SystemEvents)
+	}
+
+	if m.recordingStartedAt == nil {
+		status.Message = m.recordingMessage
+	}
+
+	status.Queue = m.queuedSessions(dtos.SessionKindRecord)
+	status.Gaps = m.recordingGaps()
+	status.ForwardedCount = m.forwardedCount
+	status.ForwardFailedCount = m.forwardFailedCount
+	if m.segmentRotation != nil {
+		status.SegmentCount = m.segmentRotation.segmentCount
 	}
 
 	return status
@@ -224,26 +526,57 @@ var replayInProgressError = errors.New("a replay is in progress")
 var noRecordedData = errors.New("no recorded data present")
 var invalidReplayRate = errors.New("invalid ReplayRate, value must be greater than 0")
 var invalidReplayCount = errors.New("invalid ReplayCount, value must be greater than or equal 0. Zero defaults to 1")
+var invalidReplayScript = errors.New("invalid Script, value must be a valid Lua script")
+var invalidMaxReplayLag = errors.New("invalid MaxReplayLag, value must be greater than or equal 0")
 
-// StartReplay starts a replay session based on the values in the request
-// An error is returned if the request data is incomplete or a record or replay session is currently running.
+// StartReplay starts a replay session based on the values in the request, or queues it if it can't run alongside the
+// running sessions and queueing is enabled. An error is returned if the request data is incomplete or the session
+// can't run alongside the running sessions and can't be queued.
 func (m *dataManager) StartReplay(request dtos.ReplayRequest) error {
+	deployed := m.fetchDeployedProfiles()
+
 	m.recordingMutex.Lock()
 	defer m.recordingMutex.Unlock()
 
-	if m.recordingStartedAt != nil {
-		return recordingInProgressError
+	busy, err := m.sessionBusy(dtos.SessionKindReplay, request)
+	if err != nil {
+		return err
 	}
 
-	if m.replayStartedAt != nil {
-		return replayInProgressError
+	if busy {
+		busyErr := replayInProgressError
+		if m.replayStartedAt == nil && m.recordingStartedAt != nil {
+			busyErr = recordingInProgressError
+		}
+
+		return m.queueSession(queuedSession{kind: dtos.SessionKindReplay, replay: request, label: request.Label,
+			correlationID: request.CorrelationID}, busyErr)
 	}
 
-	if m.recordedData == nil {
+	return m.startReplay(request, deployed)
+}
+
+// startReplay starts a replay session based on the values in the request, checking the recorded Device Profiles
+// against those deployed for drift. Must be called while holding the recording mutex when the session can run
+// alongside the running sessions.
+func (m *dataManager) startReplay(request dtos.ReplayRequest, deployed *deployedProfiles) error {
+	if len(request.SourceURL) == 0 && m.recordedData == nil {
 		return noRecordedData
 	}
 
-	if request.ReplayRate <= 0 {
+	if request.TimeWarpDuration < 0 || request.TimeWarpStart < 0 {
+		return invalidTimeWarp
+	}
+
+	if request.AlignTimeOfDay && (request.ReplayRate != 0 || request.TimeWarpDuration > 0 || len(request.DevicePriorities) > 0) {
+		return dailyReplayOptionsError
+	}
+
+	if request.TimeWarpDuration > 0 && request.ReplayRate != 0 {
+		return timeWarpReplayRateError
+	}
+
+	if request.TimeWarpDuration == 0 && !request.AlignTimeOfDay && request.ReplayRate <= 0 {
 		return invalidReplayRate
 	}
 
@@ -251,69 +584,495 @@ func (m *dataManager) StartReplay(request dtos.ReplayRequest) error {
 		return invalidReplayCount
 	}
 
-	now := time.Now()
-	m.replayStartedAt = &now
-	m.replayedDuration = 0
-	m.replayedEventCount = 0
-	m.replayedRepeatCount = 0
-	m.replayError = nil
-	m.replayContext, m.replayCancelFunc = context.WithCancel(context.Background())
+	if request.MaxDuration < 0 {
+		return invalidReplayMaxDuration
+	}
+
+	if len(request.Script) > 0 {
+		if _, err := compileReplayScript(request.Script); err != nil {
+			return fmt.Errorf("%w: %v", invalidReplayScript, err)
+		}
+	}
+
+	if request.MaxReplayLag < 0 {
+		return invalidMaxReplayLag
+	}
+
+	if len(request.Warmup) > 0 && request.Warmup != dtos.ReplayWarmupFull && request.Warmup != dtos.ReplayWarmupBackground {
+		return invalidReplayWarmup
+	}
+
+	if request.FanOut < 0 || request.FanOutOffset < 0 {
+		return invalidFanOut
+	}
+
+	if err := validateLatencyRequest(request); err != nil {
+		return err
+	}
+
+	if err := validateBreakpoints(request); err != nil {
+		return err
+	}
+
+	policy, err := newPublishPolicy(request)
+	if err != nil {
+		return err
+	}
+
+	if len(request.SourceURL) > 0 {
+		return m.startStreamedReplay(request, policy)
+	}
+
+	if len(m.recordedData.Messages) > 0 {
+		return m.startOpaqueReplay(request, policy)
+	}
+
+	if request.Telemetry && request.AlignTimeOfDay {
+		return telemetryDailyReplayError
+	}
+
+	if request.Telemetry && m.opaquePublisher == nil {
+		return telemetryReplayUnavailableError
+	}
+
+	if request.SystemEvents && m.opaquePublisher == nil {
+		return systemEventsReplayUnavailableError
+	}
+
+	startIndex, err := annotationStartIndex(request, m.recordedData)
+	if err != nil {
+		return err
+	}
+
+	sinks, err := m.newReplaySinks(request, policy)
+	if err != nil {
+		return err
+	}
+
+	request.PublishWorkers, err = m.getPublishWorkers(request)
+	if err != nil {
+		return err
+	}
+
+	validator, err := m.newReplayValidator(m.sessionLogger(request.Label, request.CorrelationID))
+	if err != nil {
+		return err
+	}
+
+	bounds, err := m.newValueBounds(request, m.sessionLogger(request.Label, request.CorrelationID))
+	if err != nil {
+		return err
+	}
+
+	drifted, err := m.checkProfileDrift(deployed, m.sessionLogger(request.Label, request.CorrelationID))
+	if err != nil {
+		return err
+	}
+
+	if err := m.resetReplayState(request); err != nil {
+		return err
+	}
+	m.replaySinks = sinks
+	m.replayDriftedProfiles = drifted
+	m.replaySeek = newReplaySeek(request)
 
 	if len(m.recordedData.Devices) == 0 {
-		err := m.loadDevices()
+		// Devices missing from Core Metadata are handled per Event when validation skips or provisions them
+		err := m.loadDevices(validator.skipsMissingDevices())
 		if err != nil {
+			m.abortReplayStart()
 			return err
 		}
 
-		m.appSvc.LoggingClient().Debugf("ARR Replay: Loaded %d devices for replay", len(m.recordedData.Devices))
+		m.sessionLogger(m.replayLabel, m.replayCorrelationID).Debugf("ARR Replay: Loaded %d devices for replay", len(m.recordedData.Devices))
 	}
 
-	go m.replayRecordedEvents(request)
+	if len(request.SimulationServiceName) > 0 {
+		if err := m.registerSimulationDevices(request.SimulationServiceName, request.FanOut, m.sessionLogger(m.replayLabel, m.replayCorrelationID)); err != nil {
+			m.abortReplayStart()
+			return err
+		}
+	}
+
+	var warmup *replayWarmup
+	if len(request.Warmup) > 0 {
+		warmup = newReplayWarmup()
+	}
+
+	// A full warm-up fails the start of the replay rather than the replay part way through
+	if request.Warmup == dtos.ReplayWarmupFull {
+		if err := warmup.prepare(m.replayContext, m.recordedData.Events); err != nil {
+			m.abortReplayStart()
+			return err
+		}
+
+		m.sessionLogger(m.replayLabel, m.replayCorrelationID).Debugf("ARR Replay: Warm-up prepared %d events", m.recordedData.Events.len())
+	}
+
+	start := func() error {
+		if request.ShadowMode {
+			if err := m.startShadowCapture(); err != nil {
+				return err
+			}
+		}
+
+		if len(request.LatencyTopic) > 0 {
+			if err := m.startLatencyCapture(request.LatencyTopic); err != nil {
+				return err
+			}
+		}
+
+		go m.replayRecordedEvents(request, startIndex, validator, bounds, warmup, sinks)
+		return nil
+	}
+
+	// A replay in standby is fully primed above, so only publishing is left once it is triggered
+	if request.Standby {
+		return m.enterReplayStandby(start)
+	}
+
+	if err := start(); err != nil {
+		m.abortReplayStart()
+		return err
+	}
+
+	return nil
+}
+
+// abortReplayStart marks the replay which failed to start as ended, canceling its context so the idle and MaxDuration
+// watches started with it stop. Must be called while holding the recording mutex after the replay state has been
+// reset.
+func (m *dataManager) abortReplayStart() {
+	m.replayCancelFunc()
+	m.replayStartedAt = nil
+}
+
+// startOpaqueReplay starts the replay of an opaque recording. Must be called while holding the recording mutex.
+func (m *dataManager) startOpaqueReplay(request dtos.ReplayRequest, policy *publishPolicy) error {
+	if len(request.Script) > 0 || request.ShadowMode || request.Telemetry || request.SystemEvents ||
+		len(request.LatencyTopic) > 0 || len(request.StartAnnotation) > 0 || len(request.DevicePriorities) > 0 ||
+		len(request.Warmup) > 0 || len(request.SimulationServiceName) > 0 || request.TimeWarpDuration > 0 ||
+		request.AlignTimeOfDay || len(request.Sinks) > 0 || request.FanOut > 0 || request.Standby ||
+		request.PublishWorkers > 0 || len(request.ValueBounds) > 0 || len(request.Breakpoints) > 0 {
+		return opaqueReplayOptionsError
+	}
+
+	if m.opaquePublisher == nil {
+		return opaqueReplayUnavailableError
+	}
+
+	if err := m.resetReplayState(request); err != nil {
+		return err
+	}
+
+	go m.replayRecordedMessages(request, policy)
 
 	return nil
 }
 
-func (m *dataManager) replayRecordedEvents(request dtos.ReplayRequest) {
+// resetReplayState marks a new replay as started. An error is returned, leaving the state unchanged, if the
+// SessionIdleTimeout is invalid. Must be called while holding the recording mutex.
+func (m *dataManager) resetReplayState(request dtos.ReplayRequest) error {
+	idleTimeout, err := m.getSessionIdleTimeout()
+	if err != nil {
+		return err
+	}
+
+	now := m.clock.Now()
+	m.replayStartedAt = &now
+	// The replay holds on to the recorded data it replays, since a recording running alongside it replaces it
+	m.replayData = m.recordedData
+	m.replayUsesPipelines = usesSessionPipelines(request)
+	m.replayedDuration = 0
+	m.replayedEventCount = 0
+	m.replayedRepeatCount = 0
+	m.replaySkippedEventCount = 0
+	m.replayDriftedProfiles = nil
+	m.replayIterations = nil
+	m.replaySchedulingErrors = newSchedulingErrors()
+	m.replayDroppedEventCount = 0
+	m.replayPublishRetryCount = 0
+	m.replayPublishFailedEventCount = 0
+	m.replayClampedReadingCount = 0
+	m.replayRejectedReadingCount = 0
+	m.replayLabel = request.Label
+	m.replayCorrelationID = request.CorrelationID
+	m.replayError = nil
+	m.replaySinks = nil
+	m.replaySeek = nil
+	m.replayPause = nil
+	m.replayContext, m.replayCancelFunc = context.WithCancel(context.Background())
+	m.replayMaxDuration = request.MaxDuration
+	m.replayStopCause = nil
+	m.replayPublishingSince = nil
+	m.startReplayIdleWatch(idleTimeout)
+
+	// The time budget of a replay in standby starts once it is triggered
+	if !request.Standby {
+		m.startReplayBudget()
+	}
+
+	return nil
+}
+
+func (m *dataManager) replayRecordedEvents(request dtos.ReplayRequest, startIndex int, validator *replayValidator,
+	bounds *valueBounds, warmup *replayWarmup, sinks []*replaySinkState) {
 	var previousEventTime int64
 	firstEvent := true
-	lc := m.appSvc.LoggingClient()
+	lc := m.sessionLogger(request.Label, request.CorrelationID)
 
-	// Replay Count of zero defaults to 1.
+	// Capture the shadow for this replay since a new replay may start before the deferred stop is run
+	shadow := m.shadow
+	if request.ShadowMode {
+		defer m.stopShadowCapture(shadow)
+	}
+
+	latency := m.latency
+	if len(request.LatencyTopic) > 0 {
+		defer m.stopLatencyCapture(latency)
+	} else {
+		latency = nil
+	}
+
+	daily := newDailyAlignment(request, m.replayData.Events, m.replayData.Envelopes, m.clock.Now())
+
+	// Replay Count of zero defaults to 1, except for a daily replay which loops until canceled.
 	replayCount := 1
 	if request.RepeatCount > 0 {
 		replayCount = request.RepeatCount
 	}
 
+	loopDaily := daily != nil && request.RepeatCount == 0
+
+	var script *replayScript
+	if len(request.Script) > 0 {
+		var err error
+		if script, err = newReplayScript(m.replayContext, request.Script); err != nil {
+			m.setReplayError(fmt.Errorf(replayScriptFailed, err), true)
+			return
+		}
+		defer script.close()
+	}
+
+	// A time warp replaces the requested rate with the rate derived from the recorded and target windows
+	warp := newTimeWarp(request, m.replayData.Events, m.replayData.Envelopes, m.clock.Now())
+	if warp != nil {
+		request.ReplayRate = warp.rate()
+	}
+
+	scheduler := newReplayScheduler(request)
+	breakpoints := newReplayBreakpoints(request, m.replayData, startIndex)
+	systemEvents := newSystemEventReplay(request, m.replayData, startIndex)
+	// stepping pauses the replay before the next Event, whether it matches a breakpoint or not
+	stepping := false
+	// The telemetry is stopped when the replay ends for any reason, not just when it is canceled
+	telemetry := newTelemetryReplay(request, m.replayData, startIndex)
+	if telemetry != nil {
+		var stopTelemetry context.CancelFunc
+		telemetry.ctx, stopTelemetry = context.WithCancel(m.replayContext)
+		defer stopTelemetry()
+	}
+
+	// Publish workers are only used with more than one, otherwise the Events are published inline
+	var workers *publishWorkers
+	if request.PublishWorkers > 1 {
+		workers = m.startPublishWorkers(m.replayContext, request.PublishWorkers, sinks, lc)
+		defer workers.stop()
+		m.pipelineStats.setPublishWorkers(workers)
+		defer m.pipelineStats.setPublishWorkers(nil)
+	}
+
+	if request.Warmup == dtos.ReplayWarmupBackground {
+		go func() {
+			if err := warmup.prepare(m.replayContext, m.replayData.Events); err == nil {
+				lc.Debugf("ARR Replay: Warm-up prepared %d events", m.replayData.Events.len())
+			}
+		}()
+	}
+
 	lc.Debugf("ARR Replay: Replay starting with Replay Rate of %v and Repeat Count of %d ", request.ReplayRate, replayCount)
 
-	for i := 0; i < replayCount; i++ {
-		for _, event := range m.recordedData.Events {
-			// Check if service is terminating
-			if m.appSvc.AppContext().Err() != nil {
-				m.recordingMutex.Lock()
-				m.replayStartedAt = nil
-				m.recordingMutex.Unlock()
-				m.appSvc.LoggingClient().Info(replayExiting)
+	for i := 0; loopDaily || i < replayCount; i++ {
+		if scheduler != nil {
+			scheduler.restart()
+		}
+
+		if breakpoints != nil {
+			breakpoints.restart()
+		}
+
+		if systemEvents != nil {
+			systemEvents.restart()
+		}
+
+		iteration := m.startReplayIteration(i+1, request.ReplayRate)
+
+		var telemetryDone <-chan struct{}
+		if telemetry != nil {
+			telemetryDone = m.startTelemetryReplay(telemetry, iteration.startedAt, request.ReplayRate, lc)
+		}
+
+		// A day where all the Events are skipped must still wait for the next day rather than looping straight on
+		if daily != nil && i > 0 && !m.sleepUntil(daily.dayStart(i), lc) {
+			return
+		}
+
+		for index := startIndex; index < m.replayData.Events.len(); index++ {
+			if m.replayStopped(lc) {
 				return
 			}
 
-			// Check if replay cancel func has been called to cancel the replay
-			if m.replayContext.Err() != nil {
-				m.setReplayError(replayCanceled, false)
-				return
+			// A seek restarts the pacing from the Event jumped to, which is published immediately
+			if seekIndex, ok := m.takeReplaySeek(); ok {
+				index = seekIndex
+				firstEvent = true
+				iteration.rebase(m.clock.Now())
+				if scheduler != nil {
+					scheduler.restart()
+				}
+				if breakpoints != nil {
+					breakpoints.jumped()
+				}
+				if systemEvents != nil {
+					systemEvents.jumped()
+				}
 			}
 
-			replayEvent := coreDtos.Event{}
-			if err := bootstrapUtils.DeepCopy(event, &replayEvent); err != nil {
+			var replayEvent coreDtos.Event
+			if warmup != nil {
+				var err error
+				replayEvent, err = warmup.event(index)
+				if err != nil {
+					// The warm-up also stops when the replay is canceled, in which case the state is already updated
+					if !m.replayStopped(lc) {
+						m.setReplayError(err, true)
+					}
+					return
+				}
+			} else if err := bootstrapUtils.DeepCopy(m.replayData.Events.event(index), &replayEvent); err != nil {
 				m.setReplayError(fmt.Errorf(replayDeepCopyFailed, err), true)
 				return
 			}
+			restoreEvent(&replayEvent)
+
+			// Skipped events don't update previousEventTime, so the next replayed event keeps its original spacing
+			// relative to the last event that was actually replayed.
+			if script != nil {
+				var replay bool
+				var err error
+				replayEvent, replay, err = script.evaluate(replayEvent)
+				if err != nil {
+					// Canceling the replay also stops the script, in which case the state is already updated
+					if !m.replayStopped(lc) {
+						m.setReplayError(fmt.Errorf(replayScriptFailed, err), true)
+					}
+					return
+				}
+
+				if !replay {
+					lc.Debugf("ARR Replay: Event for device %s skipped by replay script", replayEvent.DeviceName)
+					continue
+				}
+			}
 
-			// Send the first event immediately and then wait appropriate time between events
-			if firstEvent {
+			if validator != nil {
+				if err := validator.validate(replayEvent); err != nil {
+					if !errors.Is(err, eventNotValidError) || validator.policy == validationPolicyFail {
+						m.setReplayError(fmt.Errorf(replayValidationFailed, err), true)
+						return
+					}
+
+					lc.Debugf("ARR Replay: Event skipped: %v", err)
+					m.incrementReplaySkippedEventCount()
+					continue
+				}
+			}
+
+			if bounds != nil {
+				clamped, rejected, err := bounds.enforce(&replayEvent)
+				if err != nil {
+					m.setReplayError(fmt.Errorf(replayValueBoundsFailed, err), true)
+					return
+				}
+
+				m.addReplayValueBoundsCounts(clamped, rejected)
+
+				if len(replayEvent.Readings) == 0 {
+					lc.Debugf("ARR Replay: Event for device %s skipped since all its readings are out of bounds", replayEvent.DeviceName)
+					continue
+				}
+			}
+
+			eventTime := replayEvent.Origin
+			envelope, hasEnvelope := m.replayData.Envelopes[replayEvent.Id]
+			if request.UseEnvelopeTiming && hasEnvelope {
+				eventTime = envelope.ReceivedAt
+			}
+
+			// Pause before publishing an Event matching a breakpoint, or any Event after a step
+			breakpoint, matched := 0, false
+			if breakpoints != nil {
+				breakpoint, matched = breakpoints.match(replayEvent, eventTime)
+			}
+
+			if matched || stepping {
+				stepping = m.pauseReplay(dtos.ReplayPauseStatus{
+					EventIndex: index,
+					DeviceName: replayEvent.DeviceName,
+					Breakpoint: breakpoint,
+					Stepped:    !matched,
+				}, lc)
+
+				if m.replayStopped(lc) {
+					return
+				}
+
+				// Like a seek, the pacing restarts from the Event paused before, which isn't published if the
+				// replay seeked while paused
+				firstEvent = true
+				iteration.rebase(m.clock.Now())
+				if scheduler != nil {
+					scheduler.restart()
+				}
+
+				if m.replaySeekPending() {
+					continue
+				}
+			}
+
+			// Send the first event immediately and then wait appropriate time between events. A prioritized replay is
+			// paced by its scheduler instead and a daily replay waits for the Event's time-of-day.
+			var dailyTarget time.Time
+			scheduledAt := iteration.scheduledAt(eventTime)
+			if daily != nil {
+				var replayToday bool
+				dailyTarget, replayToday = daily.target(eventTime, i)
+				if !replayToday {
+					continue
+				}
+				scheduledAt = dailyTarget
+
+				if !m.sleepUntil(dailyTarget, lc) {
+					return
+				}
+			} else if scheduler != nil {
+				wait, publish := scheduler.next(replayEvent, eventTime, m.clock.Now())
+				if !publish {
+					lc.Debugf("ARR Replay: Event for device %s dropped since replay is behind schedule", replayEvent.DeviceName)
+					m.incrementReplayDroppedEventCount()
+					continue
+				}
+
+				if wait > m.maxReplayDelay {
+					m.setReplayError(fmt.Errorf(maxReplayDelayExceeded, wait.String(), m.maxReplayDelay.String()), true)
+					return
+				}
+
+				m.clock.Sleep(wait)
+			} else if firstEvent {
 				firstEvent = false
 			} else {
-				delay := replayEvent.Origin - previousEventTime
+				delay := eventTime - previousEventTime
 
 				// Replay Rate less than one increases the delay to slow down replay pace while greater than one
 				// decreases the delay to increase the replay pace.
@@ -325,17 +1084,43 @@ func (m *dataManager) replayRecordedEvents(request dtos.ReplayRequest) {
 				}
 
 				// Best we can do with realtime capabilities
-				time.Sleep(time.Duration(delay))
+				m.clock.Sleep(time.Duration(delay))
 			}
 
-			previousEventTime = replayEvent.Origin
+			previousEventTime = eventTime
 
-			serviceName := m.getServiceName(replayEvent.DeviceName)
+			if systemEvents != nil {
+				if err := m.publishDueSystemEvents(systemEvents.due(eventTime), workers, lc); err != nil {
+					m.setReplayError(fmt.Errorf(replayPublishFailed, err), true)
+					return
+				}
+			}
+
+			// Events are replayed to the topic they were received on when known so the transport-level routing is
+			// reproduced, otherwise the topic is built the same as Device Services do. Events replayed under a
+			// simulation device service are always published to the service's topics.
+			var topic string
+			ok := false
+			if len(request.SimulationServiceName) == 0 {
+				topic, ok = relativeEventTopic(envelope.ReceivedTopic)
+			}
+
+			if !ok {
+				serviceName := request.SimulationServiceName
+				if len(serviceName) == 0 {
+					serviceName = m.getServiceName(replayEvent.DeviceName)
+				}
+
+				topic = buildEventTopic(serviceName, replayEvent)
+			}
 
-			topic := common.BuildTopic(strings.Replace(common.CoreDataEventSubscribeTopic, "/#", "", 1),
-				serviceName, replayEvent.ProfileName, replayEvent.DeviceName, replayEvent.SourceName)
+			newOrigin := m.clock.Now().UnixNano()
+			if warp != nil {
+				newOrigin = warp.origin(eventTime, i)
+			} else if daily != nil {
+				newOrigin = dailyTarget.UnixNano()
+			}
 
-			newOrigin := time.Now().UnixNano()
 			replayEvent.Origin = newOrigin
 			replayEvent.Id = uuid.NewString()
 			for index := range replayEvent.Readings {
@@ -343,28 +1128,111 @@ func (m *dataManager) replayRecordedEvents(request dtos.ReplayRequest) {
 				replayEvent.Readings[index].Id = uuid.NewString()
 			}
 
-			addEvent := requests.NewAddEventRequest(replayEvent)
+			for _, replayed := range fanOut(request, replayEvent, topic) {
+				if request.ShadowMode {
+					shadow.addReplayedEvent(replayed.event)
+				}
+
+				job := publishJob{
+					topic:       replayed.topic,
+					addEvent:    newReplayAddEventRequest(replayed.event, request.CorrelationID),
+					iteration:   iteration,
+					scheduledAt: scheduledAt,
+					latency:     latency,
+				}
+
+				if workers != nil {
+					if err := workers.failed(); err != nil {
+						m.setReplayError(fmt.Errorf(replayPublishFailed, err), true)
+						return
+					}
+
+					workers.submit(job)
+					continue
+				}
+
+				if err := m.publishReplayedEvent(sinks, lc, job); err != nil {
+					m.setReplayError(fmt.Errorf(replayPublishFailed, err), true)
+					return
+				}
+			}
+		}
 
-			if err := m.appSvc.PublishWithTopic(topic, addEvent, common.ContentTypeJSON); err != nil {
+		if systemEvents != nil {
+			if err := m.publishDueSystemEvents(systemEvents.remaining(), workers, lc); err != nil {
 				m.setReplayError(fmt.Errorf(replayPublishFailed, err), true)
 				return
 			}
+		}
 
-			lc.Debugf("ARR Replay: Replayed Event to topic: %s", topic)
+		// The iteration is only complete once the workers have published all its Events
+		if workers != nil {
+			if err := workers.flush(); err != nil {
+				m.setReplayError(fmt.Errorf(replayPublishFailed, err), true)
+				return
+			}
+		}
 
-			m.incrementReplayedEventCount()
+		// The iteration is only complete once its telemetry has been replayed
+		if telemetryDone != nil {
+			<-telemetryDone
+			if m.replayStopped(lc) {
+				return
+			}
 		}
 
-		m.incrementReplayRepeatCount()
+		m.completeReplayIteration(iteration)
 	}
 
+	// The replay only completes once the Events still in the pipeline have been received or are counted as lost
+	if latency != nil {
+		latency.drain(m.replayContext, request.LatencyTimeout)
+		if m.replayStopped(lc) {
+			return
+		}
+	}
+
+	m.completeReplay(lc)
+}
+
+// replayStopped returns true if the service is terminating or the replay has been canceled, in which case the replay
+// state has been updated and the replay must exit.
+func (m *dataManager) replayStopped(lc logger.LoggingClient) bool {
+	// Check if service is terminating
+	if m.appSvc.AppContext().Err() != nil {
+		m.recordingMutex.Lock()
+		m.replayCancelFunc()
+		m.replayStartedAt = nil
+		m.recordingMutex.Unlock()
+		lc.Info(replayExiting)
+		return true
+	}
+
+	// Check if replay cancel func has been called to cancel the replay
+	if m.replayContext.Err() != nil {
+		m.setReplayError(m.replayStoppedError(), false)
+		return true
+	}
+
+	return false
+}
+
+func (m *dataManager) completeReplay(lc logger.LoggingClient) {
 	m.recordingMutex.Lock()
 	defer m.recordingMutex.Unlock()
-	m.replayedDuration = time.Since(*m.replayStartedAt)
+	m.replayedDuration = m.clock.Since(*m.replayStartedAt)
 	m.replayStartedAt = nil
+	// The replay's watches stop with its context, which must be canceled before the next session resets it
+	m.replayCancelFunc()
+	m.scheduleNextSession()
 
 	lc.Debugf("ARR Replay: Replay completed in %s. %d events replayed with %d repeated replays",
 		m.replayedDuration.String(), m.replayedEventCount, m.replayedRepeatCount)
+
+	if report := schedulingErrorStatus(m.replaySchedulingErrors); report != nil {
+		lc.Debugf("ARR Replay: Scheduling error of replayed events: mean %s, p99 %s, max %s",
+			report.Mean, report.P99, report.Max)
+	}
 }
 
 func (m *dataManager) setReplayError(err error, logError bool) {
@@ -372,8 +1240,11 @@ func (m *dataManager) setReplayError(err error, logError bool) {
 	defer m.recordingMutex.Unlock()
 	m.replayError = err
 	m.replayStartedAt = nil
+	m.replayCancelFunc()
+	m.scheduleNextSession()
 	if logError {
-		m.appSvc.LoggingClient().Errorf("ARR Replay: Replay stopped due to error: %v", err)
+		m.sessionLogger(m.replayLabel, m.replayCorrelationID).Errorf("ARR Replay: Replay stopped due to error: %v", err)
+		m.sendNotification(replayFailedLabel, models.Critical, fmt.Sprintf("Replay stopped due to error: %v", err))
 	}
 }
 
@@ -383,16 +1254,11 @@ func (m *dataManager) incrementReplayedEventCount() {
 	m.replayedEventCount++
 }
 
-func (m *dataManager) incrementReplayRepeatCount() {
-	m.recordingMutex.Lock()
-	defer m.recordingMutex.Unlock()
-	m.replayedRepeatCount++
-}
-
 var noReplayRunningToCancelError = errors.New("no replay currently running")
 var replayCanceled = errors.New("replay canceled")
 
-// CancelReplay cancels the current replay session
+// CancelReplay cancels the current replay session. The next queued session, if any, is started once the replay
+// goroutine has stopped, so it doesn't overlap the canceled replay.
 func (m *dataManager) CancelReplay() error {
 	m.recordingMutex.Lock()
 	defer m.recordingMutex.Unlock()
@@ -405,9 +1271,16 @@ func (m *dataManager) CancelReplay() error {
 		m.replayCancelFunc()
 		m.replayStartedAt = nil
 		m.replayError = replayCanceled
+		m.replayPause = nil
+	}
+
+	// No replay goroutine is running to start the next session when a replay in standby is canceled
+	if m.replayStandby != nil {
+		m.leaveReplayStandby()
+		m.scheduleNextSession()
 	}
 
-	m.appSvc.LoggingClient().Debug("ARR Cancel Replay: Replay of Events has been canceled")
+	m.sessionLogger(m.replayLabel, m.replayCorrelationID).Debug("ARR Cancel Replay: Replay of Events has been canceled")
 
 	return nil
 }
@@ -419,9 +1292,9 @@ func (m *dataManager) ReplayStatus() dtos.ReplayStatus {
 
 	duration := m.replayedDuration
 
-	// If replay is in progress we need to calculate the duration so far.
-	if m.replayedDuration == 0 && m.replayStartedAt != nil {
-		duration = time.Since(*m.replayStartedAt)
+	// If replay is in progress we need to calculate the duration so far. A replay in standby hasn't started yet.
+	if m.replayedDuration == 0 && m.replayStartedAt != nil && m.replayStandby == nil {
+		duration = m.clock.Since(*m.replayStartedAt)
 	}
 
 	message := ""
@@ -431,12 +1304,33 @@ func (m *dataManager) ReplayStatus() dtos.ReplayStatus {
 		message = noReplayExists
 	}
 
+	var paused *dtos.ReplayPauseStatus
+	if m.replayPause != nil {
+		status := m.replayPause.status
+		paused = &status
+	}
+
 	return dtos.ReplayStatus{
-		Running:     m.replayStartedAt != nil,
-		EventCount:  m.replayedEventCount,
-		Duration:    duration,
-		RepeatCount: m.replayedRepeatCount,
-		Message:     message,
+		Running:                 m.replayStartedAt != nil && m.replayStandby == nil,
+		Standby:                 m.replayStandby != nil,
+		Paused:                  paused,
+		EventCount:              m.replayedEventCount,
+		Duration:                duration,
+		RepeatCount:             m.replayedRepeatCount,
+		Label:                   m.replayLabel,
+		CorrelationID:           m.replayCorrelationID,
+		SkippedEventCount:       m.replaySkippedEventCount,
+		DriftedProfiles:         m.replayDriftedProfiles,
+		DroppedEventCount:       m.replayDroppedEventCount,
+		PublishRetryCount:       m.replayPublishRetryCount,
+		PublishFailedEventCount: m.replayPublishFailedEventCount,
+		ClampedReadingCount:     m.replayClampedReadingCount,
+		RejectedReadingCount:    m.replayRejectedReadingCount,
+		Queue:                   m.queuedSessions(dtos.SessionKindReplay),
+		Sinks:                   m.replaySinksStatus(),
+		Iterations:              m.replayIterations,
+		SchedulingError:         schedulingErrorStatus(m.replaySchedulingErrors),
+		Message:                 message,
 	}
 }
 
@@ -448,16 +1342,33 @@ func (m *dataManager) ExportRecordedData() (*dtos.RecordedData, error) {
 	m.recordingMutex.Lock()
 	defer m.recordingMutex.Unlock()
 
+	return m.exportRecordedData()
+}
+
+// exportRecordedData returns the data for the last record session, loading its devices and profiles if not yet
+// loaded. Must be called while holding the recording mutex.
+func (m *dataManager) exportRecordedData() (*dtos.RecordedData, error) {
 	if m.recordedData == nil {
 		return nil, noRecordedData
 	}
 
-	if len(m.recordedData.Events) == 0 {
+	if len(m.recordedData.Messages) > 0 {
+		m.appSvc.LoggingClient().Debugf("ARR Export: Exporting %d opaque messages", len(m.recordedData.Messages))
+
+		return &dtos.RecordedData{
+			Name:        m.recordedData.Name,
+			Messages:    m.recordedData.Messages,
+			Annotations: m.recordedData.Annotations,
+			Metadata:    m.recordedData.Metadata,
+		}, nil
+	}
+
+	if m.recordedData.Events.len() == 0 {
 		return nil, noEventsRecorded
 	}
 
 	if len(m.recordedData.Devices) == 0 {
-		err := m.loadDevices()
+		err := m.loadDevices(false)
 		if err != nil {
 			return nil, err
 		}
@@ -481,37 +1392,50 @@ func (m *dataManager) ExportRecordedData() (*dtos.RecordedData, error) {
 
 		m.appSvc.LoggingClient().Debugf("ARR Export: Loaded %d devices profiles for export", len(m.recordedData.Profiles))
 	}
+	m.stampProfileHashes()
 
 	m.appSvc.LoggingClient().Debugf("ARR Export: Exporting %d events, %d devices and %d device profiles",
-		len(m.recordedData.Events), len(m.recordedData.Devices), len(m.recordedData.Profiles))
+		m.recordedData.Events.len(), len(m.recordedData.Devices), len(m.recordedData.Profiles))
 
 	return &dtos.RecordedData{
-			RecordedEvents: m.recordedData.Events,
+			Name:           m.recordedData.Name,
+			RecordedEvents: m.recordedData.Events.events(),
 			Devices:        utils.MapToSlice(m.recordedData.Devices),
 			Profiles:       utils.MapToSlice(m.recordedData.Profiles),
+			Envelopes:      m.recordedData.Envelopes,
+			DeadLetters:    m.recordedData.DeadLetters,
+			Telemetry:      m.recordedData.Telemetry,
+			SystemEvents:   m.recordedData.SystemEvents,
+			Annotations:    m.recordedData.Annotations,
+			Metadata:       m.recordedData.Metadata,
 		},
 		nil
 }
 
-func (m *dataManager) loadDevices() error {
+// loadDevices loads the devices for the recorded Events. If skipNotFound is true, devices that no longer
+// exist are left out rather than failing the load.
+func (m *dataManager) loadDevices(skipNotFound bool) error {
 	m.recordedData.Devices = make(map[string]*coreDtos.Device)
-	for _, event := range m.recordedData.Events {
-		if m.recordedData.Devices[event.DeviceName] == nil {
-			response, err := m.appSvc.DeviceClient().DeviceByName(context.Background(), event.DeviceName)
-			if err != nil {
-				m.recordedData.Devices = nil
-				return fmt.Errorf(deviceLoadFailed, event.DeviceName, err)
-			}
-			m.recordedData.Devices[event.DeviceName] = &response.Device
+	for _, deviceName := range m.recordedData.Events.uniqueDeviceNames() {
+		response, err := m.appSvc.DeviceClient().DeviceByName(context.Background(), deviceName)
+		if err != nil && skipNotFound && err.Code() == http.StatusNotFound {
+			continue
 		}
+		if err != nil {
+			m.recordedData.Devices = nil
+			return fmt.Errorf(deviceLoadFailed, deviceName, err)
+		}
+		m.recordedData.Devices[deviceName] = &response.Device
 	}
 	return nil
 }
 
 // ImportRecordedData imports data from a previously exported record session.
 // If overwrite parameter is true then Device Profiles and/or Devices will be overwritten.
-// An error is returned if a record or replay session is currently running or the data is incomplete
-func (m *dataManager) ImportRecordedData(data *dtos.RecordedData, overwrite bool) error {
+// An error is returned if a record or replay session is currently running or the data is incomplete. A provisioning
+// error is returned once imported if some of the Device Profiles or Devices failed to be provisioned, unless rollback
+// is true, in which case those added by the import are deleted and the data isn't imported.
+func (m *dataManager) ImportRecordedData(data *dtos.RecordedData, overwrite bool, rollback bool) error {
 	m.recordingMutex.Lock()
 	defer m.recordingMutex.Unlock()
 
@@ -523,108 +1447,39 @@ func (m *dataManager) ImportRecordedData(data *dtos.RecordedData, overwrite bool
 		return replayInProgressError
 	}
 
-	// Must handle profiles first, so they exist when a new device is added that references it.
-	err := m.uploadProfiles(data.Profiles, overwrite)
-	if err != nil {
-		return err
+	if m.recordedDataLocked {
+		return recordedDataLockedError
 	}
 
-	err = m.uploadDevices(data.Devices, overwrite)
-	if err != nil {
-		return err
+	// Unless rolled back, the data is imported even if some of its Device Profiles and Devices fail to be
+	// provisioned, which are reported by the provisioning error returned once imported
+	provisioningErr := m.provisionImport(data, overwrite, rollback)
+	if provisioningErr != nil && !isProvisioningError(provisioningErr) {
+		return provisioningErr
 	}
 
 	m.recordedData = &recordedData{
-		Events:   data.RecordedEvents,
-		Devices:  utils.SliceToMap(data.Devices, func(d coreDtos.Device) string { return d.Name }),
-		Profiles: utils.SliceToMap(data.Profiles, func(dp coreDtos.DeviceProfile) string { return dp.Name }),
-	}
-
-	m.appSvc.LoggingClient().Debugf("ARR Import: Imported %d events, %d devices and %d device profiles",
-		len(m.recordedData.Events), len(m.recordedData.Devices), len(m.recordedData.Profiles))
-	return nil
-}
-
-func (m *dataManager) uploadDevices(devices []coreDtos.Device, overwrite bool) error {
-	deviceClient := m.appSvc.DeviceClient()
-	for index := range devices {
-		device := devices[index]
-		_, err := deviceClient.DeviceNameExists(context.Background(), device.Name)
-		if err != nil && err.Code() != http.StatusNotFound {
-			return fmt.Errorf("failed check if device %s exist in system: %w", device.Name, err)
-		}
-
-		if err != nil && err.Code() == http.StatusNotFound {
-			addRequest := requests.NewAddDeviceRequest(device)
-			_, err := deviceClient.Add(context.Background(), []requests.AddDeviceRequest{addRequest})
-			if err != nil {
-				return fmt.Errorf("failed to add device %s to system: %w", device.Name, err)
-			}
-
-			m.appSvc.LoggingClient().Debugf("ARR Import: Added new device %s", device.Name)
-			continue
-		}
-
-		if !overwrite {
-			continue
-		}
-
-		updateDevice := coreDtos.UpdateDevice{
-			Name:           &device.Name,
-			Description:    &device.Description,
-			AdminState:     &device.AdminState,
-			OperatingState: &device.OperatingState,
-			ServiceName:    &device.ServiceName,
-			ProfileName:    &device.ProfileName,
-			Labels:         device.Labels,
-			Location:       device.Location,
-			AutoEvents:     device.AutoEvents,
-			Protocols:      device.Protocols,
-			Tags:           device.Tags,
-			Properties:     device.Properties,
-		}
-		updateRequest := requests.NewUpdateDeviceRequest(updateDevice)
-		_, err = deviceClient.Update(context.Background(), []requests.UpdateDeviceRequest{updateRequest})
-		if err != nil {
-			return fmt.Errorf("failed to update device %s in system: %w", device.Name, err)
-		}
-
-		m.appSvc.LoggingClient().Debugf("ARR Import: Updated existing device %s", device.Name)
+		Name:         data.Name,
+		Events:       newEventStore(data.RecordedEvents),
+		Devices:      utils.SliceToMap(data.Devices, func(d coreDtos.Device) string { return d.Name }),
+		Profiles:     utils.SliceToMap(data.Profiles, func(dp coreDtos.DeviceProfile) string { return dp.Name }),
+		Envelopes:    data.Envelopes,
+		Messages:     data.Messages,
+		DeadLetters:  data.DeadLetters,
+		Telemetry:    data.Telemetry,
+		SystemEvents: data.SystemEvents,
+		Annotations:  data.Annotations,
+		Metadata:     data.Metadata,
 	}
 
-	return nil
-}
-
-func (m *dataManager) uploadProfiles(profiles []coreDtos.DeviceProfile, overwrite bool) error {
-	profileClient := m.appSvc.DeviceProfileClient()
-	for _, profile := range profiles {
-		_, err := profileClient.DeviceProfileByName(context.Background(), profile.Name)
-		if err != nil && err.Code() != http.StatusNotFound {
-			return fmt.Errorf("failed check if profile %s exists in system: %w", profile.Name, err)
-		}
-
-		if err != nil && err.Code() == http.StatusNotFound {
-			addRequest := requests.NewDeviceProfileRequest(profile)
-
-			_, err = profileClient.Add(context.Background(), []requests.DeviceProfileRequest{addRequest})
-			if err != nil {
-				return fmt.Errorf("failed to add device profile %s to system: %w", profile.Name, err)
-			}
-
-			m.appSvc.LoggingClient().Debugf("ARR Import: Add new device profile %s", profile.Name)
-			continue
-		}
-
-		if !overwrite {
-			continue
-		}
-
-		// System doesn't allow profiles to be updated as a whole and portions that can be updated,
-		// are just descriptive, so not attempting to do any Device Profile updates.
-		m.appSvc.LoggingClient().Debugf("ARR Import: Existing device profile %s not updated: Can not update existing device profiles", profile.Name)
+	if len(m.recordedData.Messages) > 0 {
+		m.appSvc.LoggingClient().Debugf("ARR Import: Imported %d opaque messages", len(m.recordedData.Messages))
+		return provisioningErr
 	}
 
-	return nil
+	m.appSvc.LoggingClient().Debugf("ARR Import: Imported %d events, %d devices and %d device profiles",
+		m.recordedData.Events.len(), len(m.recordedData.Devices), len(m.recordedData.Profiles))
+	return provisioningErr
 }
 
 // Pipeline functions
@@ -632,14 +1487,16 @@ func (m *dataManager) uploadProfiles(profiles []coreDtos.DeviceProfile, overwrit
 var countsNoDataError = errors.New("CountEvents function received nil data")
 var countsDataNotEventError = errors.New("CountEvents function received data that is not an Event")
 
-// countEvents counts the number of Events recorded so far. Must be called after any filters and before the Batch function.
+// countEvents counts the number of Events recorded so far and captures the MessageBus envelope metadata received
+// with each Event. Must be called after any filters and before the Batch function.
 // This count is used when reporting Recording Status
-func (m *dataManager) countEvents(_ appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+func (m *dataManager) countEvents(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
 	if data == nil {
 		return false, countsNoDataError
 	}
 
-	if _, ok := data.(coreDtos.Event); !ok {
+	event, ok := data.(coreDtos.Event)
+	if !ok {
 		return false, countsDataNotEventError
 	}
 
@@ -647,10 +1504,40 @@ func (m *dataManager) countEvents(_ appInterfaces.AppFunctionContext, data any)
 	defer m.recordingMutex.Unlock()
 
 	m.recordedEventCount++
+	m.recordingActive()
+	event = m.compensateClockSkew(ctx, event)
 
-	m.appSvc.LoggingClient().Debugf("ARR Event Count: received event to be recorded. Current event count is %d", m.recordedEventCount)
+	if m.metadataSnapshot != nil {
+		m.metadataSnapshot.addSeenDevice(event.DeviceName)
+	}
 
-	return true, data
+	if m.metrics != nil {
+		m.metrics.recorded(event.DeviceName, payloadSize(ctx))
+	}
+
+	if m.payloadSizes != nil {
+		m.payloadSizes.add(event, payloadSize(ctx))
+	}
+
+	if m.recordedEnvelopes != nil {
+		receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+		m.recordedEnvelopes[event.Id] = dtos.EnvelopeMetadata{
+			ReceivedTopic: receivedTopic,
+			CorrelationID: ctx.CorrelationID(),
+			ContentType:   ctx.InputContentType(),
+			ReceivedAt:    m.clock.Now().UnixNano(),
+		}
+	}
+
+	m.sessionLogger(m.recordingLabel, m.recordingCorrelationID).Debugf("ARR Event Count: received event to be recorded. Current event count is %d", m.recordedEventCount)
+
+	if m.readingsOnly {
+		// Stripped Events always compact
+		compact, _ := utils.CompactEvent(stripEvent(event))
+		return true, compact
+	}
+
+	return true, event
 }
 
 var batchNoDataError = errors.New("ProcessBatchedData function received nil data")
@@ -658,54 +1545,166 @@ var batchDataNotEventCollectionError = errors.New("ProcessBatchedData function r
 
 // processBatchedData processes the batched data for the current recording session
 func (m *dataManager) processBatchedData(_ appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
-	lc := m.appSvc.LoggingClient()
-
 	m.recordingMutex.Lock()
 	defer m.recordingMutex.Unlock()
 
+	lc := m.sessionLogger(m.recordingLabel, m.recordingCorrelationID)
+
 	// Check if record was canceled and exit early
 	if m.recordingStartedAt == nil {
 		return false, nil
 	}
 
-	// This stops recording of Events
-	m.appSvc.RemoveAllFunctionPipelines()
-	lc.Debug("ARR Process Recorded Data: Recording of Events has ended and functions pipeline has been removed")
+	// Continuous recordings keep recording, with the batch starting the next segment
+	if m.segmentRotation == nil {
+		// This stops recording of Events
+		m.removeSessionPipelines()
+		lc.Debug("ARR Process Recorded Data: Recording of Events has ended and functions pipeline has been removed")
+	}
 
 	if data == nil {
 		return false, batchNoDataError
 	}
 
 	events, ok := data.([]coreDtos.Event)
+	if !ok && m.readingsOnly {
+		events, ok = expandBatchedReadings(data)
+	}
 	if !ok {
 		return false, batchDataNotEventCollectionError
 	}
 
+	if m.metrics != nil {
+		m.metrics.batched(len(events))
+	}
+	m.pipelineStats.batched(len(events), m.clock.Now())
+
+	m.orderByOrigin(events)
+
+	if m.segmentRotation != nil {
+		m.rotateSegment(&recordedData{
+			Name:         m.recordingName,
+			Label:        m.recordingLabel,
+			Events:       newEventStore(events),
+			Envelopes:    m.takeEnvelopes(events),
+			DeadLetters:  m.recordedDeadLetters,
+			Telemetry:    m.recordedTelemetry,
+			SystemEvents: m.recordedSystemEvents,
+			Annotations:  m.recordedAnnotations,
+		}, lc)
+		m.recordedDeadLetters = nil
+		m.recordedTelemetry = nil
+		m.recordedSystemEvents = nil
+		m.recordedAnnotations = nil
+
+		return false, nil
+	}
+
 	duration := 0 * time.Second
 	if m.recordingStartedAt != nil {
-		duration = time.Since(*m.recordingStartedAt)
+		duration = m.clock.Since(*m.recordingStartedAt)
 	}
 
 	m.recordedData = &recordedData{
-		Events:   events,
-		Duration: duration,
+		Name:         m.recordingName,
+		Label:        m.recordingLabel,
+		Events:       newEventStore(events),
+		Duration:     duration,
+		Envelopes:    m.takeEnvelopes(events),
+		DeadLetters:  m.recordedDeadLetters,
+		Telemetry:    m.recordedTelemetry,
+		SystemEvents: m.recordedSystemEvents,
+		Annotations:  m.recordedAnnotations,
+		Metadata:     withGaps(m.recordingMetadata, m.stopBusWatch()),
+	}
+
+	// The final refresh captures any Devices first seen or changed since the last periodic refresh
+	if snapshot := m.stopMetadataWatch(); snapshot != nil {
+		snapshot.refresh(m.appSvc, true)
+		m.recordedData.Devices, m.recordedData.Profiles = snapshot.complete()
+		m.stampProfileHashes()
 	}
 
 	m.recordingStartedAt = nil
+	m.recordedEnvelopes = nil
+	m.scheduleNextSession()
+	m.recordedDeadLetters = nil
+	m.recordedTelemetry = nil
+	m.recordedSystemEvents = nil
+	m.recordedAnnotations = nil
 
 	lc.Debugf("ARR Process Recorded Data: %d events in %s have been saved for replay", len(events), duration.String())
 
+	m.sendNotification(recordingCompletedLabel, models.Normal,
+		fmt.Sprintf("Recording completed: %d events recorded in %s", len(events), duration.String()))
+	m.startCloudSync()
+
 	return false, nil
 }
 
+// takeEnvelopes returns the envelopes for the Events that made it into the batch, removing them from those captured
+// so far. Must be called while holding the recording mutex.
+func (m *dataManager) takeEnvelopes(events []coreDtos.Event) map[string]dtos.EnvelopeMetadata {
+	envelopes := make(map[string]dtos.EnvelopeMetadata)
+	for _, event := range events {
+		if envelope, ok := m.recordedEnvelopes[event.Id]; ok {
+			envelopes[event.Id] = envelope
+			delete(m.recordedEnvelopes, event.Id)
+		}
+	}
+
+	return envelopes
+}
+
+// relativeEventTopic returns the received topic with the base topic prefix removed, since the prefix is added
+// back when published. Returns false if the topic isn't a recognizable Event topic.
+func relativeEventTopic(receivedTopic string) (string, bool) {
+	eventsTopic := strings.Split(common.CoreDataEventSubscribeTopic, "/")[0] + "/"
+	if strings.HasPrefix(receivedTopic, eventsTopic) {
+		return receivedTopic, true
+	}
+
+	index := strings.Index(receivedTopic, "/"+eventsTopic)
+	if index < 0 {
+		return "", false
+	}
+
+	return receivedTopic[index+1:], true
+}
+
 func (m *dataManager) getServiceName(deviceName string) string {
 	m.recordingMutex.Lock()
 	defer m.recordingMutex.Unlock()
 
-	device := m.recordedData.Devices[deviceName]
+	device := m.replayData.Devices[deviceName]
 	if device == nil {
-		return "unknown-service"
+		return unknownServiceName
 	}
 
 	return device.ServiceName
 }
+
+// buildEventTopic returns the topic, relative to the base topic, the Event is published to by the device's service
+func buildEventTopic(serviceName string, event coreDtos.Event) string {
+	return common.BuildTopic(strings.Replace(common.CoreDataEventSubscribeTopic, "/#", "", 1),
+		serviceName, event.ProfileName, event.DeviceName, event.SourceName)
+}
+
+// newReplayAddEventRequest returns the AddEventRequest for the replayed Event. Its RequestId is the correlation ID
+// of the request which started the replay, if known, so the replayed Events can be traced back to it.
+func newReplayAddEventRequest(event coreDtos.Event, correlationID string) requests.AddEventRequest {
+	addEvent := requests.NewAddEventRequest(event)
+	if len(correlationID) > 0 {
+		addEvent.RequestId = correlationID
+	}
+
+	return addEvent
+}
+
+// eventTopicServiceName returns the Device Service name from the Event topic built by buildEventTopic. EdgeX names
+// can't contain a '/', so the name is the first level after the Event topic prefix.
+func eventTopicServiceName(topic string) string {
+	prefix := strings.Replace(common.CoreDataEventSubscribeTopic, "#", "", 1)
+	serviceName, _, _ := strings.Cut(strings.TrimPrefix(topic, prefix), "/")
+	return serviceName
+}
@@ -22,17 +22,22 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
 	"github.com/edgexfoundry/app-record-replay/internal/utils"
 	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
 	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
 	loggerMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger/mocks"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
 	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
 	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
 	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
 	"github.com/stretchr/testify/assert"
@@ -64,7 +69,7 @@ func TestMain(m *testing.M) {
 }
 
 func TestNewManager(t *testing.T) {
-	target := NewManager(&mocks.ApplicationService{}, 0)
+	target := NewManager(&mocks.ApplicationService{}, 0, clock.New(), nil, nil)
 	require.NotNil(t, target)
 	d := target.(*dataManager)
 	require.NotNil(t, d)
@@ -128,6 +133,57 @@ func TestDataManager_StartRecording(t *testing.T) {
 			StartRequest:       dtos.RecordRequest{},
 			ExpectedStartError: batchParametersNotSetError,
 		},
+		{
+			Name:         "Happy Path - Opaque",
+			StartRequest: dtos.RecordRequest{EventLimit: 100, Opaque: true},
+		},
+		{
+			Name:               "Fail Path - Opaque with filters",
+			StartRequest:       dtos.RecordRequest{EventLimit: 100, Opaque: true, IncludeDevices: []string{"test-device1"}},
+			ExpectedStartError: opaqueFiltersError,
+		},
+		{
+			Name:         "Happy Path - Readings only",
+			StartRequest: dtos.RecordRequest{EventLimit: 100, ReadingsOnly: true},
+		},
+		{
+			Name:               "Fail Path - Opaque readings only",
+			StartRequest:       dtos.RecordRequest{EventLimit: 100, Opaque: true, ReadingsOnly: true},
+			ExpectedStartError: readingsOnlyOpaqueError,
+		},
+		{
+			Name:         "Happy Path - Telemetry",
+			StartRequest: dtos.RecordRequest{EventLimit: 100, Telemetry: true},
+		},
+		{
+			Name:               "Fail Path - Opaque telemetry",
+			StartRequest:       dtos.RecordRequest{EventLimit: 100, Opaque: true, Telemetry: true},
+			ExpectedStartError: telemetryOpaqueError,
+		},
+		{
+			Name:         "Happy Path - System events",
+			StartRequest: dtos.RecordRequest{EventLimit: 100, Telemetry: true, SystemEvents: true},
+		},
+		{
+			Name:               "Fail Path - Opaque system events",
+			StartRequest:       dtos.RecordRequest{EventLimit: 100, Opaque: true, SystemEvents: true},
+			ExpectedStartError: systemEventsOpaqueError,
+		},
+		{
+			Name: "Happy Path - Topic rules",
+			StartRequest: dtos.RecordRequest{
+				EventLimit: 100,
+				Topics: []dtos.TopicRule{
+					{Topic: "events/device/+/+/#", ExcludeDevices: []string{"test-device1"}},
+					{Topic: "events/app/#"},
+				},
+			},
+		},
+		{
+			Name:               "Fail Path - Invalid topic rule",
+			StartRequest:       dtos.RecordRequest{EventLimit: 100, Topics: []dtos.TopicRule{{Topic: "events/#/device"}}},
+			ExpectedStartError: invalidTopicRuleError,
+		},
 	}
 
 	for _, test := range tests {
@@ -137,12 +193,33 @@ func TestDataManager_StartRecording(t *testing.T) {
 			mockLogger.On("Debugf", mock.Anything, mock.Anything, mock.Anything)
 			mockSdk := &mocks.ApplicationService{}
 			mockSdk.On("LoggingClient").Return(mockLogger)
-			target := NewManager(mockSdk, 0).(*dataManager)
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+			target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
 
 			// Due to limitation of mocks with respect to function pointers, the best we can do is pass the expected number
 			// of mock.Anything parameters to match the number of expected pipeline functions pointers in the actual call.
 			var mockArgs []any
 
+			// Add mock parameter for captureTelemetry function
+			if test.StartRequest.Telemetry {
+				mockArgs = append(mockArgs, mock.Anything)
+			}
+
+			// Add mock parameter for captureSystemEvent function
+			if test.StartRequest.SystemEvents {
+				mockArgs = append(mockArgs, mock.Anything)
+			}
+
+			// Add mock parameter for decodeEvent function, which opaque recordings don't use
+			if !test.StartRequest.Opaque {
+				mockArgs = append(mockArgs, mock.Anything)
+			}
+
+			// Add mock parameter for topic router function
+			if len(test.StartRequest.Topics) > 0 {
+				mockArgs = append(mockArgs, mock.Anything)
+			}
+
 			// Add mock parameter for exclude profiles filter function
 			if len(test.StartRequest.ExcludeDeviceProfiles) > 0 {
 				mockArgs = append(mockArgs, mock.Anything)
@@ -173,7 +250,8 @@ func TestDataManager_StartRecording(t *testing.T) {
 				mockArgs = append(mockArgs, mock.Anything)
 			}
 
-			// Add three more for the expected countEvents, batch.Batch, processBatchedData pipeline functions
+			// Add three more for the expected countEvents, batch.Batch, processBatchedData pipeline functions or, for
+			// opaque recordings, the captureMessage, batch.Batch, processBatchedMessages pipeline functions
 			mockArgs = append(mockArgs, mock.Anything, mock.Anything, mock.Anything)
 
 			mockSdk.On("SetDefaultFunctionsPipeline", mockArgs...).Run(func(args mock.Arguments) {
@@ -194,6 +272,8 @@ func TestDataManager_StartRecording(t *testing.T) {
 			// simulate previous recorded data is present
 			target.recordedData = &recordedData{}
 			target.recordedEventCount = 100
+			target.payloadSizes = newPayloadSizeStats()
+			target.payloadSizes.add(coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName), 100)
 
 			startErr := target.StartRecording(test.StartRequest)
 
@@ -214,6 +294,28 @@ func TestDataManager_StartRecording(t *testing.T) {
 			assert.Zero(t, target.recordedEventCount)
 			assert.NotNil(t, target.recordingStartedAt)
 
+			// Payload sizes are tracked from the start of each recording, except for opaque recordings
+			if test.StartRequest.Opaque {
+				assert.Nil(t, target.payloadSizes)
+			} else {
+				require.NotNil(t, target.payloadSizes)
+				assert.Zero(t, target.payloadSizes.eventCount)
+			}
+
+			// Pushed Events join the pipeline after the decode, while opaque recordings have no pipeline for them
+			if test.StartRequest.Opaque {
+				assert.Nil(t, target.recordPipeline)
+			} else {
+				decodeEnd := 1
+				if test.StartRequest.Telemetry {
+					decodeEnd++
+				}
+				if test.StartRequest.SystemEvents {
+					decodeEnd++
+				}
+				assert.Len(t, target.recordPipeline, len(mockArgs)-decodeEnd)
+			}
+
 			mockSdk.AssertExpectations(t)
 
 			if len(test.StartRequest.IncludeDeviceProfiles) > 0 {
@@ -278,7 +380,7 @@ func TestDataManager_RecordingStatus(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
-			target := NewManager(nil, 0).(*dataManager)
+			target := NewManager(nil, 0, clock.New(), nil, nil).(*dataManager)
 
 			if test.ExpectedStatus.InProgress {
 				// Set up case when recording is in progress
@@ -289,10 +391,11 @@ func TestDataManager_RecordingStatus(t *testing.T) {
 				// Set up case when recording is finished and using recorded data
 				target.recordedData = &recordedData{
 					Duration: test.ExpectedStatus.Duration,
+					Events:   newEventStore(nil),
 				}
 
 				for i := 0; i < test.ExpectedStatus.EventCount; i++ {
-					target.recordedData.Events = append(target.recordedData.Events, coreDtos.Event{})
+					target.recordedData.Events.add(coreDtos.Event{})
 				}
 			}
 
@@ -333,7 +436,7 @@ func TestDataManager_CancelRecording(t *testing.T) {
 			mockSdk.On("LoggingClient").Return(mockLogger)
 			mockSdk.On("RemoveAllFunctionPipelines")
 
-			target := NewManager(mockSdk, 0).(*dataManager)
+			target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
 
 			if test.RecordingRunning {
 				now := time.Now()
@@ -378,14 +481,30 @@ func TestDataManager_StartReplay(t *testing.T) {
 			StartRequest:        goodRequest,
 			MaxReplayDelayLimit: time.Minute,
 			RecordedData: &recordedData{
-				Events: expectedEventData,
+				Events: newEventStore(expectedEventData),
+			},
+		},
+		{
+			Name:                "Happy Path - Full warm-up",
+			StartRequest:        dtos.ReplayRequest{ReplayRate: 1, RepeatCount: 2, Warmup: dtos.ReplayWarmupFull},
+			MaxReplayDelayLimit: time.Minute,
+			RecordedData: &recordedData{
+				Events: newEventStore(expectedEventData),
+			},
+		},
+		{
+			Name:                "Happy Path - Background warm-up",
+			StartRequest:        dtos.ReplayRequest{ReplayRate: 1, RepeatCount: 2, Warmup: dtos.ReplayWarmupBackground},
+			MaxReplayDelayLimit: time.Minute,
+			RecordedData: &recordedData{
+				Events: newEventStore(expectedEventData),
 			},
 		},
 		{
 			Name:         "Error Path - failed to publish",
 			StartRequest: goodRequest,
 			RecordedData: &recordedData{
-				Events: expectedEventData,
+				Events: newEventStore(expectedEventData),
 			},
 			ExpectedPublishError: errors.New("publish failed"),
 		},
@@ -396,7 +515,7 @@ func TestDataManager_StartReplay(t *testing.T) {
 			},
 			MaxReplayDelayLimit: 1 * time.Second,
 			RecordedData: &recordedData{
-				Events: expectedEventData,
+				Events: newEventStore(expectedEventData),
 			},
 			ExpectedReplayError: errors.New("delay exceeds the maximum replay delay"),
 		},
@@ -427,6 +546,36 @@ func TestDataManager_StartReplay(t *testing.T) {
 			RecordedData:       &recordedData{},
 			ExpectedStartError: invalidReplayCount,
 		},
+		{
+			Name:               "Error Path - Bad OnPublishError",
+			StartRequest:       dtos.ReplayRequest{ReplayRate: 1, OnPublishError: "ignore"},
+			RecordedData:       &recordedData{},
+			ExpectedStartError: invalidOnPublishError,
+		},
+		{
+			Name:               "Error Path - Bad Warmup",
+			StartRequest:       dtos.ReplayRequest{ReplayRate: 1, Warmup: "eager"},
+			RecordedData:       &recordedData{},
+			ExpectedStartError: invalidReplayWarmup,
+		},
+		{
+			Name:               "Error Path - Bad FanOut",
+			StartRequest:       dtos.ReplayRequest{ReplayRate: 1, FanOut: -1},
+			RecordedData:       &recordedData{},
+			ExpectedStartError: invalidFanOut,
+		},
+		{
+			Name:               "Error Path - Bad LatencyTimeout",
+			StartRequest:       dtos.ReplayRequest{ReplayRate: 1, LatencyTopic: "events/#", LatencyTimeout: -1},
+			RecordedData:       &recordedData{},
+			ExpectedStartError: invalidLatencyTimeout,
+		},
+		{
+			Name:               "Error Path - LatencyTopic with ShadowMode",
+			StartRequest:       dtos.ReplayRequest{ReplayRate: 1, LatencyTopic: "events/#", ShadowMode: true},
+			RecordedData:       &recordedData{},
+			ExpectedStartError: latencyShadowModeError,
+		},
 		{
 			Name:               "Error Path - Recording in progress",
 			RecordingRunning:   true,
@@ -454,11 +603,13 @@ func TestDataManager_StartReplay(t *testing.T) {
 				Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
 
 			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
 			mockSdk.On("LoggingClient").Return(mockLogger)
 			mockSdk.On("DeviceClient").Return(mockDeviceClient)
 			mockSdk.On("AppContext").Return(context.Background())
 			mockSdk.On("PublishWithTopic", expectedTopic, mock.Anything, common.ContentTypeJSON).Return(test.ExpectedPublishError)
-			target := NewManager(mockSdk, test.MaxReplayDelayLimit).(*dataManager)
+			mockSdk.On("NotificationClient").Return(nil)
+			target := NewManager(mockSdk, test.MaxReplayDelayLimit, clock.New(), nil, nil).(*dataManager)
 
 			target.recordingStartedAt = nil
 			target.replayStartedAt = nil
@@ -523,13 +674,242 @@ func TestDataManager_StartReplay(t *testing.T) {
 				return
 			}
 
-			expectedEventCount := len(test.RecordedData.Events) * test.StartRequest.RepeatCount
+			expectedEventCount := test.RecordedData.Events.len() * test.StartRequest.RepeatCount
 			assert.Equal(t, expectedEventCount, target.replayedEventCount)
 			assert.NotZero(t, target.replayedDuration)
 		})
 	}
 }
 
+func TestDataManager_StartReplay_Script(t *testing.T) {
+	tests := []struct {
+		Name               string
+		Script             string
+		ExpectedEventCount int
+		ExpectedValue      string
+		ExpectedStartError error
+		ExpectedRunError   string
+	}{
+		{
+			Name:               "Happy Path - all events match",
+			Script:             `return event.deviceName == "` + expectedDeviceName + `"`,
+			ExpectedEventCount: len(expectedEventData),
+			ExpectedValue:      "test1",
+		},
+		{
+			Name:               "Happy Path - no events match",
+			Script:             `return event.deviceName == "unknownDevice"`,
+			ExpectedEventCount: 0,
+		},
+		{
+			Name:               "Happy Path - events modified",
+			Script:             `event.readings[1].value = string.upper(event.readings[1].value) return true`,
+			ExpectedEventCount: len(expectedEventData),
+			ExpectedValue:      "TEST1",
+		},
+		{
+			Name:               "Error Path - invalid script",
+			Script:             `return event.deviceName ==`,
+			ExpectedStartError: invalidReplayScript,
+		},
+		{
+			Name:             "Error Path - script fails",
+			Script:           `error("bad event")`,
+			ExpectedRunError: "bad event",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockLogger := &loggerMocks.LoggingClient{}
+			mockLogger.On("Debugf", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			mockLogger.On("Errorf", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+			mockDeviceClient := &clientMocks.DeviceClient{}
+			mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+				Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+			mockContext := &mocks.AppFunctionContext{}
+			mockContext.On("LoggingClient").Return(mockLogger)
+			mockContext.On("PipelineId").Return("replay")
+
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+			mockSdk.On("LoggingClient").Return(mockLogger)
+			mockSdk.On("DeviceClient").Return(mockDeviceClient)
+			mockSdk.On("AppContext").Return(context.Background())
+			mockSdk.On("BuildContext", mock.Anything, common.ContentTypeJSON).Return(mockContext)
+			mockSdk.On("NotificationClient").Return(nil).Maybe()
+			var publishedMutex sync.Mutex
+			var published []coreDtos.Event
+			mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				publishedMutex.Lock()
+				defer publishedMutex.Unlock()
+				published = append(published, args.Get(1).(requests.AddEventRequest).Event)
+			})
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+			target.recordedData = &recordedData{
+				Events: newEventStore(expectedEventData),
+			}
+
+			err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 10, Script: test.Script})
+			if test.ExpectedStartError != nil {
+				require.ErrorIs(t, err, test.ExpectedStartError)
+				return
+			}
+
+			require.NoError(t, err)
+
+			// Wait for the replay to complete
+			for {
+				target.recordingMutex.Lock()
+				replayStartedAt := target.replayStartedAt
+				target.recordingMutex.Unlock()
+
+				if replayStartedAt == nil {
+					break
+				}
+
+				time.Sleep(500 * time.Millisecond)
+			}
+
+			target.recordingMutex.Lock()
+			defer target.recordingMutex.Unlock()
+
+			if len(test.ExpectedRunError) > 0 {
+				require.Error(t, target.replayError)
+				assert.Contains(t, target.replayError.Error(), test.ExpectedRunError)
+				return
+			}
+
+			require.NoError(t, target.replayError)
+			assert.Equal(t, test.ExpectedEventCount, target.replayedEventCount)
+			mockSdk.AssertNumberOfCalls(t, "PublishWithTopic", test.ExpectedEventCount)
+
+			publishedMutex.Lock()
+			defer publishedMutex.Unlock()
+			for _, event := range published {
+				require.Len(t, event.Readings, 1)
+				assert.Equal(t, test.ExpectedValue, event.Readings[0].Value)
+			}
+		})
+	}
+}
+
+func TestDataManager_StartReplay_Envelope(t *testing.T) {
+	mockLogger := &loggerMocks.LoggingClient{}
+	mockLogger.On("Debugf", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(mockLogger)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", "events/device/svc/p/d/s", mock.Anything, common.ContentTypeJSON).Return(nil)
+
+	events := []coreDtos.Event{
+		coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+		coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+	}
+	// Origins are far enough apart to exceed the max replay delay, so the replay only succeeds if the envelope
+	// received times are used for the timing.
+	events[1].Origin = events[0].Origin + int64(time.Hour)
+
+	target := NewManager(mockSdk, time.Second, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events:  newEventStore(events),
+		Devices: map[string]*coreDtos.Device{expectedDeviceName: {Name: expectedDeviceName}},
+		Envelopes: map[string]dtos.EnvelopeMetadata{
+			events[0].Id: {ReceivedTopic: "edgex/events/device/svc/p/d/s", ReceivedAt: 1000},
+			events[1].Id: {ReceivedTopic: "edgex/events/device/svc/p/d/s", ReceivedAt: 2000},
+		},
+	}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, UseEnvelopeTiming: true})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 100*time.Millisecond)
+
+	target.recordingMutex.Lock()
+	defer target.recordingMutex.Unlock()
+	require.NoError(t, target.replayError)
+	mockSdk.AssertNumberOfCalls(t, "PublishWithTopic", 2)
+}
+
+func TestDataManager_StartReplay_VirtualClock(t *testing.T) {
+	var origins []int64
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).
+		Run(func(args mock.Arguments) {
+			origins = append(origins, args.Get(1).(requests.AddEventRequest).Event.Origin)
+		}).
+		Return(nil)
+
+	events := []coreDtos.Event{
+		coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+		coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+	}
+	events[1].Origin = events[0].Origin + int64(10*time.Second)
+
+	start := time.Unix(1000, 0)
+	virtualClock := clock.NewVirtual(start)
+
+	target := NewManager(mockSdk, time.Minute, virtualClock, nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events:  newEventStore(events),
+		Devices: map[string]*coreDtos.Device{expectedDeviceName: {Name: expectedDeviceName}},
+	}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1})
+	require.NoError(t, err)
+
+	// The first Event is replayed immediately while the second waits for the virtual time to be advanced
+	require.Eventually(t, func() bool {
+		return target.ReplayStatus().EventCount == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, target.ReplayStatus().Running)
+
+	require.Eventually(t, func() bool {
+		virtualClock.Advance(time.Second)
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	assert.Equal(t, 2, status.EventCount)
+	assert.GreaterOrEqual(t, status.Duration, 10*time.Second)
+	require.Len(t, origins, 2)
+	assert.Equal(t, start.UnixNano(), origins[0])
+	assert.GreaterOrEqual(t, origins[1]-origins[0], int64(10*time.Second))
+}
+
+func TestRelativeEventTopic(t *testing.T) {
+	tests := []struct {
+		Name          string
+		ReceivedTopic string
+		Expected      string
+		ExpectedOk    bool
+	}{
+		{"Default prefix", "edgex/events/device/svc/p/d/s", "events/device/svc/p/d/s", true},
+		{"Multi-level prefix", "site1/edgex/events/device/svc/p/d/s", "events/device/svc/p/d/s", true},
+		{"No prefix", "events/device/svc/p/d/s", "events/device/svc/p/d/s", true},
+		{"Not events topic", "edgex/metrics/svc", "", false},
+		{"Empty", "", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, ok := relativeEventTopic(test.ReceivedTopic)
+			assert.Equal(t, test.ExpectedOk, ok)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
 func TestDataManager_StartReplay_Cancel(t *testing.T) {
 	// These values should allow time to cancel.
 	replayRequest := dtos.ReplayRequest{
@@ -568,15 +948,16 @@ func TestDataManager_StartReplay_Cancel(t *testing.T) {
 				Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
 
 			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
 			mockSdk.On("LoggingClient").Return(mockLogger)
 			mockSdk.On("DeviceClient").Return(mockDeviceClient)
 			mockSdk.On("AppContext").Return(appCtx)
 			mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-			target := NewManager(mockSdk, time.Minute).(*dataManager)
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
 
 			target.recordedData = &recordedData{
-				Events: expectedEventData,
+				Events: newEventStore(expectedEventData),
 			}
 
 			err := target.StartReplay(replayRequest)
@@ -685,15 +1066,17 @@ func TestDataManager_ReplayStatus(t *testing.T) {
 				Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
 
 			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
 			mockSdk.On("LoggingClient").Return(mockLogger)
 			mockSdk.On("DeviceClient").Return(mockDeviceClient)
 			mockSdk.On("AppContext").Return(context.Background())
 			mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(test.ExpectedReplayError)
+			mockSdk.On("NotificationClient").Return(nil)
 
-			target := NewManager(mockSdk, time.Minute).(*dataManager)
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
 
 			target.recordedData = &recordedData{
-				Events: expectedEventData,
+				Events: newEventStore(expectedEventData),
 			}
 
 			target.replayStartedAt = nil
@@ -801,15 +1184,16 @@ func TestDataManager_CancelReplay(t *testing.T) {
 				Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
 
 			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
 			mockSdk.On("LoggingClient").Return(mockLogger)
 			mockSdk.On("DeviceClient").Return(mockDeviceClient)
 			mockSdk.On("AppContext").Return(context.Background())
 			mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-			target := NewManager(mockSdk, time.Minute).(*dataManager)
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
 
 			target.recordedData = &recordedData{
-				Events: expectedEventData,
+				Events: newEventStore(expectedEventData),
 			}
 
 			target.replayStartedAt = nil
@@ -877,11 +1261,11 @@ func TestDataManager_ExportRecordedData(t *testing.T) {
 		MockProfileError     edgexErr.EdgeX
 		ExpectedError        error
 	}{
-		{"Valid", &recordedData{Events: expectedExportedData.RecordedEvents}, &expectedExportedData, nil, nil, nil},
+		{"Valid", &recordedData{Events: newEventStore(expectedExportedData.RecordedEvents)}, &expectedExportedData, nil, nil, nil},
 		{"No data", nil, nil, nil, nil, noRecordedData},
 		{"No Events", &recordedData{}, nil, nil, nil, noEventsRecorded},
-		{"Device load err", &recordedData{Events: expectedExportedData.RecordedEvents}, nil, edgexErr.NewCommonEdgeXWrapper(errors.New("failed to load device")), nil, errors.New("failed to load device")},
-		{"Profile load err", &recordedData{Events: expectedExportedData.RecordedEvents}, nil, nil, edgexErr.NewCommonEdgeXWrapper(errors.New("failed to load device profile")), errors.New("failed to load device profile")},
+		{"Device load err", &recordedData{Events: newEventStore(expectedExportedData.RecordedEvents)}, nil, edgexErr.NewCommonEdgeXWrapper(errors.New("failed to load device")), nil, errors.New("failed to load device")},
+		{"Profile load err", &recordedData{Events: newEventStore(expectedExportedData.RecordedEvents)}, nil, nil, edgexErr.NewCommonEdgeXWrapper(errors.New("failed to load device profile")), errors.New("failed to load device profile")},
 	}
 
 	for _, test := range tests {
@@ -908,7 +1292,7 @@ func TestDataManager_ExportRecordedData(t *testing.T) {
 			mockSdk.On("DeviceClient").Return(mockDeviceClient)
 			mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
 
-			target := NewManager(mockSdk, time.Minute).(*dataManager)
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
 
 			target.recordedData = test.RecordedData
 
@@ -995,8 +1379,9 @@ func TestDataManager_ImportRecordedData_NoDataErrors(t *testing.T) {
 			mockSdk.On("LoggingClient").Return(mockLogger)
 			mockSdk.On("DeviceClient").Return(mockDeviceClient)
 			mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
 
-			target := NewManager(mockSdk, time.Minute).(*dataManager)
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
 
 			now := time.Now()
 
@@ -1008,7 +1393,7 @@ func TestDataManager_ImportRecordedData_NoDataErrors(t *testing.T) {
 				target.replayStartedAt = &now
 			}
 
-			err := target.ImportRecordedData(test.ImportData, test.OverwriteFiles)
+			err := target.ImportRecordedData(test.ImportData, test.OverwriteFiles, false)
 
 			if test.ExpectedError != nil {
 				require.Error(t, err)
@@ -1019,11 +1404,11 @@ func TestDataManager_ImportRecordedData_NoDataErrors(t *testing.T) {
 			require.NoError(t, err)
 
 			require.NotNil(t, target.recordedData)
-			require.NotEmpty(t, target.recordedData.Events)
+			require.NotZero(t, target.recordedData.Events.len())
 			require.NotEmpty(t, target.recordedData.Devices)
 			require.NotEmpty(t, target.recordedData.Profiles)
 
-			assert.Equal(t, test.ImportData.RecordedEvents, target.recordedData.Events)
+			assert.Equal(t, test.ImportData.RecordedEvents, target.recordedData.Events.events())
 			for _, expectedDevice := range test.ImportData.Devices {
 				_, exists := target.recordedData.Devices[expectedDevice.Name]
 				assert.True(t, exists, fmt.Sprintf("Expected device %s not found in actual devices: %v", expectedDevice.Name, target.recordedData.Devices))
@@ -1123,10 +1508,11 @@ func TestDataManager_ImportRecordedData_DataErrors(t *testing.T) {
 			mockSdk.On("LoggingClient").Return(mockLogger)
 			mockSdk.On("DeviceClient").Return(mockDeviceClient)
 			mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
 
-			target := NewManager(mockSdk, time.Minute).(*dataManager)
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
 
-			err := target.ImportRecordedData(test.ImportData, true)
+			err := target.ImportRecordedData(test.ImportData, true, false)
 
 			require.Error(t, err)
 			assert.ErrorContains(t, err, test.ExpectedError.Error(), fmt.Sprintf("Actual error is: %v", err))
@@ -1264,9 +1650,15 @@ func TestDataManager_CountEvents(t *testing.T) {
 			mockSdk := &mocks.ApplicationService{}
 			mockSdk.On("LoggingClient").Return(mockLogger)
 
-			target := NewManager(mockSdk, 0).(*dataManager)
+			mockContext := &mocks.AppFunctionContext{}
+			mockContext.On("GetValue", interfaces.RECEIVEDTOPIC).Return("edgex/events/device/svc/profile/device/source", true)
+			mockContext.On("CorrelationID").Return("123")
+			mockContext.On("InputContentType").Return(common.ContentTypeJSON)
+
+			target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+			target.recordedEnvelopes = make(map[string]dtos.EnvelopeMetadata)
 			for i := 0; i < test.ExpectedCount; i++ {
-				continueExecution, actual := target.countEvents(nil, test.Data)
+				continueExecution, actual := target.countEvents(mockContext, test.Data)
 				if test.ExpectedError != nil {
 					require.Equal(t, test.ExpectedError, actual)
 					return
@@ -1277,6 +1669,12 @@ func TestDataManager_CountEvents(t *testing.T) {
 			}
 
 			assert.Equal(t, test.ExpectedCount, target.recordedEventCount)
+			require.Len(t, target.recordedEnvelopes, 1)
+			envelope := target.recordedEnvelopes[""]
+			assert.Equal(t, "edgex/events/device/svc/profile/device/source", envelope.ReceivedTopic)
+			assert.Equal(t, "123", envelope.CorrelationID)
+			assert.Equal(t, common.ContentTypeJSON, envelope.ContentType)
+			assert.NotZero(t, envelope.ReceivedAt)
 		})
 	}
 }
@@ -1309,8 +1707,9 @@ func TestDataManager_ProcessBatchedData(t *testing.T) {
 			mockSdk := &mocks.ApplicationService{}
 			mockSdk.On("RemoveAllFunctionPipelines")
 			mockSdk.On("LoggingClient").Return(mockLogger)
+			mockSdk.On("NotificationClient").Return(nil)
 
-			target := NewManager(mockSdk, 0).(*dataManager)
+			target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
 
 			if !test.RecordingPreviouslyCanceled {
 				now := time.Now()
@@ -1330,7 +1729,7 @@ func TestDataManager_ProcessBatchedData(t *testing.T) {
 
 			require.False(t, continueExecution)
 			require.NotNil(t, target.recordedData)
-			assert.Equal(t, expectedBatchedEvents, target.recordedData.Events)
+			assert.Equal(t, expectedBatchedEvents, target.recordedData.Events.events())
 			assert.NotZero(t, target.recordedData.Duration)
 
 			mockSdk.AssertExpectations(t)
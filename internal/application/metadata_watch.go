@@ -0,0 +1,187 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+// MetadataWatchIntervalAppSetting is the interval at which the Devices and Device Profiles referenced by the
+// Events recorded so far are refreshed from Core Metadata during a recording. Disabled when not set.
+const MetadataWatchIntervalAppSetting = "MetadataWatchInterval"
+
+// metadataSnapshot holds the latest Devices and Device Profiles for the Events recorded so far.
+// Devices deleted mid-recording keep their last known state.
+type metadataSnapshot struct {
+	lc       logger.LoggingClient
+	mutex    sync.Mutex
+	stopped  bool
+	seen     map[string]struct{}
+	devices  map[string]*coreDtos.Device
+	profiles map[string]*coreDtos.DeviceProfile
+}
+
+func newMetadataSnapshot(lc logger.LoggingClient) *metadataSnapshot {
+	return &metadataSnapshot{
+		lc:       lc,
+		seen:     make(map[string]struct{}),
+		devices:  make(map[string]*coreDtos.Device),
+		profiles: make(map[string]*coreDtos.DeviceProfile),
+	}
+}
+
+func (s *metadataSnapshot) addSeenDevice(deviceName string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.seen[deviceName] = struct{}{}
+}
+
+// refresh loads the current state of the seen Devices and their Device Profiles. The Core Metadata calls are
+// made without holding the lock so Events can continue to be recorded. Once final is set no further refreshes
+// are stored, so a slow refresh can't overwrite the final state with an older one.
+func (s *metadataSnapshot) refresh(appSvc appInterfaces.ApplicationService, final bool) {
+	lc := s.lc
+
+	s.mutex.Lock()
+	deviceNames := make([]string, 0, len(s.seen))
+	for name := range s.seen {
+		deviceNames = append(deviceNames, name)
+	}
+	s.mutex.Unlock()
+
+	devices := make(map[string]*coreDtos.Device)
+	profiles := make(map[string]*coreDtos.DeviceProfile)
+	for _, name := range deviceNames {
+		response, err := appSvc.DeviceClient().DeviceByName(context.Background(), name)
+		if err != nil {
+			lc.Warnf("ARR Metadata Watch: unable to refresh device %s, keeping last known state: %v", name, err)
+			continue
+		}
+		devices[name] = &response.Device
+
+		if profiles[response.Device.ProfileName] != nil {
+			continue
+		}
+
+		profileResponse, err := appSvc.DeviceProfileClient().DeviceProfileByName(context.Background(), response.Device.ProfileName)
+		if err != nil {
+			lc.Warnf("ARR Metadata Watch: unable to refresh device profile %s, keeping last known state: %v", response.Device.ProfileName, err)
+			continue
+		}
+		profiles[response.Device.ProfileName] = &profileResponse.Profile
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stopped {
+		return
+	}
+
+	for name, device := range devices {
+		s.devices[name] = device
+	}
+	for name, profile := range profiles {
+		s.profiles[name] = profile
+	}
+
+	s.stopped = final
+
+	lc.Debugf("ARR Metadata Watch: snapshot refreshed with %d devices and %d device profiles", len(s.devices), len(s.profiles))
+}
+
+// complete returns copies of the snapshot's Devices and Device Profiles. Nil maps are returned if any of the seen
+// Devices or their Device Profiles were never loaded, so they are loaded on demand the same as without the watch.
+func (s *metadataSnapshot) complete() (map[string]*coreDtos.Device, map[string]*coreDtos.DeviceProfile) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	devices := make(map[string]*coreDtos.Device)
+	profiles := make(map[string]*coreDtos.DeviceProfile)
+	for name := range s.seen {
+		device := s.devices[name]
+		if device == nil || s.profiles[device.ProfileName] == nil {
+			return nil, nil
+		}
+		devices[name] = device
+		profiles[device.ProfileName] = s.profiles[device.ProfileName]
+	}
+
+	return devices, profiles
+}
+
+// getMetadataWatchInterval returns the configured metadata watch interval. Zero is returned if the watch is disabled.
+func (m *dataManager) getMetadataWatchInterval() (time.Duration, error) {
+	value := m.appSvc.ApplicationSettings()[MetadataWatchIntervalAppSetting]
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid %s value '%s', must be a duration greater than 0", MetadataWatchIntervalAppSetting, value)
+	}
+
+	return interval, nil
+}
+
+// startMetadataWatch starts periodically refreshing the metadata snapshot for the current recording.
+// Must be called while holding the recording mutex.
+func (m *dataManager) startMetadataWatch(interval time.Duration) {
+	lc := m.sessionLogger(m.recordingLabel, m.recordingCorrelationID)
+	snapshot := newMetadataSnapshot(lc)
+	ctx, cancel := context.WithCancel(context.Background())
+	m.metadataSnapshot = snapshot
+	m.metadataWatchCancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				snapshot.refresh(m.appSvc, false)
+			}
+		}
+	}()
+
+	lc.Debugf("ARR Metadata Watch: started with interval of %s", interval.String())
+}
+
+// stopMetadataWatch stops the metadata watch, if running, and returns the final snapshot.
+// Must be called while holding the recording mutex.
+func (m *dataManager) stopMetadataWatch() *metadataSnapshot {
+	snapshot := m.metadataSnapshot
+	if m.metadataWatchCancel != nil {
+		m.metadataWatchCancel()
+	}
+
+	m.metadataSnapshot = nil
+	m.metadataWatchCancel = nil
+
+	return snapshot
+}
@@ -0,0 +1,116 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_GetMetadataWatchInterval(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Value         string
+		Expected      time.Duration
+		ExpectedError bool
+	}{
+		{"Not set", "", 0, false},
+		{"Valid", "30s", 30 * time.Second, false},
+		{"Bad format", "bad", 0, true},
+		{"Zero", "0s", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("ApplicationSettings").Return(map[string]string{MetadataWatchIntervalAppSetting: test.Value})
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+			actual, err := target.getMetadataWatchInterval()
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestMetadataSnapshot_Refresh(t *testing.T) {
+	device := coreDtos.Device{Name: "D1", ProfileName: "P1", Description: "original"}
+	updatedDevice := coreDtos.Device{Name: "D1", ProfileName: "P1", Description: "updated"}
+	profile := coreDtos.DeviceProfile{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "P1"}}
+	notFound := edgexErr.NewCommonEdgeXWrapper(errors.New("device not found"))
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, "D1").
+		Return(responses.DeviceResponse{Device: device}, nil).Once()
+	mockDeviceClient.On("DeviceByName", mock.Anything, "D1").
+		Return(responses.DeviceResponse{Device: updatedDevice}, nil).Once()
+	mockDeviceClient.On("DeviceByName", mock.Anything, "D1").
+		Return(responses.DeviceResponse{}, notFound)
+
+	mockProfileClient := &clientMocks.DeviceProfileClient{}
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, "P1").
+		Return(responses.DeviceProfileResponse{Profile: profile}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+
+	snapshot := newMetadataSnapshot(logger.NewMockClient())
+	snapshot.addSeenDevice("D1")
+
+	snapshot.refresh(mockSdk, false)
+	devices, profiles := snapshot.complete()
+	require.NotNil(t, devices)
+	assert.Equal(t, "original", devices["D1"].Description)
+	assert.Equal(t, "P1", profiles["P1"].Name)
+
+	// Changes made mid-recording replace the earlier state
+	snapshot.refresh(mockSdk, false)
+	devices, _ = snapshot.complete()
+	assert.Equal(t, "updated", devices["D1"].Description)
+
+	// Deleted devices keep their last known state
+	snapshot.refresh(mockSdk, true)
+	devices, _ = snapshot.complete()
+	assert.Equal(t, "updated", devices["D1"].Description)
+
+	// Devices never loaded leave the snapshot incomplete so they are loaded on demand
+	snapshot.addSeenDevice("D2")
+	devices, profiles = snapshot.complete()
+	assert.Nil(t, devices)
+	assert.Nil(t, profiles)
+}
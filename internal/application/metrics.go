@@ -0,0 +1,136 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"strconv"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	bootstrapInterfaces "github.com/edgexfoundry/go-mod-bootstrap/v3/bootstrap/interfaces"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+const (
+	// RecordedEventsMetricName is the counter of Events, or opaque messages, recorded
+	RecordedEventsMetricName = "RecordedEvents"
+	// RecordedBytesMetricName is the counter of payload bytes of the Events, or opaque messages, recorded
+	RecordedBytesMetricName = "RecordedBytes"
+	// RecordedDeviceEventsMetricName is the prefix of the counters of Events recorded for each device. Each device's
+	// counter is named with the device name appended and is tagged with the device name.
+	RecordedDeviceEventsMetricName = "RecordedDeviceEvents"
+	// RecordingPendingEventsMetricName is the gauge of Events, or opaque messages, recorded but still waiting in the
+	// batch, which shows how much the recording is holding before it is saved or rotated
+	RecordingPendingEventsMetricName = "RecordingPendingEvents"
+	// RecordedBatchSizeMetricName is the histogram of the sizes of the completed recording batches
+	RecordedBatchSizeMetricName = "RecordedBatchSize"
+	// ReplaySchedulingErrorMetricName is the histogram of the scheduling errors of the replayed Events, or opaque
+	// messages, in microseconds. Each is the time the Event was published less the time it was due at the replay rate.
+	ReplaySchedulingErrorMetricName = "ReplaySchedulingError"
+
+	deviceMetricTag = "device"
+	// payloadSizeKey is the context key the size of the raw payload is passed under from decodeEvent to countEvents,
+	// since the payload is no longer available once decoded
+	payloadSizeKey = "arrpayloadsize"
+	// batchSizeSampleSize is the number of batch sizes the histogram samples
+	batchSizeSampleSize = 1028
+	// schedulingErrorSampleSize is the number of scheduling errors the histograms sample
+	schedulingErrorSampleSize = 1028
+)
+
+// recordingMetrics publishes the recording counters through the SDK's Metrics Manager, so the record pipeline can be
+// tuned at runtime, along with the replay scheduling error. The metrics are only reported when enabled in the
+// Writable.Telemetry configuration. Must only be used while holding the recording mutex, except for the scheduling
+// error histogram which is safe for concurrent use.
+type recordingMetrics struct {
+	manager         bootstrapInterfaces.MetricsManager
+	lc              logger.LoggingClient
+	events          gometrics.Counter
+	bytes           gometrics.Counter
+	pending         gometrics.Gauge
+	batchSize       gometrics.Histogram
+	schedulingError gometrics.Histogram
+	devices         map[string]gometrics.Counter
+}
+
+// newRecordingMetrics registers the recording metrics. Metrics which fail to register are still counted, but not
+// reported.
+func newRecordingMetrics(manager bootstrapInterfaces.MetricsManager, lc logger.LoggingClient) *recordingMetrics {
+	metrics := &recordingMetrics{
+		manager:         manager,
+		lc:              lc,
+		events:          gometrics.NewCounter(),
+		bytes:           gometrics.NewCounter(),
+		pending:         gometrics.NewGauge(),
+		batchSize:       gometrics.NewHistogram(gometrics.NewUniformSample(batchSizeSampleSize)),
+		schedulingError: gometrics.NewHistogram(gometrics.NewUniformSample(schedulingErrorSampleSize)),
+		devices:         make(map[string]gometrics.Counter),
+	}
+
+	metrics.register(RecordedEventsMetricName, metrics.events, nil)
+	metrics.register(RecordedBytesMetricName, metrics.bytes, nil)
+	metrics.register(RecordingPendingEventsMetricName, metrics.pending, nil)
+	metrics.register(RecordedBatchSizeMetricName, metrics.batchSize, nil)
+	metrics.register(ReplaySchedulingErrorMetricName, metrics.schedulingError, nil)
+
+	return metrics
+}
+
+func (r *recordingMetrics) register(name string, item any, tags map[string]string) {
+	if err := r.manager.Register(name, item, tags); err != nil {
+		r.lc.Warnf("ARR Metrics: unable to register %s metric: %v", name, err)
+	}
+}
+
+// recorded counts an Event, or opaque message, with the payload size as recorded. The device name is empty for
+// opaque messages.
+func (r *recordingMetrics) recorded(deviceName string, size int) {
+	r.events.Inc(1)
+	r.bytes.Inc(int64(size))
+	r.pending.Update(r.pending.Value() + 1)
+
+	if len(deviceName) == 0 {
+		return
+	}
+
+	counter, found := r.devices[deviceName]
+	if !found {
+		counter = gometrics.NewCounter()
+		r.devices[deviceName] = counter
+		r.register(RecordedDeviceEventsMetricName+"-"+deviceName, counter, map[string]string{deviceMetricTag: deviceName})
+	}
+
+	counter.Inc(1)
+}
+
+// batched records the size of a completed batch, which is no longer pending
+func (r *recordingMetrics) batched(size int) {
+	r.batchSize.Update(int64(size))
+	r.pending.Update(max(r.pending.Value()-int64(size), 0))
+}
+
+// reset clears the pending count when a recording starts or ends, since Events left in a canceled batch are dropped
+func (r *recordingMetrics) reset() {
+	r.pending.Update(0)
+}
+
+// payloadSize returns the size of the raw payload recorded by decodeEvent in the context
+func payloadSize(ctx appInterfaces.AppFunctionContext) int {
+	value, _ := ctx.GetValue(payloadSizeKey)
+	size, _ := strconv.Atoi(value)
+	return size
+}
@@ -0,0 +1,107 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	bootstrapMocks "github.com/edgexfoundry/go-mod-bootstrap/v3/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	gometrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingMetrics(t *testing.T) {
+	registered := make(map[string]any)
+	mockMetrics := &bootstrapMocks.MetricsManager{}
+	mockMetrics.On("Register", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { registered[args.String(0)] = args.Get(1) }).
+		Return(nil)
+
+	target := newRecordingMetrics(mockMetrics, logger.NewMockClient())
+	assert.Len(t, registered, 5)
+
+	target.recorded(expectedDeviceName, 100)
+	target.recorded(expectedDeviceName, 50)
+	target.recorded("", 10)
+
+	assert.Equal(t, int64(3), target.events.Count())
+	assert.Equal(t, int64(160), target.bytes.Count())
+	assert.Equal(t, int64(3), target.pending.Value())
+
+	// The device's counter is registered once, tagged with the device name
+	deviceMetricName := RecordedDeviceEventsMetricName + "-" + expectedDeviceName
+	require.Contains(t, registered, deviceMetricName)
+	assert.Equal(t, int64(2), registered[deviceMetricName].(gometrics.Counter).Count())
+	mockMetrics.AssertNumberOfCalls(t, "Register", 6)
+	mockMetrics.AssertCalled(t, "Register", deviceMetricName, mock.Anything, map[string]string{deviceMetricTag: expectedDeviceName})
+
+	target.batched(2)
+	assert.Equal(t, int64(1), target.pending.Value())
+	assert.Equal(t, int64(1), target.batchSize.Count())
+	assert.Equal(t, int64(2), target.batchSize.Max())
+
+	// Events counted before the pending count was reset aren't pending
+	target.reset()
+	target.batched(2)
+	assert.Equal(t, int64(0), target.pending.Value())
+}
+
+func TestDataManager_CountEvents_Metrics(t *testing.T) {
+	mockMetrics := &bootstrapMocks.MetricsManager{}
+	mockMetrics.On("Register", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	lc := logger.NewMockClient()
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(lc)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, mockMetrics).(*dataManager)
+
+	ctx := pkg.NewAppFuncContextForTest("123", lc)
+	ctx.AddValue(payloadSizeKey, "42")
+
+	continuePipeline, _ := target.countEvents(ctx, coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName))
+	require.True(t, continuePipeline)
+
+	assert.Equal(t, int64(1), target.metrics.events.Count())
+	assert.Equal(t, int64(42), target.metrics.bytes.Count())
+	assert.Equal(t, int64(1), target.metrics.devices[expectedDeviceName].Count())
+}
+
+func TestDataManager_ReplayIteration_Metrics(t *testing.T) {
+	mockMetrics := &bootstrapMocks.MetricsManager{}
+	mockMetrics.On("Register", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, clock.NewVirtual(time.Unix(0, 0)), nil, mockMetrics).(*dataManager)
+
+	it := target.startReplayIteration(1, 1)
+	scheduled := it.scheduledAt(0)
+	it.published(scheduled, scheduled.Add(1500*time.Microsecond))
+
+	assert.Equal(t, int64(1), target.metrics.schedulingError.Count())
+	assert.Equal(t, int64(1500), target.metrics.schedulingError.Max())
+}
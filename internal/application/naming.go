@@ -0,0 +1,71 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+const (
+	// RecordingNameTemplateAppSetting is the name template applied to recordings started without a name.
+	// Supported placeholders are {date}, {time}, {hostname}, {devices} and {seq}.
+	RecordingNameTemplateAppSetting = "RecordingNameTemplate"
+
+	allDevicesName = "all"
+)
+
+// buildRecordingName returns the name from the request if set, otherwise the name generated from the
+// RecordingNameTemplate App Setting. An empty name is returned if neither is set.
+// Must be called while holding the recording mutex.
+func (m *dataManager) buildRecordingName(request dtos.RecordRequest, now time.Time) string {
+	if len(request.Name) > 0 {
+		return request.Name
+	}
+
+	template := m.appSvc.ApplicationSettings()[RecordingNameTemplateAppSetting]
+	if len(template) == 0 {
+		return ""
+	}
+
+	m.recordingSequence++
+
+	devices := allDevicesName
+	if len(request.IncludeDevices) > 0 {
+		devices = strings.Join(request.IncludeDevices, "+")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown-host"
+	}
+
+	// Date and time are formatted so that the generated names sort chronologically
+	replacer := strings.NewReplacer(
+		"{date}", now.Format("20060102"),
+		"{time}", now.Format("150405"),
+		"{hostname}", hostname,
+		"{devices}", devices,
+		"{seq}", fmt.Sprintf("%04d", m.recordingSequence),
+	)
+
+	return replacer.Replace(template)
+}
@@ -0,0 +1,62 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_BuildRecordingName(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	now := time.Date(2023, 7, 4, 13, 5, 9, 0, time.UTC)
+
+	tests := []struct {
+		Name     string
+		Template string
+		Request  dtos.RecordRequest
+		Expected []string
+	}{
+		{"Request name", "ignored-{seq}", dtos.RecordRequest{Name: "golden"}, []string{"golden", "golden"}},
+		{"No template", "", dtos.RecordRequest{}, []string{"", ""}},
+		{"All placeholders", "{date}-{time}-{hostname}-{devices}-{seq}", dtos.RecordRequest{},
+			[]string{"20230704-130509-" + hostname + "-all-0001", "20230704-130509-" + hostname + "-all-0002"}},
+		{"Device filter", "{devices}_{seq}", dtos.RecordRequest{IncludeDevices: []string{"D1", "D2"}},
+			[]string{"D1+D2_0001", "D1+D2_0002"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{RecordingNameTemplateAppSetting: test.Template})
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+			for _, expected := range test.Expected {
+				assert.Equal(t, expected, target.buildRecordingName(test.Request, now))
+			}
+		})
+	}
+}
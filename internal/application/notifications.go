@@ -0,0 +1,55 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+)
+
+const (
+	notificationSender   = "app-record-replay"
+	notificationCategory = "record-replay"
+
+	recordingCompletedLabel = "recording-completed"
+	replayFailedLabel       = "replay-failed"
+	sessionStartFailedLabel = "session-start-failed"
+)
+
+// sendNotification sends a notification to support-notifications so the recipients subscribed to the
+// record-replay category and/or label are alerted. Nothing is sent if the Notification client isn't configured.
+// The notification is sent asynchronously since callers are typically holding the recording mutex.
+func (m *dataManager) sendNotification(label string, severity string, content string) {
+	client := m.appSvc.NotificationClient()
+	if client == nil {
+		return
+	}
+
+	notification := coreDtos.NewNotification([]string{label}, notificationCategory, content, notificationSender, severity)
+
+	go func() {
+		_, err := client.SendNotification(context.Background(), []requests.AddNotificationRequest{requests.NewAddNotificationRequest(notification)})
+		if err != nil {
+			m.appSvc.LoggingClient().Errorf("ARR Notification: failed to send %s notification: %v", label, err)
+			return
+		}
+
+		m.appSvc.LoggingClient().Debugf("ARR Notification: %s notification sent", label)
+	}()
+}
@@ -0,0 +1,89 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	loggerMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_SendNotification(t *testing.T) {
+	tests := []struct {
+		Name          string
+		NoClient      bool
+		ExpectedError edgexErr.EdgeX
+	}{
+		{Name: "Happy Path - notification sent"},
+		{Name: "Happy Path - notification client not configured", NoClient: true},
+		{Name: "Error Path - send failed", ExpectedError: edgexErr.NewCommonEdgeX(edgexErr.KindServerError, "send failed", nil)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			sent := make(chan requests.AddNotificationRequest, 1)
+
+			mockLogger := &loggerMocks.LoggingClient{}
+			mockLogger.On("Debugf", mock.Anything, mock.Anything)
+			mockLogger.On("Errorf", mock.Anything, mock.Anything, mock.Anything)
+
+			mockNotificationClient := &clientMocks.NotificationClient{}
+			mockNotificationClient.On("SendNotification", mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					sent <- args.Get(1).([]requests.AddNotificationRequest)[0]
+				}).
+				Return(nil, test.ExpectedError)
+
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(mockLogger)
+			if test.NoClient {
+				mockSdk.On("NotificationClient").Return(nil)
+			} else {
+				mockSdk.On("NotificationClient").Return(mockNotificationClient)
+			}
+
+			target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+			target.sendNotification(replayFailedLabel, models.Critical, "replay failed")
+
+			if test.NoClient {
+				mockNotificationClient.AssertNotCalled(t, "SendNotification", mock.Anything, mock.Anything)
+				return
+			}
+
+			select {
+			case request := <-sent:
+				assert.Equal(t, notificationCategory, request.Notification.Category)
+				assert.Equal(t, notificationSender, request.Notification.Sender)
+				assert.Equal(t, models.Critical, request.Notification.Severity)
+				assert.Equal(t, []string{replayFailedLabel}, request.Notification.Labels)
+				assert.Equal(t, "replay failed", request.Notification.Content)
+			case <-time.After(5 * time.Second):
+				require.Fail(t, "notification not sent")
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/models"
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	// OpaqueTopicPlaceholder is the topic for the background publisher used to replay opaque messages. The topic of
+	// each replayed message is set in the context under the placeholder's key.
+	OpaqueTopicPlaceholder = "{" + opaqueTopicKey + "}"
+	opaqueTopicKey         = "arropaquetopic"
+)
+
+var decodeDataNotBytesError = errors.New("DecodeEvent function received data that is not the raw message payload")
+var opaqueFiltersError = errors.New("device profile, device and source filters can't be used when recording opaque messages")
+var opaqueReplayOptionsError = errors.New("Script, ShadowMode, Telemetry, SystemEvents, LatencyTopic, StartAnnotation, DevicePriorities, Warmup, SimulationServiceName, TimeWarpDuration, AlignTimeOfDay, Sinks, FanOut, Standby, PublishWorkers, ValueBounds and Breakpoints can't be used when replaying opaque messages")
+var opaqueReplayUnavailableError = errors.New("opaque messages can't be replayed since background publishing is unavailable")
+var batchDataNotMessageCollectionError = errors.New("ProcessBatchedMessages function received data that is not collection of messages")
+
+// decodeEvent decodes the raw message payload into an Event, accepting either an AddEventRequest or an Event as the
+// SDK does for its default target type. The service receives the raw payloads so opaque recordings can capture them
+// verbatim, which means Event recordings must decode them first.
+func (m *dataManager) decodeEvent(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	payload, ok := data.([]byte)
+	if !ok {
+		return false, decodeDataNotBytesError
+	}
+
+	event, err := decodeEventPayload(payload, ctx.InputContentType())
+	if err != nil {
+		m.captureDeadLetter(ctx, payload, err)
+		return false, fmt.Errorf("unable to decode Event from payload: %w", err)
+	}
+
+	ctx.AddValue(payloadSizeKey, strconv.Itoa(len(payload)))
+
+	return true, event
+}
+
+func decodeEventPayload(payload []byte, contentType string) (coreDtos.Event, error) {
+	var unmarshal func([]byte, any) error
+	switch strings.Split(contentType, ";")[0] {
+	case common.ContentTypeJSON:
+		unmarshal = json.Unmarshal
+	case common.ContentTypeCBOR:
+		unmarshal = cbor.Unmarshal
+	default:
+		return coreDtos.Event{}, fmt.Errorf("unsupported content type '%s'", contentType)
+	}
+
+	request := requests.AddEventRequest{}
+	requestErr := unmarshal(payload, &request)
+	if requestErr == nil {
+		return request.Event, nil
+	}
+
+	if edgexErr.Kind(requestErr) != edgexErr.KindContractInvalid {
+		return coreDtos.Event{}, requestErr
+	}
+
+	// Failing AddEventRequest validation likely means the payload is an Event that isn't wrapped
+	event := coreDtos.Event{}
+	if err := unmarshal(payload, &event); err == nil {
+		if err = common.Validate(event); err == nil {
+			return event, nil
+		}
+	}
+
+	return coreDtos.Event{}, requestErr
+}
+
+// captureMessage records the raw message along with its envelope metadata for an opaque recording.
+func (m *dataManager) captureMessage(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	payload, ok := data.([]byte)
+	if !ok {
+		return false, decodeDataNotBytesError
+	}
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	m.recordedEventCount++
+	m.recordingActive()
+
+	if m.metrics != nil {
+		m.metrics.recorded("", len(payload))
+	}
+
+	receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+	m.recordedMessages = append(m.recordedMessages, dtos.OpaqueMessage{
+		EnvelopeMetadata: dtos.EnvelopeMetadata{
+			ReceivedTopic: receivedTopic,
+			CorrelationID: ctx.CorrelationID(),
+			ContentType:   ctx.InputContentType(),
+			ReceivedAt:    m.clock.Now().UnixNano(),
+		},
+		// The payload is copied since the batch holds on to it
+		Payload: append([]byte(nil), payload...),
+	})
+
+	m.sessionLogger(m.recordingLabel, m.recordingCorrelationID).Debugf("ARR Message Capture: received message to be recorded. Current message count is %d", m.recordedEventCount)
+
+	return true, data
+}
+
+// processBatchedMessages saves the captured messages once the batch of raw payloads is complete. The batch is only
+// used for its count and time limits, the messages are taken from those captured so they keep their envelopes.
+func (m *dataManager) processBatchedMessages(_ appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	lc := m.sessionLogger(m.recordingLabel, m.recordingCorrelationID)
+
+	// Check if record was canceled and exit early
+	if m.recordingStartedAt == nil {
+		return false, nil
+	}
+
+	// Continuous recordings keep recording, with the batch starting the next segment
+	if m.segmentRotation == nil {
+		// This stops recording of messages
+		m.removeSessionPipelines()
+		lc.Debug("ARR Process Recorded Messages: Recording of messages has ended and functions pipeline has been removed")
+	}
+
+	if data == nil {
+		return false, batchNoDataError
+	}
+
+	payloads, ok := data.([][]byte)
+	if !ok {
+		return false, batchDataNotMessageCollectionError
+	}
+
+	messages := m.recordedMessages
+	if len(payloads) < len(messages) {
+		messages = messages[:len(payloads)]
+	}
+
+	if m.metrics != nil {
+		m.metrics.batched(len(messages))
+	}
+	m.pipelineStats.batched(len(messages), m.clock.Now())
+
+	if m.segmentRotation != nil {
+		// Messages captured after the batch completed belong to the next segment
+		m.recordedMessages = m.recordedMessages[len(messages):]
+		m.rotateSegment(&recordedData{
+			Name:        m.recordingName,
+			Label:       m.recordingLabel,
+			Messages:    messages,
+			Annotations: m.recordedAnnotations,
+		}, lc)
+		m.recordedAnnotations = nil
+
+		return false, nil
+	}
+
+	duration := m.clock.Since(*m.recordingStartedAt)
+
+	m.recordedData = &recordedData{
+		Name:        m.recordingName,
+		Label:       m.recordingLabel,
+		Messages:    messages,
+		Duration:    duration,
+		Annotations: m.recordedAnnotations,
+		Metadata:    withGaps(m.recordingMetadata, m.stopBusWatch()),
+	}
+
+	m.recordingStartedAt = nil
+	m.recordedMessages = nil
+	m.recordedAnnotations = nil
+	m.scheduleNextSession()
+
+	lc.Debugf("ARR Process Recorded Messages: %d messages in %s have been saved for replay", len(messages), duration.String())
+
+	m.sendNotification(recordingCompletedLabel, models.Normal,
+		fmt.Sprintf("Recording completed: %d messages recorded in %s", len(messages), duration.String()))
+	m.startCloudSync()
+
+	return false, nil
+}
+
+// replayRecordedMessages publishes the messages of an opaque recording verbatim, with their original content type
+// and correlation ID, paced using the times they were received.
+func (m *dataManager) replayRecordedMessages(request dtos.ReplayRequest, policy *publishPolicy) {
+	var previousReceivedAt int64
+	firstMessage := true
+	lc := m.sessionLogger(request.Label, request.CorrelationID)
+
+	// Replay Count of zero defaults to 1.
+	replayCount := 1
+	if request.RepeatCount > 0 {
+		replayCount = request.RepeatCount
+	}
+
+	lc.Debugf("ARR Replay: Replay of opaque messages starting with Replay Rate of %v and Repeat Count of %d ", request.ReplayRate, replayCount)
+
+	for i := 0; i < replayCount; i++ {
+		iteration := m.startReplayIteration(i+1, request.ReplayRate)
+
+		for _, message := range m.replayData.Messages {
+			if m.replayStopped(lc) {
+				return
+			}
+
+			scheduledAt := iteration.scheduledAt(message.ReceivedAt)
+
+			// Send the first message immediately and then wait appropriate time between messages
+			if firstMessage {
+				firstMessage = false
+			} else {
+				delay := time.Duration(float32(message.ReceivedAt-previousReceivedAt) * (1 / request.ReplayRate))
+
+				if delay > m.maxReplayDelay {
+					m.setReplayError(fmt.Errorf(maxReplayDelayExceeded, delay.String(), m.maxReplayDelay.String()), true)
+					return
+				}
+
+				m.clock.Sleep(delay)
+			}
+
+			previousReceivedAt = message.ReceivedAt
+
+			topic := relativeMessageTopic(message.ReceivedTopic)
+			ctx := m.appSvc.BuildContext(message.CorrelationID, message.ContentType)
+			ctx.AddValue(opaqueTopicKey, topic)
+
+			published, err := m.publish(policy, lc, func() error {
+				return m.opaquePublisher.Publish(message.Payload, ctx)
+			})
+			if err != nil {
+				m.setReplayError(fmt.Errorf(replayPublishFailed, err), true)
+				return
+			}
+
+			if !published {
+				continue
+			}
+
+			lc.Debugf("ARR Replay: Replayed message to topic: %s", topic)
+
+			iteration.published(scheduledAt, m.clock.Now())
+			m.incrementReplayedEventCount()
+		}
+
+		m.completeReplayIteration(iteration)
+	}
+
+	m.completeReplay(lc)
+}
+
+// relativeMessageTopic returns the received topic with the base topic prefix removed, since the prefix is added back
+// when published. Topics other than Event topics are assumed to have a single level prefix, as the default "edgex".
+func relativeMessageTopic(receivedTopic string) string {
+	if topic, ok := relativeEventTopic(receivedTopic); ok {
+		return topic
+	}
+
+	_, topic, found := strings.Cut(receivedTopic, "/")
+	if !found {
+		return receivedTopic
+	}
+
+	return topic
+}
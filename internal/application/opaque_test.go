@@ -0,0 +1,260 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeEventPayload(t *testing.T) {
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	_ = event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21))
+
+	request := requests.NewAddEventRequest(event)
+	jsonRequest, err := json.Marshal(request)
+	require.NoError(t, err)
+	cborRequest, err := cbor.Marshal(request)
+	require.NoError(t, err)
+	jsonEvent, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name          string
+		Payload       []byte
+		ContentType   string
+		ExpectedError bool
+	}{
+		{"JSON AddEventRequest", jsonRequest, common.ContentTypeJSON, false},
+		{"CBOR AddEventRequest", cborRequest, common.ContentTypeCBOR, false},
+		{"JSON Event", jsonEvent, common.ContentTypeJSON + "; charset=utf-8", false},
+		{"Invalid JSON", []byte("{bad"), common.ContentTypeJSON, true},
+		{"Not an Event", []byte(`{"name":"value"}`), common.ContentTypeJSON, true},
+		{"Unsupported content type", jsonRequest, common.ContentTypeText, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := decodeEventPayload(test.Payload, test.ContentType)
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, event.Id, actual.Id)
+			assert.Equal(t, expectedDeviceName, actual.DeviceName)
+			require.Len(t, actual.Readings, 1)
+			assert.Equal(t, "21", actual.Readings[0].Value)
+		})
+	}
+}
+
+func TestDataManager_DecodeEvent(t *testing.T) {
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	_ = event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21))
+	payload, err := json.Marshal(requests.NewAddEventRequest(event))
+	require.NoError(t, err)
+
+	mockContext := &mocks.AppFunctionContext{}
+	mockContext.On("InputContentType").Return(common.ContentTypeJSON)
+	mockContext.On("AddValue", payloadSizeKey, strconv.Itoa(len(payload)))
+
+	target := NewManager(&mocks.ApplicationService{}, 0, clock.New(), nil, nil).(*dataManager)
+
+	continuePipeline, result := target.decodeEvent(mockContext, payload)
+	require.True(t, continuePipeline)
+	assert.Equal(t, event.Id, result.(coreDtos.Event).Id)
+	// The payload size is passed on for the recording metrics
+	mockContext.AssertCalled(t, "AddValue", payloadSizeKey, strconv.Itoa(len(payload)))
+
+	continuePipeline, result = target.decodeEvent(mockContext, event)
+	require.False(t, continuePipeline)
+	assert.Equal(t, decodeDataNotBytesError, result)
+
+	continuePipeline, result = target.decodeEvent(mockContext, []byte("bad"))
+	require.False(t, continuePipeline)
+	assert.Error(t, result.(error))
+}
+
+func TestDataManager_CaptureMessages(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+	mockSdk.On("RemoveAllFunctionPipelines")
+	mockSdk.On("NotificationClient").Return(nil)
+
+	mockContext := &mocks.AppFunctionContext{}
+	mockContext.On("GetValue", interfaces.RECEIVEDTOPIC).Return("edgex/app/custom", true)
+	mockContext.On("CorrelationID").Return("123")
+	mockContext.On("InputContentType").Return(common.ContentTypeText)
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+	now := time.Now()
+	target.recordingStartedAt = &now
+
+	payloads := [][]byte{[]byte("first"), []byte("second"), []byte("third")}
+	for _, payload := range payloads {
+		continuePipeline, result := target.captureMessage(mockContext, payload)
+		require.True(t, continuePipeline)
+		require.Equal(t, payload, result)
+	}
+
+	continuePipeline, result := target.captureMessage(mockContext, "not bytes")
+	require.False(t, continuePipeline)
+	require.Equal(t, decodeDataNotBytesError, result)
+
+	// Only the messages that made it into the batch are kept
+	continuePipeline, result = target.processBatchedMessages(mockContext, payloads[:2])
+	require.False(t, continuePipeline)
+	require.Nil(t, result)
+
+	require.NotNil(t, target.recordedData)
+	require.Len(t, target.recordedData.Messages, 2)
+	assert.Nil(t, target.recordingStartedAt)
+	assert.Equal(t, 2, target.RecordingStatus().EventCount)
+
+	message := target.recordedData.Messages[1]
+	assert.Equal(t, []byte("second"), message.Payload)
+	assert.Equal(t, "edgex/app/custom", message.ReceivedTopic)
+	assert.Equal(t, "123", message.CorrelationID)
+	assert.Equal(t, common.ContentTypeText, message.ContentType)
+	assert.NotZero(t, message.ReceivedAt)
+
+	target.recordingStartedAt = &now
+	continuePipeline, result = target.processBatchedMessages(mockContext, []coreDtos.Event{})
+	require.False(t, continuePipeline)
+	assert.Equal(t, batchDataNotMessageCollectionError, result)
+}
+
+func TestDataManager_StartReplay_Opaque(t *testing.T) {
+	messages := []dtos.OpaqueMessage{
+		{
+			EnvelopeMetadata: dtos.EnvelopeMetadata{ReceivedTopic: "edgex/app/custom", CorrelationID: "1", ContentType: common.ContentTypeText, ReceivedAt: 1000},
+			Payload:          []byte("first"),
+		},
+		{
+			EnvelopeMetadata: dtos.EnvelopeMetadata{ReceivedTopic: "edgex/app/other", CorrelationID: "2", ContentType: common.ContentTypeCBOR, ReceivedAt: 2000},
+			Payload:          []byte{0xa0},
+		},
+	}
+
+	mockContext := &mocks.AppFunctionContext{}
+	mockContext.On("AddValue", opaqueTopicKey, mock.Anything)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("BuildContext", mock.Anything, mock.Anything).Return(mockContext)
+
+	mockPublisher := &mocks.BackgroundPublisher{}
+	mockPublisher.On("Publish", mock.Anything, mockContext).Return(nil)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), mockPublisher, nil).(*dataManager)
+	target.recordedData = &recordedData{Messages: messages}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, RepeatCount: 2})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	assert.Empty(t, status.Message)
+	assert.Equal(t, 4, status.EventCount)
+	assert.Equal(t, 2, status.RepeatCount)
+	require.Len(t, status.Iterations, 2)
+	assert.Equal(t, 2, status.Iterations[1].Iteration)
+	assert.Equal(t, 2, status.Iterations[1].EventCount)
+
+	mockSdk.AssertCalled(t, "BuildContext", "1", common.ContentTypeText)
+	mockSdk.AssertCalled(t, "BuildContext", "2", common.ContentTypeCBOR)
+	mockContext.AssertCalled(t, "AddValue", opaqueTopicKey, "app/custom")
+	mockContext.AssertCalled(t, "AddValue", opaqueTopicKey, "app/other")
+	mockPublisher.AssertCalled(t, "Publish", []byte("first"), mockContext)
+	mockPublisher.AssertCalled(t, "Publish", []byte{0xa0}, mockContext)
+}
+
+func TestDataManager_StartReplay_OpaqueErrors(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Request       dtos.ReplayRequest
+		Publisher     bool
+		ExpectedError error
+	}{
+		{"Script", dtos.ReplayRequest{ReplayRate: 1, Script: `return true`}, true, opaqueReplayOptionsError},
+		{"Shadow mode", dtos.ReplayRequest{ReplayRate: 1, ShadowMode: true}, true, opaqueReplayOptionsError},
+		{"Priorities", dtos.ReplayRequest{ReplayRate: 1, DevicePriorities: map[string]int{"D1": 1}}, true, opaqueReplayOptionsError},
+		{"Simulation service", dtos.ReplayRequest{ReplayRate: 1, SimulationServiceName: "device-replay"}, true, opaqueReplayOptionsError},
+		{"Time warp", dtos.ReplayRequest{TimeWarpDuration: time.Hour}, true, opaqueReplayOptionsError},
+		{"Align time of day", dtos.ReplayRequest{AlignTimeOfDay: true}, true, opaqueReplayOptionsError},
+		{"No publisher", dtos.ReplayRequest{ReplayRate: 1}, false, opaqueReplayUnavailableError},
+		{"Sinks", dtos.ReplayRequest{ReplayRate: 1, Sinks: []dtos.ReplaySink{{Type: dtos.ReplaySinkMessageBus}}}, true, opaqueReplayOptionsError},
+		{"Publish workers", dtos.ReplayRequest{ReplayRate: 1, PublishWorkers: 4}, true, opaqueReplayOptionsError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var target *dataManager
+			if test.Publisher {
+				target = NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), &mocks.BackgroundPublisher{}, nil).(*dataManager)
+			} else {
+				target = NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), nil, nil).(*dataManager)
+			}
+			target.recordedData = &recordedData{Messages: []dtos.OpaqueMessage{{Payload: []byte("data")}}}
+
+			err := target.StartReplay(test.Request)
+			require.ErrorIs(t, err, test.ExpectedError)
+			assert.Nil(t, target.replayStartedAt)
+		})
+	}
+}
+
+func TestRelativeMessageTopic(t *testing.T) {
+	tests := []struct {
+		ReceivedTopic string
+		Expected      string
+	}{
+		{"edgex/events/device/svc/p/d/s", "events/device/svc/p/d/s"},
+		{"edgex/app/custom/topic", "app/custom/topic"},
+		{"custom", "custom"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ReceivedTopic, func(t *testing.T) {
+			assert.Equal(t, test.Expected, relativeMessageTopic(test.ReceivedTopic))
+		})
+	}
+}
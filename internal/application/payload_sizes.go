@@ -0,0 +1,190 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const (
+	// maxLargestReadings is the number of largest Readings tracked, which bounds the number that can be reported
+	maxLargestReadings = 100
+	// defaultLargestCount is the number of largest devices and Readings reported when not specified
+	defaultLargestCount = 10
+)
+
+// payloadSizeBuckets are the inclusive upper bounds of the payload size histogram buckets, with a last bucket for
+// all the larger payloads
+var payloadSizeBuckets = []int{256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20}
+
+var noPayloadSizesError = errors.New("no recording has tracked payload sizes")
+
+// payloadSizeStats tracks the payload sizes of the Events captured by a recording. Must only be used while holding
+// the recording mutex.
+type payloadSizeStats struct {
+	eventCount int
+	totalBytes int64
+	minBytes   int
+	maxBytes   int
+	buckets    []int
+	devices    map[string]*dtos.DevicePayloadSize
+	readings   largestReadings
+}
+
+func newPayloadSizeStats() *payloadSizeStats {
+	return &payloadSizeStats{
+		buckets: make([]int, len(payloadSizeBuckets)+1),
+		devices: make(map[string]*dtos.DevicePayloadSize),
+	}
+}
+
+// add tracks the Event, which was received in a payload of the size given
+func (s *payloadSizeStats) add(event coreDtos.Event, size int) {
+	if s.eventCount == 0 || size < s.minBytes {
+		s.minBytes = size
+	}
+	s.maxBytes = max(s.maxBytes, size)
+	s.eventCount++
+	s.totalBytes += int64(size)
+	s.buckets[sort.SearchInts(payloadSizeBuckets, size)]++
+
+	device, found := s.devices[event.DeviceName]
+	if !found {
+		device = &dtos.DevicePayloadSize{DeviceName: event.DeviceName}
+		s.devices[event.DeviceName] = device
+	}
+	device.EventCount++
+	device.TotalBytes += int64(size)
+	device.MaxBytes = max(device.MaxBytes, size)
+
+	for _, reading := range event.Readings {
+		s.readings.add(dtos.ReadingPayloadSize{
+			DeviceName:   reading.DeviceName,
+			ResourceName: reading.ResourceName,
+			ValueType:    reading.ValueType,
+			Bytes:        readingValueSize(reading),
+			Origin:       reading.Origin,
+		})
+	}
+}
+
+// readingValueSize returns the size in bytes of the Reading's value, which is the bulk of the Reading's payload
+func readingValueSize(reading coreDtos.BaseReading) int {
+	size := len(reading.Value) + len(reading.BinaryValue)
+	if reading.ObjectValue != nil {
+		if data, err := json.Marshal(reading.ObjectValue); err == nil {
+			size += len(data)
+		}
+	}
+
+	return size
+}
+
+// report returns the report with the given number of largest devices and Readings
+func (s *payloadSizeStats) report(count int) *dtos.PayloadSizeReport {
+	report := &dtos.PayloadSizeReport{
+		EventCount:      s.eventCount,
+		TotalBytes:      s.totalBytes,
+		MinBytes:        s.minBytes,
+		MaxBytes:        s.maxBytes,
+		Histogram:       make([]dtos.PayloadSizeBucket, len(s.buckets)),
+		LargestDevices:  []dtos.DevicePayloadSize{},
+		LargestReadings: []dtos.ReadingPayloadSize{},
+	}
+
+	if s.eventCount > 0 {
+		report.MeanBytes = int(s.totalBytes / int64(s.eventCount))
+	}
+
+	for i, bucketCount := range s.buckets {
+		report.Histogram[i].Count = bucketCount
+		if i < len(payloadSizeBuckets) {
+			report.Histogram[i].MaxBytes = payloadSizeBuckets[i]
+		}
+	}
+
+	for _, device := range s.devices {
+		report.LargestDevices = append(report.LargestDevices, *device)
+	}
+	sort.Slice(report.LargestDevices, func(i, j int) bool {
+		if report.LargestDevices[i].TotalBytes != report.LargestDevices[j].TotalBytes {
+			return report.LargestDevices[i].TotalBytes > report.LargestDevices[j].TotalBytes
+		}
+		return report.LargestDevices[i].DeviceName < report.LargestDevices[j].DeviceName
+	})
+	report.LargestDevices = report.LargestDevices[:min(count, len(report.LargestDevices))]
+
+	report.LargestReadings = append(report.LargestReadings, s.readings...)
+	sort.Slice(report.LargestReadings, func(i, j int) bool {
+		return report.LargestReadings[i].Bytes > report.LargestReadings[j].Bytes
+	})
+	report.LargestReadings = report.LargestReadings[:min(count, len(report.LargestReadings))]
+
+	return report
+}
+
+// largestReadings is a min-heap of the largest Readings, so the smallest is replaced once the heap is full
+type largestReadings []dtos.ReadingPayloadSize
+
+func (h largestReadings) Len() int           { return len(h) }
+func (h largestReadings) Less(i, j int) bool { return h[i].Bytes < h[j].Bytes }
+func (h largestReadings) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *largestReadings) Push(x any)        { *h = append(*h, x.(dtos.ReadingPayloadSize)) }
+func (h *largestReadings) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// add keeps the Reading if it is one of the largest
+func (h *largestReadings) add(reading dtos.ReadingPayloadSize) {
+	if h.Len() < maxLargestReadings {
+		heap.Push(h, reading)
+		return
+	}
+
+	if reading.Bytes > (*h)[0].Bytes {
+		(*h)[0] = reading
+		heap.Fix(h, 0)
+	}
+}
+
+// PayloadSizeReport returns the payload size distribution of the Events captured by the current or last recording
+// with the given number of largest devices and Readings, defaulting to 10 when 0 and limited to 100. An error is
+// returned if no recording of Events has been run.
+func (m *dataManager) PayloadSizeReport(count int) (*dtos.PayloadSizeReport, error) {
+	if count <= 0 {
+		count = defaultLargestCount
+	}
+	count = min(count, maxLargestReadings)
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.payloadSizes == nil {
+		return nil, noPayloadSizesError
+	}
+
+	return m.payloadSizes.report(count), nil
+}
@@ -0,0 +1,147 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadSizeStats(t *testing.T) {
+	newEvent := func(deviceName string, values ...string) coreDtos.Event {
+		event := coreDtos.NewEvent(expectedProfileName, deviceName, expectedSourceName)
+		for i, value := range values {
+			require.NoError(t, event.AddSimpleReading(fmt.Sprintf("R%d", i), common.ValueTypeString, value))
+		}
+		return event
+	}
+
+	target := newPayloadSizeStats()
+	target.add(newEvent("D1", "small"), 100)
+	target.add(newEvent("D1", "medium value"), 2000)
+	target.add(newEvent("D2", string(make([]byte, 300)), "tiny"), 5000)
+	image := newEvent("D3")
+	image.AddBinaryReading("Image", make([]byte, 1000), "image/png")
+	target.add(image, 2<<20)
+	object := newEvent("D3")
+	object.AddObjectReading("Object", map[string]any{"key": "value"})
+	target.add(object, 256)
+
+	report := target.report(2)
+	assert.Equal(t, 5, report.EventCount)
+	assert.Equal(t, int64(100+2000+5000+2<<20+256), report.TotalBytes)
+	assert.Equal(t, 100, report.MinBytes)
+	assert.Equal(t, 2<<20, report.MaxBytes)
+	assert.Equal(t, int(report.TotalBytes/5), report.MeanBytes)
+
+	expectedHistogram := []dtos.PayloadSizeBucket{
+		{MaxBytes: 256, Count: 2},
+		{MaxBytes: 1 << 10},
+		{MaxBytes: 4 << 10, Count: 1},
+		{MaxBytes: 16 << 10, Count: 1},
+		{MaxBytes: 64 << 10},
+		{MaxBytes: 256 << 10},
+		{MaxBytes: 1 << 20},
+		{Count: 1},
+	}
+	assert.Equal(t, expectedHistogram, report.Histogram)
+
+	assert.Equal(t, []dtos.DevicePayloadSize{
+		{DeviceName: "D3", EventCount: 2, TotalBytes: 2<<20 + 256, MaxBytes: 2 << 20},
+		{DeviceName: "D2", EventCount: 1, TotalBytes: 5000, MaxBytes: 5000},
+	}, report.LargestDevices)
+
+	require.Len(t, report.LargestReadings, 2)
+	assert.Equal(t, "Image", report.LargestReadings[0].ResourceName)
+	assert.Equal(t, "D3", report.LargestReadings[0].DeviceName)
+	assert.Equal(t, common.ValueTypeBinary, report.LargestReadings[0].ValueType)
+	assert.Equal(t, 1000, report.LargestReadings[0].Bytes)
+	assert.Equal(t, "R0", report.LargestReadings[1].ResourceName)
+	assert.Equal(t, "D2", report.LargestReadings[1].DeviceName)
+	assert.Equal(t, 300, report.LargestReadings[1].Bytes)
+
+	// The object value is sized as JSON
+	assert.Equal(t, len(`{"key":"value"}`), readingValueSize(object.Readings[0]))
+}
+
+func TestPayloadSizeStats_LargestReadingsBounded(t *testing.T) {
+	target := newPayloadSizeStats()
+	for size := range 3 * maxLargestReadings {
+		event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+		require.NoError(t, event.AddSimpleReading("Value", common.ValueTypeString, string(make([]byte, size))))
+		target.add(event, size)
+	}
+
+	assert.Len(t, target.readings, maxLargestReadings)
+
+	report := target.report(maxLargestReadings)
+	require.Len(t, report.LargestReadings, maxLargestReadings)
+	assert.Equal(t, 3*maxLargestReadings-1, report.LargestReadings[0].Bytes)
+	assert.Equal(t, 2*maxLargestReadings, report.LargestReadings[maxLargestReadings-1].Bytes)
+}
+
+func TestDataManager_PayloadSizeReport(t *testing.T) {
+	lc := logger.NewMockClient()
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(lc)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	_, err := target.PayloadSizeReport(0)
+	require.Equal(t, noPayloadSizesError, err)
+
+	target.payloadSizes = newPayloadSizeStats()
+	for i := range 20 {
+		ctx := pkg.NewAppFuncContextForTest("123", lc)
+		ctx.AddValue(payloadSizeKey, fmt.Sprint(100+i))
+
+		event := coreDtos.NewEvent(expectedProfileName, fmt.Sprintf("device-%d", i), expectedSourceName)
+		require.NoError(t, event.AddSimpleReading("Value", common.ValueTypeInt32, int32(i)))
+		continuePipeline, _ := target.countEvents(ctx, event)
+		require.True(t, continuePipeline)
+	}
+
+	report, err := target.PayloadSizeReport(0)
+	require.NoError(t, err)
+	assert.Equal(t, 20, report.EventCount)
+	assert.Equal(t, 100, report.MinBytes)
+	assert.Equal(t, 119, report.MaxBytes)
+	assert.Len(t, report.LargestDevices, defaultLargestCount)
+	assert.Equal(t, "device-19", report.LargestDevices[0].DeviceName)
+	assert.Len(t, report.LargestReadings, defaultLargestCount)
+
+	report, err = target.PayloadSizeReport(3)
+	require.NoError(t, err)
+	assert.Len(t, report.LargestDevices, 3)
+	assert.Len(t, report.LargestReadings, 3)
+
+	report, err = target.PayloadSizeReport(1000)
+	require.NoError(t, err)
+	assert.Len(t, report.LargestDevices, 20)
+	assert.Len(t, report.LargestReadings, 20)
+}
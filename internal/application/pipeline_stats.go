@@ -0,0 +1,169 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+// maxPipelineErrors is the number of most recent pipeline errors kept for the pipeline statistics
+const maxPipelineErrors = 10
+
+// pipelineStats tracks the internal statistics of the session pipelines and the replay publishing which aren't
+// otherwise visible, such as the messages the pipeline functions failed or the batches flushed. Unlike the recording
+// metrics it is always tracked, so is cheap to update. Safe for concurrent use, since the pipeline functions run
+// outside the recording mutex.
+type pipelineStats struct {
+	mutex          sync.Mutex
+	receivedCount  int
+	flushedCount   int
+	flushCount     int
+	lastBatchSize  int
+	lastFlushedAt  int64
+	errorCount     int
+	lastErrors     []dtos.PipelineError
+	publishWorkers *publishWorkers
+}
+
+// reset clears the statistics when a recording starts
+func (s *pipelineStats) reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.receivedCount = 0
+	s.flushedCount = 0
+	s.flushCount = 0
+	s.lastBatchSize = 0
+	s.lastFlushedAt = 0
+	s.errorCount = 0
+	s.lastErrors = nil
+}
+
+// observe wraps the pipeline functions so the messages received by the pipeline and the errors returned by its
+// functions are tracked
+func (s *pipelineStats) observe(functions []appInterfaces.AppFunction, clock interfaces.Clock) []appInterfaces.AppFunction {
+	observed := make([]appInterfaces.AppFunction, len(functions))
+	for index, function := range functions {
+		name := pipelineFunctionName(function)
+		first := index == 0
+		observed[index] = func(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+			if first {
+				s.received()
+			}
+
+			ok, result := function(ctx, data)
+			if err, isError := result.(error); !ok && isError {
+				s.failed(name, err, clock.Now())
+			}
+
+			return ok, result
+		}
+	}
+
+	return observed
+}
+
+func (s *pipelineStats) received() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.receivedCount++
+}
+
+func (s *pipelineStats) failed(function string, err error, at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.errorCount++
+	s.lastErrors = append(s.lastErrors, dtos.PipelineError{Function: function, Error: err.Error(), Timestamp: at.UnixNano()})
+	if len(s.lastErrors) > maxPipelineErrors {
+		s.lastErrors = s.lastErrors[len(s.lastErrors)-maxPipelineErrors:]
+	}
+}
+
+// batched records a flushed batch of the given size
+func (s *pipelineStats) batched(size int, at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.flushCount++
+	s.flushedCount += size
+	s.lastBatchSize = size
+	s.lastFlushedAt = at.UnixNano()
+}
+
+// setPublishWorkers sets the publish workers of the running replay, or nil once it ends, so their queue depths
+// are reported
+func (s *pipelineStats) setPublishWorkers(workers *publishWorkers) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.publishWorkers = workers
+}
+
+// report returns the statistics, given the number of Events counted for the recording
+func (s *pipelineStats) report(recordedCount int) dtos.PipelineStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := dtos.PipelineStats{
+		ReceivedMessageCount:   s.receivedCount,
+		RecordedEventCount:     recordedCount,
+		PendingBatchEventCount: max(recordedCount-s.flushedCount, 0),
+		BatchFlushCount:        s.flushCount,
+		LastBatchSize:          s.lastBatchSize,
+		LastBatchFlushedAt:     s.lastFlushedAt,
+		GoroutineCount:         runtime.NumGoroutine(),
+		PipelineErrorCount:     s.errorCount,
+		LastPipelineErrors:     append([]dtos.PipelineError(nil), s.lastErrors...),
+	}
+
+	if s.publishWorkers != nil {
+		stats.PublishQueueDepths = s.publishWorkers.depths()
+	}
+
+	return stats
+}
+
+// pipelineFunctionName returns the short name of the pipeline function, e.g. decodeEvent or Batch
+func pipelineFunctionName(function appInterfaces.AppFunction) string {
+	name := runtime.FuncForPC(reflect.ValueOf(function).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// PipelineStats returns the internal statistics of the recording pipeline and the replay publishing, such as the
+// batch flushes, the publish queue depths and the last pipeline errors
+func (m *dataManager) PipelineStats() dtos.PipelineStats {
+	m.recordingMutex.Lock()
+	recording := m.recordingStartedAt != nil
+	recordedCount := m.recordedEventCount
+	m.recordingMutex.Unlock()
+
+	stats := m.pipelineStats.report(recordedCount)
+	stats.Recording = recording
+
+	return stats
+}
@@ -0,0 +1,181 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineStats_Observe(t *testing.T) {
+	now := time.Now()
+	virtualClock := clock.NewVirtual(now)
+	ctx := pkg.NewAppFuncContextForTest("123", logger.NewMockClient())
+
+	stats := &pipelineStats{}
+	passThrough := func(_ appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+		return true, data
+	}
+	failing := func(_ appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+		if data == "bad" {
+			return false, errors.New("bad data")
+		}
+		return false, nil
+	}
+
+	pipeline := stats.observe([]appInterfaces.AppFunction{passThrough, failing}, virtualClock)
+	require.Len(t, pipeline, 2)
+
+	run := func(data any) {
+		for _, function := range pipeline {
+			ok, result := function(ctx, data)
+			if !ok {
+				return
+			}
+			data = result
+		}
+	}
+
+	run("good")
+	for range maxPipelineErrors + 2 {
+		run("bad")
+	}
+
+	report := stats.report(0)
+	assert.Equal(t, maxPipelineErrors+3, report.ReceivedMessageCount)
+	assert.Equal(t, maxPipelineErrors+2, report.PipelineErrorCount)
+	require.Len(t, report.LastPipelineErrors, maxPipelineErrors)
+	assert.Equal(t, "bad data", report.LastPipelineErrors[0].Error)
+	assert.Equal(t, "func2", report.LastPipelineErrors[0].Function)
+	assert.Equal(t, now.UnixNano(), report.LastPipelineErrors[0].Timestamp)
+	assert.Positive(t, report.GoroutineCount)
+
+	stats.reset()
+	report = stats.report(0)
+	assert.Zero(t, report.ReceivedMessageCount)
+	assert.Zero(t, report.PipelineErrorCount)
+	assert.Empty(t, report.LastPipelineErrors)
+}
+
+func TestPipelineStats_Batched(t *testing.T) {
+	now := time.Now()
+	stats := &pipelineStats{}
+
+	report := stats.report(5)
+	assert.Equal(t, 5, report.PendingBatchEventCount)
+	assert.Zero(t, report.BatchFlushCount)
+
+	stats.batched(3, now)
+	stats.batched(2, now.Add(time.Second))
+	report = stats.report(7)
+	assert.Equal(t, 2, report.PendingBatchEventCount)
+	assert.Equal(t, 2, report.BatchFlushCount)
+	assert.Equal(t, 2, report.LastBatchSize)
+	assert.Equal(t, now.Add(time.Second).UnixNano(), report.LastBatchFlushedAt)
+
+	// A new recording's count restarts from zero until the statistics are reset
+	report = stats.report(1)
+	assert.Zero(t, report.PendingBatchEventCount)
+}
+
+func TestPipelineStats_PublishQueueDepths(t *testing.T) {
+	workers := &publishWorkers{queues: []chan publishJob{make(chan publishJob, 2), make(chan publishJob, 2)}}
+	workers.queues[1] <- publishJob{}
+
+	stats := &pipelineStats{}
+	assert.Nil(t, stats.report(0).PublishQueueDepths)
+
+	stats.setPublishWorkers(workers)
+	assert.Equal(t, []int{0, 1}, stats.report(0).PublishQueueDepths)
+
+	stats.setPublishWorkers(nil)
+	assert.Nil(t, stats.report(0).PublishQueueDepths)
+}
+
+func TestPipelineFunctionName(t *testing.T) {
+	target := &dataManager{}
+	assert.Equal(t, "decodeEvent", pipelineFunctionName(target.decodeEvent))
+	assert.Equal(t, "countEvents", pipelineFunctionName(target.countEvents))
+}
+
+func TestDataManager_PipelineStats(t *testing.T) {
+	lc := logger.NewMockClient()
+	var pipeline []appInterfaces.AppFunction
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(lc)
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+	mockSdk.On("NotificationClient").Return(nil)
+	mockSdk.On("RemoveAllFunctionPipelines")
+	mockSdk.On("SetDefaultFunctionsPipeline", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			pipeline = nil
+			for _, arg := range args {
+				pipeline = append(pipeline, arg.(appInterfaces.AppFunction))
+			}
+		}).Return(nil)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	require.NoError(t, target.StartRecording(dtos.RecordRequest{EventLimit: 2}))
+	require.Len(t, pipeline, 4)
+
+	run := func(payload string) {
+		ctx := jsonContext{pkg.NewAppFuncContextForTest("123", lc)}
+		var data any = []byte(payload)
+		for _, function := range pipeline {
+			ok, result := function(ctx, data)
+			if !ok {
+				return
+			}
+			data = result
+		}
+	}
+
+	event := `{"apiVersion":"v3","event":{"apiVersion":"v3","id":"%s","deviceName":"D1","profileName":"P1","sourceName":"S1","origin":1,"readings":[{"id":"%s","origin":1,"deviceName":"D1","resourceName":"R1","profileName":"P1","valueType":"Int64","value":"1"}]}}`
+	run(fmt.Sprintf(event, "3f4f6a5e-4f5c-4c5e-9a8e-6f0a1b2c3d40", "3f4f6a5e-4f5c-4c5e-9a8e-6f0a1b2c3d41"))
+	run("{not json")
+
+	stats := target.PipelineStats()
+	assert.True(t, stats.Recording)
+	assert.Equal(t, 2, stats.ReceivedMessageCount)
+	assert.Equal(t, 1, stats.RecordedEventCount)
+	assert.Equal(t, 1, stats.PendingBatchEventCount)
+	assert.Zero(t, stats.BatchFlushCount)
+	require.Len(t, stats.LastPipelineErrors, 1)
+	assert.Equal(t, "decodeEvent", stats.LastPipelineErrors[0].Function)
+
+	run(fmt.Sprintf(event, "3f4f6a5e-4f5c-4c5e-9a8e-6f0a1b2c3d42", "3f4f6a5e-4f5c-4c5e-9a8e-6f0a1b2c3d43"))
+
+	stats = target.PipelineStats()
+	assert.False(t, stats.Recording)
+	assert.Equal(t, 3, stats.ReceivedMessageCount)
+	assert.Equal(t, 2, stats.RecordedEventCount)
+	assert.Zero(t, stats.PendingBatchEventCount)
+	assert.Equal(t, 1, stats.BatchFlushCount)
+	assert.Equal(t, 2, stats.LastBatchSize)
+}
@@ -0,0 +1,111 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const defaultMaxReplayLag = time.Second
+
+// replayScheduler paces a prioritized replay against a fixed schedule derived from the replay start time, rather
+// than sleeping between consecutive Events, so the replay can tell when it has fallen behind. While behind by more
+// than the maximum lag, only Events with the highest assigned priority are published until the replay catches up.
+type replayScheduler struct {
+	priorities  map[string]int
+	maxPriority int
+	maxLag      time.Duration
+	rate        float32
+
+	startedAt      time.Time
+	firstEventTime int64
+	started        bool
+}
+
+// newReplayScheduler returns a scheduler for the request, or nil if the request doesn't assign device priorities.
+func newReplayScheduler(request dtos.ReplayRequest) *replayScheduler {
+	if len(request.DevicePriorities) == 0 {
+		return nil
+	}
+
+	scheduler := &replayScheduler{
+		priorities: request.DevicePriorities,
+		maxLag:     request.MaxReplayLag,
+		rate:       request.ReplayRate,
+	}
+
+	if scheduler.maxLag == 0 {
+		scheduler.maxLag = defaultMaxReplayLag
+	}
+
+	// Devices not listed have priority 0, so they are the lowest priority unless negative priorities are assigned
+	for _, priority := range request.DevicePriorities {
+		if priority > scheduler.maxPriority {
+			scheduler.maxPriority = priority
+		}
+	}
+
+	return scheduler
+}
+
+// priority returns the priority of the Event's device. The device name takes precedence over the profile name,
+// which is used as the device class.
+func (s *replayScheduler) priority(event coreDtos.Event) int {
+	if priority, ok := s.priorities[event.DeviceName]; ok {
+		return priority
+	}
+
+	return s.priorities[event.ProfileName]
+}
+
+// restart resets the schedule so the next Event is published immediately. Called at the start of each repeat.
+func (s *replayScheduler) restart() {
+	s.started = false
+}
+
+// next returns how long to wait before publishing the Event with the given event time, or false if the Event
+// should be dropped because the replay is behind schedule and the Event isn't of the highest priority.
+func (s *replayScheduler) next(event coreDtos.Event, eventTime int64, now time.Time) (time.Duration, bool) {
+	if !s.started {
+		s.started = true
+		s.startedAt = now
+		s.firstEventTime = eventTime
+		return 0, true
+	}
+
+	offset := time.Duration(float32(eventTime-s.firstEventTime) * (1 / s.rate))
+	wait := s.startedAt.Add(offset).Sub(now)
+
+	if -wait > s.maxLag && s.priority(event) < s.maxPriority {
+		return 0, false
+	}
+
+	if wait < 0 {
+		return 0, true
+	}
+
+	return wait, true
+}
+
+func (m *dataManager) incrementReplayDroppedEventCount() {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+	m.replayDroppedEventCount++
+}
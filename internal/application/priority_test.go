@@ -0,0 +1,144 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReplayScheduler(t *testing.T) {
+	assert.Nil(t, newReplayScheduler(dtos.ReplayRequest{ReplayRate: 1}))
+
+	scheduler := newReplayScheduler(dtos.ReplayRequest{
+		ReplayRate:       1,
+		DevicePriorities: map[string]int{"D1": 5, expectedProfileName: 2, "D3": -1},
+	})
+	require.NotNil(t, scheduler)
+	assert.Equal(t, 5, scheduler.maxPriority)
+	assert.Equal(t, defaultMaxReplayLag, scheduler.maxLag)
+
+	assert.Equal(t, 5, scheduler.priority(coreDtos.NewEvent(expectedProfileName, "D1", expectedSourceName)))
+	assert.Equal(t, 2, scheduler.priority(coreDtos.NewEvent(expectedProfileName, "D2", expectedSourceName)))
+	assert.Equal(t, -1, scheduler.priority(coreDtos.NewEvent(expectedProfileName, "D3", expectedSourceName)))
+	assert.Equal(t, 0, scheduler.priority(coreDtos.NewEvent("other", "D4", expectedSourceName)))
+}
+
+func TestReplayScheduler_Next(t *testing.T) {
+	scheduler := newReplayScheduler(dtos.ReplayRequest{
+		ReplayRate:       2,
+		DevicePriorities: map[string]int{"high": 1},
+		MaxReplayLag:     100 * time.Millisecond,
+	})
+	require.NotNil(t, scheduler)
+
+	high := coreDtos.NewEvent(expectedProfileName, "high", expectedSourceName)
+	low := coreDtos.NewEvent(expectedProfileName, "low", expectedSourceName)
+	start := time.Now()
+
+	tests := []struct {
+		Name            string
+		Event           coreDtos.Event
+		EventTime       time.Duration
+		Now             time.Duration
+		ExpectedWait    time.Duration
+		ExpectedPublish bool
+	}{
+		{"First", low, 0, 0, 0, true},
+		{"Ahead", low, time.Second, 100 * time.Millisecond, 400 * time.Millisecond, true},
+		{"Behind within lag", low, time.Second, 550 * time.Millisecond, 0, true},
+		{"Low behind", low, time.Second, 700 * time.Millisecond, 0, false},
+		{"High behind", high, time.Second, 700 * time.Millisecond, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			wait, publish := scheduler.next(test.Event, int64(test.EventTime), start.Add(test.Now))
+			assert.Equal(t, test.ExpectedPublish, publish)
+			assert.Equal(t, test.ExpectedWait, wait)
+		})
+	}
+
+	scheduler.restart()
+	wait, publish := scheduler.next(low, int64(time.Hour), start.Add(time.Minute))
+	assert.True(t, publish)
+	assert.Zero(t, wait)
+}
+
+func TestDataManager_StartReplay_Prioritized(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("AppContext").Return(context.Background())
+	// Publishing is slower than the recorded spacing, so the replay falls behind after the first Event
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).
+		Run(func(args mock.Arguments) { time.Sleep(50 * time.Millisecond) }).
+		Return(nil)
+
+	var events []coreDtos.Event
+	origin := time.Now().UnixNano()
+	for index, deviceName := range []string{"D1", "D2", "D1", "D2"} {
+		event := coreDtos.NewEvent(expectedProfileName, deviceName, expectedSourceName)
+		event.Origin = origin + int64(index)*int64(time.Millisecond)
+		events = append(events, event)
+	}
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore(events),
+		Devices: map[string]*coreDtos.Device{
+			"D1": {Name: "D1", ServiceName: expectedServiceName},
+			"D2": {Name: "D2", ServiceName: expectedServiceName},
+		},
+	}
+
+	err := target.StartReplay(dtos.ReplayRequest{
+		ReplayRate:       1,
+		DevicePriorities: map[string]int{"D1": 1},
+		MaxReplayLag:     10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	assert.Empty(t, status.Message)
+	assert.Equal(t, 2, status.EventCount)
+	assert.Equal(t, 2, status.DroppedEventCount)
+	mockSdk.AssertNumberOfCalls(t, "PublishWithTopic", 2)
+}
+
+func TestDataManager_StartReplay_InvalidMaxReplayLag(t *testing.T) {
+	target := NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, MaxReplayLag: -time.Second})
+	require.ErrorIs(t, err, invalidMaxReplayLag)
+}
@@ -0,0 +1,235 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const (
+	// ReplayProfileDriftPolicyAppSetting is the policy applied when the Device Profile currently deployed differs
+	// materially from the one used when the Events were captured. Valid values are "warn" and "fail". Profiles
+	// aren't checked when not set.
+	ReplayProfileDriftPolicyAppSetting = "ReplayProfileDriftPolicy"
+
+	profileDriftPolicyWarn = "warn"
+	profileDriftPolicyFail = "fail"
+)
+
+var profileDriftError = errors.New("device profiles differ from those the events were captured with")
+
+// profileResource holds the fields of a Device Resource which determine how its Reading values are interpreted
+type profileResource struct {
+	Name      string   `json:"name"`
+	ValueType string   `json:"valueType"`
+	Units     string   `json:"units,omitempty"`
+	MediaType string   `json:"mediaType,omitempty"`
+	Mask      *uint64  `json:"mask,omitempty"`
+	Shift     *int64   `json:"shift,omitempty"`
+	Scale     *float64 `json:"scale,omitempty"`
+	Offset    *float64 `json:"offset,omitempty"`
+	Base      *float64 `json:"base,omitempty"`
+}
+
+// profileHash returns the hash of the material parts of the Device Profile, which are those affecting the values
+// and valueTypes of its Readings. Changes to descriptions, labels, commands and the like don't change the hash.
+func profileHash(profile coreDtos.DeviceProfile) string {
+	resources := make([]profileResource, 0, len(profile.DeviceResources))
+	for _, resource := range profile.DeviceResources {
+		properties := resource.Properties
+		resources = append(resources, profileResource{
+			Name:      resource.Name,
+			ValueType: properties.ValueType,
+			Units:     properties.Units,
+			MediaType: properties.MediaType,
+			Mask:      properties.Mask,
+			Shift:     properties.Shift,
+			Scale:     properties.Scale,
+			Offset:    properties.Offset,
+			Base:      properties.Base,
+		})
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+
+	// Marshaling a slice of structs can't fail
+	data, _ := json.Marshal(resources)
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// profileHashes returns the hash of each of the Device Profiles keyed by profile name
+func profileHashes(profiles map[string]*coreDtos.DeviceProfile) map[string]string {
+	hashes := make(map[string]string, len(profiles))
+	for name, profile := range profiles {
+		hashes[name] = profileHash(*profile)
+	}
+	return hashes
+}
+
+// stampProfileHashes records the hashes of the recorded Device Profiles in the recording metadata, once the
+// profiles have been captured, so they are pinned to the versions the Events were captured with.
+// Must be called while holding the recording mutex.
+func (m *dataManager) stampProfileHashes() {
+	if m.recordedData.Metadata == nil || m.recordedData.Metadata.ProfileHashes != nil || len(m.recordedData.Profiles) == 0 {
+		return
+	}
+
+	// The metadata may be shared with previous exports, so is copied rather than modified
+	stamped := *m.recordedData.Metadata
+	stamped.ProfileHashes = profileHashes(m.recordedData.Profiles)
+	m.recordedData.Metadata = &stamped
+}
+
+// deployedProfiles holds the hashes of the currently deployed Device Profiles the recorded Events were captured
+// with, keyed by profile name, along with the errors of those which couldn't be fetched. Profiles no longer deployed
+// have an empty hash.
+type deployedProfiles struct {
+	hashes map[string]string
+	errors map[string]error
+}
+
+// pinnedProfileHashes returns the hashes of the Device Profiles the recorded Events were captured with, keyed by
+// profile name. Recordings from before the hashes were recorded are checked against the profiles they include.
+// Must be called while holding the recording mutex.
+func (m *dataManager) pinnedProfileHashes() map[string]string {
+	if m.recordedData == nil {
+		return nil
+	}
+
+	if m.recordedData.Metadata != nil && m.recordedData.Metadata.ProfileHashes != nil {
+		return m.recordedData.Metadata.ProfileHashes
+	}
+
+	return profileHashes(m.recordedData.Profiles)
+}
+
+// fetchDeployedProfiles fetches the currently deployed versions of the Device Profiles the recorded Events were
+// captured with from Core Metadata for the drift check, or returns nil if profiles aren't checked. The profiles are
+// fetched concurrently and without holding the recording mutex, so requests aren't held up by Core Metadata.
+func (m *dataManager) fetchDeployedProfiles() *deployedProfiles {
+	m.recordingMutex.Lock()
+	pinned := m.pinnedProfileHashes()
+	m.recordingMutex.Unlock()
+
+	if len(pinned) == 0 {
+		return nil
+	}
+
+	switch m.appSvc.ApplicationSettings()[ReplayProfileDriftPolicyAppSetting] {
+	case profileDriftPolicyWarn, profileDriftPolicyFail:
+	default:
+		return nil
+	}
+
+	deployed := &deployedProfiles{hashes: make(map[string]string, len(pinned)), errors: make(map[string]error)}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for name := range pinned {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+
+			response, err := m.appSvc.DeviceProfileClient().DeviceProfileByName(context.Background(), name)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			switch {
+			case err != nil && err.Code() == http.StatusNotFound:
+				deployed.hashes[name] = ""
+			case err != nil:
+				deployed.errors[name] = err
+			default:
+				deployed.hashes[name] = profileHash(response.Profile)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	return deployed
+}
+
+// checkProfileDrift compares the hashes of the Device Profiles the recorded Events were captured with against
+// those deployed, as fetched by fetchDeployedProfiles, and returns the sorted names of the profiles which differ.
+// Profiles no longer deployed are left to the ReplayValidationPolicy. With the "fail" policy an error wrapping
+// profileDriftError is returned if any differ, and an error is returned if any couldn't be fetched. With the "warn"
+// policy both are logged and the replay continues. Must be called while holding the recording mutex.
+func (m *dataManager) checkProfileDrift(deployed *deployedProfiles, lc logger.LoggingClient) ([]string, error) {
+	policy := m.appSvc.ApplicationSettings()[ReplayProfileDriftPolicyAppSetting]
+	switch policy {
+	case "":
+		return nil, nil
+	case profileDriftPolicyWarn, profileDriftPolicyFail:
+	default:
+		return nil, fmt.Errorf("invalid %s value '%s', must be one of %s or %s", ReplayProfileDriftPolicyAppSetting,
+			policy, profileDriftPolicyWarn, profileDriftPolicyFail)
+	}
+
+	if deployed == nil {
+		deployed = &deployedProfiles{}
+	}
+
+	var drifted []string
+	var failed []string
+	for name, hash := range m.pinnedProfileHashes() {
+		deployedHash, found := deployed.hashes[name]
+		switch {
+		case found && len(deployedHash) > 0 && deployedHash != hash:
+			drifted = append(drifted, name)
+		case found:
+		case deployed.errors[name] != nil:
+			failed = append(failed, fmt.Sprintf("%s (%v)", name, deployed.errors[name]))
+		default:
+			// The recorded data was replaced since the profiles were fetched
+			failed = append(failed, fmt.Sprintf("%s (not fetched)", name))
+		}
+	}
+	sort.Strings(drifted)
+	sort.Strings(failed)
+
+	if len(failed) > 0 {
+		if policy == profileDriftPolicyFail {
+			return nil, fmt.Errorf("failed to load device profiles for drift check: %s", strings.Join(failed, ", "))
+		}
+
+		lc.Warnf("ARR Replay: Device profiles %s couldn't be loaded for the drift check and weren't checked", strings.Join(failed, ", "))
+	}
+
+	if len(drifted) == 0 {
+		return nil, nil
+	}
+
+	if policy == profileDriftPolicyFail {
+		return nil, fmt.Errorf("%w: %s", profileDriftError, strings.Join(drifted, ", "))
+	}
+
+	lc.Warnf("ARR Replay: Device profiles %s differ from those the events were captured with, replayed values may not match their value types",
+		strings.Join(drifted, ", "))
+
+	return drifted, nil
+}
@@ -0,0 +1,182 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileHash(t *testing.T) {
+	scale := 0.1
+	profile := coreDtos.DeviceProfile{
+		DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: expectedProfileName},
+		DeviceResources: []coreDtos.DeviceResource{
+			{Name: "R1", Properties: coreDtos.ResourceProperties{ValueType: common.ValueTypeInt16}},
+			{Name: "R2", Properties: coreDtos.ResourceProperties{ValueType: common.ValueTypeFloat32, Units: "C"}},
+		},
+	}
+	hash := profileHash(profile)
+
+	reordered := profile
+	reordered.DeviceResources = []coreDtos.DeviceResource{profile.DeviceResources[1], profile.DeviceResources[0]}
+	assert.Equal(t, hash, profileHash(reordered), "resource order isn't material")
+
+	described := profile
+	described.Description = "new description"
+	assert.Equal(t, hash, profileHash(described), "description isn't material")
+
+	retyped := profile
+	retyped.DeviceResources = []coreDtos.DeviceResource{profile.DeviceResources[0],
+		{Name: "R2", Properties: coreDtos.ResourceProperties{ValueType: common.ValueTypeFloat64, Units: "C"}}}
+	assert.NotEqual(t, hash, profileHash(retyped), "value type is material")
+
+	scaled := profile
+	scaled.DeviceResources = []coreDtos.DeviceResource{profile.DeviceResources[1],
+		{Name: "R1", Properties: coreDtos.ResourceProperties{ValueType: common.ValueTypeInt16, Scale: &scale}}}
+	assert.NotEqual(t, hash, profileHash(scaled), "scale is material")
+}
+
+func TestDataManager_StampProfileHashes(t *testing.T) {
+	profile := &coreDtos.DeviceProfile{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: expectedProfileName}}
+	metadata := &dtos.RecordingMetadata{Hostname: "gateway-1"}
+
+	target := NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Profiles: map[string]*coreDtos.DeviceProfile{expectedProfileName: profile},
+		Metadata: metadata,
+	}
+
+	target.stampProfileHashes()
+	require.NotNil(t, target.recordedData.Metadata.ProfileHashes)
+	assert.Equal(t, profileHash(*profile), target.recordedData.Metadata.ProfileHashes[expectedProfileName])
+	assert.Equal(t, "gateway-1", target.recordedData.Metadata.Hostname)
+	assert.Nil(t, metadata.ProfileHashes, "shared metadata must not be modified")
+
+	// The hashes are pinned once stamped, so later changes to the profiles don't change them
+	stamped := target.recordedData.Metadata
+	target.recordedData.Profiles[expectedProfileName] = &coreDtos.DeviceProfile{
+		DeviceResources: []coreDtos.DeviceResource{{Name: "R1"}},
+	}
+	target.stampProfileHashes()
+	assert.Same(t, stamped, target.recordedData.Metadata)
+}
+
+func TestDataManager_CheckProfileDrift(t *testing.T) {
+	recorded := coreDtos.DeviceProfile{
+		DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: expectedProfileName},
+		DeviceResources: []coreDtos.DeviceResource{
+			{Name: "R1", Properties: coreDtos.ResourceProperties{ValueType: common.ValueTypeInt16}},
+		},
+	}
+	changed := recorded
+	changed.DeviceResources = []coreDtos.DeviceResource{
+		{Name: "R1", Properties: coreDtos.ResourceProperties{ValueType: common.ValueTypeString}},
+	}
+	notFound := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "profile not found", nil)
+	commError := edgexErr.NewCommonEdgeX(edgexErr.KindServiceUnavailable, "metadata unavailable", nil)
+
+	tests := []struct {
+		Name            string
+		Policy          string
+		Pinned          bool
+		Deployed        coreDtos.DeviceProfile
+		ProfileError    edgexErr.EdgeX
+		ExpectedDrifted []string
+		ExpectedError   error
+		ExpectedChecked bool
+	}{
+		{"Not set", "", true, changed, nil, nil, nil, false},
+		{"Unchanged", profileDriftPolicyWarn, true, recorded, nil, nil, nil, true},
+		{"Changed warn", profileDriftPolicyWarn, true, changed, nil, []string{expectedProfileName}, nil, true},
+		{"Changed fail", profileDriftPolicyFail, true, changed, nil, nil, profileDriftError, true},
+		{"Changed not pinned", profileDriftPolicyWarn, false, changed, nil, []string{expectedProfileName}, nil, true},
+		{"Not deployed", profileDriftPolicyFail, true, changed, notFound, nil, nil, true},
+		// Profiles which can't be loaded are logged and the replay continues, unless the policy is fail
+		{"Metadata unavailable warn", profileDriftPolicyWarn, true, changed, commError, nil, nil, true},
+		{"Metadata unavailable fail", profileDriftPolicyFail, true, changed, commError, nil, nil, true},
+		{"Invalid", "bogus", true, changed, nil, nil, nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockProfileClient := &clientMocks.DeviceProfileClient{}
+			mockProfileClient.On("DeviceProfileByName", mock.Anything, expectedProfileName).
+				Return(responses.DeviceProfileResponse{Profile: test.Deployed}, test.ProfileError)
+
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ReplayProfileDriftPolicyAppSetting: test.Policy})
+			mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+			target.recordedData = &recordedData{
+				Profiles: map[string]*coreDtos.DeviceProfile{expectedProfileName: &recorded},
+				Metadata: &dtos.RecordingMetadata{},
+			}
+			if test.Pinned {
+				target.stampProfileHashes()
+				// The pinned hash is used rather than the recorded profile, which may have been replaced by an import
+				target.recordedData.Profiles[expectedProfileName] = &changed
+			}
+
+			drifted, err := target.checkProfileDrift(target.fetchDeployedProfiles(), logger.NewMockClient())
+			switch {
+			case test.ExpectedError != nil:
+				require.ErrorIs(t, err, test.ExpectedError)
+			case test.Policy == "bogus", test.ProfileError == commError && test.Policy == profileDriftPolicyFail:
+				require.Error(t, err)
+			default:
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.ExpectedDrifted, drifted)
+
+			if test.ExpectedChecked {
+				mockProfileClient.AssertCalled(t, "DeviceProfileByName", mock.Anything, expectedProfileName)
+			} else {
+				mockProfileClient.AssertNotCalled(t, "DeviceProfileByName", mock.Anything, expectedProfileName)
+			}
+		})
+	}
+}
+
+func TestDataManager_CheckProfileDrift_RecordedDataReplaced(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ReplayProfileDriftPolicyAppSetting: profileDriftPolicyFail})
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Profiles: map[string]*coreDtos.DeviceProfile{expectedProfileName: {}},
+	}
+
+	// Profiles of recorded data imported after the deployed profiles were fetched can't be checked
+	_, err := target.checkProfileDrift(&deployedProfiles{hashes: map[string]string{}}, logger.NewMockClient())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), expectedProfileName+" (not fetched)")
+}
@@ -0,0 +1,407 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+)
+
+const (
+	// ImportBatchSizeAppSetting is the most Device Profiles or Devices added to or updated in Core Metadata by a
+	// single request when provisioning an import
+	ImportBatchSizeAppSetting = "ImportBatchSize"
+	// ImportConcurrencyAppSetting is the most requests made to Core Metadata at once when provisioning an import
+	ImportConcurrencyAppSetting = "ImportConcurrency"
+
+	defaultImportBatchSize   = 100
+	defaultImportConcurrency = 4
+)
+
+var importRolledBackError = errors.New("import rolled back since some device profiles or devices failed to be provisioned")
+
+// provisioningError lists the Device Profiles and Devices which failed to be added to or updated in Core Metadata,
+// while the rest were provisioned
+type provisioningError struct {
+	failures []dtos.ProvisioningFailure
+}
+
+func (e *provisioningError) Error() string {
+	messages := make([]string, len(e.failures))
+	for i, failure := range e.failures {
+		messages[i] = failure.Error
+	}
+
+	return fmt.Sprintf("failed to provision %d device profiles and devices: %s", len(e.failures), strings.Join(messages, "; "))
+}
+
+// ProvisioningFailures returns the failures, so they can be reported by the callers without depending on the type
+func (e *provisioningError) ProvisioningFailures() []dtos.ProvisioningFailure {
+	return e.failures
+}
+
+// joinProvisioningErrors returns the first error which isn't a provisioning error, otherwise the failures of all
+// the provisioning errors as one, or nil if there are none
+func joinProvisioningErrors(errs ...error) error {
+	var failures []dtos.ProvisioningFailure
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		var provisioning *provisioningError
+		if !errors.As(err, &provisioning) {
+			return err
+		}
+		failures = append(failures, provisioning.failures...)
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return &provisioningError{failures: failures}
+}
+
+func isProvisioningError(err error) bool {
+	var provisioning *provisioningError
+	return errors.As(err, &provisioning)
+}
+
+// provisionImport adds the imported Device Profiles and then Devices to Core Metadata. A provisioning error listing
+// all those which failed is returned once all have been tried, while any other error stops the provisioning. When
+// rollback is true, the Device Profiles and Devices added are deleted if any failed, and an import rolled back error
+// is returned instead.
+func (m *dataManager) provisionImport(data *dtos.RecordedData, overwrite bool, rollback bool) error {
+	// Must handle profiles first, so they exist when a new device is added that references it
+	addedProfiles, err := m.uploadProfiles(data.Profiles, overwrite)
+	var addedDevices []string
+	if err == nil || isProvisioningError(err) {
+		var devicesErr error
+		addedDevices, devicesErr = m.uploadDevices(data.Devices, overwrite)
+		err = joinProvisioningErrors(err, devicesErr)
+	}
+
+	if err == nil || !rollback || len(addedProfiles)+len(addedDevices) == 0 {
+		return err
+	}
+
+	return m.rollbackProvisioning(err, addedProfiles, addedDevices)
+}
+
+// rollbackProvisioning deletes the Devices and then Device Profiles added by a failed import, so Core Metadata isn't
+// left half provisioned. Those updated by the import can't be restored. Any which fail to be deleted are included in
+// the import rolled back error returned.
+func (m *dataManager) rollbackProvisioning(cause error, addedProfiles []string, addedDevices []string) error {
+	_, concurrency, err := m.getImportBatching()
+	if err != nil {
+		concurrency = defaultImportConcurrency
+	}
+
+	deviceClient := m.appSvc.DeviceClient()
+	profileClient := m.appSvc.DeviceProfileClient()
+	lc := m.appSvc.LoggingClient()
+	errs := make([]error, len(addedDevices)+len(addedProfiles))
+
+	// Devices are deleted first, since profiles in use by a device can't be deleted
+	runConcurrently(len(addedDevices), 1, concurrency, func(index int, _ int) {
+		if _, err := deviceClient.DeleteDeviceByName(context.Background(), addedDevices[index]); err != nil {
+			errs[index] = fmt.Errorf("failed to delete device %s from system: %w", addedDevices[index], err)
+			return
+		}
+		lc.Debugf("ARR Import: Rolled back added device %s", addedDevices[index])
+	})
+
+	runConcurrently(len(addedProfiles), 1, concurrency, func(index int, _ int) {
+		if _, err := profileClient.DeleteByName(context.Background(), addedProfiles[index]); err != nil {
+			errs[len(addedDevices)+index] = fmt.Errorf("failed to delete device profile %s from system: %w", addedProfiles[index], err)
+			return
+		}
+		lc.Debugf("ARR Import: Rolled back added device profile %s", addedProfiles[index])
+	})
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w, but failed to delete some of the added device profiles and devices: %s: %w", importRolledBackError, cause.Error(), err)
+	}
+
+	return fmt.Errorf("%w, deleted %d added device profiles and %d added devices: %s", importRolledBackError, len(addedProfiles), len(addedDevices), cause.Error())
+}
+
+// getImportBatching returns the batch size and concurrency the imported Device Profiles and Devices are provisioned
+// with from the App Settings
+func (m *dataManager) getImportBatching() (int, int, error) {
+	settings := m.appSvc.ApplicationSettings()
+	batchSize := defaultImportBatchSize
+	concurrency := defaultImportConcurrency
+
+	if value := settings[ImportBatchSizeAppSetting]; len(value) > 0 {
+		var err error
+		batchSize, err = strconv.Atoi(value)
+		if err != nil || batchSize < 1 {
+			return 0, 0, fmt.Errorf("invalid %s value '%s', must be an integer greater than 0", ImportBatchSizeAppSetting, value)
+		}
+	}
+
+	if value := settings[ImportConcurrencyAppSetting]; len(value) > 0 {
+		var err error
+		concurrency, err = strconv.Atoi(value)
+		if err != nil || concurrency < 1 {
+			return 0, 0, fmt.Errorf("invalid %s value '%s', must be an integer greater than 0", ImportConcurrencyAppSetting, value)
+		}
+	}
+
+	return batchSize, concurrency, nil
+}
+
+// runConcurrently calls the func for each of the batches of up to the batch size of the count items, with the start
+// and end indexes of the batch, running up to the concurrency at once
+func runConcurrently(count int, batchSize int, concurrency int, fn func(start int, end int)) {
+	slots := make(chan struct{}, concurrency)
+	var wait sync.WaitGroup
+	for start := 0; start < count; start += batchSize {
+		end := min(start+batchSize, count)
+
+		slots <- struct{}{}
+		wait.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wait.Done()
+			}()
+			fn(start, end)
+		}()
+	}
+
+	wait.Wait()
+}
+
+// batchErrors returns the error of each of the count items of a batch request, from the response the multi-status
+// Core Metadata APIs return for each item in order, or the request's error for all the items if it failed as a whole
+func batchErrors(count int, responses []commonDTO.BaseResponse, err error) []error {
+	errs := make([]error, count)
+	for i := range errs {
+		switch {
+		case err != nil:
+			errs[i] = err
+		case i < len(responses) && (responses[i].StatusCode < http.StatusOK || responses[i].StatusCode >= http.StatusMultipleChoices):
+			errs[i] = fmt.Errorf("status %d: %s", responses[i].StatusCode, responses[i].Message)
+		}
+	}
+
+	return errs
+}
+
+func baseResponses(responses []commonDTO.BaseWithIdResponse) []commonDTO.BaseResponse {
+	result := make([]commonDTO.BaseResponse, len(responses))
+	for i, response := range responses {
+		result[i] = response.BaseResponse
+	}
+
+	return result
+}
+
+// uploadProfiles adds the Device Profiles which don't exist to Core Metadata, in batches with the import batch size
+// and concurrency. Existing profiles aren't updated. A provisioning error is returned listing the profiles which
+// failed, once all have been tried. The names of the profiles added are returned.
+func (m *dataManager) uploadProfiles(profiles []coreDtos.DeviceProfile, overwrite bool) ([]string, error) {
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+
+	batchSize, concurrency, err := m.getImportBatching()
+	if err != nil {
+		return nil, err
+	}
+
+	profileClient := m.appSvc.DeviceProfileClient()
+	lc := m.appSvc.LoggingClient()
+	errs := make([]error, len(profiles))
+	missing := make([]bool, len(profiles))
+
+	runConcurrently(len(profiles), 1, concurrency, func(index int, _ int) {
+		_, err := profileClient.DeviceProfileByName(context.Background(), profiles[index].Name)
+		switch {
+		case err != nil && err.Code() == http.StatusNotFound:
+			missing[index] = true
+		case err != nil:
+			errs[index] = fmt.Errorf("failed check if profile %s exists in system: %w", profiles[index].Name, err)
+		case overwrite:
+			// System doesn't allow profiles to be updated as a whole and portions that can be updated,
+			// are just descriptive, so not attempting to do any Device Profile updates.
+			lc.Debugf("ARR Import: Existing device profile %s not updated: Can not update existing device profiles", profiles[index].Name)
+		}
+	})
+
+	var added []int
+	for index := range profiles {
+		if missing[index] {
+			added = append(added, index)
+		}
+	}
+
+	runConcurrently(len(added), batchSize, concurrency, func(start int, end int) {
+		addRequests := make([]requests.DeviceProfileRequest, 0, end-start)
+		for _, index := range added[start:end] {
+			addRequests = append(addRequests, requests.NewDeviceProfileRequest(profiles[index]))
+		}
+
+		responses, err := profileClient.Add(context.Background(), addRequests)
+
+		for i, itemErr := range batchErrors(end-start, baseResponses(responses), err) {
+			index := added[start+i]
+			if itemErr != nil {
+				errs[index] = fmt.Errorf("failed to add device profile %s to system: %w", profiles[index].Name, itemErr)
+				continue
+			}
+			lc.Debugf("ARR Import: Add new device profile %s", profiles[index].Name)
+		}
+	})
+
+	return addedNames(added, errs, func(index int) string { return profiles[index].Name }),
+		newProvisioningError(dtos.ProvisioningKindProfile, errs, func(index int) string { return profiles[index].Name })
+}
+
+// uploadDevices adds the Devices which don't exist to Core Metadata and, when overwriting, updates those which do,
+// in batches with the import batch size and concurrency. A provisioning error is returned listing the devices which
+// failed, once all have been tried. The names of the devices added are returned.
+func (m *dataManager) uploadDevices(devices []coreDtos.Device, overwrite bool) ([]string, error) {
+	if len(devices) == 0 {
+		return nil, nil
+	}
+
+	batchSize, concurrency, err := m.getImportBatching()
+	if err != nil {
+		return nil, err
+	}
+
+	deviceClient := m.appSvc.DeviceClient()
+	lc := m.appSvc.LoggingClient()
+	errs := make([]error, len(devices))
+	missing := make([]bool, len(devices))
+
+	runConcurrently(len(devices), 1, concurrency, func(index int, _ int) {
+		_, err := deviceClient.DeviceNameExists(context.Background(), devices[index].Name)
+		switch {
+		case err != nil && err.Code() == http.StatusNotFound:
+			missing[index] = true
+		case err != nil:
+			errs[index] = fmt.Errorf("failed check if device %s exist in system: %w", devices[index].Name, err)
+		}
+	})
+
+	var added, updated []int
+	for index := range devices {
+		switch {
+		case missing[index]:
+			added = append(added, index)
+		case errs[index] == nil && overwrite:
+			updated = append(updated, index)
+		}
+	}
+
+	runConcurrently(len(added), batchSize, concurrency, func(start int, end int) {
+		addRequests := make([]requests.AddDeviceRequest, 0, end-start)
+		for _, index := range added[start:end] {
+			addRequests = append(addRequests, requests.NewAddDeviceRequest(devices[index]))
+		}
+
+		responses, err := deviceClient.Add(context.Background(), addRequests)
+
+		for i, itemErr := range batchErrors(end-start, baseResponses(responses), err) {
+			index := added[start+i]
+			if itemErr != nil {
+				errs[index] = fmt.Errorf("failed to add device %s to system: %w", devices[index].Name, itemErr)
+				continue
+			}
+			lc.Debugf("ARR Import: Added new device %s", devices[index].Name)
+		}
+	})
+
+	runConcurrently(len(updated), batchSize, concurrency, func(start int, end int) {
+		updateRequests := make([]requests.UpdateDeviceRequest, 0, end-start)
+		for _, index := range updated[start:end] {
+			device := devices[index]
+			updateRequests = append(updateRequests, requests.NewUpdateDeviceRequest(coreDtos.UpdateDevice{
+				Name:           &device.Name,
+				Description:    &device.Description,
+				AdminState:     &device.AdminState,
+				OperatingState: &device.OperatingState,
+				ServiceName:    &device.ServiceName,
+				ProfileName:    &device.ProfileName,
+				Labels:         device.Labels,
+				Location:       device.Location,
+				AutoEvents:     device.AutoEvents,
+				Protocols:      device.Protocols,
+				Tags:           device.Tags,
+				Properties:     device.Properties,
+			}))
+		}
+
+		responses, err := deviceClient.Update(context.Background(), updateRequests)
+
+		for i, itemErr := range batchErrors(end-start, responses, err) {
+			index := updated[start+i]
+			if itemErr != nil {
+				errs[index] = fmt.Errorf("failed to update device %s in system: %w", devices[index].Name, itemErr)
+				continue
+			}
+			lc.Debugf("ARR Import: Updated existing device %s", devices[index].Name)
+		}
+	})
+
+	return addedNames(added, errs, func(index int) string { return devices[index].Name }),
+		newProvisioningError(dtos.ProvisioningKindDevice, errs, func(index int) string { return devices[index].Name })
+}
+
+// addedNames returns the names of the items which were added without error
+func addedNames(added []int, errs []error, name func(index int) string) []string {
+	var names []string
+	for _, index := range added {
+		if errs[index] == nil {
+			names = append(names, name(index))
+		}
+	}
+
+	return names
+}
+
+// newProvisioningError returns a provisioning error listing the items of the kind with errors, in order, or nil if
+// there are none
+func newProvisioningError(kind string, errs []error, name func(index int) string) error {
+	var failures []dtos.ProvisioningFailure
+	for index, err := range errs {
+		if err != nil {
+			failures = append(failures, dtos.ProvisioningFailure{Kind: kind, Name: name(index), Error: err.Error()})
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return &provisioningError{failures: failures}
+}
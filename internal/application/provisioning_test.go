@@ -0,0 +1,269 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_GetImportBatching(t *testing.T) {
+	tests := []struct {
+		Name                string
+		Settings            map[string]string
+		ExpectedBatchSize   int
+		ExpectedConcurrency int
+		ExpectedError       bool
+	}{
+		{"Defaults", map[string]string{}, defaultImportBatchSize, defaultImportConcurrency, false},
+		{"Configured", map[string]string{ImportBatchSizeAppSetting: "10", ImportConcurrencyAppSetting: "2"}, 10, 2, false},
+		{"Invalid batch size", map[string]string{ImportBatchSizeAppSetting: "many"}, 0, 0, true},
+		{"Zero batch size", map[string]string{ImportBatchSizeAppSetting: "0"}, 0, 0, true},
+		{"Negative concurrency", map[string]string{ImportConcurrencyAppSetting: "-1"}, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(test.Settings)
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+			batchSize, concurrency, err := target.getImportBatching()
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedBatchSize, batchSize)
+			assert.Equal(t, test.ExpectedConcurrency, concurrency)
+		})
+	}
+}
+
+func TestRunConcurrently(t *testing.T) {
+	var mutex sync.Mutex
+	var batches [][2]int
+	var running, maxRunning atomic.Int32
+
+	runConcurrently(7, 3, 2, func(start int, end int) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			observed := maxRunning.Load()
+			if current <= observed || maxRunning.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		batches = append(batches, [2]int{start, end})
+	})
+
+	assert.ElementsMatch(t, [][2]int{{0, 3}, {3, 6}, {6, 7}}, batches)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+}
+
+func TestJoinProvisioningErrors(t *testing.T) {
+	profileErr := &provisioningError{failures: []dtos.ProvisioningFailure{{Kind: dtos.ProvisioningKindProfile, Name: "P1", Error: "failed"}}}
+	deviceErr := &provisioningError{failures: []dtos.ProvisioningFailure{{Kind: dtos.ProvisioningKindDevice, Name: "D1", Error: "failed"}}}
+	otherErr := errors.New("invalid setting")
+
+	assert.NoError(t, joinProvisioningErrors(nil, nil))
+	assert.Equal(t, otherErr, joinProvisioningErrors(profileErr, otherErr))
+
+	err := joinProvisioningErrors(profileErr, nil, deviceErr)
+	require.True(t, isProvisioningError(err))
+	var joined *provisioningError
+	require.True(t, errors.As(err, &joined))
+	assert.Equal(t, append(profileErr.failures, deviceErr.failures...), joined.ProvisioningFailures())
+}
+
+func TestDataManager_UploadDevices_Batched(t *testing.T) {
+	devices := make([]coreDtos.Device, 5)
+	for i := range devices {
+		devices[i] = coreDtos.Device{Name: fmt.Sprintf("D%d", i+1), ProfileName: "P1"}
+	}
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	// D5 already exists so is updated, the rest are added
+	mockDeviceClient.On("DeviceNameExists", mock.Anything, "D5").Return(commonDTO.BaseResponse{StatusCode: http.StatusOK}, nil)
+	mockDeviceClient.On("DeviceNameExists", mock.Anything, mock.Anything).
+		Return(commonDTO.BaseResponse{StatusCode: http.StatusNotFound}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "", nil))
+
+	var addedMutex sync.Mutex
+	var batchSizes []int
+	mockDeviceClient.On("Add", mock.Anything, mock.Anything).Return(
+		func(_ context.Context, reqs []requests.AddDeviceRequest) ([]commonDTO.BaseWithIdResponse, edgexErr.EdgeX) {
+			addedMutex.Lock()
+			batchSizes = append(batchSizes, len(reqs))
+			addedMutex.Unlock()
+
+			result := make([]commonDTO.BaseWithIdResponse, len(reqs))
+			for i, req := range reqs {
+				result[i].StatusCode = http.StatusCreated
+				if req.Device.Name == "D2" {
+					result[i].StatusCode = http.StatusConflict
+					result[i].Message = "duplicate"
+				}
+			}
+			return result, nil
+		})
+	mockDeviceClient.On("Update", mock.Anything, mock.Anything).
+		Return(nil, edgexErr.NewCommonEdgeXWrapper(errors.New("metadata unavailable")))
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ImportBatchSizeAppSetting: "2", ImportConcurrencyAppSetting: "2"})
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	added, err := target.uploadDevices(devices, true)
+	require.Error(t, err)
+
+	// The four missing devices are added in two batches, and the failures of both the add and update reported
+	assert.ElementsMatch(t, []int{2, 2}, batchSizes)
+	assert.Equal(t, []string{"D1", "D3", "D4"}, added)
+	var provisioning *provisioningError
+	require.True(t, errors.As(err, &provisioning))
+	failures := provisioning.ProvisioningFailures()
+	require.Len(t, failures, 2)
+	assert.Equal(t, dtos.ProvisioningKindDevice, failures[0].Kind)
+	assert.Equal(t, "D2", failures[0].Name)
+	assert.Contains(t, failures[0].Error, "status 409: duplicate")
+	assert.Equal(t, "D5", failures[1].Name)
+	assert.Contains(t, failures[1].Error, "metadata unavailable")
+}
+
+func TestDataManager_UploadProfiles_InvalidSetting(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ImportConcurrencyAppSetting: "none"})
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	_, err := target.uploadProfiles([]coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "P1"}}}, false)
+	require.Error(t, err)
+	assert.False(t, isProvisioningError(err))
+}
+
+func TestDataManager_ImportRecordedData_ProvisioningFailures(t *testing.T) {
+	importData, _, _ := createTestRecordedData()
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceNameExists", mock.Anything, mock.Anything).
+		Return(commonDTO.BaseResponse{StatusCode: http.StatusNotFound}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "", nil))
+	mockDeviceClient.On("Add", mock.Anything, mock.Anything).Return(nil, nil)
+
+	mockProfileClient := &clientMocks.DeviceProfileClient{}
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, "P1").
+		Return(responses.DeviceProfileResponse{}, edgexErr.NewCommonEdgeXWrapper(errors.New("metadata unavailable")))
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, mock.Anything).Return(responses.DeviceProfileResponse{}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	err := target.ImportRecordedData(&importData, false, false)
+	require.Error(t, err)
+	require.True(t, isProvisioningError(err))
+
+	// The devices are still provisioned and the data imported, despite the failed profile
+	mockDeviceClient.AssertCalled(t, "Add", mock.Anything, mock.Anything)
+	require.NotNil(t, target.recordedData)
+	assert.Equal(t, importData.RecordedEvents, target.recordedData.Events.events())
+}
+
+func TestDataManager_ImportRecordedData_Rollback(t *testing.T) {
+	tests := []struct {
+		Name          string
+		DeleteError   edgexErr.EdgeX
+		ExpectedError string
+	}{
+		{"Rolled back", nil, "deleted 1 added device profiles and 3 added devices"},
+		{"Delete failed", edgexErr.NewCommonEdgeXWrapper(errors.New("metadata unavailable")), "failed to delete device D2 from system"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			importData, _, _ := createTestRecordedData()
+
+			mockDeviceClient := &clientMocks.DeviceClient{}
+			mockDeviceClient.On("DeviceNameExists", mock.Anything, mock.Anything).
+				Return(commonDTO.BaseResponse{StatusCode: http.StatusNotFound}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "", nil))
+			mockDeviceClient.On("Add", mock.Anything, mock.Anything).Return(nil, nil)
+			mockDeviceClient.On("DeleteDeviceByName", mock.Anything, "D2").Return(commonDTO.BaseResponse{}, test.DeleteError)
+			mockDeviceClient.On("DeleteDeviceByName", mock.Anything, mock.Anything).Return(commonDTO.BaseResponse{}, nil)
+
+			// P1 is added, while P2 fails, so the import is rolled back
+			mockProfileClient := &clientMocks.DeviceProfileClient{}
+			mockProfileClient.On("DeviceProfileByName", mock.Anything, "P1").
+				Return(responses.DeviceProfileResponse{}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "", nil))
+			mockProfileClient.On("DeviceProfileByName", mock.Anything, "P2").
+				Return(responses.DeviceProfileResponse{}, edgexErr.NewCommonEdgeXWrapper(errors.New("metadata unavailable")))
+			mockProfileClient.On("Add", mock.Anything, mock.Anything).Return(nil, nil)
+			mockProfileClient.On("DeleteByName", mock.Anything, "P1").Return(commonDTO.BaseResponse{}, nil)
+
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("DeviceClient").Return(mockDeviceClient)
+			mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+			mockSdk.On("ApplicationSettings").Return(map[string]string{})
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+			err := target.ImportRecordedData(&importData, false, true)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, importRolledBackError)
+			assert.False(t, isProvisioningError(err))
+			assert.ErrorContains(t, err, test.ExpectedError)
+
+			for _, device := range importData.Devices {
+				mockDeviceClient.AssertCalled(t, "DeleteDeviceByName", mock.Anything, device.Name)
+			}
+			mockProfileClient.AssertCalled(t, "DeleteByName", mock.Anything, "P1")
+			mockProfileClient.AssertNotCalled(t, "DeleteByName", mock.Anything, "P2")
+			assert.Nil(t, target.recordedData)
+		})
+	}
+}
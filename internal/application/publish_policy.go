@@ -0,0 +1,135 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+)
+
+const (
+	defaultMaxPublishRetries       = 5
+	defaultPublishRetryInterval    = time.Second
+	defaultMaxPublishRetryInterval = 30 * time.Second
+)
+
+var invalidOnPublishError = errors.New("invalid OnPublishError, value must be abort, skip or retry")
+var invalidPublishRetry = errors.New("invalid publish retry parameters, MaxPublishRetries, PublishRetryInterval and MaxPublishRetryInterval must be greater than or equal 0")
+
+// publishPolicy applies the replay request's OnPublishError policy when publishing a replayed Event or message fails
+type publishPolicy struct {
+	onError     string
+	maxRetries  int
+	interval    time.Duration
+	maxInterval time.Duration
+}
+
+// newPublishPolicy returns the publish policy for the request, with the defaults applied for the options not set.
+// An error is returned if the request's policy options are invalid.
+func newPublishPolicy(request dtos.ReplayRequest) (*publishPolicy, error) {
+	policy := &publishPolicy{
+		onError:     request.OnPublishError,
+		maxRetries:  request.MaxPublishRetries,
+		interval:    request.PublishRetryInterval,
+		maxInterval: request.MaxPublishRetryInterval,
+	}
+
+	switch policy.onError {
+	case "":
+		policy.onError = dtos.ReplayPublishErrorAbort
+	case dtos.ReplayPublishErrorAbort, dtos.ReplayPublishErrorSkip, dtos.ReplayPublishErrorRetry:
+	default:
+		return nil, invalidOnPublishError
+	}
+
+	if policy.maxRetries < 0 || policy.interval < 0 || policy.maxInterval < 0 {
+		return nil, invalidPublishRetry
+	}
+
+	if policy.maxRetries == 0 {
+		policy.maxRetries = defaultMaxPublishRetries
+	}
+
+	if policy.interval == 0 {
+		policy.interval = defaultPublishRetryInterval
+	}
+
+	if policy.maxInterval == 0 {
+		policy.maxInterval = defaultMaxPublishRetryInterval
+	}
+
+	return policy, nil
+}
+
+// publish calls the publish function and applies the policy if it fails. True is returned if the publish succeeded,
+// otherwise an error is returned if the replay must stop. A skipped publish returns false with no error, as does a
+// retry interrupted by the replay being stopped, which the caller detects on its next check.
+func (m *dataManager) publish(policy *publishPolicy, lc logger.LoggingClient, publish func() error) (bool, error) {
+	// Tracked so a replay unable to publish is canceled once the SessionIdleTimeout passes
+	m.startPublishing()
+	defer m.endPublishing()
+
+	err := publish()
+	if err == nil {
+		return true, nil
+	}
+
+	switch policy.onError {
+	case dtos.ReplayPublishErrorSkip:
+		lc.Warnf("ARR Replay: Publish failed, skipping: %v", err)
+		m.incrementReplayPublishFailedEventCount()
+		return false, nil
+
+	case dtos.ReplayPublishErrorRetry:
+		interval := policy.interval
+		for retry := 1; retry <= policy.maxRetries; retry++ {
+			lc.Warnf("ARR Replay: Publish failed, retry %d of %d in %s: %v", retry, policy.maxRetries, interval.String(), err)
+			m.clock.Sleep(interval)
+
+			if m.replayContext.Err() != nil {
+				return false, nil
+			}
+
+			m.incrementReplayPublishRetryCount()
+			if err = publish(); err == nil {
+				return true, nil
+			}
+
+			interval = min(interval*2, policy.maxInterval)
+		}
+
+		return false, fmt.Errorf("%w after %d retries", err, policy.maxRetries)
+	}
+
+	return false, err
+}
+
+func (m *dataManager) incrementReplayPublishRetryCount() {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+	m.replayPublishRetryCount++
+}
+
+func (m *dataManager) incrementReplayPublishFailedEventCount() {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+	m.replayPublishFailedEventCount++
+}
@@ -0,0 +1,133 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPublishPolicy(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Request       dtos.ReplayRequest
+		Expected      *publishPolicy
+		ExpectedError error
+	}{
+		{
+			Name:     "Defaults",
+			Request:  dtos.ReplayRequest{},
+			Expected: &publishPolicy{onError: dtos.ReplayPublishErrorAbort, maxRetries: defaultMaxPublishRetries, interval: defaultPublishRetryInterval, maxInterval: defaultMaxPublishRetryInterval},
+		},
+		{
+			Name:     "Retry",
+			Request:  dtos.ReplayRequest{OnPublishError: dtos.ReplayPublishErrorRetry, MaxPublishRetries: 2, PublishRetryInterval: time.Millisecond, MaxPublishRetryInterval: time.Second},
+			Expected: &publishPolicy{onError: dtos.ReplayPublishErrorRetry, maxRetries: 2, interval: time.Millisecond, maxInterval: time.Second},
+		},
+		{
+			Name:          "Invalid policy",
+			Request:       dtos.ReplayRequest{OnPublishError: "ignore"},
+			ExpectedError: invalidOnPublishError,
+		},
+		{
+			Name:          "Invalid retries",
+			Request:       dtos.ReplayRequest{OnPublishError: dtos.ReplayPublishErrorRetry, MaxPublishRetries: -1},
+			ExpectedError: invalidPublishRetry,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := newPublishPolicy(test.Request)
+			require.Equal(t, test.ExpectedError, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestDataManager_Publish(t *testing.T) {
+	publishErr := errors.New("bus unavailable")
+
+	tests := []struct {
+		Name                 string
+		OnPublishError       string
+		Failures             int
+		ExpectedPublished    bool
+		ExpectedError        bool
+		ExpectedCalls        int
+		ExpectedRetryCount   int
+		ExpectedFailedEvents int
+	}{
+		{"Success", dtos.ReplayPublishErrorAbort, 0, true, false, 1, 0, 0},
+		{"Abort", dtos.ReplayPublishErrorAbort, 1, false, true, 1, 0, 0},
+		{"Skip", dtos.ReplayPublishErrorSkip, 1, false, false, 1, 0, 1},
+		{"Retry succeeds", dtos.ReplayPublishErrorRetry, 2, true, false, 3, 2, 0},
+		{"Retries exhausted", dtos.ReplayPublishErrorRetry, 10, false, true, 4, 3, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := NewManager(nil, 0, clock.New(), nil, nil).(*dataManager)
+			target.replayContext, target.replayCancelFunc = context.WithCancel(context.Background())
+			policy := &publishPolicy{onError: test.OnPublishError, maxRetries: 3, interval: time.Millisecond, maxInterval: 2 * time.Millisecond}
+
+			calls := 0
+			published, err := target.publish(policy, logger.NewMockClient(), func() error {
+				calls++
+				if calls <= test.Failures {
+					return publishErr
+				}
+				return nil
+			})
+
+			assert.Equal(t, test.ExpectedPublished, published)
+			if test.ExpectedError {
+				require.ErrorIs(t, err, publishErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.ExpectedCalls, calls)
+			assert.Equal(t, test.ExpectedRetryCount, target.replayPublishRetryCount)
+			assert.Equal(t, test.ExpectedFailedEvents, target.replayPublishFailedEventCount)
+		})
+	}
+}
+
+func TestDataManager_Publish_Canceled(t *testing.T) {
+	target := NewManager(nil, 0, clock.New(), nil, nil).(*dataManager)
+	target.replayContext, target.replayCancelFunc = context.WithCancel(context.Background())
+	policy := &publishPolicy{onError: dtos.ReplayPublishErrorRetry, maxRetries: 3, interval: time.Millisecond, maxInterval: time.Millisecond}
+
+	calls := 0
+	published, err := target.publish(policy, logger.NewMockClient(), func() error {
+		calls++
+		target.replayCancelFunc()
+		return errors.New("bus unavailable")
+	})
+
+	assert.False(t, published)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
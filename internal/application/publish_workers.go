@@ -0,0 +1,184 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+)
+
+// ReplayPublishWorkersAppSetting is the number of worker goroutines publishing the replayed Events when the replay
+// request doesn't set PublishWorkers. The Events are published inline by the replay goroutine when not set or 1.
+const ReplayPublishWorkersAppSetting = "ReplayPublishWorkers"
+
+// publishWorkerQueueSize is the number of Events queued for each worker before the replay waits for the worker,
+// so a slow sink holds back the replay rather than the Events piling up in memory
+const publishWorkerQueueSize = 64
+
+var invalidPublishWorkers = errors.New("invalid PublishWorkers, value must be equal or greater than 0")
+
+// getPublishWorkers returns the number of publish workers for the replay, which is the request's PublishWorkers if
+// set, otherwise the ReplayPublishWorkers App Setting
+func (m *dataManager) getPublishWorkers(request dtos.ReplayRequest) (int, error) {
+	if request.PublishWorkers < 0 {
+		return 0, invalidPublishWorkers
+	}
+
+	if request.PublishWorkers > 0 {
+		return request.PublishWorkers, nil
+	}
+
+	value := m.appSvc.ApplicationSettings()[ReplayPublishWorkersAppSetting]
+	if len(value) == 0 {
+		return 1, nil
+	}
+
+	workers, err := strconv.Atoi(value)
+	if err != nil || workers < 0 {
+		return 0, fmt.Errorf("invalid %s value '%s', must be an integer greater than or equal 0", ReplayPublishWorkersAppSetting, value)
+	}
+
+	return max(workers, 1), nil
+}
+
+// publishJob is a replayed Event waiting to be published
+type publishJob struct {
+	topic       string
+	addEvent    requests.AddEventRequest
+	iteration   *replayIteration
+	scheduledAt time.Time
+	// latency is set when the replay measures the end-to-end latency of the published Events
+	latency *latencyCapture
+}
+
+// publishReplayedEvent publishes the replayed Event to the sinks, counting it as replayed if any sink published it.
+// An error is returned if the replay must stop.
+func (m *dataManager) publishReplayedEvent(sinks []*replaySinkState, lc logger.LoggingClient, job publishJob) error {
+	if job.latency != nil {
+		job.latency.stamp(&job.addEvent.Event)
+	}
+
+	published, err := m.publishToSinks(sinks, lc, job.topic, job.addEvent)
+	if err != nil || !published {
+		return err
+	}
+
+	lc.Debugf("ARR Replay: Replayed Event to topic: %s", job.topic)
+
+	job.iteration.published(job.scheduledAt, m.clock.Now())
+	m.incrementReplayedEventCount()
+	if job.latency != nil {
+		job.latency.eventPublished()
+	}
+
+	return nil
+}
+
+// publishWorkers publishes the replayed Events using a pool of goroutines. The Events are partitioned by device, so
+// each device's Events are published in order by the same worker while different devices are published in parallel.
+type publishWorkers struct {
+	queues  []chan publishJob
+	pending sync.WaitGroup
+	stopped sync.WaitGroup
+
+	mutex sync.Mutex
+	err   error
+}
+
+// startPublishWorkers starts the workers publishing to the sinks. Events queued once the replay is canceled or a
+// publish has failed are dropped.
+func (m *dataManager) startPublishWorkers(ctx context.Context, count int, sinks []*replaySinkState,
+	lc logger.LoggingClient) *publishWorkers {
+	workers := &publishWorkers{queues: make([]chan publishJob, count)}
+
+	workers.stopped.Add(count)
+	for i := range workers.queues {
+		queue := make(chan publishJob, publishWorkerQueueSize)
+		workers.queues[i] = queue
+
+		go func() {
+			defer workers.stopped.Done()
+			for job := range queue {
+				if ctx.Err() == nil && workers.failed() == nil {
+					if err := m.publishReplayedEvent(sinks, lc, job); err != nil {
+						workers.fail(err)
+					}
+				}
+				workers.pending.Done()
+			}
+		}()
+	}
+
+	return workers
+}
+
+// submit queues the Event to the worker for its device, waiting if the worker's queue is full
+func (w *publishWorkers) submit(job publishJob) {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(job.addEvent.Event.DeviceName))
+
+	w.pending.Add(1)
+	w.queues[hash.Sum32()%uint32(len(w.queues))] <- job
+}
+
+// flush waits for all the queued Events to be published, returning the error if a publish failed
+func (w *publishWorkers) flush() error {
+	w.pending.Wait()
+	return w.failed()
+}
+
+// stop stops the workers once they have handled the queued Events
+func (w *publishWorkers) stop() {
+	for _, queue := range w.queues {
+		close(queue)
+	}
+	w.stopped.Wait()
+}
+
+// depths returns the number of Events queued for each worker
+func (w *publishWorkers) depths() []int {
+	depths := make([]int, len(w.queues))
+	for index, queue := range w.queues {
+		depths[index] = len(queue)
+	}
+
+	return depths
+}
+
+// failed returns the error of the first failed publish, if any
+func (w *publishWorkers) failed() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.err
+}
+
+func (w *publishWorkers) fail(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
@@ -0,0 +1,165 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_GetPublishWorkers(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Requested     int
+		Setting       string
+		Expected      int
+		ExpectedError bool
+	}{
+		{"Not set", 0, "", 1, false},
+		{"Setting", 0, "4", 4, false},
+		{"Setting 0", 0, "0", 1, false},
+		{"Request overrides setting", 2, "4", 2, false},
+		{"Bad setting", 0, "many", 0, true},
+		{"Negative setting", 0, "-1", 0, true},
+		{"Negative request", -1, "", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ReplayPublishWorkersAppSetting: test.Setting})
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+			actual, err := target.getPublishWorkers(dtos.ReplayRequest{PublishWorkers: test.Requested})
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func publishWorkersTestData(t *testing.T, deviceNames []string, eventsPerDevice int) *recordedData {
+	var events []coreDtos.Event
+	devices := make(map[string]*coreDtos.Device)
+	origin := time.Now().UnixNano()
+	for sequence := range eventsPerDevice {
+		for _, deviceName := range deviceNames {
+			event := coreDtos.NewEvent(expectedProfileName, deviceName, expectedSourceName)
+			event.Origin = origin
+			require.NoError(t, event.AddSimpleReading("Sequence", common.ValueTypeInt32, int32(sequence)))
+			events = append(events, event)
+			devices[deviceName] = &coreDtos.Device{Name: deviceName, ServiceName: expectedServiceName}
+		}
+	}
+
+	return &recordedData{Events: newEventStore(events), Devices: devices}
+}
+
+func TestDataManager_StartReplay_PublishWorkers(t *testing.T) {
+	deviceNames := []string{"D1", "D2", "D3", "D4", "D5"}
+	eventsPerDevice := 50
+
+	var mutex sync.Mutex
+	published := make(map[string][]string)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ReplayPublishWorkersAppSetting: "3"})
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).
+		Run(func(args mock.Arguments) {
+			event := args.Get(1).(requests.AddEventRequest).Event
+			mutex.Lock()
+			defer mutex.Unlock()
+			published[event.DeviceName] = append(published[event.DeviceName], event.Readings[0].Value)
+		}).
+		Return(nil)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = publishWorkersTestData(t, deviceNames, eventsPerDevice)
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, RepeatCount: 2})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	assert.Empty(t, status.Message)
+	assert.Equal(t, 2*len(deviceNames)*eventsPerDevice, status.EventCount)
+	require.Len(t, status.Iterations, 2)
+	assert.Equal(t, len(deviceNames)*eventsPerDevice, status.Iterations[0].EventCount)
+	assert.Equal(t, len(deviceNames)*eventsPerDevice, status.Iterations[1].EventCount)
+
+	// Each device's Events are published in their recorded order, once for each repeat
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, deviceName := range deviceNames {
+		var expected []string
+		for range 2 {
+			for sequence := range eventsPerDevice {
+				expected = append(expected, fmt.Sprint(sequence))
+			}
+		}
+		assert.Equal(t, expected, published[deviceName], deviceName)
+	}
+}
+
+func TestDataManager_StartReplay_PublishWorkersFailed(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).Return(errors.New("broker down"))
+	mockSdk.On("NotificationClient").Return(nil)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = publishWorkersTestData(t, []string{"D1", "D2", "D3"}, 20)
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, PublishWorkers: 2})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	assert.Contains(t, status.Message, fmt.Sprintf(replayPublishFailed, "broker down"))
+	assert.Zero(t, status.EventCount)
+	assert.Empty(t, status.Iterations)
+}
@@ -0,0 +1,88 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/google/uuid"
+)
+
+var readingsOnlyOpaqueError = errors.New("ReadingsOnly can't be used when recording opaque messages")
+
+// stripEvent returns the Event stripped down to its readings for a readings-only recording. The Ids, Tags and API
+// version of the Event and its Readings are dropped, so the Event can be held as the device, profile, source and origin
+// of its reading tuples of resource, value type, units, origin and value. See utils.CompactEvent.
+func stripEvent(event coreDtos.Event) coreDtos.Event {
+	event.Id = ""
+	event.ApiVersion = ""
+	event.Tags = nil
+
+	// The Readings are shared with the received Event, so they are copied rather than changed in place
+	readings := make([]coreDtos.BaseReading, len(event.Readings))
+	for index, reading := range event.Readings {
+		reading.Id = ""
+		reading.Tags = nil
+		readings[index] = reading
+	}
+	event.Readings = readings
+
+	return event
+}
+
+// restoreEvent reconstructs the full Event envelope of an Event stripped by a readings-only recording, so it can be
+// replayed. Events which weren't stripped are left unchanged.
+func restoreEvent(event *coreDtos.Event) {
+	if len(event.ApiVersion) == 0 {
+		event.ApiVersion = common.ApiVersion
+	}
+
+	if len(event.Id) == 0 {
+		event.Id = uuid.NewString()
+	}
+
+	for index := range event.Readings {
+		if len(event.Readings[index].Id) == 0 {
+			event.Readings[index].Id = uuid.NewString()
+		}
+	}
+}
+
+// expandBatchedReadings returns the Events of a readings-only recording from the batched JSON of their reading
+// tuples. False is returned if the batched data isn't reading tuples.
+func expandBatchedReadings(data any) ([]coreDtos.Event, bool) {
+	batched, ok := data.([][]byte)
+	if !ok {
+		return nil, false
+	}
+
+	events := make([]coreDtos.Event, len(batched))
+	for index, item := range batched {
+		compact := dtos.ReadingsOnlyEvent{}
+		if err := json.Unmarshal(item, &compact); err != nil {
+			return nil, false
+		}
+		events[index] = utils.ExpandEvent(compact)
+	}
+
+	return events, true
+}
@@ -0,0 +1,146 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReadingsOnlyTestEvent(t *testing.T) coreDtos.Event {
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	require.NoError(t, event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21)))
+	require.NoError(t, event.AddSimpleReading("Humidity", common.ValueTypeInt32, int32(50)))
+	event.Readings[1].Tags = coreDtos.Tags{"calibrated": true}
+	event.Tags = coreDtos.Tags{"site": "north"}
+	return event
+}
+
+func TestStripEvent(t *testing.T) {
+	event := newReadingsOnlyTestEvent(t)
+
+	actual := stripEvent(event)
+	assert.Empty(t, actual.Id)
+	assert.Empty(t, actual.ApiVersion)
+	assert.Nil(t, actual.Tags)
+	assert.Equal(t, event.Origin, actual.Origin)
+	require.Len(t, actual.Readings, 2)
+	for index, reading := range actual.Readings {
+		assert.Empty(t, reading.Id)
+		assert.Nil(t, reading.Tags)
+		assert.Equal(t, event.Readings[index].ResourceName, reading.ResourceName)
+		assert.Equal(t, event.Readings[index].Value, reading.Value)
+		assert.Equal(t, event.Readings[index].Origin, reading.Origin)
+	}
+
+	// The received Event is left unchanged
+	assert.NotEmpty(t, event.Readings[0].Id)
+	assert.NotNil(t, event.Readings[1].Tags)
+
+	// The stripped Event is exported without the Ids and Tags
+	full, err := json.Marshal(event)
+	require.NoError(t, err)
+	stripped, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.Less(t, len(stripped), len(full)-2*36)
+}
+
+func TestRestoreEvent(t *testing.T) {
+	event := newReadingsOnlyTestEvent(t)
+
+	actual := stripEvent(event)
+	restoreEvent(&actual)
+	assert.Equal(t, common.ApiVersion, actual.ApiVersion)
+	assert.NotEmpty(t, actual.Id)
+	assert.NotEqual(t, event.Id, actual.Id)
+	for _, reading := range actual.Readings {
+		assert.NotEmpty(t, reading.Id)
+	}
+	request := requests.NewAddEventRequest(actual)
+	require.NoError(t, request.Validate())
+
+	// Full Events are left unchanged
+	expected := event
+	restoreEvent(&event)
+	assert.Equal(t, expected, event)
+}
+
+func TestDataManager_CountEvents_ReadingsOnly(t *testing.T) {
+	lc := logger.NewMockClient()
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(lc)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.readingsOnly = true
+
+	continuePipeline, actual := target.countEvents(pkg.NewAppFuncContextForTest("123", lc), newReadingsOnlyTestEvent(t))
+	require.True(t, continuePipeline)
+
+	// The Event is batched as its reading tuples
+	compact, ok := actual.(dtos.ReadingsOnlyEvent)
+	require.True(t, ok)
+	require.Len(t, compact.Readings, 2)
+	assert.Equal(t, "Temperature", compact.Readings[0].ResourceName)
+	assert.Equal(t, "21", compact.Readings[0].Value)
+	assert.Equal(t, 1, target.recordedEventCount)
+}
+
+func TestDataManager_ProcessBatchedData_ReadingsOnly(t *testing.T) {
+	lc := logger.NewMockClient()
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(lc)
+	mockSdk.On("RemoveAllFunctionPipelines")
+	mockSdk.On("NotificationClient").Return(nil)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.readingsOnly = true
+	now := time.Now()
+	target.recordingStartedAt = &now
+
+	events := []coreDtos.Event{newReadingsOnlyTestEvent(t), newReadingsOnlyTestEvent(t)}
+	var batched [][]byte
+	for _, event := range events {
+		_, compact := target.countEvents(pkg.NewAppFuncContextForTest("123", lc), event)
+		data, err := json.Marshal(compact)
+		require.NoError(t, err)
+		batched = append(batched, data)
+	}
+
+	continuePipeline, result := target.processBatchedData(nil, batched)
+	require.False(t, continuePipeline)
+	require.Nil(t, result)
+	require.NotNil(t, target.recordedData)
+
+	// The Events are recorded stripped down to their readings
+	assert.Equal(t, []coreDtos.Event{stripEvent(events[0]), stripEvent(events[1])}, target.recordedData.Events.events())
+
+	target.recordingStartedAt = &now
+	_, result = target.processBatchedData(nil, [][]byte{[]byte("not json")})
+	assert.Equal(t, batchDataNotEventCollectionError, result)
+}
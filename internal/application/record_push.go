@@ -0,0 +1,99 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/google/uuid"
+)
+
+var noRecordingRunningToPushError = errors.New("no recording currently running to push Events to")
+var pushOpaqueRecordingError = errors.New("Events can't be pushed to an opaque recording")
+
+// PushRecordEvents includes the Events pushed over REST in the active recording. The Events pass through the
+// recording's pipeline in the background, as if received from the MessageBus. An error is returned if no recording is
+// running or the recording is opaque.
+func (m *dataManager) PushRecordEvents(request dtos.RecordPushRequest) error {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.recordingStartedAt == nil {
+		return noRecordingRunningToPushError
+	}
+
+	if m.recordPipeline == nil {
+		return pushOpaqueRecordingError
+	}
+
+	// The batch may hold the pipeline until the recording's Duration has passed, so it isn't run in the request
+	go m.recordPushedEvents(m.sessionLogger(m.recordingLabel, m.recordingCorrelationID), m.recordingStartedAt, m.recordPipeline, request)
+
+	return nil
+}
+
+// recordPushedEvents runs the pushed Events through the recording's pipeline, stopping if the recording they were
+// pushed to has ended
+func (m *dataManager) recordPushedEvents(lc logger.LoggingClient, startedAt *time.Time,
+	pipeline []appInterfaces.AppFunction, request dtos.RecordPushRequest) {
+	now := m.clock.Now().UnixNano()
+
+	for index, event := range request.Events {
+		m.recordingMutex.Lock()
+		recording := m.recordingStartedAt == startedAt
+		m.recordingMutex.Unlock()
+
+		if !recording {
+			lc.Debugf("ARR Record Push: Recording ended, %d pushed Events not recorded", len(request.Events)-index)
+			return
+		}
+
+		fillInjectedEvent(&event, now)
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			lc.Errorf("ARR Record Push: unable to encode Event for device %s: %v", event.DeviceName, err)
+			continue
+		}
+
+		ctx := m.appSvc.BuildContext(uuid.NewString(), common.ContentTypeJSON)
+		ctx.AddValue(payloadSizeKey, strconv.Itoa(len(payload)))
+		if len(request.ReceivedTopic) > 0 {
+			ctx.AddValue(appInterfaces.RECEIVEDTOPIC, request.ReceivedTopic)
+		}
+
+		var data any = event
+		for _, function := range pipeline {
+			var next bool
+			if next, data = function(ctx, data); !next {
+				if err, ok := data.(error); ok {
+					lc.Debugf("ARR Record Push: Event for device %s not recorded: %v", event.DeviceName, err)
+				}
+				break
+			}
+		}
+	}
+
+	lc.Debugf("ARR Record Push: %d pushed Events passed to the recording", len(request.Events))
+}
@@ -0,0 +1,99 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_PushRecordEvents(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("NotificationClient").Return(nil).Maybe()
+	mockSdk.On("BuildContext", mock.Anything, common.ContentTypeJSON).
+		Return(func(correlationId string, contentType string) appInterfaces.AppFunctionContext {
+			return pkg.NewAppFuncContextForTest(correlationId, logger.NewMockClient())
+		})
+	// decodeEvent, include devices filter, countEvents, batch.Batch and processBatchedData
+	mockSdk.On("SetDefaultFunctionsPipeline", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	mockSdk.On("RemoveAllFunctionPipelines")
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+
+	err := target.PushRecordEvents(dtos.RecordPushRequest{Events: []coreDtos.Event{{}}})
+	require.ErrorIs(t, err, noRecordingRunningToPushError)
+
+	err = target.StartRecording(dtos.RecordRequest{EventLimit: 2, IncludeDevices: []string{"D1", "D2"}})
+	require.NoError(t, err)
+
+	events := []coreDtos.Event{
+		coreDtos.NewEvent(expectedProfileName, "D1", expectedSourceName),
+		coreDtos.NewEvent(expectedProfileName, "D3", expectedSourceName),
+		{ProfileName: expectedProfileName, DeviceName: "D2", SourceName: expectedSourceName},
+	}
+	receivedTopic := "edgex/events/device/script/P1/D1/S1"
+
+	err = target.PushRecordEvents(dtos.RecordPushRequest{Events: events, ReceivedTopic: receivedTopic})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.RecordingStatus().InProgress
+	}, 5*time.Second, 10*time.Millisecond)
+
+	target.recordingMutex.Lock()
+	defer target.recordingMutex.Unlock()
+
+	require.NotNil(t, target.recordedData)
+	recorded := target.recordedData.Events.events()
+	require.Len(t, recorded, 2)
+	assert.Equal(t, events[0].Id, recorded[0].Id)
+	assert.Equal(t, "D2", recorded[1].DeviceName)
+	assert.NotEmpty(t, recorded[1].Id)
+	assert.NotZero(t, recorded[1].Origin)
+	assert.Equal(t, receivedTopic, target.recordedData.Envelopes[events[0].Id].ReceivedTopic)
+}
+
+func TestDataManager_PushRecordEvents_Opaque(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	// captureMessage, batch.Batch and processBatchedMessages
+	mockSdk.On("SetDefaultFunctionsPipeline", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+
+	err := target.StartRecording(dtos.RecordRequest{EventLimit: 2, Opaque: true})
+	require.NoError(t, err)
+
+	err = target.PushRecordEvents(dtos.RecordPushRequest{Events: []coreDtos.Event{{}}})
+	require.ErrorIs(t, err, pushOpaqueRecordingError)
+}
@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import "errors"
+
+var invalidReplayMaxDuration = errors.New("replay MaxDuration must not be negative")
+var replayMaxDurationReached = errors.New("replay stopped on reaching its MaxDuration")
+
+// startReplayBudget stops the replay once it has run for its MaxDuration, regardless of the repeats remaining.
+// Must be called while holding the recording mutex once the replay has started publishing, i.e. after it is
+// triggered for a replay in standby.
+func (m *dataManager) startReplayBudget() {
+	if m.replayMaxDuration <= 0 {
+		return
+	}
+
+	replayContext := m.replayContext
+	maxDuration := m.replayMaxDuration
+
+	go func() {
+		// The budget wakes at least every maximum replay delay, so it stops soon after the replay ends
+		deadline := m.clock.Now().Add(maxDuration)
+		for wait := maxDuration; wait > 0; wait = deadline.Sub(m.clock.Now()) {
+			if m.maxReplayDelay > 0 && wait > m.maxReplayDelay {
+				wait = m.maxReplayDelay
+			}
+
+			m.clock.Sleep(wait)
+
+			if replayContext.Err() != nil {
+				return
+			}
+		}
+
+		m.recordingMutex.Lock()
+		defer m.recordingMutex.Unlock()
+
+		// The replay has already ended, or a later replay has been started since
+		if m.replayContext != replayContext || replayContext.Err() != nil || m.replayStartedAt == nil {
+			return
+		}
+
+		m.stopReplay(replayMaxDurationReached)
+		m.sessionLogger(m.replayLabel, m.replayCorrelationID).Infof("ARR Replay: Replay stopped on reaching its MaxDuration of %s", maxDuration)
+	}()
+}
+
+// stopReplay cancels the replay, like CancelReplay, with the cause as the replay's error. Must be called while
+// holding the recording mutex while the replay is running.
+func (m *dataManager) stopReplay(cause error) {
+	m.replayStopCause = cause
+	m.replayCancelFunc()
+	m.replayStartedAt = nil
+	m.replayError = cause
+	m.replayPause = nil
+}
+
+// replayStoppedError returns the reason the replay's context was canceled
+func (m *dataManager) replayStoppedError() error {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.replayStopCause != nil {
+		return m.replayStopCause
+	}
+
+	return replayCanceled
+}
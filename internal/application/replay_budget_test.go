@@ -0,0 +1,78 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_StartReplay_MaxDuration(t *testing.T) {
+	target, _ := newStandbyTarget(map[string]string{})
+	virtualClock := clock.NewVirtual(time.Unix(0, 0))
+	target.clock = virtualClock
+
+	require.Equal(t, invalidReplayMaxDuration, target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, MaxDuration: -1}))
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, RepeatCount: 1000, MaxDuration: 5 * time.Second})
+	require.NoError(t, err)
+	require.True(t, target.ReplayStatus().Running)
+
+	// Keeps advancing the virtual time until the replay is stopped, which the repeats remaining would far exceed
+	require.Eventually(t, func() bool {
+		virtualClock.Advance(time.Second)
+		return !target.ReplayStatus().Running
+	}, 10*time.Second, time.Millisecond)
+
+	status := target.ReplayStatus()
+	assert.Equal(t, replayMaxDurationReached.Error(), status.Message)
+	assert.Less(t, status.RepeatCount, 1000)
+
+	// The replay goroutine keeps the reason once it notices the replay was stopped
+	require.Eventually(t, func() bool {
+		virtualClock.Advance(time.Second)
+		return target.replayStoppedError() == replayMaxDurationReached && target.ReplayStatus().Message == replayMaxDurationReached.Error()
+	}, 10*time.Second, time.Millisecond)
+}
+
+func TestDataManager_StartReplay_MaxDuration_Standby(t *testing.T) {
+	target, _ := newStandbyTarget(map[string]string{})
+	virtualClock := clock.NewVirtual(time.Unix(0, 0))
+	target.clock = virtualClock
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, RepeatCount: 1000, MaxDuration: 5 * time.Second, Standby: true})
+	require.NoError(t, err)
+
+	// The time spent in standby doesn't count towards the MaxDuration
+	virtualClock.Advance(time.Minute)
+	require.True(t, target.ReplayStatus().Standby)
+
+	require.NoError(t, target.TriggerReplay())
+	require.True(t, target.ReplayStatus().Running)
+
+	require.Eventually(t, func() bool {
+		virtualClock.Advance(time.Second)
+		return !target.ReplayStatus().Running
+	}, 10*time.Second, time.Millisecond)
+
+	assert.Equal(t, replayMaxDurationReached.Error(), target.ReplayStatus().Message)
+}
@@ -0,0 +1,151 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+// maxReplayIterations is the number of most recent iterations kept in the replay status, so replays repeated many
+// times, or daily replays looping until canceled, don't grow it without bound
+const maxReplayIterations = 100
+
+// replayIteration collects the statistics of one iteration of a replay. It is used by the replay goroutine and, when
+// the replay has them, the publish workers.
+type replayIteration struct {
+	number    int
+	rate      float32
+	startedAt time.Time
+
+	// Set when the replay seeks, so the Events are scheduled from the first Event published since then
+	seekedAt       time.Time
+	firstEventTime int64
+	hasFirstEvent  bool
+
+	// The lag statistics are updated concurrently when the Events are published by the publish workers
+	mutex      sync.Mutex
+	eventCount int
+	totalLag   time.Duration
+	maxLag     time.Duration
+
+	// The session counts when the iteration started, so the iteration's share can be derived when it completes
+	skippedAtStart int
+	failedAtStart  int
+
+	// The scheduling errors of the session and of the metric, if published. Both are safe for concurrent use.
+	schedulingErrors      gometrics.Histogram
+	schedulingErrorMetric gometrics.Histogram
+}
+
+// startReplayIteration returns the statistics for the iteration starting now, numbered from 1
+func (m *dataManager) startReplayIteration(number int, rate float32) *replayIteration {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	it := &replayIteration{
+		number:           number,
+		rate:             rate,
+		startedAt:        m.clock.Now(),
+		skippedAtStart:   m.replaySkippedEventCount,
+		failedAtStart:    m.replayPublishFailedEventCount,
+		schedulingErrors: m.replaySchedulingErrors,
+	}
+	if m.metrics != nil {
+		it.schedulingErrorMetric = m.metrics.schedulingError
+	}
+
+	return it
+}
+
+// scheduledAt returns the time the Event is due to be published, which is its offset from the first Event of the
+// iteration, or since the replay last seeked, at the replay rate. Replays without a rate, such as daily replays, are
+// scheduled by their own means.
+func (it *replayIteration) scheduledAt(eventTime int64) time.Time {
+	if it.rate <= 0 {
+		return it.startedAt
+	}
+
+	if !it.hasFirstEvent {
+		it.firstEventTime = eventTime
+		it.hasFirstEvent = true
+	}
+
+	from := it.startedAt
+	if !it.seekedAt.IsZero() {
+		from = it.seekedAt
+	}
+
+	return from.Add(time.Duration(float64(eventTime-it.firstEventTime) / float64(it.rate)))
+}
+
+// rebase schedules the Events from the next Event published, at the time given, after the replay seeks
+func (it *replayIteration) rebase(now time.Time) {
+	it.seekedAt = now
+	it.hasFirstEvent = false
+}
+
+// published records an Event published at the time given, which was due at the scheduled time
+func (it *replayIteration) published(scheduled time.Time, now time.Time) {
+	schedulingError := now.Sub(scheduled)
+	if it.schedulingErrors != nil {
+		it.schedulingErrors.Update(int64(schedulingError))
+	}
+	if it.schedulingErrorMetric != nil {
+		it.schedulingErrorMetric.Update(schedulingError.Microseconds())
+	}
+
+	lag := max(schedulingError, 0)
+
+	it.mutex.Lock()
+	defer it.mutex.Unlock()
+	it.eventCount++
+	it.totalLag += lag
+	it.maxLag = max(it.maxLag, lag)
+}
+
+// completeReplayIteration counts the completed iteration as a repeat of the replay and adds its statistics to the
+// replay status
+func (m *dataManager) completeReplayIteration(it *replayIteration) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	m.replayedRepeatCount++
+
+	it.mutex.Lock()
+	defer it.mutex.Unlock()
+
+	status := dtos.ReplayIterationStatus{
+		Iteration:               it.number,
+		Duration:                m.clock.Since(it.startedAt),
+		EventCount:              it.eventCount,
+		SkippedEventCount:       m.replaySkippedEventCount - it.skippedAtStart,
+		PublishFailedEventCount: m.replayPublishFailedEventCount - it.failedAtStart,
+		MaxLag:                  it.maxLag,
+	}
+	if it.eventCount > 0 {
+		status.AverageLag = it.totalLag / time.Duration(it.eventCount)
+	}
+
+	m.replayIterations = append(m.replayIterations, status)
+	if len(m.replayIterations) > maxReplayIterations {
+		m.replayIterations = append([]dtos.ReplayIterationStatus(nil), m.replayIterations[1:]...)
+	}
+}
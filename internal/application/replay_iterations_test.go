@@ -0,0 +1,108 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayIteration_ScheduledAt(t *testing.T) {
+	start := time.Unix(100, 0)
+
+	tests := []struct {
+		Name     string
+		Rate     float32
+		Expected []time.Time
+	}{
+		{"rate 1", 1, []time.Time{start, start.Add(2 * time.Second), start.Add(5 * time.Second)}},
+		{"rate 2", 2, []time.Time{start, start.Add(time.Second), start.Add(2500 * time.Millisecond)}},
+		{"no rate", 0, []time.Time{start, start, start}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			it := &replayIteration{rate: test.Rate, startedAt: start}
+
+			var actual []time.Time
+			for _, eventTime := range []int64{int64(10 * time.Second), int64(12 * time.Second), int64(15 * time.Second)} {
+				actual = append(actual, it.scheduledAt(eventTime))
+			}
+
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestDataManager_CompleteReplayIteration(t *testing.T) {
+	virtualClock := clock.NewVirtual(time.Unix(0, 0))
+	target := NewManager(nil, time.Minute, virtualClock, nil, nil).(*dataManager)
+	target.replaySkippedEventCount = 1
+	target.replayPublishFailedEventCount = 2
+	target.replaySchedulingErrors = newSchedulingErrors()
+
+	it := target.startReplayIteration(1, 1)
+
+	scheduled := it.scheduledAt(0)
+	it.published(scheduled, scheduled.Add(3*time.Second))
+	scheduled = it.scheduledAt(int64(time.Second))
+	it.published(scheduled, scheduled.Add(time.Second))
+	// Events published ahead of time have no lag
+	scheduled = it.scheduledAt(int64(2 * time.Second))
+	it.published(scheduled, scheduled.Add(-time.Second))
+
+	target.replaySkippedEventCount = 2
+	target.replayPublishFailedEventCount = 5
+	virtualClock.Advance(4 * time.Second)
+
+	target.completeReplayIteration(it)
+
+	require.Len(t, target.replayIterations, 1)
+	assert.Equal(t, 1, target.replayedRepeatCount)
+	assert.Equal(t, dtos.ReplayIterationStatus{
+		Iteration:               1,
+		Duration:                4 * time.Second,
+		EventCount:              3,
+		SkippedEventCount:       1,
+		PublishFailedEventCount: 3,
+		AverageLag:              4 * time.Second / 3,
+		MaxLag:                  3 * time.Second,
+	}, target.replayIterations[0])
+
+	// The scheduling errors keep Events published ahead of time as negative errors
+	assert.Equal(t, int64(3), target.replaySchedulingErrors.Count())
+	assert.Equal(t, int64(-time.Second), target.replaySchedulingErrors.Min())
+	assert.Equal(t, int64(3*time.Second), target.replaySchedulingErrors.Max())
+}
+
+func TestDataManager_CompleteReplayIteration_Trimmed(t *testing.T) {
+	target := NewManager(nil, time.Minute, clock.NewVirtual(time.Unix(0, 0)), nil, nil).(*dataManager)
+
+	for i := 1; i <= maxReplayIterations+5; i++ {
+		target.completeReplayIteration(target.startReplayIteration(i, 1))
+	}
+
+	require.Len(t, target.replayIterations, maxReplayIterations)
+	assert.Equal(t, 6, target.replayIterations[0].Iteration)
+	assert.Equal(t, maxReplayIterations+5, target.replayIterations[maxReplayIterations-1].Iteration)
+	assert.Equal(t, maxReplayIterations+5, target.replayedRepeatCount)
+}
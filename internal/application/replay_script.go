@@ -0,0 +1,279 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// replayScriptEventGlobal is the Lua global the replay script is given the Event as
+const replayScriptEventGlobal = "event"
+
+// replayScriptLibs are the Lua libraries available to replay scripts, which can't reach the file system, environment
+// or other processes
+var replayScriptLibs = []struct {
+	name string
+	open lua.LGFunction
+}{
+	{lua.BaseLibName, lua.OpenBase},
+	{lua.TabLibName, lua.OpenTable},
+	{lua.StringLibName, lua.OpenString},
+	{lua.MathLibName, lua.OpenMath},
+}
+
+// replayScriptUnsafeGlobals are the base library functions removed from replay scripts, since they load code from
+// files or strings
+var replayScriptUnsafeGlobals = []string{"dofile", "loadfile", "load", "loadstring", "require", "module"}
+
+// replayScript is the Lua script run against each replayed Event before it is published, which filters and may
+// modify the Events. It is only run by the replay's goroutine, since the Lua state isn't safe for concurrent use.
+type replayScript struct {
+	state *lua.LState
+	chunk *lua.LFunction
+}
+
+// compileReplayScript compiles the Lua replay script, returning an error if it isn't valid Lua
+func compileReplayScript(source string) (*lua.FunctionProto, error) {
+	chunk, err := parse.Parse(strings.NewReader(source), "script")
+	if err != nil {
+		return nil, err
+	}
+
+	return lua.Compile(chunk, "script")
+}
+
+// newReplayScript returns the replay script for the Lua source. The script stops running when the context is
+// canceled, so a script which never returns can't outlive the replay.
+func newReplayScript(ctx context.Context, source string) (*replayScript, error) {
+	proto, err := compileReplayScript(source)
+	if err != nil {
+		return nil, err
+	}
+
+	state := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range replayScriptLibs {
+		state.Push(state.NewFunction(lib.open))
+		state.Push(lua.LString(lib.name))
+		state.Call(1, 0)
+	}
+	for _, name := range replayScriptUnsafeGlobals {
+		state.SetGlobal(name, lua.LNil)
+	}
+	state.SetContext(ctx)
+
+	return &replayScript{state: state, chunk: state.NewFunctionFromProto(proto)}, nil
+}
+
+func (s *replayScript) close() {
+	s.state.Close()
+}
+
+// evaluate runs the script against the Event, returning the Event to replay, or false if the script skips it. The
+// script is given the Event as the event global table, with the same fields as its JSON. Returning false or nil skips
+// the Event, true replays the event global, including any changes made to it, and a table replays that table as the
+// Event. Lua numbers are double precision, so origins the script leaves unchanged keep their exact value.
+func (s *replayScript) evaluate(event coreDtos.Event) (coreDtos.Event, bool, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return event, false, err
+	}
+
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return event, false, err
+	}
+
+	s.state.SetGlobal(replayScriptEventGlobal, toLuaValue(s.state, decoded))
+	if err := s.state.CallByParam(lua.P{Fn: s.chunk, NRet: 1, Protect: true}); err != nil {
+		return event, false, err
+	}
+
+	result := s.state.Get(-1)
+	s.state.Pop(1)
+
+	var table *lua.LTable
+	switch value := result.(type) {
+	case lua.LBool:
+		if !bool(value) {
+			return event, false, nil
+		}
+
+		var ok bool
+		if table, ok = s.state.GetGlobal(replayScriptEventGlobal).(*lua.LTable); !ok {
+			return event, false, fmt.Errorf("script returned true but the %s global isn't a table", replayScriptEventGlobal)
+		}
+	case *lua.LTable:
+		table = value
+	default:
+		if value == lua.LNil {
+			return event, false, nil
+		}
+		return event, false, fmt.Errorf("script returned a %s, expected a boolean or an Event table", value.Type())
+	}
+
+	modified, err := eventFromLuaTable(table)
+	if err != nil {
+		return event, false, fmt.Errorf("script returned an invalid Event: %v", err)
+	}
+
+	keepExactOrigins(event, &modified)
+
+	return modified, true, nil
+}
+
+// toLuaValue converts the value decoded from JSON to the Lua value. Objects and arrays are converted to tables.
+func toLuaValue(state *lua.LState, value any) lua.LValue {
+	switch value := value.(type) {
+	case bool:
+		return lua.LBool(value)
+	case float64:
+		return lua.LNumber(value)
+	case string:
+		return lua.LString(value)
+	case []any:
+		table := state.CreateTable(len(value), 0)
+		for _, item := range value {
+			table.Append(toLuaValue(state, item))
+		}
+		return table
+	case map[string]any:
+		table := state.CreateTable(0, len(value))
+		for key, item := range value {
+			table.RawSetString(key, toLuaValue(state, item))
+		}
+		return table
+	default:
+		return lua.LNil
+	}
+}
+
+// fromLuaValue converts the Lua value to the value to marshal to JSON. Tables with only the keys 1 to n are converted
+// to arrays and other tables to objects. Empty tables are converted to null, since they could be either.
+func fromLuaValue(value lua.LValue) (any, error) {
+	switch value := value.(type) {
+	case lua.LBool:
+		return bool(value), nil
+	case lua.LNumber:
+		number := float64(value)
+		if number == math.Trunc(number) && math.Abs(number) < math.MaxInt64 {
+			return int64(number), nil
+		}
+		return number, nil
+	case lua.LString:
+		return string(value), nil
+	case *lua.LTable:
+		return fromLuaTable(value)
+	default:
+		if value == lua.LNil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unsupported %s value", value.Type())
+	}
+}
+
+func fromLuaTable(table *lua.LTable) (any, error) {
+	count := 0
+	table.ForEach(func(lua.LValue, lua.LValue) { count++ })
+	if count == 0 {
+		return nil, nil
+	}
+
+	if length := table.MaxN(); length == count {
+		array := make([]any, length)
+		for index := range array {
+			item, err := fromLuaValue(table.RawGetInt(index + 1))
+			if err != nil {
+				return nil, err
+			}
+			array[index] = item
+		}
+		return array, nil
+	}
+
+	object := make(map[string]any, count)
+	var err error
+	table.ForEach(func(key lua.LValue, item lua.LValue) {
+		if err != nil {
+			return
+		}
+		object[key.String()], err = fromLuaValue(item)
+	})
+
+	return object, err
+}
+
+// eventFromLuaTable converts the Lua table to the Event it holds. Reading values set to numbers are converted to
+// strings, since the values of Readings are always strings.
+func eventFromLuaTable(table *lua.LTable) (coreDtos.Event, error) {
+	if readings, ok := table.RawGetString("readings").(*lua.LTable); ok {
+		readings.ForEach(func(_ lua.LValue, reading lua.LValue) {
+			if reading, ok := reading.(*lua.LTable); ok {
+				if number, ok := reading.RawGetString("value").(lua.LNumber); ok {
+					reading.RawSetString("value", lua.LString(formatLuaNumber(number)))
+				}
+			}
+		})
+	}
+
+	value, err := fromLuaTable(table)
+	if err != nil {
+		return coreDtos.Event{}, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return coreDtos.Event{}, err
+	}
+
+	event := coreDtos.Event{}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return coreDtos.Event{}, err
+	}
+
+	return event, nil
+}
+
+func formatLuaNumber(number lua.LNumber) string {
+	value := float64(number)
+	if value == math.Trunc(value) && math.Abs(value) < math.MaxInt64 {
+		return strconv.FormatInt(int64(value), 10)
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// keepExactOrigins restores the exact origins of the Event and its Readings the script left unchanged, which were
+// rounded to double precision by Lua
+func keepExactOrigins(original coreDtos.Event, modified *coreDtos.Event) {
+	if float64(modified.Origin) == float64(original.Origin) {
+		modified.Origin = original.Origin
+	}
+
+	for index := range min(len(original.Readings), len(modified.Readings)) {
+		if float64(modified.Readings[index].Origin) == float64(original.Readings[index].Origin) {
+			modified.Readings[index].Origin = original.Readings[index].Origin
+		}
+	}
+}
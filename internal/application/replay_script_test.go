@@ -0,0 +1,174 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReplayScriptTestEvent(t *testing.T) coreDtos.Event {
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	// An origin beyond double precision, which must survive the round trip through Lua
+	event.Origin = 1700000000123456789
+	require.NoError(t, event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21)))
+	require.NoError(t, event.AddSimpleReading("Humidity", common.ValueTypeInt32, int32(50)))
+	event.Readings[0].Origin = event.Origin
+	event.Readings[1].Origin = event.Origin + 1
+	return event
+}
+
+func TestReplayScript_Filter(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Script         string
+		ExpectedReplay bool
+	}{
+		{"Match", `return event.deviceName == "` + expectedDeviceName + `"`, true},
+		{"No match", `return event.deviceName == "unknownDevice"`, false},
+		{"Reading value", `return tonumber(event.readings[1].value) > 20`, true},
+		{"Nil", `return nil`, false},
+		{"No return", `local x = 1`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			script, err := newReplayScript(context.Background(), test.Script)
+			require.NoError(t, err)
+			defer script.close()
+
+			event := newReplayScriptTestEvent(t)
+			actual, replay, err := script.evaluate(event)
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedReplay, replay)
+			if replay {
+				// Events the script passes unchanged are replayed exactly as they were
+				assert.Equal(t, event, actual)
+			}
+		})
+	}
+}
+
+func TestReplayScript_Mutate(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Script string
+	}{
+		{"In place", `
+			event.sourceName = "renamed"
+			event.tags = {site = "north"}
+			event.readings[1].value = event.readings[1].value * 2
+			table.remove(event.readings, 2)
+			return true`},
+		{"New table", `
+			return {
+				id = event.id, apiVersion = event.apiVersion, deviceName = event.deviceName,
+				profileName = event.profileName, sourceName = "renamed", origin = event.origin, tags = {site = "north"},
+				readings = {{
+					id = event.readings[1].id, origin = event.readings[1].origin, deviceName = event.deviceName,
+					profileName = event.profileName, resourceName = "Temperature", valueType = "Int32", value = "42",
+				}},
+			}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			script, err := newReplayScript(context.Background(), test.Script)
+			require.NoError(t, err)
+			defer script.close()
+
+			event := newReplayScriptTestEvent(t)
+			actual, replay, err := script.evaluate(event)
+			require.NoError(t, err)
+			require.True(t, replay)
+
+			assert.Equal(t, event.Id, actual.Id)
+			assert.Equal(t, event.Origin, actual.Origin)
+			assert.Equal(t, "renamed", actual.SourceName)
+			assert.Equal(t, coreDtos.Tags{"site": "north"}, actual.Tags)
+			require.Len(t, actual.Readings, 1)
+			assert.Equal(t, event.Readings[0].Id, actual.Readings[0].Id)
+			assert.Equal(t, event.Readings[0].Origin, actual.Readings[0].Origin)
+			assert.Equal(t, "42", actual.Readings[0].Value)
+		})
+	}
+}
+
+func TestReplayScript_KeepsGlobals(t *testing.T) {
+	// Every other Event is replayed
+	script, err := newReplayScript(context.Background(), `count = (count or 0) + 1 return count % 2 == 1`)
+	require.NoError(t, err)
+	defer script.close()
+
+	var replayed int
+	for range 4 {
+		_, replay, err := script.evaluate(newReplayScriptTestEvent(t))
+		require.NoError(t, err)
+		if replay {
+			replayed++
+		}
+	}
+	assert.Equal(t, 2, replayed)
+}
+
+func TestReplayScript_Errors(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Script        string
+		ExpectedError string
+	}{
+		{"Runtime error", `error("bad event")`, "bad event"},
+		{"Invalid return", `return 1`, "expected a boolean or an Event table"},
+		{"Event not a table", `event = "replaced" return true`, "isn't a table"},
+		{"Invalid Event", `event.origin = "soon" return true`, "invalid Event"},
+		{"File access", `return dofile("/etc/passwd")`, "attempt to call a non-function"},
+		{"OS library", `return os.getenv("HOME")`, "attempt to index a non-table"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			script, err := newReplayScript(context.Background(), test.Script)
+			require.NoError(t, err)
+			defer script.close()
+
+			_, _, err = script.evaluate(newReplayScriptTestEvent(t))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.ExpectedError)
+		})
+	}
+
+	_, err := newReplayScript(context.Background(), `return event.deviceName ==`)
+	require.Error(t, err)
+}
+
+func TestReplayScript_Canceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	script, err := newReplayScript(ctx, `while true do end`)
+	require.NoError(t, err)
+	defer script.close()
+
+	_, _, err = script.evaluate(newReplayScriptTestEvent(t))
+	require.Error(t, err)
+}
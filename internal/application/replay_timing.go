@@ -0,0 +1,53 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+// newSchedulingErrors returns the histogram the scheduling errors of a replay session are sampled into, in nanoseconds
+func newSchedulingErrors() gometrics.Histogram {
+	return gometrics.NewHistogram(gometrics.NewUniformSample(schedulingErrorSampleSize))
+}
+
+// schedulingErrorStatus summarizes the scheduling errors sampled, or returns nil if none have been
+func schedulingErrorStatus(schedulingErrors gometrics.Histogram) *dtos.ReplaySchedulingError {
+	if schedulingErrors == nil {
+		return nil
+	}
+
+	snapshot := schedulingErrors.Snapshot()
+	if snapshot.Count() == 0 {
+		return nil
+	}
+
+	percentiles := snapshot.Percentiles([]float64{0.5, 0.95, 0.99})
+
+	return &dtos.ReplaySchedulingError{
+		Count: snapshot.Count(),
+		Mean:  time.Duration(snapshot.Mean()),
+		Min:   time.Duration(snapshot.Min()),
+		Max:   time.Duration(snapshot.Max()),
+		P50:   time.Duration(percentiles[0]),
+		P95:   time.Duration(percentiles[1]),
+		P99:   time.Duration(percentiles[2]),
+	}
+}
@@ -0,0 +1,46 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulingErrorStatus(t *testing.T) {
+	assert.Nil(t, schedulingErrorStatus(nil))
+	assert.Nil(t, schedulingErrorStatus(newSchedulingErrors()))
+
+	schedulingErrors := newSchedulingErrors()
+	for i := -1; i <= 98; i++ {
+		schedulingErrors.Update(int64(time.Duration(i) * time.Millisecond))
+	}
+
+	actual := schedulingErrorStatus(schedulingErrors)
+	require.NotNil(t, actual)
+	assert.Equal(t, int64(100), actual.Count)
+	assert.Equal(t, 48500*time.Microsecond, actual.Mean)
+	assert.Equal(t, -time.Millisecond, actual.Min)
+	assert.Equal(t, 98*time.Millisecond, actual.Max)
+	assert.Equal(t, 48500*time.Microsecond, actual.P50)
+	// The percentiles are interpolated between the sampled errors
+	assert.InDelta(t, float64(93950*time.Microsecond), float64(actual.P95), float64(time.Microsecond))
+	assert.InDelta(t, float64(97990*time.Microsecond), float64(actual.P99), float64(time.Microsecond))
+}
@@ -0,0 +1,225 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/models"
+)
+
+const (
+	// MaxConcurrentSessionsAppSetting is the maximum number of record and replay sessions running at once, beyond
+	// which start requests are queued, or rejected when queueing is disabled. Defaults to 1 when not set. There is a
+	// single recording and a single replay state, so at most a recording and a replay run at once and larger values
+	// behave as 2. Replays using session pipelines, i.e. ShadowMode, LatencyTopic or Standby, never run alongside a
+	// recording since the pipelines are only removed all at once.
+	MaxConcurrentSessionsAppSetting = "MaxConcurrentSessions"
+	// MaxQueuedSessionsAppSetting is the maximum number of record and replay start requests queued while they can't
+	// start, which are started in order as sessions end. Requests are rejected when not set or 0.
+	MaxQueuedSessionsAppSetting = "MaxQueuedSessions"
+)
+
+var sessionQueueFullError = errors.New("session queue is full")
+
+// queuedSession is a record or replay start request waiting for the running sessions to end
+type queuedSession struct {
+	kind   string
+	record dtos.RecordRequest
+	replay dtos.ReplayRequest
+	label  string
+	// correlationID is the correlation ID of the request which queued the session
+	correlationID string
+	queuedAt      int64
+}
+
+// sessionBusy returns true if a new session of the kind can't start now, since it can't run alongside the running
+// sessions or sessions are waiting to start ahead of it, in which case it must be queued.
+// Must be called while holding the recording mutex.
+func (m *dataManager) sessionBusy(kind string, replay dtos.ReplayRequest) (bool, error) {
+	if len(m.sessionQueue) > 0 {
+		return true, nil
+	}
+
+	canRun, err := m.sessionCanRun(kind, replay)
+	return !canRun, err
+}
+
+// sessionCanRun returns true if a session of the kind can run alongside the running sessions. Sessions of the same
+// kind never run at once since they share the recording or replay state, and a recording only runs alongside a
+// replay when concurrent sessions are allowed and the replay doesn't use session pipelines.
+// Must be called while holding the recording mutex.
+func (m *dataManager) sessionCanRun(kind string, replay dtos.ReplayRequest) (bool, error) {
+	var overlapsPipelines bool
+	if kind == dtos.SessionKindRecord {
+		if m.recordingStartedAt != nil {
+			return false, nil
+		}
+		if m.replayStartedAt == nil {
+			return true, nil
+		}
+		overlapsPipelines = m.replayUsesPipelines
+	} else {
+		if m.replayStartedAt != nil {
+			return false, nil
+		}
+		if m.recordingStartedAt == nil {
+			return true, nil
+		}
+		overlapsPipelines = usesSessionPipelines(replay)
+	}
+
+	maxConcurrent, err := m.getMaxConcurrentSessions()
+	if err != nil {
+		return false, err
+	}
+
+	return maxConcurrent > 1 && !overlapsPipelines, nil
+}
+
+// usesSessionPipelines returns true if the replay adds functions pipelines of its own, which would be removed along
+// with those of a recording
+func usesSessionPipelines(request dtos.ReplayRequest) bool {
+	return request.ShadowMode || len(request.LatencyTopic) > 0 || request.Standby
+}
+
+// getMaxConcurrentSessions returns the configured maximum number of sessions running at once, which defaults to 1
+func (m *dataManager) getMaxConcurrentSessions() (int, error) {
+	value := m.appSvc.ApplicationSettings()[MaxConcurrentSessionsAppSetting]
+	if len(value) == 0 {
+		return 1, nil
+	}
+
+	maxConcurrent, err := strconv.Atoi(value)
+	if err != nil || maxConcurrent < 1 {
+		return 0, fmt.Errorf("invalid %s value '%s', must be an integer greater than 0", MaxConcurrentSessionsAppSetting, value)
+	}
+
+	return maxConcurrent, nil
+}
+
+// getMaxQueuedSessions returns the configured maximum number of queued sessions. Zero is returned if queueing is disabled.
+func (m *dataManager) getMaxQueuedSessions() (int, error) {
+	value := m.appSvc.ApplicationSettings()[MaxQueuedSessionsAppSetting]
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	maxQueued, err := strconv.Atoi(value)
+	if err != nil || maxQueued < 0 {
+		return 0, fmt.Errorf("invalid %s value '%s', must be an integer greater than or equal 0", MaxQueuedSessionsAppSetting, value)
+	}
+
+	return maxQueued, nil
+}
+
+// queueSession adds the session to the session queue. The busy error is returned if queueing is disabled, since the
+// request is then rejected as before. Must be called while holding the recording mutex.
+func (m *dataManager) queueSession(session queuedSession, busyErr error) error {
+	maxQueued, err := m.getMaxQueuedSessions()
+	if err != nil {
+		return err
+	}
+
+	if maxQueued == 0 {
+		return busyErr
+	}
+
+	if len(m.sessionQueue) >= maxQueued {
+		return fmt.Errorf("%w: %d sessions are waiting to start", sessionQueueFullError, len(m.sessionQueue))
+	}
+
+	session.queuedAt = m.clock.Now().UnixNano()
+	m.sessionQueue = append(m.sessionQueue, session)
+
+	m.sessionLogger(session.label, session.correlationID).Debugf("ARR Session Queue: %s session queued at position %d", session.kind, len(m.sessionQueue))
+
+	return nil
+}
+
+// scheduleNextSession starts the next queued session, if any, once the running session has ended. The session is
+// started asynchronously since sessions end from within the recording pipeline and replay goroutine.
+// Must be called while holding the recording mutex.
+func (m *dataManager) scheduleNextSession() {
+	if len(m.sessionQueue) > 0 {
+		go m.startNextQueuedSession()
+	}
+}
+
+// startNextQueuedSession starts the queued sessions in order while they can run alongside the running sessions.
+// Sessions that fail to start are dropped from the queue with a notification, since their requester is no longer
+// waiting on the response. The deployed Device Profiles are fetched for queued replays before taking the lock, the
+// same as StartReplay.
+func (m *dataManager) startNextQueuedSession() {
+	deployed := m.fetchDeployedProfiles()
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	for len(m.sessionQueue) > 0 {
+		session := m.sessionQueue[0]
+		canRun, err := m.sessionCanRun(session.kind, session.replay)
+		if err != nil {
+			// Queued sessions still start one at a time as sessions end
+			m.appSvc.LoggingClient().Errorf("ARR Session Queue: %v", err)
+		}
+		if !canRun {
+			return
+		}
+
+		m.sessionQueue = m.sessionQueue[1:]
+
+		lc := m.sessionLogger(session.label, session.correlationID)
+
+		switch session.kind {
+		case dtos.SessionKindRecord:
+			err = m.startRecording(session.record)
+		case dtos.SessionKindReplay:
+			err = m.startReplay(session.replay, deployed)
+		}
+
+		if err == nil {
+			lc.Debugf("ARR Session Queue: queued %s session started, %d sessions waiting", session.kind, len(m.sessionQueue))
+			continue
+		}
+
+		lc.Errorf("ARR Session Queue: queued %s session failed to start: %v", session.kind, err)
+		m.sendNotification(sessionStartFailedLabel, models.Critical,
+			fmt.Sprintf("Queued %s session failed to start: %v", session.kind, err))
+	}
+}
+
+// queuedSessions returns the queued sessions of the kind along with their positions in the queue.
+// Must be called while holding the recording mutex.
+func (m *dataManager) queuedSessions(kind string) []dtos.QueuedSession {
+	var sessions []dtos.QueuedSession
+	for i, session := range m.sessionQueue {
+		if session.kind == kind {
+			sessions = append(sessions, dtos.QueuedSession{
+				Position: i + 1,
+				Kind:     session.kind,
+				Label:    session.label,
+				QueuedAt: session.queuedAt,
+			})
+		}
+	}
+
+	return sessions
+}
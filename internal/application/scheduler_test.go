@@ -0,0 +1,200 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_QueueSessions(t *testing.T) {
+	tests := []struct {
+		Name              string
+		MaxQueuedSessions string
+		ExpectedQueued    int
+		ExpectedError     error
+	}{
+		{"Queueing disabled", "", 0, recordingInProgressError},
+		{"Queueing disabled with zero", "0", 0, recordingInProgressError},
+		{"Queue", "2", 2, nil},
+		{"Queue full", "1", 1, sessionQueueFullError},
+		{"Invalid setting", "many", 0, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("ApplicationSettings").Return(map[string]string{MaxQueuedSessionsAppSetting: test.MaxQueuedSessions})
+
+			target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+			now := time.Now()
+			target.recordingStartedAt = &now
+
+			replayErr := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, Label: "first"})
+			recordErr := target.StartRecording(dtos.RecordRequest{EventLimit: 10, Label: "second"})
+
+			require.Len(t, target.sessionQueue, test.ExpectedQueued)
+
+			switch {
+			case test.MaxQueuedSessions == "many":
+				require.ErrorContains(t, replayErr, MaxQueuedSessionsAppSetting)
+				require.ErrorContains(t, recordErr, MaxQueuedSessionsAppSetting)
+				return
+			case test.ExpectedQueued == 0:
+				require.ErrorIs(t, replayErr, test.ExpectedError)
+				require.ErrorIs(t, recordErr, test.ExpectedError)
+				return
+			case test.ExpectedError != nil:
+				require.NoError(t, replayErr)
+				require.ErrorIs(t, recordErr, test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, replayErr)
+			require.NoError(t, recordErr)
+
+			// The queue positions are visible in the status of each kind of session
+			replayQueue := target.ReplayStatus().Queue
+			require.Len(t, replayQueue, 1)
+			assert.Equal(t, 1, replayQueue[0].Position)
+			assert.Equal(t, dtos.SessionKindReplay, replayQueue[0].Kind)
+			assert.Equal(t, "first", replayQueue[0].Label)
+			assert.NotZero(t, replayQueue[0].QueuedAt)
+
+			recordQueue := target.RecordingStatus().Queue
+			require.Len(t, recordQueue, 1)
+			assert.Equal(t, 2, recordQueue[0].Position)
+			assert.Equal(t, dtos.SessionKindRecord, recordQueue[0].Kind)
+			assert.Equal(t, "second", recordQueue[0].Label)
+		})
+	}
+}
+
+func TestDataManager_StartNextQueuedSession(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{MaxQueuedSessionsAppSetting: "3"})
+	mockSdk.On("NotificationClient").Return(nil)
+	// decodeEvent, countEvents, batch and processBatchedData
+	mockSdk.On("SetDefaultFunctionsPipeline", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+	now := time.Now()
+	target.recordingStartedAt = &now
+
+	// The replay fails to start since there is no recorded data, so the recording queued after it is started
+	require.NoError(t, target.StartReplay(dtos.ReplayRequest{ReplayRate: 1}))
+	require.NoError(t, target.StartRecording(dtos.RecordRequest{EventLimit: 10, Label: "queued"}))
+	require.NoError(t, target.StartRecording(dtos.RecordRequest{EventLimit: 20}))
+
+	// Nothing is started while a session is running
+	target.startNextQueuedSession()
+	require.Len(t, target.sessionQueue, 3)
+
+	target.recordingStartedAt = nil
+	target.startNextQueuedSession()
+
+	status := target.RecordingStatus()
+	assert.True(t, status.InProgress)
+	assert.Equal(t, "queued", status.Label)
+	require.Len(t, status.Queue, 1)
+	assert.Equal(t, 1, status.Queue[0].Position)
+}
+
+func TestDataManager_SessionCanRun(t *testing.T) {
+	shadowReplay := dtos.ReplayRequest{ReplayRate: 1, ShadowMode: true}
+
+	tests := []struct {
+		Name                  string
+		MaxConcurrentSessions string
+		RecordingRunning      bool
+		ReplayRunning         bool
+		ReplayUsesPipelines   bool
+		Kind                  string
+		Replay                dtos.ReplayRequest
+		Expected              bool
+		ExpectedError         bool
+	}{
+		{"Nothing running", "", false, false, false, dtos.SessionKindRecord, dtos.ReplayRequest{}, true, false},
+		{"Record during replay by default", "", false, true, false, dtos.SessionKindRecord, dtos.ReplayRequest{}, false, false},
+		{"Record during replay", "2", false, true, false, dtos.SessionKindRecord, dtos.ReplayRequest{}, true, false},
+		{"Record during replay using pipelines", "2", false, true, true, dtos.SessionKindRecord, dtos.ReplayRequest{}, false, false},
+		{"Replay during recording", "2", true, false, false, dtos.SessionKindReplay, dtos.ReplayRequest{ReplayRate: 1}, true, false},
+		{"Replay using pipelines during recording", "2", true, false, false, dtos.SessionKindReplay, shadowReplay, false, false},
+		{"Second recording", "3", true, false, false, dtos.SessionKindRecord, dtos.ReplayRequest{}, false, false},
+		{"Second replay", "3", false, true, false, dtos.SessionKindReplay, dtos.ReplayRequest{ReplayRate: 1}, false, false},
+		{"Invalid setting", "0", false, true, false, dtos.SessionKindRecord, dtos.ReplayRequest{}, false, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("ApplicationSettings").Return(map[string]string{MaxConcurrentSessionsAppSetting: test.MaxConcurrentSessions})
+
+			target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+			now := time.Now()
+			if test.RecordingRunning {
+				target.recordingStartedAt = &now
+			}
+			if test.ReplayRunning {
+				target.replayStartedAt = &now
+				target.replayUsesPipelines = test.ReplayUsesPipelines
+			}
+
+			actual, err := target.sessionCanRun(test.Kind, test.Replay)
+			if test.ExpectedError {
+				require.ErrorContains(t, err, MaxConcurrentSessionsAppSetting)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestDataManager_StartRecording_DuringReplay(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{MaxConcurrentSessionsAppSetting: "2"})
+	// decodeEvent, countEvents, batch and processBatchedData
+	mockSdk.On("SetDefaultFunctionsPipeline", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+	replayed := &recordedData{Name: "replayed"}
+	target.recordedData = replayed
+	now := time.Now()
+	target.replayStartedAt = &now
+	target.replayData = replayed
+
+	require.NoError(t, target.StartRecording(dtos.RecordRequest{EventLimit: 10}))
+	assert.True(t, target.RecordingStatus().InProgress)
+
+	// The replay keeps the recorded data it replays, which the recording replaces
+	assert.Nil(t, target.recordedData)
+	assert.Same(t, replayed, target.replayData)
+}
@@ -0,0 +1,121 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+var (
+	noReplayRunningToSeekError = errors.New("no replay currently running")
+	seekUnsupportedError       = errors.New("seeking isn't supported for streamed, opaque, time warped, daily or telemetry replays")
+	invalidSeekRequestError    = errors.New("Offset must be greater than or equal 0 and can't be set along with Annotation")
+)
+
+// replaySeek is the pending jump of a replay session, which the replay goroutine takes before publishing its next
+// Event. Only replays paced from Event to Event can seek, since the others time the Events against fixed windows.
+type replaySeek struct {
+	useEnvelopeTiming bool
+	index             int
+	pending           bool
+}
+
+// newReplaySeek returns the seek state for the replay, or nil if the replay can't seek
+func newReplaySeek(request dtos.ReplayRequest) *replaySeek {
+	if request.TimeWarpDuration > 0 || request.AlignTimeOfDay || request.Telemetry {
+		return nil
+	}
+
+	return &replaySeek{useEnvelopeTiming: request.UseEnvelopeTiming}
+}
+
+// SeekReplay jumps the active replay session to the Event at the offset or annotation in the request, where an offset
+// of 0 jumps back to the first Event. The replay continues from that Event, published immediately, with the repeats
+// starting from the start as before. An error is returned if no replay is running, the replay can't seek or there is
+// no Event at the point.
+func (m *dataManager) SeekReplay(request dtos.ReplaySeekRequest) (*dtos.ReplaySeekResponse, error) {
+	if request.Offset < 0 || (len(request.Annotation) > 0 && request.Offset != 0) {
+		return nil, invalidSeekRequestError
+	}
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.replayStartedAt == nil {
+		return nil, noReplayRunningToSeekError
+	}
+
+	seek := m.replaySeek
+	if seek == nil {
+		return nil, seekUnsupportedError
+	}
+
+	data := m.replayData
+	firstEventTime := recordedEventTime(data, 0, seek.useEnvelopeTiming)
+
+	var index int
+	if len(request.Annotation) > 0 {
+		var err error
+		if index, err = annotationEventIndex(data, request.Annotation, seek.useEnvelopeTiming); err != nil {
+			return nil, err
+		}
+	} else {
+		var ok bool
+		if index, ok = eventIndexAt(data, firstEventTime+int64(request.Offset), seek.useEnvelopeTiming); !ok {
+			return nil, fmt.Errorf("no recorded events at or after offset %s", request.Offset)
+		}
+	}
+
+	seek.index = index
+	seek.pending = true
+
+	response := &dtos.ReplaySeekResponse{
+		EventIndex: index,
+		Offset:     time.Duration(recordedEventTime(data, index, seek.useEnvelopeTiming) - firstEventTime),
+	}
+
+	m.sessionLogger(m.replayLabel, m.replayCorrelationID).Debugf("ARR Seek Replay: Replay jumping to event %d at offset %s",
+		response.EventIndex, response.Offset)
+
+	return response, nil
+}
+
+// takeReplaySeek returns the index of the Event the replay jumps to, or false if no jump is pending
+func (m *dataManager) takeReplaySeek() (int, bool) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	seek := m.replaySeek
+	if seek == nil || !seek.pending {
+		return 0, false
+	}
+
+	seek.pending = false
+	return seek.index, true
+}
+
+// replaySeekPending checks if the replay is to jump before publishing its next Event
+func (m *dataManager) replaySeekPending() bool {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	return m.replaySeek != nil && m.replaySeek.pending
+}
@@ -0,0 +1,172 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	interfaceMocks "github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_SeekReplay(t *testing.T) {
+	events := []coreDtos.Event{newAppendEvent("D1", 10*time.Second), newAppendEvent("D1", 20*time.Second),
+		newAppendEvent("D1", 30*time.Second)}
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, &interfaceMocks.Clock{}, nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events:      newEventStore(events),
+		Envelopes:   map[string]dtos.EnvelopeMetadata{events[0].Id: {ReceivedAt: int64(5 * time.Second)}},
+		Annotations: []dtos.Annotation{{Label: "valve-opened", Timestamp: int64(25 * time.Second)}},
+	}
+
+	_, err := target.SeekReplay(dtos.ReplaySeekRequest{Offset: time.Second})
+	require.Equal(t, noReplayRunningToSeekError, err)
+
+	now := time.Now()
+	target.replayStartedAt = &now
+	target.replayData = target.recordedData
+
+	_, err = target.SeekReplay(dtos.ReplaySeekRequest{Offset: time.Second})
+	require.Equal(t, seekUnsupportedError, err)
+
+	target.replaySeek = newReplaySeek(dtos.ReplayRequest{})
+
+	tests := []struct {
+		Name             string
+		Request          dtos.ReplaySeekRequest
+		ExpectedResponse *dtos.ReplaySeekResponse
+		ExpectedError    error
+	}{
+		{"Start", dtos.ReplaySeekRequest{}, &dtos.ReplaySeekResponse{EventIndex: 0}, nil},
+		{"Offset between Events", dtos.ReplaySeekRequest{Offset: 15 * time.Second}, &dtos.ReplaySeekResponse{EventIndex: 2, Offset: 20 * time.Second}, nil},
+		{"Annotation", dtos.ReplaySeekRequest{Annotation: "valve-opened"}, &dtos.ReplaySeekResponse{EventIndex: 2, Offset: 20 * time.Second}, nil},
+		{"Negative offset", dtos.ReplaySeekRequest{Offset: -time.Second}, nil, invalidSeekRequestError},
+		{"Offset and annotation", dtos.ReplaySeekRequest{Offset: time.Second, Annotation: "valve-opened"}, nil, invalidSeekRequestError},
+		{"Offset past the end", dtos.ReplaySeekRequest{Offset: time.Minute}, nil, nil},
+		{"Unknown annotation", dtos.ReplaySeekRequest{Annotation: "missing"}, nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target.replaySeek.pending = false
+
+			response, err := target.SeekReplay(test.Request)
+			if test.ExpectedResponse == nil {
+				require.Error(t, err)
+				if test.ExpectedError != nil {
+					require.Equal(t, test.ExpectedError, err)
+				}
+				_, ok := target.takeReplaySeek()
+				assert.False(t, ok)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedResponse, response)
+
+			index, ok := target.takeReplaySeek()
+			require.True(t, ok)
+			assert.Equal(t, test.ExpectedResponse.EventIndex, index)
+
+			_, ok = target.takeReplaySeek()
+			assert.False(t, ok)
+		})
+	}
+
+	// Offsets are from the first Event's envelope when the replay uses envelope timing
+	target.replaySeek = newReplaySeek(dtos.ReplayRequest{UseEnvelopeTiming: true})
+	response, err := target.SeekReplay(dtos.ReplaySeekRequest{Offset: 10 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, &dtos.ReplaySeekResponse{EventIndex: 1, Offset: 15 * time.Second}, response)
+}
+
+func TestNewReplaySeek(t *testing.T) {
+	assert.NotNil(t, newReplaySeek(dtos.ReplayRequest{ReplayRate: 1}))
+	assert.Nil(t, newReplaySeek(dtos.ReplayRequest{TimeWarpDuration: time.Hour}))
+	assert.Nil(t, newReplaySeek(dtos.ReplayRequest{AlignTimeOfDay: true}))
+	assert.Nil(t, newReplaySeek(dtos.ReplayRequest{ReplayRate: 1, Telemetry: true}))
+}
+
+func TestDataManager_StartReplay_Seek(t *testing.T) {
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+	var publishedMutex sync.Mutex
+	var published []string
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).Return(nil).
+		Run(func(args mock.Arguments) {
+			publishedMutex.Lock()
+			defer publishedMutex.Unlock()
+			published = append(published, args.Get(1).(requests.AddEventRequest).Event.DeviceName)
+		})
+
+	mockClock := &interfaceMocks.Clock{}
+	mockClock.On("Now").Return(time.Now())
+	mockClock.On("Since", mock.Anything).Return(time.Second).Maybe()
+
+	target := NewManager(mockSdk, time.Minute, mockClock, nil, nil).(*dataManager)
+
+	// The first wait, before the second Event, seeks past the third Event to the fourth
+	var seekErr error
+	mockClock.On("Sleep", mock.Anything).Run(func(args mock.Arguments) {
+		if seekErr == nil && len(published) == 1 {
+			_, seekErr = target.SeekReplay(dtos.ReplaySeekRequest{Offset: 3 * time.Second})
+		}
+	})
+
+	target.recordedData = &recordedData{Events: newEventStore([]coreDtos.Event{
+		newAppendEvent("D1", 0), newAppendEvent("D2", time.Second), newAppendEvent("D3", 2*time.Second),
+		newAppendEvent("D4", 3*time.Second),
+	})}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, seekErr)
+	assert.Empty(t, target.ReplayStatus().Message)
+
+	publishedMutex.Lock()
+	defer publishedMutex.Unlock()
+	assert.Equal(t, []string{"D1", "D2", "D4"}, published)
+}
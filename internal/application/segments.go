@@ -0,0 +1,277 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+)
+
+// SegmentStoreDirAppSetting is the directory continuous recordings rotate their completed segments into, each
+// recording in its own sub directory. Continuous recordings can't be started when not set.
+const SegmentStoreDirAppSetting = "SegmentStoreDir"
+
+const (
+	// segmentTimeLayout names the segment files by the time the segment started, so they sort in time order
+	segmentTimeLayout    = "20060102T150405.000000000Z"
+	segmentFileExtension = ".json"
+	// segmentTempFileExtension is added to the segment file name while the segment is being written
+	segmentTempFileExtension = ".tmp"
+)
+
+var segmentStoreNotConfiguredError = fmt.Errorf("Rotation can't be used since the %s App Setting isn't set", SegmentStoreDirAppSetting)
+var invalidSegmentRotation = errors.New("invalid Rotation, MaxSegments, MaxAge and MaxBytes must be greater than or equal 0")
+
+// segmentRotation rotates the completed segments of a continuous recording into the recording's directory in the
+// segment store, in the exported recorded data format so each segment can be imported on its own.
+type segmentRotation struct {
+	dir              string
+	retention        dtos.SegmentRotation
+	segmentStartedAt time.Time
+	segmentCount     int
+}
+
+// isStoreEntryName returns true if the name is a single file or directory name, so it can't be used to read or write
+// outside the segment store
+func isStoreEntryName(name string) bool {
+	return len(name) > 0 && !strings.ContainsAny(name, `/\`) && strings.Trim(name, ".") != ""
+}
+
+// segmentPaths returns the paths of the segments in the recording's directory, sorted by name, which is also the
+// order the segments started. The directory is read as is rather than globbed, so names holding glob metacharacters
+// only match their own directory. No paths are returned if the directory doesn't exist.
+func segmentPaths(recordingDir string) ([]string, error) {
+	entries, err := os.ReadDir(recordingDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), segmentFileExtension) {
+			paths = append(paths, filepath.Join(recordingDir, entry.Name()))
+		}
+	}
+
+	return paths, nil
+}
+
+// getSegmentStoreDir returns the configured segment store directory, validating the request's retention limits.
+// An error is returned if the request rotates segments and the segment store isn't configured.
+func (m *dataManager) getSegmentStoreDir(request dtos.RecordRequest) (string, error) {
+	if request.Rotation == nil {
+		return "", nil
+	}
+
+	if request.Rotation.MaxSegments < 0 || request.Rotation.MaxAge < 0 || request.Rotation.MaxBytes < 0 {
+		return "", invalidSegmentRotation
+	}
+
+	dir := m.appSvc.ApplicationSettings()[SegmentStoreDirAppSetting]
+	if len(dir) == 0 {
+		return "", segmentStoreNotConfiguredError
+	}
+
+	return dir, nil
+}
+
+// newSegmentRotation creates the recording's directory in the segment store. Recordings without a name are stored
+// under the time they started.
+func newSegmentRotation(storeDir string, retention dtos.SegmentRotation, name string, startedAt time.Time) (*segmentRotation, error) {
+	dirName := strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if len(dirName) == 0 || strings.Trim(dirName, ".") == "" {
+		dirName = startedAt.UTC().Format(segmentTimeLayout)
+	}
+
+	dir := filepath.Join(storeDir, dirName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create segment store directory %s: %v", dir, err)
+	}
+
+	return &segmentRotation{
+		dir:              dir,
+		retention:        retention,
+		segmentStartedAt: startedAt,
+	}, nil
+}
+
+// rotate writes the completed segment to the segment store, applies the retention limits and starts the next
+// segment. The segment is written to a temporary file first, so partially written segments are never seen.
+func (r *segmentRotation) rotate(segment *dtos.RecordedData, now time.Time, lc logger.LoggingClient) error {
+	data, err := json.Marshal(segment)
+	if err != nil {
+		return fmt.Errorf("failed to marshal segment: %v", err)
+	}
+
+	path := filepath.Join(r.dir, r.segmentStartedAt.UTC().Format(segmentTimeLayout)+segmentFileExtension)
+	tempPath := path + segmentTempFileExtension
+	if err := os.WriteFile(tempPath, data, 0640); err != nil {
+		return fmt.Errorf("failed to write segment %s: %v", path, err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to write segment %s: %v", path, err)
+	}
+
+	r.segmentCount++
+	r.segmentStartedAt = now
+
+	lc.Debugf("ARR Segment Rotation: segment %s of %d bytes rotated into the segment store", path, len(data))
+
+	r.applyRetention(now, lc)
+
+	return nil
+}
+
+// applyRetention deletes the oldest segments in the recording's directory while any of the retention limits is
+// exceeded. The newest segment is always kept.
+func (r *segmentRotation) applyRetention(now time.Time, lc logger.LoggingClient) {
+	if r.retention.MaxSegments == 0 && r.retention.MaxAge == 0 && r.retention.MaxBytes == 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		lc.Errorf("ARR Segment Rotation: unable to apply retention limits: %v", err)
+		return
+	}
+
+	type segmentFile struct {
+		name      string
+		startedAt time.Time
+		size      int64
+	}
+
+	// The entries are sorted by name, which is also the order the segments started
+	var segments []segmentFile
+	var totalSize int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentFileExtension) {
+			continue
+		}
+
+		startedAt, err := time.Parse(segmentTimeLayout, strings.TrimSuffix(name, segmentFileExtension))
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		segments = append(segments, segmentFile{name: name, startedAt: startedAt, size: info.Size()})
+		totalSize += info.Size()
+	}
+
+	remaining := len(segments)
+	for _, segment := range segments[:max(len(segments)-1, 0)] {
+		exceeded := (r.retention.MaxSegments > 0 && remaining > r.retention.MaxSegments) ||
+			(r.retention.MaxAge > 0 && now.Sub(segment.startedAt) > r.retention.MaxAge) ||
+			(r.retention.MaxBytes > 0 && totalSize > r.retention.MaxBytes)
+		if !exceeded {
+			break
+		}
+
+		if err := os.Remove(filepath.Join(r.dir, segment.name)); err != nil {
+			lc.Errorf("ARR Segment Rotation: unable to delete segment %s: %v", segment.name, err)
+			break
+		}
+
+		remaining--
+		totalSize -= segment.size
+		lc.Debugf("ARR Segment Rotation: segment %s deleted by the retention limits", segment.name)
+	}
+}
+
+// rotateSegment rotates the completed segment into the segment store and keeps it as the recorded data, so the
+// latest segment can be exported or replayed once the recording is canceled. A failed rotation is logged and the
+// recording continues with the next segment.
+// Must be called while holding the recording mutex.
+func (m *dataManager) rotateSegment(data *recordedData, lc logger.LoggingClient) {
+	now := m.clock.Now()
+	startedAt := m.segmentRotation.segmentStartedAt
+	data.Duration = now.Sub(startedAt)
+	data.Metadata = segmentMetadata(m.recordingMetadata, m.recordingGaps(), startedAt)
+
+	if m.metadataSnapshot != nil {
+		data.Devices, data.Profiles = m.metadataSnapshot.complete()
+	}
+
+	segment := &dtos.RecordedData{
+		Name:         data.Name,
+		Envelopes:    data.Envelopes,
+		Messages:     data.Messages,
+		DeadLetters:  data.DeadLetters,
+		Telemetry:    data.Telemetry,
+		SystemEvents: data.SystemEvents,
+		Annotations:  data.Annotations,
+		Metadata:     data.Metadata,
+		Devices:      utils.MapToSlice(data.Devices),
+		Profiles:     utils.MapToSlice(data.Profiles),
+	}
+	if data.Events != nil {
+		segment.RecordedEvents = data.Events.events()
+		// Segments of readings-only recordings are stored as their reading tuples
+		if compact, ok := utils.CompactEvents(segment.RecordedEvents); ok && m.readingsOnly {
+			segment.RecordedEvents = nil
+			segment.ReadingsOnlyEvents = compact
+		}
+	}
+
+	if err := m.segmentRotation.rotate(segment, now, lc); err != nil {
+		lc.Errorf("ARR Segment Rotation: %v", err)
+		// The next segment starts now regardless, so the failed segment's time isn't attributed to it
+		m.segmentRotation.segmentStartedAt = now
+	}
+
+	m.recordedData = data
+}
+
+// segmentMetadata returns the recording metadata for a segment, with the segment's start time and the gaps which
+// overlap the segment
+func segmentMetadata(metadata *dtos.RecordingMetadata, gaps []dtos.RecordingGap, startedAt time.Time) *dtos.RecordingMetadata {
+	if metadata == nil {
+		return nil
+	}
+
+	var segmentGaps []dtos.RecordingGap
+	for _, gap := range gaps {
+		if gap.End == 0 || gap.End >= startedAt.UnixNano() {
+			segmentGaps = append(segmentGaps, gap)
+		}
+	}
+
+	segment := *metadata
+	segment.StartedAt = startedAt.UnixNano()
+	segment.Gaps = segmentGaps
+	return &segment
+}
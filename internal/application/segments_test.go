@@ -0,0 +1,226 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_GetSegmentStoreDir(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Rotation      *dtos.SegmentRotation
+		StoreDir      string
+		ExpectedDir   string
+		ExpectedError error
+	}{
+		{"Not continuous", nil, "", "", nil},
+		{"Continuous", &dtos.SegmentRotation{MaxSegments: 10}, "/data/segments", "/data/segments", nil},
+		{"Store not configured", &dtos.SegmentRotation{}, "", "", segmentStoreNotConfiguredError},
+		{"Negative MaxSegments", &dtos.SegmentRotation{MaxSegments: -1}, "/data/segments", "", invalidSegmentRotation},
+		{"Negative MaxAge", &dtos.SegmentRotation{MaxAge: -time.Hour}, "/data/segments", "", invalidSegmentRotation},
+		{"Negative MaxBytes", &dtos.SegmentRotation{MaxBytes: -1}, "/data/segments", "", invalidSegmentRotation},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{SegmentStoreDirAppSetting: test.StoreDir})
+
+			target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+			dir, err := target.getSegmentStoreDir(dtos.RecordRequest{Duration: time.Minute, Rotation: test.Rotation})
+			require.Equal(t, test.ExpectedError, err)
+			assert.Equal(t, test.ExpectedDir, dir)
+		})
+	}
+}
+
+func TestNewSegmentRotation_Dir(t *testing.T) {
+	startedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name        string
+		Recording   string
+		ExpectedDir string
+	}{
+		{"Named", "line-1", "line-1"},
+		{"Path separators", "plant/line-1", "plant_line-1"},
+		{"Unnamed", "", "20240301T120000.000000000Z"},
+		{"Parent dir", "..", "20240301T120000.000000000Z"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			storeDir := t.TempDir()
+
+			rotation, err := newSegmentRotation(storeDir, dtos.SegmentRotation{}, test.Recording, startedAt)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(storeDir, test.ExpectedDir), rotation.dir)
+			assert.DirExists(t, rotation.dir)
+		})
+	}
+}
+
+func TestSegmentRotation_ApplyRetention(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name             string
+		Retention        dtos.SegmentRotation
+		ExpectedSegments int
+	}{
+		{"Unlimited", dtos.SegmentRotation{}, 5},
+		{"Max segments", dtos.SegmentRotation{MaxSegments: 3}, 3},
+		{"Max age", dtos.SegmentRotation{MaxAge: 150 * time.Minute}, 2},
+		{"Max bytes", dtos.SegmentRotation{MaxBytes: 25}, 2},
+		{"Newest always kept", dtos.SegmentRotation{MaxBytes: 1}, 1},
+		{"Tightest limit applies", dtos.SegmentRotation{MaxSegments: 4, MaxAge: 10 * time.Hour, MaxBytes: 10}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			rotation, err := newSegmentRotation(t.TempDir(), test.Retention, "retention", start)
+			require.NoError(t, err)
+
+			// Five hourly segments of 10 bytes each, plus files which aren't segments and are left alone
+			var names []string
+			for i := 0; i < 5; i++ {
+				name := start.Add(time.Duration(i)*time.Hour).Format(segmentTimeLayout) + segmentFileExtension
+				names = append(names, name)
+				require.NoError(t, os.WriteFile(filepath.Join(rotation.dir, name), []byte("0123456789"), 0640))
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(rotation.dir, "notes.txt"), []byte("0123456789"), 0640))
+
+			rotation.applyRetention(start.Add(5*time.Hour), logger.NewMockClient())
+
+			entries, err := os.ReadDir(rotation.dir)
+			require.NoError(t, err)
+			var remaining []string
+			for _, entry := range entries {
+				remaining = append(remaining, entry.Name())
+			}
+
+			expected := append(append([]string(nil), names[len(names)-test.ExpectedSegments:]...), "notes.txt")
+			assert.Equal(t, expected, remaining)
+		})
+	}
+}
+
+func TestDataManager_ProcessBatchedData_Rotation(t *testing.T) {
+	virtualClock := clock.NewVirtual(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	rotation, err := newSegmentRotation(t.TempDir(), dtos.SegmentRotation{MaxSegments: 2}, "continuous", virtualClock.Now())
+	require.NoError(t, err)
+
+	target := NewManager(mockSdk, 0, virtualClock, nil, nil).(*dataManager)
+	now := virtualClock.Now()
+	target.recordingStartedAt = &now
+	target.recordingName = "continuous"
+	target.recordingMetadata = &dtos.RecordingMetadata{Hostname: "edge-node-1", StartedAt: now.UnixNano()}
+	target.recordedEnvelopes = make(map[string]dtos.EnvelopeMetadata)
+	target.segmentRotation = rotation
+
+	for segment := 1; segment <= 3; segment++ {
+		events := []coreDtos.Event{
+			{Id: "event-1", DeviceName: "device-1", Origin: int64(segment)},
+			{Id: "event-2", DeviceName: "device-1", Origin: int64(segment)},
+		}
+		target.recordedEnvelopes["event-1"] = dtos.EnvelopeMetadata{ReceivedTopic: "events/device-1"}
+		// Captured after the batch completed, so it belongs to the next segment
+		target.recordedEnvelopes["event-3"] = dtos.EnvelopeMetadata{ReceivedTopic: "events/device-1"}
+
+		virtualClock.Advance(time.Minute)
+		continuePipeline, result := target.processBatchedData(nil, events)
+		require.False(t, continuePipeline)
+		require.Nil(t, result)
+	}
+
+	// The recording continues and the pipeline is kept, which the mock would fail on if removed
+	require.NotNil(t, target.recordingStartedAt)
+	assert.Equal(t, 3, target.RecordingStatus().SegmentCount)
+	assert.Contains(t, target.recordedEnvelopes, "event-3")
+	assert.NotContains(t, target.recordedEnvelopes, "event-1")
+
+	// The latest segment is kept as the recorded data
+	require.NotNil(t, target.recordedData)
+	assert.Equal(t, 2, target.recordedData.Events.len())
+	assert.Equal(t, time.Minute, target.recordedData.Duration)
+
+	// Only the newest two segments are retained
+	entries, err := os.ReadDir(rotation.dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	lastStartedAt := virtualClock.Now().Add(-time.Minute)
+	assert.Equal(t, lastStartedAt.Format(segmentTimeLayout)+segmentFileExtension, entries[1].Name())
+
+	data, err := os.ReadFile(filepath.Join(rotation.dir, entries[1].Name()))
+	require.NoError(t, err)
+	segment := dtos.RecordedData{}
+	require.NoError(t, json.Unmarshal(data, &segment))
+	assert.Equal(t, "continuous", segment.Name)
+	require.Len(t, segment.RecordedEvents, 2)
+	assert.Equal(t, int64(3), segment.RecordedEvents[0].Origin)
+	assert.Contains(t, segment.Envelopes, "event-1")
+	require.NotNil(t, segment.Metadata)
+	assert.Equal(t, lastStartedAt.UnixNano(), segment.Metadata.StartedAt)
+	assert.Equal(t, "edge-node-1", segment.Metadata.Hostname)
+
+	mockSdk.AssertExpectations(t)
+}
+
+func TestDataManager_ProcessBatchedMessages_Rotation(t *testing.T) {
+	virtualClock := clock.NewVirtual(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	rotation, err := newSegmentRotation(t.TempDir(), dtos.SegmentRotation{}, "opaque", virtualClock.Now())
+	require.NoError(t, err)
+
+	target := NewManager(mockSdk, 0, virtualClock, nil, nil).(*dataManager)
+	now := virtualClock.Now()
+	target.recordingStartedAt = &now
+	target.segmentRotation = rotation
+	target.recordedMessages = []dtos.OpaqueMessage{
+		{Payload: []byte("one")},
+		{Payload: []byte("two")},
+		{Payload: []byte("three")},
+	}
+
+	virtualClock.Advance(time.Minute)
+	_, _ = target.processBatchedMessages(nil, [][]byte{[]byte("one"), []byte("two")})
+
+	require.NotNil(t, target.recordingStartedAt)
+	require.Len(t, target.recordedData.Messages, 2)
+	require.Len(t, target.recordedMessages, 1)
+	assert.Equal(t, []byte("three"), target.recordedMessages[0].Payload)
+	assert.FileExists(t, filepath.Join(rotation.dir, now.Format(segmentTimeLayout)+segmentFileExtension))
+}
@@ -0,0 +1,353 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientInterfaces "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+)
+
+const (
+	defaultSelfTestEventCount = 100
+	maxSelfTestEventCount     = 10000
+	defaultSelfTestInterval   = 10 * time.Millisecond
+	// selfTestTimeoutSlack is added to the expected replay time before a self-test is abandoned
+	selfTestTimeoutSlack  = 10 * time.Second
+	selfTestPollInterval  = 10 * time.Millisecond
+	maxSelfTestMismatches = 10
+	selfTestServiceName   = "arr-selftest-service"
+	selfTestProfileName   = "arr-selftest-profile"
+	selfTestDeviceName    = "arr-selftest-device"
+	selfTestSourceName    = "arr-selftest-source"
+	selfTestResourceName  = "arr-selftest-resource"
+)
+
+var invalidSelfTestEventCount = fmt.Errorf("self-test EventCount must be between 0 and %d", maxSelfTestEventCount)
+var invalidSelfTestInterval = errors.New("self-test Interval and TimingTolerance must be equal or greater than 0")
+var selfTestPipelineNotSetError = errors.New("self-test recording didn't set its functions pipeline")
+
+// SelfTest generates synthetic Events, records and replays them through an internal loopback pipeline and reports
+// whether they made the round trip unchanged, as a field diagnostic for deployments. The loopback uses its own
+// session state, so it neither touches the MessageBus nor disturbs the recorded data or a running session. An error
+// is returned if the request is invalid or the loopback sessions can't be started.
+func (m *dataManager) SelfTest(request dtos.SelfTestRequest) (*dtos.SelfTestReport, error) {
+	if request.EventCount < 0 || request.EventCount > maxSelfTestEventCount {
+		return nil, invalidSelfTestEventCount
+	}
+
+	if request.Interval < 0 || request.TimingTolerance < 0 {
+		return nil, invalidSelfTestInterval
+	}
+
+	if request.EventCount == 0 {
+		request.EventCount = defaultSelfTestEventCount
+	}
+
+	if request.Interval == 0 {
+		request.Interval = defaultSelfTestInterval
+	}
+
+	lc := m.appSvc.LoggingClient()
+	startedAt := time.Now()
+	events := generateSelfTestEvents(request.EventCount, request.Interval, startedAt)
+
+	// The loopback runs in real time, even when the service runs on a virtual clock
+	loopback := newSelfTestService(m.appSvc)
+	target := NewManager(loopback, m.maxReplayDelay, clock.New(), nil, nil).(*dataManager)
+
+	report := &dtos.SelfTestReport{EventCount: request.EventCount}
+
+	if err := target.StartRecording(dtos.RecordRequest{EventLimit: request.EventCount}); err != nil {
+		return nil, fmt.Errorf("failed to start self-test recording: %w", err)
+	}
+
+	if err := loopback.receive(events); err != nil {
+		_ = target.CancelRecording()
+		return nil, err
+	}
+
+	target.recordingMutex.Lock()
+	if target.recordedData != nil {
+		report.RecordedEventCount = target.recordedData.Events.len()
+	}
+	target.recordingMutex.Unlock()
+
+	if report.RecordedEventCount == 0 {
+		_ = target.CancelRecording()
+		report.Message = "no Events were recorded"
+		report.Duration = time.Since(startedAt)
+		lc.Errorf("ARR Self-Test: %s", report.Message)
+		return report, nil
+	}
+
+	if err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1}); err != nil {
+		return nil, fmt.Errorf("failed to start self-test replay: %w", err)
+	}
+
+	timeout := time.Duration(request.EventCount)*request.Interval + selfTestTimeoutSlack
+	status := target.ReplayStatus()
+	for deadline := time.Now().Add(timeout); status.Running && time.Now().Before(deadline); status = target.ReplayStatus() {
+		time.Sleep(selfTestPollInterval)
+	}
+
+	if status.Running {
+		_ = target.CancelReplay()
+		report.Message = fmt.Sprintf("replay didn't complete within %s", timeout)
+	} else if len(status.Message) > 0 {
+		report.Message = status.Message
+	}
+
+	published := loopback.publishedEvents()
+	report.ReplayedEventCount = len(published)
+	report.Mismatches, report.MaxTimingError = compareSelfTestEvents(events, published, request.TimingTolerance)
+	report.Passed = len(report.Message) == 0 && len(report.Mismatches) == 0 &&
+		report.RecordedEventCount == report.EventCount && report.ReplayedEventCount == report.EventCount
+	report.Duration = time.Since(startedAt)
+
+	lc.Infof("ARR Self-Test: passed=%v, %d generated, %d recorded and %d replayed Events in %s, max timing error %s",
+		report.Passed, report.EventCount, report.RecordedEventCount, report.ReplayedEventCount, report.Duration,
+		report.MaxTimingError)
+
+	return report, nil
+}
+
+// generateSelfTestEvents returns the synthetic Events for a self-test, each with a single Reading whose value is
+// the Event's index, with Origins spaced by the interval
+func generateSelfTestEvents(count int, interval time.Duration, start time.Time) []coreDtos.Event {
+	events := make([]coreDtos.Event, count)
+	for index := range events {
+		event := coreDtos.NewEvent(selfTestProfileName, selfTestDeviceName, selfTestSourceName)
+		event.Origin = start.Add(time.Duration(index) * interval).UnixNano()
+		event.AddSimpleReading(selfTestResourceName, common.ValueTypeInt64, int64(index))
+		event.Readings[0].Origin = event.Origin
+		events[index] = event
+	}
+
+	return events
+}
+
+// compareSelfTestEvents returns the differences between the generated and replayed Events, up to
+// maxSelfTestMismatches, along with the largest timing error of the replayed Events
+func compareSelfTestEvents(generated []coreDtos.Event, published []publishedSelfTestEvent,
+	tolerance time.Duration) ([]string, time.Duration) {
+	var mismatches []string
+	addMismatch := func(format string, args ...any) {
+		if len(mismatches) < maxSelfTestMismatches {
+			mismatches = append(mismatches, fmt.Sprintf(format, args...))
+		}
+	}
+
+	if len(published) != len(generated) {
+		addMismatch("%d Events replayed, expected %d", len(published), len(generated))
+	}
+
+	var maxTimingError time.Duration
+	for index := 0; index < len(published) && index < len(generated); index++ {
+		expected := generated[index]
+		actual := published[index].event
+
+		if actual.DeviceName != expected.DeviceName || actual.ProfileName != expected.ProfileName ||
+			actual.SourceName != expected.SourceName {
+			addMismatch("Event %d: replayed for %s/%s/%s, expected %s/%s/%s", index, actual.ProfileName,
+				actual.DeviceName, actual.SourceName, expected.ProfileName, expected.DeviceName, expected.SourceName)
+			continue
+		}
+
+		if len(actual.Readings) != len(expected.Readings) {
+			addMismatch("Event %d: replayed with %d Readings, expected %d", index, len(actual.Readings),
+				len(expected.Readings))
+			continue
+		}
+
+		for readingIndex, reading := range actual.Readings {
+			expectedReading := expected.Readings[readingIndex]
+			if reading.ResourceName != expectedReading.ResourceName || reading.ValueType != expectedReading.ValueType ||
+				reading.Value != expectedReading.Value {
+				addMismatch("Event %d: replayed Reading %s=%s (%s), expected %s=%s (%s)", index, reading.ResourceName,
+					reading.Value, reading.ValueType, expectedReading.ResourceName, expectedReading.Value,
+					expectedReading.ValueType)
+			}
+		}
+
+		scheduled := time.Duration(expected.Origin - generated[0].Origin)
+		timingError := published[index].publishedAt.Sub(published[0].publishedAt) - scheduled
+		if timingError < 0 {
+			timingError = -timingError
+		}
+
+		if timingError > maxTimingError {
+			maxTimingError = timingError
+		}
+
+		if tolerance > 0 && timingError > tolerance {
+			addMismatch("Event %d: replayed %s from its scheduled time, tolerance is %s", index, timingError, tolerance)
+		}
+	}
+
+	return mismatches, maxTimingError
+}
+
+// publishedSelfTestEvent is an Event published by the loopback replay along with when it was published
+type publishedSelfTestEvent struct {
+	event       coreDtos.Event
+	publishedAt time.Time
+}
+
+// selfTestService wraps the application service so a self-test's sessions run over an internal loopback. The
+// recording's functions pipeline is captured rather than set, so only the synthetic Events reach it, and the
+// replayed Events are captured rather than published. The App Settings are hidden so every optional feature keeps
+// its default, and the synthetic device is served in place of Core Metadata.
+type selfTestService struct {
+	appInterfaces.ApplicationService
+
+	mutex     sync.Mutex
+	pipeline  []appInterfaces.AppFunction
+	published []publishedSelfTestEvent
+}
+
+func newSelfTestService(service appInterfaces.ApplicationService) *selfTestService {
+	return &selfTestService{ApplicationService: service}
+}
+
+func (s *selfTestService) SetDefaultFunctionsPipeline(functions ...appInterfaces.AppFunction) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pipeline = functions
+	return nil
+}
+
+func (s *selfTestService) AddFunctionsPipelineForTopics(_ string, _ []string, _ ...appInterfaces.AppFunction) error {
+	return nil
+}
+
+func (s *selfTestService) RemoveAllFunctionPipelines() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pipeline = nil
+}
+
+func (s *selfTestService) PublishWithTopic(_ string, data any, _ string) error {
+	addEvent, ok := data.(requests.AddEventRequest)
+	if !ok {
+		return fmt.Errorf("self-test replay published %T rather than an AddEventRequest", data)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.published = append(s.published, publishedSelfTestEvent{event: addEvent.Event, publishedAt: time.Now()})
+	return nil
+}
+
+func (s *selfTestService) ApplicationSettings() map[string]string {
+	return map[string]string{}
+}
+
+func (s *selfTestService) NotificationClient() clientInterfaces.NotificationClient {
+	return nil
+}
+
+func (s *selfTestService) DeviceClient() clientInterfaces.DeviceClient {
+	return selfTestDeviceClient{}
+}
+
+// receive runs the synthetic Events through the captured recording pipeline as AddEventRequest payloads, as they
+// would be received from the MessageBus
+func (s *selfTestService) receive(events []coreDtos.Event) error {
+	s.mutex.Lock()
+	pipeline := s.pipeline
+	s.mutex.Unlock()
+
+	if len(pipeline) == 0 {
+		return selfTestPipelineNotSetError
+	}
+
+	for _, event := range events {
+		// The pipeline is removed once the recording completes
+		s.mutex.Lock()
+		pipeline = s.pipeline
+		s.mutex.Unlock()
+
+		if len(pipeline) == 0 {
+			return nil
+		}
+
+		payload, err := json.Marshal(requests.NewAddEventRequest(event))
+		if err != nil {
+			return fmt.Errorf("failed to marshal self-test Event: %w", err)
+		}
+
+		ctx := s.BuildContext(event.Id, common.ContentTypeJSON)
+		ctx.AddValue(appInterfaces.RECEIVEDTOPIC, buildEventTopic(selfTestServiceName, event))
+
+		var data any = payload
+		for _, function := range pipeline {
+			ok, result := function(ctx, data)
+			if !ok {
+				if err, isError := result.(error); isError {
+					return fmt.Errorf("self-test recording pipeline failed: %w", err)
+				}
+				break
+			}
+			data = result
+		}
+	}
+
+	return nil
+}
+
+// publishedEvents returns the Events published by the loopback replay in the order published
+func (s *selfTestService) publishedEvents() []publishedSelfTestEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]publishedSelfTestEvent(nil), s.published...)
+}
+
+// selfTestDeviceClient serves the synthetic device of a self-test in place of Core Metadata
+type selfTestDeviceClient struct {
+	clientInterfaces.DeviceClient
+}
+
+func (selfTestDeviceClient) DeviceByName(_ context.Context, name string) (responses.DeviceResponse, edgexErr.EdgeX) {
+	if name != selfTestDeviceName {
+		return responses.DeviceResponse{}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist,
+			fmt.Sprintf("device %s is not a self-test device", name), nil)
+	}
+
+	return responses.DeviceResponse{Device: coreDtos.Device{
+		Name:        selfTestDeviceName,
+		ProfileName: selfTestProfileName,
+		ServiceName: selfTestServiceName,
+	}}, nil
+}
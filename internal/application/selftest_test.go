@@ -0,0 +1,152 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonContext is a test context which reports the JSON content type the service receives Events with
+type jsonContext struct {
+	interfaces.AppFunctionContext
+}
+
+func (jsonContext) InputContentType() string {
+	return common.ContentTypeJSON
+}
+
+func TestDataManager_SelfTest(t *testing.T) {
+	lc := logger.NewMockClient()
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(lc)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("BuildContext", mock.Anything, common.ContentTypeJSON).
+		Return(func(correlationId string, _ string) interfaces.AppFunctionContext {
+			return jsonContext{pkg.NewAppFuncContextForTest(correlationId, lc)}
+		})
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	existing := &recordedData{Name: "existing", Events: newEventStore([]coreDtos.Event{coreDtos.NewEvent("P1", "D1", "S1")})}
+	target.recordedData = existing
+
+	report, err := target.SelfTest(dtos.SelfTestRequest{EventCount: 20, Interval: time.Millisecond})
+	require.NoError(t, err)
+	require.NotNil(t, report)
+
+	assert.True(t, report.Passed, report.Mismatches)
+	assert.Empty(t, report.Message)
+	assert.Empty(t, report.Mismatches)
+	assert.Equal(t, 20, report.EventCount)
+	assert.Equal(t, 20, report.RecordedEventCount)
+	assert.Equal(t, 20, report.ReplayedEventCount)
+	assert.Greater(t, report.Duration, time.Duration(0))
+
+	// The loopback leaves the service's MessageBus, pipelines and recorded data alone
+	mockSdk.AssertNotCalled(t, "PublishWithTopic", mock.Anything, mock.Anything, mock.Anything)
+	mockSdk.AssertNotCalled(t, "SetDefaultFunctionsPipeline", mock.Anything)
+	mockSdk.AssertNotCalled(t, "ApplicationSettings")
+	assert.Same(t, existing, target.recordedData)
+}
+
+func TestDataManager_SelfTest_InvalidRequest(t *testing.T) {
+	target := NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), nil, nil)
+
+	_, err := target.SelfTest(dtos.SelfTestRequest{EventCount: maxSelfTestEventCount + 1})
+	assert.Equal(t, invalidSelfTestEventCount, err)
+
+	_, err = target.SelfTest(dtos.SelfTestRequest{EventCount: -1})
+	assert.Equal(t, invalidSelfTestEventCount, err)
+
+	_, err = target.SelfTest(dtos.SelfTestRequest{Interval: -time.Second})
+	assert.Equal(t, invalidSelfTestInterval, err)
+
+	_, err = target.SelfTest(dtos.SelfTestRequest{TimingTolerance: -time.Second})
+	assert.Equal(t, invalidSelfTestInterval, err)
+}
+
+func TestCompareSelfTestEvents(t *testing.T) {
+	start := time.Now()
+	generated := generateSelfTestEvents(3, time.Second, start)
+
+	published := func(events []coreDtos.Event, offsets ...time.Duration) []publishedSelfTestEvent {
+		result := make([]publishedSelfTestEvent, len(events))
+		for index, event := range events {
+			result[index] = publishedSelfTestEvent{event: event, publishedAt: start.Add(offsets[index])}
+		}
+		return result
+	}
+
+	changed := generateSelfTestEvents(3, time.Second, start)
+	changed[1].Readings[0].Value = "9"
+	changed[2].DeviceName = "other"
+
+	tests := []struct {
+		Name               string
+		Published          []publishedSelfTestEvent
+		Tolerance          time.Duration
+		ExpectedMismatches []string
+		ExpectedTiming     time.Duration
+	}{
+		{"Identical", published(generated, 0, time.Second, 2*time.Second), 0, nil, 0},
+		{"Late, no tolerance", published(generated, 0, time.Second, 2500*time.Millisecond), 0, nil,
+			500 * time.Millisecond},
+		{"Late, within tolerance", published(generated, 0, 900*time.Millisecond, 2*time.Second), time.Second, nil,
+			100 * time.Millisecond},
+		{"Late, beyond tolerance", published(generated, 0, time.Second, 2500*time.Millisecond), 100 * time.Millisecond,
+			[]string{"Event 2: replayed 500ms from its scheduled time, tolerance is 100ms"}, 500 * time.Millisecond},
+		{"Missing Event", published(generated[:2], 0, time.Second), 0,
+			[]string{"2 Events replayed, expected 3"}, 0},
+		{"Changed Events", published(changed, 0, time.Second, 2*time.Second), 0,
+			[]string{
+				"Event 1: replayed Reading arr-selftest-resource=9 (Int64), expected arr-selftest-resource=1 (Int64)",
+				"Event 2: replayed for arr-selftest-profile/other/arr-selftest-source, expected arr-selftest-profile/arr-selftest-device/arr-selftest-source",
+			}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mismatches, timingError := compareSelfTestEvents(generated, test.Published, test.Tolerance)
+			assert.Equal(t, test.ExpectedMismatches, mismatches)
+			assert.Equal(t, test.ExpectedTiming, timingError)
+		})
+	}
+}
+
+func TestSelfTestDeviceClient(t *testing.T) {
+	response, err := selfTestDeviceClient{}.DeviceByName(context.Background(), selfTestDeviceName)
+	require.NoError(t, err)
+	assert.Equal(t, selfTestServiceName, response.Device.ServiceName)
+	assert.Equal(t, selfTestProfileName, response.Device.ProfileName)
+
+	_, err = selfTestDeviceClient{}.DeviceByName(context.Background(), "D1")
+	require.Error(t, err)
+	assert.Equal(t, 404, err.Code())
+}
@@ -0,0 +1,236 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+var noShadowReplayExists = errors.New("no shadow mode replay running or previously run")
+
+// shadowCapture holds the replayed and live Events captured during a shadow mode replay
+type shadowCapture struct {
+	mutex       sync.Mutex
+	inProgress  bool
+	replayedIds map[string]struct{}
+	replayed    []coreDtos.Event
+	live        []coreDtos.Event
+}
+
+func newShadowCapture() *shadowCapture {
+	return &shadowCapture{
+		inProgress:  true,
+		replayedIds: make(map[string]struct{}),
+	}
+}
+
+// addReplayedEvent must be called before the Event is published so that captureLiveEvent
+// can exclude it when it is received back from the message bus.
+func (s *shadowCapture) addReplayedEvent(event coreDtos.Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.replayedIds[event.Id] = struct{}{}
+	s.replayed = append(s.replayed, event)
+}
+
+// captureLiveEvent is the pipeline function which records the live Events received while the shadow mode replay is running
+func (s *shadowCapture) captureLiveEvent(_ appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	event, ok := data.(coreDtos.Event)
+	if !ok {
+		return false, fmt.Errorf("function captureLiveEvent: expected Event received %T", data)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.inProgress {
+		return false, nil
+	}
+
+	if _, replayed := s.replayedIds[event.Id]; replayed {
+		return false, nil
+	}
+
+	s.live = append(s.live, event)
+	return false, nil
+}
+
+func (s *shadowCapture) stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.inProgress = false
+}
+
+func (s *shadowCapture) report() *dtos.ShadowReport {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	replayedStats := collectResourceStats(s.replayed)
+	liveStats := collectResourceStats(s.live)
+
+	keys := make(map[resourceKey]struct{})
+	for key := range replayedStats {
+		keys[key] = struct{}{}
+	}
+	for key := range liveStats {
+		keys[key] = struct{}{}
+	}
+
+	report := &dtos.ShadowReport{
+		InProgress:         s.inProgress,
+		ReplayedEventCount: len(s.replayed),
+		LiveEventCount:     len(s.live),
+		Resources:          make([]dtos.ResourceComparison, 0, len(keys)),
+	}
+
+	for key := range keys {
+		replayed := replayedStats[key]
+		live := liveStats[key]
+		comparison := dtos.ResourceComparison{
+			DeviceName:           key.deviceName,
+			ResourceName:         key.resourceName,
+			ReplayedReadingCount: replayed.count(),
+			LiveReadingCount:     live.count(),
+			ReplayedMeanValue:    replayed.meanValue(),
+			LiveMeanValue:        live.meanValue(),
+			ReplayedMeanInterval: replayed.meanInterval(),
+			LiveMeanInterval:     live.meanInterval(),
+		}
+		comparison.MeanValueDelta = comparison.LiveMeanValue - comparison.ReplayedMeanValue
+		comparison.MeanIntervalDelta = comparison.LiveMeanInterval - comparison.ReplayedMeanInterval
+		report.Resources = append(report.Resources, comparison)
+	}
+
+	sort.Slice(report.Resources, func(i, j int) bool {
+		if report.Resources[i].DeviceName != report.Resources[j].DeviceName {
+			return report.Resources[i].DeviceName < report.Resources[j].DeviceName
+		}
+		return report.Resources[i].ResourceName < report.Resources[j].ResourceName
+	})
+
+	return report
+}
+
+type resourceKey struct {
+	deviceName   string
+	resourceName string
+}
+
+type resourceStats struct {
+	readingCount int
+	valueCount   int
+	valueSum     float64
+	origins      []int64
+}
+
+func collectResourceStats(events []coreDtos.Event) map[resourceKey]*resourceStats {
+	stats := make(map[resourceKey]*resourceStats)
+	for _, event := range events {
+		for _, reading := range event.Readings {
+			key := resourceKey{deviceName: reading.DeviceName, resourceName: reading.ResourceName}
+			stat, ok := stats[key]
+			if !ok {
+				stat = &resourceStats{}
+				stats[key] = stat
+			}
+
+			stat.readingCount++
+			stat.origins = append(stat.origins, reading.Origin)
+
+			// Non-numeric values (binary, object, bool, strings) are only counted
+			if value, err := strconv.ParseFloat(reading.Value, 64); err == nil {
+				stat.valueCount++
+				stat.valueSum += value
+			}
+		}
+	}
+
+	return stats
+}
+
+func (r *resourceStats) count() int {
+	if r == nil {
+		return 0
+	}
+	return r.readingCount
+}
+
+func (r *resourceStats) meanValue() float64 {
+	if r == nil || r.valueCount == 0 {
+		return 0
+	}
+	return r.valueSum / float64(r.valueCount)
+}
+
+func (r *resourceStats) meanInterval() time.Duration {
+	if r == nil || len(r.origins) < 2 {
+		return 0
+	}
+
+	sort.Slice(r.origins, func(i, j int) bool { return r.origins[i] < r.origins[j] })
+	return time.Duration((r.origins[len(r.origins)-1] - r.origins[0]) / int64(len(r.origins)-1))
+}
+
+// startShadowCapture sets the functions pipeline to capture the live Events while the replay is running.
+// Must be called while holding the recording mutex.
+func (m *dataManager) startShadowCapture() error {
+	m.shadow = newShadowCapture()
+	if err := m.setSessionPipeline(m.shadow.captureLiveEvent); err != nil {
+		m.shadow = nil
+		return fmt.Errorf("%s: %v", setPipelineFailedMessage, err)
+	}
+
+	m.sessionLogger(m.replayLabel, m.replayCorrelationID).Debug("ARR Replay: Shadow mode capture of live Events started")
+	return nil
+}
+
+// stopShadowCapture stops capturing the live Events once the replay has ended for any reason
+func (m *dataManager) stopShadowCapture(shadow *shadowCapture) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	// Only remove the pipeline if it hasn't since been replaced by a newer shadow mode replay
+	if m.shadow == shadow {
+		m.removeSessionPipelines()
+	}
+	shadow.stop()
+
+	m.sessionLogger(m.replayLabel, m.replayCorrelationID).Debug("ARR Replay: Shadow mode capture of live Events stopped")
+}
+
+// ShadowReport returns the comparison report for the current or last shadow mode replay session.
+// An error is returned if no shadow mode replay has been run
+func (m *dataManager) ShadowReport() (*dtos.ShadowReport, error) {
+	m.recordingMutex.Lock()
+	shadow := m.shadow
+	m.recordingMutex.Unlock()
+
+	if shadow == nil {
+		return nil, noShadowReplayExists
+	}
+
+	return shadow.report(), nil
+}
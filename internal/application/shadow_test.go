@@ -0,0 +1,182 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	loggerMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowCapture_CaptureLiveEvent(t *testing.T) {
+	target := newShadowCapture()
+
+	replayedEvent := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	liveEvent := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+
+	target.addReplayedEvent(replayedEvent)
+
+	continuePipeline, result := target.captureLiveEvent(nil, replayedEvent)
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+
+	continuePipeline, result = target.captureLiveEvent(nil, liveEvent)
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+
+	continuePipeline, result = target.captureLiveEvent(nil, "bad data")
+	assert.False(t, continuePipeline)
+	require.IsType(t, errors.New(""), result)
+
+	target.stop()
+	_, _ = target.captureLiveEvent(nil, coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName))
+
+	require.Len(t, target.replayed, 1)
+	require.Len(t, target.live, 1)
+	assert.Equal(t, liveEvent.Id, target.live[0].Id)
+}
+
+func TestShadowCapture_Report(t *testing.T) {
+	newEvent := func(deviceName string, origin int64, values ...string) coreDtos.Event {
+		event := coreDtos.NewEvent(expectedProfileName, deviceName, expectedSourceName)
+		for _, value := range values {
+			_ = event.AddSimpleReading("Int8", common.ValueTypeString, value)
+		}
+		event.Readings[0].Origin = origin
+		return event
+	}
+
+	target := newShadowCapture()
+	target.replayed = []coreDtos.Event{
+		newEvent("D1", 0, "10"),
+		newEvent("D1", int64(time.Second), "20"),
+		newEvent("D1", int64(2*time.Second), "30"),
+	}
+	target.live = []coreDtos.Event{
+		newEvent("D1", 0, "15"),
+		newEvent("D1", int64(2*time.Second), "25"),
+		newEvent("D2", 0, "not a number"),
+	}
+	target.stop()
+
+	expected := &dtos.ShadowReport{
+		InProgress:         false,
+		ReplayedEventCount: 3,
+		LiveEventCount:     3,
+		Resources: []dtos.ResourceComparison{
+			{
+				DeviceName:           "D1",
+				ResourceName:         "Int8",
+				ReplayedReadingCount: 3,
+				LiveReadingCount:     2,
+				ReplayedMeanValue:    20,
+				LiveMeanValue:        20,
+				MeanValueDelta:       0,
+				ReplayedMeanInterval: time.Second,
+				LiveMeanInterval:     2 * time.Second,
+				MeanIntervalDelta:    time.Second,
+			},
+			{
+				DeviceName:       "D2",
+				ResourceName:     "Int8",
+				LiveReadingCount: 1,
+			},
+		},
+	}
+
+	assert.Equal(t, expected, target.report())
+}
+
+func TestDataManager_StartReplay_ShadowMode(t *testing.T) {
+	mockLogger := &loggerMocks.LoggingClient{}
+	mockLogger.On("Debug", mock.Anything)
+	mockLogger.On("Debugf", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(mockLogger)
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("SetDefaultFunctionsPipeline", mock.Anything).Return(nil).Once()
+	mockSdk.On("RemoveAllFunctionPipelines").Once()
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	_, err := target.ShadowReport()
+	require.Equal(t, noShadowReplayExists, err)
+
+	target.recordedData = &recordedData{
+		Events: newEventStore(expectedEventData),
+	}
+
+	err = target.StartReplay(dtos.ReplayRequest{ReplayRate: 10, ShadowMode: true})
+	require.NoError(t, err)
+
+	var report *dtos.ShadowReport
+	require.Eventually(t, func() bool {
+		report, err = target.ShadowReport()
+		return err == nil && !report.InProgress
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, len(expectedEventData), report.ReplayedEventCount)
+	assert.Equal(t, 0, report.LiveEventCount)
+	mockSdk.AssertExpectations(t)
+}
+
+func TestDataManager_StartReplay_ShadowMode_PipelineError(t *testing.T) {
+	mockLogger := &loggerMocks.LoggingClient{}
+	mockLogger.On("Debugf", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(mockLogger)
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("SetDefaultFunctionsPipeline", mock.Anything).Return(errors.New("pipeline error"))
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore(expectedEventData),
+	}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 10, ShadowMode: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), setPipelineFailedMessage)
+	assert.Nil(t, target.replayStartedAt)
+	assert.Nil(t, target.shadow)
+}
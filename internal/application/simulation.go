@@ -0,0 +1,98 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/models"
+)
+
+const simulationServiceDescription = "Virtual device service for the devices replayed by app-record-replay"
+
+var simulationServiceLabels = []string{"app-record-replay", "simulation"}
+
+// registerSimulationDevices registers the recorded devices under the named virtual device service in Core Metadata,
+// adding the service if it doesn't exist, so replayed devices are distinguished from real ones. Devices that already
+// exist are left unchanged so real devices are never taken over. A fanned out replay registers the clones of each
+// device rather than the device. Must be called while holding the recording mutex.
+func (m *dataManager) registerSimulationDevices(serviceName string, fanOut int, lc logger.LoggingClient) error {
+	serviceClient := m.appSvc.DeviceServiceClient()
+	_, err := serviceClient.DeviceServiceByName(context.Background(), serviceName)
+	if err != nil && err.Code() != http.StatusNotFound {
+		return fmt.Errorf("failed check if device service %s exists in system: %w", serviceName, err)
+	}
+
+	if err != nil {
+		// The service is never called back since it isn't running, so the address only needs to be valid
+		service := coreDtos.DeviceService{
+			Name:        serviceName,
+			Description: simulationServiceDescription,
+			Labels:      simulationServiceLabels,
+			BaseAddress: "http://" + serviceName,
+			AdminState:  models.Unlocked,
+		}
+
+		_, err := serviceClient.Add(context.Background(), []requests.AddDeviceServiceRequest{requests.NewAddDeviceServiceRequest(service)})
+		if err != nil {
+			return fmt.Errorf("failed to add device service %s to system: %w", serviceName, err)
+		}
+
+		lc.Debugf("ARR Replay: Added simulation device service %s", serviceName)
+	}
+
+	// Must handle the profiles first, so they exist when the devices are added
+	profiles := make([]coreDtos.DeviceProfile, 0, len(m.recordedData.Profiles))
+	for _, profile := range m.recordedData.Profiles {
+		profiles = append(profiles, *profile)
+	}
+
+	if _, err := m.uploadProfiles(profiles, false); err != nil {
+		return err
+	}
+
+	devices := make([]coreDtos.Device, 0, len(m.recordedData.Devices)*max(fanOut, 1))
+	for _, device := range m.recordedData.Devices {
+		simulated := *device
+		simulated.Id = ""
+		simulated.ServiceName = serviceName
+
+		if fanOut == 0 {
+			devices = append(devices, simulated)
+			continue
+		}
+
+		for clone := 1; clone <= fanOut; clone++ {
+			cloned := simulated
+			cloned.Name = cloneDeviceName(device.Name, clone)
+			devices = append(devices, cloned)
+		}
+	}
+
+	if _, err := m.uploadDevices(devices, false); err != nil {
+		return err
+	}
+
+	lc.Debugf("ARR Replay: Registered %d devices under simulation device service %s", len(devices), serviceName)
+
+	return nil
+}
@@ -0,0 +1,169 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_RegisterSimulationDevices(t *testing.T) {
+	const serviceName = "device-replay"
+
+	tests := []struct {
+		Name          string
+		ServiceExists bool
+		ServiceError  edgexErr.EdgeX
+		ExpectedError bool
+	}{
+		{"Service added", false, nil, false},
+		{"Service exists", true, nil, false},
+		{"Service check failed", false, edgexErr.NewCommonEdgeX(edgexErr.KindServerError, "failed", nil), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockServiceClient := &clientMocks.DeviceServiceClient{}
+			switch {
+			case test.ServiceError != nil:
+				mockServiceClient.On("DeviceServiceByName", mock.Anything, serviceName).Return(responses.DeviceServiceResponse{}, test.ServiceError)
+			case test.ServiceExists:
+				mockServiceClient.On("DeviceServiceByName", mock.Anything, serviceName).Return(responses.DeviceServiceResponse{}, nil)
+			default:
+				mockServiceClient.On("DeviceServiceByName", mock.Anything, serviceName).
+					Return(responses.DeviceServiceResponse{}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "", nil))
+				mockServiceClient.On("Add", mock.Anything, mock.MatchedBy(func(reqs []requests.AddDeviceServiceRequest) bool {
+					return len(reqs) == 1 && reqs[0].Service.Name == serviceName && reqs[0].Service.AdminState == models.Unlocked
+				})).Return(nil, nil)
+			}
+
+			mockProfileClient := &clientMocks.DeviceProfileClient{}
+			mockProfileClient.On("DeviceProfileByName", mock.Anything, "P1").Return(responses.DeviceProfileResponse{}, nil)
+
+			mockDeviceClient := &clientMocks.DeviceClient{}
+			mockDeviceClient.On("DeviceNameExists", mock.Anything, "D1").
+				Return(commonDTO.BaseResponse{StatusCode: http.StatusNotFound}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "", nil))
+			mockDeviceClient.On("DeviceNameExists", mock.Anything, "D2").
+				Return(commonDTO.BaseResponse{StatusCode: http.StatusOK}, nil)
+			// Only the missing device is added, under the simulation service
+			mockDeviceClient.On("Add", mock.Anything, mock.MatchedBy(func(reqs []requests.AddDeviceRequest) bool {
+				return len(reqs) == 1 && reqs[0].Device.Name == "D1" && reqs[0].Device.ServiceName == serviceName
+			})).Return(nil, nil).Once()
+
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("DeviceServiceClient").Return(mockServiceClient)
+			mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+			mockSdk.On("DeviceClient").Return(mockDeviceClient)
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+
+			target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+			target.recordedData = &recordedData{
+				Devices: map[string]*coreDtos.Device{
+					"D1": {Name: "D1", ProfileName: "P1", ServiceName: "device-virtual"},
+					"D2": {Name: "D2", ProfileName: "P1", ServiceName: "device-virtual"},
+				},
+				Profiles: map[string]*coreDtos.DeviceProfile{
+					"P1": {DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "P1"}},
+				},
+			}
+
+			err := target.registerSimulationDevices(serviceName, 0, logger.NewMockClient())
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			mockServiceClient.AssertExpectations(t)
+			mockDeviceClient.AssertExpectations(t)
+
+			// The recorded devices keep their original service
+			assert.Equal(t, "device-virtual", target.recordedData.Devices["D1"].ServiceName)
+		})
+	}
+}
+
+func TestDataManager_RegisterSimulationDevices_FanOut(t *testing.T) {
+	const serviceName = "device-replay"
+
+	mockServiceClient := &clientMocks.DeviceServiceClient{}
+	mockServiceClient.On("DeviceServiceByName", mock.Anything, serviceName).Return(responses.DeviceServiceResponse{}, nil)
+
+	mockProfileClient := &clientMocks.DeviceProfileClient{}
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, "P1").Return(responses.DeviceProfileResponse{}, nil)
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceNameExists", mock.Anything, mock.Anything).
+		Return(commonDTO.BaseResponse{StatusCode: http.StatusNotFound}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "", nil))
+	// The clones are registered, in a single batch, rather than the recorded device
+	mockDeviceClient.On("Add", mock.Anything, mock.MatchedBy(func(reqs []requests.AddDeviceRequest) bool {
+		return len(reqs) == 2 && reqs[0].Device.Name == "D1-1" && reqs[1].Device.Name == "D1-2" &&
+			reqs[0].Device.ServiceName == serviceName && reqs[1].Device.ServiceName == serviceName
+	})).Return(nil, nil).Once()
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("DeviceServiceClient").Return(mockServiceClient)
+	mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Devices: map[string]*coreDtos.Device{"D1": {Name: "D1", ProfileName: "P1", ServiceName: "device-virtual"}},
+		Profiles: map[string]*coreDtos.DeviceProfile{
+			"P1": {DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "P1"}},
+		},
+	}
+
+	err := target.registerSimulationDevices(serviceName, 2, logger.NewMockClient())
+	require.NoError(t, err)
+	mockDeviceClient.AssertExpectations(t)
+	assert.Equal(t, "D1", target.recordedData.Devices["D1"].Name)
+}
+
+func TestDataManager_RegisterSimulationDevices_AddFailed(t *testing.T) {
+	mockServiceClient := &clientMocks.DeviceServiceClient{}
+	mockServiceClient.On("DeviceServiceByName", mock.Anything, "device-replay").
+		Return(responses.DeviceServiceResponse{}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "", nil))
+	mockServiceClient.On("Add", mock.Anything, mock.Anything).Return(nil, edgexErr.NewCommonEdgeXWrapper(errors.New("failed")))
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("DeviceServiceClient").Return(mockServiceClient)
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{}
+
+	err := target.registerSimulationDevices("device-replay", 0, logger.NewMockClient())
+	require.ErrorContains(t, err, "failed to add device service device-replay")
+}
@@ -0,0 +1,337 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/transforms"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-messaging/v3/pkg/types"
+)
+
+const (
+	defaultSinkClientIdPrefix    = "app-record-replay-"
+	defaultRemoteBaseTopicPrefix = "edgex"
+
+	// serviceNameKey is the context key holding the Event's Device Service name, for the {servicename} placeholder
+	serviceNameKey = "servicename"
+	// remoteTopicKey is the context key holding the topic an EdgeX MessageBus sink publishes the Event to
+	remoteTopicKey = "arrremotetopic"
+	// remoteCoreDataSecretKey is the key of the value in an EdgeX Core Data sink's secret which is sent as the
+	// Authorization header
+	remoteCoreDataSecretKey = "authorization"
+)
+
+var invalidReplaySinkType = fmt.Errorf("invalid sink Type, value must be '%s', '%s', '%s', '%s' or '%s'",
+	dtos.ReplaySinkMessageBus, dtos.ReplaySinkMQTT, dtos.ReplaySinkHTTP, dtos.ReplaySinkEdgeXMessageBus,
+	dtos.ReplaySinkEdgeXCoreData)
+var invalidReplaySinkMQTT = errors.New("invalid MQTT sink, BrokerAddress and Topic must be set")
+var invalidReplaySinkHTTP = errors.New("invalid HTTP sink, URL must be set")
+var invalidReplaySinkEdgeXMessageBus = errors.New("invalid EdgeX MessageBus sink, BrokerAddress must be set")
+var invalidReplaySinkEdgeXCoreData = errors.New("invalid EdgeX Core Data sink, URL must be set")
+var noEnabledReplaySink = errors.New("invalid Sinks, at least one sink must be enabled")
+
+// replaySink is a destination the replayed Events are published to
+type replaySink interface {
+	publish(topic string, addEvent requests.AddEventRequest) error
+}
+
+// replaySinkState is a sink of a replay session with its publish policy and counts. The counts are guarded by the
+// recording mutex.
+type replaySinkState struct {
+	name    string
+	kind    string
+	enabled bool
+	sink    replaySink
+	policy  *publishPolicy
+
+	publishedEventCount int
+	failedEventCount    int
+}
+
+// newReplaySinks returns the sinks for the request. A request without Sinks publishes to the MessageBus only, which
+// is reported without a name. Must be called while holding the recording mutex.
+func (m *dataManager) newReplaySinks(request dtos.ReplayRequest, policy *publishPolicy) ([]*replaySinkState, error) {
+	if len(request.Sinks) == 0 {
+		return []*replaySinkState{{kind: dtos.ReplaySinkMessageBus, enabled: true, sink: messageBusSink{m.appSvc}, policy: policy}}, nil
+	}
+
+	var sinks []*replaySinkState
+	enabled := 0
+	for _, config := range request.Sinks {
+		state := &replaySinkState{
+			name:    config.Name,
+			kind:    config.Type,
+			enabled: config.Enabled == nil || *config.Enabled,
+			policy:  policy,
+		}
+
+		if len(state.name) == 0 {
+			state.name = config.Type
+		}
+
+		if len(config.OnPublishError) > 0 {
+			sinkRequest := request
+			sinkRequest.OnPublishError = config.OnPublishError
+			sinkPolicy, err := newPublishPolicy(sinkRequest)
+			if err != nil {
+				return nil, fmt.Errorf("sink %s: %w", state.name, err)
+			}
+
+			state.policy = sinkPolicy
+		}
+
+		switch config.Type {
+		case dtos.ReplaySinkMessageBus:
+			state.sink = messageBusSink{m.appSvc}
+		case dtos.ReplaySinkMQTT:
+			if len(config.BrokerAddress) == 0 || len(config.Topic) == 0 {
+				return nil, fmt.Errorf("sink %s: %w", state.name, invalidReplaySinkMQTT)
+			}
+
+			state.sink = exportSink{appSvc: m.appSvc, send: m.mqttSender(config, state.name).MQTTSend}
+		case dtos.ReplaySinkHTTP:
+			if len(config.URL) == 0 {
+				return nil, fmt.Errorf("sink %s: %w", state.name, invalidReplaySinkHTTP)
+			}
+
+			state.sink = exportSink{appSvc: m.appSvc, send: transforms.NewHTTPSender(config.URL, common.ContentTypeJSON, false).HTTPPost}
+		case dtos.ReplaySinkEdgeXMessageBus:
+			if len(config.BrokerAddress) == 0 {
+				return nil, fmt.Errorf("sink %s: %w", state.name, invalidReplaySinkEdgeXMessageBus)
+			}
+
+			// The topic is built for each Event, so is passed to the sender in the context
+			config.Topic = "{" + remoteTopicKey + "}"
+			prefix := config.BaseTopicPrefix
+			if len(prefix) == 0 {
+				prefix = defaultRemoteBaseTopicPrefix
+			}
+
+			state.sink = remoteMessageBusSink{
+				exportSink:      exportSink{appSvc: m.appSvc, send: m.mqttSender(config, state.name).MQTTSend},
+				baseTopicPrefix: prefix,
+			}
+		case dtos.ReplaySinkEdgeXCoreData:
+			if len(config.URL) == 0 {
+				return nil, fmt.Errorf("sink %s: %w", state.name, invalidReplaySinkEdgeXCoreData)
+			}
+
+			options := transforms.HTTPSenderOptions{
+				URL:      strings.TrimSuffix(config.URL, "/") + common.ApiEventServiceNameProfileNameDeviceNameSourceNameRoute,
+				MimeType: common.ContentTypeJSON,
+			}
+			if len(config.SecretName) > 0 {
+				options.HTTPHeaderName = "Authorization"
+				options.SecretName = config.SecretName
+				options.SecretValueKey = remoteCoreDataSecretKey
+			}
+
+			state.sink = exportSink{appSvc: m.appSvc, send: transforms.NewHTTPSenderWithOptions(options).HTTPPost}
+		default:
+			return nil, fmt.Errorf("sink %s: %w", state.name, invalidReplaySinkType)
+		}
+
+		if state.enabled {
+			enabled++
+		}
+
+		sinks = append(sinks, state)
+	}
+
+	if enabled == 0 {
+		return nil, noEnabledReplaySink
+	}
+
+	return sinks, nil
+}
+
+// mqttSender returns the MQTT sender for the sink. The SDK sender keeps its broker connection open and has no way
+// to close it, so senders are kept for reuse by later replays to the same broker rather than connecting anew for
+// each replay. Must be called while holding the recording mutex.
+func (m *dataManager) mqttSender(config dtos.ReplaySink, name string) *transforms.MQTTSecretSender {
+	mqttConfig := transforms.MQTTSecretConfig{
+		BrokerAddress: config.BrokerAddress,
+		ClientId:      config.ClientId,
+		SecretName:    config.SecretName,
+		AutoReconnect: true,
+		Topic:         config.Topic,
+		QoS:           config.QoS,
+		Retain:        config.Retain,
+		AuthMode:      config.AuthMode,
+	}
+
+	if len(mqttConfig.ClientId) == 0 {
+		mqttConfig.ClientId = defaultSinkClientIdPrefix + name
+	}
+
+	if len(mqttConfig.AuthMode) == 0 {
+		mqttConfig.AuthMode = "none"
+	}
+
+	key := fmt.Sprintf("%+v", mqttConfig)
+	if sender, ok := m.mqttSinkSenders[key]; ok {
+		return sender
+	}
+
+	if m.mqttSinkSenders == nil {
+		m.mqttSinkSenders = make(map[string]*transforms.MQTTSecretSender)
+	}
+
+	sender := transforms.NewMQTTSecretSender(mqttConfig, false)
+	m.mqttSinkSenders[key] = sender
+	return sender
+}
+
+// publishToSinks publishes the replayed Event to each enabled sink in turn, applying the sink's publish policy.
+// True is returned if the Event was published to at least one sink, otherwise an error is returned if the replay
+// must stop.
+func (m *dataManager) publishToSinks(sinks []*replaySinkState, lc logger.LoggingClient, topic string,
+	addEvent requests.AddEventRequest) (bool, error) {
+	published := false
+	for _, state := range sinks {
+		if !state.enabled {
+			continue
+		}
+
+		ok, err := m.publish(state.policy, lc, func() error {
+			return state.sink.publish(topic, addEvent)
+		})
+		if err != nil {
+			if len(state.name) > 0 {
+				err = fmt.Errorf("sink %s: %w", state.name, err)
+			}
+			return false, err
+		}
+
+		m.recordingMutex.Lock()
+		if ok {
+			state.publishedEventCount++
+		} else {
+			state.failedEventCount++
+		}
+		m.recordingMutex.Unlock()
+
+		published = published || ok
+	}
+
+	return published, nil
+}
+
+// replaySinksStatus returns the status of the sinks of the replay, or nil if the replay request didn't set its
+// sinks. Must be called while holding the recording mutex.
+func (m *dataManager) replaySinksStatus() []dtos.ReplaySinkStatus {
+	var statuses []dtos.ReplaySinkStatus
+	for _, state := range m.replaySinks {
+		if len(state.name) == 0 {
+			continue
+		}
+
+		statuses = append(statuses, dtos.ReplaySinkStatus{
+			Name:                state.name,
+			Type:                state.kind,
+			Enabled:             state.enabled,
+			PublishedEventCount: state.publishedEventCount,
+			FailedEventCount:    state.failedEventCount,
+		})
+	}
+
+	return statuses
+}
+
+// messageBusSink publishes the replayed Events to the EdgeX MessageBus
+type messageBusSink struct {
+	appSvc appInterfaces.ApplicationService
+}
+
+func (s messageBusSink) publish(topic string, addEvent requests.AddEventRequest) error {
+	return s.appSvc.PublishWithTopic(topic, addEvent, common.ContentTypeJSON)
+}
+
+// exportSink publishes the replayed Events using an SDK export function, such as the MQTT or HTTP sender. The
+// function's context carries the Event's service, device, profile and source names, which fill the placeholders of
+// the sink's topic or URL.
+type exportSink struct {
+	appSvc appInterfaces.ApplicationService
+	send   appInterfaces.AppFunction
+}
+
+func (s exportSink) publish(topic string, addEvent requests.AddEventRequest) error {
+	return s.export(topic, addEvent, addEvent, nil)
+}
+
+// export exports the data for the replayed Event, with the additional context values
+func (s exportSink) export(topic string, addEvent requests.AddEventRequest, data any, values map[string]string) error {
+	ctx := s.appSvc.BuildContext(addEvent.RequestId, common.ContentTypeJSON)
+	ctx.AddValue(serviceNameKey, eventTopicServiceName(topic))
+	ctx.AddValue(appInterfaces.DEVICENAME, addEvent.Event.DeviceName)
+	ctx.AddValue(appInterfaces.PROFILENAME, addEvent.Event.ProfileName)
+	ctx.AddValue(appInterfaces.SOURCENAME, addEvent.Event.SourceName)
+	for key, value := range values {
+		ctx.AddValue(key, value)
+	}
+
+	ok, result := s.send(ctx, data)
+	if ok {
+		return nil
+	}
+
+	if err, isError := result.(error); isError {
+		return err
+	}
+
+	return fmt.Errorf("export failed: %v", result)
+}
+
+// remoteMessageBusSink publishes the replayed Events to the MQTT MessageBus of a different EdgeX instance. Each
+// Event is wrapped in a MessageBus envelope and published to the same Event topic as on the local MessageBus, under
+// the instance's base topic prefix, so the instance's Core Data and App Services receive it as if published locally.
+type remoteMessageBusSink struct {
+	exportSink
+	baseTopicPrefix string
+}
+
+func (s remoteMessageBusSink) publish(topic string, addEvent requests.AddEventRequest) error {
+	payload, err := json.Marshal(addEvent)
+	if err != nil {
+		return err
+	}
+
+	envelope := types.MessageEnvelope{
+		Versionable:   commonDTO.NewVersionable(),
+		CorrelationID: addEvent.RequestId,
+		RequestID:     addEvent.RequestId,
+		Payload:       payload,
+		ContentType:   common.ContentTypeJSON,
+	}
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	return s.export(topic, addEvent, data, map[string]string{remoteTopicKey: common.BuildTopic(s.baseTopicPrefix, topic)})
+}
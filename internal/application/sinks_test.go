@@ -0,0 +1,284 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-messaging/v3/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_NewReplaySinks(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		Name          string
+		Sinks         []dtos.ReplaySink
+		ExpectedNames []string
+		ExpectedError error
+	}{
+		{"Default", nil, []string{""}, nil},
+		{"Named", []dtos.ReplaySink{
+			{Type: dtos.ReplaySinkMessageBus},
+			{Name: "mirror", Type: dtos.ReplaySinkMQTT, BrokerAddress: "tcp://localhost:1883", Topic: "mirror/{devicename}"},
+			{Type: dtos.ReplaySinkHTTP, URL: "http://localhost/events", Enabled: &disabled},
+		}, []string{dtos.ReplaySinkMessageBus, "mirror", dtos.ReplaySinkHTTP}, nil},
+		{"Bad type", []dtos.ReplaySink{{Type: "kafka"}}, nil, invalidReplaySinkType},
+		{"MQTT missing topic", []dtos.ReplaySink{{Type: dtos.ReplaySinkMQTT, BrokerAddress: "tcp://localhost:1883"}}, nil, invalidReplaySinkMQTT},
+		{"HTTP missing URL", []dtos.ReplaySink{{Type: dtos.ReplaySinkHTTP}}, nil, invalidReplaySinkHTTP},
+		{"Remote EdgeX", []dtos.ReplaySink{
+			{Name: "lab-bus", Type: dtos.ReplaySinkEdgeXMessageBus, BrokerAddress: "tcp://lab:1883"},
+			{Name: "lab-data", Type: dtos.ReplaySinkEdgeXCoreData, URL: "http://lab:59880", SecretName: "lab"},
+		}, []string{"lab-bus", "lab-data"}, nil},
+		{"EdgeX MessageBus missing broker", []dtos.ReplaySink{{Type: dtos.ReplaySinkEdgeXMessageBus}}, nil, invalidReplaySinkEdgeXMessageBus},
+		{"EdgeX Core Data missing URL", []dtos.ReplaySink{{Type: dtos.ReplaySinkEdgeXCoreData}}, nil, invalidReplaySinkEdgeXCoreData},
+		{"Bad OnPublishError", []dtos.ReplaySink{{Type: dtos.ReplaySinkMessageBus, OnPublishError: "ignore"}}, nil, invalidOnPublishError},
+		{"None enabled", []dtos.ReplaySink{{Type: dtos.ReplaySinkMessageBus, Enabled: &disabled}}, nil, noEnabledReplaySink},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			request := dtos.ReplayRequest{ReplayRate: 1, Sinks: test.Sinks}
+			policy, err := newPublishPolicy(request)
+			require.NoError(t, err)
+
+			target := NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), nil, nil).(*dataManager)
+			sinks, err := target.newReplaySinks(request, policy)
+			if test.ExpectedError != nil {
+				require.ErrorIs(t, err, test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			var names []string
+			for _, sink := range sinks {
+				names = append(names, sink.name)
+			}
+			assert.Equal(t, test.ExpectedNames, names)
+		})
+	}
+}
+
+func TestDataManager_NewReplaySinks_Policy(t *testing.T) {
+	request := dtos.ReplayRequest{
+		ReplayRate:     1,
+		OnPublishError: dtos.ReplayPublishErrorAbort,
+		Sinks: []dtos.ReplaySink{
+			{Type: dtos.ReplaySinkMessageBus},
+			{Type: dtos.ReplaySinkHTTP, URL: "http://localhost/events", OnPublishError: dtos.ReplayPublishErrorSkip},
+		},
+	}
+	policy, err := newPublishPolicy(request)
+	require.NoError(t, err)
+
+	target := NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), nil, nil).(*dataManager)
+	sinks, err := target.newReplaySinks(request, policy)
+	require.NoError(t, err)
+	require.Len(t, sinks, 2)
+
+	// Each sink has its own policy, defaulting to the request's
+	assert.Equal(t, dtos.ReplayPublishErrorAbort, sinks[0].policy.onError)
+	assert.Equal(t, dtos.ReplayPublishErrorSkip, sinks[1].policy.onError)
+}
+
+func TestDataManager_MqttSender_Reused(t *testing.T) {
+	target := NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), nil, nil).(*dataManager)
+	config := dtos.ReplaySink{Type: dtos.ReplaySinkMQTT, BrokerAddress: "tcp://localhost:1883", Topic: "mirror"}
+
+	first := target.mqttSender(config, "mirror")
+	assert.Same(t, first, target.mqttSender(config, "mirror"))
+
+	config.Topic = "other"
+	assert.NotSame(t, first, target.mqttSender(config, "mirror"))
+}
+
+func TestDataManager_StartReplay_Sinks(t *testing.T) {
+	var mutex sync.Mutex
+	var paths []string
+	mirror := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		paths = append(paths, request.URL.Path)
+		mutex.Unlock()
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer mirror.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	lc := logger.NewMockClient()
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(lc)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).Return(nil)
+	mockSdk.On("BuildContext", mock.Anything, common.ContentTypeJSON).
+		Return(func(correlationId string, _ string) interfaces.AppFunctionContext {
+			return pkg.NewAppFuncContextForTest(correlationId, lc)
+		})
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore([]coreDtos.Event{
+			coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+			coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+		}),
+		Devices: map[string]*coreDtos.Device{expectedDeviceName: {Name: expectedDeviceName}},
+	}
+
+	err := target.StartReplay(dtos.ReplayRequest{
+		ReplayRate: 1,
+		Sinks: []dtos.ReplaySink{
+			{Type: dtos.ReplaySinkMessageBus},
+			{Name: "mirror", Type: dtos.ReplaySinkHTTP, URL: mirror.URL + "/events/{devicename}"},
+			{Name: "broken", Type: dtos.ReplaySinkHTTP, URL: broken.URL, OnPublishError: dtos.ReplayPublishErrorSkip},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	require.Empty(t, status.Message)
+	assert.Equal(t, 2, status.EventCount)
+	assert.Equal(t, []dtos.ReplaySinkStatus{
+		{Name: dtos.ReplaySinkMessageBus, Type: dtos.ReplaySinkMessageBus, Enabled: true, PublishedEventCount: 2},
+		{Name: "mirror", Type: dtos.ReplaySinkHTTP, Enabled: true, PublishedEventCount: 2},
+		{Name: "broken", Type: dtos.ReplaySinkHTTP, Enabled: true, FailedEventCount: 2},
+	}, status.Sinks)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []string{"/events/" + expectedDeviceName, "/events/" + expectedDeviceName}, paths)
+	mockSdk.AssertNumberOfCalls(t, "PublishWithTopic", 2)
+}
+
+func TestDataManager_StartReplay_EdgeXCoreDataSink(t *testing.T) {
+	var mutex sync.Mutex
+	var paths []string
+	coreData := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		paths = append(paths, request.URL.Path)
+		mutex.Unlock()
+		writer.WriteHeader(http.StatusCreated)
+	}))
+	defer coreData.Close()
+
+	lc := logger.NewMockClient()
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(lc)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("BuildContext", mock.Anything, common.ContentTypeJSON).
+		Return(func(correlationId string, _ string) interfaces.AppFunctionContext {
+			return pkg.NewAppFuncContextForTest(correlationId, lc)
+		})
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore([]coreDtos.Event{coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)}),
+		Devices: map[string]*coreDtos.Device{
+			expectedDeviceName: {Name: expectedDeviceName, ServiceName: "device-virtual"},
+		},
+	}
+
+	err := target.StartReplay(dtos.ReplayRequest{
+		ReplayRate: 1,
+		Sinks:      []dtos.ReplaySink{{Name: "lab", Type: dtos.ReplaySinkEdgeXCoreData, URL: coreData.URL + "/"}},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	require.Empty(t, status.Message)
+	assert.Equal(t, []dtos.ReplaySinkStatus{
+		{Name: "lab", Type: dtos.ReplaySinkEdgeXCoreData, Enabled: true, PublishedEventCount: 1},
+	}, status.Sinks)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []string{common.ApiEventRoute + "/device-virtual/" + expectedProfileName + "/" +
+		expectedDeviceName + "/" + expectedSourceName}, paths)
+	mockSdk.AssertNotCalled(t, "PublishWithTopic", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRemoteMessageBusSink_Publish(t *testing.T) {
+	lc := logger.NewMockClient()
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("BuildContext", mock.Anything, common.ContentTypeJSON).
+		Return(func(correlationId string, _ string) interfaces.AppFunctionContext {
+			return pkg.NewAppFuncContextForTest(correlationId, lc)
+		})
+
+	var topic string
+	var data []byte
+	sink := remoteMessageBusSink{
+		exportSink: exportSink{appSvc: mockSdk, send: func(ctx interfaces.AppFunctionContext, received any) (bool, any) {
+			topic, _ = ctx.ApplyValues("{" + remoteTopicKey + "}")
+			data, _ = received.([]byte)
+			return true, nil
+		}},
+		baseTopicPrefix: "lab",
+	}
+
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	require.NoError(t, event.AddSimpleReading(expectedSourceName, common.ValueTypeInt32, int32(1)))
+	addEvent := requests.NewAddEventRequest(event)
+	require.NoError(t, sink.publish(buildEventTopic("device-virtual", event), addEvent))
+
+	assert.Equal(t, "lab/events/device/device-virtual/"+expectedProfileName+"/"+expectedDeviceName+"/"+expectedSourceName, topic)
+
+	envelope := types.MessageEnvelope{}
+	require.NoError(t, json.Unmarshal(data, &envelope))
+	assert.Equal(t, common.ContentTypeJSON, envelope.ContentType)
+	assert.Equal(t, addEvent.RequestId, envelope.CorrelationID)
+
+	actual := requests.AddEventRequest{}
+	require.NoError(t, json.Unmarshal(envelope.Payload, &actual))
+	assert.Equal(t, event.Id, actual.Event.Id)
+}
+
+func TestEventTopicServiceName(t *testing.T) {
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	assert.Equal(t, "device-virtual", eventTopicServiceName(buildEventTopic("device-virtual", event)))
+	assert.Equal(t, unknownServiceName, eventTopicServiceName(buildEventTopic(unknownServiceName, event)))
+}
@@ -0,0 +1,85 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+const (
+	sdkModulePath  = "github.com/edgexfoundry/app-functions-sdk-go/v3"
+	unknownVersion = "unknown"
+)
+
+// ServiceVersion is the version of the service - will be overwritten by build
+var ServiceVersion = "0.0.0"
+
+var noRecordingMetadata = errors.New("no recording metadata, the recorded data was imported without it")
+
+// newRecordingMetadata returns the metadata stamped on a recording started now with the request
+func newRecordingMetadata(request dtos.RecordRequest, now time.Time) *dtos.RecordingMetadata {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = unknownVersion
+	}
+
+	metadata := &dtos.RecordingMetadata{
+		Hostname:       hostname,
+		ServiceVersion: ServiceVersion,
+		SDKVersion:     unknownVersion,
+		EdgeXVersion:   unknownVersion,
+		StartedAt:      now.UnixNano(),
+		Request:        request,
+	}
+
+	// The module versions are only available when built with module support, which is always the case for builds
+	// of the service but not for some test binaries.
+	if version, ok := utils.ModuleVersion(sdkModulePath); ok {
+		metadata.SDKVersion = version
+	}
+	if version, ok := utils.ModuleVersion(utils.ContractsModulePath); ok {
+		metadata.EdgeXVersion = version
+	}
+
+	return metadata
+}
+
+// RecordingMetadata returns the metadata for the recording in progress or, if none, the last recorded or imported
+// data. An error is returned if there is no recording or the imported data has no metadata.
+func (m *dataManager) RecordingMetadata() (*dtos.RecordingMetadata, error) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.recordingStartedAt != nil {
+		return withGaps(m.recordingMetadata, m.recordingGaps()), nil
+	}
+
+	if m.recordedData == nil {
+		return nil, noRecordedData
+	}
+
+	if m.recordedData.Metadata == nil {
+		return nil, noRecordingMetadata
+	}
+
+	return m.recordedData.Metadata, nil
+}
@@ -0,0 +1,68 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecordingMetadata(t *testing.T) {
+	request := dtos.RecordRequest{EventLimit: 10, IncludeDevices: []string{"D1"}}
+	now := time.Now()
+
+	actual := newRecordingMetadata(request, now)
+	require.NotNil(t, actual)
+	assert.NotEmpty(t, actual.Hostname)
+	assert.Equal(t, ServiceVersion, actual.ServiceVersion)
+	assert.NotEmpty(t, actual.SDKVersion)
+	assert.NotEmpty(t, actual.EdgeXVersion)
+	assert.Equal(t, now.UnixNano(), actual.StartedAt)
+	assert.Equal(t, request, actual.Request)
+}
+
+func TestDataManager_RecordingMetadata(t *testing.T) {
+	target := NewManager(&mocks.ApplicationService{}, 0, clock.New(), nil, nil).(*dataManager)
+
+	_, err := target.RecordingMetadata()
+	require.Equal(t, noRecordedData, err)
+
+	// Imported data may not have been stamped
+	target.recordedData = &recordedData{}
+	_, err = target.RecordingMetadata()
+	require.Equal(t, noRecordingMetadata, err)
+
+	completed := newRecordingMetadata(dtos.RecordRequest{EventLimit: 5}, time.Now())
+	target.recordedData.Metadata = completed
+	actual, err := target.RecordingMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, completed, actual)
+
+	// The recording in progress takes precedence over the last recorded data
+	now := time.Now()
+	target.recordingStartedAt = &now
+	target.recordingMetadata = newRecordingMetadata(dtos.RecordRequest{Duration: time.Minute}, now)
+	actual, err = target.RecordingMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, target.recordingMetadata, actual)
+}
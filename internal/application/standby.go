@@ -0,0 +1,113 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+)
+
+// ReplayTriggerTopicAppSetting is the topic, relative to the base topic, on which any message triggers the replay
+// in standby, e.g. "arr/trigger". The topic must be matched by the SubscribeTopics, otherwise the trigger messages
+// aren't received. Standby replays are only triggered via the API when not set.
+const ReplayTriggerTopicAppSetting = "ReplayTriggerTopic"
+
+const replayTriggerPipelineId = "arr-replay-trigger"
+
+var noReplayInStandbyError = errors.New("no replay in standby")
+
+// enterReplayStandby holds the primed replay until it is triggered, listening for the trigger message if a trigger
+// topic is configured. Must be called while holding the recording mutex after the replay state has been reset.
+func (m *dataManager) enterReplayStandby(start func() error) error {
+	if topic := m.appSvc.ApplicationSettings()[ReplayTriggerTopicAppSetting]; len(topic) > 0 {
+		err := m.appSvc.AddFunctionsPipelineForTopics(replayTriggerPipelineId, []string{topic}, m.triggerReplayFromBus)
+		if err != nil {
+			m.abortReplayStart()
+			return fmt.Errorf("failed to add the replay trigger pipeline for topic %s: %v", topic, err)
+		}
+
+		m.replayTriggerTopic = topic
+	}
+
+	m.replayStandby = start
+	m.sessionLogger(m.replayLabel, m.replayCorrelationID).Info("ARR Replay: Replay primed and in standby until triggered")
+
+	return nil
+}
+
+// leaveReplayStandby stops listening for the trigger message and returns the start of the replay in standby.
+// Must be called while holding the recording mutex.
+func (m *dataManager) leaveReplayStandby() func() error {
+	start := m.replayStandby
+	m.replayStandby = nil
+
+	if len(m.replayTriggerTopic) > 0 {
+		// No other session pipelines are set while a replay is in standby since it never runs alongside a recording
+		m.removeSessionPipelines()
+		m.replayTriggerTopic = ""
+	}
+
+	return start
+}
+
+// TriggerReplay starts publishing the replay in standby. The replay's duration and timing start from the trigger.
+// An error is returned if no replay is in standby or the replay fails to start.
+func (m *dataManager) TriggerReplay() error {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	return m.triggerReplay()
+}
+
+// triggerReplay starts publishing the replay in standby. Must be called while holding the recording mutex.
+func (m *dataManager) triggerReplay() error {
+	if m.replayStandby == nil {
+		return noReplayInStandbyError
+	}
+
+	start := m.leaveReplayStandby()
+
+	now := m.clock.Now()
+	m.replayStartedAt = &now
+
+	if err := start(); err != nil {
+		m.abortReplayStart()
+		m.replayError = err
+		m.scheduleNextSession()
+		return err
+	}
+
+	m.startReplayBudget()
+	m.sessionLogger(m.replayLabel, m.replayCorrelationID).Info("ARR Replay: Replay in standby triggered")
+
+	return nil
+}
+
+// triggerReplayFromBus is the pipeline function which triggers the replay in standby on receiving any message on
+// the trigger topic
+func (m *dataManager) triggerReplayFromBus(ctx appInterfaces.AppFunctionContext, _ any) (bool, interface{}) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if err := m.triggerReplay(); err != nil {
+		ctx.LoggingClient().Errorf("ARR Replay: Trigger message failed to trigger the replay: %v", err)
+	}
+
+	return false, nil
+}
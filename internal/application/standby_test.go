@@ -0,0 +1,143 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newStandbyTarget(settings map[string]string) (*dataManager, *mocks.ApplicationService) {
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(settings).Maybe()
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("AppContext").Return(context.Background()).Maybe()
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore(expectedEventData),
+	}
+
+	return target, mockSdk
+}
+
+func TestDataManager_TriggerReplay(t *testing.T) {
+	target, mockSdk := newStandbyTarget(map[string]string{})
+
+	require.Equal(t, noReplayInStandbyError, target.TriggerReplay())
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 10, Standby: true})
+	require.NoError(t, err)
+
+	status := target.ReplayStatus()
+	assert.True(t, status.Standby)
+	assert.False(t, status.Running)
+	assert.Zero(t, status.Duration)
+	mockSdk.AssertNotCalled(t, "PublishWithTopic", mock.Anything, mock.Anything, mock.Anything)
+
+	// The replay in standby still blocks other sessions
+	require.Equal(t, replayInProgressError, target.StartReplay(dtos.ReplayRequest{ReplayRate: 10}))
+
+	require.NoError(t, target.TriggerReplay())
+	require.Equal(t, noReplayInStandbyError, target.TriggerReplay())
+
+	require.Eventually(t, func() bool {
+		status = target.ReplayStatus()
+		return !status.Running
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.False(t, status.Standby)
+	assert.Empty(t, status.Message)
+	assert.Equal(t, len(expectedEventData), status.EventCount)
+	mockSdk.AssertNotCalled(t, "AddFunctionsPipelineForTopics", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDataManager_TriggerReplay_Bus(t *testing.T) {
+	const topic = "arr/trigger"
+
+	target, mockSdk := newStandbyTarget(map[string]string{ReplayTriggerTopicAppSetting: topic})
+	mockSdk.On("AddFunctionsPipelineForTopics", replayTriggerPipelineId, []string{topic}, mock.Anything).Return(nil).Once()
+	mockSdk.On("RemoveAllFunctionPipelines").Once()
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 10, Standby: true})
+	require.NoError(t, err)
+	assert.True(t, target.ReplayStatus().Standby)
+
+	ctx := pkg.NewAppFuncContextForTest("", logger.NewMockClient())
+	continuePipeline, result := target.triggerReplayFromBus(ctx, []byte("go"))
+	assert.False(t, continuePipeline)
+	assert.Nil(t, result)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 10*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, len(expectedEventData), target.ReplayStatus().EventCount)
+
+	// Trigger messages received after the replay started are ignored
+	target.triggerReplayFromBus(ctx, []byte("go"))
+	mockSdk.AssertExpectations(t)
+}
+
+func TestDataManager_TriggerReplay_PipelineError(t *testing.T) {
+	target, mockSdk := newStandbyTarget(map[string]string{ReplayTriggerTopicAppSetting: "arr/trigger"})
+	mockSdk.On("AddFunctionsPipelineForTopics", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("failed"))
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 10, Standby: true})
+	require.Error(t, err)
+	assert.Nil(t, target.replayStartedAt)
+	assert.Nil(t, target.replayStandby)
+}
+
+func TestDataManager_CancelReplay_Standby(t *testing.T) {
+	target, mockSdk := newStandbyTarget(map[string]string{ReplayTriggerTopicAppSetting: "arr/trigger"})
+	mockSdk.On("AddFunctionsPipelineForTopics", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	mockSdk.On("RemoveAllFunctionPipelines").Once()
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 10, Standby: true})
+	require.NoError(t, err)
+
+	require.NoError(t, target.CancelReplay())
+
+	status := target.ReplayStatus()
+	assert.False(t, status.Standby)
+	assert.False(t, status.Running)
+	assert.Equal(t, replayCanceled.Error(), status.Message)
+	require.Equal(t, noReplayInStandbyError, target.TriggerReplay())
+	mockSdk.AssertExpectations(t)
+	mockSdk.AssertNotCalled(t, "PublishWithTopic", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,391 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"unsafe"
+
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+// eventStore holds the recorded Events in a columnar layout. The Event fields are kept in per Event columns and the
+// simple Readings in per resource columns of ids, origins and values, with the repeated names interned. This takes a
+// fraction of the memory of the Event DTOs for large recordings and lets per resource operations scan just the
+// columns they need. Readings with tags, binary, object or empty values are kept whole. Events are rebuilt on demand
+// in their recorded order. The id and API version columns are sparse, so Events stripped of them by readings-only
+// recordings don't hold columns of empty strings.
+type eventStore struct {
+	interned map[string]string
+
+	apiVersions  []string
+	ids          []string
+	deviceNames  []string
+	profileNames []string
+	sourceNames  []string
+	origins      []int64
+	tags         map[int]coreDtos.Tags
+	readingEnds  []int
+
+	readings      []readingRef
+	columns       []*resourceColumn
+	columnIndex   map[resourceColumnKey]int
+	wholeReadings []coreDtos.BaseReading
+}
+
+// readingRef locates a Reading in the store. Column is the index of its resource column, or -1 if the Reading is
+// kept whole, and row is its index within that column or within the whole Readings.
+type readingRef struct {
+	column int32
+	row    int32
+}
+
+type resourceColumnKey struct {
+	deviceName   string
+	profileName  string
+	resourceName string
+	valueType    string
+	units        string
+}
+
+// resourceColumn holds the simple Readings for a single device resource
+type resourceColumn struct {
+	resourceColumnKey
+	ids     []string
+	origins []int64
+	values  []string
+}
+
+func newEventStore(events []coreDtos.Event) *eventStore {
+	store := &eventStore{
+		interned:    make(map[string]string),
+		tags:        make(map[int]coreDtos.Tags),
+		columnIndex: make(map[resourceColumnKey]int),
+	}
+
+	for _, event := range events {
+		store.add(event)
+	}
+
+	return store
+}
+
+func (s *eventStore) intern(value string) string {
+	if interned, ok := s.interned[value]; ok {
+		return interned
+	}
+
+	s.interned[value] = value
+	return value
+}
+
+// add appends the Event to the store
+func (s *eventStore) add(event coreDtos.Event) {
+	if event.Tags != nil {
+		s.tags[len(s.origins)] = event.Tags
+	}
+
+	s.apiVersions = appendSparse(s.apiVersions, len(s.origins), s.intern(event.ApiVersion))
+	s.ids = appendSparse(s.ids, len(s.origins), event.Id)
+	s.deviceNames = append(s.deviceNames, s.intern(event.DeviceName))
+	s.profileNames = append(s.profileNames, s.intern(event.ProfileName))
+	s.sourceNames = append(s.sourceNames, s.intern(event.SourceName))
+	s.origins = append(s.origins, event.Origin)
+
+	for _, reading := range event.Readings {
+		s.readings = append(s.readings, s.addReading(reading))
+	}
+
+	s.readingEnds = append(s.readingEnds, len(s.readings))
+}
+
+func (s *eventStore) addReading(reading coreDtos.BaseReading) readingRef {
+	// Null readings can't be recognized other than by their empty value, so those are kept whole along with
+	// the readings that don't fit the columns.
+	if reading.Tags != nil || reading.BinaryValue != nil || len(reading.MediaType) > 0 || reading.ObjectValue != nil ||
+		len(reading.Value) == 0 {
+		s.wholeReadings = append(s.wholeReadings, reading)
+		return readingRef{column: -1, row: int32(len(s.wholeReadings) - 1)}
+	}
+
+	key := resourceColumnKey{
+		deviceName:   reading.DeviceName,
+		profileName:  reading.ProfileName,
+		resourceName: reading.ResourceName,
+		valueType:    reading.ValueType,
+		units:        reading.Units,
+	}
+
+	index, ok := s.columnIndex[key]
+	if !ok {
+		index = len(s.columns)
+		s.columnIndex[key] = index
+		s.columns = append(s.columns, &resourceColumn{
+			resourceColumnKey: resourceColumnKey{
+				deviceName:   s.intern(key.deviceName),
+				profileName:  s.intern(key.profileName),
+				resourceName: s.intern(key.resourceName),
+				valueType:    s.intern(key.valueType),
+				units:        s.intern(key.units),
+			},
+		})
+	}
+
+	column := s.columns[index]
+	column.ids = appendSparse(column.ids, len(column.origins), reading.Id)
+	column.origins = append(column.origins, reading.Origin)
+	column.values = append(column.values, reading.Value)
+
+	return readingRef{column: int32(index), row: int32(len(column.origins) - 1)}
+}
+
+// appendSparse appends the value to the sparse column holding the count values before it. The column is left nil
+// while all of its values are empty.
+func appendSparse(column []string, count int, value string) []string {
+	if column == nil {
+		if len(value) == 0 {
+			return nil
+		}
+
+		column = make([]string, count, count+1)
+	}
+
+	return append(column, value)
+}
+
+// sparseValue returns the value at the index of the sparse column
+func sparseValue(column []string, index int) string {
+	if column == nil {
+		return ""
+	}
+
+	return column[index]
+}
+
+// len returns the number of Events in the store, which may be nil
+func (s *eventStore) len() int {
+	if s == nil {
+		return 0
+	}
+
+	return len(s.origins)
+}
+
+// id returns the Id of the Event at the index, which is empty for Events recorded without one
+func (s *eventStore) id(index int) string {
+	return sparseValue(s.ids, index)
+}
+
+// event rebuilds the Event at the index
+func (s *eventStore) event(index int) coreDtos.Event {
+	event := coreDtos.Event{
+		Id:          s.id(index),
+		DeviceName:  s.deviceNames[index],
+		ProfileName: s.profileNames[index],
+		SourceName:  s.sourceNames[index],
+		Origin:      s.origins[index],
+		Tags:        s.tags[index],
+	}
+	event.ApiVersion = sparseValue(s.apiVersions, index)
+
+	start := 0
+	if index > 0 {
+		start = s.readingEnds[index-1]
+	}
+
+	end := s.readingEnds[index]
+	if end > start {
+		event.Readings = make([]coreDtos.BaseReading, 0, end-start)
+		for _, ref := range s.readings[start:end] {
+			event.Readings = append(event.Readings, s.reading(ref))
+		}
+	}
+
+	return event
+}
+
+func (s *eventStore) reading(ref readingRef) coreDtos.BaseReading {
+	if ref.column < 0 {
+		return s.wholeReadings[ref.row]
+	}
+
+	column := s.columns[ref.column]
+	return coreDtos.BaseReading{
+		Id:            sparseValue(column.ids, int(ref.row)),
+		Origin:        column.origins[ref.row],
+		DeviceName:    column.deviceName,
+		ResourceName:  column.resourceName,
+		ProfileName:   column.profileName,
+		ValueType:     column.valueType,
+		Units:         column.units,
+		SimpleReading: coreDtos.SimpleReading{Value: column.values[ref.row]},
+	}
+}
+
+// events rebuilds all the Events in the store, which may be nil
+func (s *eventStore) events() []coreDtos.Event {
+	if s.len() == 0 {
+		return nil
+	}
+
+	events := make([]coreDtos.Event, 0, s.len())
+	for index := range s.len() {
+		events = append(events, s.event(index))
+	}
+
+	return events
+}
+
+// uniqueDeviceNames returns the names of the devices which have Events in the store, in order of first appearance
+func (s *eventStore) uniqueDeviceNames() []string {
+	if s == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range s.deviceNames {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// eachReadingValue calls the function with the device, resource and value of each Reading for the device and resource,
+// either of which match all when empty, until the function returns false. The resource columns are scanned first
+// followed by the whole Readings.
+func (s *eventStore) eachReadingValue(deviceName string, resourceName string, fn func(deviceName string, resourceName string, value string) bool) {
+	if s == nil {
+		return
+	}
+
+	for _, column := range s.columns {
+		if (len(deviceName) > 0 && column.deviceName != deviceName) ||
+			(len(resourceName) > 0 && column.resourceName != resourceName) {
+			continue
+		}
+
+		for _, value := range column.values {
+			if !fn(column.deviceName, column.resourceName, value) {
+				return
+			}
+		}
+	}
+
+	for _, reading := range s.wholeReadings {
+		if (len(deviceName) > 0 && reading.DeviceName != deviceName) ||
+			(len(resourceName) > 0 && reading.ResourceName != resourceName) {
+			continue
+		}
+
+		if !fn(reading.DeviceName, reading.ResourceName, reading.Value) {
+			return
+		}
+	}
+}
+
+// eachReadingOrigin calls the function with the device, resource and origin of every Reading in the store. The
+// resource columns are scanned first followed by the whole Readings, so the origins aren't in recorded order.
+func (s *eventStore) eachReadingOrigin(fn func(deviceName string, resourceName string, origin int64)) {
+	if s == nil {
+		return
+	}
+
+	for _, column := range s.columns {
+		for _, origin := range column.origins {
+			fn(column.deviceName, column.resourceName, origin)
+		}
+	}
+
+	for _, reading := range s.wholeReadings {
+		fn(reading.DeviceName, reading.ResourceName, reading.Origin)
+	}
+}
+
+// memoryUsage accumulates the used and allocated size of the arrays holding recorded data
+type memoryUsage struct {
+	used      int64
+	allocated int64
+}
+
+// addSlice adds the used and allocated size of the slice's array
+func addSlice[T any](usage *memoryUsage, slice []T) {
+	size := int64(unsafe.Sizeof(*new(T)))
+	usage.used += int64(len(slice)) * size
+	usage.allocated += int64(cap(slice)) * size
+}
+
+// clip returns the slice in an array of exactly its length, releasing the unused capacity left by appending
+func clip[T any](slice []T) []T {
+	if cap(slice) == len(slice) {
+		return slice
+	}
+
+	clipped := make([]T, len(slice))
+	copy(clipped, slice)
+	return clipped
+}
+
+// memoryUsage adds the size of the store's arrays, which may be nil, to the usage
+func (s *eventStore) memoryUsage(usage *memoryUsage) {
+	if s == nil {
+		return
+	}
+
+	addSlice(usage, s.apiVersions)
+	addSlice(usage, s.ids)
+	addSlice(usage, s.deviceNames)
+	addSlice(usage, s.profileNames)
+	addSlice(usage, s.sourceNames)
+	addSlice(usage, s.origins)
+	addSlice(usage, s.readingEnds)
+	addSlice(usage, s.readings)
+	addSlice(usage, s.columns)
+	addSlice(usage, s.wholeReadings)
+
+	for _, column := range s.columns {
+		addSlice(usage, column.ids)
+		addSlice(usage, column.origins)
+		addSlice(usage, column.values)
+	}
+}
+
+// compact releases the unused capacity of the store's arrays, which may be nil. The Events are unchanged.
+func (s *eventStore) compact() {
+	if s == nil {
+		return
+	}
+
+	s.apiVersions = clip(s.apiVersions)
+	s.ids = clip(s.ids)
+	s.deviceNames = clip(s.deviceNames)
+	s.profileNames = clip(s.profileNames)
+	s.sourceNames = clip(s.sourceNames)
+	s.origins = clip(s.origins)
+	s.readingEnds = clip(s.readingEnds)
+	s.readings = clip(s.readings)
+	s.columns = clip(s.columns)
+	s.wholeReadings = clip(s.wholeReadings)
+
+	for _, column := range s.columns {
+		column.ids = clip(column.ids)
+		column.origins = clip(column.origins)
+		column.values = clip(column.values)
+	}
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStore(t *testing.T) {
+	first := coreDtos.NewEvent(expectedProfileName, "D1", expectedSourceName)
+	_ = first.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21))
+	_ = first.AddSimpleReading("Humidity", common.ValueTypeInt32, int32(50))
+	first.Readings[1].Units = "%"
+	first.Tags = coreDtos.Tags{"site": "north"}
+
+	second := coreDtos.NewEvent(expectedProfileName, "D2", expectedSourceName)
+	_ = second.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(30))
+	second.AddBinaryReading("Image", []byte{1, 2, 3}, "image/png")
+	second.AddObjectReading("Status", map[string]any{"on": true})
+
+	third := coreDtos.NewEvent(expectedProfileName, "D1", expectedSourceName)
+	_ = third.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(22))
+	third.Readings[0].Tags = coreDtos.Tags{"calibrated": true}
+
+	events := []coreDtos.Event{first, second, third}
+	store := newEventStore(events)
+
+	require.Equal(t, 3, store.len())
+	assert.Equal(t, events, store.events())
+	assert.Equal(t, second, store.event(1))
+	assert.Equal(t, []string{"D1", "D2"}, store.uniqueDeviceNames())
+
+	// The simple readings are held in per resource columns and the others whole
+	assert.Len(t, store.columns, 3)
+	assert.Len(t, store.wholeReadings, 3)
+
+	var values []string
+	store.eachReadingValue("D1", "Temperature", func(_ string, _ string, value string) bool {
+		values = append(values, value)
+		return true
+	})
+	assert.Equal(t, []string{"21", "22"}, values)
+
+	count := 0
+	store.eachReadingValue("", "", func(_ string, _ string, _ string) bool {
+		count++
+		return count < 2
+	})
+	assert.Equal(t, 2, count)
+}
+
+func TestEventStore_Empty(t *testing.T) {
+	var store *eventStore
+	assert.Zero(t, store.len())
+	assert.Nil(t, store.events())
+	assert.Nil(t, store.uniqueDeviceNames())
+
+	store = newEventStore(nil)
+	assert.Zero(t, store.len())
+	assert.Nil(t, store.events())
+
+	store.add(coreDtos.Event{})
+	assert.Equal(t, []coreDtos.Event{{}}, store.events())
+}
+
+func TestEventStore_Sparse(t *testing.T) {
+	stripped := coreDtos.NewEvent(expectedProfileName, "D1", expectedSourceName)
+	_ = stripped.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21))
+	stripped = stripEvent(stripped)
+
+	full := coreDtos.NewEvent(expectedProfileName, "D1", expectedSourceName)
+	_ = full.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(22))
+
+	// The sparse columns aren't held until a value is set, after which the earlier rows are empty
+	store := newEventStore([]coreDtos.Event{stripped})
+	assert.Nil(t, store.ids)
+	assert.Nil(t, store.apiVersions)
+	assert.Nil(t, store.columns[0].ids)
+
+	store.add(full)
+	assert.Equal(t, []coreDtos.Event{stripped, full}, store.events())
+	assert.Equal(t, "", store.id(0))
+	assert.Equal(t, full.Id, store.id(1))
+
+	// Stripped Events don't hold the id and API version columns
+	var strippedUsage, fullUsage memoryUsage
+	newEventStore([]coreDtos.Event{stripped, stripped}).memoryUsage(&strippedUsage)
+	newEventStore([]coreDtos.Event{full, full}).memoryUsage(&fullUsage)
+	assert.Less(t, strippedUsage.used, fullUsage.used)
+}
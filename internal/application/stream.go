@@ -0,0 +1,524 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/google/uuid"
+)
+
+const (
+	// ReplaySourcesAppSetting is the comma separated list of URL prefixes recordings may be streamed from for replay
+	ReplaySourcesAppSetting = "ReplaySources"
+
+	streamRecordedEventsField     = "recordedEvents"
+	streamReadingsOnlyEventsField = "readingsOnlyEvents"
+	streamMessagesField           = "messages"
+	streamDecodeFailed            = "failed to decode streamed event: %v"
+	// maxStreamRedirects is the most redirects followed when requesting a streamed recording, as for http.Client
+	maxStreamRedirects = 10
+)
+
+var streamReplayDisabled = fmt.Errorf("streamed replay is disabled since the %s App Setting isn't set", ReplaySourcesAppSetting)
+var streamSourceNotAllowed = fmt.Errorf("SourceURL isn't within the URLs allow-listed by the %s App Setting", ReplaySourcesAppSetting)
+var invalidStreamSourceURL = errors.New("invalid SourceURL, must be an absolute http or https URL")
+var streamReplayOptionsError = errors.New("ShadowMode, Telemetry, SystemEvents, LatencyTopic, UseEnvelopeTiming, StartAnnotation, DevicePriorities, Warmup, SimulationServiceName, TimeWarpDuration, AlignTimeOfDay, FanOut, Standby, PublishWorkers, ValueBounds and Breakpoints can't be used when streaming a replay")
+var streamProvisionError = fmt.Errorf("%s of %s can't be used when streaming a replay since the recorded devices aren't known up front",
+	ReplayValidationPolicyAppSetting, validationPolicyProvision)
+var streamOpaqueMessagesError = errors.New("streamed recording contains opaque messages, which can only be replayed once imported")
+
+// startStreamedReplay starts a replay which downloads the recording from the request's SourceURL and publishes each
+// Event as soon as it is decoded, so huge recordings don't need to be imported first. The source is requested before
+// returning, so an unreachable or missing recording fails the start of the replay.
+// Must be called while holding the recording mutex.
+func (m *dataManager) startStreamedReplay(request dtos.ReplayRequest, policy *publishPolicy) error {
+	if request.ShadowMode || request.Telemetry || request.SystemEvents || len(request.LatencyTopic) > 0 ||
+		request.UseEnvelopeTiming || len(request.StartAnnotation) > 0 || len(request.DevicePriorities) > 0 ||
+		len(request.Warmup) > 0 || len(request.SimulationServiceName) > 0 || request.TimeWarpDuration > 0 ||
+		request.AlignTimeOfDay || request.FanOut > 0 || request.Standby || request.PublishWorkers > 0 ||
+		len(request.ValueBounds) > 0 || len(request.Breakpoints) > 0 {
+		return streamReplayOptionsError
+	}
+
+	if err := m.checkReplaySource(request.SourceURL); err != nil {
+		return err
+	}
+
+	sinks, err := m.newReplaySinks(request, policy)
+	if err != nil {
+		return err
+	}
+
+	validator, err := m.newReplayValidator(m.sessionLogger(request.Label, request.CorrelationID))
+	if err != nil {
+		return err
+	}
+
+	if validator != nil && validator.policy == validationPolicyProvision {
+		return streamProvisionError
+	}
+
+	if err := m.resetReplayState(request); err != nil {
+		return err
+	}
+	m.replaySinks = sinks
+
+	stream, err := m.openEventStream(request.SourceURL)
+	if err != nil {
+		m.abortReplayStart()
+		return err
+	}
+
+	go m.replayStreamedEvents(request, validator, sinks, stream)
+
+	return nil
+}
+
+// checkReplaySource returns an error if the source URL isn't an http or https URL within one of the URL prefixes
+// allow-listed by the ReplaySources App Setting, so the service can't be used to reach arbitrary hosts.
+func (m *dataManager) checkReplaySource(sourceURL string) error {
+	source, err := url.Parse(sourceURL)
+	if err != nil {
+		return invalidStreamSourceURL
+	}
+
+	return m.checkReplaySourceURL(source)
+}
+
+// checkReplaySourceURL returns an error if the parsed source URL isn't allowed, see checkReplaySource. The source
+// must have the scheme and host of an allow-listed prefix and its path must be within the prefix's path, ending at a
+// path segment, so neither other hosts, i.e. host.evil.com for host, nor other paths, i.e. using .., can be reached.
+func (m *dataManager) checkReplaySourceURL(source *url.URL) error {
+	var allowed []*url.URL
+	for _, prefix := range strings.Split(m.appSvc.ApplicationSettings()[ReplaySourcesAppSetting], ",") {
+		if prefix = strings.TrimSpace(prefix); len(prefix) == 0 {
+			continue
+		}
+
+		// Invalid prefixes can't allow any source
+		if prefixURL, err := url.Parse(prefix); err == nil {
+			allowed = append(allowed, prefixURL)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return streamReplayDisabled
+	}
+
+	if (source.Scheme != "http" && source.Scheme != "https") || len(source.Host) == 0 {
+		return invalidStreamSourceURL
+	}
+
+	// Credentials in the source could be used to disguise its host
+	if source.User != nil || hasDotSegment(source.Path) {
+		return streamSourceNotAllowed
+	}
+
+	sourcePath := cleanURLPath(source.Path)
+	for _, prefix := range allowed {
+		if prefix.Scheme != source.Scheme || !strings.EqualFold(prefix.Host, source.Host) || prefix.User != nil {
+			continue
+		}
+
+		prefixPath := cleanURLPath(prefix.Path)
+		if prefixPath == "/" || sourcePath == prefixPath || strings.HasPrefix(sourcePath, prefixPath+"/") {
+			return nil
+		}
+	}
+
+	return streamSourceNotAllowed
+}
+
+// cleanURLPath returns the URL path without redundant separators, as an absolute path
+func cleanURLPath(urlPath string) string {
+	return path.Clean("/" + urlPath)
+}
+
+// hasDotSegment returns true if the URL path has a . or .. segment, which the host may resolve differently
+func hasDotSegment(urlPath string) bool {
+	for _, segment := range strings.Split(urlPath, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// eventStream decodes the recorded Events from a downloaded recording one at a time
+type eventStream struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+	// done is set once the end of the recorded Events has been reached
+	done bool
+	// readingsOnly is set when the Events are streamed as the reading tuples of a readings-only recording
+	readingsOnly bool
+}
+
+// openEventStream requests the recording from the source URL and positions the stream at the first recorded Event.
+// The request is canceled along with the replay. Only the time to receive the response headers is limited, since
+// downloading the recording takes as long as the replay.
+func (m *dataManager) openEventStream(sourceURL string) (*eventStream, error) {
+	request, err := http.NewRequestWithContext(m.replayContext, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = m.appSvc.RequestTimeout()
+	// Each redirect is checked against the allow-list, so an allowed host can't redirect to any other address
+	client := &http.Client{Transport: transport, CheckRedirect: func(request *http.Request, via []*http.Request) error {
+		if len(via) >= maxStreamRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxStreamRedirects)
+		}
+		return m.checkReplaySourceURL(request.URL)
+	}}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to request recording from %s: %v", sourceURL, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("unable to request recording from %s: unexpected status %s", sourceURL, response.Status)
+	}
+
+	stream, err := newEventStream(response.Body)
+	if err != nil {
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("unable to decode recording from %s: %v", sourceURL, err)
+	}
+
+	return stream, nil
+}
+
+// newEventStream returns the stream for the recording in the JSON export format, which is uncompressed when gzip or
+// zlib compressed. The fields before the recorded Events are skipped, since they are small compared to the Events,
+// and reading stops at the end of the Events, so the Devices and Profiles which follow them aren't downloaded.
+func newEventStream(body io.ReadCloser) (*eventStream, error) {
+	reader, err := uncompressStream(bufio.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	stream := &eventStream{body: body, decoder: json.NewDecoder(reader)}
+	if err := stream.expectDelim('{'); err != nil {
+		return nil, err
+	}
+
+	for stream.decoder.More() {
+		token, err := stream.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token %v, expected field name", token)
+		}
+
+		// Readings-only recordings are exported with their Events as reading tuples, which follow the empty recorded
+		// Events
+		readingsOnly := strings.EqualFold(key, streamReadingsOnlyEventsField)
+		if readingsOnly || strings.EqualFold(key, streamRecordedEventsField) {
+			started, err := stream.startEvents()
+			if err != nil || started {
+				stream.readingsOnly = readingsOnly
+				return stream, err
+			}
+			continue
+		}
+
+		var value json.RawMessage
+		if err := stream.decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		if strings.EqualFold(key, streamMessagesField) && string(value) != "null" && string(value) != "[]" {
+			return nil, streamOpaqueMessagesError
+		}
+	}
+
+	// A recording without Events replays nothing
+	stream.done = true
+	return stream, nil
+}
+
+// uncompressStream returns a reader which uncompresses the data if it starts with the gzip or zlib magic bytes
+func uncompressStream(reader *bufio.Reader) (io.Reader, error) {
+	header, _ := reader.Peek(2)
+	if len(header) < 2 {
+		return reader, nil
+	}
+
+	if header[0] == 0x1f && header[1] == 0x8b {
+		return gzip.NewReader(reader)
+	}
+
+	// zlib uses deflate (low nibble 8) with the header check bits making the first two bytes a multiple of 31
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(reader)
+	}
+
+	return reader, nil
+}
+
+// startEvents positions the stream at the first Event of the array, returning false if the array is null
+func (s *eventStream) startEvents() (bool, error) {
+	token, err := s.decoder.Token()
+	if err != nil {
+		return false, err
+	}
+
+	if token == nil {
+		return false, nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return false, fmt.Errorf("unexpected token %v, expected start of %s array", token, streamRecordedEventsField)
+	}
+
+	return true, nil
+}
+
+// next returns the next recorded Event, or io.EOF once all the Events have been read
+func (s *eventStream) next() (coreDtos.Event, error) {
+	if s.done || !s.decoder.More() {
+		s.done = true
+		return coreDtos.Event{}, io.EOF
+	}
+
+	if s.readingsOnly {
+		compact := dtos.ReadingsOnlyEvent{}
+		if err := s.decoder.Decode(&compact); err != nil {
+			return coreDtos.Event{}, err
+		}
+		return utils.ExpandEvent(compact), nil
+	}
+
+	event := coreDtos.Event{}
+	if err := s.decoder.Decode(&event); err != nil {
+		return coreDtos.Event{}, err
+	}
+
+	return event, nil
+}
+
+func (s *eventStream) expectDelim(expected json.Delim) error {
+	token, err := s.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("unexpected token %v, expected '%s'", token, expected.String())
+	}
+
+	return nil
+}
+
+func (s *eventStream) close() {
+	_ = s.body.Close()
+}
+
+// replayStreamedEvents publishes the Events as they are decoded from the stream, paced by their origins. Each repeat
+// downloads the recording again rather than holding the Events in memory.
+func (m *dataManager) replayStreamedEvents(request dtos.ReplayRequest, validator *replayValidator,
+	sinks []*replaySinkState, stream *eventStream) {
+	var previousEventTime int64
+	firstEvent := true
+	lc := m.sessionLogger(request.Label, request.CorrelationID)
+
+	defer func() {
+		if stream != nil {
+			stream.close()
+		}
+	}()
+
+	// Replay Count of zero defaults to 1.
+	replayCount := 1
+	if request.RepeatCount > 0 {
+		replayCount = request.RepeatCount
+	}
+
+	var script *replayScript
+	if len(request.Script) > 0 {
+		var err error
+		if script, err = newReplayScript(m.replayContext, request.Script); err != nil {
+			m.setReplayError(fmt.Errorf(replayScriptFailed, err), true)
+			return
+		}
+		defer script.close()
+	}
+
+	// The devices aren't known until after the Events, so the service names for the topics are looked up as needed
+	serviceNames := make(map[string]string)
+
+	lc.Debugf("ARR Replay: Streamed replay from %s starting with Replay Rate of %v and Repeat Count of %d ",
+		request.SourceURL, request.ReplayRate, replayCount)
+
+	for i := 0; i < replayCount; i++ {
+		if i > 0 {
+			stream.close()
+
+			var err error
+			stream, err = m.openEventStream(request.SourceURL)
+			if err != nil {
+				stream = nil
+				if !m.replayStopped(lc) {
+					m.setReplayError(err, true)
+				}
+				return
+			}
+		}
+
+		iteration := m.startReplayIteration(i+1, request.ReplayRate)
+
+		for {
+			if m.replayStopped(lc) {
+				return
+			}
+
+			replayEvent, err := stream.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				// Canceling the replay also fails the read, in which case the state is already updated
+				if !m.replayStopped(lc) {
+					m.setReplayError(fmt.Errorf(streamDecodeFailed, err), true)
+				}
+				return
+			}
+			restoreEvent(&replayEvent)
+
+			if script != nil {
+				var replay bool
+				var err error
+				replayEvent, replay, err = script.evaluate(replayEvent)
+				if err != nil {
+					// Canceling the replay also stops the script, in which case the state is already updated
+					if !m.replayStopped(lc) {
+						m.setReplayError(fmt.Errorf(replayScriptFailed, err), true)
+					}
+					return
+				}
+
+				if !replay {
+					lc.Debugf("ARR Replay: Event for device %s skipped by replay script", replayEvent.DeviceName)
+					continue
+				}
+			}
+
+			if validator != nil {
+				if err := validator.validate(replayEvent); err != nil {
+					if !errors.Is(err, eventNotValidError) || validator.policy == validationPolicyFail {
+						m.setReplayError(fmt.Errorf(replayValidationFailed, err), true)
+						return
+					}
+
+					lc.Debugf("ARR Replay: Event skipped: %v", err)
+					m.incrementReplaySkippedEventCount()
+					continue
+				}
+			}
+
+			scheduledAt := iteration.scheduledAt(replayEvent.Origin)
+
+			// Send the first event immediately and then wait appropriate time between events
+			if firstEvent {
+				firstEvent = false
+			} else {
+				delay := time.Duration(float32(replayEvent.Origin-previousEventTime) * (1 / request.ReplayRate))
+
+				if delay > m.maxReplayDelay {
+					m.setReplayError(fmt.Errorf(maxReplayDelayExceeded, delay.String(), m.maxReplayDelay.String()), true)
+					return
+				}
+
+				m.clock.Sleep(delay)
+			}
+
+			previousEventTime = replayEvent.Origin
+
+			serviceName, found := serviceNames[replayEvent.DeviceName]
+			if !found {
+				serviceName = m.lookupServiceName(replayEvent.DeviceName, lc)
+				serviceNames[replayEvent.DeviceName] = serviceName
+			}
+
+			topic := buildEventTopic(serviceName, replayEvent)
+
+			newOrigin := m.clock.Now().UnixNano()
+			replayEvent.Origin = newOrigin
+			replayEvent.Id = uuid.NewString()
+			for index := range replayEvent.Readings {
+				replayEvent.Readings[index].Origin = newOrigin
+				replayEvent.Readings[index].Id = uuid.NewString()
+			}
+
+			published, err := m.publishToSinks(sinks, lc, topic, newReplayAddEventRequest(replayEvent, request.CorrelationID))
+			if err != nil {
+				m.setReplayError(fmt.Errorf(replayPublishFailed, err), true)
+				return
+			}
+
+			if !published {
+				continue
+			}
+
+			lc.Debugf("ARR Replay: Replayed streamed Event to topic: %s", topic)
+
+			iteration.published(scheduledAt, m.clock.Now())
+			m.incrementReplayedEventCount()
+		}
+
+		m.completeReplayIteration(iteration)
+	}
+
+	m.completeReplay(lc)
+}
+
+// lookupServiceName returns the name of the device's service from Core Metadata, or unknownServiceName if the device
+// can't be loaded, matching the topic used for replayed Events of devices missing from the recorded data.
+func (m *dataManager) lookupServiceName(deviceName string, lc logger.LoggingClient) string {
+	response, err := m.appSvc.DeviceClient().DeviceByName(context.Background(), deviceName)
+	if err != nil {
+		lc.Debugf("ARR Replay: Unable to load device %s for streamed replay: %v", deviceName, err)
+		return unknownServiceName
+	}
+
+	return response.Device.ServiceName
+}
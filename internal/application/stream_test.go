@@ -0,0 +1,271 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_CheckReplaySource(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Sources       string
+		SourceURL     string
+		ExpectedError error
+	}{
+		{"Allowed", "http://other/, https://storage/recordings/", "https://storage/recordings/big.json.gz", nil},
+		{"Disabled", "", "https://storage/recordings/big.json.gz", streamReplayDisabled},
+		{"Not allowed", "https://storage/recordings/", "https://storage/private/big.json.gz", streamSourceNotAllowed},
+		{"Not http", "file:///", "file:///etc/passwd", invalidStreamSourceURL},
+		{"Not absolute", "/recordings", "/recordings/big.json", invalidStreamSourceURL},
+		{"Allowed - host", "https://storage", "https://storage/any/big.json", nil},
+		{"Allowed - prefix without slash", "https://storage/recordings", "https://storage/recordings/big.json", nil},
+		{"Not allowed - host suffix", "https://storage", "https://storage.evil.com/big.json", streamSourceNotAllowed},
+		{"Not allowed - path suffix", "https://storage/recordings", "https://storage/recordings-private/big.json",
+			streamSourceNotAllowed},
+		{"Not allowed - userinfo", "https://storage/", "https://storage@evil/recordings/big.json",
+			streamSourceNotAllowed},
+		{"Not allowed - dot segments", "https://storage/recordings/", "https://storage/recordings/../private/big.json",
+			streamSourceNotAllowed},
+		{"Not allowed - escaped dot segments", "https://storage/recordings/",
+			"https://storage/recordings/%2e%2e/private/big.json", streamSourceNotAllowed},
+		{"Not allowed - scheme", "https://storage/", "http://storage/big.json", streamSourceNotAllowed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ReplaySourcesAppSetting: test.Sources})
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+			err := target.checkReplaySource(test.SourceURL)
+			if test.ExpectedError != nil {
+				require.ErrorIs(t, err, test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDataManager_OpenEventStream_Redirect(t *testing.T) {
+	private := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"recordedEvents":[]}`))
+	}))
+	defer private.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/recordings/moved.json":
+			http.Redirect(writer, request, "/recordings/big.json", http.StatusFound)
+		case "/recordings/big.json":
+			_, _ = writer.Write([]byte(`{"recordedEvents":[]}`))
+		default:
+			http.Redirect(writer, request, private.URL+"/secret.json", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ReplaySourcesAppSetting: server.URL + "/recordings/"})
+	mockSdk.On("RequestTimeout").Return(5 * time.Second)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.replayContext = context.Background()
+
+	stream, err := target.openEventStream(server.URL + "/recordings/moved.json")
+	require.NoError(t, err)
+	require.NoError(t, stream.body.Close())
+
+	_, err = target.openEventStream(server.URL + "/recordings/elsewhere.json")
+	require.ErrorContains(t, err, streamSourceNotAllowed.Error())
+}
+
+func TestNewEventStream(t *testing.T) {
+	events := []coreDtos.Event{
+		coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+		coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName),
+	}
+	recording, err := json.Marshal(dtos.RecordedData{Name: "big", RecordedEvents: events})
+	require.NoError(t, err)
+
+	// Readings-only recordings are exported with the reading tuples following the empty recorded Events
+	stripped := []coreDtos.Event{stripEvent(newReadingsOnlyTestEvent(t)), stripEvent(newReadingsOnlyTestEvent(t))}
+	compact, ok := utils.CompactEvents(stripped)
+	require.True(t, ok)
+	readingsOnly, err := json.Marshal(dtos.RecordedData{Name: "compact", ReadingsOnlyEvents: compact})
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name           string
+		Data           []byte
+		ExpectedEvents []coreDtos.Event
+		ExpectedError  error
+	}{
+		{"JSON", recording, events, nil},
+		{"Gzip", gzipData(t, recording), events, nil},
+		{"Readings only", readingsOnly, stripped, nil},
+		{"No events", []byte(`{"name":"empty","recordedEvents":null}`), nil, nil},
+		{"Missing events", []byte(`{"name":"empty"}`), nil, nil},
+		{"Opaque", []byte(`{"messages":[{"payload":"e30="}],"recordedEvents":null}`), nil, streamOpaqueMessagesError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stream, err := newEventStream(io.NopCloser(bytes.NewReader(test.Data)))
+			if test.ExpectedError != nil {
+				require.ErrorIs(t, err, test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var decoded []coreDtos.Event
+			for {
+				event, err := stream.next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				decoded = append(decoded, event)
+			}
+
+			assert.Equal(t, test.ExpectedEvents, decoded)
+		})
+	}
+}
+
+func TestDataManager_StartReplay_Streamed(t *testing.T) {
+	recording, err := json.Marshal(dtos.RecordedData{
+		RecordedEvents: expectedEventData,
+		Devices:        []coreDtos.Device{{Name: expectedDeviceName, ServiceName: "recordedService"}},
+	})
+	require.NoError(t, err)
+	compressed := gzipData(t, recording)
+
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/recordings/big.json.gz" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		downloads.Add(1)
+		_, _ = writer.Write(compressed)
+	}))
+	defer server.Close()
+
+	expectedTopic := buildEventTopic(expectedServiceName, expectedEventData[0])
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, expectedDeviceName).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: expectedDeviceName, ServiceName: expectedServiceName}}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ReplaySourcesAppSetting: server.URL + "/recordings/"})
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("RequestTimeout").Return(5 * time.Second)
+	mockSdk.On("PublishWithTopic", expectedTopic, mock.Anything, common.ContentTypeJSON).Return(nil)
+
+	// No recording is needed since the recording is streamed
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	err = target.StartReplay(dtos.ReplayRequest{ReplayRate: 1000, RepeatCount: 2, SourceURL: server.URL + "/recordings/missing.json"})
+	require.ErrorContains(t, err, "404")
+	assert.False(t, target.ReplayStatus().Running)
+
+	err = target.StartReplay(dtos.ReplayRequest{ReplayRate: 1000, RepeatCount: 2, SourceURL: server.URL + "/recordings/big.json.gz"})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	require.Empty(t, status.Message)
+	assert.Equal(t, 2*len(expectedEventData), status.EventCount)
+	assert.Equal(t, 2, status.RepeatCount)
+	assert.Equal(t, int32(2), downloads.Load())
+	mockSdk.AssertNumberOfCalls(t, "PublishWithTopic", 2*len(expectedEventData))
+	// The service name is only looked up once per device
+	mockDeviceClient.AssertNumberOfCalls(t, "DeviceByName", 1)
+}
+
+func TestDataManager_StartReplay_StreamedOptions(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{
+		ReplaySourcesAppSetting:          "http://localhost/",
+		ReplayValidationPolicyAppSetting: validationPolicyProvision,
+	})
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, ShadowMode: true, SourceURL: "http://localhost/big.json"})
+	require.ErrorIs(t, err, streamReplayOptionsError)
+
+	err = target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, SourceURL: "http://localhost/big.json"})
+	require.ErrorIs(t, err, streamProvisionError)
+}
+
+func TestDataManager_LookupServiceName_NotFound(t *testing.T) {
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, expectedDeviceName).
+		Return(responses.DeviceResponse{}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "not found", nil))
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	assert.Equal(t, unknownServiceName, target.lookupServiceName(expectedDeviceName, logger.NewMockClient()))
+}
+
+func gzipData(t *testing.T, data []byte) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
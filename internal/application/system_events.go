@@ -0,0 +1,183 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"sort"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+)
+
+const (
+	// systemEventTopic is the topic pattern, relative to the base topic, Core Metadata publishes the device system
+	// events to, i.e. when a device is added, updated or removed
+	systemEventTopic = common.SystemEventPublishTopic + "/" + common.CoreMetaDataServiceKey + "/" +
+		common.DeviceSystemEventType + "/#"
+	// maxSystemEvents is the maximum number of system events captured per recording. Further system events are
+	// dropped.
+	maxSystemEvents = 10000
+)
+
+var systemEventsOpaqueError = errors.New("SystemEvents can't be used with Opaque, which records the system events anyway")
+var systemEventsReplayUnavailableError = errors.New("system events can't be replayed since background publishing is unavailable")
+
+// captureSystemEvent is the pipeline function which captures the Core Metadata device system events verbatim into
+// the current recording, stopping the pipeline for them so they aren't decoded as Events. Messages received on other
+// topics continue down the pipeline.
+func (m *dataManager) captureSystemEvent(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+	if !topicMatches(systemEventTopic, relativeMessageTopic(receivedTopic)) {
+		return true, data
+	}
+
+	payload, ok := data.([]byte)
+	if !ok {
+		return false, decodeDataNotBytesError
+	}
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.recordingStartedAt == nil {
+		return false, nil
+	}
+
+	lc := m.sessionLogger(m.recordingLabel, m.recordingCorrelationID)
+
+	if len(m.recordedSystemEvents) >= maxSystemEvents {
+		lc.Debugf("ARR System Events: system event limit of %d reached, message on topic '%s' not captured", maxSystemEvents, receivedTopic)
+		return false, nil
+	}
+
+	m.recordedSystemEvents = append(m.recordedSystemEvents, dtos.OpaqueMessage{
+		EnvelopeMetadata: dtos.EnvelopeMetadata{
+			ReceivedTopic: receivedTopic,
+			CorrelationID: ctx.CorrelationID(),
+			ContentType:   ctx.InputContentType(),
+			ReceivedAt:    m.clock.Now().UnixNano(),
+		},
+		// The payload is copied since the SDK may reuse it
+		Payload: append([]byte(nil), payload...),
+	})
+
+	lc.Debugf("ARR System Events: captured system event received on topic '%s'", receivedTopic)
+
+	return false, nil
+}
+
+// systemEventReplay replays the recorded system events in sequence with the Events, each before the first Event
+// recorded after it, so downstream services see the topology change before the data following it
+type systemEventReplay struct {
+	messages []dtos.OpaqueMessage
+	// startTime is the time of the Event each iteration starts from, before which the system events are skipped
+	startTime int64
+	next      int
+	// skipTo, if set, skips the system events before the next Event's time since the replay jumped to it
+	skipTo bool
+}
+
+// newSystemEventReplay returns the system event replay for the request, or nil if the system events aren't replayed
+func newSystemEventReplay(request dtos.ReplayRequest, data *recordedData, startIndex int) *systemEventReplay {
+	if !request.SystemEvents || len(data.SystemEvents) == 0 {
+		return nil
+	}
+
+	// Merged imports may interleave the system events of the recordings merged
+	messages := append([]dtos.OpaqueMessage(nil), data.SystemEvents...)
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].ReceivedAt < messages[j].ReceivedAt })
+
+	replay := &systemEventReplay{messages: messages}
+	if startIndex > 0 {
+		replay.startTime = recordedEventTime(data, startIndex, request.UseEnvelopeTiming)
+	}
+
+	return replay
+}
+
+// restart rewinds the system events for the next iteration of the replay
+func (r *systemEventReplay) restart() {
+	r.next = r.skipIndex(r.startTime)
+	r.skipTo = false
+}
+
+// jumped skips the system events recorded before the Event the replay jumped to
+func (r *systemEventReplay) jumped() {
+	r.skipTo = true
+}
+
+// due returns the system events to publish before the Event at the time given
+func (r *systemEventReplay) due(eventTime int64) []dtos.OpaqueMessage {
+	if r.skipTo {
+		r.next = r.skipIndex(eventTime)
+		r.skipTo = false
+	}
+
+	start := r.next
+	for r.next < len(r.messages) && r.messages[r.next].ReceivedAt <= eventTime {
+		r.next++
+	}
+
+	return r.messages[start:r.next]
+}
+
+// remaining returns the system events recorded after the last Event
+func (r *systemEventReplay) remaining() []dtos.OpaqueMessage {
+	start := r.next
+	r.next = len(r.messages)
+	return r.messages[start:]
+}
+
+func (r *systemEventReplay) skipIndex(eventTime int64) int {
+	return sort.Search(len(r.messages), func(i int) bool { return r.messages[i].ReceivedAt >= eventTime })
+}
+
+// publishDueSystemEvents publishes the system events once the workers, if any, have published the Events before them
+func (m *dataManager) publishDueSystemEvents(messages []dtos.OpaqueMessage, workers *publishWorkers,
+	lc logger.LoggingClient) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	if workers != nil {
+		if err := workers.flush(); err != nil {
+			return err
+		}
+	}
+
+	m.publishSystemEvents(messages, lc)
+	return nil
+}
+
+// publishSystemEvents publishes the system events verbatim to the topics they were received on. A system event that
+// fails to publish is logged and skipped rather than failing the replay.
+func (m *dataManager) publishSystemEvents(messages []dtos.OpaqueMessage, lc logger.LoggingClient) {
+	for _, message := range messages {
+		publishCtx := m.appSvc.BuildContext(message.CorrelationID, message.ContentType)
+		publishCtx.AddValue(opaqueTopicKey, relativeMessageTopic(message.ReceivedTopic))
+
+		if err := m.opaquePublisher.Publish(message.Payload, publishCtx); err != nil {
+			lc.Errorf("ARR Replay: failed to replay system event to topic %s: %v", message.ReceivedTopic, err)
+			continue
+		}
+
+		lc.Debugf("ARR Replay: Replayed system event to topic %s", message.ReceivedTopic)
+	}
+}
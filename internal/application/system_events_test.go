@@ -0,0 +1,215 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	interfaceMocks "github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_CaptureSystemEvent(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+
+	deviceTopic := "edgex/system-events/core-metadata/device/add/device-virtual/Random-Integer-Device"
+	profileTopic := "edgex/system-events/core-metadata/deviceprofile/add/core-metadata/Random-Integer-Device"
+
+	// System events received while no recording is running are dropped
+	continuePipeline, result := target.captureSystemEvent(telemetryTestContext(deviceTopic), []byte("added"))
+	require.False(t, continuePipeline)
+	require.Nil(t, result)
+	assert.Empty(t, target.recordedSystemEvents)
+
+	now := time.Now()
+	target.recordingStartedAt = &now
+
+	continuePipeline, result = target.captureSystemEvent(telemetryTestContext(deviceTopic), []byte("added"))
+	require.False(t, continuePipeline)
+	require.Nil(t, result)
+	require.Len(t, target.recordedSystemEvents, 1)
+
+	message := target.recordedSystemEvents[0]
+	assert.Equal(t, []byte("added"), message.Payload)
+	assert.Equal(t, deviceTopic, message.ReceivedTopic)
+	assert.Equal(t, "123", message.CorrelationID)
+	assert.Equal(t, common.ContentTypeJSON, message.ContentType)
+	assert.NotZero(t, message.ReceivedAt)
+	assert.Equal(t, 1, target.RecordingStatus().SystemEventCount)
+
+	// Only the device system events are captured, the rest continue down the pipeline
+	continuePipeline, result = target.captureSystemEvent(telemetryTestContext(profileTopic), []byte("profile"))
+	require.True(t, continuePipeline)
+	require.Equal(t, []byte("profile"), result)
+
+	continuePipeline, result = target.captureSystemEvent(telemetryTestContext(deviceTopic), "not bytes")
+	require.False(t, continuePipeline)
+	require.Equal(t, decodeDataNotBytesError, result)
+
+	// System events beyond the limit are dropped
+	target.recordedSystemEvents = make([]dtos.OpaqueMessage, maxSystemEvents)
+	continuePipeline, result = target.captureSystemEvent(telemetryTestContext(deviceTopic), []byte("added"))
+	require.False(t, continuePipeline)
+	require.Nil(t, result)
+	assert.Len(t, target.recordedSystemEvents, maxSystemEvents)
+}
+
+func newSystemEventMessage(payload string, receivedAt int64) dtos.OpaqueMessage {
+	return dtos.OpaqueMessage{
+		EnvelopeMetadata: dtos.EnvelopeMetadata{
+			ReceivedTopic: "edgex/system-events/core-metadata/device/" + payload,
+			CorrelationID: payload,
+			ContentType:   common.ContentTypeJSON,
+			ReceivedAt:    receivedAt,
+		},
+		Payload: []byte(payload),
+	}
+}
+
+func TestSystemEventReplay(t *testing.T) {
+	events := []coreDtos.Event{newAppendEvent("D1", 1000), newAppendEvent("D2", 3000), newAppendEvent("D3", 5000)}
+	data := &recordedData{
+		Events: newEventStore(events),
+		// Out of order as when merged from several imports
+		SystemEvents: []dtos.OpaqueMessage{newSystemEventMessage("add-D2", 2000), newSystemEventMessage("add-D1", 500),
+			newSystemEventMessage("delete", 6000), newSystemEventMessage("update-D3", 4000)},
+	}
+
+	payloads := func(messages []dtos.OpaqueMessage) []string {
+		var result []string
+		for _, message := range messages {
+			result = append(result, string(message.Payload))
+		}
+		return result
+	}
+
+	assert.Nil(t, newSystemEventReplay(dtos.ReplayRequest{}, data, 0))
+	assert.Nil(t, newSystemEventReplay(dtos.ReplayRequest{SystemEvents: true}, &recordedData{Events: data.Events}, 0))
+
+	target := newSystemEventReplay(dtos.ReplayRequest{SystemEvents: true}, data, 0)
+	require.NotNil(t, target)
+
+	target.restart()
+	assert.Equal(t, []string{"add-D1"}, payloads(target.due(1000)))
+	assert.Equal(t, []string{"add-D2"}, payloads(target.due(3000)))
+	assert.Equal(t, []string{"update-D3"}, payloads(target.due(5000)))
+	assert.Equal(t, []string{"delete"}, payloads(target.remaining()))
+
+	// Jumping skips the system events before the Event jumped to
+	target.restart()
+	target.jumped()
+	assert.Empty(t, target.due(3000))
+	assert.Equal(t, []string{"update-D3"}, payloads(target.due(5000)))
+
+	// Starting part way through skips the system events before the start
+	target = newSystemEventReplay(dtos.ReplayRequest{SystemEvents: true}, data, 1)
+	target.restart()
+	assert.Equal(t, []string{"update-D3"}, payloads(target.due(5000)))
+	target.restart()
+	assert.Empty(t, target.due(3000))
+}
+
+func TestDataManager_StartReplay_SystemEvents(t *testing.T) {
+	var publishedMutex sync.Mutex
+	var published []string
+	record := func(name string) {
+		publishedMutex.Lock()
+		defer publishedMutex.Unlock()
+		published = append(published, name)
+	}
+
+	mockContext := &mocks.AppFunctionContext{}
+	mockContext.On("AddValue", opaqueTopicKey, mock.Anything)
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ServiceName: expectedServiceName}}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("BuildContext", mock.Anything, mock.Anything).Return(mockContext)
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).Return(nil).
+		Run(func(args mock.Arguments) {
+			record(args.Get(1).(requests.AddEventRequest).Event.DeviceName)
+		})
+
+	mockPublisher := &mocks.BackgroundPublisher{}
+	mockPublisher.On("Publish", mock.Anything, mockContext).Return(nil).
+		Run(func(args mock.Arguments) {
+			record(string(args.Get(0).([]byte)))
+		})
+
+	mockClock := &interfaceMocks.Clock{}
+	mockClock.On("Now").Return(time.Now())
+	mockClock.On("Since", mock.Anything).Return(time.Second).Maybe()
+	mockClock.On("Sleep", mock.Anything)
+
+	target := NewManager(mockSdk, time.Minute, mockClock, mockPublisher, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore([]coreDtos.Event{newAppendEvent("D1", 1000), newAppendEvent("D2", 3000)}),
+		SystemEvents: []dtos.OpaqueMessage{newSystemEventMessage("add-D1", 500), newSystemEventMessage("add-D2", 2000),
+			newSystemEventMessage("delete-D1", 4000)},
+	}
+
+	// The workers are flushed before each system event, so the sequence holds when publishing with several
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, SystemEvents: true, PublishWorkers: 2})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Empty(t, target.ReplayStatus().Message)
+	mockSdk.AssertCalled(t, "BuildContext", "add-D1", common.ContentTypeJSON)
+	mockContext.AssertCalled(t, "AddValue", opaqueTopicKey, "system-events/core-metadata/device/add-D1")
+
+	publishedMutex.Lock()
+	defer publishedMutex.Unlock()
+	assert.Equal(t, []string{"add-D1", "D1", "add-D2", "D2", "delete-D1"}, published)
+}
+
+func TestDataManager_StartReplay_SystemEventsUnavailable(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{Events: newEventStore([]coreDtos.Event{newAppendEvent("D1", 1000)})}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, SystemEvents: true})
+	require.ErrorIs(t, err, systemEventsReplayUnavailableError)
+	assert.Nil(t, target.replayStartedAt)
+}
@@ -0,0 +1,164 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+)
+
+const (
+	// telemetryTopic is the topic pattern, relative to the base topic, the services publish their metrics to
+	telemetryTopic = common.MetricsPublishTopic + "/#"
+	// maxTelemetryMessages is the maximum number of telemetry messages captured per recording, so a chatty metrics
+	// configuration can't exhaust memory. Further telemetry messages are dropped.
+	maxTelemetryMessages = 10000
+	// telemetryWaitStep is the longest the telemetry replay sleeps before checking if the replay has been canceled
+	telemetryWaitStep = time.Second
+)
+
+var telemetryOpaqueError = errors.New("Telemetry can't be used with Opaque, which records the telemetry messages anyway")
+var telemetryReplayUnavailableError = errors.New("telemetry can't be replayed since background publishing is unavailable")
+var telemetryDailyReplayError = errors.New("Telemetry can't be used with AlignTimeOfDay")
+
+// captureTelemetry is the pipeline function which captures the service metrics telemetry messages verbatim into the
+// current recording, stopping the pipeline for them so they aren't decoded as Events. Messages received on other
+// topics continue down the pipeline.
+func (m *dataManager) captureTelemetry(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+	if !topicMatches(telemetryTopic, relativeMessageTopic(receivedTopic)) {
+		return true, data
+	}
+
+	payload, ok := data.([]byte)
+	if !ok {
+		return false, decodeDataNotBytesError
+	}
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	if m.recordingStartedAt == nil {
+		return false, nil
+	}
+
+	lc := m.sessionLogger(m.recordingLabel, m.recordingCorrelationID)
+
+	if len(m.recordedTelemetry) >= maxTelemetryMessages {
+		lc.Debugf("ARR Telemetry: telemetry limit of %d reached, message on topic '%s' not captured", maxTelemetryMessages, receivedTopic)
+		return false, nil
+	}
+
+	m.recordedTelemetry = append(m.recordedTelemetry, dtos.OpaqueMessage{
+		EnvelopeMetadata: dtos.EnvelopeMetadata{
+			ReceivedTopic: receivedTopic,
+			CorrelationID: ctx.CorrelationID(),
+			ContentType:   ctx.InputContentType(),
+			ReceivedAt:    m.clock.Now().UnixNano(),
+		},
+		// The payload is copied since the SDK may reuse it
+		Payload: append([]byte(nil), payload...),
+	})
+
+	lc.Debugf("ARR Telemetry: captured telemetry message received on topic '%s'", receivedTopic)
+
+	return false, nil
+}
+
+// telemetryReplay replays the recorded telemetry messages alongside the Events of each iteration
+type telemetryReplay struct {
+	// ctx is canceled once the replay ends
+	ctx      context.Context
+	messages []dtos.OpaqueMessage
+	// firstEventTime is the time of the first recorded Event, which the telemetry messages are timed against
+	firstEventTime int64
+}
+
+// newTelemetryReplay returns the telemetry replay for the request, or nil if the telemetry isn't replayed. The
+// telemetry is timed against the Event the replay starts from, with the messages before it skipped.
+func newTelemetryReplay(request dtos.ReplayRequest, data *recordedData, startIndex int) *telemetryReplay {
+	if !request.Telemetry || len(data.Telemetry) == 0 {
+		return nil
+	}
+
+	replay := &telemetryReplay{messages: data.Telemetry}
+	if data.Events.len() > 0 {
+		first := data.Events.event(startIndex)
+		replay.firstEventTime = first.Origin
+		if envelope, ok := data.Envelopes[first.Id]; ok && request.UseEnvelopeTiming {
+			replay.firstEventTime = envelope.ReceivedAt
+		}
+	} else {
+		replay.firstEventTime = data.Telemetry[0].ReceivedAt
+	}
+
+	if startIndex > 0 {
+		replay.messages = nil
+		for _, message := range data.Telemetry {
+			if message.ReceivedAt >= replay.firstEventTime {
+				replay.messages = append(replay.messages, message)
+			}
+		}
+	}
+
+	return replay
+}
+
+// startTelemetryReplay publishes the telemetry messages in the background, each at its offset from the first Event
+// at the replay rate from the start of the iteration. The returned channel is closed once all the messages have been
+// published or the replay has ended.
+func (m *dataManager) startTelemetryReplay(telemetry *telemetryReplay, iterationStart time.Time, rate float32,
+	lc logger.LoggingClient) <-chan struct{} {
+	done := make(chan struct{})
+	ctx := telemetry.ctx
+
+	go func() {
+		defer close(done)
+
+		published := 0
+		for _, message := range telemetry.messages {
+			due := iterationStart.Add(time.Duration(float64(message.ReceivedAt-telemetry.firstEventTime) / float64(rate)))
+			for wait := due.Sub(m.clock.Now()); wait > 0 && ctx.Err() == nil; wait = due.Sub(m.clock.Now()) {
+				m.clock.Sleep(min(wait, telemetryWaitStep))
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			publishCtx := m.appSvc.BuildContext(message.CorrelationID, message.ContentType)
+			publishCtx.AddValue(opaqueTopicKey, relativeMessageTopic(message.ReceivedTopic))
+
+			if err := m.opaquePublisher.Publish(message.Payload, publishCtx); err != nil {
+				lc.Errorf("ARR Replay: failed to replay telemetry message to topic %s: %v", message.ReceivedTopic, err)
+				continue
+			}
+
+			published++
+		}
+
+		lc.Debugf("ARR Replay: Replayed %d telemetry messages", published)
+	}()
+
+	return done
+}
@@ -0,0 +1,203 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func telemetryTestContext(receivedTopic string) *mocks.AppFunctionContext {
+	mockContext := &mocks.AppFunctionContext{}
+	mockContext.On("GetValue", interfaces.RECEIVEDTOPIC).Return(receivedTopic, true)
+	mockContext.On("CorrelationID").Return("123")
+	mockContext.On("InputContentType").Return(common.ContentTypeJSON)
+	return mockContext
+}
+
+func TestDataManager_CaptureTelemetry(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := NewManager(mockSdk, 0, clock.New(), nil, nil).(*dataManager)
+
+	metricTopic := "edgex/telemetry/core-data/EventsPersisted"
+	eventTopic := "edgex/events/device/svc/p/d/s"
+
+	// Telemetry received while no recording is running is dropped
+	continuePipeline, result := target.captureTelemetry(telemetryTestContext(metricTopic), []byte("metric"))
+	require.False(t, continuePipeline)
+	require.Nil(t, result)
+	assert.Empty(t, target.recordedTelemetry)
+
+	now := time.Now()
+	target.recordingStartedAt = &now
+
+	continuePipeline, result = target.captureTelemetry(telemetryTestContext(metricTopic), []byte("metric"))
+	require.False(t, continuePipeline)
+	require.Nil(t, result)
+	require.Len(t, target.recordedTelemetry, 1)
+
+	message := target.recordedTelemetry[0]
+	assert.Equal(t, []byte("metric"), message.Payload)
+	assert.Equal(t, metricTopic, message.ReceivedTopic)
+	assert.Equal(t, "123", message.CorrelationID)
+	assert.Equal(t, common.ContentTypeJSON, message.ContentType)
+	assert.NotZero(t, message.ReceivedAt)
+	assert.Equal(t, 1, target.RecordingStatus().TelemetryCount)
+
+	// Events continue down the pipeline to be decoded
+	continuePipeline, result = target.captureTelemetry(telemetryTestContext(eventTopic), []byte("event"))
+	require.True(t, continuePipeline)
+	require.Equal(t, []byte("event"), result)
+
+	continuePipeline, result = target.captureTelemetry(telemetryTestContext(metricTopic), "not bytes")
+	require.False(t, continuePipeline)
+	require.Equal(t, decodeDataNotBytesError, result)
+
+	// Telemetry beyond the limit is dropped
+	target.recordedTelemetry = make([]dtos.OpaqueMessage, maxTelemetryMessages)
+	continuePipeline, result = target.captureTelemetry(telemetryTestContext(metricTopic), []byte("metric"))
+	require.False(t, continuePipeline)
+	require.Nil(t, result)
+	assert.Len(t, target.recordedTelemetry, maxTelemetryMessages)
+}
+
+func TestNewTelemetryReplay(t *testing.T) {
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	event.Origin = 1000
+	telemetry := []dtos.OpaqueMessage{{EnvelopeMetadata: dtos.EnvelopeMetadata{ReceivedAt: 1500}}}
+
+	data := &recordedData{
+		Events:    newEventStore([]coreDtos.Event{event}),
+		Envelopes: map[string]dtos.EnvelopeMetadata{event.Id: {ReceivedAt: 1200}},
+		Telemetry: telemetry,
+	}
+
+	assert.Nil(t, newTelemetryReplay(dtos.ReplayRequest{}, data, 0))
+	assert.Nil(t, newTelemetryReplay(dtos.ReplayRequest{Telemetry: true}, &recordedData{Events: data.Events}, 0))
+
+	replay := newTelemetryReplay(dtos.ReplayRequest{Telemetry: true}, data, 0)
+	require.NotNil(t, replay)
+	assert.Equal(t, telemetry, replay.messages)
+	assert.Equal(t, int64(1000), replay.firstEventTime)
+
+	replay = newTelemetryReplay(dtos.ReplayRequest{Telemetry: true, UseEnvelopeTiming: true}, data, 0)
+	require.NotNil(t, replay)
+	assert.Equal(t, int64(1200), replay.firstEventTime)
+
+	replay = newTelemetryReplay(dtos.ReplayRequest{Telemetry: true}, &recordedData{Telemetry: telemetry}, 0)
+	require.NotNil(t, replay)
+	assert.Equal(t, int64(1500), replay.firstEventTime)
+}
+
+func TestDataManager_StartReplay_Telemetry(t *testing.T) {
+	event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	event.Origin = 1000
+
+	mockContext := &mocks.AppFunctionContext{}
+	mockContext.On("AddValue", opaqueTopicKey, mock.Anything)
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: expectedDeviceName, ServiceName: expectedServiceName}}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("BuildContext", mock.Anything, mock.Anything).Return(mockContext)
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).Return(nil)
+
+	mockPublisher := &mocks.BackgroundPublisher{}
+	mockPublisher.On("Publish", mock.Anything, mockContext).Return(nil)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), mockPublisher, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore([]coreDtos.Event{event}),
+		Telemetry: []dtos.OpaqueMessage{
+			{
+				EnvelopeMetadata: dtos.EnvelopeMetadata{ReceivedTopic: "edgex/telemetry/core-data/EventsPersisted",
+					CorrelationID: "1", ContentType: common.ContentTypeJSON, ReceivedAt: 1500},
+				Payload: []byte("metric"),
+			},
+		},
+	}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, RepeatCount: 2, Telemetry: true})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	assert.Empty(t, status.Message)
+	assert.Equal(t, 2, status.EventCount)
+
+	mockSdk.AssertCalled(t, "BuildContext", "1", common.ContentTypeJSON)
+	mockContext.AssertCalled(t, "AddValue", opaqueTopicKey, "telemetry/core-data/EventsPersisted")
+	mockPublisher.AssertNumberOfCalls(t, "Publish", 2)
+}
+
+func TestDataManager_StartReplay_TelemetryErrors(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Request       dtos.ReplayRequest
+		Publisher     bool
+		ExpectedError error
+	}{
+		{"Align time of day", dtos.ReplayRequest{AlignTimeOfDay: true, Telemetry: true}, true, telemetryDailyReplayError},
+		{"No publisher", dtos.ReplayRequest{ReplayRate: 1, Telemetry: true}, false, telemetryReplayUnavailableError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+			var target *dataManager
+			if test.Publisher {
+				target = NewManager(mockSdk, time.Minute, clock.New(), &mocks.BackgroundPublisher{}, nil).(*dataManager)
+			} else {
+				target = NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+			}
+			target.recordedData = &recordedData{
+				Events: newEventStore([]coreDtos.Event{coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)}),
+			}
+
+			err := target.StartReplay(test.Request)
+			require.ErrorIs(t, err, test.ExpectedError)
+			assert.Nil(t, target.replayStartedAt)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+var invalidTimeWarp = errors.New("invalid TimeWarpDuration and/or TimeWarpStart, values must be greater than or equal 0")
+var timeWarpReplayRateError = errors.New("ReplayRate can't be set with TimeWarpDuration, the rate is derived from the time warp windows")
+
+// timeWarp maps the wall-clock window of the recorded Events onto a target window of a different length, e.g. a
+// 24-hour recording onto a 1-hour test slot. The spacing between replayed Events is scaled by the ratio of the
+// windows and the rewritten Origins are placed proportionally within the target window, so the replayed data
+// covers the target window the same way the recorded data covered the original one.
+type timeWarp struct {
+	sourceStart int64
+	targetStart int64
+	targetSpan  int64
+	// scale is the target window length over the recorded window length
+	scale float64
+}
+
+// newTimeWarp returns the time warp for the request, or nil if the request doesn't set a TimeWarpDuration.
+// The target window starts at the TimeWarpStart, or at replayStart if not set.
+func newTimeWarp(request dtos.ReplayRequest, events *eventStore, envelopes map[string]dtos.EnvelopeMetadata,
+	replayStart time.Time) *timeWarp {
+	if request.TimeWarpDuration <= 0 || events.len() == 0 {
+		return nil
+	}
+
+	sourceStart, sourceEnd := recordedWindow(request, events, envelopes)
+
+	warp := &timeWarp{
+		sourceStart: sourceStart,
+		targetStart: request.TimeWarpStart,
+		targetSpan:  int64(request.TimeWarpDuration),
+	}
+
+	if warp.targetStart == 0 {
+		warp.targetStart = replayStart.UnixNano()
+	}
+
+	// A recording with a single point in time has no spacing to scale, so all its Events map to the window start
+	if sourceEnd > sourceStart {
+		warp.scale = float64(warp.targetSpan) / float64(sourceEnd-sourceStart)
+	}
+
+	return warp
+}
+
+// rate returns the replay rate equivalent to the time warp
+func (w *timeWarp) rate() float32 {
+	if w.scale == 0 {
+		return 1
+	}
+
+	return float32(1 / w.scale)
+}
+
+// origin returns the rewritten Origin for the Event with the given event time. Each repeat of the replay maps onto
+// the target window following that of the previous repeat.
+func (w *timeWarp) origin(eventTime int64, repeat int) int64 {
+	offset := int64(float64(eventTime-w.sourceStart) * w.scale)
+	return w.targetStart + int64(repeat)*w.targetSpan + offset
+}
+
+// recordedWindow returns the first and last event times of the recorded Events. The event time is the time the Event
+// was received when replaying with envelope timing and the Event has recorded envelope metadata, otherwise its Origin.
+func recordedWindow(request dtos.ReplayRequest, events *eventStore, envelopes map[string]dtos.EnvelopeMetadata) (int64, int64) {
+	var start, end int64
+	for index, origin := range events.origins {
+		eventTime := origin
+		if envelope, ok := envelopes[events.id(index)]; ok && request.UseEnvelopeTiming {
+			eventTime = envelope.ReceivedAt
+		}
+
+		if index == 0 || eventTime < start {
+			start = eventTime
+		}
+
+		if index == 0 || eventTime > end {
+			end = eventTime
+		}
+	}
+
+	return start, end
+}
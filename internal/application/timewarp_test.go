@@ -0,0 +1,175 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func timeWarpEvents(offsets ...time.Duration) []coreDtos.Event {
+	var events []coreDtos.Event
+	for _, offset := range offsets {
+		event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+		event.Origin = int64(time.Hour) + int64(offset)
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestNewTimeWarp(t *testing.T) {
+	replayStart := time.Unix(5000, 0)
+	events := timeWarpEvents(0, 12*time.Hour, 24*time.Hour)
+
+	tests := []struct {
+		Name           string
+		Request        dtos.ReplayRequest
+		Events         []coreDtos.Event
+		ExpectedNil    bool
+		ExpectedRate   float32
+		ExpectedStart  int64
+		ExpectedMiddle int64
+	}{
+		{"No time warp", dtos.ReplayRequest{ReplayRate: 1}, events, true, 0, 0, 0},
+		{"No events", dtos.ReplayRequest{TimeWarpDuration: time.Hour}, nil, true, 0, 0, 0},
+		{"Default start", dtos.ReplayRequest{TimeWarpDuration: time.Hour}, events, false, 24,
+			replayStart.UnixNano(), replayStart.UnixNano() + int64(30*time.Minute)},
+		{"Explicit start", dtos.ReplayRequest{TimeWarpDuration: 2 * time.Hour, TimeWarpStart: 100}, events, false, 12,
+			100, 100 + int64(time.Hour)},
+		{"Single point in time", dtos.ReplayRequest{TimeWarpDuration: time.Hour, TimeWarpStart: 100}, timeWarpEvents(0, 0), false, 1,
+			100, 100},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			warp := newTimeWarp(test.Request, newEventStore(test.Events), nil, replayStart)
+			if test.ExpectedNil {
+				assert.Nil(t, warp)
+				return
+			}
+
+			require.NotNil(t, warp)
+			assert.InDelta(t, test.ExpectedRate, warp.rate(), 0.0001)
+			assert.Equal(t, test.ExpectedStart, warp.origin(test.Events[0].Origin, 0))
+			assert.Equal(t, test.ExpectedMiddle, warp.origin(test.Events[0].Origin+int64(12*time.Hour), 0))
+		})
+	}
+}
+
+func TestNewTimeWarp_EnvelopeTiming(t *testing.T) {
+	events := timeWarpEvents(0, time.Hour)
+	envelopes := map[string]dtos.EnvelopeMetadata{
+		events[0].Id: {ReceivedAt: int64(10 * time.Hour)},
+		events[1].Id: {ReceivedAt: int64(14 * time.Hour)},
+	}
+
+	warp := newTimeWarp(dtos.ReplayRequest{TimeWarpDuration: time.Hour, TimeWarpStart: 100, UseEnvelopeTiming: true},
+		newEventStore(events), envelopes, time.Now())
+	require.NotNil(t, warp)
+	assert.InDelta(t, 4, warp.rate(), 0.0001)
+	assert.Equal(t, int64(100), warp.origin(int64(10*time.Hour), 0))
+	assert.Equal(t, 100+int64(time.Hour), warp.origin(int64(14*time.Hour), 0))
+}
+
+func TestTimeWarp_Origin_Repeat(t *testing.T) {
+	warp := newTimeWarp(dtos.ReplayRequest{TimeWarpDuration: time.Hour, TimeWarpStart: 100},
+		newEventStore(timeWarpEvents(0, 24*time.Hour)), nil, time.Now())
+	require.NotNil(t, warp)
+
+	// Each repeat maps onto the window following the previous one
+	assert.Equal(t, 100+int64(time.Hour), warp.origin(int64(time.Hour), 1))
+	assert.Equal(t, 100+int64(2*time.Hour), warp.origin(int64(25*time.Hour), 1))
+}
+
+func TestDataManager_StartReplay_TimeWarp(t *testing.T) {
+	var origins []int64
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).
+		Run(func(args mock.Arguments) {
+			origins = append(origins, args.Get(1).(requests.AddEventRequest).Event.Origin)
+		}).
+		Return(nil)
+
+	start := time.Unix(1000, 0)
+	virtualClock := clock.NewVirtual(start)
+
+	target := NewManager(mockSdk, time.Hour, virtualClock, nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events:  newEventStore(timeWarpEvents(0, 12*time.Hour, 24*time.Hour)),
+		Devices: map[string]*coreDtos.Device{expectedDeviceName: {Name: expectedDeviceName}},
+	}
+
+	// A 24-hour recording replayed within an hour, twice
+	err := target.StartReplay(dtos.ReplayRequest{TimeWarpDuration: time.Hour, RepeatCount: 2})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		virtualClock.Advance(time.Minute)
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, time.Millisecond)
+
+	status := target.ReplayStatus()
+	require.NoError(t, target.replayError)
+	assert.Equal(t, 6, status.EventCount)
+	require.Len(t, origins, 6)
+
+	base := start.UnixNano()
+	expected := []int64{
+		base, base + int64(30*time.Minute), base + int64(time.Hour),
+		base + int64(time.Hour), base + int64(90*time.Minute), base + int64(2*time.Hour),
+	}
+	assert.Equal(t, expected, origins)
+}
+
+func TestDataManager_StartReplay_TimeWarpErrors(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Request       dtos.ReplayRequest
+		ExpectedError error
+	}{
+		{"Negative duration", dtos.ReplayRequest{TimeWarpDuration: -time.Hour}, invalidTimeWarp},
+		{"Negative start", dtos.ReplayRequest{TimeWarpDuration: time.Hour, TimeWarpStart: -1}, invalidTimeWarp},
+		{"Replay rate set", dtos.ReplayRequest{ReplayRate: 2, TimeWarpDuration: time.Hour}, timeWarpReplayRateError},
+		{"No rate or time warp", dtos.ReplayRequest{}, invalidReplayRate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target := NewManager(&mocks.ApplicationService{}, time.Minute, clock.New(), nil, nil).(*dataManager)
+			target.recordedData = &recordedData{Events: newEventStore(timeWarpEvents(0))}
+
+			err := target.StartReplay(test.Request)
+			require.ErrorIs(t, err, test.ExpectedError)
+		})
+	}
+}
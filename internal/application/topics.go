@@ -0,0 +1,146 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const (
+	singleLevelWildcard = "+"
+	multiLevelWildcard  = "#"
+)
+
+var invalidTopicRuleError = errors.New("invalid topic rule")
+var opaqueTopicRuleFiltersError = errors.New("topic rule device profile, device and source filters can't be used when recording opaque messages")
+
+// topicRouter is the pipeline function which only passes on the messages received on a topic matching one of the
+// topic rules, and which pass that rule's include and exclude filters. Rules are evaluated in order and the first
+// rule matching the topic is used.
+type topicRouter struct {
+	rules []dtos.TopicRule
+}
+
+// newTopicRouter returns the router for the topic rules, or nil if there are no rules. An error is returned if any
+// rule has an invalid topic pattern or uses filters with an opaque recording.
+func newTopicRouter(rules []dtos.TopicRule, opaque bool) (*topicRouter, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	for _, rule := range rules {
+		if err := validateTopicPattern(rule.Topic); err != nil {
+			return nil, fmt.Errorf("%w '%s': %v", invalidTopicRuleError, rule.Topic, err)
+		}
+
+		if opaque && topicRuleHasFilters(rule) {
+			return nil, opaqueTopicRuleFiltersError
+		}
+	}
+
+	return &topicRouter{rules: rules}, nil
+}
+
+func validateTopicPattern(pattern string) error {
+	if len(strings.TrimSpace(pattern)) == 0 {
+		return errors.New("topic must not be blank")
+	}
+
+	levels := strings.Split(pattern, "/")
+	for index, level := range levels {
+		if strings.Contains(level, multiLevelWildcard) && (level != multiLevelWildcard || index != len(levels)-1) {
+			return errors.New("# wildcard must be the last topic level on its own")
+		}
+
+		if strings.Contains(level, singleLevelWildcard) && level != singleLevelWildcard {
+			return errors.New("+ wildcard must be a topic level on its own")
+		}
+	}
+
+	return nil
+}
+
+// route passes on the data if its received topic matches a rule and the data passes the rule's filters.
+func (r *topicRouter) route(ctx appInterfaces.AppFunctionContext, data any) (bool, interface{}) {
+	receivedTopic, _ := ctx.GetValue(appInterfaces.RECEIVEDTOPIC)
+	topic := relativeMessageTopic(receivedTopic)
+
+	for _, rule := range r.rules {
+		if !topicMatches(rule.Topic, topic) {
+			continue
+		}
+
+		// Filters are only allowed for Event recordings
+		if event, ok := data.(coreDtos.Event); ok && !topicRulePasses(rule, event) {
+			return false, nil
+		}
+
+		return true, data
+	}
+
+	return false, nil
+}
+
+func topicRuleHasFilters(rule dtos.TopicRule) bool {
+	return len(rule.IncludeDeviceProfiles)+len(rule.IncludeDevices)+len(rule.IncludeSources)+
+		len(rule.ExcludeDeviceProfiles)+len(rule.ExcludeDevices)+len(rule.ExcludeSources) > 0
+}
+
+// topicRulePasses returns true if the Event passes all the rule's include and exclude filters
+func topicRulePasses(rule dtos.TopicRule, event coreDtos.Event) bool {
+	return filterPasses(rule.IncludeDeviceProfiles, rule.ExcludeDeviceProfiles, event.ProfileName) &&
+		filterPasses(rule.IncludeDevices, rule.ExcludeDevices, event.DeviceName) &&
+		filterPasses(rule.IncludeSources, rule.ExcludeSources, event.SourceName)
+}
+
+func filterPasses(include []string, exclude []string, name string) bool {
+	if len(include) > 0 && !slices.Contains(include, name) {
+		return false
+	}
+
+	return !slices.Contains(exclude, name)
+}
+
+// topicMatches returns true if the topic matches the pattern using the MQTT wildcard rules, where + matches a single
+// topic level and # matches all the remaining levels, including none.
+func topicMatches(pattern string, topic string) bool {
+	patternLevels := strings.Split(pattern, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	for index, level := range patternLevels {
+		if level == multiLevelWildcard {
+			return true
+		}
+
+		if index >= len(topicLevels) {
+			return false
+		}
+
+		if level != singleLevelWildcard && level != topicLevels[index] {
+			return false
+		}
+	}
+
+	return len(patternLevels) == len(topicLevels)
+}
@@ -0,0 +1,125 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicMatches(t *testing.T) {
+	tests := []struct {
+		Pattern  string
+		Topic    string
+		Expected bool
+	}{
+		{"events/device/+/+/#", "events/device/svc/profile/device/source", true},
+		{"events/device/+/+/#", "events/device/svc/profile", true},
+		{"events/device/+/+/#", "events/device/svc", false},
+		{"events/device/+/+/#", "events/app/svc/profile/device", false},
+		{"events/#", "events", true},
+		{"#", "app/custom", true},
+		{"app/+/telemetry", "app/one/telemetry", true},
+		{"app/+/telemetry", "app/one/two/telemetry", false},
+		{"app/custom", "app/custom", true},
+		{"app/custom", "app/custom/more", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Pattern+" "+test.Topic, func(t *testing.T) {
+			assert.Equal(t, test.Expected, topicMatches(test.Pattern, test.Topic))
+		})
+	}
+}
+
+func TestNewTopicRouter(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Rules         []dtos.TopicRule
+		Opaque        bool
+		ExpectedError error
+	}{
+		{"Valid", []dtos.TopicRule{{Topic: "events/device/+/+/#", IncludeDevices: []string{"device1"}}}, false, nil},
+		{"Valid opaque", []dtos.TopicRule{{Topic: "app/#"}}, true, nil},
+		{"Blank topic", []dtos.TopicRule{{Topic: " "}}, false, invalidTopicRuleError},
+		{"# not last", []dtos.TopicRule{{Topic: "events/#/device"}}, false, invalidTopicRuleError},
+		{"# within level", []dtos.TopicRule{{Topic: "events/device#"}}, false, invalidTopicRuleError},
+		{"+ within level", []dtos.TopicRule{{Topic: "events/dev+/device"}}, false, invalidTopicRuleError},
+		{"Opaque with filters", []dtos.TopicRule{{Topic: "app/#", ExcludeSources: []string{"source1"}}}, true, opaqueTopicRuleFiltersError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			router, err := newTopicRouter(test.Rules, test.Opaque)
+			if test.ExpectedError != nil {
+				require.ErrorIs(t, err, test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, router)
+		})
+	}
+
+	router, err := newTopicRouter(nil, false)
+	require.NoError(t, err)
+	assert.Nil(t, router)
+}
+
+func TestTopicRouter_Route(t *testing.T) {
+	router, err := newTopicRouter([]dtos.TopicRule{
+		{Topic: "events/device/+/profile1/#", ExcludeDevices: []string{"device2"}},
+		{Topic: "events/device/+/profile2/#", IncludeSources: []string{"source1"}},
+		{Topic: "app/#"},
+	}, false)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name          string
+		ReceivedTopic string
+		Data          any
+		Expected      bool
+	}{
+		{"First rule", "edgex/events/device/svc/profile1/device1/source1", coreDtos.NewEvent("profile1", "device1", "source1"), true},
+		{"First rule excluded device", "edgex/events/device/svc/profile1/device2/source1", coreDtos.NewEvent("profile1", "device2", "source1"), false},
+		{"Second rule included source", "edgex/events/device/svc/profile2/device2/source1", coreDtos.NewEvent("profile2", "device2", "source1"), true},
+		{"Second rule not included source", "edgex/events/device/svc/profile2/device1/source2", coreDtos.NewEvent("profile2", "device1", "source2"), false},
+		{"Raw message", "edgex/app/custom", []byte("raw"), true},
+		{"No matching rule", "edgex/events/device/svc/profile3/device1/source1", coreDtos.NewEvent("profile3", "device1", "source1"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockContext := &mocks.AppFunctionContext{}
+			mockContext.On("GetValue", interfaces.RECEIVEDTOPIC).Return(test.ReceivedTopic, true)
+
+			continuePipeline, result := router.route(mockContext, test.Data)
+			require.Equal(t, test.Expected, continuePipeline)
+			if test.Expected {
+				assert.Equal(t, test.Data, result)
+			} else {
+				assert.Nil(t, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const (
+	// ReplayValidationPolicyAppSetting is the policy applied when a replayed Event's device or resources no longer
+	// exist in Core Metadata. Valid values are "skip", "fail" and "provision". Events aren't validated when not set.
+	ReplayValidationPolicyAppSetting = "ReplayValidationPolicy"
+
+	validationPolicySkip      = "skip"
+	validationPolicyFail      = "fail"
+	validationPolicyProvision = "provision"
+
+	replayValidationFailed = "replay event validation failed: %v"
+)
+
+var eventNotValidError = errors.New("event not valid against current metadata")
+
+// replayValidator validates the replayed Events against the current Devices and Device Profiles in Core Metadata.
+// Each device is checked once per replay, with missing devices provisioned from the recorded data when the policy
+// is "provision". Events that are still not valid after provisioning are skipped.
+type replayValidator struct {
+	manager *dataManager
+	lc      logger.LoggingClient
+	policy  string
+	// resources holds the resource names from the current Device Profile of each checked device.
+	// A nil entry indicates the device doesn't exist.
+	resources map[string]map[string]struct{}
+}
+
+// newReplayValidator returns the validator for the configured policy. Nil is returned if validation is disabled.
+func (m *dataManager) newReplayValidator(lc logger.LoggingClient) (*replayValidator, error) {
+	policy := m.appSvc.ApplicationSettings()[ReplayValidationPolicyAppSetting]
+	switch policy {
+	case "":
+		return nil, nil
+	case validationPolicySkip, validationPolicyFail, validationPolicyProvision:
+		return &replayValidator{
+			manager:   m,
+			lc:        lc,
+			policy:    policy,
+			resources: make(map[string]map[string]struct{}),
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid %s value '%s', must be one of %s, %s or %s", ReplayValidationPolicyAppSetting,
+			policy, validationPolicySkip, validationPolicyFail, validationPolicyProvision)
+	}
+}
+
+// skipsMissingDevices returns true if missing devices are handled per Event rather than failing the replay up front
+func (v *replayValidator) skipsMissingDevices() bool {
+	return v != nil && v.policy != validationPolicyFail
+}
+
+// validate returns an error wrapping eventNotValidError if the Event's device or any of its resources don't exist.
+// Any other error indicates the validation couldn't be completed.
+func (v *replayValidator) validate(event coreDtos.Event) error {
+	resources, checked := v.resources[event.DeviceName]
+	if !checked {
+		var err error
+		resources, err = v.loadDeviceResources(event.DeviceName)
+		if err != nil {
+			return err
+		}
+
+		v.resources[event.DeviceName] = resources
+	}
+
+	if resources == nil {
+		return fmt.Errorf("%w: device %s not found", eventNotValidError, event.DeviceName)
+	}
+
+	for _, reading := range event.Readings {
+		if _, ok := resources[reading.ResourceName]; !ok {
+			return fmt.Errorf("%w: device resource %s not found for device %s",
+				eventNotValidError, reading.ResourceName, event.DeviceName)
+		}
+	}
+
+	return nil
+}
+
+func (v *replayValidator) loadDeviceResources(deviceName string) (map[string]struct{}, error) {
+	appSvc := v.manager.appSvc
+
+	response, err := appSvc.DeviceClient().DeviceByName(context.Background(), deviceName)
+	if err != nil && err.Code() != http.StatusNotFound {
+		return nil, fmt.Errorf(deviceLoadFailed, deviceName, err)
+	}
+
+	device := response.Device
+	if err != nil {
+		if v.policy != validationPolicyProvision {
+			return nil, nil
+		}
+
+		provisioned, err := v.manager.provisionDevice(deviceName)
+		if err != nil {
+			v.lc.Warnf("ARR Replay: Unable to provision missing device %s: %v", deviceName, err)
+			return nil, nil
+		}
+
+		v.lc.Infof("ARR Replay: Provisioned missing device %s from the recorded data", deviceName)
+
+		device = *provisioned
+	}
+
+	profileResponse, err := appSvc.DeviceProfileClient().DeviceProfileByName(context.Background(), device.ProfileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load device profile %s for validation: %v", device.ProfileName, err)
+	}
+
+	resources := make(map[string]struct{})
+	for _, resource := range profileResponse.Profile.DeviceResources {
+		resources[resource.Name] = struct{}{}
+	}
+
+	return resources, nil
+}
+
+// provisionDevice adds the recorded device, and its Device Profile if not present, to Core Metadata
+func (m *dataManager) provisionDevice(deviceName string) (*coreDtos.Device, error) {
+	m.recordingMutex.Lock()
+	device := m.replayData.Devices[deviceName]
+	var profile *coreDtos.DeviceProfile
+	if device != nil {
+		profile = m.replayData.Profiles[device.ProfileName]
+	}
+	m.recordingMutex.Unlock()
+
+	if device == nil {
+		return nil, errors.New("device not in the recorded data")
+	}
+
+	// Must handle the profile first, so it exists when the device is added
+	if profile != nil {
+		if _, err := m.uploadProfiles([]coreDtos.DeviceProfile{*profile}, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := m.uploadDevices([]coreDtos.Device{*device}, false); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func (m *dataManager) incrementReplaySkippedEventCount() {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+	m.replaySkippedEventCount++
+}
@@ -0,0 +1,227 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataManager_NewReplayValidator(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Policy        string
+		ExpectedNil   bool
+		ExpectedError bool
+	}{
+		{"Not set", "", true, false},
+		{"Skip", validationPolicySkip, false, false},
+		{"Fail", validationPolicyFail, false, false},
+		{"Provision", validationPolicyProvision, false, false},
+		{"Invalid", "bogus", true, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ReplayValidationPolicyAppSetting: test.Policy})
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+
+			validator, err := target.newReplayValidator(logger.NewMockClient())
+			if test.ExpectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.ExpectedNil, validator == nil)
+			assert.Equal(t, test.Policy == validationPolicySkip || test.Policy == validationPolicyProvision,
+				validator.skipsMissingDevices())
+		})
+	}
+}
+
+func TestReplayValidator_Validate(t *testing.T) {
+	profile := coreDtos.DeviceProfile{
+		DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: expectedProfileName},
+		DeviceResources:        []coreDtos.DeviceResource{{Name: "R1"}},
+	}
+	device := coreDtos.Device{Name: expectedDeviceName, ProfileName: expectedProfileName}
+	notFound := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "device not found", nil)
+	commError := edgexErr.NewCommonEdgeX(edgexErr.KindServiceUnavailable, "metadata unavailable", nil)
+
+	tests := []struct {
+		Name              string
+		Policy            string
+		ResourceName      string
+		DeviceError       edgexErr.EdgeX
+		RecordedDevice    bool
+		ExpectedNotValid  bool
+		ExpectedError     bool
+		ExpectedProvision bool
+	}{
+		{"Valid", validationPolicySkip, "R1", nil, false, false, false, false},
+		{"Missing resource", validationPolicySkip, "R2", nil, false, true, false, false},
+		{"Missing device", validationPolicyFail, "R1", notFound, false, true, false, false},
+		{"Metadata unavailable", validationPolicySkip, "R1", commError, false, false, true, false},
+		{"Provisioned device", validationPolicyProvision, "R1", notFound, true, false, false, true},
+		{"Device not recorded", validationPolicyProvision, "R1", notFound, false, true, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDeviceClient := &clientMocks.DeviceClient{}
+			mockDeviceClient.On("DeviceByName", mock.Anything, expectedDeviceName).
+				Return(responses.DeviceResponse{Device: device}, test.DeviceError).Once()
+			mockDeviceClient.On("DeviceNameExists", mock.Anything, expectedDeviceName).
+				Return(commonDTO.BaseResponse{}, notFound)
+			mockDeviceClient.On("Add", mock.Anything, mock.Anything).Return(nil, nil)
+
+			mockProfileClient := &clientMocks.DeviceProfileClient{}
+			mockProfileClient.On("DeviceProfileByName", mock.Anything, expectedProfileName).
+				Return(responses.DeviceProfileResponse{Profile: profile}, nil)
+
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ReplayValidationPolicyAppSetting: test.Policy})
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("DeviceClient").Return(mockDeviceClient)
+			mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+			target.recordedData = &recordedData{
+				Devices:  map[string]*coreDtos.Device{},
+				Profiles: map[string]*coreDtos.DeviceProfile{expectedProfileName: &profile},
+			}
+			if test.RecordedDevice {
+				target.recordedData.Devices[expectedDeviceName] = &device
+			}
+			target.replayData = target.recordedData
+
+			validator, err := target.newReplayValidator(logger.NewMockClient())
+			require.NoError(t, err)
+
+			event := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+			_ = event.AddSimpleReading(test.ResourceName, common.ValueTypeString, "value")
+
+			// Validated a second time to verify the device is only checked once per replay
+			for i := 0; i < 2; i++ {
+				err = validator.validate(event)
+				switch {
+				case test.ExpectedNotValid:
+					require.ErrorIs(t, err, eventNotValidError)
+				case test.ExpectedError:
+					require.Error(t, err)
+					require.NotErrorIs(t, err, eventNotValidError)
+					return
+				default:
+					require.NoError(t, err)
+				}
+			}
+
+			mockDeviceClient.AssertNumberOfCalls(t, "DeviceByName", 1)
+			if test.ExpectedProvision {
+				mockDeviceClient.AssertCalled(t, "Add", mock.Anything, mock.Anything)
+			} else {
+				mockDeviceClient.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDataManager_StartReplay_ValidationSkip(t *testing.T) {
+	profile := coreDtos.DeviceProfile{
+		DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: expectedProfileName},
+		DeviceResources:        []coreDtos.DeviceResource{{Name: "R1"}},
+	}
+
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, "D1").
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: "D1", ProfileName: expectedProfileName}}, nil)
+	mockDeviceClient.On("DeviceByName", mock.Anything, "D2").
+		Return(responses.DeviceResponse{}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "device not found", nil))
+
+	mockProfileClient := &clientMocks.DeviceProfileClient{}
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, expectedProfileName).
+		Return(responses.DeviceProfileResponse{Profile: profile}, nil)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ReplayValidationPolicyAppSetting: validationPolicySkip})
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).Return(nil)
+
+	var events []coreDtos.Event
+	for _, deviceName := range []string{"D1", "D2", "D1"} {
+		event := coreDtos.NewEvent(expectedProfileName, deviceName, expectedSourceName)
+		_ = event.AddSimpleReading("R1", common.ValueTypeString, "value")
+		events = append(events, event)
+	}
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{Events: newEventStore(events)}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1000})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 100*time.Millisecond)
+
+	status := target.ReplayStatus()
+	assert.Empty(t, status.Message)
+	assert.Equal(t, 2, status.EventCount)
+	assert.Equal(t, 1, status.SkippedEventCount)
+	mockSdk.AssertNumberOfCalls(t, "PublishWithTopic", 2)
+}
+
+func TestDataManager_StartReplay_ValidationFail(t *testing.T) {
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{}, edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "device not found", nil))
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ReplayValidationPolicyAppSetting: validationPolicyFail})
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{
+		Events: newEventStore([]coreDtos.Event{coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)}),
+	}
+
+	// Devices missing when the replay starts fail the replay up front with the fail policy
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), expectedDeviceName)
+}
@@ -0,0 +1,203 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const replayValueBoundsFailed = "replay value bounds check failed: %v"
+
+var invalidValueBoundsError = fmt.Errorf("ValueBounds must be one of %s or %s", dtos.ReplayValueBoundsClamp,
+	dtos.ReplayValueBoundsReject)
+
+// valueBounds enforces the Minimum and Maximum of the Device Profile resources on the replayed Readings. Each
+// profile is loaded once per replay.
+type valueBounds struct {
+	manager *dataManager
+	lc      logger.LoggingClient
+	mode    string
+	// resources holds the resource properties of each profile by resource name. A nil entry indicates the profile
+	// wasn't found.
+	resources map[string]map[string]coreDtos.ResourceProperties
+}
+
+// newValueBounds returns the value bounds for the request. Nil is returned if the values aren't checked.
+func (m *dataManager) newValueBounds(request dtos.ReplayRequest, lc logger.LoggingClient) (*valueBounds, error) {
+	switch request.ValueBounds {
+	case "":
+		return nil, nil
+	case dtos.ReplayValueBoundsClamp, dtos.ReplayValueBoundsReject:
+		return &valueBounds{
+			manager:   m,
+			lc:        lc,
+			mode:      request.ValueBounds,
+			resources: make(map[string]map[string]coreDtos.ResourceProperties),
+		}, nil
+	default:
+		return nil, invalidValueBoundsError
+	}
+}
+
+// enforce clamps or drops the Event's numeric Readings whose values are outside their resource's bounds, returning
+// the number of Readings clamped and dropped. Readings of resources without bounds, or whose profile isn't found,
+// are left as is. An error indicates the check couldn't be completed.
+func (b *valueBounds) enforce(event *coreDtos.Event) (int, int, error) {
+	clamped, rejected := 0, 0
+	readings := event.Readings[:0]
+
+	for _, reading := range event.Readings {
+		properties, err := b.resourceProperties(reading.ProfileName, reading.ResourceName)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		value, inBounds := boundedValue(reading, properties)
+		switch {
+		case inBounds:
+		case b.mode == dtos.ReplayValueBoundsClamp:
+			b.lc.Debugf("ARR Replay: Value %s of resource %s for device %s clamped to %s", reading.Value,
+				reading.ResourceName, reading.DeviceName, value)
+			reading.Value = value
+			clamped++
+		default:
+			b.lc.Debugf("ARR Replay: Reading of resource %s for device %s rejected since its value %s is out of bounds",
+				reading.ResourceName, reading.DeviceName, reading.Value)
+			rejected++
+			continue
+		}
+
+		readings = append(readings, reading)
+	}
+
+	event.Readings = readings
+	return clamped, rejected, nil
+}
+
+// resourceProperties returns the properties of the profile's resource, or nil if the profile or resource isn't found
+func (b *valueBounds) resourceProperties(profileName string, resourceName string) (*coreDtos.ResourceProperties, error) {
+	resources, loaded := b.resources[profileName]
+	if !loaded {
+		profile, err := b.loadProfile(profileName)
+		if err != nil {
+			return nil, err
+		}
+
+		if profile != nil {
+			resources = make(map[string]coreDtos.ResourceProperties)
+			for _, resource := range profile.DeviceResources {
+				resources[resource.Name] = resource.Properties
+			}
+		}
+
+		b.resources[profileName] = resources
+	}
+
+	properties, ok := resources[resourceName]
+	if !ok {
+		return nil, nil
+	}
+
+	return &properties, nil
+}
+
+// loadProfile loads the profile from Core Metadata, falling back to the recorded profile if it isn't found there
+func (b *valueBounds) loadProfile(profileName string) (*coreDtos.DeviceProfile, error) {
+	m := b.manager
+
+	response, err := m.appSvc.DeviceProfileClient().DeviceProfileByName(context.Background(), profileName)
+	if err == nil {
+		return &response.Profile, nil
+	}
+
+	if err.Code() != http.StatusNotFound {
+		return nil, fmt.Errorf("failed to load device profile %s for value bounds: %v", profileName, err)
+	}
+
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+
+	profile := m.replayData.Profiles[profileName]
+	if profile == nil {
+		b.lc.Warnf("ARR Replay: Device profile %s not found, so its values aren't bounded", profileName)
+	}
+
+	return profile, nil
+}
+
+// boundedValue checks the Reading's numeric value against the resource's Minimum and Maximum, returning false
+// along with the value clamped to the bound it exceeds if out of bounds. Non-numeric Readings are always in bounds.
+func boundedValue(reading coreDtos.BaseReading, properties *coreDtos.ResourceProperties) (string, bool) {
+	if properties == nil || (properties.Minimum == nil && properties.Maximum == nil) {
+		return reading.Value, true
+	}
+
+	isFloat := false
+	switch reading.ValueType {
+	case common.ValueTypeFloat32, common.ValueTypeFloat64:
+		isFloat = true
+	case common.ValueTypeInt8, common.ValueTypeInt16, common.ValueTypeInt32, common.ValueTypeInt64,
+		common.ValueTypeUint8, common.ValueTypeUint16, common.ValueTypeUint32, common.ValueTypeUint64:
+	default:
+		return reading.Value, true
+	}
+
+	value, err := strconv.ParseFloat(reading.Value, 64)
+	if err != nil {
+		return reading.Value, true
+	}
+
+	// Integer values are clamped to the nearest integer within the bounds
+	var bound float64
+	switch {
+	case properties.Minimum != nil && value < *properties.Minimum:
+		bound = *properties.Minimum
+		if !isFloat {
+			bound = math.Ceil(bound)
+		}
+	case properties.Maximum != nil && value > *properties.Maximum:
+		bound = *properties.Maximum
+		if !isFloat {
+			bound = math.Floor(bound)
+		}
+	default:
+		return reading.Value, true
+	}
+
+	// Floats are formatted the same as the Readings created by the Device Services
+	if isFloat {
+		return fmt.Sprintf("%e", bound), false
+	}
+
+	return strconv.FormatFloat(bound, 'f', 0, 64), false
+}
+
+func (m *dataManager) addReplayValueBoundsCounts(clamped int, rejected int) {
+	m.recordingMutex.Lock()
+	defer m.recordingMutex.Unlock()
+	m.replayClampedReadingCount += clamped
+	m.replayRejectedReadingCount += rejected
+}
@@ -0,0 +1,219 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func float64Pointer(value float64) *float64 {
+	return &value
+}
+
+func newBoundedReading(valueType string, value string) coreDtos.BaseReading {
+	reading := coreDtos.BaseReading{ValueType: valueType}
+	reading.Value = value
+	return reading
+}
+
+func TestBoundedValue(t *testing.T) {
+	bounds := &coreDtos.ResourceProperties{Minimum: float64Pointer(-1.5), Maximum: float64Pointer(10.5)}
+
+	tests := []struct {
+		Name             string
+		Reading          coreDtos.BaseReading
+		Properties       *coreDtos.ResourceProperties
+		ExpectedValue    string
+		ExpectedInBounds bool
+	}{
+		{"In bounds", newBoundedReading(common.ValueTypeInt32, "5"), bounds, "5", true},
+		{"Integer below minimum", newBoundedReading(common.ValueTypeInt32, "-7"), bounds, "-1", false},
+		{"Integer above maximum", newBoundedReading(common.ValueTypeUint8, "200"), bounds, "10", false},
+		{"Float below minimum", newBoundedReading(common.ValueTypeFloat64, "-2.000000e+00"), bounds, "-1.500000e+00", false},
+		{"Float above maximum", newBoundedReading(common.ValueTypeFloat32, "11"), bounds, "1.050000e+01", false},
+		{"Only maximum", newBoundedReading(common.ValueTypeInt64, "-100"), &coreDtos.ResourceProperties{Maximum: float64Pointer(0)}, "-100", true},
+		{"No bounds", newBoundedReading(common.ValueTypeInt64, "100"), &coreDtos.ResourceProperties{}, "100", true},
+		{"No properties", newBoundedReading(common.ValueTypeInt64, "100"), nil, "100", true},
+		{"Not numeric type", newBoundedReading(common.ValueTypeString, "100"), bounds, "100", true},
+		{"Not numeric value", newBoundedReading(common.ValueTypeInt16, "NaN?"), bounds, "NaN?", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			value, inBounds := boundedValue(test.Reading, test.Properties)
+			assert.Equal(t, test.ExpectedValue, value)
+			assert.Equal(t, test.ExpectedInBounds, inBounds)
+		})
+	}
+}
+
+func newBoundsProfile(name string, minimum float64, maximum float64) coreDtos.DeviceProfile {
+	return coreDtos.DeviceProfile{
+		DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: name},
+		DeviceResources: []coreDtos.DeviceResource{
+			{Name: expectedSourceName, Properties: coreDtos.ResourceProperties{ValueType: common.ValueTypeInt32,
+				Minimum: float64Pointer(minimum), Maximum: float64Pointer(maximum)}},
+		},
+	}
+}
+
+func newBoundsEvent(profileName string, values ...int32) coreDtos.Event {
+	event := coreDtos.NewEvent(profileName, expectedDeviceName, expectedSourceName)
+	for _, value := range values {
+		_ = event.AddSimpleReading(expectedSourceName, common.ValueTypeInt32, value)
+	}
+	_ = event.AddSimpleReading("unbounded", common.ValueTypeInt32, int32(1000))
+	return event
+}
+
+func TestValueBounds_Enforce(t *testing.T) {
+	notFound := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "profile not found", nil)
+	commError := edgexErr.NewCommonEdgeX(edgexErr.KindServiceUnavailable, "metadata unavailable", nil)
+
+	mockProfileClient := &clientMocks.DeviceProfileClient{}
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, "current").
+		Return(responses.DeviceProfileResponse{Profile: newBoundsProfile("current", 0, 10)}, nil).Once()
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, "recorded").
+		Return(responses.DeviceProfileResponse{}, notFound).Once()
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, "missing").
+		Return(responses.DeviceProfileResponse{}, notFound).Once()
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, "unavailable").
+		Return(responses.DeviceProfileResponse{}, commError)
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	recordedProfile := newBoundsProfile("recorded", 100, 200)
+	target.recordedData = &recordedData{Profiles: map[string]*coreDtos.DeviceProfile{"recorded": &recordedProfile}}
+	target.replayData = target.recordedData
+
+	_, err := target.newValueBounds(dtos.ReplayRequest{ValueBounds: "wrap"}, logger.NewMockClient())
+	require.Equal(t, invalidValueBoundsError, err)
+
+	bounds, err := target.newValueBounds(dtos.ReplayRequest{}, logger.NewMockClient())
+	require.NoError(t, err)
+	require.Nil(t, bounds)
+
+	clamp, err := target.newValueBounds(dtos.ReplayRequest{ValueBounds: dtos.ReplayValueBoundsClamp}, logger.NewMockClient())
+	require.NoError(t, err)
+	reject, err := target.newValueBounds(dtos.ReplayRequest{ValueBounds: dtos.ReplayValueBoundsReject}, logger.NewMockClient())
+	require.NoError(t, err)
+
+	readingValues := func(event coreDtos.Event) []string {
+		var values []string
+		for _, reading := range event.Readings {
+			values = append(values, reading.Value)
+		}
+		return values
+	}
+
+	// The current profile's bounds apply
+	event := newBoundsEvent("current", -5, 5, 15)
+	clamped, rejected, err := clamp.enforce(&event)
+	require.NoError(t, err)
+	assert.Equal(t, 2, clamped)
+	assert.Zero(t, rejected)
+	assert.Equal(t, []string{"0", "5", "10", "1000"}, readingValues(event))
+
+	// The recorded profile's bounds apply when the profile isn't in Core Metadata. Each profile is loaded once.
+	for range 2 {
+		event = newBoundsEvent("recorded", 50, 150)
+		clamped, rejected, err = reject.enforce(&event)
+		require.NoError(t, err)
+		assert.Zero(t, clamped)
+		assert.Equal(t, 1, rejected)
+		assert.Equal(t, []string{"150", "1000"}, readingValues(event))
+	}
+
+	// Values are left as is when the profile isn't found at all
+	event = newBoundsEvent("missing", -5)
+	clamped, rejected, err = clamp.enforce(&event)
+	require.NoError(t, err)
+	assert.Zero(t, clamped+rejected)
+	assert.Equal(t, []string{"-5", "1000"}, readingValues(event))
+
+	event = newBoundsEvent("unavailable", -5)
+	_, _, err = clamp.enforce(&event)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "metadata unavailable")
+
+	mockProfileClient.AssertExpectations(t)
+}
+
+func TestDataManager_StartReplay_ValueBounds(t *testing.T) {
+	mockDeviceClient := &clientMocks.DeviceClient{}
+	mockDeviceClient.On("DeviceByName", mock.Anything, mock.Anything).
+		Return(responses.DeviceResponse{Device: coreDtos.Device{Name: expectedDeviceName, ServiceName: expectedServiceName}}, nil)
+	mockProfileClient := &clientMocks.DeviceProfileClient{}
+	mockProfileClient.On("DeviceProfileByName", mock.Anything, expectedProfileName).
+		Return(responses.DeviceProfileResponse{Profile: newBoundsProfile(expectedProfileName, 0, 10)}, nil)
+
+	var published []coreDtos.Event
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("DeviceClient").Return(mockDeviceClient)
+	mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+	mockSdk.On("AppContext").Return(context.Background())
+	mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, common.ContentTypeJSON).Return(nil).
+		Run(func(args mock.Arguments) {
+			published = append(published, args.Get(1).(requests.AddEventRequest).Event)
+		})
+
+	// The second Event's only reading is rejected, so the Event isn't published
+	first := newBoundsEvent(expectedProfileName, 20, 5)
+	first.Readings = first.Readings[:2]
+	second := newBoundsEvent(expectedProfileName, -1)
+	second.Readings = second.Readings[:1]
+
+	target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+	target.recordedData = &recordedData{Events: newEventStore([]coreDtos.Event{first, second})}
+
+	err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1000, ValueBounds: dtos.ReplayValueBoundsReject})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return !target.ReplayStatus().Running
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status := target.ReplayStatus()
+	assert.Empty(t, status.Message)
+	assert.Equal(t, 2, status.RejectedReadingCount)
+	assert.Zero(t, status.ClampedReadingCount)
+
+	require.Len(t, published, 1)
+	require.Len(t, published[0].Readings, 1)
+	assert.Equal(t, "5", published[0].Readings[0].Value)
+}
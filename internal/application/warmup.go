@@ -0,0 +1,118 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	bootstrapUtils "github.com/edgexfoundry/go-mod-bootstrap/v3/bootstrap/utils"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+)
+
+const replayWarmupFailed = "replay warm-up failed for event %d (%s): %v"
+
+var invalidReplayWarmup = fmt.Errorf("invalid Warmup, value must be empty, '%s' or '%s'", dtos.ReplayWarmupFull, dtos.ReplayWarmupBackground)
+
+// replayWarmup holds the recorded Events prepared for replay ahead of being published. Preparing an Event deep copies
+// it and validates and marshals the AddEventRequest it is published in, so errors that would otherwise stop a replay
+// part way through surface before, or early in, the replay, and the copying cost is taken out of the publish loop.
+type replayWarmup struct {
+	mutex  sync.Mutex
+	ready  *sync.Cond
+	events []coreDtos.Event
+	err    error
+}
+
+func newReplayWarmup() *replayWarmup {
+	warmup := &replayWarmup{}
+	warmup.ready = sync.NewCond(&warmup.mutex)
+	return warmup
+}
+
+// prepare prepares the Events in order, stopping at the first error or when the context is done
+func (w *replayWarmup) prepare(ctx context.Context, store *eventStore) error {
+	for index := range store.len() {
+		if ctx.Err() != nil {
+			return w.fail(ctx.Err())
+		}
+
+		event, err := prepareReplayEvent(store.event(index))
+		if err != nil {
+			return w.fail(fmt.Errorf(replayWarmupFailed, index, event.Id, err))
+		}
+
+		w.mutex.Lock()
+		w.events = append(w.events, event)
+		w.ready.Broadcast()
+		w.mutex.Unlock()
+	}
+
+	return nil
+}
+
+func (w *replayWarmup) fail(err error) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.err = err
+	w.ready.Broadcast()
+	return err
+}
+
+// event waits for the Event at the index to be prepared and returns a copy whose origins and ids can be replaced for
+// publishing. Once preparing has failed the error is returned for every index, so a background warm-up stops the
+// replay as soon as it fails rather than when the replay reaches the failed Event.
+func (w *replayWarmup) event(index int) (coreDtos.Event, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for len(w.events) <= index && w.err == nil {
+		w.ready.Wait()
+	}
+
+	if w.err != nil {
+		return coreDtos.Event{}, w.err
+	}
+
+	event := w.events[index]
+	event.Readings = slices.Clone(event.Readings)
+	return event, nil
+}
+
+func prepareReplayEvent(event coreDtos.Event) (coreDtos.Event, error) {
+	prepared := coreDtos.Event{}
+	if err := bootstrapUtils.DeepCopy(event, &prepared); err != nil {
+		return event, fmt.Errorf(replayDeepCopyFailed, err)
+	}
+
+	addEvent := requests.NewAddEventRequest(prepared)
+	if err := addEvent.Validate(); err != nil {
+		return event, err
+	}
+
+	if _, err := json.Marshal(addEvent); err != nil {
+		return event, err
+	}
+
+	return prepared, nil
+}
@@ -0,0 +1,125 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayWarmup(t *testing.T) {
+	warmup := newReplayWarmup()
+	require.NoError(t, warmup.prepare(context.Background(), newEventStore(expectedEventData)))
+
+	event, err := warmup.event(1)
+	require.NoError(t, err)
+	assert.Equal(t, expectedEventData[1], event)
+
+	// The Readings are copied each time so replaying can replace their ids and origins
+	event.Readings[0].Id = "replaced"
+	again, err := warmup.event(1)
+	require.NoError(t, err)
+	assert.Equal(t, expectedEventData[1].Readings[0].Id, again.Readings[0].Id)
+}
+
+func TestReplayWarmup_Failed(t *testing.T) {
+	// An Event without Readings fails validation
+	invalid := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	events := append([]coreDtos.Event{expectedEventData[0]}, invalid)
+
+	warmup := newReplayWarmup()
+	err := warmup.prepare(context.Background(), newEventStore(events))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), invalid.Id)
+
+	// Once failed, even the prepared Events return the error
+	_, err = warmup.event(0)
+	require.Error(t, err)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	warmup = newReplayWarmup()
+	require.Equal(t, context.Canceled, warmup.prepare(canceled, newEventStore(events)))
+}
+
+func TestDataManager_StartReplay_WarmupFailed(t *testing.T) {
+	invalid := coreDtos.NewEvent(expectedProfileName, expectedDeviceName, expectedSourceName)
+	invalid.Origin = time.Now().UnixNano()
+	_ = invalid.AddSimpleReading(expectedSourceName, common.ValueTypeString, "test1")
+	invalid.Readings[0].ValueType = "bogus"
+	events := append(append([]coreDtos.Event{}, expectedEventData...), invalid)
+
+	tests := []struct {
+		Name               string
+		Warmup             string
+		ExpectedStartError bool
+	}{
+		{"Full warm-up fails the start", dtos.ReplayWarmupFull, true},
+		{"Background warm-up stops the replay", dtos.ReplayWarmupBackground, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("AppContext").Return(context.Background())
+			mockSdk.On("NotificationClient").Return(nil)
+			mockSdk.On("PublishWithTopic", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			target := NewManager(mockSdk, time.Minute, clock.New(), nil, nil).(*dataManager)
+			target.recordedData = &recordedData{
+				Events:  newEventStore(events),
+				Devices: map[string]*coreDtos.Device{expectedDeviceName: {Name: expectedDeviceName, ServiceName: expectedServiceName}},
+			}
+
+			err := target.StartReplay(dtos.ReplayRequest{ReplayRate: 1, Warmup: test.Warmup})
+			if test.ExpectedStartError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "replay warm-up failed")
+				assert.Nil(t, target.replayStartedAt)
+				return
+			}
+
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				target.recordingMutex.Lock()
+				defer target.recordingMutex.Unlock()
+				return target.replayStartedAt == nil
+			}, 10*time.Second, 10*time.Millisecond)
+
+			target.recordingMutex.Lock()
+			defer target.recordingMutex.Unlock()
+			require.Error(t, target.replayError)
+			assert.Contains(t, target.replayError.Error(), "replay warm-up failed")
+			// The failure surfaces before the replay reaches the invalid Event
+			assert.Less(t, target.replayedEventCount, len(expectedEventData))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package clock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces"
+)
+
+type realClock struct{}
+
+// New is the factory function which instantiates a Clock backed by the system time
+func New() interfaces.Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+type sleeper struct {
+	wakeAt time.Time
+	wake   chan struct{}
+}
+
+type virtualClock struct {
+	mutex    sync.Mutex
+	now      time.Time
+	sleepers []sleeper
+}
+
+// NewVirtual is the factory function which instantiates a VirtualClock starting at the start time
+func NewVirtual(start time.Time) interfaces.VirtualClock {
+	return &virtualClock{now: start}
+}
+
+func (c *virtualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *virtualClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *virtualClock) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	wake := make(chan struct{})
+	c.sleepers = append(c.sleepers, sleeper{wakeAt: c.now.Add(d), wake: wake})
+	c.mutex.Unlock()
+
+	<-wake
+}
+
+func (c *virtualClock) Advance(d time.Duration) time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+
+	pending := c.sleepers[:0]
+	for _, s := range c.sleepers {
+		if s.wakeAt.After(c.now) {
+			pending = append(pending, s)
+			continue
+		}
+		close(s.wake)
+	}
+	c.sleepers = pending
+
+	return c.now
+}
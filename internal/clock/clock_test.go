@@ -0,0 +1,69 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealClock(t *testing.T) {
+	target := New()
+
+	start := target.Now()
+	target.Sleep(time.Millisecond)
+	assert.GreaterOrEqual(t, target.Since(start), time.Millisecond)
+}
+
+func TestVirtualClock(t *testing.T) {
+	start := time.Unix(1000, 0)
+	target := NewVirtual(start)
+
+	assert.Equal(t, start, target.Now())
+	assert.Zero(t, target.Since(start))
+
+	// Non-positive sleeps return immediately without advancing
+	target.Sleep(0)
+	target.Sleep(-time.Second)
+
+	woken := make(chan time.Duration, 2)
+	for _, d := range []time.Duration{time.Second, 3 * time.Second} {
+		go func(d time.Duration) {
+			target.Sleep(d)
+			woken <- d
+		}(d)
+	}
+
+	// Wait for both sleepers to register before advancing
+	require.Eventually(t, func() bool {
+		c := target.(*virtualClock)
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return len(c.sleepers) == 2
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, start.Add(2*time.Second), target.Advance(2*time.Second))
+	assert.Equal(t, time.Second, <-woken)
+	assert.Empty(t, woken)
+
+	target.Advance(time.Second)
+	assert.Equal(t, 3*time.Second, <-woken)
+	assert.Equal(t, 3*time.Second, target.Since(start))
+}
@@ -0,0 +1,82 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/labstack/echo/v4"
+)
+
+// labelParam is the optional annotation search query parameter with the path.Match pattern the labels must match
+const labelParam = "label"
+
+// addAnnotation adds the annotation in the request to the recording in progress or the recorded data, returning the
+// annotation added as the HTTP response
+func (c *httpController) addAnnotation(ctx echo.Context) error {
+	request := dtos.Annotation{}
+
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&request); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	if len(request.Label) == 0 {
+		return ctx.String(http.StatusBadRequest, failedAnnotationValidate)
+	}
+
+	// The timestamp defaults to the current time, which the manager checks the end against
+	if request.End != 0 && request.Timestamp != 0 && request.End < request.Timestamp {
+		return ctx.String(http.StatusBadRequest, failedAnnotationRangeValidate)
+	}
+
+	annotation, err := c.dataManager.AddAnnotation(request)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to add annotation: %v", err))
+	}
+
+	jsonResponse, err := json.Marshal(annotation)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal annotation: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// searchAnnotations returns the annotations with labels matching the label query parameter as the HTTP response,
+// a page at a time when requested
+func (c *httpController) searchAnnotations(ctx echo.Context) error {
+	pattern := ctx.Request().URL.Query().Get(labelParam)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: '%s'", failedLabelPatternValidate, pattern))
+	}
+
+	list, err := parseListRequest(ctx.Request().URL.Query())
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	result, err := c.dataManager.SearchAnnotations(pattern)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to search annotations: %v", err))
+	}
+
+	return listResponse(ctx, list, result, &result.Matches, "annotation search result")
+}
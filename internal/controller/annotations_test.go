@@ -0,0 +1,124 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_AddAnnotation(t *testing.T) {
+	annotation := dtos.Annotation{Label: "valve-opened", Timestamp: 1000}
+
+	tests := []struct {
+		Name           string
+		Body           string
+		ManagerError   error
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		{"Valid", `{"label":"valve-opened","timestamp":1000}`, nil, http.StatusOK, ""},
+		{"Bad JSON", `{`, nil, http.StatusBadRequest, failedRequestJSON},
+		{"No label", `{"timestamp":1000}`, nil, http.StatusBadRequest, failedAnnotationValidate},
+		{"End before timestamp", `{"label":"fault","timestamp":1000,"end":999}`, nil, http.StatusBadRequest, failedAnnotationRangeValidate},
+		{"Manager error", `{"label":"valve-opened","timestamp":1000}`, errors.New("recorded data is locked"), http.StatusInternalServerError, "recorded data is locked"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.addAnnotation))
+
+			if test.ExpectedStatus != http.StatusBadRequest {
+				mockDataManager.On("AddAnnotation", annotation).Return(&annotation, test.ManagerError)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, annotateRoute, strings.NewReader(test.Body))
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			mockDataManager.AssertExpectations(t)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedBody)
+				return
+			}
+
+			actualResponse := dtos.Annotation{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &actualResponse))
+			assert.Equal(t, annotation, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_SearchAnnotations(t *testing.T) {
+	result := &dtos.AnnotationSearchResult{Matches: []dtos.AnnotationMatch{
+		{Annotation: dtos.Annotation{Label: "valve-opened", Timestamp: 1000}, RecordingName: "line-1"},
+	}}
+
+	tests := []struct {
+		Name            string
+		Query           string
+		ExpectedPattern string
+		ManagerError    error
+		ExpectedStatus  int
+		ExpectedBody    string
+	}{
+		{"All", "", "", nil, http.StatusOK, ""},
+		{"Pattern", "?label=valve-*", "valve-*", nil, http.StatusOK, ""},
+		{"Bad pattern", "?label=%5B", "", nil, http.StatusBadRequest, failedLabelPatternValidate},
+		{"Manager error", "?label=valve", "valve", errors.New("permission denied"), http.StatusInternalServerError, "permission denied"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.searchAnnotations))
+
+			if test.ExpectedStatus != http.StatusBadRequest {
+				mockDataManager.On("SearchAnnotations", test.ExpectedPattern).Return(result, test.ManagerError)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, annotateRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			mockDataManager.AssertExpectations(t)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedBody)
+				return
+			}
+
+			actualResponse := &dtos.AnnotationSearchResult{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), actualResponse))
+			assert.Equal(t, result, actualResponse)
+		})
+	}
+}
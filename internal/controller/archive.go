@@ -0,0 +1,254 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+const (
+	// arrFormat is the export and import format of the .arr archive, a zip archive containing the recording and
+	// a manifest describing it
+	arrFormat = "arr"
+	// archiveFormatVersion is the version of the .arr archive format written by this service and the latest read
+	archiveFormatVersion = 1
+	archiveExtension     = ".arr"
+	manifestEntryName    = "manifest.json"
+	recordingEntryName   = "recording.json"
+
+	failedArchiveManifest      = "invalid archive manifest"
+	failedDependencyCheck      = "failed to check the dependencies of the archive"
+	failedArchiveCreate        = "failed to create archive"
+	archiveCompressionConflict = "compression can't be used with the arr format, the archive is already compressed"
+)
+
+var (
+	noArchiveManifest         = errors.New("archive has no manifest")
+	unsupportedArchiveVersion = errors.New("unsupported archive format version")
+	incompatibleEdgeXVersion  = errors.New("archive requires an incompatible EdgeX version")
+	archiveChecksumMismatch   = errors.New("archive entry checksum mismatch")
+	archiveManifestMismatch   = errors.New("archive manifest doesn't match the recorded data")
+	archiveRecordingNotFound  = errors.New("archive has no recording entry")
+)
+
+// newArchiveManifest returns the manifest for the recorded data, with the checksum of the encoded recording
+func newArchiveManifest(recordedData *dtos.RecordedData, recording []byte) dtos.ArchiveManifest {
+	profiles, deviceServices := archiveDependencies(recordedData)
+
+	// The recording is for the EdgeX version it was captured with when known, otherwise the version of this service
+	edgexVersion := ""
+	if recordedData.Metadata != nil {
+		edgexVersion = recordedData.Metadata.EdgeXVersion
+	}
+	if len(edgexVersion) == 0 {
+		edgexVersion, _ = utils.ModuleVersion(utils.ContractsModulePath)
+	}
+
+	checksum := sha256.Sum256(recording)
+	return dtos.ArchiveManifest{
+		FormatVersion:  archiveFormatVersion,
+		EdgeXVersion:   edgexVersion,
+		Name:           recordedData.Name,
+		Profiles:       profiles,
+		DeviceServices: deviceServices,
+		Checksums:      map[string]string{recordingEntryName: hex.EncodeToString(checksum[:])},
+	}
+}
+
+// archiveDependencies returns the sorted names of the Device Profiles and Device Services referenced by the
+// recorded Events and Devices
+func archiveDependencies(recordedData *dtos.RecordedData) ([]string, []string) {
+	profiles := make(map[string]bool)
+	deviceServices := make(map[string]bool)
+	for _, event := range recordedData.RecordedEvents {
+		profiles[event.ProfileName] = true
+	}
+	for _, device := range recordedData.Devices {
+		profiles[device.ProfileName] = true
+		deviceServices[device.ServiceName] = true
+	}
+
+	return sortedNames(profiles), sortedNames(deviceServices)
+}
+
+func sortedNames(names map[string]bool) []string {
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		if len(name) > 0 {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// createArchive returns the .arr archive containing the manifest and the encoded recording
+func createArchive(manifest dtos.ArchiveManifest, recording []byte) ([]byte, error) {
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := &bytes.Buffer{}
+	archive := zip.NewWriter(buffer)
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{{manifestEntryName, manifestData}, {recordingEntryName, recording}} {
+		writer, err := archive.Create(entry.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(entry.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// readArchiveManifest returns the manifest of the archive, or nil if the archive has no manifest, in which case
+// it is a plain zip archive
+func readArchiveManifest(archive *zip.Reader, limits importLimits) (*dtos.ArchiveManifest, error) {
+	file := findZipFile(archive, manifestEntryName)
+	if file == nil {
+		return nil, nil
+	}
+
+	data, err := readZipFile(file, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &dtos.ArchiveManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// validateArchive checks the archive can be imported by this service and the checksum of each of its entries, and
+// returns the recording entry
+func validateArchive(archive *zip.Reader, manifest *dtos.ArchiveManifest, limits importLimits) (*zip.File, error) {
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > archiveFormatVersion {
+		return nil, fmt.Errorf("%w: %d", unsupportedArchiveVersion, manifest.FormatVersion)
+	}
+
+	// Only the major versions need to match, as the EdgeX contracts are compatible within a major version. The
+	// check is skipped when either version isn't known.
+	if serviceVersion, ok := utils.ModuleVersion(utils.ContractsModulePath); ok && len(manifest.EdgeXVersion) > 0 &&
+		majorVersion(serviceVersion) != majorVersion(manifest.EdgeXVersion) {
+		return nil, fmt.Errorf("%w: %s, service is using %s", incompatibleEdgeXVersion, manifest.EdgeXVersion, serviceVersion)
+	}
+
+	recording := findZipFile(archive, recordingEntryName)
+	if recording == nil {
+		return nil, archiveRecordingNotFound
+	}
+
+	if _, ok := manifest.Checksums[recordingEntryName]; !ok {
+		return nil, fmt.Errorf("%w: no checksum for %s", archiveChecksumMismatch, recordingEntryName)
+	}
+
+	for name, expected := range manifest.Checksums {
+		file := findZipFile(archive, name)
+		if file == nil {
+			return nil, fmt.Errorf("%w: %s not found", archiveChecksumMismatch, name)
+		}
+
+		data, err := readZipFile(file, limits)
+		if err != nil {
+			return nil, err
+		}
+
+		checksum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(checksum[:]), expected) {
+			return nil, fmt.Errorf("%w: %s", archiveChecksumMismatch, name)
+		}
+	}
+
+	return recording, nil
+}
+
+// checkManifestDependencies checks the dependencies listed in the manifest are those of the recorded data and
+// returns the names of the ones which must already exist in Core Metadata, which are the Device Profiles not
+// included in the recorded data and all the Device Services
+func checkManifestDependencies(manifest *dtos.ArchiveManifest, recordedData *dtos.RecordedData) ([]string, []string, error) {
+	profiles, deviceServices := archiveDependencies(recordedData)
+	if !slices.Equal(profiles, manifest.Profiles) || !slices.Equal(deviceServices, manifest.DeviceServices) {
+		return nil, nil, archiveManifestMismatch
+	}
+
+	included := make(map[string]bool)
+	for _, profile := range recordedData.Profiles {
+		included[profile.Name] = true
+	}
+
+	var requiredProfiles []string
+	for _, profile := range profiles {
+		if !included[profile] {
+			requiredProfiles = append(requiredProfiles, profile)
+		}
+	}
+
+	return requiredProfiles, deviceServices, nil
+}
+
+// majorVersion returns the major version of a module version such as v3.1.0
+func majorVersion(version string) string {
+	major, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+	return major
+}
+
+func findZipFile(archive *zip.Reader, name string) *zip.File {
+	for _, file := range archive.File {
+		if file.Name == name {
+			return file
+		}
+	}
+
+	return nil
+}
+
+// readZipFile reads the entry in full, limited the same as the recorded data entry
+func readZipFile(file *zip.File, limits importLimits) ([]byte, error) {
+	reader, err := openZipFile(file, limits)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(limitImportReader(reader, limits.maxBytes, "uncompressed data"))
+}
@@ -0,0 +1,178 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var archivedData = &dtos.RecordedData{
+	Name: "line-1",
+	RecordedEvents: []coreDtos.Event{
+		{Id: "event-1", DeviceName: "device-1", ProfileName: "profile-1", Origin: 1},
+		{Id: "event-2", DeviceName: "device-2", ProfileName: "profile-2", Origin: 2},
+	},
+	Devices: []coreDtos.Device{
+		{Name: "device-1", ProfileName: "profile-1", ServiceName: "service-1"},
+		{Name: "device-2", ProfileName: "profile-2", ServiceName: "service-1"},
+	},
+	Profiles: []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "profile-1"}}},
+	Metadata: &dtos.RecordingMetadata{EdgeXVersion: "v3.1.0"},
+}
+
+func TestHttpController_ExportArchive(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("ExportRecordedData").Return(archivedData, nil)
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, dataRoute+"?format=arr", nil))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="line-1.arr"`, recorder.Header().Get("Content-Disposition"))
+
+	archive, err := zip.NewReader(bytes.NewReader(recorder.Body.Bytes()), int64(recorder.Body.Len()))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 2)
+	assert.Equal(t, manifestEntryName, archive.File[0].Name)
+	assert.Equal(t, recordingEntryName, archive.File[1].Name)
+
+	manifest, err := readArchiveManifest(archive, defaultTestImportLimits())
+	require.NoError(t, err)
+	assert.Equal(t, archiveFormatVersion, manifest.FormatVersion)
+	assert.Equal(t, "v3.1.0", manifest.EdgeXVersion)
+	assert.Equal(t, "line-1", manifest.Name)
+	assert.Equal(t, []string{"profile-1", "profile-2"}, manifest.Profiles)
+	assert.Equal(t, []string{"service-1"}, manifest.DeviceServices)
+
+	entry, err := validateArchive(archive, manifest, defaultTestImportLimits())
+	require.NoError(t, err)
+	reader, err := entry.Open()
+	require.NoError(t, err)
+	defer reader.Close()
+	actual := &dtos.RecordedData{}
+	require.NoError(t, json.NewDecoder(reader).Decode(actual))
+	assert.Equal(t, archivedData, actual)
+}
+
+func TestHttpController_ExportArchive_Compressed(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("ExportRecordedData").Return(archivedData, nil)
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, dataRoute+"?format=arr&compression=gzip", nil))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), archiveCompressionConflict)
+}
+
+func TestHttpController_ImportArchive(t *testing.T) {
+	recording := marshal(t, archivedData)
+	manifest := newArchiveManifest(archivedData, recording)
+
+	unsupported := manifest
+	unsupported.FormatVersion = archiveFormatVersion + 1
+
+	incompatible := manifest
+	incompatible.EdgeXVersion = "v2.3.0"
+
+	tampered := manifest
+	tampered.Checksums = map[string]string{recordingEntryName: "00"}
+
+	mismatched := manifest
+	mismatched.Profiles = []string{"profile-1"}
+
+	// The EdgeX version check is skipped when the contracts module version isn't known
+	incompatibleStatus, incompatibleMessage, incompatibleMissing := http.StatusBadRequest, incompatibleEdgeXVersion.Error(), (*dtos.MissingDependencies)(nil)
+	if _, ok := utils.ModuleVersion(utils.ContractsModulePath); !ok {
+		incompatibleStatus, incompatibleMessage, incompatibleMissing = http.StatusAccepted, "", &dtos.MissingDependencies{}
+	}
+
+	tests := []struct {
+		Name            string
+		Archive         []byte
+		FormatParam     string
+		Missing         *dtos.MissingDependencies
+		ExpectedStatus  int
+		ExpectedMessage string
+	}{
+		{"Valid", archiveData(t, manifest, recording), "", &dtos.MissingDependencies{}, http.StatusAccepted, ""},
+		{"Valid with format", archiveData(t, manifest, recording), arrFormat, &dtos.MissingDependencies{}, http.StatusAccepted, ""},
+		{"Missing dependencies", archiveData(t, manifest, recording), "",
+			&dtos.MissingDependencies{Profiles: []string{"profile-2"}, DeviceServices: []string{"service-1"}},
+			http.StatusFailedDependency, `{"profiles":["profile-2"],"deviceServices":["service-1"]}`},
+		{"No manifest", zipData(t, recordingEntryName, recording), arrFormat, nil, http.StatusBadRequest, noArchiveManifest.Error()},
+		{"Unsupported version", archiveData(t, unsupported, recording), "", nil, http.StatusBadRequest, unsupportedArchiveVersion.Error()},
+		{"Checksum mismatch", archiveData(t, tampered, recording), "", nil, http.StatusBadRequest, archiveChecksumMismatch.Error()},
+		{"Manifest mismatch", archiveData(t, mismatched, recording), "", nil, http.StatusBadRequest, archiveManifestMismatch.Error()},
+		{"Incompatible EdgeX version", archiveData(t, incompatible, recording), "", incompatibleMissing, incompatibleStatus, incompatibleMessage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData))
+			if test.Missing != nil {
+				mockDataManager.On("MissingDependencies", []string{"profile-2"}, []string{"service-1"}).Return(test.Missing, nil)
+			}
+			mockDataManager.On("ImportRecordedData", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			url := dataRoute
+			if len(test.FormatParam) > 0 {
+				url += "?" + importFormatParam + "=" + test.FormatParam
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, url, bytes.NewReader(test.Archive)))
+
+			require.Equal(t, test.ExpectedStatus, recorder.Code, recorder.Body.String())
+			assert.Contains(t, recorder.Body.String(), test.ExpectedMessage)
+			if test.ExpectedStatus == http.StatusAccepted {
+				mockDataManager.AssertCalled(t, "ImportRecordedData", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				mockDataManager.AssertNotCalled(t, "ImportRecordedData", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	assert.Equal(t, "3", majorVersion("v3.1.0"))
+	assert.Equal(t, "3", majorVersion("3.2.0-dev.53"))
+	assert.Equal(t, "4", majorVersion("v4"))
+}
+
+func archiveData(t *testing.T, manifest dtos.ArchiveManifest, recording []byte) []byte {
+	data, err := createArchive(manifest, recording)
+	require.NoError(t, err)
+	return data
+}
+
+func defaultTestImportLimits() importLimits {
+	return importLimits{maxBytes: 1 << 20, maxEvents: 1000, maxCompressionRatio: 100, maxRequestBytes: 1 << 20}
+}
@@ -0,0 +1,293 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// backupFormatVersion is the version of the backup archive format written by this service and the latest read
+	backupFormatVersion     = 1
+	backupManifestEntryName = "backup.json"
+	backupCurrentEntryName  = "current.json"
+	// backupRecordingsDir is the directory in the backup archive holding a directory of segments per recording
+	backupRecordingsDir = "recordings"
+	backupTimeLayout    = "20060102T150405Z"
+
+	failedBackup  = "Backup failed"
+	failedRestore = "Restore failed"
+)
+
+var (
+	noBackupManifest         = errors.New("archive has no backup manifest")
+	backupEntryNotFound      = errors.New("backup archive entry not found")
+	unsupportedBackupVersion = errors.New("unsupported backup format version")
+)
+
+// backupStore responds with a zip archive holding the recorded data and every named recording in the segment store,
+// which can be restored on another instance.
+func (c *httpController) backupStore(ctx echo.Context) error {
+	backup, err := c.dataManager.Backup()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedBackup, err))
+	}
+
+	// The archive is streamed to the response, so errors once it is started can only end the response early
+	name := "arr-backup-" + time.Unix(0, backup.CreatedAt).UTC().Format(backupTimeLayout) + ".zip"
+	response := ctx.Response()
+	response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	response.Header().Set(echo.HeaderContentType, "application/zip")
+	response.WriteHeader(http.StatusOK)
+
+	if err := writeBackupArchive(response, backup); err != nil {
+		return fmt.Errorf("%s: %v", failedBackup, err)
+	}
+
+	return nil
+}
+
+// restoreStore restores the backup archive in the request, writing its recordings into the segment store and
+// importing its recorded data. Multi-Status is returned if some of the recorded data's Device Profiles or Devices
+// failed to be provisioned.
+func (c *httpController) restoreStore(ctx echo.Context) error {
+	overwrite := true
+	if queryParam := ctx.Request().URL.Query().Get("overwrite"); len(queryParam) > 0 {
+		var err error
+		overwrite, err = strconv.ParseBool(queryParam)
+		if err != nil {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("failed to parse overwrite parameter: %v", err))
+		}
+	}
+
+	limits, err := c.getImportLimits()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedRestore, err))
+	}
+
+	// Requests with a known length are rejected before reading the body, otherwise the limit is applied while reading
+	if ctx.Request().ContentLength > limits.maxRequestBytes {
+		return ctx.String(http.StatusRequestEntityTooLarge, fmt.Sprintf("%s: %v: request body of %d bytes exceeds %d bytes",
+			failedImportLimit, importLimitExceeded, ctx.Request().ContentLength, limits.maxRequestBytes))
+	}
+
+	archive, err := readZipArchive(limitImportReader(ctx.Request().Body, limits.maxRequestBytes, "request body"))
+	if err != nil {
+		return c.importReadFailed(ctx, failedRestore, err)
+	}
+
+	backup, err := readBackupArchive(archive, limits)
+	if err != nil {
+		return c.importReadFailed(ctx, failedRestore, err)
+	}
+
+	result, err := c.dataManager.Restore(backup, overwrite)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedRestore, err))
+	}
+
+	jsonResponse, err := json.Marshal(result)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedRestore, err))
+	}
+
+	status := http.StatusOK
+	if len(result.ProvisioningFailures) > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	return ctx.String(status, string(jsonResponse))
+}
+
+// backupSegmentEntryName returns the name of the backup archive entry holding the segment of the recording
+func backupSegmentEntryName(recordingName string, segmentName string) string {
+	return path.Join(backupRecordingsDir, recordingName, segmentName)
+}
+
+// writeBackupArchive writes the zip archive holding the manifest, the recorded data, if any, and the segments of
+// each recording. Segments in the store are copied from their files as they are written, so the archive is never
+// held in memory. Segments deleted by the retention limits since they were listed are left out.
+func writeBackupArchive(writer io.Writer, backup *dtos.StoreBackup) error {
+	manifest := dtos.BackupManifest{
+		FormatVersion: backupFormatVersion,
+		CreatedAt:     backup.CreatedAt,
+		Current:       backup.Current != nil,
+		Recordings:    make([]dtos.BackupRecording, 0, len(backup.Recordings)),
+	}
+
+	archive := zip.NewWriter(writer)
+	writeEntry := func(name string, data []byte) error {
+		entry, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = entry.Write(data)
+		return err
+	}
+
+	if backup.Current != nil {
+		data, err := marshalRecordedData(backup.Current)
+		if err != nil {
+			return err
+		}
+		if err := writeEntry(backupCurrentEntryName, data); err != nil {
+			return err
+		}
+	}
+
+	for _, recording := range backup.Recordings {
+		listed := dtos.BackupRecording{Name: recording.Name, Segments: make([]string, 0, len(recording.Segments))}
+		for _, segment := range recording.Segments {
+			written, err := writeBackupSegment(archive, backupSegmentEntryName(recording.Name, segment.FileName), segment)
+			if err != nil {
+				return err
+			}
+			if written {
+				listed.Segments = append(listed.Segments, segment.FileName)
+			}
+		}
+		if len(listed.Segments) > 0 {
+			manifest.Recordings = append(manifest.Recordings, listed)
+		}
+	}
+
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err := writeEntry(backupManifestEntryName, manifestData); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// writeBackupSegment writes the segment to the archive entry, copying it from its file in the segment store when it
+// has a path. False is returned if the file no longer exists.
+func writeBackupSegment(archive *zip.Writer, name string, segment dtos.SegmentBackup) (bool, error) {
+	if len(segment.Path) == 0 {
+		entry, err := archive.Create(name)
+		if err != nil {
+			return false, err
+		}
+		_, err = entry.Write(segment.Data)
+		return err == nil, err
+	}
+
+	file, err := os.Open(segment.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	entry, err := archive.Create(name)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(entry, file); err != nil {
+		return false, fmt.Errorf("failed to copy segment %s: %v", segment.Path, err)
+	}
+
+	return true, nil
+}
+
+// readBackupArchive returns the content of the backup archive listed by its manifest, with the total uncompressed
+// size of its entries limited the same as imported data
+func readBackupArchive(archive *zip.Reader, limits importLimits) (*dtos.StoreBackup, error) {
+	var total int64
+	readEntry := func(name string) ([]byte, error) {
+		file := findZipFile(archive, name)
+		if file == nil {
+			return nil, fmt.Errorf("%w: %s", backupEntryNotFound, name)
+		}
+
+		reader, err := openZipFile(file, limits)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		// The limit is shared by the entries, so it fails once their total exceeds it
+		limited := &limitedReader{ReadCloser: reader, description: "uncompressed backup data", maxBytes: limits.maxBytes, read: total}
+		data, err := io.ReadAll(limited)
+		total = limited.read
+		return data, err
+	}
+
+	if findZipFile(archive, backupManifestEntryName) == nil {
+		return nil, noBackupManifest
+	}
+
+	manifestData, err := readEntry(backupManifestEntryName)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := dtos.BackupManifest{}
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %v", err)
+	}
+
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > backupFormatVersion {
+		return nil, fmt.Errorf("%w: %d", unsupportedBackupVersion, manifest.FormatVersion)
+	}
+
+	backup := &dtos.StoreBackup{CreatedAt: manifest.CreatedAt}
+	if manifest.Current {
+		data, err := readEntry(backupCurrentEntryName)
+		if err != nil {
+			return nil, err
+		}
+
+		backup.Current = &dtos.RecordedData{}
+		if err := json.Unmarshal(data, backup.Current); err != nil {
+			return nil, fmt.Errorf("invalid recorded data in backup: %v", err)
+		}
+		if err := expandReadingsOnly(backup.Current, int(limits.maxEvents)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, listed := range manifest.Recordings {
+		recording := dtos.RecordingBackup{Name: listed.Name}
+		for _, segmentName := range listed.Segments {
+			data, err := readEntry(backupSegmentEntryName(listed.Name, segmentName))
+			if err != nil {
+				return nil, err
+			}
+			recording.Segments = append(recording.Segments, dtos.SegmentBackup{FileName: segmentName, Data: data})
+		}
+		backup.Recordings = append(backup.Recordings, recording)
+	}
+
+	return backup, nil
+}
@@ -0,0 +1,188 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testStoreBackup = &dtos.StoreBackup{
+	CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC).UnixNano(),
+	Current:   archivedData,
+	Recordings: []dtos.RecordingBackup{{
+		Name:     "line-1",
+		Segments: []dtos.SegmentBackup{{FileName: "20240301T120000.000000000Z.json", Data: []byte(`{"name":"line-1"}`)}},
+	}},
+}
+
+func TestHttpController_BackupStore(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("Backup").Return(testStoreBackup, nil)
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.backupStore))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, backupRoute, nil))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="arr-backup-20240301T120000Z.zip"`, recorder.Header().Get("Content-Disposition"))
+
+	archive, err := zip.NewReader(bytes.NewReader(recorder.Body.Bytes()), int64(recorder.Body.Len()))
+	require.NoError(t, err)
+	backup, err := readBackupArchive(archive, defaultTestImportLimits())
+	require.NoError(t, err)
+	assert.Equal(t, testStoreBackup, backup)
+}
+
+func TestHttpController_BackupStore_Error(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("Backup").Return(nil, errors.New("a recording is in progress"))
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.backupStore))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, backupRoute, nil))
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), failedBackup)
+}
+
+func TestHttpController_BackupStore_SegmentFiles(t *testing.T) {
+	dir := t.TempDir()
+	segmentPath := filepath.Join(dir, "20240301T120000.000000000Z.json")
+	require.NoError(t, os.WriteFile(segmentPath, []byte(`{"name":"line-1"}`), 0640))
+
+	// The segment files are copied into the archive, leaving out those deleted since they were listed
+	backup := &dtos.StoreBackup{
+		CreatedAt: testStoreBackup.CreatedAt,
+		Recordings: []dtos.RecordingBackup{
+			{Name: "line-1", Segments: []dtos.SegmentBackup{
+				{FileName: "20240301T120000.000000000Z.json", Path: segmentPath},
+				{FileName: "20240301T130000.000000000Z.json", Path: filepath.Join(dir, "20240301T130000.000000000Z.json")},
+			}},
+			{Name: "line-2", Segments: []dtos.SegmentBackup{
+				{FileName: "20240301T120000.000000000Z.json", Path: filepath.Join(dir, "deleted.json")},
+			}},
+		},
+	}
+
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("Backup").Return(backup, nil)
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.backupStore))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, backupRoute, nil))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	archive, err := zip.NewReader(bytes.NewReader(recorder.Body.Bytes()), int64(recorder.Body.Len()))
+	require.NoError(t, err)
+	actual, err := readBackupArchive(archive, defaultTestImportLimits())
+	require.NoError(t, err)
+	assert.Equal(t, testStoreBackup.Recordings, actual.Recordings)
+}
+
+func TestReadBackupArchive_TotalLimit(t *testing.T) {
+	segment := bytes.Repeat([]byte("a"), 600)
+	backup := &dtos.StoreBackup{
+		Recordings: []dtos.RecordingBackup{{
+			Name: "line-1",
+			Segments: []dtos.SegmentBackup{
+				{FileName: "20240301T120000.000000000Z.json", Data: segment},
+				{FileName: "20240301T130000.000000000Z.json", Data: segment},
+			},
+		}},
+	}
+	data := createBackupArchive(t, backup)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	// Each entry is within the limit, but their total isn't
+	limits := defaultTestImportLimits()
+	limits.maxBytes = 1000
+	_, err = readBackupArchive(archive, limits)
+	require.ErrorIs(t, err, importLimitExceeded)
+	assert.Contains(t, err.Error(), "exceeds 1000 bytes")
+
+	limits.maxBytes = 2000
+	_, err = readBackupArchive(archive, limits)
+	require.NoError(t, err)
+}
+
+func createBackupArchive(t *testing.T, backup *dtos.StoreBackup) []byte {
+	buffer := &bytes.Buffer{}
+	require.NoError(t, writeBackupArchive(buffer, backup))
+	return buffer.Bytes()
+}
+
+func TestHttpController_RestoreStore(t *testing.T) {
+	backupArchive := createBackupArchive(t, testStoreBackup)
+
+	// An .arr archive isn't a backup, since it has no backup manifest
+	arrArchive, err := createArchive(dtos.ArchiveManifest{FormatVersion: archiveFormatVersion}, []byte("{}"))
+	require.NoError(t, err)
+
+	failures := []dtos.ProvisioningFailure{{Kind: dtos.ProvisioningKindDevice, Name: "device-1", Error: "failed"}}
+
+	tests := []struct {
+		Name           string
+		Body           []byte
+		Query          string
+		Result         *dtos.RestoreResult
+		RestoreError   error
+		ExpectedStatus int
+	}{
+		{"Restored", backupArchive, "", &dtos.RestoreResult{CurrentRestored: true, RecordingCount: 1, SegmentCount: 1}, nil, http.StatusOK},
+		{"Provisioning failures", backupArchive, "", &dtos.RestoreResult{CurrentRestored: true, ProvisioningFailures: failures}, nil, http.StatusMultiStatus},
+		{"Restore failed", backupArchive, "", nil, errors.New("a replay is in progress"), http.StatusInternalServerError},
+		{"Not a backup", arrArchive, "", nil, nil, http.StatusBadRequest},
+		{"Not an archive", []byte("{}"), "", nil, nil, http.StatusBadRequest},
+		{"Invalid overwrite", backupArchive, "?overwrite=sometimes", nil, nil, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			mockDataManager.On("Restore", mock.Anything, true).Return(test.Result, test.RestoreError)
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.restoreStore))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, restoreRoute+test.Query, bytes.NewReader(test.Body)))
+			require.Equal(t, test.ExpectedStatus, recorder.Code, recorder.Body.String())
+
+			if test.Result == nil {
+				return
+			}
+
+			mockDataManager.AssertCalled(t, "Restore", testStoreBackup, true)
+			actual := &dtos.RestoreResult{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), actual))
+			assert.Equal(t, test.Result, actual)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	clockRoute        = common.ApiBase + "/clock"
+	clockAdvanceRoute = clockRoute + "/advance"
+
+	failedClockAdvanceValidate = "Clock advance request failed validation: Duration must be greater than 0"
+)
+
+// addClockRoutes adds the routes to read and advance the virtual clock. These routes are only added when the
+// service runs with the virtual clock, which is intended for tests and simulation frameworks.
+func (c *httpController) addClockRoutes() error {
+	if c.virtualClock == nil {
+		return nil
+	}
+
+	if err := c.appSdk.AddCustomRoute(clockRoute, false, c.clockStatus, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, clockRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(clockAdvanceRoute, false, c.advanceClock, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, clockAdvanceRoute, http.MethodPost, err)
+	}
+
+	c.lc.Warn("Virtual clock enabled. Record and replay timing is driven by the clock routes")
+
+	return nil
+}
+
+// clockStatus returns the current virtual time as the HTTP response.
+func (c *httpController) clockStatus(ctx echo.Context) error {
+	return clockStatusResponse(ctx, c.virtualClock.Now())
+}
+
+// advanceClock advances the virtual clock by the duration in the request and returns the new virtual time
+// as the HTTP response. Any replay waiting on the clock resumes once its wait has elapsed.
+func (c *httpController) advanceClock(ctx echo.Context) error {
+	advanceRequest := &dtos.ClockAdvanceRequest{}
+
+	if err := json.NewDecoder(ctx.Request().Body).Decode(advanceRequest); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	if advanceRequest.Duration <= 0 {
+		return ctx.String(http.StatusBadRequest, failedClockAdvanceValidate)
+	}
+
+	return clockStatusResponse(ctx, c.virtualClock.Advance(advanceRequest.Duration))
+}
+
+func clockStatusResponse(ctx echo.Context, now time.Time) error {
+	jsonResponse, err := json.Marshal(dtos.ClockStatus{Now: now.UnixNano()})
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal clock status: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
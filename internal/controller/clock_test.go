@@ -0,0 +1,120 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/clock"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_AddClockRoutes(t *testing.T) {
+	mockSdk := &appMocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := New(nil, nil, nil, mockSdk).(*httpController)
+	require.NoError(t, target.addClockRoutes())
+	mockSdk.AssertNotCalled(t, "AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	tests := []struct {
+		Name   string
+		Route  string
+		Method string
+	}{
+		{"Clock Status", clockRoute, http.MethodGet},
+		{"Advance Clock", clockAdvanceRoute, http.MethodPost},
+	}
+
+	expectedError := errors.New("AddRoutes error")
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &appMocks.ApplicationService{}
+			mockSdk.On("AddCustomRoute", test.Route, mock.Anything, mock.Anything, test.Method).Return(expectedError)
+			mockSdk.On("AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+			target := New(nil, nil, clock.NewVirtual(time.Now()), mockSdk)
+
+			err := target.AddRoutes()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.Route)
+			assert.Contains(t, err.Error(), test.Method)
+		})
+	}
+}
+
+func TestHttpController_Clock(t *testing.T) {
+	start := time.Unix(1000, 0)
+	target, _, _ := createTargetAndMocks()
+	target.virtualClock = clock.NewVirtual(start)
+
+	req, err := http.NewRequest(http.MethodGet, clockRoute, nil)
+	require.NoError(t, err)
+	testRecorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.clockStatus)).ServeHTTP(testRecorder, req)
+	require.Equal(t, http.StatusOK, testRecorder.Code)
+
+	status := dtos.ClockStatus{}
+	require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &status))
+	assert.Equal(t, start.UnixNano(), status.Now)
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.advanceClock))
+
+	tests := []struct {
+		Name            string
+		Input           []byte
+		ExpectedStatus  int
+		ExpectedMessage string
+		ExpectedNow     time.Time
+	}{
+		{"Advance", marshal(t, dtos.ClockAdvanceRequest{Duration: time.Minute}), http.StatusOK, "", start.Add(time.Minute)},
+		{"Advance again", marshal(t, dtos.ClockAdvanceRequest{Duration: time.Second}), http.StatusOK, "", start.Add(time.Minute + time.Second)},
+		{"Bad JSON Input", []byte("bad input"), http.StatusBadRequest, failedRequestJSON, time.Time{}},
+		{"Zero Duration", marshal(t, dtos.ClockAdvanceRequest{}), http.StatusBadRequest, failedClockAdvanceValidate, time.Time{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, clockAdvanceRoute, bytes.NewReader(test.Input))
+			require.NoError(t, err)
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedMessage)
+				return
+			}
+
+			status := dtos.ClockStatus{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &status))
+			assert.Equal(t, test.ExpectedNow.UnixNano(), status.Now)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	clusterRoute       = common.ApiBase + "/cluster"
+	clusterRecordRoute = clusterRoute + "/record"
+	clusterReplayRoute = clusterRoute + "/replay"
+	clusterDataRoute   = clusterRoute + "/data"
+
+	failedFanOut = "Cluster command failed"
+)
+
+// addClusterRoutes adds the coordinator routes which fan out the record, replay and export commands to the
+// peer instances. Each cluster route mirrors the local route of the same name.
+func (c *httpController) addClusterRoutes() error {
+	routes := []struct {
+		clusterRoute string
+		peerRoute    string
+		methods      []string
+	}{
+		{clusterRecordRoute, recordRoute, []string{http.MethodPost, http.MethodGet, http.MethodDelete}},
+		{clusterReplayRoute, replayRoute, []string{http.MethodPost, http.MethodGet, http.MethodDelete}},
+		{clusterDataRoute, dataRoute, []string{http.MethodGet}},
+	}
+
+	for _, route := range routes {
+		for _, method := range route.methods {
+			if err := c.appSdk.AddCustomRoute(route.clusterRoute, false, c.fanOut(route.peerRoute), method); err != nil {
+				return fmt.Errorf(failedRouteMessage, route.clusterRoute, method, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// fanOut returns the handler which sends the request to the peer route on every peer instance and
+// returns the aggregated peer responses as the HTTP response.
+func (c *httpController) fanOut(peerRoute string) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		body, err := io.ReadAll(ctx.Request().Body)
+		if err != nil {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+		}
+
+		route := peerRoute
+		if len(ctx.Request().URL.RawQuery) > 0 {
+			route = route + "?" + ctx.Request().URL.RawQuery
+		}
+
+		responses, err := c.coordinator.FanOut(ctx.Request().Method, route, body)
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedFanOut, err))
+		}
+
+		jsonResponse, err := json.Marshal(responses)
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal peer responses: %s", err))
+		}
+
+		return ctx.String(http.StatusOK, string(jsonResponse))
+	}
+}
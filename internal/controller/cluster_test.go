@@ -0,0 +1,79 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_FanOut(t *testing.T) {
+	peerResponses := []dtos.PeerResponse{
+		{Peer: "http://node1:59712", StatusCode: http.StatusOK, Body: json.RawMessage(`{"running":true}`)},
+		{Peer: "http://node2:59712", StatusCode: http.StatusInternalServerError, Error: "Replay failed"},
+	}
+
+	tests := []struct {
+		Name              string
+		Method            string
+		ClusterRoute      string
+		PeerRoute         string
+		Body              []byte
+		FanOutError       error
+		ExpectedPeerRoute string
+		ExpectedStatus    int
+	}{
+		{"Start replay", http.MethodPost, clusterReplayRoute, replayRoute, []byte(`{"replayRate":1}`), nil, replayRoute, http.StatusOK},
+		{"Replay status", http.MethodGet, clusterReplayRoute, replayRoute, []byte{}, nil, replayRoute, http.StatusOK},
+		{"Export with query", http.MethodGet, clusterDataRoute + "?format=summary", dataRoute, []byte{}, nil, dataRoute + "?format=summary", http.StatusOK},
+		{"No peers", http.MethodDelete, clusterRecordRoute, recordRoute, []byte{}, errors.New("no peers"), recordRoute, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, _, _ := createTargetAndMocks()
+			mockCoordinator := target.coordinator.(*mocks.Coordinator)
+			mockCoordinator.On("FanOut", test.Method, test.ExpectedPeerRoute, test.Body).Return(peerResponses, test.FanOutError)
+
+			req, err := http.NewRequest(test.Method, test.ClusterRoute, bytes.NewReader(test.Body))
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.fanOut(test.PeerRoute))).ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			mockCoordinator.AssertExpectations(t)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), failedFanOut)
+				return
+			}
+
+			var actual []dtos.PeerResponse
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &actual))
+			assert.Equal(t, peerResponses, actual)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+)
+
+const (
+	zlibCompression     = "zlib"
+	gzipCompression     = "gzip"
+	contentEncodingGzip = "gzip"
+	contentEncodingZlib = "deflate" // standard value used for zlib is deflate
+)
+
+// codec compresses the exported data and uncompresses the imported data for a compression format
+type codec struct {
+	// contentEncoding is the HTTP Content-Encoding value for the compression format, used for both export and import
+	contentEncoding string
+	newWriter       func(writer io.Writer) io.WriteCloser
+	newReader       func(reader io.Reader) (io.ReadCloser, error)
+	// frame wraps a raw deflate stream in the format's header and trailer, so large exports can be compressed in
+	// parallel. Formats without it are always compressed by a single writer.
+	frame func(deflated []byte, data []byte) []byte
+}
+
+// codecs is the registry of the supported compression formats, keyed by the name used for the export compression
+// query parameter. New compression formats only need to be added here to be supported by both export and import.
+var codecs = map[string]codec{
+	gzipCompression: {
+		contentEncoding: contentEncodingGzip,
+		newWriter:       func(writer io.Writer) io.WriteCloser { return gzip.NewWriter(writer) },
+		newReader:       func(reader io.Reader) (io.ReadCloser, error) { return gzip.NewReader(reader) },
+		frame:           gzipFrame,
+	},
+	zlibCompression: {
+		contentEncoding: contentEncodingZlib,
+		newWriter:       func(writer io.Writer) io.WriteCloser { return zlib.NewWriter(writer) },
+		newReader:       func(reader io.Reader) (io.ReadCloser, error) { return zlib.NewReader(reader) },
+		frame:           zlibFrame,
+	},
+}
+
+// codecByContentEncoding returns the name and codec for the HTTP Content-Encoding value
+func codecByContentEncoding(contentEncoding string) (string, codec, bool) {
+	for name, codec := range codecs {
+		if strings.EqualFold(codec.contentEncoding, contentEncoding) {
+			return name, codec, true
+		}
+	}
+
+	return "", codec{}, false
+}
+
+// compress returns the data compressed using the codec. Data larger than a single chunk is compressed in parallel
+// when the codec supports it.
+func (c codec) compress(data []byte) ([]byte, error) {
+	if c.frame != nil && exportWorkers > 1 && len(data) > exportCompressChunkSize {
+		deflated, err := deflateChunks(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.frame(deflated, data), nil
+	}
+
+	buffer := &bytes.Buffer{}
+	writer := c.newWriter(buffer)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+
+	// Close flushes the remaining compressed data
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,68 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	data := []byte(`{"recordedEvents":[{"deviceName":"test"}]}`)
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			compressed, err := codec.compress(data)
+			require.NoError(t, err)
+			assert.NotEqual(t, data, compressed)
+
+			reader, err := codec.newReader(bytes.NewReader(compressed))
+			require.NoError(t, err)
+			defer reader.Close()
+
+			actual, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, data, actual)
+		})
+	}
+}
+
+func TestCodecByContentEncoding(t *testing.T) {
+	tests := []struct {
+		Name            string
+		ContentEncoding string
+		ExpectedName    string
+		ExpectedOk      bool
+	}{
+		{"gzip", contentEncodingGzip, gzipCompression, true},
+		{"deflate", contentEncodingZlib, zlibCompression, true},
+		{"Case insensitive", "GZIP", gzipCompression, true},
+		{"Unsupported", "br", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			name, _, ok := codecByContentEncoding(test.ContentEncoding)
+			assert.Equal(t, test.ExpectedOk, ok)
+			assert.Equal(t, test.ExpectedName, name)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// compareAParam and compareBParam are the path parameters of the compare route holding the names of the two
+	// recordings compared
+	compareAParam = "a"
+	compareBParam = "b"
+	// resourceParam is the required compare query parameter with the name of the resource compared
+	resourceParam = "resource"
+)
+
+// compareSeries returns the numeric Readings of the resource query parameter, limited to the device query parameter
+// when set, from the two recordings named by the path as paired time series for plotting overlays. The series are
+// listed a page of devices at a time when requested.
+func (c *httpController) compareSeries(ctx echo.Context) error {
+	resourceName := ctx.Request().URL.Query().Get(resourceParam)
+	if len(resourceName) == 0 {
+		return ctx.String(http.StatusBadRequest, failedCompareValidate)
+	}
+
+	list, err := parseListRequest(ctx.Request().URL.Query())
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	series, err := c.dataManager.CompareSeries(ctx.Param(compareAParam), ctx.Param(compareBParam), resourceName,
+		ctx.Request().URL.Query().Get(deviceParam))
+	if err != nil {
+		return ctx.String(http.StatusNotFound, fmt.Sprintf("failed to compare recordings: %v", err))
+	}
+
+	return listResponse(ctx, list, series, &series.Series, "comparison series")
+}
@@ -0,0 +1,82 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_CompareSeries(t *testing.T) {
+	expected := &dtos.ComparisonSeries{
+		ResourceName: "Temperature",
+		A:            dtos.ComparedRecording{Name: "baseline", Start: 1000, PointCount: 1},
+		B:            dtos.ComparedRecording{Name: "current", Start: 5000, PointCount: 1},
+		Series: []dtos.PairedSeries{{
+			DeviceName: "D1",
+			A:          []dtos.SeriesPoint{{Offset: 0, Origin: 1000, Value: 21.5}},
+			B:          []dtos.SeriesPoint{{Offset: 0, Origin: 5000, Value: 22}},
+		}},
+	}
+
+	tests := []struct {
+		Name           string
+		Query          string
+		ManagerError   error
+		ExpectedStatus int
+	}{
+		{"Valid", "?resource=Temperature&device=D1", nil, http.StatusOK},
+		{"No resource", "", nil, http.StatusBadRequest},
+		{"Not found", "?resource=Temperature&device=D1", errors.New("recording not found"), http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			if test.ExpectedStatus != http.StatusBadRequest {
+				mockDataManager.On("CompareSeries", "baseline", "current", "Temperature", "D1").Return(expected, test.ManagerError)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, dataRoute+"/compare/baseline/current/series"+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			ctx := echo.New().NewContext(req, testRecorder)
+			ctx.SetParamNames(compareAParam, compareBParam)
+			ctx.SetParamValues("baseline", "current")
+			require.NoError(t, target.compareSeries(ctx))
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			mockDataManager.AssertExpectations(t)
+			if test.ExpectedStatus != http.StatusOK {
+				return
+			}
+
+			actual := &dtos.ComparisonSeries{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), actual))
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/labstack/echo/v4"
+)
+
+// fieldRenames maps JSON field names of one API version to those of another
+type fieldRenames map[string]string
+
+// compatVersion is a frozen API version still served for existing clients, so DTO changes don't break their
+// automation scripts. Its routes mirror the current routes and are served by the same handlers, with the top-level
+// JSON field names of the request and response bodies translated between the versions.
+type compatVersion struct {
+	apiBase string
+	// requestFields maps the older field names of the request bodies to the current names, keyed by current route
+	requestFields map[string]fieldRenames
+	// responseFields maps the current field names of the response bodies to the older names, keyed by current route
+	responseFields map[string]fieldRenames
+}
+
+// v3Compat is the v3 API as of this release, served under /api/v3.0 so clients can pin its DTOs while those of the
+// current routes evolve. Its field names are the current ones, so its rename tables are empty. When a DTO field of
+// the current routes is renamed, the new name is mapped to the frozen name here.
+var v3Compat = compatVersion{
+	apiBase:        "/api/v3.0",
+	requestFields:  map[string]fieldRenames{},
+	responseFields: map[string]fieldRenames{},
+}
+
+// route returns the route of the version mirroring the current route
+func (v compatVersion) route(currentRoute string) string {
+	return v.apiBase + strings.TrimPrefix(currentRoute, common.ApiBase)
+}
+
+// addCompatRoutes adds the routes of the frozen API versions still served
+func (c *httpController) addCompatRoutes() error {
+	routes := []struct {
+		route   string
+		method  string
+		handler echo.HandlerFunc
+	}{
+		{recordRoute, http.MethodPost, c.startRecording},
+		{recordRoute, http.MethodGet, c.recordingStatus},
+		{recordRoute, http.MethodDelete, c.cancelRecording},
+		{replayRoute, http.MethodPost, c.startReplay},
+		{replayRoute, http.MethodGet, c.replayStatus},
+		{replayRoute, http.MethodDelete, c.cancelReplay},
+		{dataRoute, http.MethodGet, c.exportRecordedData},
+		{dataRoute, http.MethodPost, c.importRecordedData},
+	}
+
+	for _, version := range []compatVersion{v3Compat} {
+		for _, route := range routes {
+			compatRoute := version.route(route.route)
+			if err := c.appSdk.AddCustomRoute(compatRoute, false, version.handler(route.route, route.handler), route.method); err != nil {
+				return fmt.Errorf(failedRouteMessage, compatRoute, route.method, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// handler returns the handler which translates the request and response bodies of the current route's handler.
+// Routes without renamed fields are served by the current handler as is, so streamed responses aren't buffered.
+func (v compatVersion) handler(currentRoute string, handler echo.HandlerFunc) echo.HandlerFunc {
+	requestFields := v.requestFields[currentRoute]
+	responseFields := v.responseFields[currentRoute]
+	if len(requestFields) == 0 && len(responseFields) == 0 {
+		return handler
+	}
+
+	return func(ctx echo.Context) error {
+		request := ctx.Request()
+		if len(requestFields) > 0 && request.Body != nil {
+			body, err := io.ReadAll(request.Body)
+			if err != nil {
+				return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+			}
+
+			body = requestFields.translate(body)
+			request.Body = io.NopCloser(bytes.NewReader(body))
+			request.ContentLength = int64(len(body))
+		}
+
+		if len(responseFields) == 0 {
+			return handler(ctx)
+		}
+
+		writer := ctx.Response().Writer
+		buffered := &bufferedResponse{header: writer.Header()}
+		ctx.Response().Writer = buffered
+		err := handler(ctx)
+		ctx.Response().Writer = writer
+
+		if buffered.status == 0 {
+			return err
+		}
+
+		writer.WriteHeader(buffered.status)
+		if _, writeErr := writer.Write(responseFields.translate(buffered.body.Bytes())); writeErr != nil && err == nil {
+			err = writeErr
+		}
+
+		return err
+	}
+}
+
+// translate returns the JSON object with its top-level fields renamed. Where both the old and the new name of a
+// field are present, the new name takes precedence. Data which isn't a JSON object is returned unchanged, leaving
+// it to the handler to report invalid request bodies.
+func (r fieldRenames) translate(data []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return data
+	}
+
+	renamed := false
+	for from, to := range r {
+		value, ok := fields[from]
+		if !ok {
+			continue
+		}
+
+		delete(fields, from)
+		if _, exists := fields[to]; !exists {
+			fields[to] = value
+		}
+		renamed = true
+	}
+
+	if !renamed {
+		return data
+	}
+
+	translated, err := json.Marshal(fields)
+	if err != nil {
+		return data
+	}
+
+	return translated
+}
+
+// bufferedResponse buffers the response written by a handler so its body can be translated before it is sent
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+
+	return b.body.Write(data)
+}
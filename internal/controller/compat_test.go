@@ -0,0 +1,156 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCompat is a frozen version with renamed fields, as the frozen versions will have once a current DTO field is
+// renamed
+var testCompat = compatVersion{
+	apiBase: "/api/v3.0",
+	requestFields: map[string]fieldRenames{
+		replayRoute: {"rate": "replayRate"},
+	},
+	responseFields: map[string]fieldRenames{
+		recordRoute: {"inProgress": "running"},
+	},
+}
+
+func TestCompatVersion_Route(t *testing.T) {
+	assert.Equal(t, "/api/v3.0/record", v3Compat.route(recordRoute))
+	assert.Equal(t, "/api/v3.0/data", v3Compat.route(dataRoute))
+}
+
+func TestV3Compat_CurrentFields(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("StartReplay", dtos.ReplayRequest{ReplayRate: 2, RepeatCount: 3}).Return(nil).Once()
+
+	// The frozen v3 DTOs are the current DTOs until a field is renamed
+	handler := http.HandlerFunc(WrapEchoHandler(t, v3Compat.handler(replayRoute, target.startReplay)))
+
+	req, err := http.NewRequest(http.MethodPost, v3Compat.route(replayRoute), bytes.NewReader([]byte(`{"replayRate":2,"repeatCount":3}`)))
+	require.NoError(t, err)
+
+	testRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(testRecorder, req)
+
+	require.Equal(t, http.StatusAccepted, testRecorder.Code, testRecorder.Body.String())
+	mockDataManager.AssertExpectations(t)
+}
+
+func TestFieldRenames_Translate(t *testing.T) {
+	renames := fieldRenames{"rate": "replayRate"}
+
+	tests := []struct {
+		Name     string
+		Input    string
+		Expected string
+	}{
+		{"Renamed", `{"rate":2,"repeatCount":1}`, `{"repeatCount":1,"replayRate":2}`},
+		{"New name takes precedence", `{"rate":2,"replayRate":3}`, `{"replayRate":3}`},
+		{"Nothing to rename", `{"replayRate":2}`, `{"replayRate":2}`},
+		{"Not an object", `[1,2]`, `[1,2]`},
+		{"Invalid JSON", `bad input`, `bad input`},
+		{"Empty", ``, ``},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, string(renames.translate([]byte(test.Input))))
+		})
+	}
+}
+
+func TestHttpController_Compat_StartReplay(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("StartReplay", dtos.ReplayRequest{ReplayRate: 2, RepeatCount: 3}).Return(nil).Once()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, testCompat.handler(replayRoute, target.startReplay)))
+
+	req, err := http.NewRequest(http.MethodPost, testCompat.route(replayRoute), bytes.NewReader([]byte(`{"rate":2,"repeatCount":3}`)))
+	require.NoError(t, err)
+
+	testRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(testRecorder, req)
+
+	require.Equal(t, http.StatusAccepted, testRecorder.Code, testRecorder.Body.String())
+	mockDataManager.AssertExpectations(t)
+}
+
+func TestHttpController_Compat_StartReplay_BadJSON(t *testing.T) {
+	target, _, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, testCompat.handler(replayRoute, target.startReplay)))
+
+	req, err := http.NewRequest(http.MethodPost, testCompat.route(replayRoute), bytes.NewReader([]byte("bad input")))
+	require.NoError(t, err)
+
+	testRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(testRecorder, req)
+
+	require.Equal(t, http.StatusBadRequest, testRecorder.Code)
+	assert.Contains(t, testRecorder.Body.String(), failedRequestJSON)
+}
+
+func TestHttpController_Compat_RecordingStatus(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("RecordingStatus").Return(dtos.RecordStatus{InProgress: true, EventCount: 4, Duration: time.Second})
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, testCompat.handler(recordRoute, target.recordingStatus)))
+
+	req, err := http.NewRequest(http.MethodGet, testCompat.route(recordRoute), http.NoBody)
+	require.NoError(t, err)
+
+	testRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(testRecorder, req)
+
+	require.Equal(t, http.StatusOK, testRecorder.Code)
+
+	var status map[string]any
+	require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &status))
+	assert.Equal(t, true, status["running"])
+	assert.NotContains(t, status, "inProgress")
+	assert.Equal(t, float64(4), status["eventCount"])
+}
+
+func TestHttpController_Compat_PassThrough(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("CancelReplay").Return(nil).Once()
+
+	// Routes without renamed fields are served by the current handler
+	handler := http.HandlerFunc(WrapEchoHandler(t, testCompat.handler(dataRoute, target.cancelReplay)))
+
+	req, err := http.NewRequest(http.MethodDelete, testCompat.route(replayRoute), http.NoBody)
+	require.NoError(t, err)
+
+	testRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(testRecorder, req)
+
+	require.Equal(t, http.StatusAccepted, testRecorder.Code)
+	mockDataManager.AssertExpectations(t)
+}
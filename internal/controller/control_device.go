@@ -0,0 +1,294 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/models"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// ControlDeviceNameAppSetting is the name of the device registered in Core Metadata, along with a device service and
+	// device profile of the same name, so the service can be controlled through Core Command and the tooling built on
+	// it, e.g. the UI. Core Command reaches the service at the ControlBaseAddress. Disabled when not set.
+	ControlDeviceNameAppSetting = "ControlDeviceName"
+	// ControlBaseAddressAppSetting is the base address Core Command sends the control device's commands to, which is
+	// this service's address, e.g. http://app-record-replay:59712. Required when ControlDeviceName is set.
+	ControlBaseAddressAppSetting = "ControlBaseAddress"
+
+	// Resources of the control device, each of which is also a command
+	controlResourceRecord       = "Record"
+	controlResourceReplay       = "Replay"
+	controlResourceCancelRecord = "CancelRecord"
+	controlResourceCancelReplay = "CancelReplay"
+
+	controlDeviceDescription = "Controls the record and replay sessions of app-record-replay"
+
+	deviceCommandRoute = common.ApiDeviceRoute + "/" + common.Name + "/:" + common.Name + "/:" + common.Command
+)
+
+var controlDeviceLabels = []string{"app-record-replay", "control"}
+
+var controlBaseAddressNotSet = errors.New(ControlBaseAddressAppSetting + " must be set when " + ControlDeviceNameAppSetting + " is set")
+var controlValueNotTrue = errors.New("value must be true")
+
+// controlDeviceName returns the name of the control device, or empty if it is disabled
+func (c *httpController) controlDeviceName() string {
+	return c.appSdk.ApplicationSettings()[ControlDeviceNameAppSetting]
+}
+
+// controlDeviceProfile returns the Device Profile of the control device. Record and Replay read the session status
+// and start a session when set to the JSON record or replay request, while the cancel resources are set to true.
+func controlDeviceProfile(name string) coreDtos.DeviceProfile {
+	return coreDtos.DeviceProfile{
+		DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{
+			Name:         name,
+			Manufacturer: "EdgeX Foundry",
+			Model:        "app-record-replay",
+			Description:  controlDeviceDescription,
+			Labels:       controlDeviceLabels,
+		},
+		DeviceResources: []coreDtos.DeviceResource{
+			{
+				Name:        controlResourceRecord,
+				Description: "Status of the recording session. Set to a record request to start a recording",
+				Properties:  coreDtos.ResourceProperties{ValueType: common.ValueTypeObject, ReadWrite: common.ReadWrite_RW},
+			},
+			{
+				Name:        controlResourceReplay,
+				Description: "Status of the replay session. Set to a replay request to start a replay",
+				Properties:  coreDtos.ResourceProperties{ValueType: common.ValueTypeObject, ReadWrite: common.ReadWrite_RW},
+			},
+			{
+				Name:        controlResourceCancelRecord,
+				Description: "Set to true to cancel the recording session",
+				Properties:  coreDtos.ResourceProperties{ValueType: common.ValueTypeBool, ReadWrite: common.ReadWrite_W},
+			},
+			{
+				Name:        controlResourceCancelReplay,
+				Description: "Set to true to cancel the replay session",
+				Properties:  coreDtos.ResourceProperties{ValueType: common.ValueTypeBool, ReadWrite: common.ReadWrite_W},
+			},
+		},
+	}
+}
+
+// registerControlDevice adds the control device, and its device service and profile, to Core Metadata if they don't
+// exist. An error is returned if the base address isn't set or Core Metadata can't be updated.
+func (c *httpController) registerControlDevice() error {
+	name := c.controlDeviceName()
+	if len(name) == 0 {
+		return nil
+	}
+
+	baseAddress := c.appSdk.ApplicationSettings()[ControlBaseAddressAppSetting]
+	if len(baseAddress) == 0 {
+		return controlBaseAddressNotSet
+	}
+
+	serviceClient := c.appSdk.DeviceServiceClient()
+	if _, err := serviceClient.DeviceServiceByName(context.Background(), name); err != nil {
+		if err.Code() != http.StatusNotFound {
+			return fmt.Errorf("failed to check if device service %s exists: %w", name, err)
+		}
+
+		service := coreDtos.DeviceService{
+			Name:        name,
+			Description: controlDeviceDescription,
+			Labels:      controlDeviceLabels,
+			BaseAddress: baseAddress,
+			AdminState:  models.Unlocked,
+		}
+
+		if _, err := serviceClient.Add(context.Background(), []requests.AddDeviceServiceRequest{requests.NewAddDeviceServiceRequest(service)}); err != nil {
+			return fmt.Errorf("failed to add device service %s: %w", name, err)
+		}
+	}
+
+	profileClient := c.appSdk.DeviceProfileClient()
+	if _, err := profileClient.DeviceProfileByName(context.Background(), name); err != nil {
+		if err.Code() != http.StatusNotFound {
+			return fmt.Errorf("failed to check if device profile %s exists: %w", name, err)
+		}
+
+		request := requests.NewDeviceProfileRequest(controlDeviceProfile(name))
+		if _, err := profileClient.Add(context.Background(), []requests.DeviceProfileRequest{request}); err != nil {
+			return fmt.Errorf("failed to add device profile %s: %w", name, err)
+		}
+	}
+
+	deviceClient := c.appSdk.DeviceClient()
+	if _, err := deviceClient.DeviceByName(context.Background(), name); err != nil {
+		if err.Code() != http.StatusNotFound {
+			return fmt.Errorf("failed to check if device %s exists: %w", name, err)
+		}
+
+		device := coreDtos.Device{
+			Name:           name,
+			Description:    controlDeviceDescription,
+			Labels:         controlDeviceLabels,
+			AdminState:     models.Unlocked,
+			OperatingState: models.Up,
+			ServiceName:    name,
+			ProfileName:    name,
+			Protocols:      map[string]coreDtos.ProtocolProperties{},
+		}
+
+		if _, err := deviceClient.Add(context.Background(), []requests.AddDeviceRequest{requests.NewAddDeviceRequest(device)}); err != nil {
+			return fmt.Errorf("failed to add device %s: %w", name, err)
+		}
+	}
+
+	c.lc.Infof("ARR Control Device: Registered control device %s at %s", name, baseAddress)
+
+	return nil
+}
+
+// controlDeviceResponse writes the Core Command style response with the status code and message
+func controlDeviceResponse(ctx echo.Context, statusCode int, message string) error {
+	return ctx.JSON(statusCode, commonDTO.NewBaseResponse("", message, statusCode))
+}
+
+// readControlDevice handles the get commands of the control device sent by Core Command, returning the Event with the
+// session status read
+func (c *httpController) readControlDevice(ctx echo.Context) error {
+	name := ctx.Param(common.Name)
+	if len(c.controlDeviceName()) == 0 || name != c.controlDeviceName() {
+		return controlDeviceResponse(ctx, http.StatusNotFound, fmt.Sprintf("device %s not found", name))
+	}
+
+	command := ctx.Param(common.Command)
+	event := coreDtos.NewEvent(name, name, command)
+
+	switch command {
+	case controlResourceRecord:
+		event.AddObjectReading(command, c.dataManager.RecordingStatus())
+	case controlResourceReplay:
+		event.AddObjectReading(command, c.dataManager.ReplayStatus())
+	case controlResourceCancelRecord, controlResourceCancelReplay:
+		return controlDeviceResponse(ctx, http.StatusMethodNotAllowed, fmt.Sprintf("command %s is write-only", command))
+	default:
+		return controlDeviceResponse(ctx, http.StatusNotFound, fmt.Sprintf("command %s not found", command))
+	}
+
+	if returnEvent, err := strconv.ParseBool(ctx.QueryParam(common.ReturnEvent)); err == nil && !returnEvent {
+		return controlDeviceResponse(ctx, http.StatusOK, "")
+	}
+
+	return ctx.JSON(http.StatusOK, responses.NewEventResponse("", "", http.StatusOK, event))
+}
+
+// writeControlDevice handles the set commands of the control device sent by Core Command, starting or canceling
+// the session. The request holds the value of the command's resource, keyed by the resource name.
+func (c *httpController) writeControlDevice(ctx echo.Context) error {
+	name := ctx.Param(common.Name)
+	if len(c.controlDeviceName()) == 0 || name != c.controlDeviceName() {
+		return controlDeviceResponse(ctx, http.StatusNotFound, fmt.Sprintf("device %s not found", name))
+	}
+
+	command := ctx.Param(common.Command)
+
+	var values map[string]json.RawMessage
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&values); err != nil {
+		return controlDeviceResponse(ctx, http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	value, found := values[command]
+	if !found {
+		return controlDeviceResponse(ctx, http.StatusBadRequest, fmt.Sprintf("value of %s not set", command))
+	}
+
+	var err error
+	switch command {
+	case controlResourceRecord:
+		request := &dtos.RecordRequest{}
+		if err := decodeControlValue(value, request); err != nil {
+			return controlDeviceResponse(ctx, http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+		}
+		if failure := recordRequestFailure(request); len(failure) > 0 {
+			return controlDeviceResponse(ctx, http.StatusBadRequest, failure)
+		}
+		request.CorrelationID = correlationID(ctx)
+		err = c.dataManager.StartRecording(*request)
+	case controlResourceReplay:
+		request := &dtos.ReplayRequest{}
+		if err := decodeControlValue(value, request); err != nil {
+			return controlDeviceResponse(ctx, http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+		}
+		if failure := replayRequestFailure(request); len(failure) > 0 {
+			return controlDeviceResponse(ctx, http.StatusBadRequest, failure)
+		}
+		request.CorrelationID = correlationID(ctx)
+		err = c.dataManager.StartReplay(*request)
+	case controlResourceCancelRecord, controlResourceCancelReplay:
+		if err := checkControlValueTrue(value); err != nil {
+			return controlDeviceResponse(ctx, http.StatusBadRequest, fmt.Sprintf("invalid %s value: %v", command, err))
+		}
+		if command == controlResourceCancelRecord {
+			err = c.dataManager.CancelRecording()
+		} else {
+			err = c.dataManager.CancelReplay()
+		}
+	default:
+		return controlDeviceResponse(ctx, http.StatusNotFound, fmt.Sprintf("command %s not found", command))
+	}
+
+	if err != nil {
+		return controlDeviceResponse(ctx, http.StatusInternalServerError, fmt.Sprintf("%s command failed: %v", command, err))
+	}
+
+	return controlDeviceResponse(ctx, http.StatusOK, "")
+}
+
+// decodeControlValue decodes the request set as the value of a resource. The value may be the request object or,
+// since tooling often sends all values as strings, the request's JSON as a string.
+func decodeControlValue(value json.RawMessage, request any) error {
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		value = json.RawMessage(text)
+	}
+
+	return json.Unmarshal(value, request)
+}
+
+// checkControlValueTrue returns an error unless the value is true, either as a bool or a string
+func checkControlValueTrue(value json.RawMessage) error {
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		value = json.RawMessage(text)
+	}
+
+	set, err := strconv.ParseBool(string(value))
+	if err != nil || !set {
+		return controlValueNotTrue
+	}
+
+	return nil
+}
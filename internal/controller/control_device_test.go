@@ -0,0 +1,255 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	clientMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	commonDTO "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/common"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/requests"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos/responses"
+	edgexErr "github.com/edgexfoundry/go-mod-core-contracts/v3/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testControlDeviceName = "app-record-replay"
+
+func createControlDeviceTarget(settings map[string]string) (*httpController, *mocks.DataManager, *appMocks.ApplicationService) {
+	mockDataManager := &mocks.DataManager{}
+	mockSdk := &appMocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(settings)
+
+	target := New(mockDataManager, &mocks.Coordinator{}, nil, mockSdk).(*httpController)
+	return target, mockDataManager, mockSdk
+}
+
+func controlDeviceRequest(t *testing.T, handler echo.HandlerFunc, method string, name string, command string,
+	query string, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, common.ApiDeviceRoute+"/name/"+name+"/"+command+query, strings.NewReader(body))
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	ctx := echo.New().NewContext(req, resp)
+	ctx.SetParamNames(common.Name, common.Command)
+	ctx.SetParamValues(name, command)
+
+	require.NoError(t, handler(ctx))
+	return resp
+}
+
+func TestHttpController_RegisterControlDevice(t *testing.T) {
+	notFound := edgexErr.NewCommonEdgeX(edgexErr.KindEntityDoesNotExist, "not found", nil)
+	settings := map[string]string{
+		ControlDeviceNameAppSetting:  testControlDeviceName,
+		ControlBaseAddressAppSetting: "http://app-record-replay:59712",
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		target, _, _ := createControlDeviceTarget(map[string]string{})
+		require.NoError(t, target.registerControlDevice())
+	})
+
+	t.Run("Base address not set", func(t *testing.T) {
+		target, _, mockSdk := createControlDeviceTarget(map[string]string{ControlDeviceNameAppSetting: testControlDeviceName})
+		mockSdk.On("AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		require.Equal(t, controlBaseAddressNotSet, target.registerControlDevice())
+		require.Equal(t, controlBaseAddressNotSet, target.AddRoutes())
+	})
+
+	t.Run("Added", func(t *testing.T) {
+		target, _, mockSdk := createControlDeviceTarget(settings)
+
+		mockServiceClient := &clientMocks.DeviceServiceClient{}
+		mockServiceClient.On("DeviceServiceByName", mock.Anything, testControlDeviceName).Return(responses.DeviceServiceResponse{}, notFound)
+		mockServiceClient.On("Add", mock.Anything, mock.MatchedBy(func(reqs []requests.AddDeviceServiceRequest) bool {
+			return len(reqs) == 1 && reqs[0].Service.BaseAddress == settings[ControlBaseAddressAppSetting]
+		})).Return(nil, nil).Once()
+
+		mockProfileClient := &clientMocks.DeviceProfileClient{}
+		mockProfileClient.On("DeviceProfileByName", mock.Anything, testControlDeviceName).Return(responses.DeviceProfileResponse{}, notFound)
+		mockProfileClient.On("Add", mock.Anything, mock.MatchedBy(func(reqs []requests.DeviceProfileRequest) bool {
+			return len(reqs) == 1 && len(reqs[0].Profile.DeviceResources) == 4
+		})).Return(nil, nil).Once()
+
+		mockDeviceClient := &clientMocks.DeviceClient{}
+		mockDeviceClient.On("DeviceByName", mock.Anything, testControlDeviceName).Return(responses.DeviceResponse{}, notFound)
+		mockDeviceClient.On("Add", mock.Anything, mock.MatchedBy(func(reqs []requests.AddDeviceRequest) bool {
+			return len(reqs) == 1 && reqs[0].Device.ServiceName == testControlDeviceName &&
+				reqs[0].Device.ProfileName == testControlDeviceName
+		})).Return(nil, nil).Once()
+
+		mockSdk.On("DeviceServiceClient").Return(mockServiceClient)
+		mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+		mockSdk.On("DeviceClient").Return(mockDeviceClient)
+
+		require.NoError(t, target.registerControlDevice())
+		mockServiceClient.AssertExpectations(t)
+		mockProfileClient.AssertExpectations(t)
+		mockDeviceClient.AssertExpectations(t)
+	})
+
+	t.Run("Already registered", func(t *testing.T) {
+		target, _, mockSdk := createControlDeviceTarget(settings)
+
+		mockServiceClient := &clientMocks.DeviceServiceClient{}
+		mockServiceClient.On("DeviceServiceByName", mock.Anything, testControlDeviceName).Return(responses.DeviceServiceResponse{}, nil)
+		mockProfileClient := &clientMocks.DeviceProfileClient{}
+		mockProfileClient.On("DeviceProfileByName", mock.Anything, testControlDeviceName).Return(responses.DeviceProfileResponse{}, nil)
+		mockDeviceClient := &clientMocks.DeviceClient{}
+		mockDeviceClient.On("DeviceByName", mock.Anything, testControlDeviceName).Return(responses.DeviceResponse{}, nil)
+
+		mockSdk.On("DeviceServiceClient").Return(mockServiceClient)
+		mockSdk.On("DeviceProfileClient").Return(mockProfileClient)
+		mockSdk.On("DeviceClient").Return(mockDeviceClient)
+
+		require.NoError(t, target.registerControlDevice())
+		mockServiceClient.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+		mockProfileClient.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+		mockDeviceClient.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Core Metadata unavailable", func(t *testing.T) {
+		target, _, mockSdk := createControlDeviceTarget(settings)
+
+		mockServiceClient := &clientMocks.DeviceServiceClient{}
+		mockServiceClient.On("DeviceServiceByName", mock.Anything, testControlDeviceName).
+			Return(responses.DeviceServiceResponse{}, edgexErr.NewCommonEdgeX(edgexErr.KindServiceUnavailable, "unavailable", nil))
+		mockSdk.On("DeviceServiceClient").Return(mockServiceClient)
+		mockSdk.On("AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		require.Error(t, target.registerControlDevice())
+		// The routes are still added so the device can be controlled once registered
+		require.NoError(t, target.AddRoutes())
+	})
+}
+
+func TestHttpController_ReadControlDevice(t *testing.T) {
+	target, mockDataManager, _ := createControlDeviceTarget(map[string]string{ControlDeviceNameAppSetting: testControlDeviceName})
+	mockDataManager.On("RecordingStatus").Return(dtos.RecordStatus{InProgress: true, EventCount: 5})
+	mockDataManager.On("ReplayStatus").Return(dtos.ReplayStatus{Running: true})
+
+	tests := []struct {
+		Name           string
+		DeviceName     string
+		Command        string
+		Query          string
+		ExpectedStatus int
+		ExpectedEvent  bool
+	}{
+		{"Record", testControlDeviceName, controlResourceRecord, "", http.StatusOK, true},
+		{"Replay", testControlDeviceName, controlResourceReplay, "?ds-returnevent=true", http.StatusOK, true},
+		{"No event returned", testControlDeviceName, controlResourceRecord, "?ds-returnevent=false", http.StatusOK, false},
+		{"Write-only", testControlDeviceName, controlResourceCancelRecord, "", http.StatusMethodNotAllowed, false},
+		{"Unknown command", testControlDeviceName, "Pause", "", http.StatusNotFound, false},
+		{"Unknown device", "D1", controlResourceRecord, "", http.StatusNotFound, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			resp := controlDeviceRequest(t, target.readControlDevice, http.MethodGet, test.DeviceName, test.Command, test.Query, "")
+			require.Equal(t, test.ExpectedStatus, resp.Code)
+
+			response := responses.EventResponse{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+			assert.Equal(t, test.ExpectedStatus, response.StatusCode)
+
+			if !test.ExpectedEvent {
+				assert.Empty(t, response.Event.Readings)
+				return
+			}
+
+			require.Len(t, response.Event.Readings, 1)
+			assert.Equal(t, test.Command, response.Event.Readings[0].ResourceName)
+			assert.Equal(t, common.ValueTypeObject, response.Event.Readings[0].ValueType)
+			assert.NotNil(t, response.Event.Readings[0].ObjectValue)
+		})
+	}
+}
+
+func TestHttpController_WriteControlDevice(t *testing.T) {
+	recordRequest := dtos.RecordRequest{EventLimit: 10}
+	replayRequest := dtos.ReplayRequest{ReplayRate: 2}
+
+	tests := []struct {
+		Name           string
+		DeviceName     string
+		Command        string
+		Body           string
+		ManagerCall    string
+		ManagerArgs    []interface{}
+		ManagerError   error
+		ExpectedStatus int
+	}{
+		{"Start recording", testControlDeviceName, controlResourceRecord, `{"Record":{"eventLimit":10}}`,
+			"StartRecording", []interface{}{recordRequest}, nil, http.StatusOK},
+		{"Start replay - JSON string", testControlDeviceName, controlResourceReplay, `{"Replay":"{\"replayRate\":2}"}`,
+			"StartReplay", []interface{}{replayRequest}, nil, http.StatusOK},
+		{"Start replay - Failed", testControlDeviceName, controlResourceReplay, `{"Replay":{"replayRate":2}}`,
+			"StartReplay", []interface{}{replayRequest}, errors.New("failed"), http.StatusInternalServerError},
+		{"Cancel recording", testControlDeviceName, controlResourceCancelRecord, `{"CancelRecord":true}`,
+			"CancelRecording", nil, nil, http.StatusOK},
+		{"Cancel replay - String", testControlDeviceName, controlResourceCancelReplay, `{"CancelReplay":"true"}`,
+			"CancelReplay", nil, nil, http.StatusOK},
+		{"Cancel replay - False", testControlDeviceName, controlResourceCancelReplay, `{"CancelReplay":false}`,
+			"", nil, nil, http.StatusBadRequest},
+		{"Invalid record request", testControlDeviceName, controlResourceRecord, `{"Record":{}}`,
+			"", nil, nil, http.StatusBadRequest},
+		{"Bad request JSON", testControlDeviceName, controlResourceRecord, `{"Record":"{"}`,
+			"", nil, nil, http.StatusBadRequest},
+		{"Value not set", testControlDeviceName, controlResourceRecord, `{"Replay":{}}`,
+			"", nil, nil, http.StatusBadRequest},
+		{"Bad body", testControlDeviceName, controlResourceRecord, `[`,
+			"", nil, nil, http.StatusBadRequest},
+		{"Unknown command", testControlDeviceName, "Pause", `{"Pause":true}`,
+			"", nil, nil, http.StatusNotFound},
+		{"Unknown device", "D1", controlResourceRecord, `{"Record":{"eventLimit":10}}`,
+			"", nil, nil, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createControlDeviceTarget(map[string]string{ControlDeviceNameAppSetting: testControlDeviceName})
+			if len(test.ManagerCall) > 0 {
+				mockDataManager.On(test.ManagerCall, test.ManagerArgs...).Return(test.ManagerError).Once()
+			}
+
+			resp := controlDeviceRequest(t, target.writeControlDevice, http.MethodPut, test.DeviceName, test.Command, "", test.Body)
+			require.Equal(t, test.ExpectedStatus, resp.Code)
+
+			response := commonDTO.BaseResponse{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+			assert.Equal(t, test.ExpectedStatus, response.StatusCode)
+			mockDataManager.AssertExpectations(t)
+		})
+	}
+}
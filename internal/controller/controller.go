@@ -16,26 +16,76 @@
 package controller
 
 import (
-	"compress/gzip"
-	"compress/zlib"
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/labstack/echo/v4"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
 	"github.com/edgexfoundry/app-record-replay/internal/interfaces"
 	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/yuin/gopher-lua/parse"
 )
 
 const (
-	recordRoute = common.ApiBase + "/record"
-	replayRoute = common.ApiBase + "/replay"
-	dataRoute   = common.ApiBase + "/data"
+	recordRoute     = common.ApiBase + "/record"
+	pushRoute       = recordRoute + "/events"
+	replayRoute     = common.ApiBase + "/replay"
+	shadowRoute     = replayRoute + "/shadow"
+	latencyRoute    = replayRoute + "/latency"
+	triggerRoute    = replayRoute + "/trigger"
+	seekRoute       = replayRoute + "/seek"
+	resumeRoute     = replayRoute + "/resume"
+	stepRoute       = replayRoute + "/step"
+	playlistRoute   = replayRoute + "/playlist"
+	dataRoute       = common.ApiBase + "/data"
+	assertRoute     = dataRoute + "/assert"
+	validateRoute   = dataRoute + "/validate"
+	dependsRoute    = dataRoute + "/dependencies"
+	lockRoute       = dataRoute + "/lock"
+	metadataRoute   = dataRoute + "/metadata"
+	exportRoute     = dataRoute + "/export"
+	exportSizeRoute = dataRoute + "/size"
+	statsRoute      = dataRoute + "/stats"
+	sizesRoute      = dataRoute + "/sizes"
+	gapsRoute       = dataRoute + "/gaps"
+	eventsRoute     = dataRoute + "/events"
+	annotateRoute   = dataRoute + "/annotations"
+	downsampleRoute = dataRoute + "/downsample"
+	compactRoute    = dataRoute + "/compact"
+	exportLinkRoute = dataRoute + "/link"
+	compareRoute    = dataRoute + "/compare/:" + compareAParam + "/:" + compareBParam + "/series"
+	jobsRoute       = common.ApiBase + "/jobs"
+	jobRoute        = jobsRoute + "/:" + jobIdParam
+	injectRoute     = common.ApiBase + "/inject"
+	backupRoute     = common.ApiBase + "/admin/backup"
+	restoreRoute    = common.ApiBase + "/admin/restore"
+	selfTestRoute   = common.ApiBase + "/admin/selftest"
+	pipelineRoute   = common.ApiBase + "/debug/pipeline"
+
+	// topParam is the optional payload size report query parameter with the number of largest devices and Readings
+	topParam = "top"
+	// thresholdParam is the required gap report query parameter with the longest interval between Readings that
+	// isn't a gap
+	thresholdParam = "threshold"
+	// startParam and endParam are the required Event deletion query parameters with the start and end of the range,
+	// either in nanoseconds since the epoch or as RFC3339 times
+	startParam = "start"
+	endParam   = "end"
+	// deviceParam is the optional Event deletion query parameter with the name of the device to limit the deletion to
+	deviceParam = "device"
+	// importRollbackParam is the optional import query parameter which, when true, deletes the Device Profiles and
+	// Devices added by an import if any failed to be provisioned, rather than importing the data regardless
+	importRollbackParam = "rollback"
 
 	failedRouteMessage = "failed to added %s route for %s method: %v"
 
@@ -43,34 +93,88 @@ const (
 	failedRecordRequestValidate    = "Record request failed validation: Duration and/or EventLimit must be set"
 	failedRecordDurationValidate   = "Record request failed validation: Duration must be > 0 when set"
 	failedRecordEventLimitValidate = "Record request failed validation: Event Limit must be > 0 when set"
+	failedRecordRotationValidate   = "Record request failed validation: Rotation MaxSegments, MaxAge and MaxBytes must be equal or greater than 0"
 	failedRecording                = "Recording failed"
+	failedPushRequestValidate      = "Push request failed validation: at least one Event must be specified"
 	failedReplayRateValidate       = "Replay request failed validation: Replay Rate must be greater than 0"
+	failedTimeWarpValidate         = "Replay request failed validation: Time Warp Duration and Time Warp Start must be equal or greater than 0"
+	failedTimeWarpRateValidate     = "Replay request failed validation: Replay Rate must not be set when Time Warp Duration is set"
+	failedAlignTimeOfDayValidate   = "Replay request failed validation: Replay Rate, Time Warp Duration and Device Priorities must not be set when Align Time Of Day is set"
 	failedRepeatCountValidate      = "Replay request failed validation: Repeat Count must be equal or greater than 0"
+	failedReplayScriptValidate     = "Replay request failed validation: Script must be a valid Lua script"
+	failedMaxReplayLagValidate     = "Replay request failed validation: Max Replay Lag must be equal or greater than 0"
+	failedReplayWarmupValidate     = "Replay request failed validation: Warmup must be empty, full or background"
+	failedOnPublishErrorValidate   = "Replay request failed validation: OnPublishError must be empty, abort, skip or retry"
+	failedPublishRetryValidate     = "Replay request failed validation: MaxPublishRetries, PublishRetryInterval and MaxPublishRetryInterval must be equal or greater than 0"
+	failedFanOutValidate           = "Replay request failed validation: FanOut and FanOutOffset must be equal or greater than 0"
+	failedPublishWorkersValidate   = "Replay request failed validation: PublishWorkers must be equal or greater than 0"
+	failedReplaySinksValidate      = "Replay request failed validation: Sinks must have a Type of messagebus, mqtt, http, edgex-messagebus or edgex-coredata and an OnPublishError that is empty, abort, skip or retry"
 	failedReplay                   = "Replay failed"
+	failedSeekRequestValidate      = "Seek request failed validation: Offset must be equal or greater than 0 and must not be set when Annotation is set"
 	failedDataCompression          = "failed to compress recorded data of type"
 	failedToUncompressData         = "failed to uncompress data"
 	failedImportingData            = "Import data failed"
+	failedImportLimit              = "Import data exceeds the import limits"
+	failedImportTransform          = "Import data transform failed"
+	failedSigningData              = "failed to sign recorded data"
+	failedAnonymizing              = "failed to anonymize recorded data"
+	failedSampling                 = "failed to sample recorded data"
+	failedLocalExport              = "Export to local path failed"
+	failedVerifyingData            = "failed to verify signature of imported data"
+	failedAssertRequestValidate    = "Assert request failed validation: at least one assertion must be specified"
+	failedAssertingData            = "Assert data failed"
+	failedValidatingData           = "Validate data failed"
+	failedDependencyGraph          = "Dependency graph failed"
+	failedTopValidate              = "top must be an integer greater than 0"
+	failedThresholdValidate        = "threshold must be a duration greater than 0"
+	failedCompareValidate          = "Compare request failed validation: resource must be set"
+	failedAnnotationValidate       = "Annotation failed validation: label must be set"
+	failedAnnotationRangeValidate  = "Annotation failed validation: end must not be before timestamp"
+	failedLabelPatternValidate     = "label must be a valid pattern"
+	failedDeleteRangeValidate      = "start and end must be nanoseconds since the epoch or RFC3339 times, with end after start"
+	failedDownsampleValidate       = "Downsample request failed validation"
+	failedPlaylistValidate         = "Playlist failed validation"
+	failedSummaryWindowValidate    = "Export request failed validation: window must be a duration greater than 0"
+	failedFeaturesValidate         = "Export request failed validation"
+	failedInjectRequestValidate    = "Inject request failed validation: at least one Event or message must be specified and each message must have a Topic"
+	failedInject                   = "Inject failed"
+	failedStatusWaitValidate       = "waitForCompletion must be true or false and timeout must be a duration greater than 0 and at most %s"
+	failedSelfTestValidate         = "Self-test request failed validation: EventCount must be between 0 and 10000 and Interval and TimingTolerance must be equal or greater than 0"
+	failedSelfTestDuration         = "Self-test request failed validation: EventCount times Interval of %s must be at most %s, so the self-test completes within the Service.RequestTimeout of %s"
+	failedSelfTest                 = "Self-test failed"
 	noDataFound                    = "no recorded data found"
 
-	noCompression       = ""
-	zlibCompression     = "zlib"
-	gzipCompression     = "gzip"
-	contentEncodingGzip = "gzip"
-	contentEncodingZlib = "deflate" // standard value used for zlib is deflate
+	noCompression = ""
+
+	nativeFormat  = ""
+	ekuiperFormat = "ekuiper"
+	summaryFormat = "summary"
 )
 
 type httpController struct {
-	lc          logger.LoggingClient
-	dataManager interfaces.DataManager
-	appSdk      appInterfaces.ApplicationService
+	lc           logger.LoggingClient
+	dataManager  interfaces.DataManager
+	coordinator  interfaces.Coordinator
+	virtualClock interfaces.VirtualClock
+	appSdk       appInterfaces.ApplicationService
+	exportLinks  *exportLinks
+	jobs         *jobs
+	playlist     *playlistRunner
 }
 
 // New is the factory function which instantiates a new HTTP Controller
-func New(dataManager interfaces.DataManager, appSdk appInterfaces.ApplicationService) interfaces.HttpController {
+// The virtualClock is nil unless the service runs with the virtual clock, in which case the clock routes are added.
+func New(dataManager interfaces.DataManager, coordinator interfaces.Coordinator, virtualClock interfaces.VirtualClock,
+	appSdk appInterfaces.ApplicationService) interfaces.HttpController {
 	return &httpController{
-		lc:          appSdk.LoggingClient(),
-		dataManager: dataManager,
-		appSdk:      appSdk,
+		lc:           appSdk.LoggingClient(),
+		dataManager:  dataManager,
+		coordinator:  coordinator,
+		virtualClock: virtualClock,
+		appSdk:       correlatedRoutes{ApplicationService: appSdk, lc: appSdk.LoggingClient()},
+		exportLinks:  newExportLinks(),
+		jobs:         newJobs(),
+		playlist:     newPlaylistRunner(),
 	}
 }
 
@@ -85,6 +189,9 @@ func (c *httpController) AddRoutes() error {
 	if err := c.appSdk.AddCustomRoute(recordRoute, false, c.cancelRecording, http.MethodDelete); err != nil {
 		return fmt.Errorf(failedRouteMessage, recordRoute, http.MethodDelete, err)
 	}
+	if err := c.appSdk.AddCustomRoute(pushRoute, false, c.pushRecordEvents, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, pushRoute, http.MethodPost, err)
+	}
 
 	if err := c.appSdk.AddCustomRoute(replayRoute, false, c.startReplay, http.MethodPost); err != nil {
 		return fmt.Errorf(failedRouteMessage, replayRoute, http.MethodPost, err)
@@ -95,13 +202,159 @@ func (c *httpController) AddRoutes() error {
 	if err := c.appSdk.AddCustomRoute(replayRoute, false, c.cancelReplay, http.MethodDelete); err != nil {
 		return fmt.Errorf(failedRouteMessage, replayRoute, http.MethodDelete, err)
 	}
+	if err := c.appSdk.AddCustomRoute(shadowRoute, false, c.shadowReport, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, shadowRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(latencyRoute, false, c.latencyReport, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, latencyRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(triggerRoute, false, c.triggerReplay, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, triggerRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(seekRoute, false, c.seekReplay, http.MethodPatch); err != nil {
+		return fmt.Errorf(failedRouteMessage, seekRoute, http.MethodPatch, err)
+	}
+	if err := c.appSdk.AddCustomRoute(resumeRoute, false, c.resumeReplay, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, resumeRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(stepRoute, false, c.stepReplay, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, stepRoute, http.MethodPost, err)
+	}
+
+	if err := c.appSdk.AddCustomRoute(playlistRoute, false, c.startPlaylist, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, playlistRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(playlistRoute, false, c.playlistStatus, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, playlistRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(playlistRoute, false, c.cancelPlaylist, http.MethodDelete); err != nil {
+		return fmt.Errorf(failedRouteMessage, playlistRoute, http.MethodDelete, err)
+	}
+	if err := c.appSdk.AddCustomRoute(playlistDefinitionRoute, false, c.exportPlaylist, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, playlistDefinitionRoute, http.MethodGet, err)
+	}
+
+	if err := c.appSdk.AddCustomRoute(deviceCommandRoute, false, c.readControlDevice, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, deviceCommandRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(deviceCommandRoute, false, c.writeControlDevice, http.MethodPut); err != nil {
+		return fmt.Errorf(failedRouteMessage, deviceCommandRoute, http.MethodPut, err)
+	}
 
 	if err := c.appSdk.AddCustomRoute(dataRoute, false, c.exportRecordedData, http.MethodGet); err != nil {
 		return fmt.Errorf(failedRouteMessage, dataRoute, http.MethodGet, err)
 	}
-	if err := c.appSdk.AddCustomRoute(dataRoute, false, c.importRecordedData, http.MethodPost); err != nil {
+	if err := c.appSdk.AddCustomRoute(dataRoute, false, c.asyncJob(dtos.JobKindImport, c.importRecordedData), http.MethodPost); err != nil {
 		return fmt.Errorf(failedRouteMessage, dataRoute, http.MethodPost, err)
 	}
+	if err := c.appSdk.AddCustomRoute(assertRoute, false, c.assertRecordedData, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, assertRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(validateRoute, false, c.validateRecordedData, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, validateRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(dependsRoute, false, c.dependencyGraph, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, dependsRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(lockRoute, false, c.lockRecordedData, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, lockRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(lockRoute, false, c.unlockRecordedData, http.MethodDelete); err != nil {
+		return fmt.Errorf(failedRouteMessage, lockRoute, http.MethodDelete, err)
+	}
+	if err := c.appSdk.AddCustomRoute(metadataRoute, false, c.recordingMetadata, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, metadataRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(downsampleRoute, false, c.asyncJob(dtos.JobKindExport, c.downsampleToPath), http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, downsampleRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(exportRoute, false, c.asyncJob(dtos.JobKindExport, c.exportRecordedDataToPath), http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, exportRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(exportSizeRoute, false, c.estimateExportSize, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, exportSizeRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(exportSizeRoute, false, c.estimateExportSize, http.MethodHead); err != nil {
+		return fmt.Errorf(failedRouteMessage, exportSizeRoute, http.MethodHead, err)
+	}
+	if err := c.appSdk.AddCustomRoute(statsRoute, false, c.storeStats, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, statsRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(sizesRoute, false, c.payloadSizeReport, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, sizesRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(gapsRoute, false, c.recordedDataGaps, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, gapsRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(eventsRoute, false, c.deleteRecordedEvents, http.MethodDelete); err != nil {
+		return fmt.Errorf(failedRouteMessage, eventsRoute, http.MethodDelete, err)
+	}
+	if err := c.appSdk.AddCustomRoute(annotateRoute, false, c.addAnnotation, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, annotateRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(annotateRoute, false, c.searchAnnotations, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, annotateRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(compareRoute, false, c.compareSeries, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, compareRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(compactRoute, false, c.compactStore, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, compactRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(exportLinkRoute, false, c.mintExportLink, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, exportLinkRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(exportLinkRoute, false, c.downloadExportLink, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, exportLinkRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(jobRoute, false, c.jobStatus, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, jobRoute, http.MethodGet, err)
+	}
+	if err := c.appSdk.AddCustomRoute(jobRoute, false, c.cancelJob, http.MethodDelete); err != nil {
+		return fmt.Errorf(failedRouteMessage, jobRoute, http.MethodDelete, err)
+	}
+
+	if err := c.appSdk.AddCustomRoute(injectRoute, false, c.inject, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, injectRoute, http.MethodPost, err)
+	}
+
+	if err := c.appSdk.AddCustomRoute(backupRoute, false, c.backupStore, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, backupRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(restoreRoute, false, c.restoreStore, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, restoreRoute, http.MethodPost, err)
+	}
+	if err := c.appSdk.AddCustomRoute(selfTestRoute, false, c.selfTest, http.MethodPost); err != nil {
+		return fmt.Errorf(failedRouteMessage, selfTestRoute, http.MethodPost, err)
+	}
+
+	if err := c.appSdk.AddCustomRoute(pipelineRoute, false, c.pipelineStats, http.MethodGet); err != nil {
+		return fmt.Errorf(failedRouteMessage, pipelineRoute, http.MethodGet, err)
+	}
+
+	if err := c.addClusterRoutes(); err != nil {
+		return err
+	}
+
+	if err := c.addClockRoutes(); err != nil {
+		return err
+	}
+
+	if err := c.addCompatRoutes(); err != nil {
+		return err
+	}
+
+	if err := c.addProfilingRoutes(); err != nil {
+		return err
+	}
+
+	// Core Metadata may not be available yet, so a failed registration doesn't stop the service
+	if err := c.registerControlDevice(); err != nil {
+		if errors.Is(err, controlBaseAddressNotSet) {
+			return err
+		}
+		c.lc.Errorf("ARR Control Device: %v", err)
+	}
 
 	c.lc.Info("Add Record & Replay routes")
 
@@ -117,18 +370,11 @@ func (c *httpController) startRecording(ctx echo.Context) error {
 		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
 	}
 
-	if startRequest.Duration == 0 && startRequest.EventLimit == 0 {
-		return ctx.String(http.StatusBadRequest, failedRecordRequestValidate)
-	}
-
-	if startRequest.Duration < 0 {
-		return ctx.String(http.StatusBadRequest, failedRecordDurationValidate)
-	}
-
-	if startRequest.EventLimit < 0 {
-		return ctx.String(http.StatusBadRequest, failedRecordEventLimitValidate)
+	if failure := recordRequestFailure(startRequest); len(failure) > 0 {
+		return ctx.String(http.StatusBadRequest, failure)
 	}
 
+	startRequest.CorrelationID = correlationID(ctx)
 	if err := c.dataManager.StartRecording(*startRequest); err != nil {
 		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedRecording, err))
 	}
@@ -146,8 +392,37 @@ func (c *httpController) cancelRecording(ctx echo.Context) error {
 	return nil
 }
 
-// recordingStatus returns the status of the current recording session as the HTTP response.
+// pushRecordEvents includes the Events pushed in the ctx.Request() in the active recording session.
+func (c *httpController) pushRecordEvents(ctx echo.Context) error {
+	pushRequest := &dtos.RecordPushRequest{}
+
+	if err := json.NewDecoder(ctx.Request().Body).Decode(pushRequest); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	if len(pushRequest.Events) == 0 {
+		return ctx.String(http.StatusBadRequest, failedPushRequestValidate)
+	}
+
+	if err := c.dataManager.PushRecordEvents(*pushRequest); err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to push Events to recording: %v", err))
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// recordingStatus returns the status of the current recording session as the HTTP response. With the
+// waitForCompletion query parameter, the status is returned once the recording and any queued recordings have ended.
 func (c *httpController) recordingStatus(ctx echo.Context) error {
+	ended := func() bool {
+		status := c.dataManager.RecordingStatus()
+		return !status.InProgress && len(status.Queue) == 0
+	}
+	limit := statusWaitLimit(c.appSdk.RequestTimeout())
+	if !waitForCompletion(ctx, limit, ended) {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf(failedStatusWaitValidate, limit))
+	}
+
 	recordingStatus := c.dataManager.RecordingStatus()
 
 	jsonResponse, err := json.Marshal(recordingStatus)
@@ -155,6 +430,11 @@ func (c *httpController) recordingStatus(ctx echo.Context) error {
 		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal recording status: %s", err))
 	}
 
+	// Status is unchanged while idle, so repeated polling with If-None-Match is cheap
+	if setETag(ctx, jsonResponse) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
 	return ctx.String(http.StatusOK, string(jsonResponse))
 }
 
@@ -167,14 +447,11 @@ func (c *httpController) startReplay(ctx echo.Context) error {
 		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
 	}
 
-	if startRequest.ReplayRate <= 0 {
-		return ctx.String(http.StatusBadRequest, failedReplayRateValidate)
-	}
-
-	if startRequest.RepeatCount < 0 {
-		return ctx.String(http.StatusBadRequest, failedRepeatCountValidate)
+	if failure := replayRequestFailure(startRequest); len(failure) > 0 {
+		return ctx.String(http.StatusBadRequest, failure)
 	}
 
+	startRequest.CorrelationID = correlationID(ctx)
 	if err := c.dataManager.StartReplay(*startRequest); err != nil {
 		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedReplay, err))
 	}
@@ -182,6 +459,101 @@ func (c *httpController) startReplay(ctx echo.Context) error {
 	return ctx.NoContent(http.StatusAccepted)
 }
 
+// recordRequestFailure returns the validation failure of the record request, or empty if it is valid
+func recordRequestFailure(request *dtos.RecordRequest) string {
+	if request.Duration == 0 && request.EventLimit == 0 {
+		return failedRecordRequestValidate
+	}
+
+	if request.Duration < 0 {
+		return failedRecordDurationValidate
+	}
+
+	if request.EventLimit < 0 {
+		return failedRecordEventLimitValidate
+	}
+
+	if rotation := request.Rotation; rotation != nil &&
+		(rotation.MaxSegments < 0 || rotation.MaxAge < 0 || rotation.MaxBytes < 0) {
+		return failedRecordRotationValidate
+	}
+
+	return ""
+}
+
+// replayRequestFailure returns the validation failure of the replay request, or empty if it is valid
+func replayRequestFailure(request *dtos.ReplayRequest) string {
+	if request.TimeWarpDuration < 0 || request.TimeWarpStart < 0 {
+		return failedTimeWarpValidate
+	}
+
+	if request.AlignTimeOfDay &&
+		(request.ReplayRate != 0 || request.TimeWarpDuration > 0 || len(request.DevicePriorities) > 0) {
+		return failedAlignTimeOfDayValidate
+	}
+
+	if request.TimeWarpDuration > 0 && request.ReplayRate != 0 {
+		return failedTimeWarpRateValidate
+	}
+
+	if request.TimeWarpDuration == 0 && !request.AlignTimeOfDay && request.ReplayRate <= 0 {
+		return failedReplayRateValidate
+	}
+
+	if request.RepeatCount < 0 {
+		return failedRepeatCountValidate
+	}
+
+	if len(request.Script) > 0 {
+		if _, err := parse.Parse(strings.NewReader(request.Script), "script"); err != nil {
+			return fmt.Sprintf("%s: %v", failedReplayScriptValidate, err)
+		}
+	}
+
+	if request.MaxReplayLag < 0 {
+		return failedMaxReplayLagValidate
+	}
+
+	if len(request.Warmup) > 0 && request.Warmup != dtos.ReplayWarmupFull && request.Warmup != dtos.ReplayWarmupBackground {
+		return failedReplayWarmupValidate
+	}
+
+	switch request.OnPublishError {
+	case "", dtos.ReplayPublishErrorAbort, dtos.ReplayPublishErrorSkip, dtos.ReplayPublishErrorRetry:
+	default:
+		return failedOnPublishErrorValidate
+	}
+
+	if request.MaxPublishRetries < 0 || request.PublishRetryInterval < 0 || request.MaxPublishRetryInterval < 0 {
+		return failedPublishRetryValidate
+	}
+
+	if request.FanOut < 0 || request.FanOutOffset < 0 {
+		return failedFanOutValidate
+	}
+
+	if request.PublishWorkers < 0 {
+		return failedPublishWorkersValidate
+	}
+
+	for _, sink := range request.Sinks {
+		switch sink.Type {
+		case dtos.ReplaySinkMessageBus, dtos.ReplaySinkMQTT, dtos.ReplaySinkHTTP, dtos.ReplaySinkEdgeXMessageBus,
+			dtos.ReplaySinkEdgeXCoreData:
+		default:
+			return failedReplaySinksValidate
+		}
+
+		switch sink.OnPublishError {
+		case "", dtos.ReplayPublishErrorAbort, dtos.ReplayPublishErrorSkip, dtos.ReplayPublishErrorRetry:
+		default:
+			return failedReplaySinksValidate
+		}
+	}
+
+	return ""
+}
+
 // cancelReplay cancels the current replay session as the HTTP response.
 func (c *httpController) cancelReplay(ctx echo.Context) error {
 	if err := c.dataManager.CancelReplay(); err != nil {
@@ -191,8 +563,70 @@ func (c *httpController) cancelReplay(ctx echo.Context) error {
 	return ctx.NoContent(http.StatusAccepted)
 }
 
-// replayStatus returns the status of the current replay session as the HTTP response.
+// triggerReplay starts publishing the replay in standby
+func (c *httpController) triggerReplay(ctx echo.Context) error {
+	if err := c.dataManager.TriggerReplay(); err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to trigger replay: %v", err))
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// seekReplay jumps the active replay session to the offset or annotation in the request, returning where it jumps
+// to as the HTTP response
+func (c *httpController) seekReplay(ctx echo.Context) error {
+	request := dtos.ReplaySeekRequest{}
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&request); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	if request.Offset < 0 || (len(request.Annotation) > 0 && request.Offset != 0) {
+		return ctx.String(http.StatusBadRequest, failedSeekRequestValidate)
+	}
+
+	response, err := c.dataManager.SeekReplay(request)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to seek replay: %v", err))
+	}
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal seek response: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// resumeReplay continues the paused replay session until the next Event matching a breakpoint
+func (c *httpController) resumeReplay(ctx echo.Context) error {
+	if err := c.dataManager.ResumeReplay(); err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to resume replay: %v", err))
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// stepReplay publishes the Event the replay session is paused before and pauses again before the next Event
+func (c *httpController) stepReplay(ctx echo.Context) error {
+	if err := c.dataManager.StepReplay(); err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to step replay: %v", err))
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// replayStatus returns the status of the current replay session as the HTTP response. With the waitForCompletion
+// query parameter, the status is returned once the replay and any queued replays have ended.
 func (c *httpController) replayStatus(ctx echo.Context) error {
+	ended := func() bool {
+		status := c.dataManager.ReplayStatus()
+		return !status.Running && !status.Standby && len(status.Queue) == 0
+	}
+	limit := statusWaitLimit(c.appSdk.RequestTimeout())
+	if !waitForCompletion(ctx, limit, ended) {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf(failedStatusWaitValidate, limit))
+	}
+
 	replayStatus := c.dataManager.ReplayStatus()
 
 	jsonResponse, err := json.Marshal(replayStatus)
@@ -200,6 +634,184 @@ func (c *httpController) replayStatus(ctx echo.Context) error {
 		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal replay status: %s", err))
 	}
 
+	// Status is unchanged while idle, so repeated polling with If-None-Match is cheap
+	if setETag(ctx, jsonResponse) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// shadowReport returns the comparison report for the current or last shadow mode replay session as the HTTP response.
+func (c *httpController) shadowReport(ctx echo.Context) error {
+	report, err := c.dataManager.ShadowReport()
+	if err != nil {
+		return ctx.String(http.StatusNotFound, fmt.Sprintf("failed to get shadow report: %v", err))
+	}
+
+	jsonResponse, err := json.Marshal(report)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal shadow report: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// latencyReport returns the end-to-end latency report for the current or last latency measurement replay session as
+// the HTTP response.
+func (c *httpController) latencyReport(ctx echo.Context) error {
+	report, err := c.dataManager.LatencyReport()
+	if err != nil {
+		return ctx.String(http.StatusNotFound, fmt.Sprintf("failed to get latency report: %v", err))
+	}
+
+	jsonResponse, err := json.Marshal(report)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal latency report: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// recordingMetadata returns the metadata describing where and how the current or last recording was captured
+func (c *httpController) recordingMetadata(ctx echo.Context) error {
+	metadata, err := c.dataManager.RecordingMetadata()
+	if err != nil {
+		return ctx.String(http.StatusNotFound, fmt.Sprintf("failed to get recording metadata: %v", err))
+	}
+
+	jsonResponse, err := json.Marshal(metadata)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal recording metadata: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// storeStats returns the memory and storage statistics of the recording store as the HTTP response.
+func (c *httpController) storeStats(ctx echo.Context) error {
+	stats, err := c.dataManager.StoreStats()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to get store statistics: %v", err))
+	}
+
+	jsonResponse, err := json.Marshal(stats)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal store statistics: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// pipelineStats returns the internal statistics of the recording pipeline and the replay publishing as the HTTP
+// response, to help diagnose why a recording is dropping or lagging.
+func (c *httpController) pipelineStats(ctx echo.Context) error {
+	jsonResponse, err := json.Marshal(c.dataManager.PipelineStats())
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal pipeline statistics: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// payloadSizeReport returns the payload size distribution and the largest devices and Readings of the current or
+// last recording as the HTTP response. The top query parameter sets how many of the largest are listed.
+func (c *httpController) payloadSizeReport(ctx echo.Context) error {
+	var count int
+	if value := ctx.Request().URL.Query().Get(topParam); len(value) > 0 {
+		var err error
+		count, err = strconv.Atoi(value)
+		if err != nil || count < 1 {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: '%s'", failedTopValidate, value))
+		}
+	}
+
+	report, err := c.dataManager.PayloadSizeReport(count)
+	if err != nil {
+		return ctx.String(http.StatusNotFound, fmt.Sprintf("failed to get payload size report: %v", err))
+	}
+
+	jsonResponse, err := json.Marshal(report)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal payload size report: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// recordedDataGaps returns the periods of the recorded data where a device resource produced no Readings for longer
+// than the threshold query parameter as the HTTP response, a page at a time when requested.
+func (c *httpController) recordedDataGaps(ctx echo.Context) error {
+	value := ctx.Request().URL.Query().Get(thresholdParam)
+	threshold, err := time.ParseDuration(value)
+	if err != nil || threshold <= 0 {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: '%s'", failedThresholdValidate, value))
+	}
+
+	list, err := parseListRequest(ctx.Request().URL.Query())
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	report, err := c.dataManager.RecordedDataGaps(threshold)
+	if err != nil {
+		return ctx.String(http.StatusNotFound, fmt.Sprintf("failed to get gap report: %v", err))
+	}
+
+	return listResponse(ctx, list, report, &report.Gaps, "gap report")
+}
+
+// deleteRecordedEvents deletes the recorded Events in the range set by the start and end query parameters, limited
+// to the device query parameter when set, and returns the count deleted as the HTTP response.
+func (c *httpController) deleteRecordedEvents(ctx echo.Context) error {
+	query := ctx.Request().URL.Query()
+	start, startErr := parseTimeParam(query.Get(startParam))
+	end, endErr := parseTimeParam(query.Get(endParam))
+	if startErr != nil || endErr != nil || end <= start {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: start '%s', end '%s'", failedDeleteRangeValidate,
+			query.Get(startParam), query.Get(endParam)))
+	}
+
+	result, err := c.dataManager.DeleteRecordedEvents(start, end, query.Get(deviceParam))
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to delete recorded events: %v", err))
+	}
+
+	jsonResponse, err := json.Marshal(result)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal delete result: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// parseTimeParam parses the time query parameter, in nanoseconds since the epoch or as an RFC3339 time, to
+// nanoseconds since the epoch
+func parseTimeParam(value string) (int64, error) {
+	if nanos, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return nanos, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return parsed.UnixNano(), nil
+}
+
+// compactStore compacts the recording store and returns the space reclaimed as the HTTP response.
+func (c *httpController) compactStore(ctx echo.Context) error {
+	result, err := c.dataManager.CompactStore()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to compact store: %v", err))
+	}
+
+	jsonResponse, err := json.Marshal(result)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal compact result: %s", err))
+	}
+
 	return ctx.String(http.StatusOK, string(jsonResponse))
 }
 
@@ -211,59 +823,204 @@ func (c *httpController) exportRecordedData(ctx echo.Context) error {
 		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to export recorded data: %v", err))
 	}
 
-	compression := ctx.Request().URL.Query().Get("compression")
-	switch compression {
-	case noCompression:
-		c.appSdk.LoggingClient().Debug("ARR Export - Exporting as JSON w/o compression")
-		jsonResponse, err := json.Marshal(recordedData)
+	return c.serveRecordedData(ctx, recordedData)
+}
+
+// serveRecordedData returns the recorded data as the HTTP response, encoded as specified by the query parameters
+func (c *httpController) serveRecordedData(ctx echo.Context, recordedData *dtos.RecordedData) error {
+	var err error
+	sign := false
+	signParam := ctx.Request().URL.Query().Get("sign")
+	if len(signParam) > 0 {
+		sign, err = strconv.ParseBool(signParam)
 		if err != nil {
-			return ctx.String(http.StatusInternalServerError, "failed to marshal recorded data")
-		}
-		ctx.Response().Header().Set("Content-Type", "application/json")
-		return ctx.String(http.StatusOK, string(jsonResponse))
-
-	case zlibCompression:
-		c.appSdk.LoggingClient().Debug("ARR Export - Exporting as JSON using ZLIB compression")
-		ctx.Response().Header().Set("Content-Encoding", contentEncodingZlib)
-		ctx.Response().Header().Set("Content-Type", "application/json")
-		zlibWriter := zlib.NewWriter(ctx.Response().Writer)
-		defer zlibWriter.Close()
-		err = json.NewEncoder(zlibWriter).Encode(&recordedData)
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("failed to parse sign parameter: %v", err))
+		}
+	}
+
+	projection, err := parseExportProjection(ctx.Request().URL.Query())
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("failed to parse export fields: %v", err))
+	}
+
+	sampling, err := parseExportSampling(ctx.Request().URL.Query())
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedSampling, err))
+	}
+
+	if sampling != nil {
+		c.appSdk.LoggingClient().Debugf("ARR Export - Sampling %v of %d events, stratified by: %s", sampling.fraction, len(recordedData.RecordedEvents), sampling.stratify)
+		recordedData, err = sampling.sample(recordedData)
 		if err != nil {
-			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s %s: %s", failedDataCompression, zlibCompression, err))
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedSampling, err))
 		}
+	}
 
-	case gzipCompression:
-		c.appSdk.LoggingClient().Debug("ARR Export - Exporting as JSON using GZIP compression")
-		ctx.Response().Header().Set("Content-Encoding", contentEncodingGzip)
-		ctx.Response().Header().Set("Content-Type", "application/json")
-		gZipWriter := gzip.NewWriter(ctx.Response().Writer)
-		defer gZipWriter.Close()
-		err = json.NewEncoder(gZipWriter).Encode(&recordedData)
+	if anonymizeParam := ctx.Request().URL.Query().Get(anonymizeParam); len(anonymizeParam) > 0 {
+		anonymize, err := strconv.ParseBool(anonymizeParam)
 		if err != nil {
-			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s %s: %s", failedDataCompression, gzipCompression, err))
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("failed to parse anonymize parameter: %v", err))
 		}
 
+		if anonymize {
+			recordedData, err = c.anonymizeRecordedData(recordedData)
+			if errors.Is(err, anonymizeOpaqueError) {
+				return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedAnonymizing, err))
+			}
+			if err != nil {
+				return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedAnonymizing, err))
+			}
+		}
+	}
+
+	var jsonResponse []byte
+	format := ctx.Request().URL.Query().Get("format")
+	if projection != nil && format != nativeFormat {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("export fields can't be selected for format: %s", format))
+	}
+
+	// Formats listing the Events can be exported a page of Events at a time, each page holding the rest of the data
+	eventPage, err := parsePage(ctx.Request().URL.Query())
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+	if eventPage != (page{}) {
+		switch format {
+		case nativeFormat, ndjsonFormat, csvFormat, ekuiperFormat:
+		default:
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("export can't be paged for format: %s", format))
+		}
+
+		paged := *recordedData
+		paged.RecordedEvents = pageItems(ctx, eventPage, recordedData.RecordedEvents)
+		recordedData = &paged
+	}
+
+	switch format {
+	case nativeFormat:
+		if projection != nil {
+			c.appSdk.LoggingClient().Debugf("ARR Export - Exporting with fields omitted: %v, reading fields selected: %v", projection.omit, projection.fields)
+			jsonResponse, err = projection.marshal(recordedData)
+			break
+		}
+		jsonResponse, err = marshalRecordedData(recordedData)
+	case arrFormat:
+		if ctx.Request().URL.Query().Get("compression") != noCompression {
+			return ctx.String(http.StatusBadRequest, archiveCompressionConflict)
+		}
+		c.appSdk.LoggingClient().Debug("ARR Export - Exporting as .arr archive")
+		jsonResponse, err = marshalRecordedData(recordedData)
+	case ekuiperFormat:
+		c.appSdk.LoggingClient().Debug("ARR Export - Exporting as eKuiper sample stream")
+		jsonResponse, err = json.Marshal(toEKuiperSamples(recordedData.RecordedEvents))
+	case csvFormat:
+		c.appSdk.LoggingClient().Debug("ARR Export - Exporting as labeled CSV")
+		jsonResponse, err = toCSV(recordedData)
+	case ndjsonFormat:
+		c.appSdk.LoggingClient().Debug("ARR Export - Exporting as newline delimited JSON")
+		jsonResponse, err = toNDJSON(recordedData)
+	case summaryFormat, featuresFormat:
+		window := defaultSummaryWindow
+		if windowParam := ctx.Request().URL.Query().Get("window"); len(windowParam) > 0 {
+			window, err = time.ParseDuration(windowParam)
+			if err != nil || window <= 0 {
+				return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: '%s'", failedSummaryWindowValidate, windowParam))
+			}
+		}
+
+		if format == summaryFormat {
+			c.appSdk.LoggingClient().Debugf("ARR Export - Exporting as summary with %s windows", window.String())
+			jsonResponse, err = json.Marshal(toSummary(recordedData.RecordedEvents, window))
+			break
+		}
+
+		aggregations, parseErr := parseFeatureAggregations(ctx.Request().URL.Query())
+		if parseErr != nil {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedFeaturesValidate, parseErr))
+		}
+		c.appSdk.LoggingClient().Debugf("ARR Export - Exporting as feature vectors with %s windows and aggregations: %v", window.String(), aggregations)
+		jsonResponse, err = json.Marshal(toFeatureVectors(recordedData.RecordedEvents, window, aggregations))
 	default:
-		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("compression format not available: %s", compression))
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("export format not available: %s", format))
+	}
+
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, "failed to marshal recorded data")
+	}
+
+	// The signature is always for the uncompressed JSON so it can be verified independent of the compression used
+	if sign {
+		signature, err := c.signData(jsonResponse)
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedSigningData, err))
+		}
+		ctx.Response().Header().Set(signatureHeader, signature)
+	}
+
+	// Named recordings are downloaded using their name so saved exports are easy to identify
+	extension := ".json"
+	switch format {
+	case arrFormat:
+		extension = archiveExtension
+	case csvFormat:
+		extension = csvExtension
+	case ndjsonFormat:
+		extension = ndjsonExtension
+	}
+	if len(recordedData.Name) > 0 {
+		ctx.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", recordedData.Name+extension))
+	}
+
+	body := jsonResponse
+	contentType := "application/json"
+	switch format {
+	case csvFormat:
+		contentType = "text/csv"
+	case ndjsonFormat:
+		contentType = ndjsonContentType
+	}
+	compression := ctx.Request().URL.Query().Get("compression")
+	if format == arrFormat {
+		body, err = createArchive(newArchiveManifest(recordedData, jsonResponse), jsonResponse)
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedArchiveCreate, err))
+		}
+		contentType = "application/zip"
+	} else if compression == noCompression {
+		c.appSdk.LoggingClient().Debug("ARR Export - Exporting as JSON w/o compression")
+	} else {
+		codec, ok := codecs[compression]
+		if !ok {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("compression format not available: %s", compression))
+		}
+
+		c.appSdk.LoggingClient().Debugf("ARR Export - Exporting as JSON using %s compression", strings.ToUpper(compression))
+		body, err = codec.compress(jsonResponse)
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s %s: %s", failedDataCompression, compression, err))
+		}
+		ctx.Response().Header().Set("Content-Encoding", codec.contentEncoding)
 	}
 
+	// The export is encoded the same way each time for the same recorded data, so serving it as content allows
+	// interrupted downloads to be resumed using Range requests against the encoded (possibly compressed) bytes.
+	// ServeContent handles If-None-Match and If-Range using the ETag, so unchanged data isn't downloaded again
+	// and a download is only resumed if the data hasn't changed since it started.
+	ctx.Response().Header().Set("Content-Type", contentType)
+	ctx.Response().Header().Set(etagHeader, computeETag(body))
+	http.ServeContent(ctx.Response(), ctx.Request(), "", time.Time{}, bytes.NewReader(body))
+
 	return nil
 }
 
 // importRecordedData imports data from a previously exported record session.
 // An error is returned if a record or replay session is currently running or the data is incomplete
 func (c *httpController) importRecordedData(ctx echo.Context) error {
-	importedRecordedData := &dtos.RecordedData{}
+	var importedRecordedData *dtos.RecordedData
 	var reader io.ReadCloser
 	var err error
 	var overWriteProfilesDevices bool
 
-	contentType := ctx.Request().Header.Get(common.ContentType)
-	if contentType != common.ContentTypeJSON {
-		return ctx.String(http.StatusBadRequest, fmt.Sprintf("Invalid content type '%s'. Must be application/json", contentType))
-	}
-
 	queryParam := ctx.Request().URL.Query().Get("overwrite")
 	if len(queryParam) == 0 {
 		overWriteProfilesDevices = true
@@ -274,51 +1031,347 @@ func (c *httpController) importRecordedData(ctx echo.Context) error {
 		}
 	}
 
+	var rollback bool
+	if queryParam := ctx.Request().URL.Query().Get(importRollbackParam); len(queryParam) > 0 {
+		rollback, err = strconv.ParseBool(queryParam)
+		if err != nil {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("failed to parse %s parameter: %v", importRollbackParam, err))
+		}
+	}
+
+	// The format is detected from the data unless overridden, so the Content-Type header isn't relied on
+	format := ctx.Request().URL.Query().Get(importFormatParam)
+	switch format {
+	case "", jsonImportFormat, ndjsonImportFormat, cborImportFormat, zipImportFormat, arrFormat:
+	default:
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("import format not available: %s", format))
+	}
+
+	transform, err := parseImportTransform(ctx.Request().URL.Query())
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedImportTransform, err))
+	}
+
+	spliced, err := parseImportAppend(ctx.Request().URL.Query())
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedImportingData, err))
+	}
+
+	limits, err := c.getImportLimits()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedImportingData, err))
+	}
+
+	var body *bufio.Reader
+	var localSignature string
+
+	// Recordings staged on the gateway are read from the local file rather than streamed through the request, so
+	// the request body limit doesn't apply. The limits on the uncompressed data still do.
+	if localPath := ctx.Request().URL.Query().Get(importPathParam); len(localPath) > 0 {
+		file, signature, err := c.openLocalImportFile(localPath)
+		switch {
+		case errors.Is(err, localPathDisabled), errors.Is(err, localPathNotAllowed):
+			return ctx.String(http.StatusForbidden, fmt.Sprintf("%s: %v", failedImportingData, err))
+		case err != nil:
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedImportingData, err))
+		}
+		defer file.Close()
+
+		c.appSdk.LoggingClient().Debugf("ARR Import - Importing from local file %s", file.Name())
+		// The file is read under the request's context, so an import run as a job stops reading when canceled
+		body = bufio.NewReader(&contextReader{ReadCloser: file, ctx: ctx.Request().Context()})
+		localSignature = signature
+	} else {
+		// Requests with a known length are rejected before reading the body, otherwise the limit is applied while reading
+		if ctx.Request().ContentLength > limits.maxRequestBytes {
+			return ctx.String(http.StatusRequestEntityTooLarge, fmt.Sprintf("%s: %v: request body of %d bytes exceeds %d bytes",
+				failedImportLimit, importLimitExceeded, ctx.Request().ContentLength, limits.maxRequestBytes))
+		}
+
+		body = bufio.NewReader(limitImportReader(ctx.Request().Body, limits.maxRequestBytes, "request body"))
+	}
+	reader = io.NopCloser(body)
+
 	compression := ctx.Request().Header.Get("Content-Encoding")
-	switch compression {
+	name, codec, compressed := codecByContentEncoding(compression)
+	if len(compression) > 0 && !compressed {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("compression format %s not supported", compression))
+	}
 
-	case noCompression:
-		c.appSdk.LoggingClient().Debug("ARR Import - Importing as JSON w/o compression")
-		reader = ctx.Request().Body
+	// Archives with a manifest are .arr archives, which are validated against their manifest before being imported
+	var manifest *dtos.ArchiveManifest
+	if format == zipImportFormat || format == arrFormat || (format == "" && !compressed && isZipArchive(body)) {
+		c.appSdk.LoggingClient().Debug("ARR Import - Importing from zip archive")
+		archive, err := readZipArchive(body)
+		if err != nil {
+			return c.importReadFailed(ctx, failedToUncompressData, err)
+		}
 
-	case contentEncodingGzip:
-		c.appSdk.LoggingClient().Debug("ARR Import - Importing as JSON using GZIP compression")
-		reader, err = gzip.NewReader(ctx.Request().Body)
+		manifest, err = readArchiveManifest(archive, limits)
 		if err != nil {
-			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %s", failedToUncompressData, err))
+			return c.importReadFailed(ctx, failedArchiveManifest, err)
+		}
+		if manifest == nil && format == arrFormat {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedArchiveManifest, noArchiveManifest))
 		}
 
-	case contentEncodingZlib:
-		c.appSdk.LoggingClient().Debug("ARR Import - Importing as JSON using ZLIB compression")
-		reader, err = zlib.NewReader(ctx.Request().Body)
+		if manifest != nil {
+			c.appSdk.LoggingClient().Debugf("ARR Import - Importing .arr archive of format version %d", manifest.FormatVersion)
+			entry, err := validateArchive(archive, manifest, limits)
+			if err != nil {
+				return c.importReadFailed(ctx, failedArchiveManifest, err)
+			}
+			reader, err = openZipFile(entry, limits)
+		} else {
+			reader, err = openZipEntry(archive, limits)
+		}
 		if err != nil {
-			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %s", failedToUncompressData, err))
+			return c.importReadFailed(ctx, failedToUncompressData, err)
+		}
+		format = ""
+	} else {
+		if !compressed {
+			name, codec, compressed = sniffCompression(body)
 		}
-	default:
-		return ctx.String(http.StatusBadRequest, fmt.Sprintf("compression format %s not supported", compression))
 
+		if !compressed {
+			c.appSdk.LoggingClient().Debug("ARR Import - Importing w/o compression")
+		} else {
+			c.appSdk.LoggingClient().Debugf("ARR Import - Importing using %s compression", strings.ToUpper(name))
+			compressedReader := &countingReader{reader: body}
+			uncompressed, err := codec.newReader(compressedReader)
+			if err != nil {
+				return c.importReadFailed(ctx, failedToUncompressData, err)
+			}
+
+			reader = &compressionRatioReader{
+				ReadCloser: uncompressed,
+				compressed: compressedReader,
+				maxRatio:   limits.maxCompressionRatio,
+			}
+		}
 	}
 	defer reader.Close()
-	err = json.NewDecoder(reader).Decode(&importedRecordedData)
+	reader = limitImportReader(reader, limits.maxBytes, "uncompressed data")
+
+	// The signature is for the uncompressed data, so must verify after it has been uncompressed. Verifying
+	// requires all the data, so it is read in full, up to the max bytes, before being decoded.
+	signature := ctx.Request().Header.Get(signatureHeader)
+	if len(signature) == 0 {
+		signature = localSignature
+	}
+	if len(signature) > 0 {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return c.importReadFailed(ctx, failedToUncompressData, err)
+		}
+
+		if err := c.verifyData(data, signature); err != nil {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedVerifyingData, err))
+		}
+		c.appSdk.LoggingClient().Debug("ARR Import - Signature of imported data verified")
+
+		reader = io.NopCloser(bytes.NewReader(data))
+	}
+
+	payload := bufio.NewReader(reader)
+	if len(format) == 0 {
+		format, err = detectPayloadFormat(payload)
+		if err != nil {
+			return c.importReadFailed(ctx, failedRequestJSON, err)
+		}
+	}
+	c.appSdk.LoggingClient().Debugf("ARR Import - Importing as %s", strings.ToUpper(format))
+
+	importedRecordedData, err = decodeImportedData(payload, format, int(limits.maxEvents))
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+		return c.importReadFailed(ctx, failedRequestJSON, err)
+	}
+
+	// Data stored as a delta is reconstructed from its baseline before anything else looks at it
+	if importedRecordedData.Delta != nil {
+		c.appSdk.LoggingClient().Debugf("ARR Import - Reconstructing delta against baseline %s", importedRecordedData.Delta.Baseline)
+		if len(importedRecordedData.Delta.Events) > int(limits.maxEvents) {
+			return c.importReadFailed(ctx, failedImportingData,
+				fmt.Errorf("%w: more than %d recorded events", importLimitExceeded, limits.maxEvents))
+		}
+
+		err := c.resolveRecordedDataDelta(importedRecordedData, limits)
+		switch {
+		case errors.Is(err, localPathDisabled), errors.Is(err, localPathNotAllowed):
+			return ctx.String(http.StatusForbidden, fmt.Sprintf("%s: %v", failedImportingData, err))
+		case err != nil:
+			return c.importReadFailed(ctx, failedImportingData, err)
+		}
+	}
+
+	// Opaque recordings only have messages, which don't reference any devices
+	if len(importedRecordedData.Messages) < 1 {
+		if len(importedRecordedData.RecordedEvents) < 1 {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: no recorded events", noDataFound))
+		}
+
+		if len(importedRecordedData.Devices) < 1 {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: no devices", noDataFound))
+		}
+
+		if len(importedRecordedData.Profiles) < 1 {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: no profiles", noDataFound))
+		}
+	}
+
+	if spliced != nil && len(importedRecordedData.Messages) > 0 {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedImportingData, appendOpaqueError))
+	}
+
+	// The manifest describes the data as archived, so is checked before the data is transformed
+	var profiles, deviceServices []string
+	if manifest != nil {
+		profiles, deviceServices, err = checkManifestDependencies(manifest, importedRecordedData)
+		if err != nil {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedArchiveManifest, err))
+		}
+	}
+
+	if transform != nil {
+		if err := transform.apply(importedRecordedData); err != nil {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedImportTransform, err))
+		}
+		profiles = transform.profileNames(profiles)
+	}
+
+	// All the missing dependencies are reported at once, before Core Metadata is changed by the import
+	if manifest != nil {
+		missing, err := c.dataManager.MissingDependencies(profiles, deviceServices)
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedDependencyCheck, err))
+		}
+
+		if len(missing.Profiles) > 0 || len(missing.DeviceServices) > 0 {
+			jsonResponse, err := json.Marshal(missing)
+			if err != nil {
+				return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedDependencyCheck, err))
+			}
+			return ctx.String(http.StatusFailedDependency, string(jsonResponse))
+		}
 	}
 
-	if len(importedRecordedData.RecordedEvents) < 1 {
-		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: no recorded events", noDataFound))
+	if spliced != nil {
+		if err := c.dataManager.AppendRecordedData(importedRecordedData, spliced.gap, overWriteProfilesDevices, rollback); err != nil {
+			return importFailed(ctx, err)
+		}
+		return ctx.NoContent(http.StatusAccepted)
 	}
 
-	if len(importedRecordedData.Devices) < 1 {
-		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: no devices", noDataFound))
+	if err := c.dataManager.ImportRecordedData(importedRecordedData, overWriteProfilesDevices, rollback); err != nil {
+		return importFailed(ctx, err)
 	}
 
-	if len(importedRecordedData.Profiles) < 1 {
-		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: no profiles", noDataFound))
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// provisioningFailures is implemented by the error returned once the data is imported when some of its Device
+// Profiles or Devices failed to be provisioned
+type provisioningFailures interface {
+	ProvisioningFailures() []dtos.ProvisioningFailure
+}
+
+// importFailed returns Multi-Status with the Device Profiles and Devices which failed to be provisioned if the data
+// was imported regardless, otherwise Internal Server Error
+func importFailed(ctx echo.Context, err error) error {
+	var provisioning provisioningFailures
+	if !errors.As(err, &provisioning) {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedImportingData, err))
 	}
 
-	if err := c.dataManager.ImportRecordedData(importedRecordedData, overWriteProfilesDevices); err != nil {
+	jsonResponse, err := json.Marshal(dtos.ProvisioningReport{Failures: provisioning.ProvisioningFailures()})
+	if err != nil {
 		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedImportingData, err))
 	}
 
+	return ctx.String(http.StatusMultiStatus, string(jsonResponse))
+}
+
+// importReadFailed returns Request Entity Too Large if the imported data exceeded the import limits,
+// otherwise Bad Request
+func (c *httpController) importReadFailed(ctx echo.Context, message string, err error) error {
+	if isImportLimitError(err) {
+		return ctx.String(http.StatusRequestEntityTooLarge, fmt.Sprintf("%s: %v", failedImportLimit, err))
+	}
+
+	return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", message, err))
+}
+
+// assertRecordedData checks the assertions in the request against the uploaded or last recorded data and
+// returns the pass/fail result for each assertion as the HTTP response.
+func (c *httpController) assertRecordedData(ctx echo.Context) error {
+	assertRequest := &dtos.AssertRequest{}
+
+	if err := json.NewDecoder(ctx.Request().Body).Decode(assertRequest); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	if len(assertRequest.Assertions) == 0 {
+		return ctx.String(http.StatusBadRequest, failedAssertRequestValidate)
+	}
+
+	response, err := c.dataManager.AssertRecordedData(*assertRequest)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedAssertingData, err))
+	}
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal assert response: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// validateRecordedData checks every recorded Event against the EdgeX contract validation rules and the recorded
+// Device Profiles and returns the validation report as the HTTP response, listing a page of the invalid Events
+// when requested.
+func (c *httpController) validateRecordedData(ctx echo.Context) error {
+	list, err := parseListRequest(ctx.Request().URL.Query())
+	if err != nil {
+		return ctx.String(http.StatusBadRequest, err.Error())
+	}
+
+	report, err := c.dataManager.ValidateRecordedData()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedValidatingData, err))
+	}
+
+	return listResponse(ctx, list, report, &report.InvalidEvents, "validation report")
+}
+
+// dependencyGraph returns the Device Profile and Device Service each recorded Device depends on, and which of them
+// are missing from Core Metadata, as the HTTP response.
+func (c *httpController) dependencyGraph(ctx echo.Context) error {
+	graph, err := c.dataManager.DependencyGraph()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedDependencyGraph, err))
+	}
+
+	jsonResponse, err := json.Marshal(graph)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal dependency graph: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// lockRecordedData marks the recorded data as read-only so it can't be overwritten by a new recording or import.
+func (c *httpController) lockRecordedData(ctx echo.Context) error {
+	if err := c.dataManager.LockRecordedData(); err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to lock recorded data: %v", err))
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// unlockRecordedData removes the read-only lock from the recorded data.
+func (c *httpController) unlockRecordedData(ctx echo.Context) error {
+	c.dataManager.UnlockRecordedData()
 	return ctx.NoContent(http.StatusAccepted)
 }
@@ -16,6 +16,7 @@
 package controller
 
 import (
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
 	"compress/zlib"
@@ -36,6 +37,7 @@ import (
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
 	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -49,6 +51,7 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, target.appSdk)
 	assert.NotNil(t, target.lc)
 	assert.NotNil(t, target.dataManager)
+	assert.NotNil(t, target.coordinator)
 }
 
 func TestHttpController_AddCustomRoutes_Success(t *testing.T) {
@@ -68,13 +71,71 @@ func TestHttpController_AddRoutes_Error(t *testing.T) {
 		{"Start Recording", recordRoute, http.MethodPost},
 		{"Cancel Recording", recordRoute, http.MethodDelete},
 		{"Recording Status", recordRoute, http.MethodGet},
+		{"Push Record Events", pushRoute, http.MethodPost},
 
 		{"Start Replay", replayRoute, http.MethodPost},
 		{"Cancel Replay", replayRoute, http.MethodDelete},
 		{"Replay Status", replayRoute, http.MethodGet},
+		{"Shadow Report", shadowRoute, http.MethodGet},
+		{"Latency Report", latencyRoute, http.MethodGet},
+		{"Trigger Replay", triggerRoute, http.MethodPost},
+		{"Seek Replay", seekRoute, http.MethodPatch},
+		{"Resume Replay", resumeRoute, http.MethodPost},
+		{"Step Replay", stepRoute, http.MethodPost},
+		{"Start Playlist", playlistRoute, http.MethodPost},
+		{"Playlist Status", playlistRoute, http.MethodGet},
+		{"Cancel Playlist", playlistRoute, http.MethodDelete},
+		{"Export Playlist", playlistDefinitionRoute, http.MethodGet},
+
+		{"Read Control Device", deviceCommandRoute, http.MethodGet},
+		{"Write Control Device", deviceCommandRoute, http.MethodPut},
 
 		{"Export", dataRoute, http.MethodGet},
 		{"Import", dataRoute, http.MethodPost},
+		{"Assert", assertRoute, http.MethodPost},
+		{"Validate", validateRoute, http.MethodGet},
+		{"Dependencies", dependsRoute, http.MethodGet},
+		{"Lock", lockRoute, http.MethodPost},
+		{"Unlock", lockRoute, http.MethodDelete},
+		{"Recording Metadata", metadataRoute, http.MethodGet},
+		{"Downsample", downsampleRoute, http.MethodPost},
+		{"Export To Path", exportRoute, http.MethodPost},
+		{"Export Size", exportSizeRoute, http.MethodGet},
+		{"Export Size Head", exportSizeRoute, http.MethodHead},
+		{"Store Stats", statsRoute, http.MethodGet},
+		{"Payload Sizes", sizesRoute, http.MethodGet},
+		{"Data Gaps", gapsRoute, http.MethodGet},
+		{"Delete Events", eventsRoute, http.MethodDelete},
+		{"Add Annotation", annotateRoute, http.MethodPost},
+		{"Search Annotations", annotateRoute, http.MethodGet},
+		{"Compare Series", compareRoute, http.MethodGet},
+		{"Compact Store", compactRoute, http.MethodPost},
+		{"Mint Export Link", exportLinkRoute, http.MethodPost},
+		{"Download Export Link", exportLinkRoute, http.MethodGet},
+		{"Job Status", jobRoute, http.MethodGet},
+		{"Cancel Job", jobRoute, http.MethodDelete},
+		{"Inject", injectRoute, http.MethodPost},
+		{"Backup", backupRoute, http.MethodPost},
+		{"Restore", restoreRoute, http.MethodPost},
+		{"Self-Test", selfTestRoute, http.MethodPost},
+		{"Pipeline Stats", pipelineRoute, http.MethodGet},
+
+		{"Cluster Start Recording", clusterRecordRoute, http.MethodPost},
+		{"Cluster Cancel Recording", clusterRecordRoute, http.MethodDelete},
+		{"Cluster Recording Status", clusterRecordRoute, http.MethodGet},
+		{"Cluster Start Replay", clusterReplayRoute, http.MethodPost},
+		{"Cluster Cancel Replay", clusterReplayRoute, http.MethodDelete},
+		{"Cluster Replay Status", clusterReplayRoute, http.MethodGet},
+		{"Cluster Export", clusterDataRoute, http.MethodGet},
+
+		{"V3.0 Start Recording", v3Compat.route(recordRoute), http.MethodPost},
+		{"V3.0 Cancel Recording", v3Compat.route(recordRoute), http.MethodDelete},
+		{"V3.0 Recording Status", v3Compat.route(recordRoute), http.MethodGet},
+		{"V3.0 Start Replay", v3Compat.route(replayRoute), http.MethodPost},
+		{"V3.0 Cancel Replay", v3Compat.route(replayRoute), http.MethodDelete},
+		{"V3.0 Replay Status", v3Compat.route(replayRoute), http.MethodGet},
+		{"V3.0 Export", v3Compat.route(dataRoute), http.MethodGet},
+		{"V3.0 Import", v3Compat.route(dataRoute), http.MethodPost},
 	}
 
 	expectedError := errors.New("AddRoutes error")
@@ -85,7 +146,7 @@ func TestHttpController_AddRoutes_Error(t *testing.T) {
 			mockSdk.On("AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
 
-			target := New(nil, mockSdk)
+			target := New(nil, nil, nil, mockSdk)
 
 			err := target.AddRoutes()
 			require.Error(t, err)
@@ -135,6 +196,11 @@ func TestHttpController_StartRecording(t *testing.T) {
 		ExcludeSources:        nil,
 	}
 
+	badRotationRequestDTO := dtos.RecordRequest{
+		Duration: time.Minute,
+		Rotation: &dtos.SegmentRotation{MaxSegments: -1},
+	}
+
 	tests := []struct {
 		Name                         string
 		Input                        []byte
@@ -149,6 +215,7 @@ func TestHttpController_StartRecording(t *testing.T) {
 		{"Empty DTO Input", marshal(t, emptyRequestDTO), nil, http.StatusBadRequest, failedRecordRequestValidate},
 		{"Bad Duration", marshal(t, badDurationRequestDTO), nil, http.StatusBadRequest, failedRecordDurationValidate},
 		{"Bad Event Limit", marshal(t, badEventLimitRequestDTO), nil, http.StatusBadRequest, failedRecordEventLimitValidate},
+		{"Bad Rotation", marshal(t, badRotationRequestDTO), nil, http.StatusBadRequest, failedRecordRotationValidate},
 	}
 
 	for _, test := range tests {
@@ -243,6 +310,50 @@ func TestHttpController_CancelRecording(t *testing.T) {
 	}
 }
 
+func TestHttpController_PushRecordEvents(t *testing.T) {
+	validRequest := dtos.RecordPushRequest{
+		Events:        []coreDtos.Event{coreDtos.NewEvent("P1", "D1", "S1")},
+		ReceivedTopic: "edgex/events/device/script/P1/D1/S1",
+	}
+
+	tests := []struct {
+		Name           string
+		Request        any
+		PushError      error
+		ExpectedStatus int
+		ExpectedError  string
+	}{
+		{"Valid", validRequest, nil, http.StatusAccepted, ""},
+		{"Bad JSON", "bad", nil, http.StatusBadRequest, failedRequestJSON},
+		{"No Events", dtos.RecordPushRequest{}, nil, http.StatusBadRequest, failedPushRequestValidate},
+		{"Push fails", validRequest, errors.New("no recording"), http.StatusInternalServerError, "no recording"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			mockDataManager.On("PushRecordEvents", mock.Anything).Return(test.PushError)
+
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.pushRecordEvents))
+
+			body, err := json.Marshal(test.Request)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, pushRoute, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			assert.Contains(t, testRecorder.Body.String(), test.ExpectedError)
+			if test.ExpectedStatus == http.StatusAccepted {
+				mockDataManager.AssertCalled(t, "PushRecordEvents", validRequest)
+			}
+		})
+	}
+}
+
 func TestHttpController_StartReplay(t *testing.T) {
 	target, mockDataManager, _ := createTargetAndMocks()
 
@@ -264,6 +375,74 @@ func TestHttpController_StartReplay(t *testing.T) {
 		RepeatCount: 0,
 	}
 
+	invalidScriptRequestDTO := dtos.ReplayRequest{
+		ReplayRate: 1,
+		Script:     "{bad json",
+	}
+
+	invalidLagRequestDTO := dtos.ReplayRequest{
+		ReplayRate:   1,
+		MaxReplayLag: -time.Second,
+	}
+
+	invalidWarmupRequestDTO := dtos.ReplayRequest{
+		ReplayRate: 1,
+		Warmup:     "eager",
+	}
+
+	invalidFanOutRequestDTO := dtos.ReplayRequest{
+		ReplayRate: 1,
+		FanOut:     -1,
+	}
+
+	invalidPublishWorkersRequestDTO := dtos.ReplayRequest{
+		ReplayRate:     1,
+		PublishWorkers: -1,
+	}
+
+	invalidOnPublishErrorRequestDTO := dtos.ReplayRequest{
+		ReplayRate:     1,
+		OnPublishError: "ignore",
+	}
+
+	invalidPublishRetryRequestDTO := dtos.ReplayRequest{
+		ReplayRate:           1,
+		OnPublishError:       dtos.ReplayPublishErrorRetry,
+		PublishRetryInterval: -time.Second,
+	}
+
+	validTimeWarpRequestDTO := dtos.ReplayRequest{
+		TimeWarpDuration: time.Hour,
+	}
+
+	invalidTimeWarpRequestDTO := dtos.ReplayRequest{
+		TimeWarpDuration: -time.Hour,
+	}
+
+	invalidTimeWarpRateRequestDTO := dtos.ReplayRequest{
+		ReplayRate:       1,
+		TimeWarpDuration: time.Hour,
+	}
+
+	invalidSinkTypeRequestDTO := dtos.ReplayRequest{
+		ReplayRate: 1,
+		Sinks:      []dtos.ReplaySink{{Type: "kafka"}},
+	}
+
+	invalidSinkPolicyRequestDTO := dtos.ReplayRequest{
+		ReplayRate: 1,
+		Sinks:      []dtos.ReplaySink{{Type: dtos.ReplaySinkMessageBus, OnPublishError: "ignore"}},
+	}
+
+	validAlignTimeOfDayRequestDTO := dtos.ReplayRequest{
+		AlignTimeOfDay: true,
+	}
+
+	invalidAlignTimeOfDayRequestDTO := dtos.ReplayRequest{
+		ReplayRate:     1,
+		AlignTimeOfDay: true,
+	}
+
 	tests := []struct {
 		Name                         string
 		Input                        []byte
@@ -272,12 +451,26 @@ func TestHttpController_StartReplay(t *testing.T) {
 		ExpectedMessage              string
 	}{
 		{"Success", marshal(t, validRequestDTO), nil, http.StatusAccepted, ""},
+		{"Success - Time Warp", marshal(t, validTimeWarpRequestDTO), nil, http.StatusAccepted, ""},
+		{"Success - Align Time Of Day", marshal(t, validAlignTimeOfDayRequestDTO), nil, http.StatusAccepted, ""},
 		{"Recording failed", marshal(t, validRequestDTO), errors.New("replay failed"), http.StatusInternalServerError, failedReplay},
 		{"No Input", nil, nil, http.StatusBadRequest, failedRequestJSON},
 		{"Bad JSON Input", []byte("bad input"), nil, http.StatusBadRequest, failedRequestJSON},
 		{"Empty DTO Input", marshal(t, invalidEmptyRequestDTO), nil, http.StatusBadRequest, failedReplayRateValidate},
 		{"Bad Rate", marshal(t, invalidRateRequestDTO), nil, http.StatusBadRequest, failedReplayRateValidate},
 		{"Bad Count", marshal(t, invalidCountRequestDTO), nil, http.StatusBadRequest, failedRepeatCountValidate},
+		{"Bad Script", marshal(t, invalidScriptRequestDTO), nil, http.StatusBadRequest, failedReplayScriptValidate},
+		{"Bad Max Lag", marshal(t, invalidLagRequestDTO), nil, http.StatusBadRequest, failedMaxReplayLagValidate},
+		{"Bad Warmup", marshal(t, invalidWarmupRequestDTO), nil, http.StatusBadRequest, failedReplayWarmupValidate},
+		{"Bad FanOut", marshal(t, invalidFanOutRequestDTO), nil, http.StatusBadRequest, failedFanOutValidate},
+		{"Bad PublishWorkers", marshal(t, invalidPublishWorkersRequestDTO), nil, http.StatusBadRequest, failedPublishWorkersValidate},
+		{"Bad OnPublishError", marshal(t, invalidOnPublishErrorRequestDTO), nil, http.StatusBadRequest, failedOnPublishErrorValidate},
+		{"Bad Publish Retry", marshal(t, invalidPublishRetryRequestDTO), nil, http.StatusBadRequest, failedPublishRetryValidate},
+		{"Bad Time Warp", marshal(t, invalidTimeWarpRequestDTO), nil, http.StatusBadRequest, failedTimeWarpValidate},
+		{"Bad Time Warp Rate", marshal(t, invalidTimeWarpRateRequestDTO), nil, http.StatusBadRequest, failedTimeWarpRateValidate},
+		{"Bad Align Time Of Day", marshal(t, invalidAlignTimeOfDayRequestDTO), nil, http.StatusBadRequest, failedAlignTimeOfDayValidate},
+		{"Bad Sink Type", marshal(t, invalidSinkTypeRequestDTO), nil, http.StatusBadRequest, failedReplaySinksValidate},
+		{"Bad Sink OnPublishError", marshal(t, invalidSinkPolicyRequestDTO), nil, http.StatusBadRequest, failedReplaySinksValidate},
 	}
 
 	for _, test := range tests {
@@ -356,234 +549,894 @@ func TestHttpController_ReplayStatus(t *testing.T) {
 		})
 	}
 }
-func TestHttpController_CancelReplay(t *testing.T) {
+
+func TestHttpController_ShadowReport(t *testing.T) {
 	target, mockDataManager, _ := createTargetAndMocks()
 
-	handler := http.HandlerFunc(WrapEchoHandler(t, target.cancelReplay))
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.shadowReport))
+
+	report := &dtos.ShadowReport{
+		ReplayedEventCount: 2,
+		LiveEventCount:     3,
+		Resources: []dtos.ResourceComparison{
+			{
+				DeviceName:           "Random-Integer-Device",
+				ResourceName:         "Int8",
+				ReplayedReadingCount: 2,
+				LiveReadingCount:     3,
+				ReplayedMeanValue:    10,
+				LiveMeanValue:        12,
+				MeanValueDelta:       2,
+			},
+		},
+	}
 
 	tests := []struct {
-		Name           string
-		ExpectedStatus int
-		ExpectedError  error
+		Name             string
+		ExpectedResponse *dtos.ShadowReport
+		ExpectedStatus   int
+		ExpectedError    error
 	}{
-		{"Valid", http.StatusAccepted, nil},
-		{"Error", http.StatusInternalServerError, errors.New("failed")},
+		{"Valid", report, http.StatusOK, nil},
+		{"No shadow replay", nil, http.StatusNotFound, errors.New("no shadow mode replay")},
 	}
+
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
-			mockDataManager.On("CancelReplay").Return(test.ExpectedError).Once()
-
-			req, err := http.NewRequest(http.MethodGet, recordRoute, nil)
+			mockDataManager.On("ShadowReport").Return(test.ExpectedResponse, test.ExpectedError).Once()
+			req, err := http.NewRequest(http.MethodGet, shadowRoute, nil)
 			require.NoError(t, err)
 
 			testRecorder := httptest.NewRecorder()
 			handler.ServeHTTP(testRecorder, req)
 
 			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedError.Error())
+				return
+			}
+
+			actualResponse := &dtos.ShadowReport{}
+			err = json.Unmarshal(testRecorder.Body.Bytes(), actualResponse)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedResponse, actualResponse)
 		})
 	}
 }
 
-func TestHttpController_ExportRecordedData(t *testing.T) {
-	noRecordedData := dtos.RecordedData{}
-	recordedData := dtos.RecordedData{
-		RecordedEvents: []coreDtos.Event{
-			coreDtos.Event{
-				DeviceName:  "test",
-				ProfileName: "test",
-				Readings: []coreDtos.BaseReading{
-					coreDtos.BaseReading{
-						SimpleReading: coreDtos.SimpleReading{
-							Value: "1456.0",
-						},
-					},
-				},
-			},
-			coreDtos.Event{
-				DeviceName:  "test",
-				ProfileName: "test",
-				Readings: []coreDtos.BaseReading{
-					coreDtos.BaseReading{
-						SimpleReading: coreDtos.SimpleReading{
-							Value: "1456.0",
-						},
-					},
-				},
-			},
-		},
-		Devices: []coreDtos.Device{
-			coreDtos.Device{
-				Name:        "test_device",
-				ProfileName: "test",
-			},
-		},
-		Profiles: []coreDtos.DeviceProfile{
-			coreDtos.DeviceProfile{
-				DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{
-					Name: "test",
-				},
-			},
+func TestHttpController_LatencyReport(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.latencyReport))
+
+	report := &dtos.LatencyReport{
+		Topic:               "events/core/#",
+		PublishedEventCount: 3,
+		ReceivedEventCount:  2,
+		LostEventCount:      1,
+		Latency: &dtos.LatencyDistribution{
+			Count: 2,
+			Mean:  15 * time.Millisecond,
+			Min:   10 * time.Millisecond,
+			Max:   20 * time.Millisecond,
+			P50:   15 * time.Millisecond,
+			P90:   20 * time.Millisecond,
+			P95:   20 * time.Millisecond,
+			P99:   20 * time.Millisecond,
 		},
 	}
 
 	tests := []struct {
 		Name             string
-		ExpectedResponse *dtos.RecordedData
+		ExpectedResponse *dtos.LatencyReport
 		ExpectedStatus   int
 		ExpectedError    error
-		QueryParam       string
 	}{
-		{
-			Name:             "Valid with data",
-			ExpectedResponse: &recordedData,
-			ExpectedStatus:   http.StatusOK,
-			ExpectedError:    nil,
-		},
-		{
-			Name:             "Valid - no events",
-			ExpectedResponse: &noRecordedData,
-			ExpectedStatus:   http.StatusInternalServerError,
-			ExpectedError:    fmt.Errorf("failed"),
-		},
-		{
-			Name:             "Valid with data with GZIP query parameter",
-			ExpectedResponse: &recordedData,
-			ExpectedStatus:   http.StatusOK,
-			ExpectedError:    nil,
-			QueryParam:       gzipCompression,
-		},
-		{
-			Name:             "Valid with data with ZLIB query parameter",
-			ExpectedResponse: &recordedData,
-			ExpectedStatus:   http.StatusOK,
-			ExpectedError:    nil,
-			QueryParam:       zlibCompression,
-		},
-		{
-			Name:             "Valid with data with invalid GZ query parameter",
-			ExpectedResponse: &recordedData,
-			ExpectedStatus:   http.StatusInternalServerError,
-			ExpectedError:    nil,
-			QueryParam:       "GZ",
-		},
+		{"Valid", report, http.StatusOK, nil},
+		{"No latency replay", nil, http.StatusNotFound, errors.New("no latency measurement replay")},
 	}
+
 	for _, test := range tests {
 		t.Run(test.Name, func(t *testing.T) {
-			target, mockDataManager, _ := createTargetAndMocks()
-			mockDataManager.On("ExportRecordedData").Return(test.ExpectedResponse, test.ExpectedError)
-
-			handler := http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData))
-
-			req, err := http.NewRequest(http.MethodGet, dataRoute, nil)
+			mockDataManager.On("LatencyReport").Return(test.ExpectedResponse, test.ExpectedError).Once()
+			req, err := http.NewRequest(http.MethodGet, latencyRoute, nil)
 			require.NoError(t, err)
 
-			query := req.URL.Query()
-			query.Add("compression", test.QueryParam)
-			req.URL.RawQuery = query.Encode()
-
 			testRecorder := httptest.NewRecorder()
 			handler.ServeHTTP(testRecorder, req)
 
 			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
 			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedError.Error())
 				return
 			}
 
-			require.NotNil(t, testRecorder.Body)
-			actualResponse := &dtos.RecordedData{}
-			if test.QueryParam == "" {
-				err = json.Unmarshal(testRecorder.Body.Bytes(), actualResponse)
-				require.NoError(t, err)
-			} else {
-				actualResponse = uncompressData(t, test.QueryParam, testRecorder.Body)
-			}
-
+			actualResponse := &dtos.LatencyReport{}
+			err = json.Unmarshal(testRecorder.Body.Bytes(), actualResponse)
+			require.NoError(t, err)
 			require.Equal(t, test.ExpectedResponse, actualResponse)
-
 		})
 	}
 }
 
-func TestHttpController_ImportRecordedData(t *testing.T) {
-	emptyDataRequest := dtos.RecordedData{}
-	recordedEventRequest := dtos.RecordedData{
-		RecordedEvents: []coreDtos.Event{
-			coreDtos.Event{
-				DeviceName:  "test",
-				ProfileName: "test",
-				Readings: []coreDtos.BaseReading{
-					coreDtos.BaseReading{
-						SimpleReading: coreDtos.SimpleReading{
-							Value: "1456.0",
-						},
-					},
-				},
-			},
-			coreDtos.Event{
-				DeviceName:  "test",
-				ProfileName: "test",
-				Readings: []coreDtos.BaseReading{
-					coreDtos.BaseReading{
-						SimpleReading: coreDtos.SimpleReading{
-							Value: "1456.0",
-						},
-					},
-				},
-			},
-		},
-		Devices: []coreDtos.Device{
-			coreDtos.Device{
-				Name:        "test_device",
-				ProfileName: "test",
-			},
+func TestHttpController_PayloadSizeReport(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.payloadSizeReport))
+
+	report := &dtos.PayloadSizeReport{
+		EventCount: 2,
+		TotalBytes: 300,
+		MinBytes:   100,
+		MaxBytes:   200,
+		MeanBytes:  150,
+		Histogram:  []dtos.PayloadSizeBucket{{MaxBytes: 256, Count: 2}, {Count: 0}},
+		LargestDevices: []dtos.DevicePayloadSize{
+			{DeviceName: "Random-Integer-Device", EventCount: 2, TotalBytes: 300, MaxBytes: 200},
 		},
-		Profiles: []coreDtos.DeviceProfile{
-			coreDtos.DeviceProfile{
-				DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{
-					Name: "test",
-				},
-			},
+		LargestReadings: []dtos.ReadingPayloadSize{
+			{DeviceName: "Random-Integer-Device", ResourceName: "Int8", ValueType: "Int8", Bytes: 3, Origin: 1},
 		},
 	}
 
-	trueParam := "true"
-	falseParam := "false"
-
 	tests := []struct {
 		Name             string
-		ContentEncoding  string
-		ContentType      string
-		OverwriteParam   *string
-		ExpectedResponse []byte
+		Query            string
+		ExpectedCount    int
+		ExpectedResponse *dtos.PayloadSizeReport
 		ExpectedStatus   int
 		ExpectedError    error
+		ExpectedMessage  string
 	}{
-		{
-			Name:             "valid - data with 2 events",
-			ExpectedResponse: marshal(t, recordedEventRequest),
-			ExpectedStatus:   http.StatusAccepted,
-			ExpectedError:    nil,
-			OverwriteParam:   &falseParam,
-			ContentType:      common.ContentTypeJSON,
-		},
-		{
-			Name:             "valid - data with 2 events using json file",
-			ExpectedResponse: readJsonFile(t, "recordedDataJsonUncompressed.json"),
-			ExpectedStatus:   http.StatusAccepted,
-			ExpectedError:    nil,
-			OverwriteParam:   &falseParam,
-			ContentType:      common.ContentTypeJSON,
-		},
-		{
-			Name:             "invalid - no data",
-			ExpectedResponse: marshal(t, emptyDataRequest),
-			ExpectedStatus:   http.StatusBadRequest,
-			ExpectedError:    errors.New(noDataFound),
-			OverwriteParam:   &falseParam,
-			ContentType:      common.ContentTypeJSON,
-		},
-		{
-			Name:             "valid - data with 2 events and compressed gzip",
+		{"Valid", "", 0, report, http.StatusOK, nil, ""},
+		{"Valid with top", "?top=5", 5, report, http.StatusOK, nil, ""},
+		{"Bad top", "?top=many", 0, nil, http.StatusBadRequest, nil, failedTopValidate},
+		{"Zero top", "?top=0", 0, nil, http.StatusBadRequest, nil, failedTopValidate},
+		{"No recording", "", 0, nil, http.StatusNotFound, errors.New("no recording has tracked payload sizes"), "no recording"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.ExpectedResponse != nil || test.ExpectedError != nil {
+				mockDataManager.On("PayloadSizeReport", test.ExpectedCount).Return(test.ExpectedResponse, test.ExpectedError).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodGet, sizesRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedMessage)
+				return
+			}
+
+			actualResponse := &dtos.PayloadSizeReport{}
+			err = json.Unmarshal(testRecorder.Body.Bytes(), actualResponse)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedResponse, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_RecordedDataGaps(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.recordedDataGaps))
+
+	report := &dtos.GapReport{
+		Threshold:     5 * time.Second,
+		ResourceCount: 2,
+		GapCount:      1,
+		Gaps: []dtos.DataGap{
+			{DeviceName: "Random-Integer-Device", ResourceName: "Int8", Start: 1, End: int64(10 * time.Second),
+				Duration: 10*time.Second - 1, Disconnected: true},
+		},
+	}
+
+	tests := []struct {
+		Name              string
+		Query             string
+		ExpectedThreshold time.Duration
+		ExpectedResponse  *dtos.GapReport
+		ExpectedStatus    int
+		ExpectedError     error
+		ExpectedMessage   string
+	}{
+		{"Valid", "?threshold=5s", 5 * time.Second, report, http.StatusOK, nil, ""},
+		{"Missing threshold", "", 0, nil, http.StatusBadRequest, nil, failedThresholdValidate},
+		{"Bad threshold", "?threshold=5", 0, nil, http.StatusBadRequest, nil, failedThresholdValidate},
+		{"Negative threshold", "?threshold=-5s", 0, nil, http.StatusBadRequest, nil, failedThresholdValidate},
+		{"No data", "?threshold=1m", time.Minute, nil, http.StatusNotFound, errors.New("no recorded data present"), "no recorded data"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.ExpectedResponse != nil || test.ExpectedError != nil {
+				mockDataManager.On("RecordedDataGaps", test.ExpectedThreshold).Return(test.ExpectedResponse, test.ExpectedError).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodGet, gapsRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedMessage)
+				return
+			}
+
+			actualResponse := &dtos.GapReport{}
+			err = json.Unmarshal(testRecorder.Body.Bytes(), actualResponse)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedResponse, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_RecordingMetadata(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.recordingMetadata))
+
+	metadata := &dtos.RecordingMetadata{
+		Hostname:       "edge-node-1",
+		ServiceVersion: "3.2.0",
+		SDKVersion:     "v3.2.0",
+		EdgeXVersion:   "v3.2.0",
+		StartedAt:      1700000000000000000,
+		Request:        dtos.RecordRequest{EventLimit: 10, IncludeDevices: []string{"D1"}},
+	}
+
+	tests := []struct {
+		Name             string
+		ExpectedResponse *dtos.RecordingMetadata
+		ExpectedStatus   int
+		ExpectedError    error
+	}{
+		{"Valid", metadata, http.StatusOK, nil},
+		{"No recording", nil, http.StatusNotFound, errors.New("no recorded data present")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDataManager.On("RecordingMetadata").Return(test.ExpectedResponse, test.ExpectedError).Once()
+			req, err := http.NewRequest(http.MethodGet, metadataRoute, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedError.Error())
+				return
+			}
+
+			actualResponse := &dtos.RecordingMetadata{}
+			err = json.Unmarshal(testRecorder.Body.Bytes(), actualResponse)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedResponse, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_PipelineStats(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.pipelineStats))
+
+	stats := dtos.PipelineStats{
+		Recording:              true,
+		ReceivedMessageCount:   12,
+		RecordedEventCount:     10,
+		PendingBatchEventCount: 4,
+		BatchFlushCount:        1,
+		LastBatchSize:          6,
+		PublishQueueDepths:     []int{0, 3},
+		GoroutineCount:         25,
+		PipelineErrorCount:     2,
+		LastPipelineErrors:     []dtos.PipelineError{{Function: "decodeEvent", Error: "bad payload", Timestamp: 1}},
+	}
+	mockDataManager.On("PipelineStats").Return(stats)
+
+	req, err := http.NewRequest(http.MethodGet, pipelineRoute, nil)
+	require.NoError(t, err)
+
+	testRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(testRecorder, req)
+
+	require.Equal(t, http.StatusOK, testRecorder.Code)
+	actualResponse := dtos.PipelineStats{}
+	require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &actualResponse))
+	require.Equal(t, stats, actualResponse)
+}
+
+func TestHttpController_StoreStats(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.storeStats))
+
+	stats := dtos.StoreStats{
+		Memory:   dtos.MemoryStoreStats{EventCount: 10, UsedBytes: 800, AllocatedBytes: 1000, Fragmentation: 0.2},
+		Segments: &dtos.SegmentStoreStats{RecordingCount: 1, SegmentCount: 3, SegmentBytes: 4096},
+	}
+
+	tests := []struct {
+		Name           string
+		ExpectedError  error
+		ExpectedStatus int
+	}{
+		{"Valid", nil, http.StatusOK},
+		{"Scan failed", errors.New("permission denied"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDataManager.On("StoreStats").Return(stats, test.ExpectedError).Once()
+			req, err := http.NewRequest(http.MethodGet, statsRoute, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedError != nil {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedError.Error())
+				return
+			}
+
+			actualResponse := dtos.StoreStats{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &actualResponse))
+			require.Equal(t, stats, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_CompactStore(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.compactStore))
+
+	result := &dtos.CompactResult{ReclaimedMemoryBytes: 200, ReclaimedStorageBytes: 512, RemovedFileCount: 1}
+
+	tests := []struct {
+		Name           string
+		ExpectedResult *dtos.CompactResult
+		ExpectedError  error
+		ExpectedStatus int
+	}{
+		{"Valid", result, nil, http.StatusOK},
+		{"Replay in progress", nil, errors.New("a replay is in progress"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDataManager.On("CompactStore").Return(test.ExpectedResult, test.ExpectedError).Once()
+			req, err := http.NewRequest(http.MethodPost, compactRoute, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedError != nil {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedError.Error())
+				return
+			}
+
+			actualResponse := &dtos.CompactResult{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), actualResponse))
+			require.Equal(t, test.ExpectedResult, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_DeleteRecordedEvents(t *testing.T) {
+	result := &dtos.DeleteEventsResult{DeletedEventCount: 3, RemainingEventCount: 7}
+
+	tests := []struct {
+		Name           string
+		Query          string
+		ExpectedStart  int64
+		ExpectedEnd    int64
+		ExpectedDevice string
+		ExpectedError  error
+		ExpectedStatus int
+	}{
+		{"Valid nanoseconds", "?start=1000&end=2000", 1000, 2000, "", nil, http.StatusOK},
+		{"Valid RFC3339 for device", "?start=2024-01-01T00:00:00Z&end=2024-01-01T00:01:00Z&device=D1", 1704067200000000000, 1704067260000000000, "D1", nil, http.StatusOK},
+		{"Manager error", "?start=1000&end=2000", 1000, 2000, "", errors.New("recorded data is locked"), http.StatusInternalServerError},
+		{"Missing start", "?end=2000", 0, 0, "", errors.New(failedDeleteRangeValidate), http.StatusBadRequest},
+		{"Bad end", "?start=1000&end=later", 0, 0, "", errors.New(failedDeleteRangeValidate), http.StatusBadRequest},
+		{"End before start", "?start=2000&end=1000", 0, 0, "", errors.New(failedDeleteRangeValidate), http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.deleteRecordedEvents))
+
+			if test.ExpectedStatus != http.StatusBadRequest {
+				mockDataManager.On("DeleteRecordedEvents", test.ExpectedStart, test.ExpectedEnd, test.ExpectedDevice).
+					Return(result, test.ExpectedError)
+			}
+
+			req, err := http.NewRequest(http.MethodDelete, eventsRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			mockDataManager.AssertExpectations(t)
+			if test.ExpectedError != nil {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedError.Error())
+				return
+			}
+
+			actualResponse := &dtos.DeleteEventsResult{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), actualResponse))
+			require.Equal(t, result, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_CancelReplay(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.cancelReplay))
+
+	tests := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedError  error
+	}{
+		{"Valid", http.StatusAccepted, nil},
+		{"Error", http.StatusInternalServerError, errors.New("failed")},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDataManager.On("CancelReplay").Return(test.ExpectedError).Once()
+
+			req, err := http.NewRequest(http.MethodGet, recordRoute, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+		})
+	}
+}
+
+func TestHttpController_TriggerReplay(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.triggerReplay))
+
+	tests := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedError  error
+	}{
+		{"Valid", http.StatusAccepted, nil},
+		{"Error", http.StatusInternalServerError, errors.New("failed")},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDataManager.On("TriggerReplay").Return(test.ExpectedError).Once()
+
+			req, err := http.NewRequest(http.MethodPost, triggerRoute, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+		})
+	}
+}
+
+func TestHttpController_SeekReplay(t *testing.T) {
+	response := &dtos.ReplaySeekResponse{EventIndex: 12, Offset: time.Minute}
+
+	tests := []struct {
+		Name            string
+		Body            string
+		ExpectedRequest dtos.ReplaySeekRequest
+		ManagerError    error
+		ExpectedStatus  int
+		ExpectedBody    string
+	}{
+		{"Offset", `{"offset":60000000000}`, dtos.ReplaySeekRequest{Offset: time.Minute}, nil, http.StatusOK, ""},
+		{"Annotation", `{"annotation":"valve-opened"}`, dtos.ReplaySeekRequest{Annotation: "valve-opened"}, nil, http.StatusOK, ""},
+		{"Bad JSON", `{`, dtos.ReplaySeekRequest{}, nil, http.StatusBadRequest, failedRequestJSON},
+		{"Negative offset", `{"offset":-1}`, dtos.ReplaySeekRequest{}, nil, http.StatusBadRequest, failedSeekRequestValidate},
+		{"Offset and annotation", `{"offset":1,"annotation":"valve-opened"}`, dtos.ReplaySeekRequest{}, nil, http.StatusBadRequest, failedSeekRequestValidate},
+		{"Manager error", `{"offset":60000000000}`, dtos.ReplaySeekRequest{Offset: time.Minute}, errors.New("no replay currently running"), http.StatusInternalServerError, "no replay currently running"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.seekReplay))
+
+			if test.ExpectedStatus != http.StatusBadRequest {
+				mockDataManager.On("SeekReplay", test.ExpectedRequest).Return(response, test.ManagerError)
+			}
+
+			req, err := http.NewRequest(http.MethodPatch, seekRoute, bytes.NewBufferString(test.Body))
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			mockDataManager.AssertExpectations(t)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedBody)
+				return
+			}
+
+			actualResponse := &dtos.ReplaySeekResponse{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), actualResponse))
+			assert.Equal(t, response, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_ResumeAndStepReplay(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Method         string
+		Route          string
+		ExpectedStatus int
+		ExpectedError  error
+	}{
+		{"Resume Valid", "ResumeReplay", resumeRoute, http.StatusAccepted, nil},
+		{"Resume Error", "ResumeReplay", resumeRoute, http.StatusInternalServerError, errors.New("failed")},
+		{"Step Valid", "StepReplay", stepRoute, http.StatusAccepted, nil},
+		{"Step Error", "StepReplay", stepRoute, http.StatusInternalServerError, errors.New("failed")},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			mockDataManager.On(test.Method).Return(test.ExpectedError).Once()
+
+			handlerFunc := target.resumeReplay
+			if test.Route == stepRoute {
+				handlerFunc = target.stepReplay
+			}
+			handler := http.HandlerFunc(WrapEchoHandler(t, handlerFunc))
+
+			req, err := http.NewRequest(http.MethodPost, test.Route, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			mockDataManager.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttpController_ExportRecordedData(t *testing.T) {
+	noRecordedData := dtos.RecordedData{}
+	recordedData := dtos.RecordedData{
+		RecordedEvents: []coreDtos.Event{
+			coreDtos.Event{
+				DeviceName:  "test",
+				ProfileName: "test",
+				Readings: []coreDtos.BaseReading{
+					coreDtos.BaseReading{
+						SimpleReading: coreDtos.SimpleReading{
+							Value: "1456.0",
+						},
+					},
+				},
+			},
+			coreDtos.Event{
+				DeviceName:  "test",
+				ProfileName: "test",
+				Readings: []coreDtos.BaseReading{
+					coreDtos.BaseReading{
+						SimpleReading: coreDtos.SimpleReading{
+							Value: "1456.0",
+						},
+					},
+				},
+			},
+		},
+		Devices: []coreDtos.Device{
+			coreDtos.Device{
+				Name:        "test_device",
+				ProfileName: "test",
+			},
+		},
+		Profiles: []coreDtos.DeviceProfile{
+			coreDtos.DeviceProfile{
+				DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{
+					Name: "test",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		Name             string
+		ExpectedResponse *dtos.RecordedData
+		ExpectedStatus   int
+		ExpectedError    error
+		QueryParam       string
+	}{
+		{
+			Name:             "Valid with data",
+			ExpectedResponse: &recordedData,
+			ExpectedStatus:   http.StatusOK,
+			ExpectedError:    nil,
+		},
+		{
+			Name:             "Valid - no events",
+			ExpectedResponse: &noRecordedData,
+			ExpectedStatus:   http.StatusInternalServerError,
+			ExpectedError:    fmt.Errorf("failed"),
+		},
+		{
+			Name:             "Valid with data with GZIP query parameter",
+			ExpectedResponse: &recordedData,
+			ExpectedStatus:   http.StatusOK,
+			ExpectedError:    nil,
+			QueryParam:       gzipCompression,
+		},
+		{
+			Name:             "Valid with data with ZLIB query parameter",
+			ExpectedResponse: &recordedData,
+			ExpectedStatus:   http.StatusOK,
+			ExpectedError:    nil,
+			QueryParam:       zlibCompression,
+		},
+		{
+			Name:             "Valid with data with invalid GZ query parameter",
+			ExpectedResponse: &recordedData,
+			ExpectedStatus:   http.StatusInternalServerError,
+			ExpectedError:    nil,
+			QueryParam:       "GZ",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			mockDataManager.On("ExportRecordedData").Return(test.ExpectedResponse, test.ExpectedError)
+
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData))
+
+			req, err := http.NewRequest(http.MethodGet, dataRoute, nil)
+			require.NoError(t, err)
+
+			query := req.URL.Query()
+			query.Add("compression", test.QueryParam)
+			req.URL.RawQuery = query.Encode()
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				return
+			}
+
+			require.NotNil(t, testRecorder.Body)
+			actualResponse := &dtos.RecordedData{}
+			if test.QueryParam == "" {
+				err = json.Unmarshal(testRecorder.Body.Bytes(), actualResponse)
+				require.NoError(t, err)
+			} else {
+				actualResponse = uncompressData(t, test.QueryParam, testRecorder.Body)
+			}
+
+			require.Equal(t, test.ExpectedResponse, actualResponse)
+
+		})
+	}
+}
+
+func TestHttpController_ExportRecordedData_Projection(t *testing.T) {
+	event := coreDtos.NewEvent("profile", "device", "source")
+	require.NoError(t, event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21)))
+	event.Readings[0].Units = "C"
+
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("ExportRecordedData").Return(&dtos.RecordedData{RecordedEvents: []coreDtos.Event{event}}, nil)
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData))
+
+	tests := []struct {
+		Name           string
+		Query          string
+		ExpectedStatus int
+	}{
+		{"Omit", "?omit=units,tags", http.StatusOK},
+		{"Fields", "?fields=origin,value", http.StatusOK},
+		{"Unknown field", "?omit=origin", http.StatusBadRequest},
+		{"Not native format", "?fields=value&format=ekuiper", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, dataRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus == http.StatusOK {
+				assert.NotContains(t, testRecorder.Body.String(), `"units"`)
+			}
+		})
+	}
+}
+
+func TestHttpController_ExportRecordedData_Named(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("ExportRecordedData").Return(&dtos.RecordedData{Name: "golden-0001"}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, dataRoute, nil)
+	require.NoError(t, err)
+
+	testRecorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData)).ServeHTTP(testRecorder, req)
+
+	require.Equal(t, http.StatusOK, testRecorder.Code)
+	assert.Equal(t, `attachment; filename="golden-0001.json"`, testRecorder.Header().Get("Content-Disposition"))
+}
+
+func TestHttpController_ExportRecordedData_Range(t *testing.T) {
+	recordedData := &dtos.RecordedData{Name: "golden-0001"}
+	expected, err := json.Marshal(recordedData)
+	require.NoError(t, err)
+
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("ExportRecordedData").Return(recordedData, nil)
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData))
+
+	tests := []struct {
+		Name           string
+		Range          string
+		ExpectedStatus int
+		ExpectedBody   []byte
+	}{
+		{"Full", "", http.StatusOK, expected},
+		{"Resumed", "bytes=10-", http.StatusPartialContent, expected[10:]},
+		{"Partial", "bytes=0-4", http.StatusPartialContent, expected[:5]},
+		{"Not satisfiable", fmt.Sprintf("bytes=%d-", len(expected)+10), http.StatusRequestedRangeNotSatisfiable, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, dataRoute, nil)
+			require.NoError(t, err)
+			if len(test.Range) > 0 {
+				req.Header.Set("Range", test.Range)
+			}
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedBody != nil {
+				assert.Equal(t, "bytes", testRecorder.Header().Get("Accept-Ranges"))
+				assert.Equal(t, test.ExpectedBody, testRecorder.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestHttpController_ImportRecordedData(t *testing.T) {
+	emptyDataRequest := dtos.RecordedData{}
+	recordedEventRequest := dtos.RecordedData{
+		RecordedEvents: []coreDtos.Event{
+			coreDtos.Event{
+				DeviceName:  "test",
+				ProfileName: "test",
+				Readings: []coreDtos.BaseReading{
+					coreDtos.BaseReading{
+						SimpleReading: coreDtos.SimpleReading{
+							Value: "1456.0",
+						},
+					},
+				},
+			},
+			coreDtos.Event{
+				DeviceName:  "test",
+				ProfileName: "test",
+				Readings: []coreDtos.BaseReading{
+					coreDtos.BaseReading{
+						SimpleReading: coreDtos.SimpleReading{
+							Value: "1456.0",
+						},
+					},
+				},
+			},
+		},
+		Devices: []coreDtos.Device{
+			coreDtos.Device{
+				Name:        "test_device",
+				ProfileName: "test",
+			},
+		},
+		Profiles: []coreDtos.DeviceProfile{
+			coreDtos.DeviceProfile{
+				DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{
+					Name: "test",
+				},
+			},
+		},
+	}
+
+	opaqueDataRequest := dtos.RecordedData{
+		Messages: []dtos.OpaqueMessage{
+			{
+				EnvelopeMetadata: dtos.EnvelopeMetadata{ReceivedTopic: "edgex/app/custom", ContentType: common.ContentTypeText},
+				Payload:          []byte("raw"),
+			},
+		},
+	}
+
+	trueParam := "true"
+	falseParam := "false"
+
+	tests := []struct {
+		Name             string
+		ContentEncoding  string
+		ContentType      string
+		OverwriteParam   *string
+		FormatParam      string
+		ExpectedResponse []byte
+		ExpectedStatus   int
+		ExpectedError    error
+	}{
+		{
+			Name:             "valid - data with 2 events",
+			ExpectedResponse: marshal(t, recordedEventRequest),
+			ExpectedStatus:   http.StatusAccepted,
+			ExpectedError:    nil,
+			OverwriteParam:   &falseParam,
+			ContentType:      common.ContentTypeJSON,
+		},
+		{
+			Name:             "valid - data with 2 events using json file",
+			ExpectedResponse: readJsonFile(t, "recordedDataJsonUncompressed.json"),
+			ExpectedStatus:   http.StatusAccepted,
+			ExpectedError:    nil,
+			OverwriteParam:   &falseParam,
+			ContentType:      common.ContentTypeJSON,
+		},
+		{
+			Name:             "valid - opaque messages without events",
+			ExpectedResponse: marshal(t, opaqueDataRequest),
+			ExpectedStatus:   http.StatusAccepted,
+			ExpectedError:    nil,
+			OverwriteParam:   &falseParam,
+			ContentType:      common.ContentTypeJSON,
+		},
+		{
+			Name:             "invalid - no data",
+			ExpectedResponse: marshal(t, emptyDataRequest),
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedError:    errors.New(noDataFound),
+			OverwriteParam:   &falseParam,
+			ContentType:      common.ContentTypeJSON,
+		},
+		{
+			Name:             "valid - data with 2 events and compressed gzip",
 			ContentEncoding:  contentEncodingGzip,
 			ExpectedResponse: compressData(t, "GZIP", recordedEventRequest),
 			ExpectedStatus:   http.StatusAccepted,
@@ -619,19 +1472,64 @@ func TestHttpController_ImportRecordedData(t *testing.T) {
 			ContentType:      common.ContentTypeJSON,
 		},
 		{
-			Name:             "invalid - bad Content-type",
-			ExpectedResponse: marshal(t, emptyDataRequest),
-			ExpectedStatus:   http.StatusBadRequest,
-			ExpectedError:    nil,
+			Name:             "valid - format detected regardless of Content-type",
+			ExpectedResponse: marshal(t, recordedEventRequest),
+			ExpectedStatus:   http.StatusAccepted,
 			OverwriteParam:   &falseParam,
 			ContentType:      common.ContentTypeTOML,
 		},
 		{
-			Name:             "invalid - no Content-type",
-			ExpectedResponse: marshal(t, emptyDataRequest),
+			Name:             "valid - gzip detected w/o Content-Encoding",
+			ExpectedResponse: compressData(t, "GZIP", recordedEventRequest),
+			ExpectedStatus:   http.StatusAccepted,
+			ContentType:      "",
+		},
+		{
+			Name:             "valid - zlib detected w/o Content-Encoding",
+			ExpectedResponse: compressData(t, "ZLIB", recordedEventRequest),
+			ExpectedStatus:   http.StatusAccepted,
+			ContentType:      "",
+		},
+		{
+			Name:             "valid - NDJSON",
+			ExpectedResponse: ndjsonData(t, recordedEventRequest),
+			ExpectedStatus:   http.StatusAccepted,
+			ContentType:      "application/x-ndjson",
+		},
+		{
+			Name:             "valid - CBOR",
+			ExpectedResponse: cborData(t, recordedEventRequest),
+			ExpectedStatus:   http.StatusAccepted,
+			ContentType:      common.ContentTypeCBOR,
+		},
+		{
+			Name:             "valid - zip archive",
+			ExpectedResponse: zipData(t, "recording.json", marshal(t, recordedEventRequest)),
+			ExpectedStatus:   http.StatusAccepted,
+			ContentType:      "application/zip",
+		},
+		{
+			Name:             "valid - zip archive of NDJSON w/o extension using format override",
+			ExpectedResponse: zipData(t, "recording", ndjsonData(t, recordedEventRequest)),
+			ExpectedStatus:   http.StatusAccepted,
+			FormatParam:      zipImportFormat,
+		},
+		{
+			Name:             "invalid - format override doesn't match data",
+			ExpectedResponse: marshal(t, recordedEventRequest),
+			ExpectedStatus:   http.StatusBadRequest,
+			FormatParam:      cborImportFormat,
+		},
+		{
+			Name:             "invalid - unknown format override",
+			ExpectedResponse: marshal(t, recordedEventRequest),
+			ExpectedStatus:   http.StatusBadRequest,
+			FormatParam:      "xml",
+		},
+		{
+			Name:             "invalid - format not detected",
+			ExpectedResponse: []byte("not recorded data"),
 			ExpectedStatus:   http.StatusBadRequest,
-			ExpectedError:    nil,
-			OverwriteParam:   &falseParam,
 			ContentType:      "",
 		},
 	}
@@ -639,7 +1537,7 @@ func TestHttpController_ImportRecordedData(t *testing.T) {
 		t.Run(test.Name, func(t *testing.T) {
 			target, mockDataManager, _ := createTargetAndMocks()
 			handler := http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData))
-			mockDataManager.On("ImportRecordedData", mock.Anything, mock.Anything).Return(test.ExpectedError)
+			mockDataManager.On("ImportRecordedData", mock.Anything, mock.Anything, mock.Anything).Return(test.ExpectedError)
 
 			req, err := http.NewRequest(http.MethodPost, dataRoute, bytes.NewReader(test.ExpectedResponse))
 			require.NoError(t, err)
@@ -655,13 +1553,314 @@ func TestHttpController_ImportRecordedData(t *testing.T) {
 				req.URL.RawQuery = query.Encode()
 			}
 
+			if len(test.FormatParam) > 0 {
+				query := req.URL.Query()
+				query.Add(importFormatParam, test.FormatParam)
+				req.URL.RawQuery = query.Encode()
+			}
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+		})
+	}
+
+}
+
+type testProvisioningError []dtos.ProvisioningFailure
+
+func (e testProvisioningError) Error() string {
+	return "failed to provision"
+}
+
+func (e testProvisioningError) ProvisioningFailures() []dtos.ProvisioningFailure {
+	return e
+}
+
+func TestHttpController_ImportRecordedData_ProvisioningFailures(t *testing.T) {
+	failures := []dtos.ProvisioningFailure{{Kind: dtos.ProvisioningKindDevice, Name: "D1", Error: "failed to add device D1 to system"}}
+	data := dtos.RecordedData{
+		RecordedEvents: []coreDtos.Event{{DeviceName: "D1", ProfileName: "P1"}},
+		Devices:        []coreDtos.Device{{Name: "D1", ProfileName: "P1"}},
+		Profiles:       []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "P1"}}},
+	}
+
+	tests := []struct {
+		Name           string
+		ImportError    error
+		ExpectedStatus int
+	}{
+		{"Provisioning failures", fmt.Errorf("import: %w", testProvisioningError(failures)), http.StatusMultiStatus},
+		{"Import failed", errors.New("failed"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData))
+			mockDataManager.On("ImportRecordedData", mock.Anything, mock.Anything, mock.Anything).Return(test.ImportError)
+
+			req, err := http.NewRequest(http.MethodPost, dataRoute, bytes.NewReader(marshal(t, data)))
+			require.NoError(t, err)
+			req.Header.Set(common.ContentType, common.ContentTypeJSON)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusMultiStatus {
+				return
+			}
+
+			var report dtos.ProvisioningReport
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &report))
+			assert.Equal(t, failures, report.Failures)
+		})
+	}
+}
+
+func TestHttpController_ImportRecordedData_Rollback(t *testing.T) {
+	data := dtos.RecordedData{
+		RecordedEvents: []coreDtos.Event{{DeviceName: "D1", ProfileName: "P1"}},
+		Devices:        []coreDtos.Device{{Name: "D1", ProfileName: "P1"}},
+		Profiles:       []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "P1"}}},
+	}
+
+	tests := []struct {
+		Name             string
+		RollbackParam    string
+		ExpectedRollback bool
+		ExpectedStatus   int
+	}{
+		{"Not set", "", false, http.StatusAccepted},
+		{"Rollback", "true", true, http.StatusAccepted},
+		{"Invalid", "always", false, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData))
+			mockDataManager.On("ImportRecordedData", mock.Anything, true, test.ExpectedRollback).Return(nil).Maybe()
+
+			req, err := http.NewRequest(http.MethodPost, dataRoute, bytes.NewReader(marshal(t, data)))
+			require.NoError(t, err)
+			if len(test.RollbackParam) > 0 {
+				query := req.URL.Query()
+				query.Add(importRollbackParam, test.RollbackParam)
+				req.URL.RawQuery = query.Encode()
+			}
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus == http.StatusAccepted {
+				mockDataManager.AssertCalled(t, "ImportRecordedData", mock.Anything, true, test.ExpectedRollback)
+			}
+		})
+	}
+}
+
+func TestHttpController_AssertRecordedData(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.assertRecordedData))
+
+	validRequest := dtos.AssertRequest{
+		Assertions: []dtos.Assertion{{Type: dtos.AssertEventCount, MinCount: 1}},
+	}
+
+	validResponse := &dtos.AssertResponse{
+		Passed: true,
+		Results: []dtos.AssertionResult{
+			{Assertion: validRequest.Assertions[0], Passed: true},
+		},
+	}
+
+	tests := []struct {
+		Name             string
+		Request          []byte
+		ExpectedResponse *dtos.AssertResponse
+		ExpectedStatus   int
+		ExpectedError    error
+		ExpectedMessage  string
+	}{
+		{"Valid", marshal(t, validRequest), validResponse, http.StatusOK, nil, ""},
+		{"Bad JSON", []byte("bad json"), nil, http.StatusBadRequest, nil, failedRequestJSON},
+		{"No Assertions", marshal(t, dtos.AssertRequest{}), nil, http.StatusBadRequest, nil, failedAssertRequestValidate},
+		{"Assert Error", marshal(t, validRequest), nil, http.StatusInternalServerError, errors.New("no recorded data present"), failedAssertingData},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.ExpectedResponse != nil || test.ExpectedError != nil {
+				mockDataManager.On("AssertRecordedData", validRequest).Return(test.ExpectedResponse, test.ExpectedError).Once()
+			}
+
+			req, err := http.NewRequest(http.MethodPost, assertRoute, bytes.NewReader(test.Request))
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedMessage)
+				return
+			}
+
+			actualResponse := &dtos.AssertResponse{}
+			err = json.Unmarshal(testRecorder.Body.Bytes(), actualResponse)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedResponse, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_ValidateRecordedData(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.validateRecordedData))
+
+	invalidReport := &dtos.ValidationReport{
+		EventCount:        2,
+		InvalidEventCount: 1,
+		ProfilesChecked:   true,
+		InvalidEvents: []dtos.EventValidation{
+			{
+				Index:      1,
+				Id:         "1234",
+				DeviceName: "Random-Integer-Device",
+				Errors:     []string{"resource Int64 is not in device profile Random-Integer-Device"},
+			},
+		},
+	}
+
+	tests := []struct {
+		Name             string
+		ExpectedResponse *dtos.ValidationReport
+		ExpectedStatus   int
+		ExpectedError    error
+	}{
+		{"Valid", &dtos.ValidationReport{Valid: true, EventCount: 2}, http.StatusOK, nil},
+		{"Invalid Events", invalidReport, http.StatusOK, nil},
+		{"Validate Error", nil, http.StatusInternalServerError, errors.New("no recorded data present")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDataManager.On("ValidateRecordedData").Return(test.ExpectedResponse, test.ExpectedError).Once()
+
+			req, err := http.NewRequest(http.MethodGet, validateRoute, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), failedValidatingData)
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedError.Error())
+				return
+			}
+
+			actualResponse := &dtos.ValidationReport{}
+			err = json.Unmarshal(testRecorder.Body.Bytes(), actualResponse)
+			require.NoError(t, err)
+			require.Equal(t, test.ExpectedResponse, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_DependencyGraph(t *testing.T) {
+	graph := &dtos.DependencyGraph{
+		Devices:        []dtos.DeviceDependencies{{DeviceName: "d1", ProfileName: "p1", Recorded: true}},
+		Profiles:       []dtos.ProfileDependency{{Name: "p1", DeviceNames: []string{"d1"}, Missing: true}},
+		DeviceServices: []dtos.DeviceServiceDependency{},
+		Missing:        dtos.MissingDependencies{Profiles: []string{"p1"}},
+	}
+
+	tests := []struct {
+		Name             string
+		ExpectedResponse *dtos.DependencyGraph
+		ExpectedStatus   int
+		ExpectedError    error
+	}{
+		{"Valid", graph, http.StatusOK, nil},
+		{"Dependency Graph Error", nil, http.StatusInternalServerError, errors.New("no recorded data present")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.dependencyGraph))
+			mockDataManager.On("DependencyGraph").Return(test.ExpectedResponse, test.ExpectedError)
+
+			req, err := http.NewRequest(http.MethodGet, dependsRoute, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), failedDependencyGraph)
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedError.Error())
+				return
+			}
+
+			actualResponse := &dtos.DependencyGraph{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), actualResponse))
+			assert.Equal(t, test.ExpectedResponse, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_LockRecordedData(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.lockRecordedData))
+
+	tests := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedError  error
+	}{
+		{"Valid", http.StatusAccepted, nil},
+		{"Error", http.StatusInternalServerError, errors.New("no recorded data present")},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDataManager.On("LockRecordedData").Return(test.ExpectedError).Once()
+
+			req, err := http.NewRequest(http.MethodPost, lockRoute, nil)
+			require.NoError(t, err)
+
 			testRecorder := httptest.NewRecorder()
 			handler.ServeHTTP(testRecorder, req)
 
 			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedError != nil {
+				assert.Contains(t, testRecorder.Body.String(), test.ExpectedError.Error())
+			}
 		})
 	}
+}
+
+func TestHttpController_UnlockRecordedData(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("UnlockRecordedData").Once()
+
+	req, err := http.NewRequest(http.MethodDelete, lockRoute, nil)
+	require.NoError(t, err)
+
+	testRecorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.unlockRecordedData)).ServeHTTP(testRecorder, req)
 
+	require.Equal(t, http.StatusAccepted, testRecorder.Code)
+	mockDataManager.AssertExpectations(t)
 }
 
 func marshal(t *testing.T, v any) []byte {
@@ -680,8 +1879,10 @@ func createTargetAndMocks() (*httpController, *mocks.DataManager, *appMocks.Appl
 	mockDataManager := &mocks.DataManager{}
 	mockSdk := &appMocks.ApplicationService{}
 	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{}).Maybe()
+	mockSdk.On("RequestTimeout").Return(5 * time.Second).Maybe()
 
-	target := New(mockDataManager, mockSdk).(*httpController)
+	target := New(mockDataManager, &mocks.Coordinator{}, nil, mockSdk).(*httpController)
 	return target, mockDataManager, mockSdk
 }
 
@@ -729,6 +1930,35 @@ func compressData(t *testing.T, compressionType string, data dtos.RecordedData)
 	return buf.Bytes()
 }
 
+func ndjsonData(t *testing.T, data dtos.RecordedData) []byte {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	require.NoError(t, encoder.Encode(dtos.RecordedData{Devices: data.Devices, Profiles: data.Profiles}))
+	for _, event := range data.RecordedEvents {
+		require.NoError(t, encoder.Encode(event))
+	}
+
+	return buf.Bytes()
+}
+
+func cborData(t *testing.T, data dtos.RecordedData) []byte {
+	encoded, err := cbor.Marshal(data)
+	require.NoError(t, err)
+	return encoded
+}
+
+func zipData(t *testing.T, name string, data []byte) []byte {
+	buf := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(buf)
+	writer, err := zipWriter.Create(name)
+	require.NoError(t, err)
+	_, err = writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zipWriter.Close())
+
+	return buf.Bytes()
+}
+
 // WrapHandler wraps `handler func(http.ResponseWriter, *http.Request)` into `echo.HandlerFunc`
 func WrapEchoHandler(t *testing.T, handler echo.HandlerFunc) func(http.ResponseWriter, *http.Request) {
 	t.Helper()
@@ -0,0 +1,91 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// correlatedRoutes wraps the handler of each custom route with correlationMiddleware, so every route of the service
+// respects and returns the X-Correlation-ID header and is logged, without each route having to opt in
+type correlatedRoutes struct {
+	appInterfaces.ApplicationService
+	lc logger.LoggingClient
+}
+
+// AddCustomRoute adds the route with its handler wrapped by correlationMiddleware
+func (r correlatedRoutes) AddCustomRoute(route string, authentication appInterfaces.Authentication,
+	handler echo.HandlerFunc, methods ...string) error {
+	return r.ApplicationService.AddCustomRoute(route, authentication, correlationMiddleware(r.lc, handler), methods...)
+}
+
+// correlationMiddleware makes sure the request has a correlation ID, using the X-Correlation-ID request header if
+// present, then the ID the SDK stored in the request context, else a new ID. The ID is set on the request, so
+// handlers can pass it to the DataManager, and on the response. Each request is then logged with its method,
+// route, status and duration; server errors at error level and everything else at debug level.
+func correlationMiddleware(lc logger.LoggingClient, next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		begin := time.Now()
+		request := ctx.Request()
+
+		id := request.Header.Get(common.CorrelationHeader)
+		if len(id) == 0 {
+			id, _ = request.Context().Value(common.CorrelationHeader).(string)
+		}
+		if len(id) == 0 {
+			id = uuid.NewString()
+		}
+
+		request.Header.Set(common.CorrelationHeader, id)
+		ctx.Response().Header().Set(common.CorrelationHeader, id)
+
+		err := next(ctx)
+
+		status := ctx.Response().Status
+		if err != nil {
+			// echo writes the error response after the middleware returns, so use the status it will write
+			status = http.StatusInternalServerError
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				status = httpErr.Code
+			}
+		}
+
+		args := []any{common.CorrelationHeader, id, "method", request.Method, "route", ctx.Path(),
+			"status", status, "duration", time.Since(begin).String()}
+		if status >= http.StatusInternalServerError {
+			lc.Error("ARR Request", args...)
+		} else {
+			lc.Debug("ARR Request", args...)
+		}
+
+		return err
+	}
+}
+
+// correlationID returns the correlation ID of the request, which correlationMiddleware sets on the request header
+func correlationID(ctx echo.Context) string {
+	return ctx.Request().Header.Get(common.CorrelationHeader)
+}
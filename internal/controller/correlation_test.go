@@ -0,0 +1,174 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	loggerMocks "github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationMiddleware(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Header         string
+		ContextID      string
+		HandlerStatus  int
+		HandlerError   error
+		ExpectedID     string
+		ExpectedStatus int
+		ExpectedLevel  string
+	}{
+		{"Header", "header-id", "", http.StatusOK, nil, "header-id", http.StatusOK, "Debug"},
+		{"Header over context", "header-id", "context-id", http.StatusAccepted, nil, "header-id", http.StatusAccepted,
+			"Debug"},
+		{"Context", "", "context-id", http.StatusBadRequest, nil, "context-id", http.StatusBadRequest, "Debug"},
+		{"Generated", "", "", http.StatusOK, nil, "", http.StatusOK, "Debug"},
+		{"Server error", "header-id", "", http.StatusInternalServerError, nil, "header-id",
+			http.StatusInternalServerError, "Error"},
+		{"Handler error", "header-id", "", 0, errors.New("failed"), "header-id", http.StatusInternalServerError, "Error"},
+		{"HTTP error", "header-id", "", 0, echo.NewHTTPError(http.StatusNotFound), "header-id", http.StatusNotFound, "Debug"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, replayRoute, nil)
+			require.NoError(t, err)
+			if len(test.Header) > 0 {
+				req.Header.Set(common.CorrelationHeader, test.Header)
+			}
+			if len(test.ContextID) > 0 {
+				// nolint:staticcheck // The SDK stores the correlation ID under the header name
+				req = req.WithContext(context.WithValue(req.Context(), common.CorrelationHeader, test.ContextID))
+			}
+
+			resp := httptest.NewRecorder()
+			ctx := echo.New().NewContext(req, resp)
+			ctx.SetPath(replayRoute)
+
+			var handlerID string
+			handler := func(ctx echo.Context) error {
+				handlerID = correlationID(ctx)
+				if test.HandlerError != nil {
+					return test.HandlerError
+				}
+				return ctx.NoContent(test.HandlerStatus)
+			}
+
+			var expectedID any = test.ExpectedID
+			if len(test.ExpectedID) == 0 {
+				expectedID = mock.Anything
+			}
+
+			mockLogger := &loggerMocks.LoggingClient{}
+			mockLogger.On(test.ExpectedLevel, "ARR Request", common.CorrelationHeader, expectedID, "method",
+				http.MethodPost, "route", replayRoute, "status", test.ExpectedStatus, "duration", mock.Anything).Return()
+
+			err = correlationMiddleware(mockLogger, handler)(ctx)
+			assert.Equal(t, test.HandlerError, err)
+			mockLogger.AssertExpectations(t)
+
+			if len(test.ExpectedID) == 0 {
+				_, err := uuid.Parse(handlerID)
+				require.NoError(t, err)
+			} else {
+				assert.Equal(t, test.ExpectedID, handlerID)
+			}
+			assert.Equal(t, handlerID, resp.Header().Get(common.CorrelationHeader))
+		})
+	}
+}
+
+func TestCorrelatedRoutes_AddCustomRoute(t *testing.T) {
+	var handler echo.HandlerFunc
+	mockSdk := &appMocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("AddCustomRoute", recordRoute, appInterfaces.Unauthenticated, mock.Anything, http.MethodGet).
+		Run(func(args mock.Arguments) {
+			handler = args.Get(2).(echo.HandlerFunc)
+		}).Return(nil)
+
+	target := New(nil, nil, nil, mockSdk).(*httpController)
+	require.NoError(t, target.appSdk.AddCustomRoute(recordRoute, appInterfaces.Unauthenticated,
+		func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) }, http.MethodGet))
+	require.NotNil(t, handler)
+
+	req, err := http.NewRequest(http.MethodGet, recordRoute, nil)
+	require.NoError(t, err)
+	req.Header.Set(common.CorrelationHeader, "test-id")
+	resp := httptest.NewRecorder()
+
+	require.NoError(t, handler(echo.New().NewContext(req, resp)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "test-id", resp.Header().Get(common.CorrelationHeader))
+}
+
+func TestHttpController_CorrelationIDPropagation(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("StartRecording", dtos.RecordRequest{EventLimit: 10, CorrelationID: "record-id"}).Return(nil)
+	mockDataManager.On("StartReplay", dtos.ReplayRequest{ReplayRate: 1, CorrelationID: "replay-id"}).Return(nil)
+	mockDataManager.On("Inject", mock.MatchedBy(func(request dtos.InjectRequest) bool {
+		return request.CorrelationID == "inject-id"
+	})).Return(dtos.InjectResponse{PublishedEventCount: 1}, nil)
+
+	tests := []struct {
+		Name           string
+		Handler        echo.HandlerFunc
+		Request        any
+		ID             string
+		ExpectedStatus int
+	}{
+		{"Record", target.startRecording, dtos.RecordRequest{EventLimit: 10}, "record-id", http.StatusAccepted},
+		{"Replay", target.startReplay, dtos.ReplayRequest{ReplayRate: 1}, "replay-id", http.StatusAccepted},
+		{"Inject", target.inject, dtos.InjectRequest{Messages: []dtos.InjectMessage{{Topic: "test"}}}, "inject-id",
+			http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			body, err := json.Marshal(test.Request)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set(common.CorrelationHeader, test.ID)
+
+			resp := httptest.NewRecorder()
+			handler := correlationMiddleware(logger.NewMockClient(), test.Handler)
+			require.NoError(t, handler(echo.New().NewContext(req, resp)))
+			assert.Equal(t, test.ExpectedStatus, resp.Code, resp.Body.String())
+		})
+	}
+
+	mockDataManager.AssertExpectations(t)
+}
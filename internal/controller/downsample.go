@@ -0,0 +1,251 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	downsampleFirst   = "first"
+	downsampleLast    = "last"
+	downsampleAverage = "avg"
+
+	downsampleNameSuffix = "-downsampled"
+)
+
+var (
+	invalidDownsampleInterval    = errors.New("interval must be greater than 0")
+	invalidDownsampleAggregation = fmt.Errorf("aggregation must be one of %s, %s or %s", downsampleFirst, downsampleLast, downsampleAverage)
+	downsampleOpaqueError        = errors.New("opaque messages can't be downsampled since they aren't decoded")
+)
+
+type downsampleKey struct {
+	start       int64
+	deviceName  string
+	profileName string
+	sourceName  string
+}
+
+// downsampleBucket holds the Event kept for a bucket and, when averaging, the sum and count of the numeric Reading
+// values of each resource in the bucket
+type downsampleBucket struct {
+	index  int
+	sums   map[string]float64
+	counts map[string]int
+}
+
+// validateDownsampleRequest checks the request, defaulting the aggregation to first
+func validateDownsampleRequest(request *dtos.DownsampleRequest) error {
+	if request.Interval <= 0 {
+		return invalidDownsampleInterval
+	}
+
+	switch request.Aggregation {
+	case "":
+		request.Aggregation = downsampleFirst
+	case downsampleFirst, downsampleLast, downsampleAverage:
+	default:
+		return invalidDownsampleAggregation
+	}
+
+	return nil
+}
+
+// downsampleRecordedData returns a copy of the recorded data with the Events of each device and source resampled
+// to one per interval, along with the Envelopes of the Events kept. The Device Profiles, Devices, annotations and
+// metadata are kept as is, while the dead letters are dropped since they aren't replayed.
+func downsampleRecordedData(data *dtos.RecordedData, request dtos.DownsampleRequest) (*dtos.RecordedData, error) {
+	if len(data.Messages) > 0 {
+		return nil, downsampleOpaqueError
+	}
+
+	name := request.Name
+	if len(name) == 0 {
+		name = data.Name + downsampleNameSuffix
+	}
+
+	result := &dtos.RecordedData{
+		Name:           name,
+		RecordedEvents: downsampleEvents(data.RecordedEvents, request),
+		Profiles:       data.Profiles,
+		Devices:        data.Devices,
+		SystemEvents:   data.SystemEvents,
+		Annotations:    data.Annotations,
+		Metadata:       data.Metadata,
+	}
+
+	if len(data.Envelopes) > 0 {
+		result.Envelopes = make(map[string]dtos.EnvelopeMetadata)
+		for _, event := range result.RecordedEvents {
+			if envelope, ok := data.Envelopes[event.Id]; ok {
+				result.Envelopes[event.Id] = envelope
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// downsampleEvents reduces the Events of each device and source within each interval, aligned to multiples of the
+// interval by the Events' Origins, to the first or last Event or the first Event with its numeric Readings averaged.
+// The Events kept stay in their recorded order.
+func downsampleEvents(events []coreDtos.Event, request dtos.DownsampleRequest) []coreDtos.Event {
+	interval := int64(request.Interval)
+	buckets := make(map[downsampleKey]*downsampleBucket)
+	for index, event := range events {
+		key := downsampleKey{
+			start:       event.Origin - event.Origin%interval,
+			deviceName:  event.DeviceName,
+			profileName: event.ProfileName,
+			sourceName:  event.SourceName,
+		}
+
+		bucket, ok := buckets[key]
+		switch {
+		case !ok:
+			bucket = &downsampleBucket{index: index}
+			buckets[key] = bucket
+		case request.Aggregation == downsampleLast:
+			bucket.index = index
+		}
+
+		if request.Aggregation != downsampleAverage {
+			continue
+		}
+
+		if bucket.sums == nil {
+			bucket.sums = make(map[string]float64)
+			bucket.counts = make(map[string]int)
+		}
+
+		for _, reading := range event.Readings {
+			if !isNumericValueType(reading.ValueType) {
+				continue
+			}
+
+			value, err := strconv.ParseFloat(reading.Value, 64)
+			if err != nil {
+				continue
+			}
+
+			bucket.sums[reading.ResourceName] += value
+			bucket.counts[reading.ResourceName]++
+		}
+	}
+
+	kept := make([]*downsampleBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		kept = append(kept, bucket)
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].index < kept[j].index })
+
+	result := make([]coreDtos.Event, 0, len(kept))
+	for _, bucket := range kept {
+		event := events[bucket.index]
+		if request.Aggregation == downsampleAverage {
+			event = averageEvent(event, bucket)
+		}
+		result = append(result, event)
+	}
+
+	return result
+}
+
+// averageEvent returns a copy of the Event with the value of each numeric Reading replaced by the average of the
+// bucket's values for the Reading's resource
+func averageEvent(event coreDtos.Event, bucket *downsampleBucket) coreDtos.Event {
+	readings := make([]coreDtos.BaseReading, len(event.Readings))
+	copy(readings, event.Readings)
+
+	for index, reading := range readings {
+		count := bucket.counts[reading.ResourceName]
+		if count == 0 || !isNumericValueType(reading.ValueType) {
+			continue
+		}
+
+		readings[index].Value = formatAverage(reading.ValueType, bucket.sums[reading.ResourceName]/float64(count))
+	}
+
+	event.Readings = readings
+	return event
+}
+
+func isNumericValueType(valueType string) bool {
+	switch valueType {
+	case common.ValueTypeInt8, common.ValueTypeInt16, common.ValueTypeInt32, common.ValueTypeInt64,
+		common.ValueTypeUint8, common.ValueTypeUint16, common.ValueTypeUint32, common.ValueTypeUint64,
+		common.ValueTypeFloat32, common.ValueTypeFloat64:
+		return true
+	default:
+		return false
+	}
+}
+
+// formatAverage formats the average as a value of the numeric value type, rounding it for integer types. Floats are
+// formatted the same as the EdgeX contracts format them when adding a Reading.
+func formatAverage(valueType string, average float64) string {
+	switch valueType {
+	case common.ValueTypeFloat32, common.ValueTypeFloat64:
+		return fmt.Sprintf("%e", average)
+	case common.ValueTypeUint8, common.ValueTypeUint16, common.ValueTypeUint32, common.ValueTypeUint64:
+		return strconv.FormatUint(uint64(math.Max(math.Round(average), 0)), 10)
+	default:
+		return strconv.FormatInt(int64(math.Round(average)), 10)
+	}
+}
+
+// downsampleToPath resamples the recorded data into a derived recording, which is written to a file on the local
+// filesystem, and returns the file written as the HTTP response. The recorded data is left intact.
+func (c *httpController) downsampleToPath(ctx echo.Context) error {
+	request := dtos.DownsampleRequest{}
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&request); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	if err := validateDownsampleRequest(&request); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedDownsampleValidate, err))
+	}
+
+	return c.exportToLocalPath(ctx, request.LocalExportRequest, func() (*dtos.RecordedData, error) {
+		data, err := c.dataManager.ExportRecordedData()
+		if err != nil {
+			return nil, err
+		}
+
+		downsampled, err := downsampleRecordedData(data, request)
+		if err != nil {
+			return nil, err
+		}
+
+		c.appSdk.LoggingClient().Infof("ARR Downsample - Resampled %d events to %d with %s per %s",
+			len(data.RecordedEvents), len(downsampled.RecordedEvents), request.Aggregation, request.Interval)
+
+		return downsampled, nil
+	})
+}
@@ -0,0 +1,199 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDownsampleEvent(t *testing.T, deviceName string, origin time.Duration, value int32, label string) coreDtos.Event {
+	event := coreDtos.NewEvent("profile", deviceName, "source")
+	event.Origin = int64(origin)
+	require.NoError(t, event.AddSimpleReading("Int32", common.ValueTypeInt32, value))
+	require.NoError(t, event.AddSimpleReading("Label", common.ValueTypeString, label))
+	require.NoError(t, event.AddSimpleReading("Float64", common.ValueTypeFloat64, float64(value)/2))
+	return event
+}
+
+func TestDownsampleEvents(t *testing.T) {
+	events := []coreDtos.Event{
+		newDownsampleEvent(t, "D1", 0, 1, "a"),
+		newDownsampleEvent(t, "D2", 500*time.Millisecond, 10, "x"),
+		newDownsampleEvent(t, "D1", 500*time.Millisecond, 2, "b"),
+		newDownsampleEvent(t, "D1", 900*time.Millisecond, 4, "c"),
+		newDownsampleEvent(t, "D1", 1500*time.Millisecond, 8, "d"),
+	}
+
+	tests := []struct {
+		Name           string
+		Aggregation    string
+		ExpectedEvents []int
+		ExpectedInt32  []string
+		ExpectedFloat  []string
+		ExpectedLabel  []string
+	}{
+		{"First", downsampleFirst, []int{0, 1, 4}, []string{"1", "10", "8"},
+			[]string{"5.000000e-01", "5.000000e+00", "4.000000e+00"}, []string{"a", "x", "d"}},
+		{"Last", downsampleLast, []int{1, 3, 4}, []string{"10", "4", "8"},
+			[]string{"5.000000e+00", "2.000000e+00", "4.000000e+00"}, []string{"x", "c", "d"}},
+		{"Average", downsampleAverage, []int{0, 1, 4}, []string{"2", "10", "8"},
+			[]string{"1.166667e+00", "5.000000e+00", "4.000000e+00"}, []string{"a", "x", "d"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			request := dtos.DownsampleRequest{Interval: time.Second, Aggregation: test.Aggregation}
+			actual := downsampleEvents(events, request)
+			require.Len(t, actual, len(test.ExpectedEvents))
+			for index, expected := range test.ExpectedEvents {
+				assert.Equal(t, events[expected].Id, actual[index].Id)
+				assert.Equal(t, test.ExpectedInt32[index], actual[index].Readings[0].Value)
+				assert.Equal(t, test.ExpectedLabel[index], actual[index].Readings[1].Value)
+				assert.Equal(t, test.ExpectedFloat[index], actual[index].Readings[2].Value)
+			}
+		})
+	}
+
+	// Averaging copies the Readings rather than changing the recorded Events
+	assert.Equal(t, "1", events[0].Readings[0].Value)
+}
+
+func TestValidateDownsampleRequest(t *testing.T) {
+	request := dtos.DownsampleRequest{Interval: time.Second}
+	require.NoError(t, validateDownsampleRequest(&request))
+	assert.Equal(t, downsampleFirst, request.Aggregation)
+
+	request = dtos.DownsampleRequest{Interval: time.Second, Aggregation: downsampleAverage}
+	require.NoError(t, validateDownsampleRequest(&request))
+	assert.Equal(t, downsampleAverage, request.Aggregation)
+
+	assert.Equal(t, invalidDownsampleInterval, validateDownsampleRequest(&dtos.DownsampleRequest{}))
+	assert.Equal(t, invalidDownsampleAggregation,
+		validateDownsampleRequest(&dtos.DownsampleRequest{Interval: time.Second, Aggregation: "median"}))
+}
+
+func TestFormatAverage(t *testing.T) {
+	assert.Equal(t, "3", formatAverage(common.ValueTypeInt8, 2.5))
+	assert.Equal(t, "-3", formatAverage(common.ValueTypeInt64, -2.5))
+	assert.Equal(t, "0", formatAverage(common.ValueTypeUint16, -1))
+	assert.Equal(t, "2.500000e+00", formatAverage(common.ValueTypeFloat32, 2.5))
+}
+
+func TestHttpController_DownsampleToPath(t *testing.T) {
+	recordedData := &dtos.RecordedData{
+		Name: "line-1",
+		RecordedEvents: []coreDtos.Event{
+			newDownsampleEvent(t, "D1", 0, 1, "a"),
+			newDownsampleEvent(t, "D1", 500*time.Millisecond, 3, "b"),
+			newDownsampleEvent(t, "D1", 1500*time.Millisecond, 5, "c"),
+		},
+		Devices:  []coreDtos.Device{{Name: "D1", ProfileName: "profile"}},
+		Profiles: []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "profile"}}},
+	}
+	recordedData.Envelopes = map[string]dtos.EnvelopeMetadata{
+		recordedData.RecordedEvents[0].Id: {CorrelationID: "first"},
+		recordedData.RecordedEvents[1].Id: {CorrelationID: "second"},
+	}
+
+	tests := []struct {
+		Name           string
+		Request        dtos.DownsampleRequest
+		ExportData     *dtos.RecordedData
+		ExportError    error
+		ExpectedStatus int
+		ExpectedFile   string
+		ExpectedName   string
+	}{
+		{"Downsample", dtos.DownsampleRequest{Interval: time.Second}, recordedData, nil, http.StatusOK,
+			"line-1-downsampled.json", "line-1-downsampled"},
+		{"Downsample named", dtos.DownsampleRequest{Interval: time.Second, Aggregation: downsampleAverage, Name: "smoke"},
+			recordedData, nil, http.StatusOK, "smoke.json", "smoke"},
+		{"Bad interval", dtos.DownsampleRequest{}, recordedData, nil, http.StatusBadRequest, "", ""},
+		{"Bad aggregation", dtos.DownsampleRequest{Interval: time.Second, Aggregation: "median"}, recordedData, nil,
+			http.StatusBadRequest, "", ""},
+		{"No recorded data", dtos.DownsampleRequest{Interval: time.Second}, nil, errors.New("no recorded data"),
+			http.StatusInternalServerError, "", ""},
+		{"Opaque", dtos.DownsampleRequest{Interval: time.Second},
+			&dtos.RecordedData{Messages: []dtos.OpaqueMessage{{}}}, nil, http.StatusInternalServerError, "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			usbDir := t.TempDir()
+			test.Request.Path = usbDir
+
+			mockDataManager := &mocks.DataManager{}
+			mockDataManager.On("ExportRecordedData").Return(test.ExportData, test.ExportError)
+			mockSdk := &appMocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ExportPathsAppSetting: usbDir})
+			target := New(mockDataManager, nil, nil, mockSdk).(*httpController)
+
+			body, err := json.Marshal(test.Request)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, downsampleRoute, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.downsampleToPath)).ServeHTTP(recorder, req)
+			require.Equal(t, test.ExpectedStatus, recorder.Code, recorder.Body.String())
+
+			if test.ExpectedStatus != http.StatusOK {
+				return
+			}
+
+			response := dtos.LocalExportResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			assert.Equal(t, test.ExpectedFile, filepath.Base(response.Path))
+
+			data, err := os.ReadFile(response.Path)
+			require.NoError(t, err)
+
+			downsampled := &dtos.RecordedData{}
+			require.NoError(t, json.Unmarshal(data, downsampled))
+			assert.Equal(t, test.ExpectedName, downsampled.Name)
+			require.Len(t, downsampled.RecordedEvents, 2)
+			assert.Equal(t, recordedData.RecordedEvents[0].Id, downsampled.RecordedEvents[0].Id)
+			assert.Equal(t, recordedData.RecordedEvents[2].Id, downsampled.RecordedEvents[1].Id)
+			assert.Equal(t, recordedData.Devices, downsampled.Devices)
+			assert.Len(t, downsampled.Profiles, 1)
+			assert.Equal(t, map[string]dtos.EnvelopeMetadata{recordedData.RecordedEvents[0].Id: {CorrelationID: "first"}},
+				downsampled.Envelopes)
+
+			// The recorded data the service holds is left as is
+			assert.Len(t, recordedData.RecordedEvents, 3)
+			assert.Equal(t, "1", recordedData.RecordedEvents[0].Readings[0].Value)
+		})
+	}
+}
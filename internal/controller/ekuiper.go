@@ -0,0 +1,92 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const (
+	ekuiperDeviceNameField  = "deviceName"
+	ekuiperProfileNameField = "profileName"
+	ekuiperSourceNameField  = "sourceName"
+	ekuiperOriginField      = "origin"
+)
+
+// toEKuiperSamples converts the recorded Events to the JSON array format consumed by the eKuiper file source.
+// Each Event becomes a flat object with a field per Reading, keyed by resource name, holding the typed value
+// as the eKuiper EdgeX source would produce, plus the Event's device, profile, source names and origin.
+func toEKuiperSamples(events []coreDtos.Event) []map[string]any {
+	samples := make([]map[string]any, 0, len(events))
+	for _, event := range events {
+		sample := map[string]any{
+			ekuiperDeviceNameField:  event.DeviceName,
+			ekuiperProfileNameField: event.ProfileName,
+			ekuiperSourceNameField:  event.SourceName,
+			ekuiperOriginField:      event.Origin,
+		}
+
+		for _, reading := range event.Readings {
+			sample[reading.ResourceName] = ekuiperReadingValue(reading)
+		}
+
+		samples = append(samples, sample)
+	}
+
+	return samples
+}
+
+// ekuiperReadingValue returns the Reading value converted to its native type. The value is left as
+// a string if it can't be converted.
+func ekuiperReadingValue(reading coreDtos.BaseReading) any {
+	valueType := reading.ValueType
+
+	switch {
+	case valueType == common.ValueTypeBinary:
+		return reading.BinaryValue
+	case valueType == common.ValueTypeObject:
+		return reading.ObjectValue
+	case strings.HasSuffix(valueType, "Array"):
+		var value []any
+		if err := json.Unmarshal([]byte(reading.Value), &value); err == nil {
+			return value
+		}
+	case valueType == common.ValueTypeBool:
+		if value, err := strconv.ParseBool(reading.Value); err == nil {
+			return value
+		}
+	case strings.HasPrefix(valueType, "Int"):
+		if value, err := strconv.ParseInt(reading.Value, 10, 64); err == nil {
+			return value
+		}
+	case strings.HasPrefix(valueType, "Uint"):
+		if value, err := strconv.ParseUint(reading.Value, 10, 64); err == nil {
+			return value
+		}
+	case strings.HasPrefix(valueType, "Float"):
+		if value, err := strconv.ParseFloat(reading.Value, 64); err == nil {
+			return value
+		}
+	}
+
+	return reading.Value
+}
@@ -0,0 +1,92 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEKuiperReadingValue(t *testing.T) {
+	tests := []struct {
+		Name      string
+		ValueType string
+		Value     string
+		Expected  any
+	}{
+		{"Int8", common.ValueTypeInt8, "-12", int64(-12)},
+		{"Uint64", common.ValueTypeUint64, "18446744073709551615", uint64(18446744073709551615)},
+		{"Float32", common.ValueTypeFloat32, "1.250000e+01", 12.5},
+		{"Bool", common.ValueTypeBool, "true", true},
+		{"String", common.ValueTypeString, "hello", "hello"},
+		{"Int16Array", common.ValueTypeInt16Array, "[1, 2]", []any{float64(1), float64(2)}},
+		{"Bad Int", common.ValueTypeInt32, "bad", "bad"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			reading := coreDtos.BaseReading{
+				ValueType:     test.ValueType,
+				SimpleReading: coreDtos.SimpleReading{Value: test.Value},
+			}
+			assert.Equal(t, test.Expected, ekuiperReadingValue(reading))
+		})
+	}
+}
+
+func TestHttpController_ExportRecordedData_EKuiper(t *testing.T) {
+	event := coreDtos.NewEvent("test-profile", "test-device", "test-source")
+	_ = event.AddSimpleReading("Temperature", common.ValueTypeFloat64, 21.5)
+	_ = event.AddSimpleReading("Switch", common.ValueTypeBool, true)
+
+	recordedData := &dtos.RecordedData{RecordedEvents: []coreDtos.Event{event}}
+
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("ExportRecordedData").Return(recordedData, nil)
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData))
+
+	req, err := http.NewRequest(http.MethodGet, dataRoute+"?format=ekuiper", nil)
+	require.NoError(t, err)
+	testRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(testRecorder, req)
+	require.Equal(t, http.StatusOK, testRecorder.Code)
+
+	var samples []map[string]any
+	require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &samples))
+	require.Len(t, samples, 1)
+	assert.Equal(t, "test-device", samples[0][ekuiperDeviceNameField])
+	assert.Equal(t, "test-profile", samples[0][ekuiperProfileNameField])
+	assert.Equal(t, "test-source", samples[0][ekuiperSourceNameField])
+	assert.Equal(t, float64(event.Origin), samples[0][ekuiperOriginField])
+	assert.Equal(t, 21.5, samples[0]["Temperature"])
+	assert.Equal(t, true, samples[0]["Switch"])
+
+	req, err = http.NewRequest(http.MethodGet, dataRoute+"?format=bogus", nil)
+	require.NoError(t, err)
+	testRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(testRecorder, req)
+	require.Equal(t, http.StatusBadRequest, testRecorder.Code)
+}
@@ -0,0 +1,58 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	etagHeader        = "ETag"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+// computeETag returns the strong ETag for the response body
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%x"`, sum[:16])
+}
+
+// setETag sets the ETag for the response body and returns true if it matches the request's If-None-Match header,
+// in which case the client already has the current response and Not Modified should be returned instead.
+func setETag(ctx echo.Context, body []byte) bool {
+	etag := computeETag(body)
+	ctx.Response().Header().Set(etagHeader, etag)
+
+	ifNoneMatch := ctx.Request().Header.Get(ifNoneMatchHeader)
+	if len(ifNoneMatch) == 0 {
+		return false
+	}
+
+	// If-None-Match uses weak comparison, so W/ prefixed ETags also match
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetETag(t *testing.T) {
+	body := []byte(`{"eventCount":10}`)
+	etag := computeETag(body)
+
+	tests := []struct {
+		Name        string
+		IfNoneMatch string
+		Expected    bool
+	}{
+		{"No header", "", false},
+		{"Match", etag, true},
+		{"Weak match", "W/" + etag, true},
+		{"Match in list", `"other", ` + etag, true},
+		{"Any", "*", true},
+		{"No match", `"other"`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, recordRoute, nil)
+			require.NoError(t, err)
+			if len(test.IfNoneMatch) > 0 {
+				req.Header.Set(ifNoneMatchHeader, test.IfNoneMatch)
+			}
+
+			testRecorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, func(ctx echo.Context) error {
+				assert.Equal(t, test.Expected, setETag(ctx, body))
+				return nil
+			})).ServeHTTP(testRecorder, req)
+
+			assert.Equal(t, etag, testRecorder.Header().Get(etagHeader))
+		})
+	}
+}
+
+func TestHttpController_ConditionalGet(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("RecordingStatus").Return(dtos.RecordStatus{EventCount: 10, Duration: time.Second})
+	mockDataManager.On("ReplayStatus").Return(dtos.ReplayStatus{EventCount: 10, Duration: time.Second})
+	mockDataManager.On("ExportRecordedData").Return(&dtos.RecordedData{Name: "golden-0001"}, nil)
+
+	tests := []struct {
+		Name    string
+		Route   string
+		Handler echo.HandlerFunc
+	}{
+		{"Recording status", recordRoute, target.recordingStatus},
+		{"Replay status", replayRoute, target.replayStatus},
+		{"Export", dataRoute, target.exportRecordedData},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			handler := http.HandlerFunc(WrapEchoHandler(t, test.Handler))
+
+			req, err := http.NewRequest(http.MethodGet, test.Route, nil)
+			require.NoError(t, err)
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+			require.Equal(t, http.StatusOK, testRecorder.Code)
+
+			etag := testRecorder.Header().Get(etagHeader)
+			require.NotEmpty(t, etag)
+
+			req.Header.Set(ifNoneMatchHeader, etag)
+			testRecorder = httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+			require.Equal(t, http.StatusNotModified, testRecorder.Code)
+			assert.Empty(t, testRecorder.Body.Bytes())
+		})
+	}
+}
@@ -0,0 +1,230 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const (
+	// AnonymizeMaxTimeShiftAppSetting is the largest offset, either way, the timestamps of an anonymized export are
+	// shifted by. A random offset is picked for each export, so the intervals between the Events are preserved.
+	AnonymizeMaxTimeShiftAppSetting = "AnonymizeMaxTimeShift"
+	// AnonymizeTagPatternsAppSetting is the comma separated list of regular expressions matching the names of the
+	// Event, Reading and Device tags scrubbed from an anonymized export
+	AnonymizeTagPatternsAppSetting = "AnonymizeTagPatterns"
+
+	anonymizeParam            = "anonymize"
+	anonymizationSecretName   = "arr-anonymization"
+	anonymizationSalt         = "salt"
+	anonymizedDevicePrefix    = "device-"
+	anonymizedDeviceHashBytes = 8
+)
+
+var anonymizeOpaqueError = errors.New("opaque recordings can't be anonymized since their messages aren't decoded")
+
+// anonymizationProfile holds the configured anonymization applied to an export
+type anonymizationProfile struct {
+	salt         []byte
+	maxTimeShift time.Duration
+	tagPatterns  []*regexp.Regexp
+}
+
+// getAnonymizationProfile returns the configured anonymization profile. The salt device names are hashed with is
+// held in the secret store, so the anonymized names can't be reversed by hashing known device names.
+func (c *httpController) getAnonymizationProfile() (*anonymizationProfile, error) {
+	secrets, err := c.appSdk.SecretProvider().GetSecret(anonymizationSecretName, anonymizationSalt)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get %s from secret %s: %v", anonymizationSalt, anonymizationSecretName, err)
+	}
+
+	if len(secrets[anonymizationSalt]) == 0 {
+		return nil, fmt.Errorf("%s in secret %s must not be empty", anonymizationSalt, anonymizationSecretName)
+	}
+
+	profile := &anonymizationProfile{salt: []byte(secrets[anonymizationSalt])}
+
+	settings := c.appSdk.ApplicationSettings()
+	if setting := settings[AnonymizeMaxTimeShiftAppSetting]; len(setting) > 0 {
+		profile.maxTimeShift, err = time.ParseDuration(setting)
+		if err != nil || profile.maxTimeShift < 0 {
+			return nil, fmt.Errorf("invalid %s value '%s', must be a duration of 0 or more", AnonymizeMaxTimeShiftAppSetting, setting)
+		}
+	}
+
+	for _, pattern := range strings.Split(settings[AnonymizeTagPatternsAppSetting], ",") {
+		pattern = strings.TrimSpace(pattern)
+		if len(pattern) == 0 {
+			continue
+		}
+
+		expression, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern '%s': %v", AnonymizeTagPatternsAppSetting, pattern, err)
+		}
+		profile.tagPatterns = append(profile.tagPatterns, expression)
+	}
+
+	return profile, nil
+}
+
+// anonymizeRecordedData returns the recorded data anonymized with the configured anonymization profile, shifting the
+// timestamps by a random offset
+func (c *httpController) anonymizeRecordedData(data *dtos.RecordedData) (*dtos.RecordedData, error) {
+	profile, err := c.getAnonymizationProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	shift, err := profile.randomTimeShift()
+	if err != nil {
+		return nil, err
+	}
+
+	c.appSdk.LoggingClient().Debugf("ARR Export - Anonymizing %d events", len(data.RecordedEvents))
+
+	return profile.anonymize(data, shift)
+}
+
+// randomTimeShift returns a random offset between -maxTimeShift and maxTimeShift
+func (p *anonymizationProfile) randomTimeShift() (time.Duration, error) {
+	if p.maxTimeShift == 0 {
+		return 0, nil
+	}
+
+	value, err := rand.Int(rand.Reader, big.NewInt(2*int64(p.maxTimeShift)+1))
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(value.Int64()) - p.maxTimeShift, nil
+}
+
+// deviceName returns the anonymized name of the device, which is the same for every export with the same salt, so
+// anonymized datasets can be joined
+func (p *anonymizationProfile) deviceName(name string) string {
+	if len(name) == 0 {
+		return name
+	}
+
+	mac := hmac.New(sha256.New, p.salt)
+	mac.Write([]byte(name))
+	return anonymizedDevicePrefix + hex.EncodeToString(mac.Sum(nil)[:anonymizedDeviceHashBytes])
+}
+
+// scrubTags returns a copy of the tags without those whose names match the tag patterns
+func (p *anonymizationProfile) scrubTags(tags map[string]any) map[string]any {
+	if tags == nil {
+		return nil
+	}
+
+	scrubbed := make(map[string]any, len(tags))
+	for name, value := range tags {
+		if !p.matchesTagPattern(name) {
+			scrubbed[name] = value
+		}
+	}
+
+	if len(scrubbed) == 0 {
+		return nil
+	}
+
+	return scrubbed
+}
+
+func (p *anonymizationProfile) matchesTagPattern(name string) bool {
+	for _, pattern := range p.tagPatterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// anonymize returns a privacy-safe copy of the recorded data. Device names are hashed, timestamps are shifted by the
+// offset and tags matching the patterns are scrubbed. The device details which may identify a site, i.e. protocol
+// addresses and locations, are dropped along with the envelope metadata, dead letters and the recording's host and
+// request, since they hold raw topics and payloads naming the devices. The recorded data isn't modified.
+func (p *anonymizationProfile) anonymize(data *dtos.RecordedData, shift time.Duration) (*dtos.RecordedData, error) {
+	if len(data.Messages) > 0 {
+		return nil, anonymizeOpaqueError
+	}
+
+	anonymized := &dtos.RecordedData{
+		Name:           data.Name,
+		RecordedEvents: make([]coreDtos.Event, len(data.RecordedEvents)),
+		Profiles:       data.Profiles,
+		Devices:        make([]coreDtos.Device, len(data.Devices)),
+	}
+
+	for i, event := range data.RecordedEvents {
+		event.DeviceName = p.deviceName(event.DeviceName)
+		event.Origin += int64(shift)
+		event.Tags = p.scrubTags(event.Tags)
+
+		readings := make([]coreDtos.BaseReading, len(event.Readings))
+		for j, reading := range event.Readings {
+			reading.DeviceName = p.deviceName(reading.DeviceName)
+			reading.Origin += int64(shift)
+			reading.Tags = p.scrubTags(reading.Tags)
+			readings[j] = reading
+		}
+		event.Readings = readings
+
+		anonymized.RecordedEvents[i] = event
+	}
+
+	for i, device := range data.Devices {
+		anonymized.Devices[i] = coreDtos.Device{
+			Name:           p.deviceName(device.Name),
+			Parent:         p.deviceName(device.Parent),
+			AdminState:     device.AdminState,
+			OperatingState: device.OperatingState,
+			Labels:         device.Labels,
+			ServiceName:    device.ServiceName,
+			ProfileName:    device.ProfileName,
+			AutoEvents:     device.AutoEvents,
+			Protocols:      make(map[string]coreDtos.ProtocolProperties),
+			Tags:           p.scrubTags(device.Tags),
+		}
+	}
+
+	if metadata := data.Metadata; metadata != nil {
+		anonymized.Metadata = &dtos.RecordingMetadata{
+			ServiceVersion: metadata.ServiceVersion,
+			SDKVersion:     metadata.SDKVersion,
+			EdgeXVersion:   metadata.EdgeXVersion,
+			StartedAt:      metadata.StartedAt + int64(shift),
+			ProfileHashes:  metadata.ProfileHashes,
+		}
+	}
+
+	return anonymized, nil
+}
@@ -0,0 +1,202 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	bootstrapMocks "github.com/edgexfoundry/go-mod-bootstrap/v3/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createMockAnonymizationSecretProvider(salt string, secretError error) *bootstrapMocks.SecretProvider {
+	mockSecretProvider := &bootstrapMocks.SecretProvider{}
+	mockSecretProvider.On("GetSecret", anonymizationSecretName, anonymizationSalt).
+		Return(map[string]string{anonymizationSalt: salt}, secretError)
+	return mockSecretProvider
+}
+
+func TestHttpController_GetAnonymizationProfile(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Salt          string
+		SecretError   error
+		Settings      map[string]string
+		ExpectedShift time.Duration
+		ExpectedTags  int
+		ExpectedError bool
+	}{
+		{"Defaults", "pepper", nil, map[string]string{}, 0, 0, false},
+		{"Configured", "pepper", nil, map[string]string{
+			AnonymizeMaxTimeShiftAppSetting: "24h",
+			AnonymizeTagPatternsAppSetting:  "^site$, owner.*",
+		}, 24 * time.Hour, 2, false},
+		{"No secret", "", errors.New("not found"), map[string]string{}, 0, 0, true},
+		{"Empty salt", "", nil, map[string]string{}, 0, 0, true},
+		{"Bad time shift", "pepper", nil, map[string]string{AnonymizeMaxTimeShiftAppSetting: "-1h"}, 0, 0, true},
+		{"Bad tag pattern", "pepper", nil, map[string]string{AnonymizeTagPatternsAppSetting: "("}, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, _, mockSdk := createTargetAndMocks()
+			mockSdk.ExpectedCalls = nil
+			mockSdk.On("ApplicationSettings").Return(test.Settings)
+			mockSdk.On("SecretProvider").Return(createMockAnonymizationSecretProvider(test.Salt, test.SecretError))
+
+			actual, err := target.getAnonymizationProfile()
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, []byte(test.Salt), actual.salt)
+			assert.Equal(t, test.ExpectedShift, actual.maxTimeShift)
+			assert.Len(t, actual.tagPatterns, test.ExpectedTags)
+		})
+	}
+}
+
+func TestAnonymizationProfile_RandomTimeShift(t *testing.T) {
+	profile := &anonymizationProfile{}
+	shift, err := profile.randomTimeShift()
+	require.NoError(t, err)
+	assert.Zero(t, shift)
+
+	profile.maxTimeShift = time.Hour
+	for range 100 {
+		shift, err = profile.randomTimeShift()
+		require.NoError(t, err)
+		assert.LessOrEqual(t, shift, time.Hour)
+		assert.GreaterOrEqual(t, shift, -time.Hour)
+	}
+}
+
+func TestAnonymizationProfile_Anonymize(t *testing.T) {
+	profile := &anonymizationProfile{salt: []byte("pepper"), tagPatterns: []*regexp.Regexp{regexp.MustCompile("^site$")}}
+
+	event := coreDtos.NewEvent("profile", "boiler-room-3", "source")
+	require.NoError(t, event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21)))
+	event.Tags = coreDtos.Tags{"site": "north", "line": 2}
+	event.Readings[0].Tags = coreDtos.Tags{"site": "north"}
+
+	device := coreDtos.Device{
+		Name:        "boiler-room-3",
+		Description: "Boiler in room 3",
+		Location:    "Building A",
+		ServiceName: "device-modbus",
+		ProfileName: "profile",
+		Protocols:   map[string]coreDtos.ProtocolProperties{"modbus-tcp": {"Address": "10.0.0.3"}},
+		Tags:        map[string]any{"site": "north"},
+	}
+
+	data := &dtos.RecordedData{
+		Name:           "golden",
+		RecordedEvents: []coreDtos.Event{event},
+		Devices:        []coreDtos.Device{device},
+		Envelopes:      map[string]dtos.EnvelopeMetadata{event.Id: {ReceivedTopic: "edgex/events/device/device-modbus/profile/boiler-room-3/source"}},
+		DeadLetters:    []dtos.DeadLetter{{Payload: []byte("boiler-room-3")}},
+		Metadata:       &dtos.RecordingMetadata{Hostname: "plant-7", EdgeXVersion: "v3.2.0", StartedAt: 1000},
+	}
+
+	actual, err := profile.anonymize(data, time.Second)
+	require.NoError(t, err)
+
+	// The device name is hashed the same everywhere, so the Events still reference their Device
+	anonymizedName := profile.deviceName("boiler-room-3")
+	assert.True(t, strings.HasPrefix(anonymizedName, anonymizedDevicePrefix))
+	assert.NotContains(t, anonymizedName, "boiler")
+	assert.Equal(t, anonymizedName, actual.RecordedEvents[0].DeviceName)
+	assert.Equal(t, anonymizedName, actual.RecordedEvents[0].Readings[0].DeviceName)
+	assert.Equal(t, anonymizedName, actual.Devices[0].Name)
+	assert.NotEqual(t, anonymizedName, (&anonymizationProfile{salt: []byte("salt")}).deviceName("boiler-room-3"))
+
+	assert.Equal(t, event.Origin+int64(time.Second), actual.RecordedEvents[0].Origin)
+	assert.Equal(t, event.Readings[0].Origin+int64(time.Second), actual.RecordedEvents[0].Readings[0].Origin)
+	assert.Equal(t, coreDtos.Tags{"line": 2}, actual.RecordedEvents[0].Tags)
+	assert.Nil(t, actual.RecordedEvents[0].Readings[0].Tags)
+
+	assert.Empty(t, actual.Devices[0].Description)
+	assert.Nil(t, actual.Devices[0].Location)
+	assert.Empty(t, actual.Devices[0].Protocols)
+	assert.Nil(t, actual.Devices[0].Tags)
+	assert.Equal(t, "device-modbus", actual.Devices[0].ServiceName)
+
+	assert.Nil(t, actual.Envelopes)
+	assert.Nil(t, actual.DeadLetters)
+	require.NotNil(t, actual.Metadata)
+	assert.Empty(t, actual.Metadata.Hostname)
+	assert.Equal(t, "v3.2.0", actual.Metadata.EdgeXVersion)
+	assert.Equal(t, int64(1000)+int64(time.Second), actual.Metadata.StartedAt)
+
+	// The recorded data isn't modified
+	assert.Equal(t, "boiler-room-3", data.RecordedEvents[0].DeviceName)
+	assert.Equal(t, coreDtos.Tags{"site": "north"}, data.RecordedEvents[0].Readings[0].Tags)
+	assert.Equal(t, "10.0.0.3", data.Devices[0].Protocols["modbus-tcp"]["Address"])
+
+	_, err = profile.anonymize(&dtos.RecordedData{Messages: []dtos.OpaqueMessage{{Payload: []byte("raw")}}}, 0)
+	require.ErrorIs(t, err, anonymizeOpaqueError)
+}
+
+func TestHttpController_ExportRecordedData_Anonymized(t *testing.T) {
+	event := coreDtos.NewEvent("profile", "boiler-room-3", "source")
+	require.NoError(t, event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21)))
+
+	tests := []struct {
+		Name           string
+		Query          string
+		Data           *dtos.RecordedData
+		Salt           string
+		ExpectedStatus int
+	}{
+		{"Anonymized", "?anonymize=true", &dtos.RecordedData{RecordedEvents: []coreDtos.Event{event}}, "pepper", http.StatusOK},
+		{"Bad parameter", "?anonymize=maybe", &dtos.RecordedData{}, "pepper", http.StatusBadRequest},
+		{"Opaque", "?anonymize=true", &dtos.RecordedData{Messages: []dtos.OpaqueMessage{{}}}, "pepper", http.StatusBadRequest},
+		{"No salt", "?anonymize=true", &dtos.RecordedData{}, "", http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, mockSdk := createTargetAndMocks()
+			mockDataManager.On("ExportRecordedData").Return(test.Data, nil)
+			mockSdk.On("SecretProvider").Return(createMockAnonymizationSecretProvider(test.Salt, nil))
+
+			req, err := http.NewRequest(http.MethodGet, dataRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData)).ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus == http.StatusOK {
+				assert.NotContains(t, testRecorder.Body.String(), "boiler-room-3")
+				assert.Contains(t, testRecorder.Body.String(), anonymizedDevicePrefix)
+			}
+		})
+	}
+}
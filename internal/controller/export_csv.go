@@ -0,0 +1,97 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+)
+
+const (
+	csvFormat    = "csv"
+	csvExtension = ".csv"
+
+	// csvLabelSeparator separates the labels of a row covered by more than one labeled time range
+	csvLabelSeparator = ";"
+)
+
+// csvHeader is the header row of a CSV export
+var csvHeader = []string{"origin", "deviceName", "profileName", "sourceName", "resourceName", "valueType", "value",
+	"units", "label"}
+
+// toCSV converts the recorded Events to CSV with a row per Reading, so a capture can be used directly as a
+// supervised-learning dataset. The label column holds the labels of the annotations labeling a time range which
+// includes the Reading's Event, in annotation order, so the Readings can be labeled, e.g. as fault or normal, by
+// annotating the recording. Binary values are left out and object values are encoded as JSON.
+func toCSV(data *dtos.RecordedData) ([]byte, error) {
+	var ranges []dtos.Annotation
+	for _, annotation := range data.Annotations {
+		if annotation.End != 0 {
+			ranges = append(ranges, annotation)
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	writer := csv.NewWriter(buffer)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for _, event := range data.RecordedEvents {
+		var labels []string
+		for _, annotation := range ranges {
+			if event.Origin >= annotation.Timestamp && event.Origin <= annotation.End && !slices.Contains(labels, annotation.Label) {
+				labels = append(labels, annotation.Label)
+			}
+		}
+		label := strings.Join(labels, csvLabelSeparator)
+
+		for _, reading := range event.Readings {
+			value := reading.Value
+			switch reading.ValueType {
+			case common.ValueTypeBinary:
+				value = ""
+			case common.ValueTypeObject:
+				encoded, err := json.Marshal(reading.ObjectValue)
+				if err != nil {
+					return nil, err
+				}
+				value = string(encoded)
+			}
+
+			row := []string{strconv.FormatInt(event.Origin, 10), event.DeviceName, event.ProfileName, event.SourceName,
+				reading.ResourceName, reading.ValueType, value, reading.Units, label}
+			if err := writer.Write(row); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
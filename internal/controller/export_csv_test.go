@@ -0,0 +1,96 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func csvTestData(t *testing.T) *dtos.RecordedData {
+	var events []coreDtos.Event
+	for origin := int64(1); origin <= 3; origin++ {
+		event := coreDtos.NewEvent("profile", "pump", "source")
+		event.Origin = origin * 1000
+		require.NoError(t, event.AddSimpleReading("Pressure", common.ValueTypeFloat64, float64(origin)))
+		events = append(events, event)
+	}
+	events[0].AddObjectReading("Status", map[string]any{"ok": true})
+	events[0].Readings[1].Units = "n/a"
+	events[2].AddBinaryReading("Image", []byte{1, 2, 3}, "image/png")
+
+	return &dtos.RecordedData{
+		Name:           "pump",
+		RecordedEvents: events,
+		Annotations: []dtos.Annotation{
+			{Label: "normal", Timestamp: 0, End: 1500},
+			{Label: "fault", Timestamp: 2000, End: 3000},
+			{Label: "inspected", Timestamp: 2500, End: 3500},
+			{Label: "point", Timestamp: 2000},
+		},
+	}
+}
+
+func TestToCSV(t *testing.T) {
+	encoded, err := toCSV(csvTestData(t))
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(strings.NewReader(string(encoded))).ReadAll()
+	require.NoError(t, err)
+
+	expected := [][]string{
+		csvHeader,
+		{"1000", "pump", "profile", "source", "Pressure", common.ValueTypeFloat64, "1.000000e+00", "", "normal"},
+		{"1000", "pump", "profile", "source", "Status", common.ValueTypeObject, `{"ok":true}`, "n/a", "normal"},
+		{"2000", "pump", "profile", "source", "Pressure", common.ValueTypeFloat64, "2.000000e+00", "", "fault"},
+		{"3000", "pump", "profile", "source", "Pressure", common.ValueTypeFloat64, "3.000000e+00", "", "fault;inspected"},
+		{"3000", "pump", "profile", "source", "Image", common.ValueTypeBinary, "", "", "fault;inspected"},
+	}
+	assert.Equal(t, expected, rows)
+
+	encoded, err = toCSV(&dtos.RecordedData{})
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(csvHeader, ",")+"\n", string(encoded))
+}
+
+func TestHttpController_ExportRecordedData_CSV(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("ExportRecordedData").Return(csvTestData(t), nil)
+
+	req, err := http.NewRequest(http.MethodGet, dataRoute+"?format=csv", nil)
+	require.NoError(t, err)
+
+	testRecorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData)).ServeHTTP(testRecorder, req)
+
+	require.Equal(t, http.StatusOK, testRecorder.Code)
+	assert.Equal(t, "text/csv", testRecorder.Header().Get("Content-Type"))
+	assert.Contains(t, testRecorder.Header().Get("Content-Disposition"), `"pump.csv"`)
+
+	rows, err := csv.NewReader(testRecorder.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, rows, 6)
+}
@@ -0,0 +1,359 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+var deltaBaselineIsDelta = errors.New("baseline must not itself be stored as a delta")
+var deltaBaselineChanged = errors.New("baseline has changed since the delta was stored")
+var deltaBaselineMismatch = errors.New("delta references data missing from the baseline")
+
+// readBaseline reads and decodes the baseline recording file, which must be within one of the directories
+// allow-listed by the ImportPaths App Setting, returning it with the digest of its uncompressed data. The baseline is
+// verified if it is signed and is subject to the same limits on its uncompressed data as an import.
+func (c *httpController) readBaseline(path string, limits importLimits) (*dtos.RecordedData, string, error) {
+	file, signature, err := c.openLocalImportFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	body := bufio.NewReader(file)
+	reader := io.NopCloser(body)
+	if _, codec, compressed := sniffCompression(body); compressed {
+		reader, err = codec.newReader(body)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %v", failedToUncompressData, err)
+		}
+		defer reader.Close()
+	}
+
+	data, err := io.ReadAll(limitImportReader(reader, limits.maxBytes, "uncompressed baseline"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(signature) > 0 {
+		if err := c.verifyData(data, signature); err != nil {
+			return nil, "", fmt.Errorf("%s: %v", failedVerifyingData, err)
+		}
+	}
+
+	payload := bufio.NewReader(bytes.NewReader(data))
+	format, err := detectPayloadFormat(payload)
+	if err != nil {
+		return nil, "", err
+	}
+
+	baseline, err := decodeImportedData(payload, format, int(limits.maxEvents))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if baseline.Delta != nil {
+		return nil, "", deltaBaselineIsDelta
+	}
+
+	digest := sha256.Sum256(data)
+	return baseline, hex.EncodeToString(digest[:]), nil
+}
+
+// eventSourceKey identifies the device and source of an Event, whose Events are similar from one capture to the next
+func eventSourceKey(event coreDtos.Event) string {
+	return event.DeviceName + "/" + event.ProfileName + "/" + event.SourceName
+}
+
+// deltaRecordedData returns the recorded data stored as its differences against the baseline. Each Event is stored
+// against the baseline Event in the same position among the Events from its device and source, when they are
+// similar enough that it can be reconstructed exactly, otherwise it is stored in full.
+func deltaRecordedData(data *dtos.RecordedData, baseline *dtos.RecordedData, baselinePath string, digest string) (*dtos.RecordedData, error) {
+	baseEvents := make(map[string][]int)
+	for index, event := range baseline.RecordedEvents {
+		key := eventSourceKey(event)
+		baseEvents[key] = append(baseEvents[key], index)
+	}
+
+	delta := &dtos.RecordedDataDelta{
+		Baseline:       baselinePath,
+		BaselineDigest: digest,
+		Events:         make([]dtos.EventDelta, len(data.RecordedEvents)),
+	}
+
+	used := make(map[string]int)
+	for index, event := range data.RecordedEvents {
+		key := eventSourceKey(event)
+		position := used[key]
+		used[key]++
+
+		if position < len(baseEvents[key]) {
+			baseIndex := baseEvents[key][position]
+			eventDelta, ok, err := deltaEvent(event, baseline.RecordedEvents[baseIndex], baseIndex)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				delta.Events[index] = eventDelta
+				continue
+			}
+		}
+
+		delta.Events[index] = dtos.EventDelta{Event: &event}
+	}
+
+	var err error
+	delta.Devices, err = deltaNamed(data.Devices, baseline.Devices, func(device coreDtos.Device) string { return device.Name },
+		func(name string, device *coreDtos.Device) dtos.DeviceDelta {
+			return dtos.DeviceDelta{Name: name, Device: device}
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	delta.Profiles, err = deltaNamed(data.Profiles, baseline.Profiles, func(profile coreDtos.DeviceProfile) string { return profile.Name },
+		func(name string, profile *coreDtos.DeviceProfile) dtos.ProfileDelta {
+			return dtos.ProfileDelta{Name: name, Profile: profile}
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	stored := *data
+	stored.RecordedEvents = nil
+	stored.Devices = nil
+	stored.Profiles = nil
+	stored.Delta = delta
+	return &stored, nil
+}
+
+// deltaEvent returns the Event's differences against the baseline Event. False is returned if the Event can't be
+// reconstructed exactly from them, such as when its Readings differ in more than their values and tags.
+func deltaEvent(event coreDtos.Event, base coreDtos.Event, baseIndex int) (dtos.EventDelta, bool, error) {
+	if len(event.Readings) != len(base.Readings) {
+		return dtos.EventDelta{}, false, nil
+	}
+
+	delta := dtos.EventDelta{
+		Base:     &baseIndex,
+		Id:       event.Id,
+		Origin:   event.Origin,
+		Readings: make([]dtos.ReadingDelta, len(event.Readings)),
+	}
+	if !reflect.DeepEqual(event.Tags, base.Tags) {
+		delta.Tags = event.Tags
+	}
+
+	for index, reading := range event.Readings {
+		baseReading := base.Readings[index]
+		readingDelta := dtos.ReadingDelta{Id: reading.Id, OriginOffset: reading.Origin - event.Origin}
+		if reading.Value != baseReading.Value {
+			value := reading.Value
+			readingDelta.Value = &value
+		}
+		if !bytes.Equal(reading.BinaryValue, baseReading.BinaryValue) {
+			readingDelta.BinaryValue = reading.BinaryValue
+		}
+		if !reflect.DeepEqual(reading.ObjectValue, baseReading.ObjectValue) {
+			readingDelta.ObjectValue = reading.ObjectValue
+		}
+		if !reflect.DeepEqual(reading.Tags, baseReading.Tags) {
+			readingDelta.Tags = reading.Tags
+		}
+		delta.Readings[index] = readingDelta
+	}
+
+	// Anything the differences don't hold, such as the units or a value removed, leaves the reconstructed Event
+	// different, so the Event is only stored against the baseline Event when it reconstructs exactly
+	reconstructed, err := applyEventDelta(delta, base)
+	if err != nil {
+		return dtos.EventDelta{}, false, err
+	}
+
+	same, err := sameJSON(event, reconstructed)
+	if err != nil {
+		return dtos.EventDelta{}, false, err
+	}
+
+	return delta, same, nil
+}
+
+// applyEventDelta reconstructs the Event from its differences against the baseline Event
+func applyEventDelta(delta dtos.EventDelta, base coreDtos.Event) (coreDtos.Event, error) {
+	if len(delta.Readings) != len(base.Readings) {
+		return coreDtos.Event{}, fmt.Errorf("%w: Event %s has %d readings, the baseline Event has %d",
+			deltaBaselineMismatch, delta.Id, len(delta.Readings), len(base.Readings))
+	}
+
+	event := base
+	event.Id = delta.Id
+	event.Origin = delta.Origin
+	event.Tags = maps.Clone(base.Tags)
+	if delta.Tags != nil {
+		event.Tags = delta.Tags
+	}
+
+	event.Readings = make([]coreDtos.BaseReading, len(base.Readings))
+	for index, readingDelta := range delta.Readings {
+		reading := base.Readings[index]
+		reading.Id = readingDelta.Id
+		reading.Origin = delta.Origin + readingDelta.OriginOffset
+		reading.Tags = maps.Clone(reading.Tags)
+		if readingDelta.Value != nil {
+			reading.Value = *readingDelta.Value
+		}
+		if readingDelta.BinaryValue != nil {
+			reading.BinaryValue = readingDelta.BinaryValue
+		}
+		if readingDelta.ObjectValue != nil {
+			reading.ObjectValue = readingDelta.ObjectValue
+		}
+		if readingDelta.Tags != nil {
+			reading.Tags = readingDelta.Tags
+		}
+		event.Readings[index] = reading
+	}
+
+	return event, nil
+}
+
+// deltaNamed returns each item by name when it is unchanged from the baseline's item of the same name, otherwise in
+// full
+func deltaNamed[T any, D any](items []T, baseline []T, name func(T) string, delta func(string, *T) D) ([]D, error) {
+	baseItems := make(map[string]T, len(baseline))
+	for _, item := range baseline {
+		baseItems[name(item)] = item
+	}
+
+	deltas := make([]D, len(items))
+	for index, item := range items {
+		if baseItem, found := baseItems[name(item)]; found {
+			same, err := sameJSON(item, baseItem)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				deltas[index] = delta(name(item), nil)
+				continue
+			}
+		}
+
+		deltas[index] = delta(name(item), &item)
+	}
+
+	return deltas, nil
+}
+
+// applyNamed reconstructs the items from those stored by name, which are taken from the baseline, and those stored in
+// full
+func applyNamed[T any, D any](deltas []D, baseline []T, name func(T) string, item func(D) (string, *T)) ([]T, error) {
+	baseItems := make(map[string]T, len(baseline))
+	for _, baseItem := range baseline {
+		baseItems[name(baseItem)] = baseItem
+	}
+
+	items := make([]T, len(deltas))
+	for index, delta := range deltas {
+		itemName, full := item(delta)
+		if full != nil {
+			items[index] = *full
+			continue
+		}
+
+		baseItem, found := baseItems[itemName]
+		if !found {
+			return nil, fmt.Errorf("%w: %s", deltaBaselineMismatch, itemName)
+		}
+		items[index] = baseItem
+	}
+
+	return items, nil
+}
+
+// sameJSON returns true if the values marshal to the same JSON, which is how they are stored
+func sameJSON(value any, other any) (bool, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	otherEncoded, err := json.Marshal(other)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(encoded, otherEncoded), nil
+}
+
+// resolveRecordedDataDelta reconstructs the Events, Devices and Profiles of recorded data stored as a delta from its
+// baseline, which must be unchanged since the delta was stored
+func (c *httpController) resolveRecordedDataDelta(data *dtos.RecordedData, limits importLimits) error {
+	delta := data.Delta
+	baseline, digest, err := c.readBaseline(delta.Baseline, limits)
+	if err != nil {
+		return fmt.Errorf("baseline %s: %w", delta.Baseline, err)
+	}
+
+	if digest != delta.BaselineDigest {
+		return fmt.Errorf("%w: %s", deltaBaselineChanged, delta.Baseline)
+	}
+
+	events := make([]coreDtos.Event, len(delta.Events))
+	for index, eventDelta := range delta.Events {
+		switch {
+		case eventDelta.Event != nil:
+			events[index] = *eventDelta.Event
+		case eventDelta.Base == nil || *eventDelta.Base < 0 || *eventDelta.Base >= len(baseline.RecordedEvents):
+			return fmt.Errorf("%w: Event %s has no baseline Event", deltaBaselineMismatch, eventDelta.Id)
+		default:
+			events[index], err = applyEventDelta(eventDelta, baseline.RecordedEvents[*eventDelta.Base])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	devices, err := applyNamed(delta.Devices, baseline.Devices, func(device coreDtos.Device) string { return device.Name },
+		func(delta dtos.DeviceDelta) (string, *coreDtos.Device) { return delta.Name, delta.Device })
+	if err != nil {
+		return err
+	}
+
+	profiles, err := applyNamed(delta.Profiles, baseline.Profiles, func(profile coreDtos.DeviceProfile) string { return profile.Name },
+		func(delta dtos.ProfileDelta) (string, *coreDtos.DeviceProfile) { return delta.Name, delta.Profile })
+	if err != nil {
+		return err
+	}
+
+	data.RecordedEvents = events
+	data.Devices = devices
+	data.Profiles = profiles
+	data.Delta = nil
+	return nil
+}
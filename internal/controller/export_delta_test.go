@@ -0,0 +1,243 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// deltaTestData returns a capture of the same devices, with Ids, origins and values which depend on the capture
+func deltaTestData(capture int) *dtos.RecordedData {
+	data := &dtos.RecordedData{
+		Name: fmt.Sprintf("line-%d", capture),
+		Devices: []coreDtos.Device{
+			{Name: "device-1", ProfileName: "profile-1", ServiceName: "service-1"},
+			{Name: "device-2", ProfileName: "profile-1", ServiceName: "service-1"},
+		},
+		Profiles: []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "profile-1"}}},
+	}
+
+	for index := 0; index < 6; index++ {
+		origin := int64(capture*1000 + index)
+		event := coreDtos.NewEvent("profile-1", fmt.Sprintf("device-%d", index%2+1), "temperature")
+		event.Id = fmt.Sprintf("event-%d-%d", capture, index)
+		event.Origin = origin
+		_ = event.AddSimpleReading("temperature", common.ValueTypeInt32, int32(20+index%3))
+		_ = event.AddSimpleReading("humidity", common.ValueTypeInt32, int32(capture+index))
+		for readingIndex := range event.Readings {
+			event.Readings[readingIndex].Id = fmt.Sprintf("reading-%d-%d-%d", capture, index, readingIndex)
+			event.Readings[readingIndex].Origin = origin
+		}
+		data.RecordedEvents = append(data.RecordedEvents, event)
+	}
+
+	return data
+}
+
+func TestDeltaRecordedData(t *testing.T) {
+	importDir := t.TempDir()
+	baselinePath := filepath.Join(importDir, "line-1.json")
+	baselineJSON := marshal(t, deltaTestData(1))
+	require.NoError(t, os.WriteFile(baselinePath, baselineJSON, 0640))
+
+	mockSdk := &appMocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ImportPathsAppSetting: importDir})
+	target := New(nil, nil, nil, mockSdk).(*httpController)
+
+	limits, err := target.getImportLimits()
+	require.NoError(t, err)
+	baseline, digest, err := target.readBaseline(baselinePath, limits)
+	require.NoError(t, err)
+	require.Len(t, baseline.RecordedEvents, 6)
+
+	data := deltaTestData(2)
+	// Units aren't held by the differences, so the Event is stored in full
+	data.RecordedEvents[1].Readings[0].Units = "C"
+	data.RecordedEvents[2].Tags = coreDtos.Tags{"site": "north"}
+	data.RecordedEvents[3].Readings[1].Origin += 5
+	// The seventh Event from device-1 has no baseline Event to be stored against
+	extra := data.RecordedEvents[0]
+	extra.Id = "event-2-6"
+	data.RecordedEvents = append(data.RecordedEvents, extra)
+	data.Devices[1].Labels = []string{"moved"}
+	data.Profiles = append(data.Profiles, coreDtos.DeviceProfile{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "profile-2"}})
+	expected := marshal(t, data)
+
+	stored, err := deltaRecordedData(data, baseline, baselinePath, digest)
+	require.NoError(t, err)
+	assert.Equal(t, "line-2", stored.Name)
+	assert.Empty(t, stored.RecordedEvents)
+	require.NotNil(t, stored.Delta)
+	assert.Equal(t, baselinePath, stored.Delta.Baseline)
+
+	require.Len(t, stored.Delta.Events, 7)
+	for index, eventDelta := range stored.Delta.Events {
+		switch index {
+		case 1, 6:
+			assert.NotNil(t, eventDelta.Event, "Event %d stored in full", index)
+			assert.Nil(t, eventDelta.Base)
+		default:
+			assert.Nil(t, eventDelta.Event, "Event %d stored against the baseline", index)
+			require.NotNil(t, eventDelta.Base)
+			assert.Equal(t, index, *eventDelta.Base)
+		}
+	}
+	// The temperatures repeat from one capture to the next, so only the humidity values are stored
+	assert.Nil(t, stored.Delta.Events[0].Readings[0].Value)
+	assert.Equal(t, "2", *stored.Delta.Events[0].Readings[1].Value)
+	assert.Equal(t, coreDtos.Tags{"site": "north"}, stored.Delta.Events[2].Tags)
+	assert.Equal(t, int64(5), stored.Delta.Events[3].Readings[1].OriginOffset)
+
+	assert.Equal(t, []dtos.DeviceDelta{{Name: "device-1"}, {Name: "device-2", Device: &data.Devices[1]}}, stored.Delta.Devices)
+	assert.Equal(t, []dtos.ProfileDelta{{Name: "profile-1"}, {Name: "profile-2", Profile: &data.Profiles[1]}}, stored.Delta.Profiles)
+
+	storedJSON := marshal(t, stored)
+	assert.Less(t, len(storedJSON), len(expected))
+
+	// The data reconstructs exactly once stored and read back
+	imported := &dtos.RecordedData{}
+	require.NoError(t, json.Unmarshal(storedJSON, imported))
+	require.NoError(t, target.resolveRecordedDataDelta(imported, limits))
+	assert.Nil(t, imported.Delta)
+	assert.JSONEq(t, string(expected), string(marshal(t, imported)))
+
+	// The delta isn't reconstructed from a baseline which has changed
+	imported = &dtos.RecordedData{}
+	require.NoError(t, json.Unmarshal(storedJSON, imported))
+	require.NoError(t, os.WriteFile(baselinePath, marshal(t, deltaTestData(3)), 0640))
+	require.ErrorIs(t, target.resolveRecordedDataDelta(imported, limits), deltaBaselineChanged)
+
+	// Nor is a delta used as a baseline
+	require.NoError(t, os.WriteFile(baselinePath, storedJSON, 0640))
+	_, _, err = target.readBaseline(baselinePath, limits)
+	require.ErrorIs(t, err, deltaBaselineIsDelta)
+}
+
+func TestResolveRecordedDataDelta_Mismatch(t *testing.T) {
+	importDir := t.TempDir()
+	baselinePath := filepath.Join(importDir, "line-1.json")
+	require.NoError(t, os.WriteFile(baselinePath, marshal(t, deltaTestData(1)), 0640))
+
+	mockSdk := &appMocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ImportPathsAppSetting: importDir})
+	target := New(nil, nil, nil, mockSdk).(*httpController)
+
+	limits, err := target.getImportLimits()
+	require.NoError(t, err)
+	baseline, digest, err := target.readBaseline(baselinePath, limits)
+	require.NoError(t, err)
+
+	outOfRange := len(baseline.RecordedEvents)
+	tests := []struct {
+		Name  string
+		Delta dtos.RecordedDataDelta
+	}{
+		{"Base out of range", dtos.RecordedDataDelta{Events: []dtos.EventDelta{{Base: &outOfRange}}}},
+		{"No base", dtos.RecordedDataDelta{Events: []dtos.EventDelta{{Id: "event"}}}},
+		{"Readings differ", dtos.RecordedDataDelta{Events: []dtos.EventDelta{{Base: new(int)}}}},
+		{"Unknown device", dtos.RecordedDataDelta{Devices: []dtos.DeviceDelta{{Name: "device-3"}}}},
+		{"Unknown profile", dtos.RecordedDataDelta{Profiles: []dtos.ProfileDelta{{Name: "profile-3"}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			test.Delta.Baseline = baselinePath
+			test.Delta.BaselineDigest = digest
+			err := target.resolveRecordedDataDelta(&dtos.RecordedData{Delta: &test.Delta}, limits)
+			require.ErrorIs(t, err, deltaBaselineMismatch)
+		})
+	}
+}
+
+func TestHttpController_ExportRecordedDataToPath_Delta(t *testing.T) {
+	usbDir := t.TempDir()
+	baselinePath := filepath.Join(usbDir, "line-1.json.gzip")
+	compressed, err := codecs[gzipCompression].compress(marshal(t, deltaTestData(1)))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(baselinePath, compressed, 0640))
+
+	data := deltaTestData(2)
+	mockDataManager := &mocks.DataManager{}
+	mockDataManager.On("ExportRecordedData").Return(data, nil)
+	mockDataManager.On("ImportRecordedData", mock.Anything, true, false).Return(nil)
+	mockSdk := &appMocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ExportPathsAppSetting: usbDir, ImportPathsAppSetting: usbDir})
+	target := New(mockDataManager, nil, nil, mockSdk).(*httpController)
+
+	exportToPath := func(request dtos.LocalExportRequest) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedDataToPath)).ServeHTTP(recorder,
+			httptest.NewRequest(http.MethodPost, exportRoute, bytes.NewReader(marshal(t, request))))
+		return recorder
+	}
+
+	recorder := exportToPath(dtos.LocalExportRequest{Path: usbDir, Baseline: baselinePath})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	response := dtos.LocalExportResponse{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Less(t, response.Size, len(marshal(t, data)))
+
+	stored := dtos.RecordedData{}
+	storedJSON, err := os.ReadFile(response.Path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(storedJSON, &stored))
+	require.NotNil(t, stored.Delta)
+	assert.Empty(t, stored.RecordedEvents)
+
+	// The delta is reconstructed when imported
+	recorder = httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData)).ServeHTTP(recorder,
+		httptest.NewRequest(http.MethodPost, dataRoute, bytes.NewReader(storedJSON)))
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+	imported := mockDataManager.Calls[len(mockDataManager.Calls)-1].Arguments.Get(0).(*dtos.RecordedData)
+	assert.JSONEq(t, string(marshal(t, data)), string(marshal(t, imported)))
+
+	recorder = exportToPath(dtos.LocalExportRequest{Path: usbDir, Baseline: "/etc/passwd"})
+	require.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+	mockDataManager.AssertNumberOfCalls(t, "ExportRecordedData", 1)
+
+	recorder = exportToPath(dtos.LocalExportRequest{Path: usbDir, Baseline: filepath.Join(usbDir, "missing.json")})
+	require.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+
+	// A delta whose baseline has changed isn't imported
+	require.NoError(t, os.WriteFile(baselinePath, marshal(t, deltaTestData(3)), 0640))
+	recorder = httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData)).ServeHTTP(recorder,
+		httptest.NewRequest(http.MethodPost, dataRoute, bytes.NewReader(storedJSON)))
+	require.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+	assert.Contains(t, recorder.Body.String(), deltaBaselineChanged.Error())
+}
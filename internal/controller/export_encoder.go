@@ -0,0 +1,220 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"hash/adler32"
+	"hash/crc32"
+	"runtime"
+	"sync"
+
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+const (
+	// exportEventChunkSize is the number of Events each worker marshals at a time
+	exportEventChunkSize = 1000
+	// exportCompressChunkSize is the number of bytes each worker compresses at a time
+	exportCompressChunkSize = 1 << 20
+	// deflateDictionarySize is the deflate window size, so is the most of the previous chunk used as a dictionary
+	deflateDictionarySize = 32 << 10
+)
+
+// exportWorkers is the number of parallel workers used to encode an export
+var exportWorkers = runtime.GOMAXPROCS(0)
+
+// parallelEach calls the function for each index from 0 to count using up to exportWorkers goroutines, returning the
+// first error encountered.
+func parallelEach(count int, fn func(index int) error) error {
+	indexes := make(chan int)
+	errs := make([]error, count)
+
+	var wait sync.WaitGroup
+	for range min(exportWorkers, count) {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for index := range indexes {
+				errs[index] = fn(index)
+			}
+		}()
+	}
+
+	for index := range count {
+		indexes <- index
+	}
+	close(indexes)
+	wait.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// marshalRecordedData marshals the recorded data for export, with the Events of a readings-only recording compacted
+// to their reading tuples. See compactReadingsOnly and marshalRecordedEvents.
+func marshalRecordedData(data *dtos.RecordedData) ([]byte, error) {
+	return marshalRecordedEvents(compactReadingsOnly(data))
+}
+
+// compactReadingsOnly returns the recorded data of a readings-only recording with its Events replaced by their reading
+// tuples, otherwise the recorded data unchanged. Events which weren't stripped down to their readings, e.g. appended
+// from another recording, are never compacted, since they would lose their Ids. The recorded data isn't modified.
+func compactReadingsOnly(data *dtos.RecordedData) *dtos.RecordedData {
+	if data.Metadata == nil || !data.Metadata.Request.ReadingsOnly {
+		return data
+	}
+
+	compact, ok := utils.CompactEvents(data.RecordedEvents)
+	if !ok {
+		return data
+	}
+
+	compacted := *data
+	compacted.RecordedEvents = nil
+	compacted.ReadingsOnlyEvents = compact
+	return &compacted
+}
+
+// marshalRecordedEvents marshals the recorded data to the same JSON as json.Marshal, with the recorded Events
+// marshaled in chunks by parallel workers and joined back in order.
+func marshalRecordedEvents(data *dtos.RecordedData) ([]byte, error) {
+	chunkCount := (len(data.RecordedEvents) + exportEventChunkSize - 1) / exportEventChunkSize
+	if chunkCount <= 1 || exportWorkers <= 1 {
+		return json.Marshal(data)
+	}
+
+	// Marshal the rest of the data with no Events so the marshaled Events can be spliced in place of the empty list.
+	// The field name can't otherwise appear unescaped since any quotes in string values are escaped.
+	withoutEvents := *data
+	withoutEvents.RecordedEvents = nil
+	outer, err := json.Marshal(withoutEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	placeholder := []byte(`"` + recordedEventsField + `":null`)
+	split := bytes.Index(outer, placeholder)
+	if split < 0 {
+		return json.Marshal(data)
+	}
+	split += len(placeholder) - len("null")
+
+	chunks := make([][]byte, chunkCount)
+	err = parallelEach(chunkCount, func(index int) error {
+		start := index * exportEventChunkSize
+		end := min(start+exportEventChunkSize, len(data.RecordedEvents))
+
+		// Marshaling the slice and trimming its brackets gives the comma separated Events
+		chunk, err := json.Marshal(data.RecordedEvents[start:end])
+		if err != nil {
+			return err
+		}
+
+		chunks[index] = chunk[1 : len(chunk)-1]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	size := len(outer) + chunkCount
+	for _, chunk := range chunks {
+		size += len(chunk)
+	}
+
+	result := make([]byte, 0, size)
+	result = append(result, outer[:split]...)
+	result = append(result, '[')
+	for index, chunk := range chunks {
+		if index > 0 {
+			result = append(result, ',')
+		}
+		result = append(result, chunk...)
+	}
+	result = append(result, ']')
+	result = append(result, outer[split+len("null"):]...)
+
+	return result, nil
+}
+
+// deflateChunks compresses the data in chunks by parallel workers and joins them into a single raw deflate stream.
+// Each chunk uses the end of the previous chunk as its dictionary, so compresses nearly as well as a single stream,
+// and all but the last chunk end with a sync flush so the chunks can be concatenated.
+func deflateChunks(data []byte) ([]byte, error) {
+	chunkCount := max(1, (len(data)+exportCompressChunkSize-1)/exportCompressChunkSize)
+
+	chunks := make([][]byte, chunkCount)
+	err := parallelEach(chunkCount, func(index int) error {
+		start := index * exportCompressChunkSize
+		end := min(start+exportCompressChunkSize, len(data))
+		dictionary := data[max(0, start-deflateDictionarySize):start]
+
+		buffer := &bytes.Buffer{}
+		writer, err := flate.NewWriterDict(buffer, flate.DefaultCompression, dictionary)
+		if err != nil {
+			return err
+		}
+
+		if _, err := writer.Write(data[start:end]); err != nil {
+			return err
+		}
+
+		if index < chunkCount-1 {
+			err = writer.Flush()
+		} else {
+			err = writer.Close()
+		}
+		if err != nil {
+			return err
+		}
+
+		chunks[index] = buffer.Bytes()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.Join(chunks, nil), nil
+}
+
+// gzipFrame wraps the raw deflate stream in a gzip header and trailer
+func gzipFrame(deflated []byte, data []byte) []byte {
+	// Magic number, deflate method, no flags, no modification time, no extra flags and unknown OS
+	framed := []byte{0x1f, 0x8b, 8, 0, 0, 0, 0, 0, 0, 255}
+	framed = append(framed, deflated...)
+	framed = binary.LittleEndian.AppendUint32(framed, crc32.ChecksumIEEE(data))
+	return binary.LittleEndian.AppendUint32(framed, uint32(len(data)))
+}
+
+// zlibFrame wraps the raw deflate stream in a zlib header and trailer
+func zlibFrame(deflated []byte, data []byte) []byte {
+	// Deflate with 32K window and default compression level, with the check bits set
+	framed := []byte{0x78, 0x9c}
+	framed = append(framed, deflated...)
+	return binary.BigEndian.AppendUint32(framed, adler32.Checksum(data))
+}
@@ -0,0 +1,178 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withExportWorkers(t *testing.T, workers int) {
+	original := exportWorkers
+	exportWorkers = workers
+	t.Cleanup(func() { exportWorkers = original })
+}
+
+func TestMarshalRecordedEvents(t *testing.T) {
+	withExportWorkers(t, 4)
+
+	var events []coreDtos.Event
+	for index := range 2*exportEventChunkSize + 10 {
+		event := coreDtos.NewEvent("profile", fmt.Sprintf("device-%d", index%7), "source")
+		_ = event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(index))
+		events = append(events, event)
+	}
+
+	tests := []struct {
+		Name string
+		Data dtos.RecordedData
+	}{
+		{"Many events", dtos.RecordedData{Name: `quoted "recordedEvents":null name`, RecordedEvents: events}},
+		{"Single chunk", dtos.RecordedData{Name: "small", RecordedEvents: events[:3]}},
+		{"No events", dtos.RecordedData{Messages: []dtos.OpaqueMessage{{Payload: []byte("raw")}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			expected, err := json.Marshal(test.Data)
+			require.NoError(t, err)
+
+			actual, err := marshalRecordedEvents(&test.Data)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestMarshalRecordedData_ReadingsOnly(t *testing.T) {
+	var events, stripped []coreDtos.Event
+	for index := range 100 {
+		event := coreDtos.NewEvent("profile", fmt.Sprintf("device-%d", index%7), "source")
+		_ = event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(index))
+		_ = event.AddSimpleReading("Humidity", common.ValueTypeInt32, int32(index/2))
+		_ = event.AddSimpleReading("Pressure", common.ValueTypeFloat64, float64(index)/10)
+		// Device services give the Readings the origin of their Event
+		for readingIndex := range event.Readings {
+			event.Readings[readingIndex].Origin = event.Origin
+		}
+		events = append(events, event)
+
+		// Stripped the same as a readings-only recording captures the Event
+		event.Id = ""
+		event.ApiVersion = ""
+		event.Readings = slices.Clone(event.Readings)
+		for readingIndex := range event.Readings {
+			event.Readings[readingIndex].Id = ""
+		}
+		stripped = append(stripped, event)
+	}
+
+	full, err := marshalRecordedData(&dtos.RecordedData{RecordedEvents: events, Metadata: &dtos.RecordingMetadata{}})
+	require.NoError(t, err)
+
+	data := &dtos.RecordedData{
+		RecordedEvents: stripped,
+		Metadata:       &dtos.RecordingMetadata{Request: dtos.RecordRequest{ReadingsOnly: true}},
+	}
+	compact, err := marshalRecordedData(data)
+	require.NoError(t, err)
+
+	// The reading tuples are less than half the size of the full Events
+	assert.Less(t, 2*len(compact), len(full), "compact %d bytes, full %d bytes", len(compact), len(full))
+	assert.NotContains(t, string(compact), `"id"`)
+	assert.Len(t, data.RecordedEvents, len(stripped), "recorded data was modified")
+
+	// Importing expands the reading tuples back into the stripped Events
+	imported, err := decodeImportedData(bytes.NewReader(compact), jsonImportFormat, len(stripped))
+	require.NoError(t, err)
+	assert.Empty(t, imported.ReadingsOnlyEvents)
+	expected, err := json.Marshal(stripped)
+	require.NoError(t, err)
+	actual, err := json.Marshal(imported.RecordedEvents)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+
+	_, err = decodeImportedData(bytes.NewReader(compact), jsonImportFormat, len(stripped)-1)
+	require.ErrorIs(t, err, importLimitExceeded)
+
+	// Full Events, e.g. appended to a readings-only recording, are never compacted
+	data.RecordedEvents = append(slices.Clone(stripped), events[0])
+	mixed, err := marshalRecordedData(data)
+	require.NoError(t, err)
+	assert.NotContains(t, string(mixed), "readingsOnlyEvents")
+}
+
+func TestCodecs_ParallelRoundTrip(t *testing.T) {
+	withExportWorkers(t, 4)
+
+	buffer := &bytes.Buffer{}
+	for index := 0; buffer.Len() < 3*exportCompressChunkSize+100; index++ {
+		_, _ = fmt.Fprintf(buffer, `{"deviceName":"device-%d","value":"%d"},`, index%13, index)
+	}
+	data := buffer.Bytes()
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			compressed, err := codec.compress(data)
+			require.NoError(t, err)
+			assert.Less(t, len(compressed), len(data)/4)
+
+			// The readers verify the checksums and sizes in the trailers
+			reader, err := codec.newReader(bytes.NewReader(compressed))
+			require.NoError(t, err)
+			defer reader.Close()
+
+			actual, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(data, actual))
+		})
+	}
+}
+
+func TestParallelEach(t *testing.T) {
+	withExportWorkers(t, 3)
+
+	results := make([]int, 10)
+	err := parallelEach(len(results), func(index int) error {
+		results[index] = index * 2
+		return nil
+	})
+	require.NoError(t, err)
+	for index, result := range results {
+		assert.Equal(t, index*2, result)
+	}
+
+	expected := errors.New("failed")
+	err = parallelEach(5, func(index int) error {
+		if index == 3 {
+			return expected
+		}
+		return nil
+	})
+	assert.Equal(t, expected, err)
+}
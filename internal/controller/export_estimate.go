@@ -0,0 +1,216 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// exportEstimateSampleSize is the most Events encoded to estimate the size of an export
+	exportEstimateSampleSize = 1000
+	// estimatedSizeHeader is the header holding the estimated size of the export in the requested format, so HEAD
+	// requests can get it without the body
+	estimatedSizeHeader = "X-Estimated-Size"
+	// nativeFormatName is the name of the native format in the estimate, which has no format query parameter value
+	nativeFormatName = "json"
+
+	failedExportEstimate = "Export size estimate failed"
+)
+
+// exportEstimator encodes the recorded data in one of the formats the export size is estimated for
+type exportEstimator struct {
+	format     string
+	compressed bool
+	encode     func(data *dtos.RecordedData) ([]byte, error)
+}
+
+// exportEstimators lists the formats the export size is estimated for, in the order they are reported. The sizes of
+// the summary and features formats depend on the windows rather than the Event count, so aren't estimated.
+var exportEstimators = []exportEstimator{
+	{format: nativeFormat, compressed: true, encode: marshalRecordedData},
+	{format: arrFormat, encode: func(data *dtos.RecordedData) ([]byte, error) {
+		recording, err := marshalRecordedData(data)
+		if err != nil {
+			return nil, err
+		}
+		return createArchive(newArchiveManifest(data, recording), recording)
+	}},
+	{format: ekuiperFormat, compressed: true, encode: func(data *dtos.RecordedData) ([]byte, error) {
+		return json.Marshal(toEKuiperSamples(data.RecordedEvents))
+	}},
+	{format: csvFormat, compressed: true, encode: toCSV},
+	{format: ndjsonFormat, compressed: true, encode: toNDJSON},
+}
+
+// estimateExportSize returns the estimated size of the recorded data once exported and the time taken to encode it,
+// for the requested format or each format if none is requested, without encoding the full export. A sample of the
+// Events is encoded, along with the data without any Events, and the difference scaled up to all the Events.
+// The estimate is exact, apart from the encode time, when there are no more Events than the sample size.
+func (c *httpController) estimateExportSize(ctx echo.Context) error {
+	query := ctx.Request().URL.Query()
+
+	compression := query.Get("compression")
+	var compressor *codec
+	if compression != noCompression {
+		selected, ok := codecs[compression]
+		if !ok {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: compression format not available: %s", failedExportEstimate, compression))
+		}
+		compressor = &selected
+	}
+
+	estimators := exportEstimators
+	format, formatRequested := query.Get("format"), query.Has("format")
+	if formatRequested {
+		if format == nativeFormatName {
+			format = nativeFormat
+		}
+
+		estimators = nil
+		for _, estimator := range exportEstimators {
+			if estimator.format == format {
+				estimators = append(estimators, estimator)
+			}
+		}
+
+		if len(estimators) == 0 {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: size can't be estimated for format: %s", failedExportEstimate, format))
+		}
+	}
+
+	recordedData, err := c.dataManager.ExportRecordedData()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to export recorded data: %v", err))
+	}
+
+	estimate, err := estimateExportSize(recordedData, estimators, compression, compressor)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedExportEstimate, err))
+	}
+
+	// The header holds the size for the requested format, otherwise for the native format, which is listed first
+	ctx.Response().Header().Set(estimatedSizeHeader, strconv.FormatInt(estimate.Formats[0].Size, 10))
+
+	jsonResponse, err := json.Marshal(estimate)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedExportEstimate, err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// estimateExportSize estimates the size and encode time of the recorded data in each of the formats
+func estimateExportSize(data *dtos.RecordedData, estimators []exportEstimator, compression string,
+	compressor *codec) (*dtos.ExportSizeEstimate, error) {
+	eventCount := len(data.RecordedEvents)
+	sample := sampleEvents(data.RecordedEvents, exportEstimateSampleSize)
+
+	scale := 1.0
+	if len(sample) > 0 {
+		scale = float64(eventCount) / float64(len(sample))
+	}
+
+	sampled := *data
+	sampled.RecordedEvents = sample
+	withoutEvents := *data
+	withoutEvents.RecordedEvents = []coreDtos.Event{}
+
+	estimate := &dtos.ExportSizeEstimate{
+		EventCount:        eventCount,
+		SampledEventCount: len(sample),
+		Compression:       compression,
+	}
+
+	for _, estimator := range estimators {
+		// The archive format is always a zip, so can't be compressed
+		applied := compressor
+		if !estimator.compressed {
+			applied = nil
+		}
+
+		baseSize, baseTime, err := encodeForEstimate(&withoutEvents, estimator, applied)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", formatName(estimator.format), err)
+		}
+
+		sampleSize, sampleTime, err := encodeForEstimate(&sampled, estimator, applied)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", formatName(estimator.format), err)
+		}
+
+		estimate.Formats = append(estimate.Formats, dtos.ExportFormatEstimate{
+			Format:     formatName(estimator.format),
+			Compressed: applied != nil,
+			Size:       baseSize + int64(float64(max(sampleSize-baseSize, 0))*scale),
+			EncodeTime: baseTime + time.Duration(float64(max(sampleTime-baseTime, 0))*scale),
+		})
+	}
+
+	return estimate, nil
+}
+
+// encodeForEstimate encodes the data as the estimator's format, compressed if a compressor is given, returning the
+// size of the result and the time taken
+func encodeForEstimate(data *dtos.RecordedData, estimator exportEstimator, compressor *codec) (int64, time.Duration, error) {
+	started := time.Now()
+
+	encoded, err := estimator.encode(data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if compressor != nil {
+		encoded, err = compressor.compress(encoded)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return int64(len(encoded)), time.Since(started), nil
+}
+
+// sampleEvents returns up to count Events evenly spaced through the Events, or all of them if there are no more
+func sampleEvents(events []coreDtos.Event, count int) []coreDtos.Event {
+	if len(events) <= count {
+		return events
+	}
+
+	sample := make([]coreDtos.Event, count)
+	for index := range sample {
+		sample[index] = events[index*len(events)/count]
+	}
+
+	return sample
+}
+
+// formatName returns the name of the export format used in the estimate
+func formatName(format string) string {
+	if format == nativeFormat {
+		return nativeFormatName
+	}
+
+	return format
+}
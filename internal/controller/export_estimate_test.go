@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func estimateTestData(t *testing.T, count int) *dtos.RecordedData {
+	events := make([]coreDtos.Event, count)
+	for index := range events {
+		events[index] = coreDtos.NewEvent("profile", fmt.Sprintf("device-%d", index%10), "source")
+		events[index].Origin = int64(index+1) * 1000
+		require.NoError(t, events[index].AddSimpleReading("Temperature", common.ValueTypeFloat64, float64(index%100)))
+	}
+
+	return &dtos.RecordedData{
+		Name:           "estimate",
+		RecordedEvents: events,
+		Profiles:       []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "profile"}}},
+		Devices:        []coreDtos.Device{{Name: "device-0", ProfileName: "profile", ServiceName: "service"}},
+	}
+}
+
+func TestEstimateExportSize_Exact(t *testing.T) {
+	data := estimateTestData(t, 50)
+	codec := codecs[gzipCompression]
+
+	estimate, err := estimateExportSize(data, exportEstimators, gzipCompression, &codec)
+	require.NoError(t, err)
+	assert.Equal(t, 50, estimate.EventCount)
+	assert.Equal(t, 50, estimate.SampledEventCount)
+	require.Len(t, estimate.Formats, len(exportEstimators))
+
+	for _, formatEstimate := range estimate.Formats {
+		t.Run(formatEstimate.Format, func(t *testing.T) {
+			var estimator exportEstimator
+			for _, estimator = range exportEstimators {
+				if formatName(estimator.format) == formatEstimate.Format {
+					break
+				}
+			}
+
+			expected, err := estimator.encode(data)
+			require.NoError(t, err)
+			if estimator.compressed {
+				expected, err = codec.compress(expected)
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, estimator.compressed, formatEstimate.Compressed)
+			assert.Equal(t, int64(len(expected)), formatEstimate.Size)
+		})
+	}
+}
+
+func TestEstimateExportSize_Sampled(t *testing.T) {
+	data := estimateTestData(t, 20*exportEstimateSampleSize)
+
+	estimate, err := estimateExportSize(data, exportEstimators, noCompression, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 20*exportEstimateSampleSize, estimate.EventCount)
+	assert.Equal(t, exportEstimateSampleSize, estimate.SampledEventCount)
+
+	for index, estimator := range exportEstimators {
+		encoded, err := estimator.encode(data)
+		require.NoError(t, err)
+
+		actual := float64(len(encoded))
+		assert.InEpsilon(t, actual, float64(estimate.Formats[index].Size), 0.05, "format %s", estimate.Formats[index].Format)
+	}
+}
+
+func TestSampleEvents(t *testing.T) {
+	events := estimateTestData(t, 10).RecordedEvents
+
+	assert.Equal(t, events, sampleEvents(events, 10))
+
+	sample := sampleEvents(events, 5)
+	require.Len(t, sample, 5)
+	assert.Equal(t, []coreDtos.Event{events[0], events[2], events[4], events[6], events[8]}, sample)
+}
+
+func TestHttpController_EstimateExportSize(t *testing.T) {
+	tests := []struct {
+		Name            string
+		Query           string
+		ExportError     error
+		ExpectedStatus  int
+		ExpectedFormats []string
+	}{
+		{"All formats", "", nil, http.StatusOK, []string{nativeFormatName, arrFormat, ekuiperFormat, csvFormat, ndjsonFormat}},
+		{"Compressed", "?compression=gzip", nil, http.StatusOK, []string{nativeFormatName, arrFormat, ekuiperFormat, csvFormat, ndjsonFormat}},
+		{"Native format", "?format=json", nil, http.StatusOK, []string{nativeFormatName}},
+		{"CSV format", "?format=csv&compression=zlib", nil, http.StatusOK, []string{csvFormat}},
+		{"Summary format", "?format=summary", nil, http.StatusBadRequest, nil},
+		{"Unknown format", "?format=junk", nil, http.StatusBadRequest, nil},
+		{"Unknown compression", "?compression=junk", nil, http.StatusBadRequest, nil},
+		{"Export error", "", errors.New("failed"), http.StatusInternalServerError, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			var data *dtos.RecordedData
+			if test.ExportError == nil {
+				data = estimateTestData(t, 5)
+			}
+			mockDataManager.On("ExportRecordedData").Return(data, test.ExportError)
+
+			req, err := http.NewRequest(http.MethodGet, exportSizeRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.estimateExportSize)).ServeHTTP(testRecorder, req)
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code, testRecorder.Body.String())
+
+			if test.ExpectedStatus != http.StatusOK {
+				return
+			}
+
+			var estimate dtos.ExportSizeEstimate
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &estimate))
+			assert.Equal(t, 5, estimate.EventCount)
+
+			var formats []string
+			for _, formatEstimate := range estimate.Formats {
+				formats = append(formats, formatEstimate.Format)
+			}
+			assert.Equal(t, test.ExpectedFormats, formats)
+			assert.Equal(t, strconv.FormatInt(estimate.Formats[0].Size, 10), testRecorder.Header().Get(estimatedSizeHeader))
+		})
+	}
+}
@@ -0,0 +1,315 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// exportLinkTokenParam is the query parameter of the export link route holding the link's token
+	exportLinkTokenParam = "token"
+
+	defaultExportLinkExpiry = time.Hour
+	maxExportLinkExpiry     = 7 * 24 * time.Hour
+	exportLinkNonceBytes    = 16
+
+	failedExportLinkValidate = "Export link request failed validation: ExpiresIn must be between 0 and 7 days, MaxDownloads must be equal or greater than 0 and Compression and Format must be available export options"
+)
+
+var exportLinkNotFound = errors.New("export link is invalid, expired or has been used")
+var exportLinkRecordingChanged = errors.New("the recording the export link was minted for has been replaced")
+
+// exportLink is the content of a minted link's token, which is signed with the signing key so any instance sharing
+// the key can verify it, including after a restart. The link downloads the recording with the name and digest it was
+// minted for, with the export options it was minted with.
+type exportLink struct {
+	Recording    string `json:"recording"`
+	Digest       string `json:"digest"`
+	Compression  string `json:"compression,omitempty"`
+	Format       string `json:"format,omitempty"`
+	Sign         bool   `json:"sign,omitempty"`
+	ExpiresAt    int64  `json:"expiresAt"`
+	MaxDownloads int    `json:"maxDownloads"`
+	// Nonce makes the token of each link unique, so the downloads of links minted with the same content are
+	// counted separately
+	Nonce string `json:"nonce"`
+}
+
+// query returns the export query parameters the link was minted with
+func (l *exportLink) query() url.Values {
+	query := url.Values{}
+	if l.Compression != noCompression {
+		query.Set("compression", l.Compression)
+	}
+	if l.Format != nativeFormat {
+		query.Set("format", l.Format)
+	}
+	if l.Sign {
+		query.Set("sign", "true")
+	}
+	return query
+}
+
+// exportLinks counts the downloads of the links used, keyed by token, until they expire. The counts are held in
+// memory, so each instance counts the downloads it serves and the counts don't survive a restart.
+type exportLinks struct {
+	mutex     sync.Mutex
+	downloads map[string]*exportLinkDownloads
+	now       func() time.Time
+}
+
+type exportLinkDownloads struct {
+	count     int
+	expiresAt time.Time
+}
+
+func newExportLinks() *exportLinks {
+	return &exportLinks{
+		downloads: make(map[string]*exportLinkDownloads),
+		now:       time.Now,
+	}
+}
+
+// available returns true if the link hasn't expired or been used up
+func (l *exportLinks) available(token string, link *exportLink) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.now().Before(time.Unix(0, link.ExpiresAt)) {
+		return false
+	}
+
+	downloads := l.downloads[token]
+	return downloads == nil || downloads.count < link.MaxDownloads
+}
+
+// use uses one of the link's downloads, returning false if it has expired or been used up. The counts of the
+// expired links are pruned.
+func (l *exportLinks) use(token string, link *exportLink) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	for existing, downloads := range l.downloads {
+		if !now.Before(downloads.expiresAt) {
+			delete(l.downloads, existing)
+		}
+	}
+
+	expiresAt := time.Unix(0, link.ExpiresAt)
+	if !now.Before(expiresAt) {
+		return false
+	}
+
+	downloads := l.downloads[token]
+	if downloads == nil {
+		downloads = &exportLinkDownloads{expiresAt: expiresAt}
+		l.downloads[token] = downloads
+	}
+
+	if downloads.count >= link.MaxDownloads {
+		return false
+	}
+
+	downloads.count++
+	return true
+}
+
+// recordingDigest returns the hex encoded SHA-256 digest of the recorded data as exported, so a link only downloads
+// the exact recording it was minted for
+func recordingDigest(data *dtos.RecordedData) (string, error) {
+	encoded, err := marshalRecordedData(data)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256(encoded)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// signExportLink returns the token of the link, which is its base64 encoded JSON and the signature of that JSON
+// separated by a dot
+func (c *httpController) signExportLink(link *exportLink) (string, error) {
+	claims, err := json.Marshal(link)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(claims)
+	signature, err := c.signData([]byte(payload))
+	if err != nil {
+		return "", err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return "", err
+	}
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(decoded), nil
+}
+
+// verifyExportLink returns the link of the token if its signature is valid
+func (c *httpController) verifyExportLink(token string) (*exportLink, error) {
+	payload, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, exportLinkNotFound
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, exportLinkNotFound
+	}
+
+	if err := c.verifyData([]byte(payload), base64.StdEncoding.EncodeToString(decoded)); err != nil {
+		return nil, exportLinkNotFound
+	}
+
+	claims, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, exportLinkNotFound
+	}
+
+	link := &exportLink{}
+	if err := json.Unmarshal(claims, link); err != nil {
+		return nil, exportLinkNotFound
+	}
+
+	return link, nil
+}
+
+// mintExportLink mints a time-limited link for downloading the last recorded data and returns it as the HTTP
+// response. The link's token is signed with the signing key and holds the recording's name and the digest of its
+// export, so the link downloads that exact recording and stops working if the recording has changed since.
+func (c *httpController) mintExportLink(ctx echo.Context) error {
+	request := dtos.ExportLinkRequest{}
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&request); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	if request.ExpiresIn == 0 {
+		request.ExpiresIn = defaultExportLinkExpiry
+	}
+	if request.MaxDownloads == 0 {
+		request.MaxDownloads = 1
+	}
+
+	_, compressionAvailable := codecs[request.Compression]
+	if request.ExpiresIn < 0 || request.ExpiresIn > maxExportLinkExpiry || request.MaxDownloads < 0 ||
+		(request.Compression != noCompression && !compressionAvailable) ||
+		(request.Format != nativeFormat && request.Format != ekuiperFormat && request.Format != summaryFormat) {
+		return ctx.String(http.StatusBadRequest, failedExportLinkValidate)
+	}
+
+	recordedData, err := c.dataManager.ExportRecordedData()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to export recorded data: %v", err))
+	}
+
+	digest, err := recordingDigest(recordedData)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to mint export link: %v", err))
+	}
+
+	nonce := make([]byte, exportLinkNonceBytes)
+	if _, err := rand.Read(nonce); err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to mint export link: %v", err))
+	}
+
+	expiresAt := c.exportLinks.now().Add(request.ExpiresIn)
+	token, err := c.signExportLink(&exportLink{
+		Recording:    recordedData.Name,
+		Digest:       digest,
+		Compression:  request.Compression,
+		Format:       request.Format,
+		Sign:         request.Sign,
+		ExpiresAt:    expiresAt.UnixNano(),
+		MaxDownloads: request.MaxDownloads,
+		Nonce:        base64.RawURLEncoding.EncodeToString(nonce),
+	})
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to mint export link: %v", err))
+	}
+
+	response := dtos.ExportLinkResponse{
+		URL:          exportLinkRoute + "?" + url.Values{exportLinkTokenParam: []string{token}}.Encode(),
+		Token:        token,
+		ExpiresAt:    expiresAt.UnixNano(),
+		MaxDownloads: request.MaxDownloads,
+	}
+
+	c.appSdk.LoggingClient().Infof("ARR Export - Minted export link valid for %s and %d downloads", request.ExpiresIn.String(), request.MaxDownloads)
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal export link: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// downloadExportLink returns the recorded data for the export link as the HTTP response, using one of the link's
+// downloads. Only the token is needed, so the link can be shared without sharing the API credentials.
+func (c *httpController) downloadExportLink(ctx echo.Context) error {
+	token := ctx.Request().URL.Query().Get(exportLinkTokenParam)
+
+	// The token is checked before the recorded data, so nothing about the recording is revealed to invalid links
+	link, err := c.verifyExportLink(token)
+	if err != nil || !c.exportLinks.available(token, link) {
+		return ctx.String(http.StatusNotFound, fmt.Sprintf("failed to download export: %v", exportLinkNotFound))
+	}
+
+	recordedData, err := c.dataManager.ExportRecordedData()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to export recorded data: %v", err))
+	}
+
+	// The link isn't used when the recording has changed, in case the original recording is imported again
+	if recordedData.Name != link.Recording {
+		return ctx.String(http.StatusGone, fmt.Sprintf("failed to download export: %v", exportLinkRecordingChanged))
+	}
+	digest, err := recordingDigest(recordedData)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to export recorded data: %v", err))
+	}
+	if digest != link.Digest {
+		return ctx.String(http.StatusGone, fmt.Sprintf("failed to download export: %v", exportLinkRecordingChanged))
+	}
+
+	if !c.exportLinks.use(token, link) {
+		return ctx.String(http.StatusNotFound, fmt.Sprintf("failed to download export: %v", exportLinkNotFound))
+	}
+
+	// The data is encoded with the options the link was minted with, ignoring any others in the request
+	ctx.Request().URL.RawQuery = link.query().Encode()
+	return c.serveRecordedData(ctx, recordedData)
+}
@@ -0,0 +1,190 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_MintExportLink_Validation(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name           string
+		Request        dtos.ExportLinkRequest
+		ExpectedStatus int
+	}{
+		{"Defaults", dtos.ExportLinkRequest{}, http.StatusOK},
+		{"All options", dtos.ExportLinkRequest{ExpiresIn: time.Minute, MaxDownloads: 3, Compression: gzipCompression, Format: summaryFormat}, http.StatusOK},
+		{"Negative ExpiresIn", dtos.ExportLinkRequest{ExpiresIn: -time.Minute}, http.StatusBadRequest},
+		{"ExpiresIn too long", dtos.ExportLinkRequest{ExpiresIn: maxExportLinkExpiry + time.Second}, http.StatusBadRequest},
+		{"Negative MaxDownloads", dtos.ExportLinkRequest{MaxDownloads: -1}, http.StatusBadRequest},
+		{"Bad compression", dtos.ExportLinkRequest{Compression: "bogus"}, http.StatusBadRequest},
+		{"Bad format", dtos.ExportLinkRequest{Format: "bogus"}, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, mockSdk := createTargetAndMocks()
+			mockSdk.On("SecretProvider").Return(createMockSecretProvider(privateKey, publicKey, nil))
+			mockDataManager.On("ExportRecordedData").Return(&dtos.RecordedData{Name: "line-1"}, nil)
+
+			recorder := mintExportLink(t, target, test.Request)
+			require.Equal(t, test.ExpectedStatus, recorder.Code, recorder.Body.String())
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, recorder.Body.String(), failedExportLinkValidate)
+			}
+		})
+	}
+}
+
+func TestHttpController_DownloadExportLink(t *testing.T) {
+	recordedData := &dtos.RecordedData{
+		Name:           "line-1",
+		RecordedEvents: []coreDtos.Event{{Id: "event-1", DeviceName: "test", ProfileName: "test", Origin: 1}},
+		Devices:        []coreDtos.Device{{Name: "test", ProfileName: "test"}},
+		Profiles:       []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "test"}}},
+	}
+	replacedData := &dtos.RecordedData{
+		Name:           "line-1",
+		RecordedEvents: []coreDtos.Event{{Id: "event-2", DeviceName: "test", ProfileName: "test", Origin: 2}},
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	target, mockDataManager, mockSdk := createTargetAndMocks()
+	mockSdk.On("SecretProvider").Return(createMockSecretProvider(privateKey, publicKey, nil))
+	target.exportLinks.now = func() time.Time { return now }
+	mockDataManager.On("ExportRecordedData").Return(recordedData, nil).Once()
+
+	recorder := mintExportLink(t, target, dtos.ExportLinkRequest{ExpiresIn: time.Hour, MaxDownloads: 2, Compression: gzipCompression})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	link := dtos.ExportLinkResponse{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &link))
+	assert.Equal(t, now.Add(time.Hour).UnixNano(), link.ExpiresAt)
+	assert.Equal(t, 2, link.MaxDownloads)
+	assert.Contains(t, link.URL, exportLinkRoute+"?token=")
+
+	// Options in the download request are ignored, the link's options are used
+	download := func(url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, url+"&compression=zlib", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		http.HandlerFunc(WrapEchoHandler(t, target.downloadExportLink)).ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// The recording was replaced, so the link doesn't download the replacement but remains usable
+	mockDataManager.On("ExportRecordedData").Return(replacedData, nil).Once()
+	recorder = download(link.URL)
+	require.Equal(t, http.StatusGone, recorder.Code, recorder.Body.String())
+
+	mockDataManager.On("ExportRecordedData").Return(recordedData, nil)
+	for i := 0; i < link.MaxDownloads; i++ {
+		recorder = download(link.URL)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		assert.Equal(t, contentEncodingGzip, recorder.Header().Get("Content-Encoding"))
+		assert.Equal(t, recordedData, uncompressData(t, gzipCompression, recorder.Body))
+	}
+
+	// Used up
+	recorder = download(link.URL)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// Unknown or tampered token
+	recorder = download(exportLinkRoute + "?token=bogus")
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	payload, signature, _ := strings.Cut(link.Token, ".")
+	recorder = download(exportLinkRoute + "?token=" + payload + "A." + signature)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// Expired
+	recorder = mintExportLink(t, target, dtos.ExportLinkRequest{ExpiresIn: time.Minute})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &link))
+	now = now.Add(time.Minute)
+	recorder = download(link.URL)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// The download counts of expired links are pruned when the next link is used
+	now = now.Add(time.Hour)
+	recorder = mintExportLink(t, target, dtos.ExportLinkRequest{})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &link))
+	recorder = download(link.URL)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, target.exportLinks.downloads, 1)
+
+	// The token is verified with the signing key, so another instance sharing it, or a restarted instance, serves
+	// the link too
+	recorder = mintExportLink(t, target, dtos.ExportLinkRequest{})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &link))
+	other, otherDataManager, otherSdk := createTargetAndMocks()
+	otherSdk.On("SecretProvider").Return(createMockSecretProvider(privateKey, publicKey, nil))
+	otherDataManager.On("ExportRecordedData").Return(recordedData, nil)
+	other.exportLinks.now = func() time.Time { return now }
+	req, err := http.NewRequest(http.MethodGet, link.URL, nil)
+	require.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, other.downloadExportLink)).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
+
+func TestHttpController_DownloadExportLink_InvalidToken(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	target, mockDataManager, mockSdk := createTargetAndMocks()
+	mockSdk.On("SecretProvider").Return(createMockSecretProvider(privateKey, publicKey, nil))
+
+	req, err := http.NewRequest(http.MethodGet, exportLinkRoute+"?token=bogus.bogus", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.downloadExportLink)).ServeHTTP(recorder, req)
+
+	// The recorded data isn't touched for invalid links, so nothing about the recording is revealed
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	mockDataManager.AssertNotCalled(t, "ExportRecordedData")
+}
+
+func mintExportLink(t *testing.T, target *httpController, request dtos.ExportLinkRequest) *httptest.ResponseRecorder {
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, exportLinkRoute, bytes.NewReader(body))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.mintExportLink)).ServeHTTP(recorder, req)
+	return recorder
+}
@@ -0,0 +1,232 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/labstack/echo/v4"
+)
+
+// ExportPathsAppSetting is the comma separated list of local directories, i.e. USB stick mount points, the recorded
+// data may be exported to. Exports to the local filesystem are disabled when not set.
+const ExportPathsAppSetting = "ExportPaths"
+
+const (
+	defaultLocalExportName = "recorded-data"
+	signatureFileExtension = ".sig"
+)
+
+var localExportExists = errors.New("file already exists, set overwrite to replace it")
+
+// resolveLocalExportPath returns the file the recorded data is exported to, which must be in an existing directory
+// allow-listed by the ExportPaths App Setting. The file is named after the recording when the path is an existing
+// directory.
+func (c *httpController) resolveLocalExportPath(path string, name string, extension string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", localPathNotAbsolute
+	}
+
+	path = filepath.Clean(path)
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		if len(name) == 0 {
+			name = defaultLocalExportName
+		}
+		path = filepath.Join(path, filepath.Base(name)+extension)
+	}
+
+	dir, err := c.allowedLocalPath(ExportPathsAppSetting, filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, filepath.Base(path)), nil
+}
+
+// localExportFile is a file written by a local export, i.e. the recorded data or its signature
+type localExportFile struct {
+	path string
+	data []byte
+}
+
+// writeLocalExportFile writes the data to a temporary file in the same directory and renames it into place, so an
+// existing file is never left partially written and a symbolic link at the path is replaced rather than followed.
+// The data is synced before returning so the removable media can be detached as soon as the export completes.
+func writeLocalExportFile(path string, data []byte, overwrite bool) error {
+	if _, err := os.Lstat(path); err == nil && !overwrite {
+		return localExportExists
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+
+	return nil
+}
+
+// exportRecordedDataToPath writes the data for the last record session to a file on the local filesystem, i.e. a
+// USB stick attached to the gateway, and returns the file written as the HTTP response.
+func (c *httpController) exportRecordedDataToPath(ctx echo.Context) error {
+	request := dtos.LocalExportRequest{}
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&request); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	return c.exportToLocalPath(ctx, request, c.dataManager.ExportRecordedData)
+}
+
+// exportToLocalPath writes the recorded data returned by the export function to the local filesystem destination in
+// the request and returns the file written as the HTTP response. The export function isn't called if the request
+// is invalid.
+func (c *httpController) exportToLocalPath(ctx echo.Context, request dtos.LocalExportRequest,
+	export func() (*dtos.RecordedData, error)) error {
+	extension := ".json"
+	var fileCodec codec
+	if request.Compression != noCompression {
+		var ok bool
+		fileCodec, ok = codecs[request.Compression]
+		if !ok {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("compression format not available: %s", request.Compression))
+		}
+		extension += "." + request.Compression
+	}
+
+	// The baseline is read before exporting, so the export isn't wasted on a baseline which can't be used
+	var baseline *dtos.RecordedData
+	var baselineDigest string
+	if len(request.Baseline) > 0 {
+		limits, err := c.getImportLimits()
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedLocalExport, err))
+		}
+
+		baseline, baselineDigest, err = c.readBaseline(request.Baseline, limits)
+		switch {
+		case errors.Is(err, localPathDisabled), errors.Is(err, localPathNotAllowed):
+			return ctx.String(http.StatusForbidden, fmt.Sprintf("%s: baseline: %v", failedLocalExport, err))
+		case isImportLimitError(err):
+			return ctx.String(http.StatusRequestEntityTooLarge, fmt.Sprintf("%s: baseline: %v", failedLocalExport, err))
+		case err != nil:
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: baseline: %v", failedLocalExport, err))
+		}
+	}
+
+	recordedData, err := export()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to export recorded data: %v", err))
+	}
+
+	if baseline != nil {
+		recordedData, err = deltaRecordedData(recordedData, baseline, request.Baseline, baselineDigest)
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedLocalExport, err))
+		}
+	}
+
+	path, err := c.resolveLocalExportPath(request.Path, recordedData.Name, extension)
+	switch {
+	case errors.Is(err, localPathDisabled), errors.Is(err, localPathNotAllowed):
+		return ctx.String(http.StatusForbidden, fmt.Sprintf("%s: %v", failedLocalExport, err))
+	case err != nil:
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedLocalExport, err))
+	}
+
+	data, err := marshalRecordedData(recordedData)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, "failed to marshal recorded data")
+	}
+
+	response := dtos.LocalExportResponse{Path: path}
+
+	// The signature is always for the uncompressed JSON, the same as for the exports downloaded
+	var signature string
+	if request.Sign {
+		signature, err = c.signData(data)
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedSigningData, err))
+		}
+		response.SignaturePath = path + signatureFileExtension
+	}
+
+	if request.Compression != noCompression {
+		data, err = fileCodec.compress(data)
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s %s: %s", failedDataCompression, request.Compression, err))
+		}
+	}
+
+	// An export run as a job which has been canceled stops before the file is written
+	if err := ctx.Request().Context().Err(); err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedLocalExport, err))
+	}
+
+	files := []localExportFile{{path: path, data: data}}
+	if request.Sign {
+		files = append(files, localExportFile{path: response.SignaturePath, data: []byte(signature)})
+	}
+
+	// Both files are checked before either is written, so the data file isn't replaced when its signature can't be
+	if !request.Overwrite {
+		for _, file := range files {
+			if _, err := os.Lstat(file.path); err == nil {
+				return ctx.String(http.StatusConflict, fmt.Sprintf("%s: %s: %v", failedLocalExport, file.path, localExportExists))
+			}
+		}
+	}
+
+	for _, file := range files {
+		if err := writeLocalExportFile(file.path, file.data, request.Overwrite); err != nil {
+			if errors.Is(err, localExportExists) {
+				return ctx.String(http.StatusConflict, fmt.Sprintf("%s: %s: %v", failedLocalExport, file.path, err))
+			}
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %s: %v", failedLocalExport, file.path, err))
+		}
+	}
+
+	response.Size = len(data)
+	c.appSdk.LoggingClient().Infof("ARR Export - Exported %d bytes of recorded data to %s", response.Size, path)
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal export response: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
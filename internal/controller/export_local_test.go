@@ -0,0 +1,219 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_ResolveLocalExportPath(t *testing.T) {
+	usbDir := t.TempDir()
+	otherDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(usbDir, "recordings"), 0750))
+	require.NoError(t, os.Symlink(otherDir, filepath.Join(usbDir, "escape")))
+	// Shares the allowed directory's name as a prefix, without being within it
+	siblingDir := usbDir + "-sibling"
+	require.NoError(t, os.Mkdir(siblingDir, 0750))
+	t.Cleanup(func() { _ = os.RemoveAll(siblingDir) })
+
+	tests := []struct {
+		Name          string
+		ExportPaths   string
+		Path          string
+		RecordingName string
+		ExpectedPath  string
+		ExpectedError error
+	}{
+		{"File in allowed dir", usbDir, filepath.Join(usbDir, "line-1.json"), "", filepath.Join(usbDir, "line-1.json"), nil},
+		{"File in allowed sub dir", usbDir, filepath.Join(usbDir, "recordings", "line-1.json"), "", filepath.Join(usbDir, "recordings", "line-1.json"), nil},
+		{"Allowed dir named after recording", usbDir, usbDir, "line-1", filepath.Join(usbDir, "line-1.json"), nil},
+		{"Allowed dir unnamed recording", usbDir, usbDir, "", filepath.Join(usbDir, defaultLocalExportName+".json"), nil},
+		{"Second allowed dir", "/media/missing, " + usbDir, filepath.Join(usbDir, "line-1.json"), "", filepath.Join(usbDir, "line-1.json"), nil},
+		{"Disabled", "", filepath.Join(usbDir, "line-1.json"), "", "", localPathDisabled},
+		{"Relative path", usbDir, "line-1.json", "", "", localPathNotAbsolute},
+		{"Outside allowed dirs", usbDir, filepath.Join(otherDir, "line-1.json"), "", "", localPathNotAllowed},
+		{"Parent traversal", usbDir, filepath.Join(usbDir, "..", filepath.Base(otherDir), "line-1.json"), "", "", localPathNotAllowed},
+		{"Symbolic link escape", usbDir, filepath.Join(usbDir, "escape", "line-1.json"), "", "", localPathNotAllowed},
+		{"Prefix of allowed dir", usbDir, filepath.Join(siblingDir, "line-1.json"), "", "", localPathNotAllowed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &appMocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ExportPathsAppSetting: test.ExportPaths})
+			target := New(nil, nil, nil, mockSdk).(*httpController)
+
+			path, err := target.resolveLocalExportPath(test.Path, test.RecordingName, ".json")
+			if test.ExpectedError != nil {
+				require.ErrorIs(t, err, test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			expectedPath, err := filepath.EvalSymlinks(filepath.Dir(test.ExpectedPath))
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(expectedPath, filepath.Base(test.ExpectedPath)), path)
+		})
+	}
+}
+
+func TestHttpController_ExportRecordedDataToPath(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	recordedData := &dtos.RecordedData{
+		Name:           "line-1",
+		RecordedEvents: []coreDtos.Event{{DeviceName: "test", ProfileName: "test"}},
+		Devices:        []coreDtos.Device{{Name: "test", ProfileName: "test"}},
+		Profiles:       []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "test"}}},
+	}
+
+	tests := []struct {
+		Name           string
+		Request        dtos.LocalExportRequest
+		Existing       bool
+		ExportError    error
+		ExpectedStatus int
+		ExpectedFile   string
+	}{
+		{"Export", dtos.LocalExportRequest{}, false, nil, http.StatusOK, "line-1.json"},
+		{"Export compressed", dtos.LocalExportRequest{Compression: gzipCompression}, false, nil, http.StatusOK, "line-1.json.gzip"},
+		{"Export signed", dtos.LocalExportRequest{Sign: true}, false, nil, http.StatusOK, "line-1.json"},
+		{"Overwrite", dtos.LocalExportRequest{Overwrite: true}, true, nil, http.StatusOK, "line-1.json"},
+		{"Exists", dtos.LocalExportRequest{}, true, nil, http.StatusConflict, ""},
+		{"Bad compression", dtos.LocalExportRequest{Compression: "bogus"}, false, nil, http.StatusBadRequest, ""},
+		{"No recorded data", dtos.LocalExportRequest{}, false, errors.New("no recorded data"), http.StatusInternalServerError, ""},
+		{"Not allowed", dtos.LocalExportRequest{Path: "/tmp"}, false, nil, http.StatusForbidden, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			usbDir := t.TempDir()
+			if len(test.Request.Path) == 0 {
+				test.Request.Path = usbDir
+			}
+			if test.Existing {
+				require.NoError(t, os.WriteFile(filepath.Join(usbDir, "line-1.json"), []byte("old"), 0640))
+			}
+
+			mockDataManager := &mocks.DataManager{}
+			mockDataManager.On("ExportRecordedData").Return(recordedData, test.ExportError)
+			mockSdk := &appMocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ExportPathsAppSetting: usbDir})
+			mockSdk.On("SecretProvider").Return(createMockSecretProvider(privateKey, publicKey, nil))
+			target := New(mockDataManager, nil, nil, mockSdk).(*httpController)
+
+			body, err := json.Marshal(test.Request)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, exportRoute, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedDataToPath)).ServeHTTP(recorder, req)
+			require.Equal(t, test.ExpectedStatus, recorder.Code, recorder.Body.String())
+
+			if test.ExpectedStatus != http.StatusOK {
+				return
+			}
+
+			response := dtos.LocalExportResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			assert.Equal(t, test.ExpectedFile, filepath.Base(response.Path))
+
+			data, err := os.ReadFile(response.Path)
+			require.NoError(t, err)
+			assert.Len(t, data, response.Size)
+
+			exported := &dtos.RecordedData{}
+			if len(test.Request.Compression) > 0 {
+				exported = uncompressData(t, test.Request.Compression, bytes.NewReader(data))
+			} else {
+				require.NoError(t, json.Unmarshal(data, exported))
+			}
+			assert.Equal(t, recordedData.Name, exported.Name)
+			assert.Len(t, exported.RecordedEvents, 1)
+
+			expectedFiles := 1
+			if test.Request.Sign {
+				expectedFiles++
+				signature, err := os.ReadFile(response.SignaturePath)
+				require.NoError(t, err)
+				require.NoError(t, target.verifyData(data, string(signature)))
+			} else {
+				assert.Empty(t, response.SignaturePath)
+			}
+
+			// Only the exported files are left, no temporary files
+			entries, err := os.ReadDir(usbDir)
+			require.NoError(t, err)
+			assert.Len(t, entries, expectedFiles)
+		})
+	}
+}
+
+func TestHttpController_ExportRecordedDataToPath_SignatureExists(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	usbDir := t.TempDir()
+	signaturePath := filepath.Join(usbDir, "line-1.json"+signatureFileExtension)
+	require.NoError(t, os.WriteFile(signaturePath, []byte("old"), 0640))
+
+	mockDataManager := &mocks.DataManager{}
+	mockDataManager.On("ExportRecordedData").Return(&dtos.RecordedData{Name: "line-1"}, nil)
+	mockSdk := &appMocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ExportPathsAppSetting: usbDir})
+	mockSdk.On("SecretProvider").Return(createMockSecretProvider(privateKey, publicKey, nil))
+	target := New(mockDataManager, nil, nil, mockSdk).(*httpController)
+
+	body, err := json.Marshal(dtos.LocalExportRequest{Path: usbDir, Sign: true})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, exportRoute, bytes.NewReader(body))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedDataToPath)).ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusConflict, recorder.Code, recorder.Body.String())
+
+	// Neither the signature is replaced nor the data written without it
+	signature, err := os.ReadFile(signaturePath)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(signature))
+	entries, err := os.ReadDir(usbDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
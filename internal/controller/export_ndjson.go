@@ -0,0 +1,52 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+const (
+	ndjsonFormat      = "ndjson"
+	ndjsonExtension   = ".ndjson"
+	ndjsonContentType = "application/x-ndjson"
+)
+
+// toNDJSON converts the recorded data to newline delimited JSON, with a first line holding the recorded data without
+// its Events followed by a line per Event, so the Events can be consumed a line at a time. The data can be imported
+// with the ndjson import format.
+func toNDJSON(data *dtos.RecordedData) ([]byte, error) {
+	withoutEvents := *data
+	withoutEvents.RecordedEvents = nil
+
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	if err := encoder.Encode(withoutEvents); err != nil {
+		return nil, err
+	}
+
+	for _, event := range data.RecordedEvents {
+		if err := encoder.Encode(event); err != nil {
+			return nil, err
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
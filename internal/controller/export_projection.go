@@ -0,0 +1,194 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const (
+	// omitFieldsParam is the comma separated list of heavy fields left out of the exported Events and Readings
+	omitFieldsParam = "omit"
+	// readingFieldsParam is the comma separated list of the only Reading fields included in the exported Readings
+	readingFieldsParam = "fields"
+
+	binaryValueField = "binaryValue"
+	mediaTypeField   = "mediaType"
+	objectValueField = "objectValue"
+	tagsField        = "tags"
+	unitsField       = "units"
+)
+
+// omittableFields are the fields that can be omitted from an export. Omitting them leaves valid Events, so the
+// export can still be imported.
+var omittableFields = []string{binaryValueField, objectValueField, tagsField, unitsField}
+
+// readingFields are the JSON names of the Reading fields that can be selected for an export
+var readingFields = []string{"id", "origin", "deviceName", "resourceName", "profileName", "valueType", unitsField,
+	tagsField, "value", binaryValueField, mediaTypeField, objectValueField}
+
+// exportProjection holds the fields to leave out of, or the only Reading fields to include in, an export
+type exportProjection struct {
+	omit   []string
+	fields []string
+}
+
+// projectedRecordedData is the recorded data with its Events replaced by the projected Events
+type projectedRecordedData struct {
+	*dtos.RecordedData
+	RecordedEvents []projectedEvent `json:"recordedEvents"`
+}
+
+// projectedEvent is an Event with its Readings replaced by the selected fields of each Reading
+type projectedEvent struct {
+	coreDtos.Event
+	Readings []map[string]json.RawMessage `json:"readings"`
+}
+
+// parseExportProjection returns the projection requested by the query parameters, or nil if none was requested
+func parseExportProjection(query url.Values) (*exportProjection, error) {
+	omit, err := parseFieldList(query.Get(omitFieldsParam), omittableFields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %w", omitFieldsParam, err)
+	}
+
+	fields, err := parseFieldList(query.Get(readingFieldsParam), readingFields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %w", readingFieldsParam, err)
+	}
+
+	if len(omit) == 0 && len(fields) == 0 {
+		return nil, nil
+	}
+
+	return &exportProjection{omit: omit, fields: fields}, nil
+}
+
+func parseFieldList(value string, allowed []string) ([]string, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if !slices.Contains(allowed, field) {
+			return nil, fmt.Errorf("unknown field '%s', must be one of %s", field, strings.Join(allowed, ", "))
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// marshal returns the JSON for the recorded data with the projection applied. The recorded data isn't modified.
+func (p *exportProjection) marshal(data *dtos.RecordedData) ([]byte, error) {
+	projected := *data
+	projected.RecordedEvents = make([]coreDtos.Event, len(data.RecordedEvents))
+	for i, event := range data.RecordedEvents {
+		projected.RecordedEvents[i] = p.omitEventFields(event)
+	}
+
+	// Projected Events are kept as Events, even if their Ids are omitted
+	if len(p.fields) == 0 {
+		return marshalRecordedEvents(&projected)
+	}
+
+	result := projectedRecordedData{
+		RecordedData:   &projected,
+		RecordedEvents: make([]projectedEvent, len(projected.RecordedEvents)),
+	}
+
+	for i, event := range projected.RecordedEvents {
+		readings := make([]map[string]json.RawMessage, len(event.Readings))
+		for j, reading := range event.Readings {
+			selected, err := p.selectReadingFields(reading)
+			if err != nil {
+				return nil, err
+			}
+			readings[j] = selected
+		}
+		result.RecordedEvents[i] = projectedEvent{Event: event, Readings: readings}
+	}
+
+	return json.Marshal(result)
+}
+
+// omitEventFields returns a copy of the Event with the omitted fields cleared, which leaves them out of the JSON
+// since they are all omitted when empty
+func (p *exportProjection) omitEventFields(event coreDtos.Event) coreDtos.Event {
+	if len(p.omit) == 0 {
+		return event
+	}
+
+	readings := make([]coreDtos.BaseReading, len(event.Readings))
+	copy(readings, event.Readings)
+	event.Readings = readings
+
+	for _, field := range p.omit {
+		for i := range event.Readings {
+			reading := &event.Readings[i]
+			switch field {
+			case binaryValueField:
+				// The media type is only valid along with the binary value
+				reading.BinaryValue = nil
+				reading.MediaType = ""
+			case objectValueField:
+				reading.ObjectValue = nil
+			case tagsField:
+				reading.Tags = nil
+			case unitsField:
+				reading.Units = ""
+			}
+		}
+
+		if field == tagsField {
+			event.Tags = nil
+		}
+	}
+
+	return event
+}
+
+// selectReadingFields returns the selected fields of the Reading as encoded by its own marshaling
+func (p *exportProjection) selectReadingFields(reading coreDtos.BaseReading) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(reading)
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]json.RawMessage, len(p.fields))
+	for _, field := range p.fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,128 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExportProjection(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Query         url.Values
+		Expected      *exportProjection
+		ExpectedError bool
+	}{
+		{"None", url.Values{}, nil, false},
+		{"Omit", url.Values{omitFieldsParam: {"binaryValue, tags"}}, &exportProjection{omit: []string{binaryValueField, tagsField}}, false},
+		{"Fields", url.Values{readingFieldsParam: {"origin,value"}}, &exportProjection{fields: []string{"origin", "value"}}, false},
+		{"Both", url.Values{omitFieldsParam: {"units"}, readingFieldsParam: {"value"}}, &exportProjection{omit: []string{unitsField}, fields: []string{"value"}}, false},
+		{"Unknown omit field", url.Values{omitFieldsParam: {"origin"}}, nil, true},
+		{"Unknown reading field", url.Values{readingFieldsParam: {"bogus"}}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := parseExportProjection(test.Query)
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func projectionTestData(t *testing.T) *dtos.RecordedData {
+	event := coreDtos.NewEvent("profile", "device", "source")
+	event.Tags = map[string]any{"site": "A"}
+	require.NoError(t, event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21)))
+	event.Readings[0].Units = "C"
+	event.Readings[0].Tags = map[string]any{"sensor": "1"}
+	event.AddBinaryReading("Image", []byte{1, 2, 3}, "image/png")
+
+	return &dtos.RecordedData{Name: "projected", RecordedEvents: []coreDtos.Event{event}}
+}
+
+func TestExportProjection_Omit(t *testing.T) {
+	data := projectionTestData(t)
+	projection := &exportProjection{omit: omittableFields}
+
+	encoded, err := projection.marshal(data)
+	require.NoError(t, err)
+
+	actual := dtos.RecordedData{}
+	require.NoError(t, json.Unmarshal(encoded, &actual))
+	require.Len(t, actual.RecordedEvents, 1)
+
+	event := actual.RecordedEvents[0]
+	assert.Nil(t, event.Tags)
+	require.Len(t, event.Readings, 2)
+	assert.Equal(t, "21", event.Readings[0].Value)
+	assert.Empty(t, event.Readings[0].Units)
+	assert.Nil(t, event.Readings[0].Tags)
+	assert.Nil(t, event.Readings[1].BinaryValue)
+	assert.Empty(t, event.Readings[1].MediaType)
+
+	// The exported data isn't modified
+	assert.Equal(t, "C", data.RecordedEvents[0].Readings[0].Units)
+	assert.NotNil(t, data.RecordedEvents[0].Tags)
+	assert.NotNil(t, data.RecordedEvents[0].Readings[1].BinaryValue)
+}
+
+func TestExportProjection_Fields(t *testing.T) {
+	data := projectionTestData(t)
+	projection := &exportProjection{fields: []string{"origin", "resourceName", "value"}}
+
+	encoded, err := projection.marshal(data)
+	require.NoError(t, err)
+
+	actual := struct {
+		Name           string `json:"name"`
+		RecordedEvents []struct {
+			DeviceName string           `json:"deviceName"`
+			Readings   []map[string]any `json:"readings"`
+		} `json:"recordedEvents"`
+	}{}
+	require.NoError(t, json.Unmarshal(encoded, &actual))
+
+	assert.Equal(t, "projected", actual.Name)
+	require.Len(t, actual.RecordedEvents, 1)
+	assert.Equal(t, "device", actual.RecordedEvents[0].DeviceName)
+	require.Len(t, actual.RecordedEvents[0].Readings, 2)
+
+	temperature := actual.RecordedEvents[0].Readings[0]
+	assert.Len(t, temperature, 3)
+	assert.Equal(t, "Temperature", temperature["resourceName"])
+	assert.Equal(t, "21", temperature["value"])
+	assert.Contains(t, temperature, "origin")
+
+	// Binary readings have no simple value
+	image := actual.RecordedEvents[0].Readings[1]
+	assert.Equal(t, "Image", image["resourceName"])
+	assert.NotContains(t, image, binaryValueField)
+}
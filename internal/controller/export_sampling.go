@@ -0,0 +1,177 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const (
+	// sampleParam is the fraction of the Events randomly sampled into an export
+	sampleParam = "sample"
+	// stratifyParam is what the sampled Events are balanced across, either device or resource
+	stratifyParam = "stratify"
+	// sampleSeedParam is the seed for the random sampling, so a sampled export can be reproduced
+	sampleSeedParam = "seed"
+
+	stratifyDevice   = "device"
+	stratifyResource = "resource"
+
+	sampledNameSuffix = "-sampled"
+)
+
+var (
+	invalidSampleFraction = errors.New("sample must be a fraction greater than 0 and no more than 1")
+	invalidStratify       = fmt.Errorf("stratify must be one of %s or %s", stratifyDevice, stratifyResource)
+	stratifyWithoutSample = fmt.Errorf("stratify and seed can only be used along with %s", sampleParam)
+	sampleOpaqueError     = errors.New("opaque messages can't be sampled since they aren't decoded")
+)
+
+// exportSampling holds the random sampling applied to an export
+type exportSampling struct {
+	fraction float64
+	stratify string
+	seed     uint64
+}
+
+// parseExportSampling returns the sampling requested by the query parameters, or nil if none was requested. A random
+// seed is used when none is specified.
+func parseExportSampling(query url.Values) (*exportSampling, error) {
+	sampleValue := query.Get(sampleParam)
+	if len(sampleValue) == 0 {
+		if len(query.Get(stratifyParam)) > 0 || len(query.Get(sampleSeedParam)) > 0 {
+			return nil, stratifyWithoutSample
+		}
+		return nil, nil
+	}
+
+	fraction, err := strconv.ParseFloat(sampleValue, 64)
+	if err != nil || math.IsNaN(fraction) || fraction <= 0 || fraction > 1 {
+		return nil, invalidSampleFraction
+	}
+
+	sampling := &exportSampling{fraction: fraction, stratify: query.Get(stratifyParam), seed: rand.Uint64()}
+	switch sampling.stratify {
+	case "", stratifyDevice, stratifyResource:
+	default:
+		return nil, invalidStratify
+	}
+
+	if seedValue := query.Get(sampleSeedParam); len(seedValue) > 0 {
+		sampling.seed, err = strconv.ParseUint(seedValue, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter: %v", sampleSeedParam, err)
+		}
+	}
+
+	return sampling, nil
+}
+
+// sample returns a copy of the recorded data with a random sample of the fraction of its Events, along with their
+// Envelopes. When stratified, each Event is weighted by the inverse of the number of Events of its device, or device
+// and source, so the sample is balanced across them rather than dominated by the busiest. The Events kept stay in
+// their recorded order. The Device Profiles, Devices, annotations and metadata are kept as is, while the dead letters,
+// telemetry and system events are dropped since the sample is a training set rather than a replayable capture.
+func (s *exportSampling) sample(data *dtos.RecordedData) (*dtos.RecordedData, error) {
+	if len(data.Messages) > 0 {
+		return nil, sampleOpaqueError
+	}
+
+	result := &dtos.RecordedData{
+		Name:           data.Name,
+		RecordedEvents: s.sampleEvents(data.RecordedEvents),
+		Profiles:       data.Profiles,
+		Devices:        data.Devices,
+		Annotations:    data.Annotations,
+		Metadata:       data.Metadata,
+	}
+
+	if len(result.Name) > 0 {
+		result.Name += sampledNameSuffix
+	}
+
+	if len(data.Envelopes) > 0 {
+		result.Envelopes = make(map[string]dtos.EnvelopeMetadata)
+		for _, event := range result.RecordedEvents {
+			if envelope, ok := data.Envelopes[event.Id]; ok {
+				result.Envelopes[event.Id] = envelope
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// sampleEvents returns the weighted random sample of the Events without replacement. Each Event is given the key
+// u^(1/weight), with u uniformly random, and those with the largest keys are kept, which samples each Event with
+// probability proportional to its weight.
+func (s *exportSampling) sampleEvents(events []coreDtos.Event) []coreDtos.Event {
+	if len(events) == 0 {
+		return []coreDtos.Event{}
+	}
+
+	count := max(int(math.Round(s.fraction*float64(len(events)))), 1)
+
+	strata := make(map[string]int)
+	for _, event := range events {
+		strata[s.stratum(event)]++
+	}
+
+	type sampleKey struct {
+		index int
+		key   float64
+	}
+
+	random := rand.New(rand.NewPCG(s.seed, s.seed))
+	keys := make([]sampleKey, len(events))
+	for index, event := range events {
+		// With a weight of 1/size the key is u^size, which is compared by its log to avoid underflow
+		keys[index] = sampleKey{index: index, key: math.Log(1-random.Float64()) * float64(strata[s.stratum(event)])}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].key > keys[j].key })
+	keys = keys[:count]
+	sort.Slice(keys, func(i, j int) bool { return keys[i].index < keys[j].index })
+
+	result := make([]coreDtos.Event, count)
+	for i, key := range keys {
+		result[i] = events[key.index]
+	}
+
+	return result
+}
+
+// stratum returns the stratum the Event is balanced within, which is the same for all Events when not stratified
+func (s *exportSampling) stratum(event coreDtos.Event) string {
+	switch s.stratify {
+	case stratifyDevice:
+		return event.DeviceName
+	case stratifyResource:
+		return event.DeviceName + "/" + event.SourceName
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,180 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExportSampling(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Query         url.Values
+		Expected      *exportSampling
+		ExpectedError error
+	}{
+		{"None", url.Values{}, nil, nil},
+		{"Fraction", url.Values{sampleParam: {"0.25"}, sampleSeedParam: {"7"}}, &exportSampling{fraction: 0.25, seed: 7}, nil},
+		{"Stratified", url.Values{sampleParam: {"1"}, stratifyParam: {stratifyResource}, sampleSeedParam: {"7"}}, &exportSampling{fraction: 1, stratify: stratifyResource, seed: 7}, nil},
+		{"Zero fraction", url.Values{sampleParam: {"0"}}, nil, invalidSampleFraction},
+		{"Fraction above one", url.Values{sampleParam: {"1.5"}}, nil, invalidSampleFraction},
+		{"Not a fraction", url.Values{sampleParam: {"half"}}, nil, invalidSampleFraction},
+		{"Unknown stratify", url.Values{sampleParam: {"0.5"}, stratifyParam: {"profile"}}, nil, invalidStratify},
+		{"Stratify without sample", url.Values{stratifyParam: {stratifyDevice}}, nil, stratifyWithoutSample},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := parseExportSampling(test.Query)
+			require.Equal(t, test.ExpectedError, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+
+	_, err := parseExportSampling(url.Values{sampleParam: {"0.5"}, sampleSeedParam: {"-1"}})
+	require.Error(t, err)
+}
+
+// samplingTestEvents returns the Events for a busy device with 90 Events and a quiet device with 10 Events
+func samplingTestEvents() []coreDtos.Event {
+	var events []coreDtos.Event
+	for i := range 100 {
+		device := "busy"
+		if i%10 == 0 {
+			device = "quiet"
+		}
+		event := coreDtos.NewEvent("profile", device, "source")
+		event.Origin = int64(i)
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestExportSampling_SampleEvents(t *testing.T) {
+	events := samplingTestEvents()
+
+	countDevices := func(events []coreDtos.Event) map[string]int {
+		counts := make(map[string]int)
+		for _, event := range events {
+			counts[event.DeviceName]++
+		}
+		return counts
+	}
+
+	sampling := &exportSampling{fraction: 0.2, seed: 1}
+	sampled := sampling.sampleEvents(events)
+	require.Len(t, sampled, 20)
+	for i := 1; i < len(sampled); i++ {
+		assert.Less(t, sampled[i-1].Origin, sampled[i].Origin, "sampled Events must stay in recorded order")
+	}
+
+	// The same seed always produces the same sample
+	assert.Equal(t, sampled, sampling.sampleEvents(events))
+
+	// Stratified by device the quiet device gets a much larger share than its 10%, averaged over several seeds
+	quiet := 0
+	for seed := range uint64(20) {
+		stratified := &exportSampling{fraction: 0.2, stratify: stratifyDevice, seed: seed}
+		sampled = stratified.sampleEvents(events)
+		require.Len(t, sampled, 20)
+		quiet += countDevices(sampled)["quiet"]
+	}
+	assert.Greater(t, quiet, 20*4)
+
+	// At least one Event is always sampled
+	sampling = &exportSampling{fraction: 0.001, seed: 1}
+	assert.Len(t, sampling.sampleEvents(events), 1)
+	assert.Empty(t, sampling.sampleEvents(nil))
+
+	sampling = &exportSampling{fraction: 1, stratify: stratifyResource, seed: 1}
+	assert.Equal(t, events, sampling.sampleEvents(events))
+}
+
+func TestExportSampling_Sample(t *testing.T) {
+	events := samplingTestEvents()[:10]
+	data := &dtos.RecordedData{
+		Name:           "capture",
+		RecordedEvents: events,
+		Envelopes:      map[string]dtos.EnvelopeMetadata{events[0].Id: {}, events[1].Id: {}},
+		DeadLetters:    []dtos.DeadLetter{{}},
+		Annotations:    []dtos.Annotation{{Label: "start"}},
+	}
+
+	sampling := &exportSampling{fraction: 0.5, seed: 3}
+	sampled, err := sampling.sample(data)
+	require.NoError(t, err)
+	assert.Equal(t, "capture"+sampledNameSuffix, sampled.Name)
+	assert.Len(t, sampled.RecordedEvents, 5)
+	assert.Empty(t, sampled.DeadLetters)
+	assert.Equal(t, data.Annotations, sampled.Annotations)
+	assert.LessOrEqual(t, len(sampled.Envelopes), 2)
+	for id := range sampled.Envelopes {
+		assert.True(t, id == events[0].Id || id == events[1].Id)
+	}
+	assert.Len(t, data.RecordedEvents, 10, "source data must not be modified")
+
+	_, err = sampling.sample(&dtos.RecordedData{Messages: []dtos.OpaqueMessage{{}}})
+	require.ErrorIs(t, err, sampleOpaqueError)
+}
+
+func TestHttpController_ExportRecordedData_Sampled(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Query          string
+		Data           *dtos.RecordedData
+		ExpectedStatus int
+		ExpectedCount  int
+	}{
+		{"Sampled", "?sample=0.1&seed=5", &dtos.RecordedData{RecordedEvents: samplingTestEvents()}, http.StatusOK, 10},
+		{"Stratified", "?sample=0.3&stratify=device", &dtos.RecordedData{RecordedEvents: samplingTestEvents()}, http.StatusOK, 30},
+		{"Bad fraction", "?sample=2", &dtos.RecordedData{}, http.StatusBadRequest, 0},
+		{"Opaque", "?sample=0.5", &dtos.RecordedData{Messages: []dtos.OpaqueMessage{{}}}, http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			mockDataManager.On("ExportRecordedData").Return(test.Data, nil)
+
+			req, err := http.NewRequest(http.MethodGet, dataRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData)).ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code, testRecorder.Body.String())
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), failedSampling)
+				return
+			}
+
+			actual := dtos.RecordedData{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &actual))
+			assert.Len(t, actual.RecordedEvents, test.ExpectedCount)
+		})
+	}
+}
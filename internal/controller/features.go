@@ -0,0 +1,220 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const (
+	featuresFormat = "features"
+
+	// aggregationsParam is the comma separated list of aggregations computed for each resource in each window
+	aggregationsParam = "aggregations"
+
+	featureMin    = "min"
+	featureMax    = "max"
+	featureAvg    = "avg"
+	featureSum    = "sum"
+	featureCount  = "count"
+	featureStdDev = "stddev"
+	featureFirst  = "first"
+	featureLast   = "last"
+)
+
+// featureAggregations are the aggregations that can be computed for the feature vectors
+var featureAggregations = []string{featureMin, featureMax, featureAvg, featureSum, featureCount, featureStdDev,
+	featureFirst, featureLast}
+
+// defaultFeatureAggregations are the aggregations computed when none are specified
+var defaultFeatureAggregations = []string{featureMin, featureMax, featureAvg}
+
+type featureResource struct {
+	deviceName   string
+	resourceName string
+}
+
+type featureKey struct {
+	start    int64
+	resource featureResource
+}
+
+// featureWindow holds the running aggregation of the numeric Readings of a resource within a window
+type featureWindow struct {
+	count      int
+	sum        float64
+	sumSquares float64
+	min        float64
+	max        float64
+	first      float64
+	last       float64
+}
+
+// parseFeatureAggregations returns the aggregations requested by the query parameter, or the defaults if none
+func parseFeatureAggregations(query url.Values) ([]string, error) {
+	value := query.Get(aggregationsParam)
+	if len(value) == 0 {
+		return defaultFeatureAggregations, nil
+	}
+
+	aggregations, err := parseFieldList(value, featureAggregations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %w", aggregationsParam, err)
+	}
+
+	return aggregations, nil
+}
+
+// toFeatureVectors aggregates the numeric Readings from the recorded Events into fixed time windows aligned to
+// multiples of the window size, producing a vector per window with the aggregations of every resource in the
+// recording, so all the vectors have the same features in the same order. The window a Reading falls in is based
+// on its Origin, and the first and last values are by Origin.
+func toFeatureVectors(events []coreDtos.Event, window time.Duration, aggregations []string) *dtos.FeatureVectors {
+	windows := make(map[featureKey]*featureWindow)
+	firstOrigins := make(map[featureKey]int64)
+	lastOrigins := make(map[featureKey]int64)
+	resources := make(map[featureResource]bool)
+	starts := make(map[int64]bool)
+
+	for _, event := range events {
+		for _, reading := range event.Readings {
+			if !isNumericValueType(reading.ValueType) {
+				continue
+			}
+
+			value, err := strconv.ParseFloat(reading.Value, 64)
+			if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+
+			resource := featureResource{deviceName: reading.DeviceName, resourceName: reading.ResourceName}
+			key := featureKey{start: reading.Origin - reading.Origin%int64(window), resource: resource}
+			resources[resource] = true
+			starts[key.start] = true
+
+			aggregate, ok := windows[key]
+			if !ok {
+				aggregate = &featureWindow{min: value, max: value, first: value, last: value}
+				windows[key] = aggregate
+				firstOrigins[key] = reading.Origin
+				lastOrigins[key] = reading.Origin
+			}
+
+			aggregate.count++
+			aggregate.sum += value
+			aggregate.sumSquares += value * value
+			aggregate.min = math.Min(aggregate.min, value)
+			aggregate.max = math.Max(aggregate.max, value)
+			if reading.Origin < firstOrigins[key] {
+				aggregate.first = value
+				firstOrigins[key] = reading.Origin
+			}
+			if reading.Origin >= lastOrigins[key] {
+				aggregate.last = value
+				lastOrigins[key] = reading.Origin
+			}
+		}
+	}
+
+	sortedResources := make([]featureResource, 0, len(resources))
+	for resource := range resources {
+		sortedResources = append(sortedResources, resource)
+	}
+	sort.Slice(sortedResources, func(i, j int) bool {
+		if sortedResources[i].deviceName != sortedResources[j].deviceName {
+			return sortedResources[i].deviceName < sortedResources[j].deviceName
+		}
+		return sortedResources[i].resourceName < sortedResources[j].resourceName
+	})
+
+	result := &dtos.FeatureVectors{
+		Window:   window,
+		Features: make([]string, 0, len(sortedResources)*len(aggregations)),
+		Vectors:  make([]dtos.FeatureVector, 0, len(starts)),
+	}
+
+	for _, resource := range sortedResources {
+		for _, aggregation := range aggregations {
+			result.Features = append(result.Features,
+				strings.Join([]string{resource.deviceName, resource.resourceName, aggregation}, "/"))
+		}
+	}
+
+	sortedStarts := make([]int64, 0, len(starts))
+	for start := range starts {
+		sortedStarts = append(sortedStarts, start)
+	}
+	slices.Sort(sortedStarts)
+
+	for _, start := range sortedStarts {
+		vector := dtos.FeatureVector{Start: start, Values: make([]*float64, 0, len(result.Features))}
+		for _, resource := range sortedResources {
+			aggregate := windows[featureKey{start: start, resource: resource}]
+			for _, aggregation := range aggregations {
+				vector.Values = append(vector.Values, aggregate.value(aggregation))
+			}
+		}
+		result.Vectors = append(result.Vectors, vector)
+	}
+
+	return result
+}
+
+// value returns the aggregation of the window, which is nil for a resource without Readings in the window other
+// than the count, which is 0
+func (w *featureWindow) value(aggregation string) *float64 {
+	if w == nil {
+		if aggregation == featureCount {
+			return new(float64)
+		}
+		return nil
+	}
+
+	var value float64
+	switch aggregation {
+	case featureMin:
+		value = w.min
+	case featureMax:
+		value = w.max
+	case featureAvg:
+		value = w.sum / float64(w.count)
+	case featureSum:
+		value = w.sum
+	case featureCount:
+		value = float64(w.count)
+	case featureStdDev:
+		// The population standard deviation, clamped at 0 since rounding can make the variance slightly negative
+		mean := w.sum / float64(w.count)
+		value = math.Sqrt(math.Max(w.sumSquares/float64(w.count)-mean*mean, 0))
+	case featureFirst:
+		value = w.first
+	case featureLast:
+		value = w.last
+	}
+
+	return &value
+}
@@ -0,0 +1,145 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFeatureAggregations(t *testing.T) {
+	aggregations, err := parseFeatureAggregations(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultFeatureAggregations, aggregations)
+
+	aggregations, err = parseFeatureAggregations(url.Values{aggregationsParam: {"count, stddev,last"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{featureCount, featureStdDev, featureLast}, aggregations)
+
+	_, err = parseFeatureAggregations(url.Values{aggregationsParam: {"median"}})
+	require.Error(t, err)
+}
+
+func newFeatureEvent(t *testing.T, deviceName string, origin time.Duration, resourceValues ...any) coreDtos.Event {
+	event := coreDtos.NewEvent("profile", deviceName, "source")
+	event.Origin = int64(origin)
+	for i := 0; i < len(resourceValues); i += 2 {
+		value := resourceValues[i+1]
+		valueType := common.ValueTypeFloat64
+		if _, ok := value.(string); ok {
+			valueType = common.ValueTypeString
+		}
+		require.NoError(t, event.AddSimpleReading(resourceValues[i].(string), valueType, value))
+	}
+	for i := range event.Readings {
+		event.Readings[i].Origin = int64(origin)
+	}
+
+	return event
+}
+
+func TestToFeatureVectors(t *testing.T) {
+	events := []coreDtos.Event{
+		newFeatureEvent(t, "D1", 5*time.Second, "Temperature", 4.0, "Status", "ok"),
+		// Out of order within the window, so it is the first value by Origin
+		newFeatureEvent(t, "D1", 1*time.Second, "Temperature", 2.0),
+		newFeatureEvent(t, "D2", 2*time.Second, "Pressure", 10.0),
+		newFeatureEvent(t, "D1", 61*time.Second, "Temperature", 6.0),
+	}
+
+	aggregations := []string{featureMin, featureMax, featureAvg, featureSum, featureCount, featureStdDev, featureFirst, featureLast}
+	actual := toFeatureVectors(events, time.Minute, aggregations)
+
+	assert.Equal(t, time.Minute, actual.Window)
+	require.Len(t, actual.Features, 16)
+	assert.Equal(t, "D1/Temperature/min", actual.Features[0])
+	assert.Equal(t, "D2/Pressure/last", actual.Features[15])
+
+	values := func(vector dtos.FeatureVector) []any {
+		var result []any
+		for _, value := range vector.Values {
+			if value == nil {
+				result = append(result, nil)
+				continue
+			}
+			result = append(result, *value)
+		}
+		return result
+	}
+
+	require.Len(t, actual.Vectors, 2)
+	assert.Equal(t, int64(0), actual.Vectors[0].Start)
+	assert.Equal(t, []any{2.0, 4.0, 3.0, 6.0, 2.0, 1.0, 2.0, 4.0, 10.0, 10.0, 10.0, 10.0, 1.0, 0.0, 10.0, 10.0},
+		values(actual.Vectors[0]))
+	assert.Equal(t, int64(time.Minute), actual.Vectors[1].Start)
+	assert.Equal(t, []any{6.0, 6.0, 6.0, 6.0, 1.0, 0.0, 6.0, 6.0, nil, nil, nil, nil, 0.0, nil, nil, nil},
+		values(actual.Vectors[1]))
+
+	empty := toFeatureVectors(nil, time.Minute, defaultFeatureAggregations)
+	assert.Empty(t, empty.Features)
+	assert.Empty(t, empty.Vectors)
+}
+
+func TestHttpController_ExportRecordedData_Features(t *testing.T) {
+	events := []coreDtos.Event{newFeatureEvent(t, "D1", time.Second, "Temperature", 4.0)}
+
+	tests := []struct {
+		Name           string
+		Query          string
+		ExpectedStatus int
+		ExpectedCount  int
+	}{
+		{"Defaults", "?format=features", http.StatusOK, 3},
+		{"Aggregations", "?format=features&window=10s&aggregations=count,sum", http.StatusOK, 2},
+		{"Bad aggregation", "?format=features&aggregations=median", http.StatusBadRequest, 0},
+		{"Bad window", "?format=features&window=0s", http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			mockDataManager.On("ExportRecordedData").Return(&dtos.RecordedData{RecordedEvents: events}, nil)
+
+			req, err := http.NewRequest(http.MethodGet, dataRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData)).ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				return
+			}
+
+			actual := dtos.FeatureVectors{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &actual))
+			assert.Len(t, actual.Features, test.ExpectedCount)
+			require.Len(t, actual.Vectors, 1)
+			assert.Len(t, actual.Vectors[0].Values, test.ExpectedCount)
+		})
+	}
+}
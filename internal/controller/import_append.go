@@ -0,0 +1,77 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// importAppendParam is the optional import query parameter which, when true, appends the imported data after the
+	// recorded data rather than replacing it
+	importAppendParam = "append"
+	// importAppendGapParam is the optional import query parameter with the duration between the last recorded Event
+	// and the first appended Event. Defaults to 0.
+	importAppendGapParam = "gap"
+)
+
+var appendGapWithoutAppend = fmt.Errorf("%s parameter can only be set when %s is true", importAppendGapParam, importAppendParam)
+var invalidAppendGap = errors.New("must be a duration greater than or equal 0")
+var appendOpaqueError = errors.New("opaque recordings can't be appended since their messages aren't decoded")
+
+// importAppend holds the splicing of imported data appended after the recorded data
+type importAppend struct {
+	gap time.Duration
+}
+
+// parseImportAppend returns the append requested by the query parameters, or nil if the imported data replaces the
+// recorded data
+func parseImportAppend(query url.Values) (*importAppend, error) {
+	value := query.Get(importAppendParam)
+	gapValue := query.Get(importAppendGapParam)
+
+	appendData := false
+	if len(value) > 0 {
+		var err error
+		appendData, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter: %v", importAppendParam, err)
+		}
+	}
+
+	if !appendData {
+		if len(gapValue) > 0 {
+			return nil, appendGapWithoutAppend
+		}
+		return nil, nil
+	}
+
+	result := &importAppend{}
+	if len(gapValue) > 0 {
+		gap, err := time.ParseDuration(gapValue)
+		if err != nil || gap < 0 {
+			return nil, fmt.Errorf("invalid %s parameter '%s': %w", importAppendGapParam, gapValue, invalidAppendGap)
+		}
+		result.gap = gap
+	}
+
+	return result, nil
+}
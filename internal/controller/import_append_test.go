@@ -0,0 +1,104 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportAppend(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Query         url.Values
+		Expected      *importAppend
+		ExpectedError bool
+	}{
+		{"None", url.Values{}, nil, false},
+		{"Not appended", url.Values{importAppendParam: {"false"}}, nil, false},
+		{"Appended", url.Values{importAppendParam: {"true"}}, &importAppend{}, false},
+		{"Appended with gap", url.Values{importAppendParam: {"true"}, importAppendGapParam: {"5s"}},
+			&importAppend{gap: 5 * time.Second}, false},
+		{"Bad append", url.Values{importAppendParam: {"bogus"}}, nil, true},
+		{"Bad gap", url.Values{importAppendParam: {"true"}, importAppendGapParam: {"5"}}, nil, true},
+		{"Negative gap", url.Values{importAppendParam: {"true"}, importAppendGapParam: {"-5s"}}, nil, true},
+		{"Gap without append", url.Values{importAppendGapParam: {"5s"}}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := parseImportAppend(test.Query)
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestHttpController_ImportRecordedData_Append(t *testing.T) {
+	tests := []struct {
+		Name            string
+		Data            []byte
+		Query           url.Values
+		ExpectedStatus  int
+		ExpectedMessage string
+		ExpectedGap     time.Duration
+	}{
+		{"Append", marshal(t, archivedData), url.Values{importAppendParam: {"true"}, importAppendGapParam: {"2s"}},
+			http.StatusAccepted, "", 2 * time.Second},
+		{"Bad gap", marshal(t, archivedData), url.Values{importAppendParam: {"true"}, importAppendGapParam: {"soon"}},
+			http.StatusBadRequest, importAppendGapParam, 0},
+		{"Opaque", marshal(t, &dtos.RecordedData{Messages: []dtos.OpaqueMessage{{Payload: []byte("raw")}}}),
+			url.Values{importAppendParam: {"true"}}, http.StatusBadRequest, appendOpaqueError.Error(), 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData))
+			mockDataManager.On("AppendRecordedData", mock.Anything, test.ExpectedGap, true, false).Return(nil).Maybe()
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, dataRoute+"?"+test.Query.Encode(), bytes.NewReader(test.Data)))
+
+			require.Equal(t, test.ExpectedStatus, recorder.Code, recorder.Body.String())
+			assert.Contains(t, recorder.Body.String(), test.ExpectedMessage)
+			mockDataManager.AssertNotCalled(t, "ImportRecordedData", mock.Anything, mock.Anything, mock.Anything)
+			if test.ExpectedStatus != http.StatusAccepted {
+				mockDataManager.AssertNotCalled(t, "AppendRecordedData", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			mockDataManager.AssertCalled(t, "AppendRecordedData", mock.MatchedBy(func(data *dtos.RecordedData) bool {
+				return data.Name == archivedData.Name && len(data.RecordedEvents) == len(archivedData.RecordedEvents)
+			}), test.ExpectedGap, true, false)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const recordedEventsField = "recordedEvents"
+
+// decodeRecordedData decodes the recorded data a token at a time, decoding the recorded Events one at a time so the
+// max Events limit is enforced as the Events are read rather than after the whole payload has been decoded.
+func decodeRecordedData(reader io.Reader, maxEvents int) (*dtos.RecordedData, error) {
+	decoder := json.NewDecoder(reader)
+	data := &dtos.RecordedData{}
+
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+
+	// The remaining fields are small compared to the recorded Events, so are collected and decoded as a whole
+	remaining := make(map[string]json.RawMessage)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token %v, expected field name", token)
+		}
+
+		if !strings.EqualFold(key, recordedEventsField) {
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return nil, err
+			}
+			remaining[key] = value
+			continue
+		}
+
+		data.RecordedEvents, err = decodeRecordedEvents(decoder, maxEvents)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expectDelim(decoder, '}'); err != nil {
+		return nil, err
+	}
+
+	fields, err := json.Marshal(remaining)
+	if err != nil {
+		return nil, err
+	}
+
+	events := data.RecordedEvents
+	if err := json.Unmarshal(fields, data); err != nil {
+		return nil, err
+	}
+	data.RecordedEvents = events
+
+	return data, nil
+}
+
+func decodeRecordedEvents(decoder *json.Decoder, maxEvents int) ([]coreDtos.Event, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	// null is accepted the same as a whole body decode would
+	if token == nil {
+		return nil, nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("unexpected token %v, expected start of %s array", token, recordedEventsField)
+	}
+
+	var events []coreDtos.Event
+	for decoder.More() {
+		if len(events) >= maxEvents {
+			return nil, fmt.Errorf("%w: more than %d recorded events", importLimitExceeded, maxEvents)
+		}
+
+		event := coreDtos.Event{}
+		if err := decoder.Decode(&event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, expectDelim(decoder, ']')
+}
+
+func expectDelim(decoder *json.Decoder, expected json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("unexpected token %v, expected '%s'", token, expected.String())
+	}
+
+	return nil
+}
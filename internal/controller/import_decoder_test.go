@@ -0,0 +1,119 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRecordedData(t *testing.T) {
+	jsonData := readJsonFile(t, "recordedDataJsonUncompressed.json")
+
+	expected := &dtos.RecordedData{}
+	require.NoError(t, json.Unmarshal(jsonData, expected))
+	require.NotEmpty(t, expected.RecordedEvents)
+
+	actual, err := decodeRecordedData(bytes.NewReader(jsonData), len(expected.RecordedEvents))
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	_, err = decodeRecordedData(bytes.NewReader(jsonData), len(expected.RecordedEvents)-1)
+	require.Error(t, err)
+	assert.True(t, isImportLimitError(err))
+}
+
+func TestDecodeRecordedData_Invalid(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Data           string
+		ExpectedEvents int
+		ExpectedError  bool
+	}{
+		{"Null events", `{"recordedEvents":null,"name":"test"}`, 0, false},
+		{"No events", `{"name":"test"}`, 0, false},
+		{"Not an object", `[]`, 0, true},
+		{"Events not an array", `{"recordedEvents":{}}`, 0, true},
+		{"Truncated", `{"recordedEvents":[{"deviceName":"D1"},`, 0, true},
+		{"Bad event", `{"recordedEvents":["bad"]}`, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := decodeRecordedData(strings.NewReader(test.Data), 10)
+			if test.ExpectedError {
+				require.Error(t, err)
+				assert.False(t, isImportLimitError(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "test", actual.Name)
+			assert.Len(t, actual.RecordedEvents, test.ExpectedEvents)
+		})
+	}
+}
+
+func TestHttpController_ImportRecordedData_Limits(t *testing.T) {
+	jsonData := readJsonFile(t, "recordedDataJsonUncompressed.json")
+
+	tests := []struct {
+		Name           string
+		Settings       map[string]string
+		ExpectedStatus int
+	}{
+		{"Within limits", map[string]string{ImportMaxEventsAppSetting: "1000", ImportMaxBytesAppSetting: "1000000"}, http.StatusAccepted},
+		{"Too many events", map[string]string{ImportMaxEventsAppSetting: "1"}, http.StatusRequestEntityTooLarge},
+		{"Too many bytes", map[string]string{ImportMaxBytesAppSetting: "100"}, http.StatusRequestEntityTooLarge},
+		{"Request too large", map[string]string{ImportMaxRequestBytesAppSetting: "100"}, http.StatusRequestEntityTooLarge},
+		{"Invalid setting", map[string]string{ImportMaxBytesAppSetting: "junk"}, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDataManager := &mocks.DataManager{}
+			mockDataManager.On("ImportRecordedData", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			mockSdk := &appMocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("ApplicationSettings").Return(test.Settings)
+
+			target := New(mockDataManager, &mocks.Coordinator{}, nil, mockSdk).(*httpController)
+
+			req, err := http.NewRequest(http.MethodPost, dataRoute, bytes.NewReader(jsonData))
+			require.NoError(t, err)
+			req.Header.Set(common.ContentType, common.ContentTypeJSON)
+
+			testRecorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData)).ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code, testRecorder.Body.String())
+		})
+	}
+}
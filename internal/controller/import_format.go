@@ -0,0 +1,304 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/edgexfoundry/app-record-replay/internal/utils"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	// importFormatParam is the optional import query parameter which overrides the detected format
+	importFormatParam = "format"
+
+	jsonImportFormat   = "json"
+	ndjsonImportFormat = "ndjson"
+	cborImportFormat   = "cbor"
+	zipImportFormat    = "zip"
+
+	// ndjsonPeekSize is how much of the data is examined for a complete first line when detecting NDJSON
+	ndjsonPeekSize = 64 << 10
+	// cborSelfDescribeTag is the optional CBOR tag marking the data as CBOR
+	cborSelfDescribeTag = "\xd9\xd9\xf7"
+	zipMagic            = "PK\x03\x04"
+	gzipMagic           = "\x1f\x8b"
+)
+
+var unknownImportFormat = errors.New("unable to detect the format of the imported data")
+
+// sniffCompression returns the codec for the compression detected from the magic bytes at the start of the data
+func sniffCompression(reader *bufio.Reader) (string, codec, bool) {
+	header, _ := reader.Peek(2)
+	if len(header) < 2 {
+		return "", codec{}, false
+	}
+
+	if string(header) == gzipMagic {
+		return gzipCompression, codecs[gzipCompression], true
+	}
+
+	// zlib uses deflate (low nibble 8) with the header check bits making the first two bytes a multiple of 31. JSON
+	// and CBOR maps never start this way.
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlibCompression, codecs[zlibCompression], true
+	}
+
+	return "", codec{}, false
+}
+
+// isZipArchive returns true if the data starts with the zip local file header signature
+func isZipArchive(reader *bufio.Reader) bool {
+	header, _ := reader.Peek(len(zipMagic))
+	return string(header) == zipMagic
+}
+
+// readZipArchive reads the zip archive, up to the max request bytes already applied to the reader
+func readZipArchive(reader io.Reader) (*zip.Reader, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// openZipEntry opens the recorded data entry in the zip archive. Entries with a recognized extension are preferred,
+// otherwise the first file is used.
+func openZipEntry(archive *zip.Reader, limits importLimits) (io.ReadCloser, error) {
+	var entry *zip.File
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		switch path.Ext(file.Name) {
+		case ".json", ".ndjson", ".cbor":
+			entry = file
+		}
+
+		if entry != nil {
+			break
+		}
+	}
+
+	if entry == nil {
+		for _, file := range archive.File {
+			if !file.FileInfo().IsDir() {
+				entry = file
+				break
+			}
+		}
+	}
+
+	if entry == nil {
+		return nil, errors.New("zip archive contains no files")
+	}
+
+	return openZipFile(entry, limits)
+}
+
+// openZipFile opens the entry in the zip archive once its sizes are checked against the import limits
+func openZipFile(entry *zip.File, limits importLimits) (io.ReadCloser, error) {
+	// The sizes in the archive are checked up front, while the actual uncompressed bytes are limited when read
+	if entry.UncompressedSize64 > uint64(limits.maxBytes) {
+		return nil, fmt.Errorf("%w: zip entry %s exceeds %d bytes", importLimitExceeded, entry.Name, limits.maxBytes)
+	}
+
+	if entry.CompressedSize64 > 0 && entry.UncompressedSize64/entry.CompressedSize64 > uint64(limits.maxCompressionRatio) {
+		return nil, fmt.Errorf("%w: compression ratio exceeds %d to 1", importLimitExceeded, limits.maxCompressionRatio)
+	}
+
+	return entry.Open()
+}
+
+// detectPayloadFormat returns the format of the uncompressed data from its first bytes
+func detectPayloadFormat(reader *bufio.Reader) (string, error) {
+	for {
+		first, err := reader.Peek(1)
+		if errors.Is(err, io.EOF) {
+			return "", unknownImportFormat
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case first[0] == ' ' || first[0] == '\t' || first[0] == '\r' || first[0] == '\n':
+			_, _ = reader.ReadByte()
+			continue
+		case first[0] == '{':
+			if isNDJSON(reader) {
+				return ndjsonImportFormat, nil
+			}
+			return jsonImportFormat, nil
+		case first[0] >= 0xa0 && first[0] <= 0xbf:
+			// CBOR map
+			return cborImportFormat, nil
+		}
+
+		if tag, _ := reader.Peek(len(cborSelfDescribeTag)); string(tag) == cborSelfDescribeTag {
+			return cborImportFormat, nil
+		}
+
+		return "", unknownImportFormat
+	}
+}
+
+// isNDJSON returns true if the first line of the data is a complete JSON value followed by another line. A JSON
+// object spread over multiple lines never has a complete first line, while a single line JSON object has nothing
+// after it. Data whose first line is longer than the peek size is treated as a JSON object.
+func isNDJSON(reader *bufio.Reader) bool {
+	peeked, _ := reader.Peek(ndjsonPeekSize)
+
+	line, rest, found := bytes.Cut(peeked, []byte("\n"))
+	if !found || !json.Valid(line) {
+		return false
+	}
+
+	return len(bytes.TrimSpace(rest)) > 0
+}
+
+// decodeImportedData decodes the recorded data in the format, with the Events of recorded data stored as reading
+// tuples expanded back into its recorded Events
+func decodeImportedData(reader io.Reader, format string, maxEvents int) (*dtos.RecordedData, error) {
+	var data *dtos.RecordedData
+	var err error
+
+	switch format {
+	case jsonImportFormat:
+		data, err = decodeRecordedData(reader, maxEvents)
+	case ndjsonImportFormat:
+		data, err = decodeNDJSONRecordedData(reader, maxEvents)
+	case cborImportFormat:
+		data, err = decodeCBORRecordedData(reader, maxEvents)
+	default:
+		return nil, fmt.Errorf("import format not available: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := expandReadingsOnly(data, maxEvents); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// expandReadingsOnly moves the Events of recorded data stored as the reading tuples of a readings-only recording into
+// its recorded Events, still stripped down to their readings, since the full Events are only reconstructed when
+// replayed. An error is returned if there are more than the maximum Events in total.
+func expandReadingsOnly(data *dtos.RecordedData, maxEvents int) error {
+	if len(data.ReadingsOnlyEvents) == 0 {
+		return nil
+	}
+
+	if len(data.RecordedEvents)+len(data.ReadingsOnlyEvents) > maxEvents {
+		return fmt.Errorf("%w: more than %d recorded events", importLimitExceeded, maxEvents)
+	}
+
+	data.RecordedEvents = append(data.RecordedEvents, utils.ExpandEvents(data.ReadingsOnlyEvents)...)
+	data.ReadingsOnlyEvents = nil
+	return nil
+}
+
+// decodeNDJSONRecordedData decodes the recorded data from newline delimited JSON. Lines with readings are decoded as
+// recorded Events and all other lines as recorded data, whose Devices, Profiles, Events and Messages are appended,
+// so the Devices and Profiles can be given in one or more lines along with the Events.
+func decodeNDJSONRecordedData(reader io.Reader, maxEvents int) (*dtos.RecordedData, error) {
+	decoder := json.NewDecoder(reader)
+	data := &dtos.RecordedData{}
+
+	for line := 1; decoder.More(); line++ {
+		var raw map[string]json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		encoded, err := json.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, isEvent := raw["readings"]; isEvent {
+			event := coreDtos.Event{}
+			if err := json.Unmarshal(encoded, &event); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			data.RecordedEvents = append(data.RecordedEvents, event)
+		} else {
+			lineData := dtos.RecordedData{}
+			if err := json.Unmarshal(encoded, &lineData); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			mergeRecordedData(data, lineData)
+		}
+
+		if len(data.RecordedEvents) > maxEvents {
+			return nil, fmt.Errorf("%w: more than %d recorded events", importLimitExceeded, maxEvents)
+		}
+	}
+
+	return data, nil
+}
+
+func mergeRecordedData(data *dtos.RecordedData, other dtos.RecordedData) {
+	if len(other.Name) > 0 {
+		data.Name = other.Name
+	}
+
+	data.RecordedEvents = append(data.RecordedEvents, other.RecordedEvents...)
+	data.Devices = append(data.Devices, other.Devices...)
+	data.Profiles = append(data.Profiles, other.Profiles...)
+	data.Messages = append(data.Messages, other.Messages...)
+	data.DeadLetters = append(data.DeadLetters, other.DeadLetters...)
+	data.Telemetry = append(data.Telemetry, other.Telemetry...)
+	data.SystemEvents = append(data.SystemEvents, other.SystemEvents...)
+	data.Annotations = append(data.Annotations, other.Annotations...)
+
+	for id, envelope := range other.Envelopes {
+		if data.Envelopes == nil {
+			data.Envelopes = make(map[string]dtos.EnvelopeMetadata)
+		}
+		data.Envelopes[id] = envelope
+	}
+}
+
+// decodeCBORRecordedData decodes the recorded data from CBOR, which uses the same field names as JSON
+func decodeCBORRecordedData(reader io.Reader, maxEvents int) (*dtos.RecordedData, error) {
+	data := &dtos.RecordedData{}
+	if err := cbor.NewDecoder(reader).Decode(data); err != nil {
+		return nil, err
+	}
+
+	if len(data.RecordedEvents) > maxEvents {
+		return nil, fmt.Errorf("%w: more than %d recorded events", importLimitExceeded, maxEvents)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,118 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectPayloadFormat(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Data           []byte
+		ExpectedFormat string
+		ExpectedError  bool
+	}{
+		{"JSON object", []byte(`{"recordedEvents":[]}`), jsonImportFormat, false},
+		{"JSON object with trailing newline", []byte("{\"recordedEvents\":[]}\n"), jsonImportFormat, false},
+		{"Indented JSON object", []byte("\n  {\n  \"recordedEvents\": []\n}\n"), jsonImportFormat, false},
+		{"NDJSON", []byte("{\"devices\":[]}\n{\"readings\":[]}\n"), ndjsonImportFormat, false},
+		{"CBOR map", []byte{0xa2, 0x01}, cborImportFormat, false},
+		{"CBOR self describe tag", []byte{0xd9, 0xd9, 0xf7, 0xa1}, cborImportFormat, false},
+		{"Empty", []byte{}, "", true},
+		{"Text", []byte("recorded data"), "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			format, err := detectPayloadFormat(bufio.NewReader(bytes.NewReader(test.Data)))
+			if test.ExpectedError {
+				require.Equal(t, unknownImportFormat, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedFormat, format)
+		})
+	}
+}
+
+func TestSniffCompression(t *testing.T) {
+	data := []byte(`{"recordedEvents":[]}`)
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			compressed, err := codec.compress(data)
+			require.NoError(t, err)
+
+			actual, _, ok := sniffCompression(bufio.NewReader(bytes.NewReader(compressed)))
+			require.True(t, ok)
+			assert.Equal(t, name, actual)
+		})
+	}
+
+	_, _, ok := sniffCompression(bufio.NewReader(bytes.NewReader(data)))
+	assert.False(t, ok)
+}
+
+func TestDecodeNDJSONRecordedData(t *testing.T) {
+	data := strings.Join([]string{
+		`{"name":"ndjson","devices":[{"name":"D1"}]}`,
+		`{"deviceName":"D1","readings":[{"resourceName":"R1","value":"1"}]}`,
+		`{"profiles":[{"name":"P1"}]}`,
+		`{"deviceName":"D1","readings":[{"resourceName":"R1","value":"2"}]}`,
+	}, "\n")
+
+	actual, err := decodeNDJSONRecordedData(strings.NewReader(data), 10)
+	require.NoError(t, err)
+	assert.Equal(t, "ndjson", actual.Name)
+	assert.Equal(t, []coreDtos.Device{{Name: "D1"}}, actual.Devices)
+	assert.Equal(t, []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "P1"}}}, actual.Profiles)
+	require.Len(t, actual.RecordedEvents, 2)
+	assert.Equal(t, "2", actual.RecordedEvents[1].Readings[0].Value)
+
+	_, err = decodeNDJSONRecordedData(strings.NewReader(data), 1)
+	require.Error(t, err)
+	assert.True(t, isImportLimitError(err))
+
+	_, err = decodeNDJSONRecordedData(strings.NewReader("{\"name\":\"a\"}\n{bad"), 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestDecodeCBORRecordedData(t *testing.T) {
+	data := dtos.RecordedData{
+		Name:           "cbor",
+		RecordedEvents: []coreDtos.Event{coreDtos.NewEvent("P1", "D1", "S1"), coreDtos.NewEvent("P1", "D1", "S1")},
+	}
+
+	actual, err := decodeCBORRecordedData(bytes.NewReader(cborData(t, data)), 10)
+	require.NoError(t, err)
+	assert.Equal(t, data.Name, actual.Name)
+	assert.Equal(t, data.RecordedEvents[1].Id, actual.RecordedEvents[1].Id)
+
+	_, err = decodeCBORRecordedData(bytes.NewReader(cborData(t, data)), 1)
+	assert.True(t, isImportLimitError(err))
+}
@@ -0,0 +1,162 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	// ImportMaxEventsAppSetting is the maximum number of recorded Events accepted by an import
+	ImportMaxEventsAppSetting = "ImportMaxEvents"
+	// ImportMaxBytesAppSetting is the maximum size in bytes of the uncompressed data accepted by an import
+	ImportMaxBytesAppSetting = "ImportMaxBytes"
+	// ImportMaxRequestBytesAppSetting is the maximum size in bytes of the import request body as sent, i.e. compressed
+	ImportMaxRequestBytesAppSetting = "ImportMaxRequestBytes"
+	// ImportMaxCompressionRatioAppSetting is the maximum ratio of uncompressed to compressed bytes accepted by a
+	// compressed import, which protects against zip bombs
+	ImportMaxCompressionRatioAppSetting = "ImportMaxCompressionRatio"
+
+	defaultImportMaxEvents           = 1000000
+	defaultImportMaxBytes            = 1 << 30
+	defaultImportMaxRequestBytes     = 256 << 20
+	defaultImportMaxCompressionRatio = 100
+
+	// The compression ratio is only checked once this many bytes have been uncompressed, since the start of
+	// the data can legitimately compress far better than the data as a whole.
+	compressionRatioMinBytes = 1 << 20
+)
+
+var importLimitExceeded = errors.New("import limit exceeded")
+
+type importLimits struct {
+	maxEvents           int64
+	maxBytes            int64
+	maxRequestBytes     int64
+	maxCompressionRatio int64
+}
+
+// getImportLimits returns the configured limits for an import, using the defaults for those not configured
+func (c *httpController) getImportLimits() (importLimits, error) {
+	settings := c.appSdk.ApplicationSettings()
+	limits := importLimits{}
+
+	for _, limit := range []struct {
+		name         string
+		defaultValue int64
+		value        *int64
+	}{
+		{ImportMaxEventsAppSetting, defaultImportMaxEvents, &limits.maxEvents},
+		{ImportMaxBytesAppSetting, defaultImportMaxBytes, &limits.maxBytes},
+		{ImportMaxRequestBytesAppSetting, defaultImportMaxRequestBytes, &limits.maxRequestBytes},
+		{ImportMaxCompressionRatioAppSetting, defaultImportMaxCompressionRatio, &limits.maxCompressionRatio},
+	} {
+		*limit.value = limit.defaultValue
+
+		setting := settings[limit.name]
+		if len(setting) == 0 {
+			continue
+		}
+
+		value, err := strconv.ParseInt(setting, 10, 64)
+		if err != nil || value <= 0 {
+			return importLimits{}, fmt.Errorf("invalid %s value '%s', must be an integer greater than 0", limit.name, setting)
+		}
+		*limit.value = value
+	}
+
+	return limits, nil
+}
+
+// limitedReader fails once more than the max bytes have been read
+type limitedReader struct {
+	io.ReadCloser
+	description string
+	maxBytes    int64
+	read        int64
+}
+
+// limitImportReader returns a reader that fails with an import limit error once more than maxBytes have been read
+func limitImportReader(reader io.ReadCloser, maxBytes int64, description string) io.ReadCloser {
+	return &limitedReader{ReadCloser: reader, description: description, maxBytes: maxBytes}
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.read > r.maxBytes {
+		return 0, fmt.Errorf("%w: %s exceeds %d bytes", importLimitExceeded, r.description, r.maxBytes)
+	}
+
+	// Reading one byte past the max detects data that exceeds the max, rather than just reaching it
+	if remaining := r.maxBytes - r.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if r.read > r.maxBytes {
+		// No data is returned with the error since some decoders ignore errors returned along with data
+		return 0, fmt.Errorf("%w: %s exceeds %d bytes", importLimitExceeded, r.description, r.maxBytes)
+	}
+
+	return n, err
+}
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// compressionRatioReader fails once the bytes uncompressed exceed the max ratio of the compressed bytes read
+type compressionRatioReader struct {
+	io.ReadCloser
+	compressed   *countingReader
+	uncompressed int64
+	maxRatio     int64
+	err          error
+}
+
+func (r *compressionRatioReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	r.uncompressed += int64(n)
+
+	if r.uncompressed > compressionRatioMinBytes && r.uncompressed > r.compressed.count*r.maxRatio {
+		// No data is returned with the error since some decoders ignore errors returned along with data
+		r.err = fmt.Errorf("%w: compression ratio exceeds %d to 1", importLimitExceeded, r.maxRatio)
+		return 0, r.err
+	}
+
+	return n, err
+}
+
+// isImportLimitError returns true if the error is due to the import exceeding any of the import limits
+func isImportLimitError(err error) bool {
+	return errors.Is(err, importLimitExceeded)
+}
@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitImportReader(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Data          string
+		MaxBytes      int64
+		ExpectedError bool
+	}{
+		{"Under limit", "12345", 10, false},
+		{"At limit", "1234567890", 10, false},
+		{"Over limit", "12345678901", 10, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			reader := limitImportReader(io.NopCloser(strings.NewReader(test.Data)), test.MaxBytes, "test data")
+			actual, err := io.ReadAll(reader)
+			if test.ExpectedError {
+				require.Error(t, err)
+				assert.True(t, isImportLimitError(err))
+				assert.Contains(t, err.Error(), "test data exceeds 10 bytes")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Data, string(actual))
+		})
+	}
+}
+
+func TestHttpController_ImportRecordedData_ZipBomb(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	// Valid JSON, so only the compression ratio can fail the import
+	_, err := writer.Write([]byte("{" + strings.Repeat(" ", 4*compressionRatioMinBytes) + "}"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	target, _, _ := createTargetAndMocks()
+
+	// No Content-Length, so the request body limit is only enforced while reading
+	req, err := http.NewRequest(http.MethodPost, dataRoute, io.NopCloser(buffer))
+	require.NoError(t, err)
+	req.Header.Set(common.ContentType, common.ContentTypeJSON)
+	req.Header.Set("Content-Encoding", contentEncodingGzip)
+
+	testRecorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData)).ServeHTTP(testRecorder, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, testRecorder.Code, testRecorder.Body.String())
+	assert.Contains(t, testRecorder.Body.String(), "compression ratio exceeds")
+}
@@ -0,0 +1,112 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ImportPathsAppSetting is the comma separated list of local directories recordings staged on the gateway may be
+// imported from, so they don't need to be streamed through the request. Imports from the local filesystem are
+// disabled when not set.
+const ImportPathsAppSetting = "ImportPaths"
+
+const (
+	// importPathParam is the optional import query parameter with the path of the local file to import
+	importPathParam = "path"
+	// maxSignatureFileBytes is the most read from the signature file of a local import, which is far more than the
+	// encoded signature written by a signed local export
+	maxSignatureFileBytes = 1024
+)
+
+var localImportNotFile = errors.New("path isn't a regular file")
+var signatureFileTooLarge = fmt.Errorf("signature file exceeds %d bytes", maxSignatureFileBytes)
+
+// openLocalImportFile opens the local file to import, which must be within one of the directories allow-listed by
+// the ImportPaths App Setting. The signature is read from the file alongside it with a .sig extension, as written by
+// a signed local export, or is empty if there isn't one.
+func (c *httpController) openLocalImportFile(path string) (*os.File, string, error) {
+	path, err := c.allowedLocalPath(ImportPathsAppSetting, path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil, "", fmt.Errorf("%w: %s", localImportNotFile, path)
+	}
+
+	signature, err := c.readLocalImportSignature(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return file, signature, nil
+}
+
+// readLocalImportSignature returns the signature from the file alongside the local import file with a .sig extension,
+// or empty if there isn't one. The signature file is checked against the ImportPaths App Setting the same as the
+// import file, so a symbolic link can't be used to read outside the allow-listed directories, and only a limited
+// amount of it is read.
+func (c *httpController) readLocalImportSignature(path string) (string, error) {
+	signaturePath := path + signatureFileExtension
+	if _, err := os.Lstat(signaturePath); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	signaturePath, err := c.allowedLocalPath(ImportPathsAppSetting, signaturePath)
+	if err != nil {
+		return "", fmt.Errorf("unable to read signature of %s: %w", path, err)
+	}
+
+	file, err := os.Open(signaturePath)
+	if err != nil {
+		return "", fmt.Errorf("unable to read signature of %s: %v", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("unable to read signature of %s: %v", path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("unable to read signature of %s: %w: %s", path, localImportNotFile, signaturePath)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSignatureFileBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("unable to read signature of %s: %v", path, err)
+	}
+	if len(data) > maxSignatureFileBytes {
+		return "", fmt.Errorf("unable to read signature of %s: %w", path, signatureFileTooLarge)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,139 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_ImportRecordedData_LocalPath(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	recordedData := dtos.RecordedData{
+		Name:           "line-1",
+		RecordedEvents: []coreDtos.Event{{DeviceName: "test", ProfileName: "test"}},
+		Devices:        []coreDtos.Device{{Name: "test", ProfileName: "test"}},
+		Profiles:       []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "test"}}},
+	}
+	data, err := json.Marshal(recordedData)
+	require.NoError(t, err)
+
+	stagingDir := t.TempDir()
+	otherDir := t.TempDir()
+	writeFile := func(dir string, name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0640))
+		return path
+	}
+
+	compressed := &bytes.Buffer{}
+	writer := gzip.NewWriter(compressed)
+	_, err = writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	plainPath := writeFile(stagingDir, "line-1.json", data)
+	compressedPath := writeFile(stagingDir, "line-1.json.gzip", compressed.Bytes())
+	signedPath := writeFile(stagingDir, "signed.json", data)
+	tamperedPath := writeFile(stagingDir, "tampered.json", bytes.Replace(data, []byte("test"), []byte("fake"), 1))
+	outsidePath := writeFile(otherDir, "line-1.json", data)
+	linkPath := filepath.Join(stagingDir, "link.json")
+	require.NoError(t, os.Symlink(outsidePath, linkPath))
+
+	signingSdk := &appMocks.ApplicationService{}
+	signingSdk.On("LoggingClient").Return(logger.NewMockClient())
+	signingSdk.On("SecretProvider").Return(createMockSecretProvider(privateKey, publicKey, nil))
+	signature, err := New(nil, nil, nil, signingSdk).(*httpController).signData(data)
+	require.NoError(t, err)
+	writeFile(stagingDir, "signed.json"+signatureFileExtension, []byte(signature+"\n"))
+	writeFile(stagingDir, "tampered.json"+signatureFileExtension, []byte(signature))
+	// The signature file is subject to the same checks as the file imported
+	signatureLinkPath := writeFile(stagingDir, "signature-link.json", data)
+	require.NoError(t, os.Symlink(writeFile(otherDir, "signature.sig", []byte(signature)), signatureLinkPath+signatureFileExtension))
+	largeSignaturePath := writeFile(stagingDir, "large-signature.json", data)
+	writeFile(stagingDir, "large-signature.json"+signatureFileExtension, bytes.Repeat([]byte("A"), maxSignatureFileBytes+1))
+
+	tests := []struct {
+		Name           string
+		ImportPaths    string
+		Path           string
+		ExpectedStatus int
+	}{
+		{"Plain", stagingDir, plainPath, http.StatusAccepted},
+		{"Compressed", stagingDir, compressedPath, http.StatusAccepted},
+		{"Signed", stagingDir, signedPath, http.StatusAccepted},
+		{"Tampered", stagingDir, tamperedPath, http.StatusBadRequest},
+		{"Disabled", "", plainPath, http.StatusForbidden},
+		{"Outside allowed dirs", stagingDir, outsidePath, http.StatusForbidden},
+		{"Symbolic link escape", stagingDir, linkPath, http.StatusForbidden},
+		{"Signature symbolic link escape", stagingDir, signatureLinkPath, http.StatusForbidden},
+		{"Signature too large", stagingDir, largeSignaturePath, http.StatusBadRequest},
+		{"Directory", stagingDir, stagingDir, http.StatusBadRequest},
+		{"Not found", stagingDir, filepath.Join(stagingDir, "missing.json"), http.StatusBadRequest},
+		{"Relative path", stagingDir, "line-1.json", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockDataManager := &mocks.DataManager{}
+			mockDataManager.On("ImportRecordedData", mock.Anything, true, false).Return(nil)
+			mockSdk := &appMocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ImportPathsAppSetting: test.ImportPaths})
+			mockSdk.On("SecretProvider").Return(createMockSecretProvider(privateKey, publicKey, nil))
+			target := New(mockDataManager, nil, nil, mockSdk).(*httpController)
+
+			// The request body is ignored when importing from a local file
+			req, err := http.NewRequest(http.MethodPost, dataRoute+"?"+importPathParam+"="+url.QueryEscape(test.Path), bytes.NewReader([]byte("ignored")))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData)).ServeHTTP(recorder, req)
+			require.Equal(t, test.ExpectedStatus, recorder.Code, recorder.Body.String())
+
+			if test.ExpectedStatus != http.StatusAccepted {
+				mockDataManager.AssertNotCalled(t, "ImportRecordedData", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			mockDataManager.AssertCalled(t, "ImportRecordedData", mock.MatchedBy(func(data *dtos.RecordedData) bool {
+				return data.Name == recordedData.Name && len(data.RecordedEvents) == 1
+			}), true, false)
+			assert.Empty(t, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,162 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+// importTransformParam is the optional import query parameter with the JSON encoded ImportTransform applied to the
+// imported data
+const importTransformParam = "transform"
+
+var transformOpaqueError = errors.New("opaque recordings can't be transformed since their messages aren't decoded")
+
+// importTransform holds the transformations applied to the imported data
+type importTransform struct {
+	dtos.ImportTransform
+}
+
+// parseImportTransform returns the transform requested by the query parameters, or nil if none was requested
+func parseImportTransform(query url.Values) (*importTransform, error) {
+	value := query.Get(importTransformParam)
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	transform := &importTransform{}
+	if err := json.Unmarshal([]byte(value), &transform.ImportTransform); err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %v", importTransformParam, err)
+	}
+
+	if err := checkRenames("device", transform.DeviceNames); err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %w", importTransformParam, err)
+	}
+
+	if err := checkRenames("profile", transform.ProfileNames); err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %w", importTransformParam, err)
+	}
+
+	for name := range transform.Tags {
+		if len(name) == 0 {
+			return nil, fmt.Errorf("invalid %s parameter: tag names must not be empty", importTransformParam)
+		}
+	}
+
+	return transform, nil
+}
+
+func checkRenames(kind string, renames map[string]string) error {
+	for from, to := range renames {
+		if len(from) == 0 || len(to) == 0 {
+			return fmt.Errorf("%s names must not be empty, got '%s' renamed to '%s'", kind, from, to)
+		}
+	}
+
+	return nil
+}
+
+// deviceName returns the local name of the device
+func (t *importTransform) deviceName(name string) string {
+	if renamed, found := t.DeviceNames[name]; found {
+		return renamed
+	}
+
+	return name
+}
+
+// profileName returns the local name of the profile
+func (t *importTransform) profileName(name string) string {
+	if renamed, found := t.ProfileNames[name]; found {
+		return renamed
+	}
+
+	return name
+}
+
+// profileNames returns the local names of the profiles
+func (t *importTransform) profileNames(names []string) []string {
+	renamed := make([]string, len(names))
+	for i, name := range names {
+		renamed[i] = t.profileName(name)
+	}
+
+	return renamed
+}
+
+// apply renames the Devices and Device Profiles of the imported data, along with the Events and Readings referencing
+// them, and adds the tags to the Events. An error is returned if renaming would merge two Devices or Device Profiles.
+func (t *importTransform) apply(data *dtos.RecordedData) error {
+	if len(data.Messages) > 0 {
+		return transformOpaqueError
+	}
+
+	devices := make(map[string]string, len(data.Devices))
+	for i := range data.Devices {
+		device := &data.Devices[i]
+		name := t.deviceName(device.Name)
+		if original, found := devices[name]; found {
+			return fmt.Errorf("devices %s and %s would both be named %s", original, device.Name, name)
+		}
+		devices[name] = device.Name
+
+		device.Name = name
+		device.ProfileName = t.profileName(device.ProfileName)
+	}
+
+	profiles := make(map[string]string, len(data.Profiles))
+	for i := range data.Profiles {
+		profile := &data.Profiles[i]
+		name := t.profileName(profile.Name)
+		if original, found := profiles[name]; found {
+			return fmt.Errorf("profiles %s and %s would both be named %s", original, profile.Name, name)
+		}
+		profiles[name] = profile.Name
+
+		profile.Name = name
+	}
+
+	for i := range data.RecordedEvents {
+		event := &data.RecordedEvents[i]
+		event.DeviceName = t.deviceName(event.DeviceName)
+		event.ProfileName = t.profileName(event.ProfileName)
+
+		if len(t.Tags) > 0 {
+			tags := make(coreDtos.Tags, len(event.Tags)+len(t.Tags))
+			maps.Copy(tags, event.Tags)
+			maps.Copy(tags, t.Tags)
+			event.Tags = tags
+		}
+
+		readings := make([]coreDtos.BaseReading, len(event.Readings))
+		for j, reading := range event.Readings {
+			reading.DeviceName = t.deviceName(reading.DeviceName)
+			reading.ProfileName = t.profileName(reading.ProfileName)
+			readings[j] = reading
+		}
+		event.Readings = readings
+	}
+
+	return nil
+}
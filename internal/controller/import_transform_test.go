@@ -0,0 +1,190 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportTransform(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Query         url.Values
+		Expected      *importTransform
+		ExpectedError bool
+	}{
+		{"None", url.Values{}, nil, false},
+		{"Renames and tags", url.Values{importTransformParam: {`{"deviceNames":{"a":"b"},"profileNames":{"c":"d"},"tags":{"site":"B"}}`}},
+			&importTransform{dtos.ImportTransform{
+				DeviceNames:  map[string]string{"a": "b"},
+				ProfileNames: map[string]string{"c": "d"},
+				Tags:         map[string]any{"site": "B"},
+			}}, false},
+		{"Bad JSON", url.Values{importTransformParam: {"bogus"}}, nil, true},
+		{"Empty device name", url.Values{importTransformParam: {`{"deviceNames":{"a":""}}`}}, nil, true},
+		{"Empty profile name", url.Values{importTransformParam: {`{"profileNames":{"":"d"}}`}}, nil, true},
+		{"Empty tag name", url.Values{importTransformParam: {`{"tags":{"":"B"}}`}}, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			actual, err := parseImportTransform(test.Query)
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func transformTestData(t *testing.T) *dtos.RecordedData {
+	event := coreDtos.NewEvent("profile-1", "device-1", "source")
+	event.Tags = map[string]any{"site": "A", "line": "1"}
+	require.NoError(t, event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21)))
+
+	other := coreDtos.NewEvent("profile-2", "device-2", "source")
+	require.NoError(t, other.AddSimpleReading("Humidity", common.ValueTypeInt32, int32(50)))
+
+	return &dtos.RecordedData{
+		RecordedEvents: []coreDtos.Event{event, other},
+		Devices: []coreDtos.Device{
+			{Name: "device-1", ProfileName: "profile-1"},
+			{Name: "device-2", ProfileName: "profile-2"},
+		},
+		Profiles: []coreDtos.DeviceProfile{
+			{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "profile-1"}},
+			{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "profile-2"}},
+		},
+	}
+}
+
+func TestImportTransform_Apply(t *testing.T) {
+	data := transformTestData(t)
+	recordedTags := data.RecordedEvents[0].Tags
+
+	transform := &importTransform{dtos.ImportTransform{
+		DeviceNames:  map[string]string{"device-1": "local-device-1"},
+		ProfileNames: map[string]string{"profile-1": "local-profile-1"},
+		Tags:         map[string]any{"site": "B"},
+	}}
+	require.NoError(t, transform.apply(data))
+
+	assert.Equal(t, "local-device-1", data.Devices[0].Name)
+	assert.Equal(t, "local-profile-1", data.Devices[0].ProfileName)
+	assert.Equal(t, "device-2", data.Devices[1].Name)
+	assert.Equal(t, "profile-2", data.Devices[1].ProfileName)
+	assert.Equal(t, "local-profile-1", data.Profiles[0].Name)
+	assert.Equal(t, "profile-2", data.Profiles[1].Name)
+
+	event := data.RecordedEvents[0]
+	assert.Equal(t, "local-device-1", event.DeviceName)
+	assert.Equal(t, "local-profile-1", event.ProfileName)
+	assert.Equal(t, "local-device-1", event.Readings[0].DeviceName)
+	assert.Equal(t, "local-profile-1", event.Readings[0].ProfileName)
+	assert.Equal(t, coreDtos.Tags{"site": "B", "line": "1"}, event.Tags)
+	assert.Equal(t, coreDtos.Tags{"site": "B"}, data.RecordedEvents[1].Tags)
+	assert.Equal(t, "device-2", data.RecordedEvents[1].Readings[0].DeviceName)
+
+	// The decoded tags may be shared, so are copied rather than modified
+	assert.Equal(t, "A", recordedTags["site"])
+}
+
+func TestImportTransform_Apply_Errors(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Transform     dtos.ImportTransform
+		Data          *dtos.RecordedData
+		ExpectedError string
+	}{
+		{"Merged devices", dtos.ImportTransform{DeviceNames: map[string]string{"device-1": "device-2"}},
+			transformTestData(t), "would both be named device-2"},
+		{"Merged profiles", dtos.ImportTransform{ProfileNames: map[string]string{"profile-1": "local", "profile-2": "local"}},
+			transformTestData(t), "would both be named local"},
+		{"Opaque", dtos.ImportTransform{Tags: map[string]any{"site": "B"}},
+			&dtos.RecordedData{Messages: []dtos.OpaqueMessage{{}}}, transformOpaqueError.Error()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			transform := &importTransform{test.Transform}
+			err := transform.apply(test.Data)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.ExpectedError)
+		})
+	}
+}
+
+func TestHttpController_ImportRecordedData_Transform(t *testing.T) {
+	recording := marshal(t, archivedData)
+	archive := archiveData(t, newArchiveManifest(archivedData, recording), recording)
+
+	tests := []struct {
+		Name            string
+		Data            []byte
+		Transform       string
+		ExpectedStatus  int
+		ExpectedMessage string
+		ExpectedDevice  string
+	}{
+		{"JSON", marshal(t, archivedData), `{"deviceNames":{"device-1":"local-device-1"}}`, http.StatusAccepted, "", "local-device-1"},
+		// The manifest lists the archived names, while the missing dependencies are the local names
+		{"Archive", archive, `{"deviceNames":{"device-1":"local-device-1"},"profileNames":{"profile-2":"local-profile-2"}}`,
+			http.StatusAccepted, "", "local-device-1"},
+		{"Bad transform", marshal(t, archivedData), "bogus", http.StatusBadRequest, failedImportTransform, ""},
+		{"Merged devices", marshal(t, archivedData), `{"deviceNames":{"device-1":"device-2"}}`,
+			http.StatusBadRequest, "would both be named device-2", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData))
+			mockDataManager.On("MissingDependencies", []string{"local-profile-2"}, []string{"service-1"}).
+				Return(&dtos.MissingDependencies{}, nil).Maybe()
+			mockDataManager.On("ImportRecordedData", mock.Anything, true, false).Return(nil).Maybe()
+
+			query := url.Values{importTransformParam: {test.Transform}}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, dataRoute+"?"+query.Encode(), bytes.NewReader(test.Data)))
+
+			require.Equal(t, test.ExpectedStatus, recorder.Code, recorder.Body.String())
+			assert.Contains(t, recorder.Body.String(), test.ExpectedMessage)
+			if test.ExpectedStatus != http.StatusAccepted {
+				mockDataManager.AssertNotCalled(t, "ImportRecordedData", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			mockDataManager.AssertCalled(t, "ImportRecordedData", mock.MatchedBy(func(data *dtos.RecordedData) bool {
+				return data.RecordedEvents[0].DeviceName == test.ExpectedDevice && data.Devices[0].Name == test.ExpectedDevice
+			}), true, false)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/labstack/echo/v4"
+)
+
+// inject publishes the one-off Events and raw messages in the request immediately, returning the number published as
+// the HTTP response
+func (c *httpController) inject(ctx echo.Context) error {
+	request := &dtos.InjectRequest{}
+
+	if err := json.NewDecoder(ctx.Request().Body).Decode(request); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	if !validInjectRequest(request) {
+		return ctx.String(http.StatusBadRequest, failedInjectRequestValidate)
+	}
+
+	request.CorrelationID = correlationID(ctx)
+	response, err := c.dataManager.Inject(*request)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s after %d Events and %d messages: %v",
+			failedInject, response.PublishedEventCount, response.PublishedMessageCount, err))
+	}
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal inject response: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// validInjectRequest returns true if the request has something to publish and each message has a topic
+func validInjectRequest(request *dtos.InjectRequest) bool {
+	if len(request.Events) == 0 && len(request.Messages) == 0 {
+		return false
+	}
+
+	for _, message := range request.Messages {
+		if len(message.Topic) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_Inject(t *testing.T) {
+	event := coreDtos.NewEvent("P1", "D1", "S1")
+	validRequest := dtos.InjectRequest{
+		Events:   []coreDtos.Event{event},
+		Messages: []dtos.InjectMessage{{Topic: "events/device/bad", Payload: []byte("{not json")}},
+	}
+
+	tests := []struct {
+		Name             string
+		Request          any
+		InjectResponse   dtos.InjectResponse
+		InjectError      error
+		ExpectedStatus   int
+		ExpectedResponse string
+	}{
+		{"Valid", validRequest, dtos.InjectResponse{PublishedEventCount: 1, PublishedMessageCount: 1}, nil,
+			http.StatusOK, `{"publishedEventCount":1,"publishedMessageCount":1}`},
+		{"Bad JSON", "bad", dtos.InjectResponse{}, nil, http.StatusBadRequest, failedRequestJSON},
+		{"Nothing to inject", dtos.InjectRequest{}, dtos.InjectResponse{}, nil,
+			http.StatusBadRequest, failedInjectRequestValidate},
+		{"Message missing topic", dtos.InjectRequest{Messages: []dtos.InjectMessage{{Payload: []byte("x")}}},
+			dtos.InjectResponse{}, nil, http.StatusBadRequest, failedInjectRequestValidate},
+		{"Publish fails", validRequest, dtos.InjectResponse{PublishedEventCount: 1}, errors.New("publish error"),
+			http.StatusInternalServerError, failedInject + " after 1 Events and 0 messages: publish error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			mockDataManager.On("Inject", mock.Anything).Return(test.InjectResponse, test.InjectError)
+
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.inject))
+
+			body, err := json.Marshal(test.Request)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, injectRoute, bytes.NewReader(body))
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			assert.Contains(t, testRecorder.Body.String(), test.ExpectedResponse)
+			if test.ExpectedStatus == http.StatusOK {
+				mockDataManager.AssertCalled(t, "Inject", validRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// asyncParam is the optional query parameter which runs a long operation as a job when true
+	asyncParam = "async"
+	// jobIdParam is the path parameter of the job route holding the job's Id
+	jobIdParam = "id"
+
+	// jobRetention is how long a finished job's status is kept for
+	jobRetention = time.Hour
+	// jobSpoolPattern is the pattern of the temporary files a job's request body is spooled to
+	jobSpoolPattern = "arr-job-*"
+)
+
+var jobNotFound = errors.New("job not found or has expired")
+var jobFinished = errors.New("job has already finished")
+
+// job is a long operation running in the background, with the func to cancel it
+type job struct {
+	status dtos.JobStatus
+	cancel context.CancelFunc
+}
+
+// jobs holds the running jobs and the status of the finished jobs, keyed by Id. Jobs are held in memory, so don't
+// survive a restart.
+type jobs struct {
+	mutex sync.Mutex
+	jobs  map[string]*job
+	now   func() time.Time
+}
+
+func newJobs() *jobs {
+	return &jobs{
+		jobs: make(map[string]*job),
+		now:  time.Now,
+	}
+}
+
+// add adds a running job of the kind, pruning the jobs which finished more than the retention ago
+func (j *jobs) add(kind string, cancel context.CancelFunc) dtos.JobStatus {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	now := j.now()
+	for id, existing := range j.jobs {
+		if existing.status.FinishedAt > 0 && now.Sub(time.Unix(0, existing.status.FinishedAt)) > jobRetention {
+			delete(j.jobs, id)
+		}
+	}
+
+	added := &job{
+		status: dtos.JobStatus{
+			Id:        uuid.NewString(),
+			Kind:      kind,
+			State:     dtos.JobStateRunning,
+			CreatedAt: now.UnixNano(),
+		},
+		cancel: cancel,
+	}
+	j.jobs[added.status.Id] = added
+
+	return added.status
+}
+
+// get returns the status of the job
+func (j *jobs) get(id string) (dtos.JobStatus, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	existing := j.jobs[id]
+	if existing == nil {
+		return dtos.JobStatus{}, jobNotFound
+	}
+
+	return existing.status, nil
+}
+
+// cancel cancels the job if it is still running. The job is canceled once its operation stops, which is
+// immediately unless it has got past the point where it can stop, in which case it completes anyway.
+func (j *jobs) cancel(id string) (dtos.JobStatus, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	existing := j.jobs[id]
+	if existing == nil {
+		return dtos.JobStatus{}, jobNotFound
+	}
+
+	if existing.status.State != dtos.JobStateRunning {
+		return existing.status, jobFinished
+	}
+
+	existing.cancel()
+	return existing.status, nil
+}
+
+// finish records the response of the job's operation. A job whose operation failed after it was canceled is
+// canceled rather than failed.
+func (j *jobs) finish(id string, canceled bool, response *bufferedResponse) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	existing := j.jobs[id]
+	if existing == nil {
+		return
+	}
+
+	status := &existing.status
+	status.FinishedAt = j.now().UnixNano()
+	status.StatusCode = response.status
+
+	switch {
+	case response.status >= http.StatusOK && response.status < http.StatusMultipleChoices:
+		status.State = dtos.JobStateCompleted
+	case canceled:
+		status.State = dtos.JobStateCanceled
+	default:
+		status.State = dtos.JobStateFailed
+	}
+
+	// The handlers return their JSON responses as strings, so the body is checked rather than the Content-Type
+	body := bytes.TrimSpace(response.body.Bytes())
+	if len(body) > 0 && (body[0] == '{' || body[0] == '[') && json.Valid(body) {
+		status.Result = json.RawMessage(body)
+	} else {
+		status.Message = string(body)
+	}
+
+	existing.cancel()
+}
+
+// asyncJob returns the handler which runs the long operation of the handler as a job when the async query parameter
+// is true, responding with 202 Accepted and the job's status, so HTTP clients don't time out on multi-minute
+// operations. The job's status, including the operation's response, is polled using GET /api/v3/jobs/{id}.
+// Otherwise the operation is run as is.
+func (c *httpController) asyncJob(kind string, handler echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		async := false
+		if value := ctx.QueryParam(asyncParam); len(value) > 0 {
+			var err error
+			async, err = strconv.ParseBool(value)
+			if err != nil {
+				return ctx.String(http.StatusBadRequest, fmt.Sprintf("failed to parse %s parameter: %v", asyncParam, err))
+			}
+		}
+
+		if !async {
+			return handler(ctx)
+		}
+
+		// The request body is read once the response has been sent, so is spooled to a file first
+		spool, err := c.spoolRequestBody(ctx.Request())
+		if err != nil {
+			if isImportLimitError(err) {
+				return ctx.String(http.StatusRequestEntityTooLarge, fmt.Sprintf("%s: %v", failedImportLimit, err))
+			}
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to spool request body for job: %v", err))
+		}
+
+		jobCtx, cancel := context.WithCancel(context.Background())
+		status := c.jobs.add(kind, cancel)
+
+		request := ctx.Request().Clone(jobCtx)
+		request.Body = &contextReader{ctx: jobCtx, ReadCloser: spool}
+		response := &bufferedResponse{header: make(http.Header)}
+		jobEchoCtx := ctx.Echo().NewContext(request, response)
+
+		go func() {
+			defer spool.Close()
+
+			if err := handler(jobEchoCtx); err != nil {
+				c.lc.Errorf("ARR Job %s: %s job failed to respond: %v", status.Id, kind, err)
+			}
+
+			c.jobs.finish(status.Id, jobCtx.Err() != nil, response)
+			c.lc.Debugf("ARR Job %s: %s job finished with status %d", status.Id, kind, response.status)
+		}()
+
+		c.lc.Debugf("ARR Job %s: %s job started", status.Id, kind)
+
+		jsonResponse, err := json.Marshal(status)
+		if err != nil {
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal job status: %s", err))
+		}
+
+		ctx.Response().Header().Set(echo.HeaderLocation, jobsRoute+"/"+status.Id)
+		return ctx.String(http.StatusAccepted, string(jsonResponse))
+	}
+}
+
+// spoolRequestBody copies the request body, up to the import's max request bytes, to a temporary file which is
+// removed when closed
+func (c *httpController) spoolRequestBody(request *http.Request) (io.ReadCloser, error) {
+	limits, err := c.getImportLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	if request.ContentLength > limits.maxRequestBytes {
+		return nil, fmt.Errorf("%w: request body of %d bytes exceeds %d bytes",
+			importLimitExceeded, request.ContentLength, limits.maxRequestBytes)
+	}
+
+	file, err := os.CreateTemp("", jobSpoolPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	spool := &spoolFile{File: file}
+	if request.Body != nil {
+		_, err = io.Copy(file, limitImportReader(request.Body, limits.maxRequestBytes, "request body"))
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = spool.Close()
+		return nil, err
+	}
+
+	return spool, nil
+}
+
+// spoolFile is a temporary file which is removed when closed
+type spoolFile struct {
+	*os.File
+}
+
+func (f *spoolFile) Close() error {
+	err := f.File.Close()
+	_ = os.Remove(f.Name())
+	return err
+}
+
+// contextReader fails reads once the context is done, so a canceled job stops reading its data
+type contextReader struct {
+	io.ReadCloser
+	ctx context.Context
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.ReadCloser.Read(p)
+}
+
+// jobStatus returns the status of the job as the HTTP response
+func (c *httpController) jobStatus(ctx echo.Context) error {
+	status, err := c.jobs.get(ctx.Param(jobIdParam))
+	if err != nil {
+		return ctx.String(http.StatusNotFound, err.Error())
+	}
+
+	return jobStatusResponse(ctx, http.StatusOK, status)
+}
+
+// cancelJob cancels the running job and returns its status as the HTTP response. The job's state changes once its
+// operation has stopped.
+func (c *httpController) cancelJob(ctx echo.Context) error {
+	status, err := c.jobs.cancel(ctx.Param(jobIdParam))
+	switch {
+	case errors.Is(err, jobNotFound):
+		return ctx.String(http.StatusNotFound, err.Error())
+	case err != nil:
+		return ctx.String(http.StatusConflict, err.Error())
+	}
+
+	return jobStatusResponse(ctx, http.StatusAccepted, status)
+}
+
+func jobStatusResponse(ctx echo.Context, code int, status dtos.JobStatus) error {
+	jsonResponse, err := json.Marshal(status)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal job status: %s", err))
+	}
+
+	return ctx.String(code, string(jsonResponse))
+}
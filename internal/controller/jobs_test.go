@@ -0,0 +1,209 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_AsyncJob_Import(t *testing.T) {
+	recordedData := dtos.RecordedData{
+		RecordedEvents: []coreDtos.Event{{DeviceName: "test", ProfileName: "test"}},
+		Devices:        []coreDtos.Device{{Name: "test", ProfileName: "test"}},
+		Profiles:       []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "test"}}},
+	}
+	data, err := json.Marshal(recordedData)
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name               string
+		Body               []byte
+		ExpectedState      string
+		ExpectedStatusCode int
+		ExpectedMessage    string
+	}{
+		{"Completed", data, dtos.JobStateCompleted, http.StatusAccepted, ""},
+		{"Failed", []byte("{bad"), dtos.JobStateFailed, http.StatusBadRequest, failedRequestJSON},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			mockDataManager.On("ImportRecordedData", mock.Anything, true, false).Return(nil)
+
+			req, err := http.NewRequest(http.MethodPost, dataRoute+"?async=true", bytes.NewReader(test.Body))
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.asyncJob(dtos.JobKindImport, target.importRecordedData)))
+			handler.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusAccepted, resp.Code, resp.Body.String())
+
+			started := dtos.JobStatus{}
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &started))
+			assert.Equal(t, dtos.JobKindImport, started.Kind)
+			assert.Equal(t, dtos.JobStateRunning, started.State)
+			assert.Equal(t, jobsRoute+"/"+started.Id, resp.Header().Get(echo.HeaderLocation))
+
+			status := waitForJob(t, target, started.Id)
+			assert.Equal(t, test.ExpectedState, status.State)
+			assert.Equal(t, test.ExpectedStatusCode, status.StatusCode)
+			assert.Contains(t, status.Message, test.ExpectedMessage)
+			assert.NotZero(t, status.FinishedAt)
+		})
+	}
+}
+
+func TestHttpController_AsyncJob_Sync(t *testing.T) {
+	target, _, _ := createTargetAndMocks()
+	handler := func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, `{"done":true}`)
+	}
+
+	for _, query := range []string{"", "?async=false"} {
+		req, err := http.NewRequest(http.MethodPost, exportRoute+query, nil)
+		require.NoError(t, err)
+
+		resp := httptest.NewRecorder()
+		http.HandlerFunc(WrapEchoHandler(t, target.asyncJob(dtos.JobKindExport, handler))).ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, `{"done":true}`, resp.Body.String())
+	}
+
+	req, err := http.NewRequest(http.MethodPost, exportRoute+"?async=maybe", nil)
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.asyncJob(dtos.JobKindExport, handler))).ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestHttpController_AsyncJob_RequestTooLarge(t *testing.T) {
+	target, _, mockSdk := createTargetAndMocks()
+	mockSdk.ExpectedCalls = nil
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ImportMaxRequestBytesAppSetting: "10"})
+
+	req, err := http.NewRequest(http.MethodPost, dataRoute+"?async=true", strings.NewReader(strings.Repeat("x", 11)))
+	require.NoError(t, err)
+	// An unknown length is limited while spooled
+	req.ContentLength = -1
+
+	resp := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.asyncJob(dtos.JobKindImport, target.importRecordedData))).ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+	assert.Empty(t, target.jobs.jobs)
+}
+
+func TestHttpController_CancelJob(t *testing.T) {
+	target, _, _ := createTargetAndMocks()
+
+	// The operation runs until canceled, when it fails
+	handler := func(ctx echo.Context) error {
+		<-ctx.Request().Context().Done()
+		return ctx.String(http.StatusInternalServerError, ctx.Request().Context().Err().Error())
+	}
+
+	req, err := http.NewRequest(http.MethodPost, exportRoute+"?async=true", strings.NewReader(`{}`))
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.asyncJob(dtos.JobKindExport, handler))).ServeHTTP(resp, req)
+	require.Equal(t, http.StatusAccepted, resp.Code)
+
+	started := dtos.JobStatus{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &started))
+
+	jobRequest := func(method string, id string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, jobsRoute+"/"+id, nil)
+		require.NoError(t, err)
+
+		resp := httptest.NewRecorder()
+		ctx := echo.New().NewContext(req, resp)
+		ctx.SetParamNames(jobIdParam)
+		ctx.SetParamValues(id)
+
+		if method == http.MethodDelete {
+			require.NoError(t, target.cancelJob(ctx))
+		} else {
+			require.NoError(t, target.jobStatus(ctx))
+		}
+
+		return resp
+	}
+
+	assert.Equal(t, http.StatusOK, jobRequest(http.MethodGet, started.Id).Code)
+	assert.Equal(t, http.StatusAccepted, jobRequest(http.MethodDelete, started.Id).Code)
+
+	status := waitForJob(t, target, started.Id)
+	assert.Equal(t, dtos.JobStateCanceled, status.State)
+	assert.Equal(t, "context canceled", status.Message)
+
+	// Finished jobs can't be canceled
+	assert.Equal(t, http.StatusConflict, jobRequest(http.MethodDelete, started.Id).Code)
+
+	resp = jobRequest(http.MethodGet, started.Id)
+	require.Equal(t, http.StatusOK, resp.Code)
+	status = dtos.JobStatus{}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &status))
+	assert.Equal(t, dtos.JobStateCanceled, status.State)
+
+	assert.Equal(t, http.StatusNotFound, jobRequest(http.MethodGet, "unknown").Code)
+	assert.Equal(t, http.StatusNotFound, jobRequest(http.MethodDelete, "unknown").Code)
+}
+
+func TestJobs_Retention(t *testing.T) {
+	target := newJobs()
+	now := time.Now()
+	target.now = func() time.Time { return now }
+
+	finished := target.add(dtos.JobKindImport, func() {})
+	running := target.add(dtos.JobKindImport, func() {})
+	target.finish(finished.Id, false, &bufferedResponse{status: http.StatusOK})
+
+	// Only the finished job is pruned once the retention has passed
+	now = now.Add(jobRetention + time.Second)
+	target.add(dtos.JobKindExport, func() {})
+
+	_, err := target.get(finished.Id)
+	require.ErrorIs(t, err, jobNotFound)
+	_, err = target.get(running.Id)
+	require.NoError(t, err)
+}
+
+func waitForJob(t *testing.T, target *httpController, id string) dtos.JobStatus {
+	var status dtos.JobStatus
+	require.Eventually(t, func() bool {
+		var err error
+		status, err = target.jobs.get(id)
+		return err == nil && status.State != dtos.JobStateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return status
+}
@@ -0,0 +1,67 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+var localPathDisabled = errors.New("local filesystem paths are disabled")
+var localPathNotAllowed = errors.New("path isn't within the allow-listed directories")
+var localPathNotAbsolute = errors.New("path must be absolute")
+
+// allowedLocalPath returns the path, with any symbolic links resolved, if it is within one of the directories
+// allow-listed by the comma separated App Setting. Links are resolved before checking so they can't be used to
+// escape the allow-listed directories. The path must exist.
+func (c *httpController) allowedLocalPath(setting string, path string) (string, error) {
+	var allowed []string
+	for _, dir := range strings.Split(c.appSdk.ApplicationSettings()[setting], ",") {
+		if dir = strings.TrimSpace(dir); len(dir) > 0 {
+			allowed = append(allowed, dir)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return "", fmt.Errorf("%w since the %s App Setting isn't set", localPathDisabled, setting)
+	}
+
+	if !filepath.IsAbs(path) {
+		return "", localPathNotAbsolute
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %s: %v", path, err)
+	}
+
+	for _, allowedDir := range allowed {
+		allowedDir, err := filepath.EvalSymlinks(allowedDir)
+		if err != nil {
+			// The allow-listed directory may be a mount point for removable media which isn't attached
+			continue
+		}
+
+		if resolved == allowedDir || strings.HasPrefix(resolved, allowedDir+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w of the %s App Setting", localPathNotAllowed, setting)
+}
@@ -0,0 +1,184 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// limitParam is the optional query parameter of the list routes with the most items to return
+	limitParam = "limit"
+	// cursorParam is the optional query parameter of the list routes with the cursor returned for the previous page
+	cursorParam = "cursor"
+	// listFormatParam is the optional query parameter of the list routes which, when ndjson, streams the listed items
+	// one per line rather than responding with the whole report
+	listFormatParam = "format"
+	// nextCursorHeader is the response header holding the cursor of the next page, which isn't set on the last page
+	nextCursorHeader = "X-Next-Cursor"
+
+	// ndjsonFlushCount is how many lines are streamed between flushes of the response
+	ndjsonFlushCount = 100
+
+	failedPageValidate = "limit must be an integer greater than 0 and cursor must be a cursor returned for a previous page"
+)
+
+var invalidCursor = errors.New("invalid cursor")
+
+// page is the range of the listed items requested using the limit and cursor query parameters. Cursors are opaque
+// to clients but hold the offset of the page's first item, so the pages of a list which changes between requests,
+// i.e. while recording, may skip or repeat items.
+type page struct {
+	offset int
+	// limit is the most items in the page, or 0 for all the items from the offset
+	limit int
+}
+
+// listRequest is the page and format requested from a list route
+type listRequest struct {
+	page   page
+	ndjson bool
+}
+
+// parsePage parses the limit and cursor query parameters. The zero page, holding all the items, is returned when
+// neither is set.
+func parsePage(query url.Values) (page, error) {
+	result := page{}
+	if value := query.Get(limitParam); len(value) > 0 {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return result, fmt.Errorf("%s: '%s'", failedPageValidate, value)
+		}
+		result.limit = limit
+	}
+
+	if value := query.Get(cursorParam); len(value) > 0 {
+		offset, err := decodeCursor(value)
+		if err != nil {
+			return result, fmt.Errorf("%s: '%s'", failedPageValidate, value)
+		}
+		result.offset = offset
+	}
+
+	return result, nil
+}
+
+// parseListRequest parses the page and format query parameters of a list route
+func parseListRequest(query url.Values) (listRequest, error) {
+	result := listRequest{}
+	format := query.Get(listFormatParam)
+	switch format {
+	case "":
+	case ndjsonFormat:
+		result.ndjson = true
+	default:
+		return result, fmt.Errorf("list format not available: %s", format)
+	}
+
+	var err error
+	result.page, err = parsePage(query)
+	return result, err
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, invalidCursor
+	}
+
+	offset, err := strconv.Atoi(string(decoded))
+	if err != nil || offset < 0 {
+		return 0, invalidCursor
+	}
+
+	return offset, nil
+}
+
+// pageItems returns the items in the page, setting the next cursor header when more items follow
+func pageItems[T any](ctx echo.Context, p page, items []T) []T {
+	start := min(p.offset, len(items))
+	end := len(items)
+	if p.limit > 0 && start+p.limit < end {
+		end = start + p.limit
+		ctx.Response().Header().Set(nextCursorHeader, encodeCursor(end))
+	}
+
+	return items[start:end]
+}
+
+// listResponse responds with the page of the listed items requested, either as part of the JSON report or streamed
+// as newline delimited JSON, one item per line, so large lists can be consumed incrementally. The name describes the
+// report in errors.
+func listResponse[T any](ctx echo.Context, request listRequest, report any, items *[]T, name string) error {
+	*items = pageItems(ctx, request.page, *items)
+
+	if request.ndjson {
+		return writeNDJSON(ctx, *items)
+	}
+
+	jsonResponse, err := json.Marshal(report)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal %s: %s", name, err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// writeNDJSON streams the items as newline delimited JSON, flushing periodically so clients receive the lines as
+// they are encoded. Flushing is skipped when the underlying writer doesn't support it, i.e. behind the SDK's
+// http.TimeoutHandler, in which case the lines are sent once the handler returns.
+func writeNDJSON[T any](ctx echo.Context, items []T) error {
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, ndjsonContentType)
+	response.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(response)
+	for index, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		if (index+1)%ndjsonFlushCount == 0 {
+			if err := flushResponse(response); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flushResponse(response)
+}
+
+// flushResponse flushes the response when the underlying writer supports it. Echo's Response.Flush panics when it
+// doesn't, so the writer it wraps is flushed directly.
+func flushResponse(response *echo.Response) error {
+	err := http.NewResponseController(response.Writer).Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,208 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListRequest(t *testing.T) {
+	tests := []struct {
+		Name            string
+		Query           string
+		ExpectedRequest listRequest
+		ExpectedError   bool
+	}{
+		{"Valid - all", "", listRequest{}, false},
+		{"Valid - limit", "limit=10", listRequest{page: page{limit: 10}}, false},
+		{"Valid - cursor", "cursor=" + encodeCursor(20), listRequest{page: page{offset: 20}}, false},
+		{"Valid - ndjson", "format=ndjson&limit=5", listRequest{page: page{limit: 5}, ndjson: true}, false},
+		{"Invalid - limit", "limit=0", listRequest{}, true},
+		{"Invalid - limit not integer", "limit=ten", listRequest{}, true},
+		{"Invalid - cursor", "cursor=bogus!", listRequest{}, true},
+		{"Invalid - negative cursor", "cursor=" + encodeCursor(-1), listRequest{}, true},
+		{"Invalid - format", "format=xml", listRequest{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			query, err := url.ParseQuery(test.Query)
+			require.NoError(t, err)
+
+			actual, err := parseListRequest(query)
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedRequest, actual)
+		})
+	}
+}
+
+func gapReport(count int) *dtos.GapReport {
+	report := &dtos.GapReport{Threshold: time.Second, ResourceCount: 1, GapCount: count}
+	for index := 0; index < count; index++ {
+		report.Gaps = append(report.Gaps, dtos.DataGap{DeviceName: "Device", ResourceName: "Int8",
+			Start: int64(index * 10), End: int64(index*10 + 5), Duration: 5})
+	}
+	return report
+}
+
+func TestHttpController_RecordedDataGaps_Paged(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.recordedDataGaps))
+	mockDataManager.On("RecordedDataGaps", time.Second).Return(func(time.Duration) *dtos.GapReport {
+		return gapReport(5)
+	}, nil)
+
+	var starts []int64
+	query := "?threshold=1s&limit=2"
+	for pages := 1; ; pages++ {
+		require.LessOrEqual(t, pages, 3)
+
+		testRecorder := httptest.NewRecorder()
+		handler.ServeHTTP(testRecorder, httptest.NewRequest(http.MethodGet, gapsRoute+query, nil))
+		require.Equal(t, http.StatusOK, testRecorder.Code)
+
+		actual := dtos.GapReport{}
+		require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &actual))
+		assert.Equal(t, 5, actual.GapCount)
+		for _, gap := range actual.Gaps {
+			starts = append(starts, gap.Start)
+		}
+
+		cursor := testRecorder.Header().Get(nextCursorHeader)
+		if len(cursor) == 0 {
+			assert.Equal(t, 3, pages)
+			break
+		}
+		query = "?threshold=1s&limit=2&cursor=" + cursor
+	}
+
+	assert.Equal(t, []int64{0, 10, 20, 30, 40}, starts)
+}
+
+func TestHttpController_RecordedDataGaps_NDJSON(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.recordedDataGaps))
+	mockDataManager.On("RecordedDataGaps", time.Second).Return(gapReport(3), nil)
+
+	testRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(testRecorder, httptest.NewRequest(http.MethodGet, gapsRoute+"?threshold=1s&format=ndjson&cursor="+encodeCursor(1), nil))
+	require.Equal(t, http.StatusOK, testRecorder.Code)
+	assert.Equal(t, ndjsonContentType, testRecorder.Header().Get("Content-Type"))
+	assert.Empty(t, testRecorder.Header().Get(nextCursorHeader))
+
+	var actual []dtos.DataGap
+	scanner := bufio.NewScanner(testRecorder.Body)
+	for scanner.Scan() {
+		gap := dtos.DataGap{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &gap))
+		actual = append(actual, gap)
+	}
+	require.Len(t, actual, 2)
+	assert.Equal(t, int64(10), actual[0].Start)
+	assert.Equal(t, int64(20), actual[1].Start)
+}
+
+func TestHttpController_RecordedDataGaps_NDJSON_TimeoutHandler(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	// The SDK serves every route through http.TimeoutHandler, whose writer can't be flushed
+	handler := http.TimeoutHandler(http.HandlerFunc(WrapEchoHandler(t, target.recordedDataGaps)), time.Minute, "timeout")
+	mockDataManager.On("RecordedDataGaps", time.Second).Return(gapReport(ndjsonFlushCount+1), nil)
+
+	testRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(testRecorder, httptest.NewRequest(http.MethodGet, gapsRoute+"?threshold=1s&format=ndjson", nil))
+	require.Equal(t, http.StatusOK, testRecorder.Code)
+	assert.Equal(t, ndjsonContentType, testRecorder.Header().Get("Content-Type"))
+
+	lines := 0
+	scanner := bufio.NewScanner(testRecorder.Body)
+	for scanner.Scan() {
+		gap := dtos.DataGap{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &gap))
+		lines++
+	}
+	assert.Equal(t, ndjsonFlushCount+1, lines)
+}
+
+func TestHttpController_ExportRecordedData_NDJSON(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData))
+	data := estimateTestData(t, 5)
+	mockDataManager.On("ExportRecordedData").Return(data, nil)
+
+	tests := []struct {
+		Name               string
+		Query              string
+		ExpectedStatus     int
+		ExpectedOrigins    []int64
+		ExpectedNextCursor string
+	}{
+		{"Valid - all", "?format=ndjson", http.StatusOK, []int64{1000, 2000, 3000, 4000, 5000}, ""},
+		{"Valid - first page", "?format=ndjson&limit=2", http.StatusOK, []int64{1000, 2000}, encodeCursor(2)},
+		{"Valid - last page", "?format=ndjson&limit=2&cursor=" + encodeCursor(4), http.StatusOK, []int64{5000}, ""},
+		{"Valid - past the end", "?format=ndjson&cursor=" + encodeCursor(10), http.StatusOK, nil, ""},
+		{"Invalid - limit", "?format=ndjson&limit=-1", http.StatusBadRequest, nil, ""},
+		{"Invalid - paged summary", "?format=summary&limit=2", http.StatusBadRequest, nil, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, httptest.NewRequest(http.MethodGet, dataRoute+test.Query, nil))
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code, testRecorder.Body.String())
+			if test.ExpectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, ndjsonContentType, testRecorder.Header().Get("Content-Type"))
+			assert.Equal(t, test.ExpectedNextCursor, testRecorder.Header().Get(nextCursorHeader))
+
+			lines := strings.Split(strings.TrimSpace(testRecorder.Body.String()), "\n")
+			first := dtos.RecordedData{}
+			require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+			assert.Equal(t, data.Name, first.Name)
+			assert.Empty(t, first.RecordedEvents)
+
+			// Each page is a complete NDJSON recording, which can be imported as is
+			imported, err := decodeNDJSONRecordedData(bytes.NewReader(testRecorder.Body.Bytes()), 10)
+			require.NoError(t, err)
+			var origins []int64
+			for _, event := range imported.RecordedEvents {
+				origins = append(origins, event.Origin)
+			}
+			assert.Equal(t, test.ExpectedOrigins, origins)
+		})
+	}
+
+	assert.Len(t, data.RecordedEvents, 5, "paging must not modify the recorded data")
+}
@@ -0,0 +1,516 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/labstack/echo/v4"
+)
+
+// playlistPollInterval is how often the replay status is checked while a playlist entry is replaying
+var playlistPollInterval = 250 * time.Millisecond
+
+var (
+	noPlaylist             = errors.New("no playlist has been started")
+	playlistRunning        = errors.New("a playlist is already running")
+	playlistNotRunning     = errors.New("playlist has already finished")
+	playlistNoEntries      = errors.New("at least one entry must be specified")
+	playlistSessionRunning = errors.New("a record or replay session is running or queued")
+	playlistTriggerTimeout = errors.New("timed out waiting for the replay to be triggered")
+)
+
+// playlistRunner holds the definition and status of the running or last playlist, with the func to cancel it while
+// running. Only one playlist runs at a time.
+type playlistRunner struct {
+	mutex      sync.Mutex
+	definition *dtos.Playlist
+	status     *dtos.PlaylistStatus
+	cancel     context.CancelFunc
+	now        func() time.Time
+}
+
+func newPlaylistRunner() *playlistRunner {
+	return &playlistRunner{now: time.Now}
+}
+
+// start records the playlist as running, returning the context it runs under. An error is returned if a playlist is
+// already running.
+func (p *playlistRunner) start(playlist dtos.Playlist) (context.Context, dtos.PlaylistStatus, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.status != nil && p.status.State == dtos.PlaylistStateRunning {
+		return nil, dtos.PlaylistStatus{}, playlistRunning
+	}
+
+	status := &dtos.PlaylistStatus{
+		Name:      playlist.Name,
+		State:     dtos.PlaylistStateRunning,
+		StartedAt: p.now().UnixNano(),
+		Entries:   make([]dtos.PlaylistEntryStatus, len(playlist.Entries)),
+	}
+	for index, entry := range playlist.Entries {
+		status.Entries[index] = dtos.PlaylistEntryStatus{Name: entry.Name, State: dtos.PlaylistStatePending}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.definition = &playlist
+	p.status = status
+	p.cancel = cancel
+
+	return ctx, copyPlaylistStatus(status), nil
+}
+
+// get returns the status of the running or last playlist
+func (p *playlistRunner) get() (dtos.PlaylistStatus, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.status == nil {
+		return dtos.PlaylistStatus{}, noPlaylist
+	}
+
+	return copyPlaylistStatus(p.status), nil
+}
+
+// getDefinition returns the definition of the running or last playlist, as validated
+func (p *playlistRunner) getDefinition() (dtos.Playlist, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.definition == nil {
+		return dtos.Playlist{}, noPlaylist
+	}
+
+	// The entries are copied so the definition can be changed, such as by embedding their recordings
+	definition := *p.definition
+	definition.Entries = append([]dtos.PlaylistEntry(nil), p.definition.Entries...)
+	return definition, nil
+}
+
+// stop cancels the running playlist. The playlist is canceled once its current entry's replay has been canceled.
+func (p *playlistRunner) stop() (dtos.PlaylistStatus, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.status == nil {
+		return dtos.PlaylistStatus{}, noPlaylist
+	}
+
+	if p.status.State != dtos.PlaylistStateRunning {
+		return copyPlaylistStatus(p.status), playlistNotRunning
+	}
+
+	p.cancel()
+	return copyPlaylistStatus(p.status), nil
+}
+
+// startEntry records the entry as running, replacing the result of its previous run if the playlist looped
+func (p *playlistRunner) startEntry(index int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.status.CurrentEntry = index
+	entry := &p.status.Entries[index]
+	entry.State = dtos.PlaylistStateRunning
+	entry.RunCount++
+	entry.StartedAt = p.now().UnixNano()
+	entry.FinishedAt = 0
+	entry.EventCount = 0
+	entry.Message = ""
+}
+
+// finishEntry records the result of the entry's replay. An entry which failed after the playlist was canceled is
+// canceled rather than failed.
+func (p *playlistRunner) finishEntry(index int, eventCount int, canceled bool, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	entry := &p.status.Entries[index]
+	entry.FinishedAt = p.now().UnixNano()
+	entry.EventCount = eventCount
+
+	switch {
+	case err == nil:
+		entry.State = dtos.PlaylistStateCompleted
+	case canceled:
+		entry.State = dtos.PlaylistStateCanceled
+	case errors.Is(err, playlistTriggerTimeout):
+		entry.State = dtos.PlaylistStateTimedOut
+		entry.Message = err.Error()
+	default:
+		entry.State = dtos.PlaylistStateFailed
+		entry.Message = err.Error()
+	}
+}
+
+// finish records the result of the playlist. The entries which didn't run are skipped if the playlist completed,
+// otherwise canceled.
+func (p *playlistRunner) finish(canceled bool, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.status.FinishedAt = p.now().UnixNano()
+	switch {
+	case canceled:
+		p.status.State = dtos.PlaylistStateCanceled
+	case err != nil:
+		p.status.State = dtos.PlaylistStateFailed
+		p.status.Message = err.Error()
+	default:
+		p.status.State = dtos.PlaylistStateCompleted
+	}
+
+	notRun := dtos.PlaylistStateCanceled
+	if p.status.State == dtos.PlaylistStateCompleted {
+		notRun = dtos.PlaylistStateSkipped
+	}
+
+	for index := range p.status.Entries {
+		if p.status.Entries[index].State == dtos.PlaylistStatePending {
+			p.status.Entries[index].State = notRun
+		}
+	}
+
+	p.cancel()
+}
+
+func copyPlaylistStatus(status *dtos.PlaylistStatus) dtos.PlaylistStatus {
+	result := *status
+	result.Entries = append([]dtos.PlaylistEntryStatus(nil), status.Entries...)
+	return result
+}
+
+// validatePlaylist checks the playlist's entries, defaulting their names
+func (c *httpController) validatePlaylist(playlist *dtos.Playlist) error {
+	if len(playlist.Entries) == 0 {
+		return playlistNoEntries
+	}
+
+	names := make(map[string]bool)
+	for index := range playlist.Entries {
+		entry := &playlist.Entries[index]
+		if len(entry.Name) == 0 {
+			entry.Name = fmt.Sprintf("entry-%d", index+1)
+		}
+
+		if names[entry.Name] {
+			return fmt.Errorf("entry name %s is used more than once", entry.Name)
+		}
+		names[entry.Name] = true
+
+		if entry.DelayAfter < 0 {
+			return fmt.Errorf("entry %s DelayAfter must be greater than or equal 0", entry.Name)
+		}
+
+		if entry.TriggerTimeout < 0 || (entry.TriggerTimeout > 0 && !entry.WaitForTrigger) {
+			return fmt.Errorf("entry %s TriggerTimeout must be greater than or equal 0 and requires WaitForTrigger", entry.Name)
+		}
+
+		if len(entry.OnTimeout) > 0 && entry.TriggerTimeout == 0 {
+			return fmt.Errorf("entry %s OnTimeout requires TriggerTimeout", entry.Name)
+		}
+
+		if entry.WaitForTrigger {
+			entry.Replay.Standby = true
+		}
+
+		if failure := replayRequestFailure(&entry.Replay); len(failure) > 0 {
+			return fmt.Errorf("entry %s: %s", entry.Name, failure)
+		}
+
+		if len(entry.Path) > 0 && len(entry.Data) > 0 {
+			return fmt.Errorf("entry %s must not set both Path and Data", entry.Name)
+		}
+
+		if len(entry.Signature) > 0 && len(entry.Data) == 0 {
+			return fmt.Errorf("entry %s Signature requires Data", entry.Name)
+		}
+
+		if len(entry.Path) > 0 {
+			if _, err := c.allowedLocalPath(ImportPathsAppSetting, entry.Path); err != nil {
+				return fmt.Errorf("entry %s: %w", entry.Name, err)
+			}
+		}
+
+		if len(entry.Replay.Label) == 0 {
+			entry.Replay.Label = entry.Name
+			if len(playlist.Name) > 0 {
+				entry.Replay.Label = playlist.Name + "/" + entry.Name
+			}
+		}
+	}
+
+	// The branches are checked once all the entries have been named
+	for _, entry := range playlist.Entries {
+		for _, branch := range []string{entry.Next, entry.OnTimeout, entry.OnFailure} {
+			if len(branch) > 0 && !names[branch] {
+				return fmt.Errorf("entry %s branches to unknown entry %s", entry.Name, branch)
+			}
+		}
+	}
+
+	return nil
+}
+
+// playlistEntryIndex returns the position of the named entry in the playlist. The branches are validated before the
+// playlist starts, so the entry is always found.
+func playlistEntryIndex(playlist dtos.Playlist, name string) int {
+	for index, entry := range playlist.Entries {
+		if entry.Name == name {
+			return index
+		}
+	}
+
+	return len(playlist.Entries)
+}
+
+// runPlaylist replays the playlist's entries, following their branches, until the last entry completes, an entry
+// fails or the playlist is canceled
+func (c *httpController) runPlaylist(ctx context.Context, e *echo.Echo, playlist dtos.Playlist) {
+	var err error
+	for index := 0; index < len(playlist.Entries); {
+		entry := playlist.Entries[index]
+		c.playlist.startEntry(index)
+		c.lc.Debugf("ARR Playlist: Starting entry %s", entry.Name)
+
+		var eventCount int
+		eventCount, err = c.runPlaylistEntry(ctx, e, entry)
+		c.playlist.finishEntry(index, eventCount, ctx.Err() != nil, err)
+		if ctx.Err() != nil {
+			break
+		}
+
+		next := index + 1
+		switch {
+		case err == nil && len(entry.Next) > 0:
+			next = playlistEntryIndex(playlist, entry.Next)
+		case errors.Is(err, playlistTriggerTimeout) && len(entry.OnTimeout) > 0:
+			c.lc.Debugf("ARR Playlist: Entry %s timed out, continuing with entry %s", entry.Name, entry.OnTimeout)
+			next = playlistEntryIndex(playlist, entry.OnTimeout)
+			err = nil
+		case err != nil && len(entry.OnFailure) > 0:
+			c.lc.Warnf("ARR Playlist: Entry %s failed, continuing with entry %s: %v", entry.Name, entry.OnFailure, err)
+			next = playlistEntryIndex(playlist, entry.OnFailure)
+			err = nil
+		}
+
+		if err != nil {
+			err = fmt.Errorf("entry %s failed: %w", entry.Name, err)
+			break
+		}
+
+		if entry.DelayAfter > 0 && next < len(playlist.Entries) {
+			select {
+			case <-ctx.Done():
+			case <-time.After(entry.DelayAfter):
+			}
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		index = next
+	}
+
+	c.playlist.finish(ctx.Err() != nil, err)
+	if err != nil {
+		c.lc.Errorf("ARR Playlist: %v", err)
+		return
+	}
+
+	c.lc.Debugf("ARR Playlist: Playlist %s finished", playlist.Name)
+}
+
+// runPlaylistEntry imports the entry's recording file or embedded recording, if set, and replays it, waiting for the replay to finish. The
+// replay is canceled if the playlist is. The number of Events replayed is returned.
+func (c *httpController) runPlaylistEntry(ctx context.Context, e *echo.Echo, entry dtos.PlaylistEntry) (int, error) {
+	// The entry's replay must start rather than be queued, so its status is the replay status
+	recordStatus := c.dataManager.RecordingStatus()
+	replayStatus := c.dataManager.ReplayStatus()
+	if recordStatus.InProgress || len(recordStatus.Queue) > 0 ||
+		replayStatus.Running || replayStatus.Standby || len(replayStatus.Queue) > 0 {
+		return 0, playlistSessionRunning
+	}
+
+	if len(entry.Path) > 0 || len(entry.Data) > 0 {
+		if err := c.importPlaylistEntry(ctx, e, entry); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := c.dataManager.StartReplay(entry.Replay); err != nil {
+		return 0, err
+	}
+
+	ticker := time.NewTicker(playlistPollInterval)
+	defer ticker.Stop()
+
+	var triggerDeadline <-chan time.Time
+	if entry.TriggerTimeout > 0 {
+		timer := time.NewTimer(entry.TriggerTimeout)
+		defer timer.Stop()
+		triggerDeadline = timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := c.dataManager.CancelReplay(); err != nil {
+				c.lc.Warnf("ARR Playlist: Failed to cancel replay of entry %s: %v", entry.Name, err)
+			}
+			return c.dataManager.ReplayStatus().EventCount, ctx.Err()
+		case <-triggerDeadline:
+			// The deadline only applies while the replay is in standby, so is ignored once triggered
+			triggerDeadline = nil
+			if !c.dataManager.ReplayStatus().Standby {
+				continue
+			}
+			if err := c.dataManager.CancelReplay(); err != nil {
+				c.lc.Warnf("ARR Playlist: Failed to cancel replay of entry %s: %v", entry.Name, err)
+			}
+			return 0, playlistTriggerTimeout
+		case <-ticker.C:
+		}
+
+		status := c.dataManager.ReplayStatus()
+		if status.Running || status.Standby {
+			continue
+		}
+
+		if len(status.Message) > 0 {
+			return status.EventCount, errors.New(status.Message)
+		}
+
+		return status.EventCount, nil
+	}
+}
+
+// importPlaylistEntry imports the entry's recording the same as POST /api/v3/data, either with the path query
+// parameter for its file or with its embedded data as the request body
+func (c *httpController) importPlaylistEntry(ctx context.Context, e *echo.Echo, entry dtos.PlaylistEntry) error {
+	target := dataRoute
+	source := "embedded data"
+	var body io.Reader = http.NoBody
+	if len(entry.Path) > 0 {
+		target += "?" + url.Values{importPathParam: {entry.Path}}.Encode()
+		source = entry.Path
+	} else {
+		body = bytes.NewReader(entry.Data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target, body)
+	if err != nil {
+		return err
+	}
+	if len(entry.Signature) > 0 {
+		request.Header.Set(signatureHeader, entry.Signature)
+	}
+
+	response := &bufferedResponse{header: make(http.Header)}
+	if err := c.importRecordedData(e.NewContext(request, response)); err != nil {
+		return err
+	}
+
+	if response.status < http.StatusOK || response.status >= http.StatusMultipleChoices {
+		return fmt.Errorf("import of %s failed with status %d: %s", source, response.status,
+			strings.TrimSpace(response.body.String()))
+	}
+
+	return nil
+}
+
+// startPlaylist validates the playlist in the request and starts replaying it, returning its status as the HTTP
+// response. Playlists with embedded recordings are subject to the same request body limit as imports.
+func (c *httpController) startPlaylist(ctx echo.Context) error {
+	limits, err := c.getImportLimits()
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedPlaylistValidate, err))
+	}
+
+	playlist := dtos.Playlist{}
+	body := limitImportReader(ctx.Request().Body, limits.maxRequestBytes, "request body")
+	if err := json.NewDecoder(body).Decode(&playlist); err != nil {
+		if isImportLimitError(err) {
+			return ctx.String(http.StatusRequestEntityTooLarge, fmt.Sprintf("%s: %v", failedImportLimit, err))
+		}
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	if err := c.validatePlaylist(&playlist); err != nil {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedPlaylistValidate, err))
+	}
+
+	for index := range playlist.Entries {
+		playlist.Entries[index].Replay.CorrelationID = correlationID(ctx)
+	}
+
+	runCtx, status, err := c.playlist.start(playlist)
+	if err != nil {
+		return ctx.String(http.StatusConflict, err.Error())
+	}
+
+	go c.runPlaylist(runCtx, ctx.Echo(), playlist)
+
+	return playlistStatusResponse(ctx, http.StatusAccepted, status)
+}
+
+// playlistStatus returns the status of the running or last playlist as the HTTP response
+func (c *httpController) playlistStatus(ctx echo.Context) error {
+	status, err := c.playlist.get()
+	if err != nil {
+		return ctx.String(http.StatusNotFound, err.Error())
+	}
+
+	return playlistStatusResponse(ctx, http.StatusOK, status)
+}
+
+// cancelPlaylist cancels the running playlist and returns its status as the HTTP response. The playlist's state
+// changes once its current entry's replay has been canceled.
+func (c *httpController) cancelPlaylist(ctx echo.Context) error {
+	status, err := c.playlist.stop()
+	switch {
+	case errors.Is(err, noPlaylist):
+		return ctx.String(http.StatusNotFound, err.Error())
+	case err != nil:
+		return ctx.String(http.StatusConflict, err.Error())
+	}
+
+	return playlistStatusResponse(ctx, http.StatusAccepted, status)
+}
+
+func playlistStatusResponse(ctx echo.Context, code int, status dtos.PlaylistStatus) error {
+	jsonResponse, err := json.Marshal(status)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal playlist status: %s", err))
+	}
+
+	return ctx.String(code, string(jsonResponse))
+}
@@ -0,0 +1,117 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	playlistDefinitionRoute = playlistRoute + "/definition"
+
+	// embedParam is the optional playlist definition query parameter which, if true, embeds the entries' recording
+	// files in the definition
+	embedParam = "embed"
+
+	failedPlaylistExport = "Export playlist failed"
+)
+
+// embedPlaylistRecordings replaces the path of each entry's recording file with the file's data, along with its
+// signature if signed, so the playlist can be imported on another gateway without the files. Each file must be no
+// larger than the import request body limit, since it is imported as the request body.
+func (c *httpController) embedPlaylistRecordings(playlist *dtos.Playlist) error {
+	limits, err := c.getImportLimits()
+	if err != nil {
+		return err
+	}
+
+	for index := range playlist.Entries {
+		entry := &playlist.Entries[index]
+		if len(entry.Path) == 0 {
+			continue
+		}
+
+		file, signature, err := c.openLocalImportFile(entry.Path)
+		if err != nil {
+			return fmt.Errorf("entry %s: %w", entry.Name, err)
+		}
+
+		data, err := io.ReadAll(limitImportReader(file, limits.maxRequestBytes, entry.Path))
+		_ = file.Close()
+		if err != nil {
+			return fmt.Errorf("entry %s: %w", entry.Name, err)
+		}
+
+		entry.Data = data
+		entry.Signature = signature
+		entry.Path = ""
+	}
+
+	return nil
+}
+
+// exportPlaylist returns the definition of the running or last playlist as the HTTP response, so it can be saved and
+// started again later or on another gateway. The definition is as validated, so has the default entry names and
+// labels set. The entries' recording files are embedded when the embed query parameter is true, otherwise they are
+// referenced by path.
+func (c *httpController) exportPlaylist(ctx echo.Context) error {
+	embed := false
+	if value := ctx.Request().URL.Query().Get(embedParam); len(value) > 0 {
+		var err error
+		embed, err = strconv.ParseBool(value)
+		if err != nil {
+			return ctx.String(http.StatusBadRequest, fmt.Sprintf("failed to parse %s parameter: %v", embedParam, err))
+		}
+	}
+
+	playlist, err := c.playlist.getDefinition()
+	if err != nil {
+		return ctx.String(http.StatusNotFound, err.Error())
+	}
+
+	if embed {
+		err := c.embedPlaylistRecordings(&playlist)
+		switch {
+		case errors.Is(err, localPathDisabled), errors.Is(err, localPathNotAllowed):
+			return ctx.String(http.StatusForbidden, fmt.Sprintf("%s: %v", failedPlaylistExport, err))
+		case isImportLimitError(err):
+			return ctx.String(http.StatusRequestEntityTooLarge, fmt.Sprintf("%s: %v", failedPlaylistExport, err))
+		case err != nil:
+			return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedPlaylistExport, err))
+		}
+	}
+
+	jsonResponse, err := json.Marshal(playlist)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedPlaylistExport, err))
+	}
+
+	// Named playlists are downloaded using their name so saved playlists are easy to identify
+	if len(playlist.Name) > 0 {
+		ctx.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", playlist.Name+".json"))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
@@ -0,0 +1,151 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func exportTestPlaylist(t *testing.T, target *httpController, query string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.exportPlaylist))
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, playlistDefinitionRoute+query, nil))
+	return recorder
+}
+
+func TestHttpController_ExportPlaylist(t *testing.T) {
+	pollInterval := playlistPollInterval
+	playlistPollInterval = time.Millisecond
+	defer func() { playlistPollInterval = pollInterval }()
+
+	importDir := t.TempDir()
+	recording := marshal(t, archivedData)
+	recordingPath := filepath.Join(importDir, "warmup.json")
+	require.NoError(t, os.WriteFile(recordingPath, recording, 0640))
+
+	mockDataManager := &mocks.DataManager{}
+	mockDataManager.On("ImportRecordedData", mock.Anything, true, false).Return(nil)
+	replays := &fakeReplays{}
+	replays.mock(mockDataManager)
+	mockSdk := &appMocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ImportPathsAppSetting: importDir})
+	target := New(mockDataManager, nil, nil, mockSdk).(*httpController)
+
+	recorder := exportTestPlaylist(t, target, "")
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	playlist := dtos.Playlist{Name: "demo", Entries: []dtos.PlaylistEntry{
+		{Name: "warmup", Path: recordingPath, Replay: dtos.ReplayRequest{ReplayRate: 1}},
+		{Replay: dtos.ReplayRequest{ReplayRate: 1}},
+	}}
+	recorder = startTestPlaylist(t, target, playlist)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+	status := waitForPlaylist(t, target)
+	require.Equal(t, dtos.PlaylistStateCompleted, status.State, status.Message)
+
+	recorder = exportTestPlaylist(t, target, "")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, `attachment; filename="demo.json"`, recorder.Header().Get("Content-Disposition"))
+	referenced := dtos.Playlist{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &referenced))
+	require.Len(t, referenced.Entries, 2)
+	assert.Equal(t, recordingPath, referenced.Entries[0].Path)
+	assert.Empty(t, referenced.Entries[0].Data)
+	assert.Equal(t, "entry-2", referenced.Entries[1].Name)
+	assert.Equal(t, "demo/entry-2", referenced.Entries[1].Replay.Label)
+
+	// The signature of a signed recording file is embedded along with its data
+	require.NoError(t, os.WriteFile(recordingPath+signatureFileExtension, []byte("signed\n"), 0640))
+	recorder = exportTestPlaylist(t, target, "?"+embedParam+"=true")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	embedded := dtos.Playlist{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &embedded))
+	require.Len(t, embedded.Entries, 2)
+	assert.Empty(t, embedded.Entries[0].Path)
+	assert.Equal(t, recording, embedded.Entries[0].Data)
+	assert.Equal(t, "signed", embedded.Entries[0].Signature)
+	assert.Empty(t, embedded.Entries[1].Data)
+
+	// Embedding doesn't change the definition held for the playlist
+	definition, err := target.playlist.getDefinition()
+	require.NoError(t, err)
+	assert.Equal(t, recordingPath, definition.Entries[0].Path)
+
+	// The embedded playlist is imported by starting it, without the recording file
+	require.NoError(t, os.Remove(recordingPath))
+	embedded.Entries[0].Signature = ""
+	recorder = startTestPlaylist(t, target, embedded)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+	status = waitForPlaylist(t, target)
+	require.Equal(t, dtos.PlaylistStateCompleted, status.State, status.Message)
+	mockDataManager.AssertNumberOfCalls(t, "ImportRecordedData", 2)
+}
+
+func TestHttpController_ExportPlaylist_Errors(t *testing.T) {
+	importDir := t.TempDir()
+	recordingPath := filepath.Join(importDir, "warmup.json")
+	require.NoError(t, os.WriteFile(recordingPath, []byte(strings.Repeat(" ", 100)), 0640))
+
+	target, _, mockSdk := createTargetAndMocks()
+	mockSdk.ExpectedCalls = nil
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ImportPathsAppSetting: importDir, ImportMaxRequestBytesAppSetting: "10"})
+
+	_, _, err := target.playlist.start(dtos.Playlist{Entries: []dtos.PlaylistEntry{{Name: "a", Path: recordingPath}}})
+	require.NoError(t, err)
+
+	recorder := exportTestPlaylist(t, target, "?"+embedParam+"=bogus")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = exportTestPlaylist(t, target, "?"+embedParam+"=true")
+	require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code, recorder.Body.String())
+	assert.Contains(t, recorder.Body.String(), failedPlaylistExport)
+
+	_, _, err = target.playlist.start(dtos.Playlist{Entries: []dtos.PlaylistEntry{{Name: "a", Path: "/etc/passwd"}}})
+	require.Error(t, err, "the first playlist is still running")
+	target.playlist.finish(false, nil)
+	_, _, err = target.playlist.start(dtos.Playlist{Entries: []dtos.PlaylistEntry{{Name: "a", Path: "/etc/passwd"}}})
+	require.NoError(t, err)
+
+	recorder = exportTestPlaylist(t, target, "?"+embedParam+"=true")
+	require.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+
+	// Playlists with embedded recordings are limited the same as import request bodies
+	body, err := json.Marshal(dtos.Playlist{Entries: []dtos.PlaylistEntry{{Data: []byte(strings.Repeat(" ", 100))}}})
+	require.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.startPlaylist)).ServeHTTP(recorder,
+		httptest.NewRequest(http.MethodPost, playlistRoute, bytes.NewReader(body)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code, recorder.Body.String())
+}
@@ -0,0 +1,404 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces/mocks"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_ValidatePlaylist(t *testing.T) {
+	importDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(importDir, "warmup.json"), []byte("{}"), 0640))
+
+	tests := []struct {
+		Name           string
+		Playlist       dtos.Playlist
+		ExpectedError  string
+		ExpectedNames  []string
+		ExpectedLabels []string
+	}{
+		{"Valid", dtos.Playlist{Name: "demo", Entries: []dtos.PlaylistEntry{
+			{Name: "warmup", Path: filepath.Join(importDir, "warmup.json"), Replay: dtos.ReplayRequest{ReplayRate: 1}},
+			{Replay: dtos.ReplayRequest{ReplayRate: 1, Label: "load"}, DelayAfter: time.Second},
+		}}, "", []string{"warmup", "entry-2"}, []string{"demo/warmup", "load"}},
+		{"Valid unnamed playlist", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Replay: dtos.ReplayRequest{ReplayRate: 1}}}}, "", []string{"entry-1"}, []string{"entry-1"}},
+		{"No entries", dtos.Playlist{}, playlistNoEntries.Error(), nil, nil},
+		{"Duplicate names", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Name: "a", Replay: dtos.ReplayRequest{ReplayRate: 1}}, {Name: "a", Replay: dtos.ReplayRequest{ReplayRate: 1}}}},
+			"entry name a is used more than once", nil, nil},
+		{"Negative delay", dtos.Playlist{Entries: []dtos.PlaylistEntry{{DelayAfter: -time.Second, Replay: dtos.ReplayRequest{ReplayRate: 1}}}},
+			"DelayAfter must be greater than or equal 0", nil, nil},
+		{"Invalid replay", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Replay: dtos.ReplayRequest{TimeWarpDuration: -1}}}},
+			failedTimeWarpValidate, nil, nil},
+		{"Path missing", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Path: filepath.Join(importDir, "missing.json"), Replay: dtos.ReplayRequest{ReplayRate: 1}}}},
+			"missing.json", nil, nil},
+		{"Path not allowed", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Path: "/etc/passwd", Replay: dtos.ReplayRequest{ReplayRate: 1}}}},
+			localPathNotAllowed.Error(), nil, nil},
+		{"Valid branches", dtos.Playlist{Entries: []dtos.PlaylistEntry{
+			{Name: "a", Replay: dtos.ReplayRequest{ReplayRate: 1}, WaitForTrigger: true, TriggerTimeout: time.Second, OnTimeout: "b", OnFailure: "entry-3"},
+			{Name: "b", Replay: dtos.ReplayRequest{ReplayRate: 1}, Next: "a"},
+			{Replay: dtos.ReplayRequest{ReplayRate: 1}},
+		}}, "", []string{"a", "b", "entry-3"}, []string{"a", "b", "entry-3"}},
+		{"Embedded data", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Data: []byte("{}"), Signature: "abc", Replay: dtos.ReplayRequest{ReplayRate: 1}}}},
+			"", []string{"entry-1"}, []string{"entry-1"}},
+		{"Path and data", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Path: filepath.Join(importDir, "warmup.json"), Data: []byte("{}"), Replay: dtos.ReplayRequest{ReplayRate: 1}}}},
+			"must not set both Path and Data", nil, nil},
+		{"Signature without data", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Signature: "abc", Replay: dtos.ReplayRequest{ReplayRate: 1}}}},
+			"Signature requires Data", nil, nil},
+		{"Unknown branch", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Name: "a", Replay: dtos.ReplayRequest{ReplayRate: 1}, Next: "b"}}},
+			"entry a branches to unknown entry b", nil, nil},
+		{"Trigger timeout without wait", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Replay: dtos.ReplayRequest{ReplayRate: 1}, TriggerTimeout: time.Second}}},
+			"requires WaitForTrigger", nil, nil},
+		{"Negative trigger timeout", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Replay: dtos.ReplayRequest{ReplayRate: 1}, WaitForTrigger: true, TriggerTimeout: -time.Second}}},
+			"TriggerTimeout must be greater than or equal 0", nil, nil},
+		{"OnTimeout without timeout", dtos.Playlist{Entries: []dtos.PlaylistEntry{{Name: "a", Replay: dtos.ReplayRequest{ReplayRate: 1}, WaitForTrigger: true, OnTimeout: "a"}}},
+			"entry a OnTimeout requires TriggerTimeout", nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, _, mockSdk := createTargetAndMocks()
+			mockSdk.ExpectedCalls = nil
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ImportPathsAppSetting: importDir})
+
+			err := target.validatePlaylist(&test.Playlist)
+			if len(test.ExpectedError) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			for index, entry := range test.Playlist.Entries {
+				assert.Equal(t, test.ExpectedNames[index], entry.Name)
+				assert.Equal(t, test.ExpectedLabels[index], entry.Replay.Label)
+				assert.Equal(t, entry.WaitForTrigger, entry.Replay.Standby)
+			}
+		})
+	}
+}
+
+// fakeReplays stands in for the data manager's replays, which complete once their status has been checked while
+// running unless hung. Standby replays wait for trigger to be called and those labeled in failures fail with the
+// given message.
+type fakeReplays struct {
+	mutex      sync.Mutex
+	running    bool
+	standby    bool
+	hung       bool
+	message    string
+	failures   map[string]string
+	eventCount int
+	requests   []dtos.ReplayRequest
+}
+
+// trigger starts the replay waiting in standby, returning false if there isn't one
+func (f *fakeReplays) trigger() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.standby {
+		return false
+	}
+	f.standby = false
+	f.running = true
+	return true
+}
+
+func (f *fakeReplays) mock(mockDataManager *mocks.DataManager) {
+	mockDataManager.On("RecordingStatus").Return(dtos.RecordStatus{})
+	mockDataManager.On("StartReplay", mock.Anything).Run(func(args mock.Arguments) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		request := args.Get(0).(dtos.ReplayRequest)
+		f.requests = append(f.requests, request)
+		f.standby = request.Standby
+		f.running = !request.Standby
+		f.message = f.failures[request.Label]
+	}).Return(nil)
+	mockDataManager.On("ReplayStatus").Return(func() dtos.ReplayStatus {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		status := dtos.ReplayStatus{Running: f.running, Standby: f.standby, EventCount: f.eventCount, Message: f.message}
+		if f.running && !f.hung {
+			f.running = false
+		}
+		return status
+	})
+	mockDataManager.On("CancelReplay").Run(func(_ mock.Arguments) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		f.running = false
+		f.standby = false
+		f.message = "replay canceled"
+	}).Return(nil)
+}
+
+func startTestPlaylist(t *testing.T, target *httpController, playlist dtos.Playlist) *httptest.ResponseRecorder {
+	body, err := json.Marshal(playlist)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.startPlaylist))
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, playlistRoute, bytes.NewReader(body)))
+	return recorder
+}
+
+func waitForPlaylist(t *testing.T, target *httpController) dtos.PlaylistStatus {
+	var status dtos.PlaylistStatus
+	require.Eventually(t, func() bool {
+		var err error
+		status, err = target.playlist.get()
+		require.NoError(t, err)
+		return status.State != dtos.PlaylistStateRunning
+	}, 5*time.Second, 5*time.Millisecond)
+	return status
+}
+
+func TestHttpController_Playlist(t *testing.T) {
+	pollInterval := playlistPollInterval
+	playlistPollInterval = time.Millisecond
+	defer func() { playlistPollInterval = pollInterval }()
+
+	importDir := t.TempDir()
+	recordingPath := filepath.Join(importDir, "warmup.json")
+	require.NoError(t, os.WriteFile(recordingPath, marshal(t, archivedData), 0640))
+
+	mockDataManager := &mocks.DataManager{}
+	mockDataManager.On("ImportRecordedData", mock.Anything, true, false).Return(nil)
+	replays := &fakeReplays{eventCount: 5}
+	replays.mock(mockDataManager)
+	mockSdk := &appMocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ImportPathsAppSetting: importDir})
+	target := New(mockDataManager, nil, nil, mockSdk).(*httpController)
+
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.playlistStatus)).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, playlistRoute, nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	playlist := dtos.Playlist{Name: "demo", Entries: []dtos.PlaylistEntry{
+		{Name: "warmup", Path: recordingPath, Replay: dtos.ReplayRequest{ReplayRate: 1}, DelayAfter: 10 * time.Millisecond},
+		{Replay: dtos.ReplayRequest{ReplayRate: 2}},
+	}}
+	recorder = startTestPlaylist(t, target, playlist)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	started := dtos.PlaylistStatus{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &started))
+	assert.Equal(t, dtos.PlaylistStateRunning, started.State)
+	require.Len(t, started.Entries, 2)
+
+	status := waitForPlaylist(t, target)
+	assert.Equal(t, dtos.PlaylistStateCompleted, status.State, status.Message)
+	assert.Equal(t, 1, status.CurrentEntry)
+	assert.NotZero(t, status.FinishedAt)
+	for _, entry := range status.Entries {
+		assert.Equal(t, dtos.PlaylistStateCompleted, entry.State)
+		assert.Equal(t, 5, entry.EventCount)
+	}
+	assert.Equal(t, "entry-2", status.Entries[1].Name)
+
+	mockDataManager.AssertNumberOfCalls(t, "ImportRecordedData", 1)
+	require.Len(t, replays.requests, 2)
+	assert.Equal(t, "demo/warmup", replays.requests[0].Label)
+	assert.Equal(t, "demo/entry-2", replays.requests[1].Label)
+	assert.Equal(t, float32(2), replays.requests[1].ReplayRate)
+
+	recorder = httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.cancelPlaylist)).ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, playlistRoute, nil))
+	require.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestHttpController_Playlist_Failed(t *testing.T) {
+	pollInterval := playlistPollInterval
+	playlistPollInterval = time.Millisecond
+	defer func() { playlistPollInterval = pollInterval }()
+
+	target, mockDataManager, _ := createTargetAndMocks()
+	replays := &fakeReplays{}
+	replays.mock(mockDataManager)
+	mockDataManager.On("StartReplay", mock.Anything).Unset()
+	mockDataManager.On("StartReplay", mock.Anything).Return(errors.New("no recorded data present"))
+
+	recorder := startTestPlaylist(t, target, dtos.Playlist{Entries: []dtos.PlaylistEntry{{Name: "a", Replay: dtos.ReplayRequest{ReplayRate: 1}}, {Name: "b", Replay: dtos.ReplayRequest{ReplayRate: 1}}}})
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	status := waitForPlaylist(t, target)
+	assert.Equal(t, dtos.PlaylistStateFailed, status.State)
+	assert.Contains(t, status.Message, "entry a failed: no recorded data present")
+	assert.Equal(t, dtos.PlaylistStateFailed, status.Entries[0].State)
+	assert.Equal(t, "no recorded data present", status.Entries[0].Message)
+	assert.Equal(t, dtos.PlaylistStateCanceled, status.Entries[1].State)
+}
+
+func TestHttpController_Playlist_Canceled(t *testing.T) {
+	pollInterval := playlistPollInterval
+	playlistPollInterval = time.Millisecond
+	defer func() { playlistPollInterval = pollInterval }()
+
+	target, mockDataManager, _ := createTargetAndMocks()
+	replays := &fakeReplays{hung: true}
+	replays.mock(mockDataManager)
+
+	playlist := dtos.Playlist{Entries: []dtos.PlaylistEntry{{Name: "a", Replay: dtos.ReplayRequest{ReplayRate: 1}}, {Name: "b", Replay: dtos.ReplayRequest{ReplayRate: 1}}}}
+	recorder := startTestPlaylist(t, target, playlist)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	require.Eventually(t, func() bool {
+		replays.mutex.Lock()
+		defer replays.mutex.Unlock()
+		return replays.running
+	}, 5*time.Second, time.Millisecond)
+
+	recorder = startTestPlaylist(t, target, playlist)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), playlistRunning.Error())
+
+	recorder = httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.cancelPlaylist)).ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, playlistRoute, nil))
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	status := waitForPlaylist(t, target)
+	assert.Equal(t, dtos.PlaylistStateCanceled, status.State)
+	assert.Equal(t, dtos.PlaylistStateCanceled, status.Entries[0].State)
+	assert.Equal(t, dtos.PlaylistStateCanceled, status.Entries[1].State)
+	mockDataManager.AssertCalled(t, "CancelReplay")
+}
+
+func TestHttpController_Playlist_Branches(t *testing.T) {
+	pollInterval := playlistPollInterval
+	playlistPollInterval = time.Millisecond
+	defer func() { playlistPollInterval = pollInterval }()
+
+	target, mockDataManager, _ := createTargetAndMocks()
+	replays := &fakeReplays{eventCount: 3, failures: map[string]string{"flaky": "device offline"}}
+	replays.mock(mockDataManager)
+
+	playlist := dtos.Playlist{Entries: []dtos.PlaylistEntry{
+		{Name: "flaky", Replay: dtos.ReplayRequest{ReplayRate: 1}, OnFailure: "fallback"},
+		{Name: "skipped", Replay: dtos.ReplayRequest{ReplayRate: 1}},
+		{Name: "fallback", Replay: dtos.ReplayRequest{ReplayRate: 1}, WaitForTrigger: true, TriggerTimeout: 10 * time.Millisecond, OnTimeout: "finale"},
+		{Name: "also skipped", Replay: dtos.ReplayRequest{ReplayRate: 1}},
+		{Name: "finale", Replay: dtos.ReplayRequest{ReplayRate: 1}},
+	}}
+	recorder := startTestPlaylist(t, target, playlist)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	status := waitForPlaylist(t, target)
+	assert.Equal(t, dtos.PlaylistStateCompleted, status.State, status.Message)
+	assert.Equal(t, 4, status.CurrentEntry)
+	assert.Equal(t, dtos.PlaylistStateFailed, status.Entries[0].State)
+	assert.Equal(t, "device offline", status.Entries[0].Message)
+	assert.Equal(t, dtos.PlaylistStateSkipped, status.Entries[1].State)
+	assert.Equal(t, dtos.PlaylistStateTimedOut, status.Entries[2].State)
+	assert.Equal(t, playlistTriggerTimeout.Error(), status.Entries[2].Message)
+	assert.Equal(t, dtos.PlaylistStateSkipped, status.Entries[3].State)
+	assert.Equal(t, dtos.PlaylistStateCompleted, status.Entries[4].State)
+	assert.Equal(t, 3, status.Entries[4].EventCount)
+
+	require.Len(t, replays.requests, 3)
+	assert.True(t, replays.requests[1].Standby)
+	mockDataManager.AssertNumberOfCalls(t, "CancelReplay", 1)
+}
+
+func TestHttpController_Playlist_TriggerLoop(t *testing.T) {
+	pollInterval := playlistPollInterval
+	playlistPollInterval = time.Millisecond
+	defer func() { playlistPollInterval = pollInterval }()
+
+	target, mockDataManager, _ := createTargetAndMocks()
+	replays := &fakeReplays{}
+	replays.mock(mockDataManager)
+
+	playlist := dtos.Playlist{Entries: []dtos.PlaylistEntry{
+		{Name: "intro", Replay: dtos.ReplayRequest{ReplayRate: 1}},
+		{Name: "scene", Replay: dtos.ReplayRequest{ReplayRate: 1}, WaitForTrigger: true, Next: "scene"},
+	}}
+	recorder := startTestPlaylist(t, target, playlist)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	for run := 1; run <= 3; run++ {
+		require.Eventually(t, replays.trigger, 5*time.Second, time.Millisecond)
+	}
+
+	// The fourth run waits for its trigger until the playlist is canceled
+	require.Eventually(t, func() bool {
+		status, err := target.playlist.get()
+		require.NoError(t, err)
+		return status.Entries[1].RunCount == 4
+	}, 5*time.Second, time.Millisecond)
+
+	recorder = httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.cancelPlaylist)).ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, playlistRoute, nil))
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	status := waitForPlaylist(t, target)
+	assert.Equal(t, dtos.PlaylistStateCanceled, status.State)
+	assert.Equal(t, dtos.PlaylistStateCompleted, status.Entries[0].State)
+	assert.Equal(t, 1, status.Entries[0].RunCount)
+	assert.Equal(t, dtos.PlaylistStateCanceled, status.Entries[1].State)
+	assert.Equal(t, 4, status.Entries[1].RunCount)
+	assert.Len(t, replays.requests, 5)
+}
+
+func TestHttpController_Playlist_SessionRunning(t *testing.T) {
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("RecordingStatus").Return(dtos.RecordStatus{InProgress: true})
+	mockDataManager.On("ReplayStatus").Return(dtos.ReplayStatus{})
+
+	recorder := startTestPlaylist(t, target, dtos.Playlist{Entries: []dtos.PlaylistEntry{{Replay: dtos.ReplayRequest{ReplayRate: 1}}}})
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	status := waitForPlaylist(t, target)
+	assert.Equal(t, dtos.PlaylistStateFailed, status.State)
+	assert.Contains(t, status.Message, playlistSessionRunning.Error())
+	mockDataManager.AssertNotCalled(t, "StartReplay", mock.Anything)
+}
+
+func TestHttpController_StartPlaylist_Invalid(t *testing.T) {
+	target, _, _ := createTargetAndMocks()
+
+	recorder := startTestPlaylist(t, target, dtos.Playlist{})
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), failedPlaylistValidate)
+
+	recorder = httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.startPlaylist)).ServeHTTP(recorder,
+		httptest.NewRequest(http.MethodPost, playlistRoute, bytes.NewReader([]byte("bogus"))))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), failedRequestJSON)
+
+	recorder = httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.cancelPlaylist)).ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, playlistRoute, nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -0,0 +1,140 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// ProfilingAppSetting enables the pprof and runtime trace routes when "true", so performance problems during
+	// heavy recording or replay can be profiled in the field
+	ProfilingAppSetting = "Profiling"
+
+	pprofRoute        = common.ApiBase + "/debug/pprof"
+	pprofIndexRoute   = pprofRoute + "/"
+	pprofProfileRoute = pprofRoute + "/profile"
+	pprofTraceRoute   = pprofRoute + "/trace"
+	pprofCmdlineRoute = pprofRoute + "/cmdline"
+	pprofSymbolRoute  = pprofRoute + "/symbol"
+	pprofNamedRoute   = pprofRoute + "/:" + pprofNameParam
+
+	// pprofNameParam is the name of the runtime profile, e.g. heap, goroutine, allocs, block, mutex or threadcreate
+	pprofNameParam = "name"
+	// pprofSecondsParam is the query parameter of the CPU profile and runtime trace with how long to run them for
+	pprofSecondsParam = "seconds"
+
+	// defaultCPUProfileSeconds and defaultTraceSeconds are the net/http/pprof defaults, which are shortened to fit
+	// within the service's RequestTimeout
+	defaultCPUProfileSeconds = 30
+	defaultTraceSeconds      = 1
+
+	failedPprofSecondsValidate = "seconds must be a number greater than 0 and at most %d, so the profile completes within the Service.RequestTimeout of %s"
+)
+
+// addProfilingRoutes adds the net/http/pprof routes, which serve the CPU profile, the runtime trace and the named
+// runtime profiles such as the heap and goroutines. The routes are only added when the Profiling App Setting is
+// true, and require authentication when the service runs in secure mode, since the profiles expose the service's
+// internals.
+func (c *httpController) addProfilingRoutes() error {
+	value := c.appSdk.ApplicationSettings()[ProfilingAppSetting]
+	if len(value) == 0 {
+		return nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		c.lc.Errorf("Invalid %s value '%s', profiling disabled: %v", ProfilingAppSetting, value, err)
+		return nil
+	}
+
+	if !enabled {
+		return nil
+	}
+
+	routes := []struct {
+		route   string
+		handler echo.HandlerFunc
+		method  string
+	}{
+		{pprofIndexRoute, wrapPprof(pprof.Index), http.MethodGet},
+		{pprofProfileRoute, c.boundedPprof(pprof.Profile, defaultCPUProfileSeconds), http.MethodGet},
+		{pprofTraceRoute, c.boundedPprof(pprof.Trace, defaultTraceSeconds), http.MethodGet},
+		{pprofCmdlineRoute, wrapPprof(pprof.Cmdline), http.MethodGet},
+		{pprofSymbolRoute, wrapPprof(pprof.Symbol), http.MethodGet},
+		{pprofSymbolRoute, wrapPprof(pprof.Symbol), http.MethodPost},
+		{pprofNamedRoute, namedProfile, http.MethodGet},
+	}
+
+	for _, route := range routes {
+		if err := c.appSdk.AddCustomRoute(route.route, appInterfaces.Authenticated, route.handler, route.method); err != nil {
+			return fmt.Errorf(failedRouteMessage, route.route, route.method, err)
+		}
+	}
+
+	c.lc.Warnf("Profiling enabled. The pprof and runtime trace routes are served under %s", pprofRoute)
+
+	return nil
+}
+
+// wrapPprof adapts a net/http/pprof handler to an echo handler
+func wrapPprof(handler http.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		handler(ctx.Response(), ctx.Request())
+		return nil
+	}
+}
+
+// boundedPprof adapts the net/http/pprof handler of the CPU profile or runtime trace to an echo handler which runs
+// for at most the service's RequestTimeout less a margin, since the SDK's timeout handler otherwise responds with 503
+// before the profile is written. The seconds query parameter defaults to the handler's default, shortened to the
+// limit, and larger values are rejected.
+func (c *httpController) boundedPprof(handler http.HandlerFunc, defaultSeconds int) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		requestTimeout := c.appSdk.RequestTimeout()
+		limit := max(int((requestTimeout-statusWaitMargin)/time.Second), 1)
+
+		query := ctx.Request().URL.Query()
+		if value := query.Get(pprofSecondsParam); len(value) > 0 {
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds <= 0 || seconds > float64(limit) {
+				return ctx.String(http.StatusBadRequest, fmt.Sprintf(failedPprofSecondsValidate, limit, requestTimeout))
+			}
+		} else {
+			query.Set(pprofSecondsParam, strconv.Itoa(min(defaultSeconds, limit)))
+			ctx.Request().URL.RawQuery = query.Encode()
+		}
+
+		handler(ctx.Response(), ctx.Request())
+		return nil
+	}
+}
+
+// namedProfile serves the runtime profile named in the route, e.g. heap or goroutine, taking the same debug and gc
+// query parameters as net/http/pprof
+func namedProfile(ctx echo.Context) error {
+	pprof.Handler(ctx.Param(pprofNameParam)).ServeHTTP(ctx.Response(), ctx.Request())
+	return nil
+}
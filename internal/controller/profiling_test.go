@@ -0,0 +1,176 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/pprof"
+	"testing"
+	"time"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	appMocks "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_AddProfilingRoutes_Disabled(t *testing.T) {
+	for _, value := range []string{"", "false", "bad"} {
+		t.Run(value, func(t *testing.T) {
+			mockSdk := &appMocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ProfilingAppSetting: value})
+
+			target := New(nil, nil, nil, mockSdk).(*httpController)
+			require.NoError(t, target.addProfilingRoutes())
+			mockSdk.AssertNotCalled(t, "AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestHttpController_AddProfilingRoutes(t *testing.T) {
+	mockSdk := &appMocks.ApplicationService{}
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+	mockSdk.On("ApplicationSettings").Return(map[string]string{ProfilingAppSetting: "true"})
+	mockSdk.On("AddCustomRoute", mock.Anything, appInterfaces.Authenticated, mock.Anything, mock.Anything).Return(nil)
+
+	target := New(nil, nil, nil, mockSdk).(*httpController)
+	require.NoError(t, target.addProfilingRoutes())
+	mockSdk.AssertNumberOfCalls(t, "AddCustomRoute", 7)
+	mockSdk.AssertCalled(t, "AddCustomRoute", pprofNamedRoute, appInterfaces.Authenticated, mock.Anything, http.MethodGet)
+
+	tests := []struct {
+		Name   string
+		Route  string
+		Method string
+	}{
+		{"Index", pprofIndexRoute, http.MethodGet},
+		{"CPU Profile", pprofProfileRoute, http.MethodGet},
+		{"Trace", pprofTraceRoute, http.MethodGet},
+		{"Command Line", pprofCmdlineRoute, http.MethodGet},
+		{"Symbol GET", pprofSymbolRoute, http.MethodGet},
+		{"Symbol POST", pprofSymbolRoute, http.MethodPost},
+		{"Named Profile", pprofNamedRoute, http.MethodGet},
+	}
+
+	expectedError := errors.New("AddRoutes error")
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &appMocks.ApplicationService{}
+			mockSdk.On("AddCustomRoute", test.Route, mock.Anything, mock.Anything, test.Method).Return(expectedError)
+			mockSdk.On("AddCustomRoute", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ProfilingAppSetting: "true"})
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+			target := New(nil, nil, nil, mockSdk).(*httpController)
+
+			err := target.addProfilingRoutes()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.Route)
+			assert.Contains(t, err.Error(), test.Method)
+		})
+	}
+}
+
+func TestNamedProfile(t *testing.T) {
+	tests := []struct {
+		Name             string
+		Profile          string
+		ExpectedStatus   int
+		ExpectedResponse string
+	}{
+		{"Goroutines", "goroutine", http.StatusOK, "goroutine profile:"},
+		{"Unknown", "bogus", http.StatusNotFound, "Unknown profile"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, pprofRoute+"/"+test.Profile+"?debug=1", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			ctx := echo.New().NewContext(req, resp)
+			ctx.SetParamNames(pprofNameParam)
+			ctx.SetParamValues(test.Profile)
+
+			require.NoError(t, namedProfile(ctx))
+			assert.Equal(t, test.ExpectedStatus, resp.Code)
+			assert.Contains(t, resp.Body.String(), test.ExpectedResponse)
+		})
+	}
+}
+
+func TestWrapPprof(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, pprofCmdlineRoute, nil)
+	require.NoError(t, err)
+
+	testRecorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, wrapPprof(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(request.URL.Path))
+	}))).ServeHTTP(testRecorder, req)
+
+	assert.Equal(t, http.StatusOK, testRecorder.Code)
+	assert.Equal(t, pprofCmdlineRoute, testRecorder.Body.String())
+}
+
+func TestHttpController_BoundedPprof(t *testing.T) {
+	tests := []struct {
+		Name             string
+		Query            string
+		ExpectedStatus   int
+		ExpectedResponse string
+	}{
+		// The 30 second default of the CPU profile is shortened to fit within the RequestTimeout
+		{"CPU profile default", "", http.StatusOK, ""},
+		{"CPU profile within limit", "?seconds=1", http.StatusOK, ""},
+		{"CPU profile too long", "?seconds=30", http.StatusBadRequest, fmt.Sprintf(failedPprofSecondsValidate, 1, 2*time.Second)},
+		{"Invalid seconds", "?seconds=soon", http.StatusBadRequest, fmt.Sprintf(failedPprofSecondsValidate, 1, 2*time.Second)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &appMocks.ApplicationService{}
+			mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+			mockSdk.On("RequestTimeout").Return(2 * time.Second)
+
+			target := New(nil, nil, nil, mockSdk).(*httpController)
+
+			// The routes are served through the SDK's timeout handler, as in the service
+			handler := http.TimeoutHandler(http.HandlerFunc(WrapEchoHandler(t, target.boundedPprof(pprof.Profile, defaultCPUProfileSeconds))),
+				2*time.Second, "timeout")
+
+			req, err := http.NewRequest(http.MethodGet, pprofProfileRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code, testRecorder.Body.String())
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Equal(t, test.ExpectedResponse, testRecorder.Body.String())
+				return
+			}
+			assert.NotEmpty(t, testRecorder.Body.Bytes())
+		})
+	}
+}
@@ -0,0 +1,88 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// maxSelfTestEventCount is the most synthetic Events a self-test may generate
+	maxSelfTestEventCount = 10000
+	// defaultSelfTestEventCount and defaultSelfTestInterval are used by the self-test when not set in the request
+	defaultSelfTestEventCount = 100
+	defaultSelfTestInterval   = 10 * time.Millisecond
+)
+
+// selfTest records and replays synthetic Events through an internal loopback pipeline, returning the self-test
+// report as the HTTP response. The request body is optional, with the defaults used when empty.
+func (c *httpController) selfTest(ctx echo.Context) error {
+	request := &dtos.SelfTestRequest{}
+
+	if err := json.NewDecoder(ctx.Request().Body).Decode(request); err != nil && !errors.Is(err, io.EOF) {
+		return ctx.String(http.StatusBadRequest, fmt.Sprintf("%s: %v", failedRequestJSON, err))
+	}
+
+	if request.EventCount < 0 || request.EventCount > maxSelfTestEventCount || request.Interval < 0 ||
+		request.TimingTolerance < 0 {
+		return ctx.String(http.StatusBadRequest, failedSelfTestValidate)
+	}
+
+	// The loopback replays the Events in real time, so a self-test taking longer than the service's RequestTimeout
+	// would be answered with 503 by the SDK's timeout handler while it keeps running
+	requestTimeout := c.appSdk.RequestTimeout()
+	if duration := selfTestDuration(*request); duration > requestTimeout-statusWaitMargin {
+		return ctx.String(http.StatusBadRequest,
+			fmt.Sprintf(failedSelfTestDuration, duration, requestTimeout-statusWaitMargin, requestTimeout))
+	}
+
+	report, err := c.dataManager.SelfTest(*request)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("%s: %v", failedSelfTest, err))
+	}
+
+	jsonResponse, err := json.Marshal(report)
+	if err != nil {
+		return ctx.String(http.StatusInternalServerError, fmt.Sprintf("failed to marshal self-test report: %s", err))
+	}
+
+	return ctx.String(http.StatusOK, string(jsonResponse))
+}
+
+// selfTestDuration returns how long the loopback takes to replay the self-test's Events, which is the EventCount
+// times the Interval, using the defaults when not set
+func selfTestDuration(request dtos.SelfTestRequest) time.Duration {
+	eventCount := request.EventCount
+	if eventCount == 0 {
+		eventCount = defaultSelfTestEventCount
+	}
+
+	interval := request.Interval
+	if interval == 0 {
+		interval = defaultSelfTestInterval
+	}
+
+	return time.Duration(eventCount) * interval
+}
@@ -0,0 +1,86 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_SelfTest(t *testing.T) {
+	report := &dtos.SelfTestReport{Passed: true, EventCount: 5, RecordedEventCount: 5, ReplayedEventCount: 5}
+
+	tests := []struct {
+		Name             string
+		Body             []byte
+		ExpectedRequest  dtos.SelfTestRequest
+		SelfTestError    error
+		ExpectedStatus   int
+		ExpectedResponse string
+	}{
+		{"Valid", []byte(`{"eventCount":5,"interval":1000000}`),
+			dtos.SelfTestRequest{EventCount: 5, Interval: time.Millisecond}, nil, http.StatusOK, `"passed":true`},
+		{"Empty body", nil, dtos.SelfTestRequest{}, nil, http.StatusOK, `"eventCount":5`},
+		{"Bad JSON", []byte("bad"), dtos.SelfTestRequest{}, nil, http.StatusBadRequest, failedRequestJSON},
+		{"Too many Events", []byte(`{"eventCount":10001}`), dtos.SelfTestRequest{}, nil,
+			http.StatusBadRequest, failedSelfTestValidate},
+		{"Negative interval", []byte(`{"interval":-1}`), dtos.SelfTestRequest{}, nil,
+			http.StatusBadRequest, failedSelfTestValidate},
+		{"Too long", []byte(`{"eventCount":1000,"interval":10000000}`), dtos.SelfTestRequest{}, nil,
+			http.StatusBadRequest, fmt.Sprintf(failedSelfTestDuration, 10*time.Second, 4*time.Second, 5*time.Second)},
+		{"Too long with default interval", []byte(`{"eventCount":500}`), dtos.SelfTestRequest{}, nil,
+			http.StatusBadRequest, "5s must be at most 4s"},
+		{"Self-test fails", nil, dtos.SelfTestRequest{}, errors.New("start error"),
+			http.StatusInternalServerError, failedSelfTest + ": start error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			mockDataManager.On("SelfTest", mock.Anything).Return(report, test.SelfTestError)
+
+			handler := http.HandlerFunc(WrapEchoHandler(t, target.selfTest))
+
+			req, err := http.NewRequest(http.MethodPost, selfTestRoute, bytes.NewReader(test.Body))
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			assert.Contains(t, testRecorder.Body.String(), test.ExpectedResponse)
+			if test.ExpectedStatus == http.StatusOK {
+				mockDataManager.AssertCalled(t, "SelfTest", test.ExpectedRequest)
+
+				actual := dtos.SelfTestReport{}
+				require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &actual))
+				assert.Equal(t, *report, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const (
+	signingSecretName = "arr-signing"
+	signingPrivateKey = "privateKey"
+	signingPublicKey  = "publicKey"
+	signatureHeader   = "X-Signature"
+)
+
+var invalidSignatureError = errors.New("signature does not match the recorded data")
+
+// signData returns the base64 encoded Ed25519 detached signature of the data using the private key
+// stored in the secret store. The private key may be either the 32 byte seed or the full 64 byte key.
+func (c *httpController) signData(data []byte) (string, error) {
+	key, err := c.loadSigningKey(signingPrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	var privateKey ed25519.PrivateKey
+	switch len(key) {
+	case ed25519.SeedSize:
+		privateKey = ed25519.NewKeyFromSeed(key)
+	case ed25519.PrivateKeySize:
+		privateKey = key
+	default:
+		return "", fmt.Errorf("invalid %s length %d", signingPrivateKey, len(key))
+	}
+
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data)), nil
+}
+
+// verifyData verifies the base64 encoded Ed25519 detached signature of the data using the trusted
+// public key stored in the secret store.
+func (c *httpController) verifyData(data []byte, signature string) error {
+	key, err := c.loadSigningKey(signingPublicKey)
+	if err != nil {
+		return err
+	}
+
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid %s length %d", signingPublicKey, len(key))
+	}
+
+	decodedSignature, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("unable to decode signature: %v", err)
+	}
+
+	if !ed25519.Verify(key, data, decodedSignature) {
+		return invalidSignatureError
+	}
+
+	return nil
+}
+
+func (c *httpController) loadSigningKey(keyName string) ([]byte, error) {
+	secrets, err := c.appSdk.SecretProvider().GetSecret(signingSecretName, keyName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get %s from secret %s: %v", keyName, signingSecretName, err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(secrets[keyName])
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode %s from secret %s: %v", keyName, signingSecretName, err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	bootstrapMocks "github.com/edgexfoundry/go-mod-bootstrap/v3/bootstrap/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_SignAndVerifyData(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	data := []byte(`{"recordedEvents":[]}`)
+
+	tests := []struct {
+		Name          string
+		PrivateKey    []byte
+		PublicKey     []byte
+		SecretError   error
+		ExpectedError string
+	}{
+		{"Valid - full private key", privateKey, publicKey, nil, ""},
+		{"Valid - private key seed", privateKey.Seed(), publicKey, nil, ""},
+		{"Invalid - wrong public key", privateKey, otherPublicKey, nil, invalidSignatureError.Error()},
+		{"Invalid - bad private key length", []byte("short"), publicKey, nil, "invalid privateKey length"},
+		{"Invalid - secret not found", privateKey, publicKey, errors.New("not found"), "unable to get privateKey"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, _, mockSdk := createTargetAndMocks()
+			mockSdk.On("SecretProvider").Return(createMockSecretProvider(test.PrivateKey, test.PublicKey, test.SecretError))
+
+			signature, err := target.signData(data)
+			if err == nil {
+				err = target.verifyData(data, signature)
+			}
+
+			if len(test.ExpectedError) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHttpController_ExportImport_Signed(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	recordedData := &dtos.RecordedData{
+		RecordedEvents: []coreDtos.Event{{DeviceName: "test", ProfileName: "test"}},
+		Devices:        []coreDtos.Device{{Name: "test", ProfileName: "test"}},
+		Profiles:       []coreDtos.DeviceProfile{{DeviceProfileBasicInfo: coreDtos.DeviceProfileBasicInfo{Name: "test"}}},
+	}
+
+	target, mockDataManager, mockSdk := createTargetAndMocks()
+	mockSdk.On("SecretProvider").Return(createMockSecretProvider(privateKey, publicKey, nil))
+	mockDataManager.On("ExportRecordedData").Return(recordedData, nil)
+	mockDataManager.On("ImportRecordedData", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	req, err := http.NewRequest(http.MethodGet, dataRoute+"?sign=true", nil)
+	require.NoError(t, err)
+	exportRecorder := httptest.NewRecorder()
+	http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData)).ServeHTTP(exportRecorder, req)
+	require.Equal(t, http.StatusOK, exportRecorder.Code)
+
+	signature := exportRecorder.Header().Get(signatureHeader)
+	require.NotEmpty(t, signature)
+
+	tests := []struct {
+		Name           string
+		Body           []byte
+		Signature      string
+		ExpectedStatus int
+	}{
+		{"Valid signature", exportRecorder.Body.Bytes(), signature, http.StatusAccepted},
+		{"Tampered data", bytes.Replace(exportRecorder.Body.Bytes(), []byte("test"), []byte("fake"), 1), signature, http.StatusBadRequest},
+		{"Bad signature encoding", exportRecorder.Body.Bytes(), "not base64!", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, dataRoute, bytes.NewReader(test.Body))
+			require.NoError(t, err)
+			req.Header.Set(common.ContentType, common.ContentTypeJSON)
+			req.Header.Set(signatureHeader, test.Signature)
+
+			importRecorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.importRecordedData)).ServeHTTP(importRecorder, req)
+			require.Equal(t, test.ExpectedStatus, importRecorder.Code)
+		})
+	}
+}
+
+func createMockSecretProvider(privateKey []byte, publicKey []byte, secretError error) *bootstrapMocks.SecretProvider {
+	mockSecretProvider := &bootstrapMocks.SecretProvider{}
+	mockSecretProvider.On("GetSecret", signingSecretName, signingPrivateKey).
+		Return(map[string]string{signingPrivateKey: base64.StdEncoding.EncodeToString(privateKey)}, secretError)
+	mockSecretProvider.On("GetSecret", signingSecretName, signingPublicKey).
+		Return(map[string]string{signingPublicKey: base64.StdEncoding.EncodeToString(publicKey)}, secretError)
+	return mockSecretProvider
+}
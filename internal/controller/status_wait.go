@@ -0,0 +1,94 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// waitForCompletionParam is the optional record and replay status query parameter which, when true, holds the
+	// response until the session and any queued sessions have ended, so scripts don't have to poll the status
+	waitForCompletionParam = "waitForCompletion"
+	// waitTimeoutParam is the optional record and replay status query parameter with the longest time to wait for the
+	// sessions to end, after which the status is returned regardless. Defaults to the longest wait allowed, see
+	// statusWaitLimit.
+	waitTimeoutParam = "timeout"
+
+	maxStatusWaitTimeout = 10 * time.Minute
+	// statusWaitMargin is how long before the service's RequestTimeout a waiting status request returns, leaving time
+	// to respond before the SDK's timeout handler responds with 503 instead
+	statusWaitMargin = time.Second
+)
+
+// statusPollInterval is how often a waiting status request checks whether the sessions have ended
+var statusPollInterval = 100 * time.Millisecond
+
+// statusWaitLimit returns the longest a status request may wait for the sessions to end, which is shorter than the
+// service's RequestTimeout so the status is returned before the request times out
+func statusWaitLimit(requestTimeout time.Duration) time.Duration {
+	limit := requestTimeout - statusWaitMargin
+	if limit <= 0 {
+		limit = requestTimeout / 2
+	}
+	return min(limit, maxStatusWaitTimeout)
+}
+
+// waitForCompletion holds the status request until ended returns true, the wait timeout elapses or the client
+// disconnects, when the request's waitForCompletion query parameter is true. The wait timeout defaults to the limit,
+// which it must not exceed. It returns false if the wait query parameters are invalid.
+func waitForCompletion(ctx echo.Context, limit time.Duration, ended func() bool) bool {
+	wait := false
+	if value := ctx.QueryParam(waitForCompletionParam); len(value) > 0 {
+		var err error
+		if wait, err = strconv.ParseBool(value); err != nil {
+			return false
+		}
+	}
+
+	timeout := limit
+	if value := ctx.QueryParam(waitTimeoutParam); len(value) > 0 {
+		var err error
+		if timeout, err = time.ParseDuration(value); err != nil || timeout <= 0 || timeout > limit {
+			return false
+		}
+	}
+
+	if !wait {
+		return true
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	for !ended() {
+		select {
+		case <-ctx.Request().Context().Done():
+			return true
+		case <-deadline.C:
+			return true
+		case <-ticker.C:
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,153 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpController_RecordingStatus_WaitForCompletion(t *testing.T) {
+	pollInterval := statusPollInterval
+	statusPollInterval = time.Millisecond
+	defer func() { statusPollInterval = pollInterval }()
+
+	inProgress := dtos.RecordStatus{InProgress: true, EventCount: 5}
+	queued := dtos.RecordStatus{Queue: []dtos.QueuedSession{{Label: "next"}}}
+	ended := dtos.RecordStatus{EventCount: 10}
+
+	tests := []struct {
+		Name             string
+		Query            string
+		Statuses         []dtos.RecordStatus
+		ExpectedStatus   int
+		ExpectedResponse dtos.RecordStatus
+	}{
+		{"Valid - no wait", "", []dtos.RecordStatus{inProgress}, http.StatusOK, inProgress},
+		{"Valid - wait false", "?waitForCompletion=false", []dtos.RecordStatus{inProgress}, http.StatusOK, inProgress},
+		{"Valid - ended", "?waitForCompletion=true",
+			[]dtos.RecordStatus{inProgress, queued, ended, ended}, http.StatusOK, ended},
+		{"Valid - timeout", "?waitForCompletion=true&timeout=20ms", nil, http.StatusOK, inProgress},
+		{"Invalid - wait", "?waitForCompletion=maybe", nil, http.StatusBadRequest, dtos.RecordStatus{}},
+		{"Invalid - timeout", "?waitForCompletion=true&timeout=soon", nil, http.StatusBadRequest, dtos.RecordStatus{}},
+		{"Invalid - timeout 0", "?waitForCompletion=true&timeout=0s", nil, http.StatusBadRequest, dtos.RecordStatus{}},
+		{"Invalid - timeout past request timeout", "?waitForCompletion=true&timeout=5s", nil, http.StatusBadRequest,
+			dtos.RecordStatus{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			for _, status := range test.Statuses {
+				mockDataManager.On("RecordingStatus").Return(status).Once()
+			}
+			mockDataManager.On("RecordingStatus").Return(inProgress).Maybe()
+
+			req, err := http.NewRequest(http.MethodGet, recordRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.recordingStatus)).ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Equal(t, fmt.Sprintf(failedStatusWaitValidate, 4*time.Second), testRecorder.Body.String())
+				return
+			}
+
+			actualResponse := dtos.RecordStatus{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &actualResponse))
+			assert.Equal(t, test.ExpectedResponse, actualResponse)
+		})
+	}
+}
+
+func TestHttpController_ReplayStatus_WaitForCompletion(t *testing.T) {
+	pollInterval := statusPollInterval
+	statusPollInterval = time.Millisecond
+	defer func() { statusPollInterval = pollInterval }()
+
+	running := dtos.ReplayStatus{Running: true, EventCount: 5}
+	standby := dtos.ReplayStatus{Standby: true}
+	ended := dtos.ReplayStatus{EventCount: 10}
+
+	tests := []struct {
+		Name             string
+		Query            string
+		Statuses         []dtos.ReplayStatus
+		ExpectedStatus   int
+		ExpectedResponse dtos.ReplayStatus
+	}{
+		{"Valid - no wait", "", []dtos.ReplayStatus{running}, http.StatusOK, running},
+		{"Valid - ended", "?waitForCompletion=true&timeout=4s",
+			[]dtos.ReplayStatus{standby, running, ended, ended}, http.StatusOK, ended},
+		{"Valid - timeout", "?waitForCompletion=true&timeout=20ms", nil, http.StatusOK, running},
+		{"Invalid - timeout", "?waitForCompletion=true&timeout=-1s", nil, http.StatusBadRequest, dtos.ReplayStatus{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, mockDataManager, _ := createTargetAndMocks()
+			for _, status := range test.Statuses {
+				mockDataManager.On("ReplayStatus").Return(status).Once()
+			}
+			mockDataManager.On("ReplayStatus").Return(running).Maybe()
+
+			req, err := http.NewRequest(http.MethodGet, replayRoute+test.Query, nil)
+			require.NoError(t, err)
+
+			testRecorder := httptest.NewRecorder()
+			http.HandlerFunc(WrapEchoHandler(t, target.replayStatus)).ServeHTTP(testRecorder, req)
+
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Equal(t, fmt.Sprintf(failedStatusWaitValidate, 4*time.Second), testRecorder.Body.String())
+				return
+			}
+
+			actualResponse := dtos.ReplayStatus{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &actualResponse))
+			assert.Equal(t, test.ExpectedResponse, actualResponse)
+		})
+	}
+}
+
+func TestStatusWaitLimit(t *testing.T) {
+	tests := []struct {
+		Name           string
+		RequestTimeout time.Duration
+		Expected       time.Duration
+	}{
+		{"Default request timeout", 5 * time.Second, 4 * time.Second},
+		{"Short request timeout", time.Second, 500 * time.Millisecond},
+		{"Long request timeout", time.Hour, maxStatusWaitTimeout},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, statusWaitLimit(test.RequestTimeout))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+const defaultSummaryWindow = time.Minute
+
+type summaryKey struct {
+	start        int64
+	deviceName   string
+	resourceName string
+}
+
+// toSummary aggregates the Readings from the recorded Events into fixed time windows aligned to multiples
+// of the window size. The window a Reading falls in is based on its Origin.
+func toSummary(events []coreDtos.Event, window time.Duration) *dtos.RecordedDataSummary {
+	summaries := make(map[summaryKey]*dtos.ResourceSummary)
+	for _, event := range events {
+		for _, reading := range event.Readings {
+			key := summaryKey{
+				start:        reading.Origin - reading.Origin%int64(window),
+				deviceName:   reading.DeviceName,
+				resourceName: reading.ResourceName,
+			}
+
+			summary, ok := summaries[key]
+			if !ok {
+				summary = &dtos.ResourceSummary{
+					DeviceName:   reading.DeviceName,
+					ResourceName: reading.ResourceName,
+					Min:          math.MaxFloat64,
+					Max:          -math.MaxFloat64,
+				}
+				summaries[key] = summary
+			}
+
+			summary.Count++
+
+			value, err := strconv.ParseFloat(reading.Value, 64)
+			if err != nil {
+				continue
+			}
+
+			summary.NumericCount++
+			summary.Min = math.Min(summary.Min, value)
+			summary.Max = math.Max(summary.Max, value)
+			// Avg holds the running sum until all the Readings have been aggregated
+			summary.Avg += value
+		}
+	}
+
+	windows := make(map[int64]*dtos.SummaryWindow)
+	for key, summary := range summaries {
+		if summary.NumericCount > 0 {
+			summary.Avg = summary.Avg / float64(summary.NumericCount)
+		} else {
+			summary.Min = 0
+			summary.Max = 0
+		}
+
+		summaryWindow, ok := windows[key.start]
+		if !ok {
+			summaryWindow = &dtos.SummaryWindow{Start: key.start}
+			windows[key.start] = summaryWindow
+		}
+		summaryWindow.Resources = append(summaryWindow.Resources, *summary)
+	}
+
+	result := &dtos.RecordedDataSummary{
+		Window:     window,
+		EventCount: len(events),
+		Windows:    make([]dtos.SummaryWindow, 0, len(windows)),
+	}
+
+	for _, summaryWindow := range windows {
+		sort.Slice(summaryWindow.Resources, func(i, j int) bool {
+			if summaryWindow.Resources[i].DeviceName != summaryWindow.Resources[j].DeviceName {
+				return summaryWindow.Resources[i].DeviceName < summaryWindow.Resources[j].DeviceName
+			}
+			return summaryWindow.Resources[i].ResourceName < summaryWindow.Resources[j].ResourceName
+		})
+		result.Windows = append(result.Windows, *summaryWindow)
+	}
+
+	sort.Slice(result.Windows, func(i, j int) bool { return result.Windows[i].Start < result.Windows[j].Start })
+
+	return result
+}
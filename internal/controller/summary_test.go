@@ -0,0 +1,115 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSummaryTestEvent(deviceName string, origin time.Duration, value string) coreDtos.Event {
+	event := coreDtos.NewEvent("test-profile", deviceName, "test-source")
+	_ = event.AddSimpleReading("Temperature", common.ValueTypeString, value)
+	event.Readings[0].Origin = int64(origin)
+	return event
+}
+
+func TestToSummary(t *testing.T) {
+	events := []coreDtos.Event{
+		newSummaryTestEvent("D1", 70*time.Second, "30"),
+		newSummaryTestEvent("D1", 10*time.Second, "10"),
+		newSummaryTestEvent("D1", 20*time.Second, "20"),
+		newSummaryTestEvent("D2", 30*time.Second, "on"),
+	}
+
+	expected := &dtos.RecordedDataSummary{
+		Window:     time.Minute,
+		EventCount: 4,
+		Windows: []dtos.SummaryWindow{
+			{
+				Start: 0,
+				Resources: []dtos.ResourceSummary{
+					{DeviceName: "D1", ResourceName: "Temperature", Count: 2, NumericCount: 2, Min: 10, Max: 20, Avg: 15},
+					{DeviceName: "D2", ResourceName: "Temperature", Count: 1},
+				},
+			},
+			{
+				Start: int64(time.Minute),
+				Resources: []dtos.ResourceSummary{
+					{DeviceName: "D1", ResourceName: "Temperature", Count: 1, NumericCount: 1, Min: 30, Max: 30, Avg: 30},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, toSummary(events, time.Minute))
+}
+
+func TestHttpController_ExportRecordedData_Summary(t *testing.T) {
+	recordedData := &dtos.RecordedData{
+		RecordedEvents: []coreDtos.Event{
+			newSummaryTestEvent("D1", 10*time.Second, "10"),
+			newSummaryTestEvent("D1", 20*time.Second, "20"),
+		},
+	}
+
+	target, mockDataManager, _ := createTargetAndMocks()
+	mockDataManager.On("ExportRecordedData").Return(recordedData, nil)
+
+	handler := http.HandlerFunc(WrapEchoHandler(t, target.exportRecordedData))
+
+	tests := []struct {
+		Name            string
+		Query           string
+		ExpectedStatus  int
+		ExpectedWindows int
+	}{
+		{"Default window", "?format=summary", http.StatusOK, 1},
+		{"Small window", "?format=summary&window=10s", http.StatusOK, 2},
+		{"Bad window", "?format=summary&window=bad", http.StatusBadRequest, 0},
+		{"Zero window", "?format=summary&window=0s", http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, dataRoute+test.Query, nil)
+			require.NoError(t, err)
+			testRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(testRecorder, req)
+			require.Equal(t, test.ExpectedStatus, testRecorder.Code)
+
+			if test.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, testRecorder.Body.String(), failedSummaryWindowValidate)
+				return
+			}
+
+			summary := dtos.RecordedDataSummary{}
+			require.NoError(t, json.Unmarshal(testRecorder.Body.Bytes(), &summary))
+			assert.Equal(t, 2, summary.EventCount)
+			assert.Len(t, summary.Windows, test.ExpectedWindows)
+		})
+	}
+}
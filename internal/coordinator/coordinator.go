@@ -0,0 +1,164 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package coordinator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	appInterfaces "github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
+	"github.com/edgexfoundry/app-record-replay/internal/interfaces"
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+)
+
+const (
+	// ClusterPeersAppSetting is the optional comma separated list of peer base URLs, i.e. http://node2:59712.
+	// When not set the peers are discovered via the registry.
+	ClusterPeersAppSetting = "ClusterPeers"
+	// ClusterServiceKeyPrefixAppSetting is the service key prefix used to discover peers via the registry
+	ClusterServiceKeyPrefixAppSetting = "ClusterServiceKeyPrefix"
+
+	defaultClusterServiceKeyPrefix = "app-record-replay"
+)
+
+var noPeersFoundError = errors.New("no peer instances configured or found in the registry")
+
+type coordinator struct {
+	appSvc     appInterfaces.ApplicationService
+	serviceKey string
+}
+
+// New is the factory function which instantiates a Coordinator. The service key is used to exclude
+// this instance from the peers discovered via the registry.
+func New(service appInterfaces.ApplicationService, serviceKey string) interfaces.Coordinator {
+	return &coordinator{
+		appSvc:     service,
+		serviceKey: serviceKey,
+	}
+}
+
+// Peers returns the base URLs of the peer instances, either configured or discovered via the registry
+func (c *coordinator) Peers() ([]string, error) {
+	settings := c.appSvc.ApplicationSettings()
+
+	var peers []string
+	if configured := settings[ClusterPeersAppSetting]; len(configured) > 0 {
+		for _, peer := range strings.Split(configured, ",") {
+			peer = strings.TrimSpace(peer)
+			if len(peer) > 0 {
+				peers = append(peers, strings.TrimSuffix(peer, "/"))
+			}
+		}
+	} else if registryClient := c.appSvc.RegistryClient(); registryClient != nil {
+		prefix := settings[ClusterServiceKeyPrefixAppSetting]
+		if len(prefix) == 0 {
+			prefix = defaultClusterServiceKeyPrefix
+		}
+
+		endpoints, err := registryClient.GetAllServiceEndpoints()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get service endpoints from registry: %v", err)
+		}
+
+		for _, endpoint := range endpoints {
+			if endpoint.ServiceId == c.serviceKey || !strings.HasPrefix(endpoint.ServiceId, prefix) {
+				continue
+			}
+			peers = append(peers, fmt.Sprintf("http://%s:%d", endpoint.Host, endpoint.Port))
+		}
+	}
+
+	if len(peers) == 0 {
+		return nil, noPeersFoundError
+	}
+
+	return peers, nil
+}
+
+// FanOut sends the request to the route on every peer instance concurrently and returns each peer's response
+// in the same order as Peers. An error is returned if the peers can't be determined.
+func (c *coordinator) FanOut(method string, route string, body []byte) ([]dtos.PeerResponse, error) {
+	peers, err := c.Peers()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: c.appSvc.RequestTimeout()}
+	responses := make([]dtos.PeerResponse, len(peers))
+
+	wg := sync.WaitGroup{}
+	for index, peer := range peers {
+		wg.Add(1)
+		go func(index int, peer string) {
+			defer wg.Done()
+			responses[index] = c.sendToPeer(client, peer, method, route, body)
+		}(index, peer)
+	}
+	wg.Wait()
+
+	c.appSvc.LoggingClient().Debugf("ARR Coordinator: %s %s sent to %d peers", method, route, len(peers))
+
+	return responses, nil
+}
+
+func (c *coordinator) sendToPeer(client *http.Client, peer string, method string, route string, body []byte) dtos.PeerResponse {
+	response := dtos.PeerResponse{Peer: peer}
+
+	request, err := http.NewRequest(method, peer+route, bytes.NewReader(body))
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	if len(body) > 0 {
+		request.Header.Set(common.ContentType, common.ContentTypeJSON)
+	}
+
+	result, err := client.Do(request)
+	if err != nil {
+		response.Error = err.Error()
+		c.appSvc.LoggingClient().Errorf("ARR Coordinator: %s %s failed for peer %s: %v", method, route, peer, err)
+		return response
+	}
+	defer result.Body.Close()
+
+	response.StatusCode = result.StatusCode
+
+	resultBody, err := io.ReadAll(result.Body)
+	if err != nil {
+		response.Error = fmt.Sprintf("failed to read response: %v", err)
+		return response
+	}
+
+	// Error responses are plain text, so only valid JSON is passed through as the body
+	switch {
+	case len(resultBody) == 0:
+	case result.StatusCode < http.StatusBadRequest && json.Valid(resultBody):
+		response.Body = resultBody
+	default:
+		response.Error = string(resultBody)
+	}
+
+	return response
+}
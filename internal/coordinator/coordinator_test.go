@@ -0,0 +1,173 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package coordinator
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/clients/logger"
+	"github.com/edgexfoundry/go-mod-registry/v3/pkg/types"
+	"github.com/edgexfoundry/go-mod-registry/v3/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoordinator_Peers(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Setting       string
+		Expected      []string
+		ExpectedError error
+	}{
+		{"Configured", " http://node1:59712/, http://node2:59712 ", []string{"http://node1:59712", "http://node2:59712"}, nil},
+		{"None", "", nil, noPeersFoundError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ClusterPeersAppSetting: test.Setting})
+			mockSdk.On("RegistryClient").Return(nil)
+
+			target := New(mockSdk, "app-record-replay")
+			actual, err := target.Peers()
+			require.Equal(t, test.ExpectedError, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestCoordinator_FanOut(t *testing.T) {
+	var receivedBody string
+	okPeer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v3/replay", r.URL.Path)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer okPeer.Close()
+
+	statusPeer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"running":true}`))
+	}))
+	defer statusPeer.Close()
+
+	errorPeer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("Replay failed: a replay is in progress"))
+	}))
+	defer errorPeer.Close()
+
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{
+		ClusterPeersAppSetting: okPeer.URL + "," + statusPeer.URL + "," + errorPeer.URL + ",http://127.0.0.1:1",
+	})
+	mockSdk.On("RequestTimeout").Return(5 * time.Second)
+	mockSdk.On("LoggingClient").Return(logger.NewMockClient())
+
+	target := New(mockSdk, "app-record-replay")
+	responses, err := target.FanOut(http.MethodPost, "/api/v3/replay", []byte(`{"replayRate":1}`))
+	require.NoError(t, err)
+	require.Len(t, responses, 4)
+
+	assert.Equal(t, `{"replayRate":1}`, receivedBody)
+
+	assert.Equal(t, okPeer.URL, responses[0].Peer)
+	assert.Equal(t, http.StatusAccepted, responses[0].StatusCode)
+	assert.Empty(t, responses[0].Error)
+
+	assert.Equal(t, http.StatusOK, responses[1].StatusCode)
+	assert.Equal(t, json.RawMessage(`{"running":true}`), responses[1].Body)
+
+	assert.Equal(t, http.StatusInternalServerError, responses[2].StatusCode)
+	assert.Equal(t, "Replay failed: a replay is in progress", responses[2].Error)
+	assert.Nil(t, responses[2].Body)
+
+	assert.Zero(t, responses[3].StatusCode)
+	assert.NotEmpty(t, responses[3].Error)
+}
+
+func TestCoordinator_FanOut_NoPeers(t *testing.T) {
+	mockSdk := &mocks.ApplicationService{}
+	mockSdk.On("ApplicationSettings").Return(map[string]string{})
+	mockSdk.On("RegistryClient").Return(nil)
+
+	target := New(mockSdk, "app-record-replay")
+	_, err := target.FanOut(http.MethodGet, "/api/v3/record", nil)
+	require.Equal(t, noPeersFoundError, err)
+	mockSdk.AssertNotCalled(t, "RequestTimeout", mock.Anything)
+}
+
+func TestCoordinator_Peers_Registry(t *testing.T) {
+	endpoints := []types.ServiceEndpoint{
+		{ServiceId: "app-record-replay", Host: "node1", Port: 59712},
+		{ServiceId: "app-record-replay-node2", Host: "node2", Port: 59712},
+		{ServiceId: "app-record-replay-node3", Host: "node3", Port: 59713},
+		{ServiceId: "core-data", Host: "node1", Port: 59880},
+	}
+
+	tests := []struct {
+		Name          string
+		Prefix        string
+		Registry      *fakeRegistry
+		Expected      []string
+		ExpectedError string
+	}{
+		{"Default prefix", "", &fakeRegistry{endpoints: endpoints}, []string{"http://node2:59712", "http://node3:59713"}, ""},
+		{"Custom prefix", "core", &fakeRegistry{endpoints: endpoints}, []string{"http://node1:59880"}, ""},
+		{"Registry error", "", &fakeRegistry{err: errors.New("registry unavailable")}, nil, "failed to get service endpoints"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mockSdk := &mocks.ApplicationService{}
+			mockSdk.On("ApplicationSettings").Return(map[string]string{ClusterServiceKeyPrefixAppSetting: test.Prefix})
+			mockSdk.On("RegistryClient").Return(test.Registry)
+
+			target := New(mockSdk, "app-record-replay")
+			actual, err := target.Peers()
+			if len(test.ExpectedError) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+// fakeRegistry embeds a nil registry Client so only the method used by the coordinator needs implementing
+type fakeRegistry struct {
+	registry.Client
+	endpoints []types.ServiceEndpoint
+	err       error
+}
+
+func (r *fakeRegistry) GetAllServiceEndpoints() ([]types.ServiceEndpoint, error) {
+	return r.endpoints, r.err
+}
@@ -0,0 +1,43 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+import "time"
+
+// Clock defines the interface for the time source used to pace and timestamp record and replay sessions
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Since returns the time elapsed since t
+	Since(t time.Time) time.Duration
+	// Sleep pauses the calling goroutine for at least the duration d
+	Sleep(d time.Duration)
+}
+
+// VirtualClock defines the interface for a Clock whose time only moves when it is advanced, so replay timing
+// can be driven deterministically by tests and simulation frameworks
+type VirtualClock interface {
+	// Now returns the current virtual time
+	Now() time.Time
+	// Since returns the virtual time elapsed since t
+	Since(t time.Time) time.Duration
+	// Sleep pauses the calling goroutine until the virtual time has been advanced by at least the duration d
+	Sleep(d time.Duration)
+	// Advance moves the virtual time forward by the duration d, waking any sleepers that are due, and returns
+	// the new virtual time
+	Advance(d time.Duration) time.Time
+}
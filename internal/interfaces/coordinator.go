@@ -0,0 +1,28 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package interfaces
+
+import "github.com/edgexfoundry/app-record-replay/pkg/dtos"
+
+// Coordinator defines the interface for implementations that fan out record and replay commands to peer instances
+type Coordinator interface {
+	// Peers returns the base URLs of the peer instances, either configured or discovered via the registry
+	Peers() ([]string, error)
+	// FanOut sends the request to the route on every peer instance concurrently and returns each peer's response
+	// in the same order as Peers. An error is returned if the peers can't be determined.
+	FanOut(method string, route string, body []byte) ([]dtos.PeerResponse, error)
+}
@@ -15,29 +15,140 @@
 
 package interfaces
 
-import "github.com/edgexfoundry/app-record-replay/pkg/dtos"
+import (
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
 
 // DataManager defines the interface for implementations that records and replays captured data
 type DataManager interface {
-	// StartRecording starts a recording session based on the values in the request.
-	// An error is returned if the request data is incomplete or a record or replay session is currently running.
+	// StartRecording starts a recording session based on the values in the request, or queues it if a session is
+	// running and queueing is enabled. An error is returned if the request data is incomplete or a record or replay
+	// session is currently running and the session can't be queued.
 	StartRecording(request dtos.RecordRequest) error
 	// CancelRecording cancels the current recording session
 	CancelRecording() error
 	// RecordingStatus returns the status of the current recording session
 	RecordingStatus() dtos.RecordStatus
-	// StartReplay starts a replay session based on the values in the request
-	// An error is returned if the request data is incomplete or a record or replay session is currently running.
+	// PushRecordEvents includes the Events pushed over REST in the active recording. The Events pass through the
+	// recording's pipeline in the background, as if received from the MessageBus. An error is returned if no
+	// recording is running or the recording is opaque.
+	PushRecordEvents(request dtos.RecordPushRequest) error
+	// StartReplay starts a replay session based on the values in the request, or queues it if a session is running
+	// and queueing is enabled. An error is returned if the request data is incomplete or a record or replay session
+	// is currently running and the session can't be queued.
 	StartReplay(request dtos.ReplayRequest) error
 	// CancelReplay cancels the current replay session
 	CancelReplay() error
+	// TriggerReplay starts publishing the replay in standby. The replay's duration and timing start from the trigger.
+	// An error is returned if no replay is in standby or the replay fails to start.
+	TriggerReplay() error
+	// SeekReplay jumps the active replay session to the Event at the offset or annotation in the request. An error is
+	// returned if no replay is running, the replay can't seek or there is no Event at the point.
+	SeekReplay(request dtos.ReplaySeekRequest) (*dtos.ReplaySeekResponse, error)
+	// ResumeReplay continues the paused replay session until the next Event matching a breakpoint. An error is
+	// returned if no replay is paused.
+	ResumeReplay() error
+	// StepReplay publishes the Event the replay session is paused before and pauses again before the next Event.
+	// An error is returned if no replay is paused.
+	StepReplay() error
 	// ReplayStatus returns the status of the current replay session
 	ReplayStatus() dtos.ReplayStatus
+	// ShadowReport returns the comparison report for the current or last shadow mode replay session.
+	// An error is returned if no shadow mode replay has been run
+	ShadowReport() (*dtos.ShadowReport, error)
+	// LatencyReport returns the end-to-end latency report for the current or last latency measurement replay session.
+	// An error is returned if no latency measurement replay has been run
+	LatencyReport() (*dtos.LatencyReport, error)
+	// Inject publishes the one-off Events and raw messages in the request immediately, independent of any record or
+	// replay session. An error is returned if a publish fails, in which case the response has the counts published
+	// before it.
+	Inject(request dtos.InjectRequest) (dtos.InjectResponse, error)
+	// SelfTest generates synthetic Events, records and replays them through an internal loopback pipeline and
+	// reports whether they made the round trip unchanged. The loopback neither touches the MessageBus nor disturbs
+	// the recorded data or a running session. An error is returned if the request is invalid or the loopback
+	// sessions can't be started.
+	SelfTest(request dtos.SelfTestRequest) (*dtos.SelfTestReport, error)
 	// ExportRecordedData returns the data for the last record session
 	// An error is returned if the no record session was run or a record session is currently running
 	ExportRecordedData() (*dtos.RecordedData, error)
 	// ImportRecordedData imports data from a previously exported record session.
 	// If overwrite parameter is true then Device Profiles and/or Devices will be overwritten.
-	// An error is returned if a record or replay session is currently running or the data is incomplete
-	ImportRecordedData(data *dtos.RecordedData, overwrite bool) error
+	// An error is returned if a record or replay session is currently running or the data is incomplete. An error
+	// listing the ProvisioningFailures is returned once imported if some of the Device Profiles or Devices failed to
+	// be provisioned, unless rollback is true, in which case the Device Profiles and Devices added by the import are
+	// deleted and the data isn't imported.
+	ImportRecordedData(data *dtos.RecordedData, overwrite bool, rollback bool) error
+	// AppendRecordedData appends the Events of the data after the last recorded or imported Events, shifting their
+	// Origins so the first appended Event follows the last recorded Event after the gap. The data's Device Profiles
+	// and Devices are imported, and rolled back, the same as by ImportRecordedData. An error is returned if there is no recorded data,
+	// either is opaque, or the recorded data can't be replaced.
+	AppendRecordedData(data *dtos.RecordedData, gap time.Duration, overwrite bool, rollback bool) error
+	// DeleteRecordedEvents deletes the recorded Events with Origins from the start up to, but not including, the end,
+	// in nanoseconds since the epoch, limited to the device when the device name is set. An error is returned if the
+	// range is empty, there is no recorded data, it is opaque, or it can't be replaced.
+	DeleteRecordedEvents(start int64, end int64, deviceName string) (*dtos.DeleteEventsResult, error)
+	// AssertRecordedData checks the assertions against the recorded data in the request or, if not set,
+	// the last recorded or imported data. An error is returned if there is no data to check.
+	AssertRecordedData(request dtos.AssertRequest) (*dtos.AssertResponse, error)
+	// ValidateRecordedData checks every recorded Event against the EdgeX contract validation rules and, if recorded,
+	// the Device Profiles and Devices, returning the errors of each invalid Event. An error is returned if there is
+	// no recorded data or it is opaque.
+	ValidateRecordedData() (*dtos.ValidationReport, error)
+	// RecordedDataGaps returns the periods of the recorded data where a device resource produced no Readings for
+	// longer than the threshold. An error is returned if the threshold isn't positive or there is no recorded data
+	// or it is opaque.
+	RecordedDataGaps(threshold time.Duration) (*dtos.GapReport, error)
+	// AddAnnotation adds the annotation to the recording in progress or, if none, the recorded data. Annotations
+	// without a timestamp mark the current time. An error is returned if the label isn't set, there is no recording
+	// or the recorded data is locked.
+	AddAnnotation(annotation dtos.Annotation) (*dtos.Annotation, error)
+	// SearchAnnotations returns the annotations with labels matching the path.Match pattern in the recording in
+	// progress, the recorded data and the recordings in the segment store. An empty pattern matches all the
+	// annotations. An error is returned if the pattern is malformed or the segment store can't be read.
+	SearchAnnotations(pattern string) (*dtos.AnnotationSearchResult, error)
+	// CompareSeries returns the numeric Readings of the resource, limited to the device when set, from the two
+	// recordings as paired time series for plotting overlays. Each recording is either the recorded data, named
+	// current or by its own name, or the recording with the name in the segment store. An error is returned if the
+	// resource isn't set or either recording isn't found or can't be read.
+	CompareSeries(a string, b string, resourceName string, deviceName string) (*dtos.ComparisonSeries, error)
+	// LockRecordedData marks the recorded data as read-only so it can't be overwritten by a new recording or import
+	// until it is unlocked. An error is returned if there is no recorded data to lock
+	LockRecordedData() error
+	// UnlockRecordedData removes the read-only lock from the recorded data
+	UnlockRecordedData()
+	// RecordingMetadata returns the metadata for the recording in progress or, if none, the last recorded or
+	// imported data. An error is returned if there is no recording or the imported data has no metadata.
+	RecordingMetadata() (*dtos.RecordingMetadata, error)
+	// PayloadSizeReport returns the payload size distribution of the Events captured by the current or last recording
+	// with the given number of largest devices and Readings, defaulting to 10 when 0 and limited to 100. An error is
+	// returned if no recording of Events has been run.
+	PayloadSizeReport(count int) (*dtos.PayloadSizeReport, error)
+	// StoreStats returns the memory and storage statistics of the recording store.
+	// An error is returned if the segment store can't be scanned
+	StoreStats() (dtos.StoreStats, error)
+	// PipelineStats returns the internal statistics of the recording pipeline and the replay publishing, such as the
+	// batch flushes, the publish queue depths and the last pipeline errors
+	PipelineStats() dtos.PipelineStats
+	// CompactStore releases the unused memory held by the recorded data and deletes the partially written segments
+	// and empty recording directories left in the segment store. An error is returned if a replay is in progress
+	CompactStore() (*dtos.CompactResult, error)
+	// AddCommandPipeline adds the functions pipeline which handles the record and replay commands received on the
+	// MessageBus, if the CommandTopic App Setting is set. An error is returned if the setting is invalid or the
+	// pipeline can't be added.
+	AddCommandPipeline() error
+	// MissingDependencies returns the Device Profiles and Device Services which don't exist in Core Metadata.
+	// An error is returned if Core Metadata can't be checked
+	MissingDependencies(profiles []string, deviceServices []string) (*dtos.MissingDependencies, error)
+	// DependencyGraph returns the Device Profile and Device Service each recorded Device depends on, and which of
+	// them are missing from Core Metadata.
+	// An error is returned if there is no recorded data or Core Metadata can't be checked
+	DependencyGraph() (*dtos.DependencyGraph, error)
+	// Backup returns the content of the recording store, i.e. the recorded data and the named recordings in the
+	// segment store. An error is returned if a recording is in progress
+	Backup() (*dtos.StoreBackup, error)
+	// Restore writes the backup's recordings into the segment store and imports its recorded data.
+	// An error is returned if a record or replay session is in progress or the backup can't be restored
+	Restore(backup *dtos.StoreBackup, overwrite bool) (*dtos.RestoreResult, error)
 }
@@ -6,6 +6,8 @@ import (
 	dtos "github.com/edgexfoundry/app-record-replay/pkg/dtos"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // DataManager is an autogenerated mock type for the DataManager type
@@ -13,6 +15,46 @@ type DataManager struct {
 	mock.Mock
 }
 
+// AddCommandPipeline provides a mock function with given fields:
+func (_m *DataManager) AddCommandPipeline() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AssertRecordedData provides a mock function with given fields: request
+func (_m *DataManager) AssertRecordedData(request dtos.AssertRequest) (*dtos.AssertResponse, error) {
+	ret := _m.Called(request)
+
+	var r0 *dtos.AssertResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(dtos.AssertRequest) (*dtos.AssertResponse, error)); ok {
+		return rf(request)
+	}
+	if rf, ok := ret.Get(0).(func(dtos.AssertRequest) *dtos.AssertResponse); ok {
+		r0 = rf(request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.AssertResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(dtos.AssertRequest) error); ok {
+		r1 = rf(request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CancelRecording provides a mock function with given fields:
 func (_m *DataManager) CancelRecording() error {
 	ret := _m.Called()
@@ -67,13 +109,221 @@ func (_m *DataManager) ExportRecordedData() (*dtos.RecordedData, error) {
 	return r0, r1
 }
 
-// ImportRecordedData provides a mock function with given fields: data, overwrite
-func (_m *DataManager) ImportRecordedData(data *dtos.RecordedData, overwrite bool) error {
-	ret := _m.Called(data, overwrite)
+// ImportRecordedData provides a mock function with given fields: data, overwrite, rollback
+func (_m *DataManager) ImportRecordedData(data *dtos.RecordedData, overwrite bool, rollback bool) error {
+	ret := _m.Called(data, overwrite, rollback)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dtos.RecordedData, bool, bool) error); ok {
+		r0 = rf(data, overwrite, rollback)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddAnnotation provides a mock function with given fields: annotation
+func (_m *DataManager) AddAnnotation(annotation dtos.Annotation) (*dtos.Annotation, error) {
+	ret := _m.Called(annotation)
+
+	var r0 *dtos.Annotation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(dtos.Annotation) (*dtos.Annotation, error)); ok {
+		return rf(annotation)
+	}
+	if rf, ok := ret.Get(0).(func(dtos.Annotation) *dtos.Annotation); ok {
+		r0 = rf(annotation)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.Annotation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(dtos.Annotation) error); ok {
+		r1 = rf(annotation)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AppendRecordedData provides a mock function with given fields: data, gap, overwrite, rollback
+func (_m *DataManager) AppendRecordedData(data *dtos.RecordedData, gap time.Duration, overwrite bool, rollback bool) error {
+	ret := _m.Called(data, gap, overwrite, rollback)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dtos.RecordedData, time.Duration, bool, bool) error); ok {
+		r0 = rf(data, gap, overwrite, rollback)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRecordedEvents provides a mock function with given fields: start, end, deviceName
+func (_m *DataManager) DeleteRecordedEvents(start int64, end int64, deviceName string) (*dtos.DeleteEventsResult, error) {
+	ret := _m.Called(start, end, deviceName)
+
+	var r0 *dtos.DeleteEventsResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64, string) (*dtos.DeleteEventsResult, error)); ok {
+		return rf(start, end, deviceName)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64, string) *dtos.DeleteEventsResult); ok {
+		r0 = rf(start, end, deviceName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.DeleteEventsResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64, string) error); ok {
+		r1 = rf(start, end, deviceName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchAnnotations provides a mock function with given fields: pattern
+func (_m *DataManager) SearchAnnotations(pattern string) (*dtos.AnnotationSearchResult, error) {
+	ret := _m.Called(pattern)
+
+	var r0 *dtos.AnnotationSearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*dtos.AnnotationSearchResult, error)); ok {
+		return rf(pattern)
+	}
+	if rf, ok := ret.Get(0).(func(string) *dtos.AnnotationSearchResult); ok {
+		r0 = rf(pattern)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.AnnotationSearchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(pattern)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompareSeries provides a mock function with given fields: a, b, resourceName, deviceName
+func (_m *DataManager) CompareSeries(a string, b string, resourceName string, deviceName string) (*dtos.ComparisonSeries, error) {
+	ret := _m.Called(a, b, resourceName, deviceName)
+
+	var r0 *dtos.ComparisonSeries
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (*dtos.ComparisonSeries, error)); ok {
+		return rf(a, b, resourceName, deviceName)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) *dtos.ComparisonSeries); ok {
+		r0 = rf(a, b, resourceName, deviceName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.ComparisonSeries)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(a, b, resourceName, deviceName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Inject provides a mock function with given fields: request
+func (_m *DataManager) Inject(request dtos.InjectRequest) (dtos.InjectResponse, error) {
+	ret := _m.Called(request)
+
+	var r0 dtos.InjectResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(dtos.InjectRequest) (dtos.InjectResponse, error)); ok {
+		return rf(request)
+	}
+	if rf, ok := ret.Get(0).(func(dtos.InjectRequest) dtos.InjectResponse); ok {
+		r0 = rf(request)
+	} else {
+		r0 = ret.Get(0).(dtos.InjectResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(dtos.InjectRequest) error); ok {
+		r1 = rf(request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SelfTest provides a mock function with given fields: request
+func (_m *DataManager) SelfTest(request dtos.SelfTestRequest) (*dtos.SelfTestReport, error) {
+	ret := _m.Called(request)
+
+	var r0 *dtos.SelfTestReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(dtos.SelfTestRequest) (*dtos.SelfTestReport, error)); ok {
+		return rf(request)
+	}
+	if rf, ok := ret.Get(0).(func(dtos.SelfTestRequest) *dtos.SelfTestReport); ok {
+		r0 = rf(request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.SelfTestReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(dtos.SelfTestRequest) error); ok {
+		r1 = rf(request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LatencyReport provides a mock function with given fields:
+func (_m *DataManager) LatencyReport() (*dtos.LatencyReport, error) {
+	ret := _m.Called()
+
+	var r0 *dtos.LatencyReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*dtos.LatencyReport, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *dtos.LatencyReport); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.LatencyReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockRecordedData provides a mock function with given fields:
+func (_m *DataManager) LockRecordedData() error {
+	ret := _m.Called()
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*dtos.RecordedData, bool) error); ok {
-		r0 = rf(data, overwrite)
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -81,6 +331,266 @@ func (_m *DataManager) ImportRecordedData(data *dtos.RecordedData, overwrite boo
 	return r0
 }
 
+// PushRecordEvents provides a mock function with given fields: request
+func (_m *DataManager) PushRecordEvents(request dtos.RecordPushRequest) error {
+	ret := _m.Called(request)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dtos.RecordPushRequest) error); ok {
+		r0 = rf(request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RecordingMetadata provides a mock function with given fields:
+func (_m *DataManager) RecordingMetadata() (*dtos.RecordingMetadata, error) {
+	ret := _m.Called()
+
+	var r0 *dtos.RecordingMetadata
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*dtos.RecordingMetadata, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *dtos.RecordingMetadata); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.RecordingMetadata)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PayloadSizeReport provides a mock function with given fields: count
+func (_m *DataManager) PayloadSizeReport(count int) (*dtos.PayloadSizeReport, error) {
+	ret := _m.Called(count)
+
+	var r0 *dtos.PayloadSizeReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) (*dtos.PayloadSizeReport, error)); ok {
+		return rf(count)
+	}
+	if rf, ok := ret.Get(0).(func(int) *dtos.PayloadSizeReport); ok {
+		r0 = rf(count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.PayloadSizeReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordedDataGaps provides a mock function with given fields: threshold
+func (_m *DataManager) RecordedDataGaps(threshold time.Duration) (*dtos.GapReport, error) {
+	ret := _m.Called(threshold)
+
+	var r0 *dtos.GapReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Duration) (*dtos.GapReport, error)); ok {
+		return rf(threshold)
+	}
+	if rf, ok := ret.Get(0).(func(time.Duration) *dtos.GapReport); ok {
+		r0 = rf(threshold)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.GapReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Duration) error); ok {
+		r1 = rf(threshold)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StoreStats provides a mock function with given fields:
+func (_m *DataManager) StoreStats() (dtos.StoreStats, error) {
+	ret := _m.Called()
+
+	var r0 dtos.StoreStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (dtos.StoreStats, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() dtos.StoreStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(dtos.StoreStats)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompactStore provides a mock function with given fields:
+func (_m *DataManager) CompactStore() (*dtos.CompactResult, error) {
+	ret := _m.Called()
+
+	var r0 *dtos.CompactResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*dtos.CompactResult, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *dtos.CompactResult); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.CompactResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MissingDependencies provides a mock function with given fields: profiles, deviceServices
+func (_m *DataManager) MissingDependencies(profiles []string, deviceServices []string) (*dtos.MissingDependencies, error) {
+	ret := _m.Called(profiles, deviceServices)
+
+	var r0 *dtos.MissingDependencies
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string, []string) (*dtos.MissingDependencies, error)); ok {
+		return rf(profiles, deviceServices)
+	}
+	if rf, ok := ret.Get(0).(func([]string, []string) *dtos.MissingDependencies); ok {
+		r0 = rf(profiles, deviceServices)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.MissingDependencies)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string, []string) error); ok {
+		r1 = rf(profiles, deviceServices)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DependencyGraph provides a mock function with given fields:
+func (_m *DataManager) DependencyGraph() (*dtos.DependencyGraph, error) {
+	ret := _m.Called()
+
+	var r0 *dtos.DependencyGraph
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*dtos.DependencyGraph, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *dtos.DependencyGraph); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.DependencyGraph)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Backup provides a mock function with given fields:
+func (_m *DataManager) Backup() (*dtos.StoreBackup, error) {
+	ret := _m.Called()
+
+	var r0 *dtos.StoreBackup
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*dtos.StoreBackup, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *dtos.StoreBackup); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.StoreBackup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: backup, overwrite
+func (_m *DataManager) Restore(backup *dtos.StoreBackup, overwrite bool) (*dtos.RestoreResult, error) {
+	ret := _m.Called(backup, overwrite)
+
+	var r0 *dtos.RestoreResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*dtos.StoreBackup, bool) (*dtos.RestoreResult, error)); ok {
+		return rf(backup, overwrite)
+	}
+	if rf, ok := ret.Get(0).(func(*dtos.StoreBackup, bool) *dtos.RestoreResult); ok {
+		r0 = rf(backup, overwrite)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.RestoreResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*dtos.StoreBackup, bool) error); ok {
+		r1 = rf(backup, overwrite)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PipelineStats provides a mock function with given fields:
+func (_m *DataManager) PipelineStats() dtos.PipelineStats {
+	ret := _m.Called()
+
+	var r0 dtos.PipelineStats
+	if rf, ok := ret.Get(0).(func() dtos.PipelineStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(dtos.PipelineStats)
+	}
+
+	return r0
+}
+
 // RecordingStatus provides a mock function with given fields:
 func (_m *DataManager) RecordingStatus() dtos.RecordStatus {
 	ret := _m.Called()
@@ -109,6 +619,32 @@ func (_m *DataManager) ReplayStatus() dtos.ReplayStatus {
 	return r0
 }
 
+// ShadowReport provides a mock function with given fields:
+func (_m *DataManager) ShadowReport() (*dtos.ShadowReport, error) {
+	ret := _m.Called()
+
+	var r0 *dtos.ShadowReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*dtos.ShadowReport, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *dtos.ShadowReport); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.ShadowReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StartRecording provides a mock function with given fields: request
 func (_m *DataManager) StartRecording(request dtos.RecordRequest) error {
 	ret := _m.Called(request)
@@ -137,6 +673,105 @@ func (_m *DataManager) StartReplay(request dtos.ReplayRequest) error {
 	return r0
 }
 
+// SeekReplay provides a mock function with given fields: request
+func (_m *DataManager) SeekReplay(request dtos.ReplaySeekRequest) (*dtos.ReplaySeekResponse, error) {
+	ret := _m.Called(request)
+
+	var r0 *dtos.ReplaySeekResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(dtos.ReplaySeekRequest) (*dtos.ReplaySeekResponse, error)); ok {
+		return rf(request)
+	}
+	if rf, ok := ret.Get(0).(func(dtos.ReplaySeekRequest) *dtos.ReplaySeekResponse); ok {
+		r0 = rf(request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.ReplaySeekResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(dtos.ReplaySeekRequest) error); ok {
+		r1 = rf(request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResumeReplay provides a mock function with given fields:
+func (_m *DataManager) ResumeReplay() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StepReplay provides a mock function with given fields:
+func (_m *DataManager) StepReplay() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TriggerReplay provides a mock function with given fields:
+func (_m *DataManager) TriggerReplay() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UnlockRecordedData provides a mock function with given fields:
+func (_m *DataManager) UnlockRecordedData() {
+	_m.Called()
+}
+
+// ValidateRecordedData provides a mock function with given fields:
+func (_m *DataManager) ValidateRecordedData() (*dtos.ValidationReport, error) {
+	ret := _m.Called()
+
+	var r0 *dtos.ValidationReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*dtos.ValidationReport, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *dtos.ValidationReport); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dtos.ValidationReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewDataManager interface {
 	mock.TestingT
 	Cleanup(func())
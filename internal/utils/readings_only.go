@@ -0,0 +1,134 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package utils
+
+import (
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+// CompactEvent returns the Event as the reading tuples of a readings-only recording. False is returned if the Event
+// wasn't stripped down to its readings, i.e. it or its Readings have Ids, Tags or an API version, which would be lost.
+func CompactEvent(event coreDtos.Event) (dtos.ReadingsOnlyEvent, bool) {
+	if len(event.Id) > 0 || len(event.ApiVersion) > 0 || len(event.Tags) > 0 {
+		return dtos.ReadingsOnlyEvent{}, false
+	}
+
+	compact := dtos.ReadingsOnlyEvent{
+		DeviceName:  event.DeviceName,
+		ProfileName: event.ProfileName,
+		SourceName:  event.SourceName,
+		Origin:      event.Origin,
+		Readings:    make([]dtos.ReadingTuple, len(event.Readings)),
+	}
+
+	for index, reading := range event.Readings {
+		if len(reading.Id) > 0 || len(reading.Tags) > 0 {
+			return dtos.ReadingsOnlyEvent{}, false
+		}
+
+		// Null readings can't be recognized other than by their empty value, so those are kept whole along with
+		// the readings that aren't simple values
+		if reading.BinaryValue != nil || len(reading.MediaType) > 0 || reading.ObjectValue != nil || len(reading.Value) == 0 {
+			compact.Readings[index] = dtos.ReadingTuple{Reading: &event.Readings[index]}
+			continue
+		}
+
+		tuple := dtos.ReadingTuple{
+			ResourceName: reading.ResourceName,
+			ValueType:    reading.ValueType,
+			Value:        reading.Value,
+			OriginOffset: reading.Origin - event.Origin,
+			Units:        reading.Units,
+		}
+		if reading.DeviceName != event.DeviceName {
+			tuple.DeviceName = reading.DeviceName
+		}
+		if reading.ProfileName != event.ProfileName {
+			tuple.ProfileName = reading.ProfileName
+		}
+		compact.Readings[index] = tuple
+	}
+
+	return compact, true
+}
+
+// CompactEvents returns the Events as the reading tuples of a readings-only recording. False is returned if any of
+// the Events wasn't stripped down to its readings. See CompactEvent.
+func CompactEvents(events []coreDtos.Event) ([]dtos.ReadingsOnlyEvent, bool) {
+	if len(events) == 0 {
+		return nil, false
+	}
+
+	compact := make([]dtos.ReadingsOnlyEvent, len(events))
+	for index, event := range events {
+		var ok bool
+		if compact[index], ok = CompactEvent(event); !ok {
+			return nil, false
+		}
+	}
+
+	return compact, true
+}
+
+// ExpandEvent returns the Event held by the reading tuples of a readings-only recording, still stripped of the Ids
+// and API version of the full Event envelope, which are only reconstructed when replayed
+func ExpandEvent(compact dtos.ReadingsOnlyEvent) coreDtos.Event {
+	event := coreDtos.Event{
+		DeviceName:  compact.DeviceName,
+		ProfileName: compact.ProfileName,
+		SourceName:  compact.SourceName,
+		Origin:      compact.Origin,
+		Readings:    make([]coreDtos.BaseReading, len(compact.Readings)),
+	}
+
+	for index, tuple := range compact.Readings {
+		if tuple.Reading != nil {
+			event.Readings[index] = *tuple.Reading
+			continue
+		}
+
+		reading := coreDtos.BaseReading{
+			Origin:        compact.Origin + tuple.OriginOffset,
+			DeviceName:    tuple.DeviceName,
+			ResourceName:  tuple.ResourceName,
+			ProfileName:   tuple.ProfileName,
+			ValueType:     tuple.ValueType,
+			Units:         tuple.Units,
+			SimpleReading: coreDtos.SimpleReading{Value: tuple.Value},
+		}
+		if len(reading.DeviceName) == 0 {
+			reading.DeviceName = compact.DeviceName
+		}
+		if len(reading.ProfileName) == 0 {
+			reading.ProfileName = compact.ProfileName
+		}
+		event.Readings[index] = reading
+	}
+
+	return event
+}
+
+// ExpandEvents returns the Events held by the reading tuples of a readings-only recording. See ExpandEvent.
+func ExpandEvents(compact []dtos.ReadingsOnlyEvent) []coreDtos.Event {
+	events := make([]coreDtos.Event, len(compact))
+	for index, event := range compact {
+		events[index] = ExpandEvent(event)
+	}
+
+	return events
+}
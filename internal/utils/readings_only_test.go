@@ -0,0 +1,98 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStrippedEvent returns an Event stripped down to its readings, as held by a readings-only recording
+func newStrippedEvent(t *testing.T) coreDtos.Event {
+	event := coreDtos.NewEvent("profile", "device", "source")
+	require.NoError(t, event.AddSimpleReading("Temperature", common.ValueTypeInt32, int32(21)))
+	event.AddBinaryReading("Image", []byte{1, 2, 3}, "image/png")
+	event.Readings = append(event.Readings, coreDtos.NewNullReading("profile", "device", "Missing", common.ValueTypeString))
+	require.NoError(t, event.AddSimpleReading("Humidity", common.ValueTypeInt32, int32(50)))
+	event.Readings[3].DeviceName = "other-device"
+
+	event.Id = ""
+	event.ApiVersion = ""
+	for index := range event.Readings {
+		event.Readings[index].Id = ""
+		event.Readings[index].Origin = event.Origin
+	}
+	event.Readings[3].Origin += 5
+	return event
+}
+
+func TestCompactEvent_RoundTrip(t *testing.T) {
+	event := newStrippedEvent(t)
+
+	compact, ok := CompactEvent(event)
+	require.True(t, ok)
+	require.Len(t, compact.Readings, 4)
+	assert.Equal(t, dtos.ReadingTuple{ResourceName: "Temperature", ValueType: common.ValueTypeInt32, Value: "21"}, compact.Readings[0])
+	assert.NotNil(t, compact.Readings[1].Reading)
+	assert.NotNil(t, compact.Readings[2].Reading)
+	assert.Equal(t, "other-device", compact.Readings[3].DeviceName)
+	assert.Equal(t, int64(5), compact.Readings[3].OriginOffset)
+
+	// The Event is the same once marshaled and expanded, including its binary and null Readings
+	data, err := json.Marshal(compact)
+	require.NoError(t, err)
+	decoded := dtos.ReadingsOnlyEvent{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	expected, err := json.Marshal(event)
+	require.NoError(t, err)
+	actual, err := json.Marshal(ExpandEvent(decoded))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+func TestCompactEvents_NotStripped(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Strip func(event *coreDtos.Event)
+	}{
+		{"Event Id", func(event *coreDtos.Event) { event.Id = "id" }},
+		{"API version", func(event *coreDtos.Event) { event.ApiVersion = common.ApiVersion }},
+		{"Event Tags", func(event *coreDtos.Event) { event.Tags = coreDtos.Tags{"site": "north"} }},
+		{"Reading Id", func(event *coreDtos.Event) { event.Readings[0].Id = "id" }},
+		{"Reading Tags", func(event *coreDtos.Event) { event.Readings[0].Tags = coreDtos.Tags{"site": "north"} }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			event := newStrippedEvent(t)
+			test.Strip(&event)
+
+			_, ok := CompactEvents([]coreDtos.Event{newStrippedEvent(t), event})
+			assert.False(t, ok)
+		})
+	}
+
+	_, ok := CompactEvents(nil)
+	assert.False(t, ok)
+}
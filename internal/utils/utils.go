@@ -16,6 +16,11 @@
 
 package utils
 
+import "runtime/debug"
+
+// ContractsModulePath is the path of the EdgeX contracts module, whose version identifies the EdgeX stack version
+const ContractsModulePath = "github.com/edgexfoundry/go-mod-core-contracts/v3"
+
 // SliceToMap converts a Slice of T to a Map of pointer to T where
 // the key is a string retrieved by the passed in keyFunc
 func SliceToMap[T any](slice []T, keyFunc func(T) string) map[string]*T {
@@ -35,3 +40,20 @@ func MapToSlice[T any](m map[string]*T) []T {
 
 	return slice
 }
+
+// ModuleVersion returns the version of the module the service was built with. False is returned if the version
+// isn't available, which is the case for some test binaries built without module support.
+func ModuleVersion(path string) (string, bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", false
+	}
+
+	for _, module := range info.Deps {
+		if module.Path == path {
+			return module.Version, true
+		}
+	}
+
+	return "", false
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 
 	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg"
+	"github.com/edgexfoundry/app-functions-sdk-go/v3/pkg/interfaces"
 	"github.com/edgexfoundry/app-record-replay/internal/app"
 )
 
@@ -29,6 +30,12 @@ const (
 
 func main() {
 	app := app.New()
-	code := app.CreateAndRunAppService(serviceKey, pkg.NewAppService)
+	code := app.CreateAndRunAppService(serviceKey, newAppService)
 	os.Exit(code)
 }
+
+// newAppService creates the service with raw bytes as the target type, so the functions pipeline receives the
+// message payloads undecoded and opaque recordings can capture them verbatim.
+func newAppService(serviceKey string) (interfaces.ApplicationService, bool) {
+	return pkg.NewAppServiceWithTargetType(serviceKey, &[]byte{})
+}
@@ -0,0 +1,136 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+// JobStatus returns the status of the job, see GET /api/v3/jobs/{id}
+func (c *Client) JobStatus(ctx context.Context, id string) (dtos.JobStatus, error) {
+	status := dtos.JobStatus{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: jobsRoute + "/" + url.PathEscape(id),
+		expected: []int{http.StatusOK}}, nil, &status)
+	return status, err
+}
+
+// CancelJob cancels the running job, see DELETE /api/v3/jobs/{id}
+func (c *Client) CancelJob(ctx context.Context, id string) (dtos.JobStatus, error) {
+	status := dtos.JobStatus{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodDelete, path: jobsRoute + "/" + url.PathEscape(id),
+		expected: []int{http.StatusAccepted}}, nil, &status)
+	return status, err
+}
+
+// WaitForJob polls the status of the job at the interval until it has finished or the context is done, returning
+// its final status. A job which finished with a failed state is returned without error, so the caller can inspect it.
+func (c *Client) WaitForJob(ctx context.Context, id string, interval time.Duration) (dtos.JobStatus, error) {
+	for {
+		status, err := c.JobStatus(ctx, id)
+		if err != nil || status.State != dtos.JobStateRunning {
+			return status, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Inject publishes the Events and messages directly, see POST /api/v3/inject
+func (c *Client) Inject(ctx context.Context, request dtos.InjectRequest) (dtos.InjectResponse, error) {
+	response := dtos.InjectResponse{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: injectRoute, expected: []int{http.StatusOK}},
+		request, &response)
+	return response, err
+}
+
+// Backup returns the zip archive backing up the recording store, see POST /api/v3/admin/backup
+func (c *Client) Backup(ctx context.Context) ([]byte, error) {
+	resp, err := c.do(ctx, apiRequest{method: http.MethodPost, path: backupRoute, expected: []int{http.StatusOK}})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.body, nil
+}
+
+// Restore restores the backup archive, keeping the Device Profiles and Devices already in Core Metadata when
+// keepExisting is true, see POST /api/v3/admin/restore. The result lists any Device Profiles or Devices which
+// failed to be provisioned.
+func (c *Client) Restore(ctx context.Context, archive []byte, keepExisting bool) (dtos.RestoreResult, error) {
+	result := dtos.RestoreResult{}
+	resp, err := c.do(ctx, apiRequest{method: http.MethodPost, path: restoreRoute,
+		query:    url.Values{"overwrite": {strconv.FormatBool(!keepExisting)}},
+		header:   withHeader(nil, "Content-Type", "application/zip"),
+		body:     archive,
+		expected: []int{http.StatusOK, http.StatusMultiStatus}})
+	if err != nil {
+		return result, err
+	}
+
+	if err := json.Unmarshal(resp.body, &result); err != nil {
+		return result, fmt.Errorf("failed to unmarshal restore result: %v", err)
+	}
+
+	return result, nil
+}
+
+// SelfTest records and replays synthetic Events through the service's internal loopback pipeline, see
+// POST /api/v3/admin/selftest. The report's Passed is false if the Events didn't make the round trip unchanged.
+func (c *Client) SelfTest(ctx context.Context, request dtos.SelfTestRequest) (dtos.SelfTestReport, error) {
+	report := dtos.SelfTestReport{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: selfTestRoute, expected: []int{http.StatusOK}},
+		request, &report)
+	return report, err
+}
+
+// PipelineStats returns the service's internal recording pipeline and replay publishing statistics, see
+// GET /api/v3/debug/pipeline
+func (c *Client) PipelineStats(ctx context.Context) (dtos.PipelineStats, error) {
+	stats := dtos.PipelineStats{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: pipelineRoute, expected: []int{http.StatusOK}},
+		nil, &stats)
+	return stats, err
+}
+
+// ClockStatus returns the virtual clock's time, see GET /api/v3/clock. The route is only available when the
+// service runs with the virtual clock.
+func (c *Client) ClockStatus(ctx context.Context) (dtos.ClockStatus, error) {
+	status := dtos.ClockStatus{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: clockRoute, expected: []int{http.StatusOK}},
+		nil, &status)
+	return status, err
+}
+
+// AdvanceClock advances the virtual clock by the duration, see POST /api/v3/clock/advance
+func (c *Client) AdvanceClock(ctx context.Context, duration time.Duration) (dtos.ClockStatus, error) {
+	status := dtos.ClockStatus{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: clockAdvance, expected: []int{http.StatusOK}},
+		dtos.ClockAdvanceRequest{Duration: duration}, &status)
+	return status, err
+}
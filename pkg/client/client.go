@@ -0,0 +1,303 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+)
+
+const (
+	recordRoute     = common.ApiBase + "/record"
+	pushRoute       = recordRoute + "/events"
+	replayRoute     = common.ApiBase + "/replay"
+	shadowRoute     = replayRoute + "/shadow"
+	latencyRoute    = replayRoute + "/latency"
+	triggerRoute    = replayRoute + "/trigger"
+	seekRoute       = replayRoute + "/seek"
+	resumeRoute     = replayRoute + "/resume"
+	stepRoute       = replayRoute + "/step"
+	playlistRoute   = replayRoute + "/playlist"
+	definitionRoute = playlistRoute + "/definition"
+	dataRoute       = common.ApiBase + "/data"
+	assertRoute     = dataRoute + "/assert"
+	validateRoute   = dataRoute + "/validate"
+	dependsRoute    = dataRoute + "/dependencies"
+	lockRoute       = dataRoute + "/lock"
+	metadataRoute   = dataRoute + "/metadata"
+	exportRoute     = dataRoute + "/export"
+	exportSizeRoute = dataRoute + "/size"
+	statsRoute      = dataRoute + "/stats"
+	sizesRoute      = dataRoute + "/sizes"
+	gapsRoute       = dataRoute + "/gaps"
+	eventsRoute     = dataRoute + "/events"
+	annotateRoute   = dataRoute + "/annotations"
+	downsampleRoute = dataRoute + "/downsample"
+	compactRoute    = dataRoute + "/compact"
+	exportLinkRoute = dataRoute + "/link"
+	compareRoute    = dataRoute + "/compare"
+	jobsRoute       = common.ApiBase + "/jobs"
+	injectRoute     = common.ApiBase + "/inject"
+	backupRoute     = common.ApiBase + "/admin/backup"
+	restoreRoute    = common.ApiBase + "/admin/restore"
+	selfTestRoute   = common.ApiBase + "/admin/selftest"
+	pipelineRoute   = common.ApiBase + "/debug/pipeline"
+	clockRoute      = common.ApiBase + "/clock"
+	clockAdvance    = clockRoute + "/advance"
+
+	defaultMaxRetries    = 3
+	defaultRetryInterval = 500 * time.Millisecond
+	// statusWaitPoll is the longest each status request of WaitForRecording and WaitForReplay waits for the sessions
+	// to end, which is within ARR's wait limit for its default Service.RequestTimeout of 5s
+	statusWaitPoll = 4 * time.Second
+)
+
+var invalidCompression = errors.New("compression must be empty, gzip or zlib")
+
+// ResponseError is the error returned when ARR responds with an unexpected HTTP status. Message is the response
+// body, which holds the reason the request failed.
+type ResponseError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, strings.TrimSpace(e.Message))
+}
+
+// Client drives App Record & Replay through its HTTP API, so other Go services and tests don't need to build the
+// requests themselves. Requests are retried when ARR is unreachable or unavailable.
+type Client struct {
+	baseURL       string
+	httpClient    *http.Client
+	maxRetries    int
+	retryInterval time.Duration
+	compression   string
+}
+
+// Option sets an optional setting of the Client
+type Option func(c *Client) error
+
+// WithHTTPClient sets the HTTP client the requests are sent with, i.e. for TLS or timeouts.
+// http.DefaultClient is used when not set.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) error {
+		c.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithRetries sets the number of times a failed request is retried and the interval before the first retry, which
+// doubles for each further retry. Set maxRetries to 0 to disable retries.
+func WithRetries(maxRetries int, interval time.Duration) Option {
+	return func(c *Client) error {
+		if maxRetries < 0 || interval < 0 {
+			return errors.New("retries and retry interval must be equal or greater than 0")
+		}
+		c.maxRetries = maxRetries
+		c.retryInterval = interval
+		return nil
+	}
+}
+
+// WithCompression sets the compression, either gzip or zlib, the recorded data is transferred with. Exports are
+// requested compressed and imports are sent compressed, which the Client handles transparently.
+func WithCompression(compression string) Option {
+	return func(c *Client) error {
+		if _, ok := contentEncodings[compression]; !ok && len(compression) > 0 {
+			return invalidCompression
+		}
+		c.compression = compression
+		return nil
+	}
+}
+
+// New returns the Client for the ARR service at the base URL, i.e. http://localhost:59712
+func New(baseURL string, options ...Option) (*Client, error) {
+	if _, err := url.Parse(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid base URL: %v", err)
+	}
+
+	c := &Client{
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		httpClient:    http.DefaultClient,
+		maxRetries:    defaultMaxRetries,
+		retryInterval: defaultRetryInterval,
+	}
+
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// apiRequest is an HTTP request to ARR and the statuses expected in response
+type apiRequest struct {
+	method   string
+	path     string
+	query    url.Values
+	header   http.Header
+	body     []byte
+	expected []int
+}
+
+// apiResponse is the response to an apiRequest, with the body already read
+type apiResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// do sends the request, retrying it when ARR is unreachable or responds that it is unavailable, and returns the
+// response if its status is one of those expected
+func (c *Client) do(ctx context.Context, r apiRequest) (*apiResponse, error) {
+	target := c.baseURL + r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	interval := c.retryInterval
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, r, target)
+
+		// Requests which may have been processed are only retried if repeating them has the same effect
+		retry := idempotent(r.method)
+		if err == nil {
+			retry = retryableStatus(r.method, resp.status)
+			if !retry {
+				for _, status := range r.expected {
+					if resp.status == status {
+						return resp, nil
+					}
+				}
+			}
+		}
+
+		if !retry || attempt >= c.maxRetries || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+			return nil, &ResponseError{StatusCode: resp.status, Message: string(resp.body)}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
+
+func (c *Client) send(ctx context.Context, r apiRequest, target string) (*apiResponse, error) {
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, r.method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	for name, values := range r.header {
+		httpRequest.Header[name] = values
+	}
+
+	httpResponse, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	return &apiResponse{status: httpResponse.StatusCode, header: httpResponse.Header, body: responseBody}, nil
+}
+
+// retryableStatus returns true when a request with the method may be resent after ARR responded with the status.
+// 429 means the request was rejected before it was handled, so any request is retried. 502, 503 and 504 are also
+// returned when the request timed out, i.e. by the SDK's timeout handler, after the handler already ran, so only
+// requests which have the same effect when repeated are retried.
+func retryableStatus(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent(method)
+	default:
+		return false
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// doJSON sends the request with the JSON of the input, if any, as the body and unmarshals the JSON response into the
+// output, if any
+func (c *Client) doJSON(ctx context.Context, r apiRequest, input any, output any) (*apiResponse, error) {
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %v", err)
+		}
+		r.body = data
+		r.header = withHeader(r.header, common.ContentType, common.ContentTypeJSON)
+	}
+
+	resp, err := c.do(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if output != nil && len(resp.body) > 0 {
+		if err := json.Unmarshal(resp.body, output); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+		}
+	}
+
+	return resp, nil
+}
+
+func withHeader(header http.Header, name string, value string) http.Header {
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(name, value)
+	return header
+}
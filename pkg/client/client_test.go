@@ -0,0 +1,275 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, options ...Option) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	options = append([]Option{WithRetries(defaultMaxRetries, time.Millisecond)}, options...)
+	target, err := New(server.URL+"/", options...)
+	require.NoError(t, err)
+	return target
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Options       []Option
+		ExpectedError bool
+	}{
+		{"Valid - defaults", nil, false},
+		{"Valid - options", []Option{WithHTTPClient(&http.Client{}), WithRetries(0, 0), WithCompression("zlib")}, false},
+		{"Invalid - retries", []Option{WithRetries(-1, time.Second)}, true},
+		{"Invalid - compression", []Option{WithCompression("bogus")}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			target, err := New("http://localhost:59712", test.Options...)
+			if test.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "http://localhost:59712", target.baseURL)
+		})
+	}
+}
+
+func TestClient_StartRecording(t *testing.T) {
+	expected := dtos.RecordRequest{Duration: time.Minute, EventLimit: 10}
+	target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, recordRoute, r.URL.Path)
+
+		actual := dtos.RecordRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&actual))
+		assert.Equal(t, expected, actual)
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	require.NoError(t, target.StartRecording(context.Background(), expected))
+}
+
+func TestClient_RecordingStatus(t *testing.T) {
+	expected := dtos.RecordStatus{InProgress: true, EventCount: 5}
+	target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, recordRoute, r.URL.Path)
+		_ = json.NewEncoder(w).Encode(expected)
+	})
+
+	actual, err := target.RecordingStatus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestClient_WaitForRecording(t *testing.T) {
+	inProgress := dtos.RecordStatus{InProgress: true, EventCount: 1}
+	expected := dtos.RecordStatus{EventCount: 5}
+	var requests atomic.Int32
+	target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, recordRoute, r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("waitForCompletion"))
+		assert.Equal(t, statusWaitPoll.String(), r.URL.Query().Get("timeout"))
+		if requests.Add(1) < 3 {
+			_ = json.NewEncoder(w).Encode(inProgress)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(expected)
+	})
+
+	actual, err := target.WaitForRecording(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestClient_WaitForReplay(t *testing.T) {
+	running := dtos.ReplayStatus{Running: true, EventCount: 5}
+	target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, replayRoute, r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("waitForCompletion"))
+		timeout, err := time.ParseDuration(r.URL.Query().Get("timeout"))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, timeout, 20*time.Millisecond)
+		_ = json.NewEncoder(w).Encode(running)
+	})
+
+	// The timeout elapses before the replay ends, so the status is still running
+	actual, err := target.WaitForReplay(context.Background(), 20*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, running, actual)
+}
+
+func TestClient_ResponseError(t *testing.T) {
+	target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, "Replay request failed validation")
+	})
+
+	err := target.StartReplay(context.Background(), dtos.ReplayRequest{})
+	require.Error(t, err)
+
+	var responseError *ResponseError
+	require.True(t, errors.As(err, &responseError))
+	assert.Equal(t, http.StatusBadRequest, responseError.StatusCode)
+	assert.Equal(t, "Replay request failed validation", responseError.Message)
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		Name             string
+		Status           int
+		UnavailableCount int32
+		ExpectedRequests int32
+		ExpectedError    bool
+	}{
+		{"Valid - recovers", http.StatusServiceUnavailable, 2, 3, false},
+		{"Invalid - retries exhausted", http.StatusBadGateway, 10, defaultMaxRetries + 1, true},
+		{"Invalid - not retried", http.StatusInternalServerError, 10, 1, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var requests atomic.Int32
+			target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) <= test.UnavailableCount {
+					w.WriteHeader(test.Status)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			err := target.CancelReplay(context.Background())
+			assert.Equal(t, test.ExpectedRequests, requests.Load())
+			if test.ExpectedError {
+				var responseError *ResponseError
+				require.True(t, errors.As(err, &responseError))
+				assert.Equal(t, test.Status, responseError.StatusCode)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestClient_Retries_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	var attempts atomic.Int32
+	httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		attempts.Add(1)
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	target, err := New(server.URL, WithHTTPClient(httpClient), WithRetries(2, time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = target.ReplayStatus(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int32(3), attempts.Load(), "GET must be retried")
+
+	attempts.Store(0)
+	err = target.StartReplay(context.Background(), dtos.ReplayRequest{})
+	require.Error(t, err)
+	assert.Equal(t, int32(1), attempts.Load(), "POST must not be retried as it may have been processed")
+}
+
+func TestClient_Retries_NotIdempotent(t *testing.T) {
+	tests := []struct {
+		Name             string
+		Status           int
+		ExpectedRequests int32
+		ExpectedError    bool
+	}{
+		{"Valid - too many requests retried", http.StatusTooManyRequests, 2, false},
+		{"Invalid - unavailable not retried", http.StatusServiceUnavailable, 1, true},
+		{"Invalid - gateway timeout not retried", http.StatusGatewayTimeout, 1, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var requests atomic.Int32
+			target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				if requests.Add(1) == 1 {
+					w.WriteHeader(test.Status)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			// The POST may already have been handled when it timed out, so repeating it could start a second session
+			err := target.StartRecording(context.Background(), dtos.RecordRequest{Duration: time.Minute})
+			assert.Equal(t, test.ExpectedRequests, requests.Load())
+			if test.ExpectedError {
+				var responseError *ResponseError
+				require.True(t, errors.As(err, &responseError))
+				assert.Equal(t, test.Status, responseError.StatusCode)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestClient_WaitForJob(t *testing.T) {
+	var polls atomic.Int32
+	target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, jobsRoute+"/1234", r.URL.Path)
+		status := dtos.JobStatus{Id: "1234", State: dtos.JobStateRunning}
+		if polls.Add(1) == 3 {
+			status.State = dtos.JobStateCompleted
+			status.StatusCode = http.StatusAccepted
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	})
+
+	status, err := target.WaitForJob(context.Background(), "1234", time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, dtos.JobStateCompleted, status.State)
+	assert.Equal(t, int32(3), polls.Load())
+}
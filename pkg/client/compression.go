@@ -0,0 +1,88 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	gzipCompression = "gzip"
+	zlibCompression = "zlib"
+)
+
+// contentEncodings is the HTTP Content-Encoding value of each compression supported by ARR
+var contentEncodings = map[string]string{
+	gzipCompression: "gzip",
+	zlibCompression: "deflate",
+}
+
+// compress returns the data compressed with the compression
+func compress(compression string, data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	var writer io.WriteCloser
+	switch compression {
+	case gzipCompression:
+		writer = gzip.NewWriter(&buffer)
+	case zlibCompression:
+		writer = zlib.NewWriter(&buffer)
+	default:
+		return nil, invalidCompression
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress data: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress data: %v", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// uncompress returns the data uncompressed according to the Content-Encoding of the response. The data is returned
+// as is without a Content-Encoding, i.e. when the HTTP transport has already uncompressed it.
+func uncompress(contentEncoding string, data []byte) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch {
+	case len(contentEncoding) == 0:
+		return data, nil
+	case strings.EqualFold(contentEncoding, contentEncodings[gzipCompression]):
+		reader, err = gzip.NewReader(bytes.NewReader(data))
+	case strings.EqualFold(contentEncoding, contentEncodings[zlibCompression]):
+		reader, err = zlib.NewReader(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("content encoding %s not supported", contentEncoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to uncompress data: %v", err)
+	}
+	defer reader.Close()
+
+	uncompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to uncompress data: %v", err)
+	}
+
+	return uncompressed, nil
+}
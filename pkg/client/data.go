@@ -0,0 +1,376 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/common"
+)
+
+// signatureHeader is the header holding the signature of signed exports and imports
+const signatureHeader = "X-Signature"
+
+// ExportOptions sets the optional query parameters of an export, see GET /api/v3/data
+type ExportOptions struct {
+	// Format is the format of the export, i.e. ekuiper, summary, features, csv or arr. The native format when empty.
+	Format string
+	// Window is the window of the summary and features formats
+	Window time.Duration
+	// Sign, if true, signs the export, with the signature returned in ExportedData.Signature
+	Sign bool
+	// Anonymize, if true, anonymizes the export using the service's anonymization profile
+	Anonymize bool
+	// Query holds any further query parameters, i.e. to select fields or sample the Events
+	Query url.Values
+}
+
+// ExportedData is the recorded data exported in the requested format, uncompressed
+type ExportedData struct {
+	Data []byte
+	// Signature is the signature of the data, if signed
+	Signature string
+}
+
+// ImportOptions sets the optional query parameters of an import, see POST /api/v3/data
+type ImportOptions struct {
+	// KeepExisting, if true, doesn't overwrite the Device Profiles and Devices already in Core Metadata
+	KeepExisting bool
+	// Rollback, if true, deletes the Device Profiles and Devices added by the import if any failed to be provisioned
+	Rollback bool
+	// Signature is the signature the data is verified against, if signed
+	Signature string
+	// Query holds any further query parameters, i.e. to transform the imported data
+	Query url.Values
+}
+
+// ExportRecordedData returns the current or last recorded data in the native format, see GET /api/v3/data
+func (c *Client) ExportRecordedData(ctx context.Context) (dtos.RecordedData, error) {
+	data := dtos.RecordedData{}
+	exported, err := c.ExportRecordedDataAs(ctx, ExportOptions{})
+	if err != nil {
+		return data, err
+	}
+
+	if err := json.Unmarshal(exported.Data, &data); err != nil {
+		return data, fmt.Errorf("failed to unmarshal recorded data: %v", err)
+	}
+
+	return data, nil
+}
+
+// ExportRecordedDataAs returns the current or last recorded data exported with the options, see GET /api/v3/data.
+// The data is transferred with the Client's compression, other than .arr archives, which are already compressed.
+func (c *Client) ExportRecordedDataAs(ctx context.Context, options ExportOptions) (ExportedData, error) {
+	query := url.Values{}
+	for name, values := range options.Query {
+		query[name] = values
+	}
+	if len(options.Format) > 0 {
+		query.Set("format", options.Format)
+	}
+	if options.Window > 0 {
+		query.Set("window", options.Window.String())
+	}
+	if options.Sign {
+		query.Set("sign", strconv.FormatBool(true))
+	}
+	if options.Anonymize {
+		query.Set("anonymize", strconv.FormatBool(true))
+	}
+	if len(c.compression) > 0 && options.Format != "arr" {
+		query.Set("compression", c.compression)
+	}
+
+	resp, err := c.do(ctx, apiRequest{method: http.MethodGet, path: dataRoute, query: query, expected: []int{http.StatusOK}})
+	if err != nil {
+		return ExportedData{}, err
+	}
+
+	data, err := uncompress(resp.header.Get("Content-Encoding"), resp.body)
+	if err != nil {
+		return ExportedData{}, err
+	}
+
+	return ExportedData{Data: data, Signature: resp.header.Get(signatureHeader)}, nil
+}
+
+// ImportRecordedData imports the recorded data, see POST /api/v3/data. The data is sent with the Client's
+// compression. The provisioning report is returned if some of the data's Device Profiles or Devices failed to be
+// provisioned, otherwise nil.
+func (c *Client) ImportRecordedData(ctx context.Context, data dtos.RecordedData, options ImportOptions) (*dtos.ProvisioningReport, error) {
+	r, err := c.importRequest(data, options, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.status != http.StatusMultiStatus {
+		return nil, nil
+	}
+
+	report := &dtos.ProvisioningReport{}
+	if err := json.Unmarshal(resp.body, report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal provisioning report: %v", err)
+	}
+
+	return report, nil
+}
+
+// StartImportJob imports the recorded data as a job, returning the job's status, see POST /api/v3/data?async=true.
+// The job's result is the provisioning report if some Device Profiles or Devices failed to be provisioned.
+func (c *Client) StartImportJob(ctx context.Context, data dtos.RecordedData, options ImportOptions) (dtos.JobStatus, error) {
+	status := dtos.JobStatus{}
+	r, err := c.importRequest(data, options, true)
+	if err != nil {
+		return status, err
+	}
+
+	_, err = c.doJSON(ctx, r, nil, &status)
+	return status, err
+}
+
+func (c *Client) importRequest(data dtos.RecordedData, options ImportOptions, async bool) (apiRequest, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return apiRequest{}, fmt.Errorf("failed to marshal recorded data: %v", err)
+	}
+
+	header := withHeader(nil, common.ContentType, common.ContentTypeJSON)
+	if len(c.compression) > 0 {
+		if body, err = compress(c.compression, body); err != nil {
+			return apiRequest{}, err
+		}
+		header.Set("Content-Encoding", contentEncodings[c.compression])
+	}
+	if len(options.Signature) > 0 {
+		header.Set(signatureHeader, options.Signature)
+	}
+
+	query := url.Values{}
+	for name, values := range options.Query {
+		query[name] = values
+	}
+	if options.KeepExisting {
+		query.Set("overwrite", strconv.FormatBool(false))
+	}
+	if options.Rollback {
+		query.Set("rollback", strconv.FormatBool(true))
+	}
+
+	r := apiRequest{method: http.MethodPost, path: dataRoute, query: query, header: header, body: body,
+		expected: []int{http.StatusAccepted, http.StatusMultiStatus}}
+	if async {
+		query.Set("async", strconv.FormatBool(true))
+		r.expected = []int{http.StatusAccepted}
+	}
+
+	return r, nil
+}
+
+// ExportRecordedDataToPath exports the recorded data to a local path on the gateway,
+// see POST /api/v3/data/export
+func (c *Client) ExportRecordedDataToPath(ctx context.Context, request dtos.LocalExportRequest) (dtos.LocalExportResponse, error) {
+	response := dtos.LocalExportResponse{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: exportRoute, expected: []int{http.StatusOK}},
+		request, &response)
+	return response, err
+}
+
+// DownsampleToPath exports the recorded data resampled to a coarser interval to a local path on the gateway,
+// see POST /api/v3/data/downsample
+func (c *Client) DownsampleToPath(ctx context.Context, request dtos.DownsampleRequest) (dtos.LocalExportResponse, error) {
+	response := dtos.LocalExportResponse{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: downsampleRoute, expected: []int{http.StatusOK}},
+		request, &response)
+	return response, err
+}
+
+// EstimateExportSize returns the estimated size of the export in each format with the Client's compression,
+// see GET /api/v3/data/size
+func (c *Client) EstimateExportSize(ctx context.Context) (dtos.ExportSizeEstimate, error) {
+	estimate := dtos.ExportSizeEstimate{}
+	query := url.Values{}
+	if len(c.compression) > 0 {
+		query.Set("compression", c.compression)
+	}
+
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: exportSizeRoute, query: query,
+		expected: []int{http.StatusOK}}, nil, &estimate)
+	return estimate, err
+}
+
+// AssertRecordedData checks the assertions against the recorded data, see POST /api/v3/data/assert
+func (c *Client) AssertRecordedData(ctx context.Context, request dtos.AssertRequest) (dtos.AssertResponse, error) {
+	response := dtos.AssertResponse{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: assertRoute, expected: []int{http.StatusOK}},
+		request, &response)
+	return response, err
+}
+
+// ValidateRecordedData checks the recorded Events against the EdgeX contract validation rules,
+// see GET /api/v3/data/validate
+func (c *Client) ValidateRecordedData(ctx context.Context) (dtos.ValidationReport, error) {
+	report := dtos.ValidationReport{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: validateRoute, expected: []int{http.StatusOK}},
+		nil, &report)
+	return report, err
+}
+
+// DependencyGraph returns the Device Profile and Device Service each recorded Device depends on,
+// see GET /api/v3/data/dependencies
+func (c *Client) DependencyGraph(ctx context.Context) (dtos.DependencyGraph, error) {
+	graph := dtos.DependencyGraph{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: dependsRoute, expected: []int{http.StatusOK}},
+		nil, &graph)
+	return graph, err
+}
+
+// LockRecordedData marks the recorded data as read-only, see POST /api/v3/data/lock
+func (c *Client) LockRecordedData(ctx context.Context) error {
+	_, err := c.do(ctx, apiRequest{method: http.MethodPost, path: lockRoute, expected: []int{http.StatusAccepted}})
+	return err
+}
+
+// UnlockRecordedData removes the read-only lock from the recorded data, see DELETE /api/v3/data/lock
+func (c *Client) UnlockRecordedData(ctx context.Context) error {
+	_, err := c.do(ctx, apiRequest{method: http.MethodDelete, path: lockRoute, expected: []int{http.StatusAccepted}})
+	return err
+}
+
+// RecordingMetadata returns the metadata of the current or last recording, see GET /api/v3/data/metadata
+func (c *Client) RecordingMetadata(ctx context.Context) (dtos.RecordingMetadata, error) {
+	metadata := dtos.RecordingMetadata{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: metadataRoute, expected: []int{http.StatusOK}},
+		nil, &metadata)
+	return metadata, err
+}
+
+// StoreStats returns the memory and storage statistics of the recording store, see GET /api/v3/data/stats
+func (c *Client) StoreStats(ctx context.Context) (dtos.StoreStats, error) {
+	stats := dtos.StoreStats{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: statsRoute, expected: []int{http.StatusOK}},
+		nil, &stats)
+	return stats, err
+}
+
+// PayloadSizeReport returns the payload size report of the recorded data, listing the top largest devices and
+// Readings, or the service's default when top is 0, see GET /api/v3/data/sizes
+func (c *Client) PayloadSizeReport(ctx context.Context, top int) (dtos.PayloadSizeReport, error) {
+	report := dtos.PayloadSizeReport{}
+	query := url.Values{}
+	if top > 0 {
+		query.Set("top", strconv.Itoa(top))
+	}
+
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: sizesRoute, query: query,
+		expected: []int{http.StatusOK}}, nil, &report)
+	return report, err
+}
+
+// RecordedDataGaps returns the periods where a device resource produced no Readings for longer than the threshold,
+// see GET /api/v3/data/gaps
+func (c *Client) RecordedDataGaps(ctx context.Context, threshold time.Duration) (dtos.GapReport, error) {
+	report := dtos.GapReport{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: gapsRoute,
+		query: url.Values{"threshold": {threshold.String()}}, expected: []int{http.StatusOK}}, nil, &report)
+	return report, err
+}
+
+// DeleteRecordedEvents deletes the recorded Events between start and end, in nanoseconds since the epoch, limited to
+// the device when not empty, see DELETE /api/v3/data/events
+func (c *Client) DeleteRecordedEvents(ctx context.Context, start int64, end int64, device string) (dtos.DeleteEventsResult, error) {
+	result := dtos.DeleteEventsResult{}
+	query := url.Values{"start": {strconv.FormatInt(start, 10)}, "end": {strconv.FormatInt(end, 10)}}
+	if len(device) > 0 {
+		query.Set("device", device)
+	}
+
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodDelete, path: eventsRoute, query: query,
+		expected: []int{http.StatusOK}}, nil, &result)
+	return result, err
+}
+
+// AddAnnotation adds the annotation to the recorded data, see POST /api/v3/data/annotations
+func (c *Client) AddAnnotation(ctx context.Context, annotation dtos.Annotation) (dtos.Annotation, error) {
+	added := dtos.Annotation{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: annotateRoute, expected: []int{http.StatusOK}},
+		annotation, &added)
+	return added, err
+}
+
+// SearchAnnotations returns the annotations whose label matches the pattern, i.e. "warmup*",
+// see GET /api/v3/data/annotations
+func (c *Client) SearchAnnotations(ctx context.Context, label string) (dtos.AnnotationSearchResult, error) {
+	result := dtos.AnnotationSearchResult{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: annotateRoute, query: url.Values{"label": {label}},
+		expected: []int{http.StatusOK}}, nil, &result)
+	return result, err
+}
+
+// CompareSeries returns the Readings of the resource in recordings a and b paired by offset, limited to the device
+// when not empty, see GET /api/v3/data/compare/{a}/{b}/series
+func (c *Client) CompareSeries(ctx context.Context, a string, b string, resource string, device string) (dtos.ComparisonSeries, error) {
+	series := dtos.ComparisonSeries{}
+	query := url.Values{"resource": {resource}}
+	if len(device) > 0 {
+		query.Set("device", device)
+	}
+
+	path := compareRoute + "/" + url.PathEscape(a) + "/" + url.PathEscape(b) + "/series"
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: path, query: query, expected: []int{http.StatusOK}},
+		nil, &series)
+	return series, err
+}
+
+// CompactStore compacts the recording store, see POST /api/v3/data/compact
+func (c *Client) CompactStore(ctx context.Context) (dtos.CompactResult, error) {
+	result := dtos.CompactResult{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: compactRoute, expected: []int{http.StatusOK}},
+		nil, &result)
+	return result, err
+}
+
+// MintExportLink mints a time-limited link for downloading the recorded data, see POST /api/v3/data/link
+func (c *Client) MintExportLink(ctx context.Context, request dtos.ExportLinkRequest) (dtos.ExportLinkResponse, error) {
+	response := dtos.ExportLinkResponse{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: exportLinkRoute, expected: []int{http.StatusOK}},
+		request, &response)
+	return response, err
+}
+
+// DownloadExportLink downloads the recorded data using the token of an export link, see GET /api/v3/data/link
+func (c *Client) DownloadExportLink(ctx context.Context, token string) ([]byte, error) {
+	resp, err := c.do(ctx, apiRequest{method: http.MethodGet, path: exportLinkRoute,
+		query: url.Values{"token": {token}}, expected: []int{http.StatusOK}})
+	if err != nil {
+		return nil, err
+	}
+
+	return uncompress(resp.header.Get("Content-Encoding"), resp.body)
+}
@@ -0,0 +1,145 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+func testRecordedData() dtos.RecordedData {
+	return dtos.RecordedData{
+		RecordedEvents: []coreDtos.Event{{Id: "1", DeviceName: "Device1", ProfileName: "Profile1", SourceName: "Source1"}},
+	}
+}
+
+func TestClient_ExportRecordedData(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Compression string
+	}{
+		{"Valid - no compression", ""},
+		{"Valid - gzip", gzipCompression},
+		{"Valid - zlib", zlibCompression},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			expected := testRecordedData()
+			target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, dataRoute, r.URL.Path)
+				assert.Equal(t, test.Compression, r.URL.Query().Get("compression"))
+
+				body, err := json.Marshal(expected)
+				require.NoError(t, err)
+				if len(test.Compression) > 0 {
+					body, err = compress(test.Compression, body)
+					require.NoError(t, err)
+					w.Header().Set("Content-Encoding", contentEncodings[test.Compression])
+				}
+				_, _ = w.Write(body)
+			}, WithCompression(test.Compression))
+
+			actual, err := target.ExportRecordedData(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestClient_ExportRecordedDataAs(t *testing.T) {
+	target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "csv", query.Get("format"))
+		assert.Equal(t, "true", query.Get("sign"))
+		assert.Equal(t, "0.5", query.Get("sample"))
+		w.Header().Set(signatureHeader, "signature")
+		_, _ = w.Write([]byte("csv data"))
+	})
+
+	actual, err := target.ExportRecordedDataAs(context.Background(),
+		ExportOptions{Format: "csv", Sign: true, Query: map[string][]string{"sample": {"0.5"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "csv data", string(actual.Data))
+	assert.Equal(t, "signature", actual.Signature)
+}
+
+func TestClient_ImportRecordedData(t *testing.T) {
+	expectedReport := dtos.ProvisioningReport{Failures: []dtos.ProvisioningFailure{{Name: "Device1"}}}
+
+	tests := []struct {
+		Name           string
+		Compression    string
+		Status         int
+		ExpectedReport *dtos.ProvisioningReport
+	}{
+		{"Valid - no compression", "", http.StatusAccepted, nil},
+		{"Valid - gzip", gzipCompression, http.StatusAccepted, nil},
+		{"Valid - provisioning failures", zlibCompression, http.StatusMultiStatus, &expectedReport},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			expected := testRecordedData()
+			target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "false", r.URL.Query().Get("overwrite"))
+				assert.Equal(t, contentEncodings[test.Compression], r.Header.Get("Content-Encoding"))
+
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				body, err = uncompress(r.Header.Get("Content-Encoding"), body)
+				require.NoError(t, err)
+
+				actual := dtos.RecordedData{}
+				require.NoError(t, json.Unmarshal(body, &actual))
+				assert.Equal(t, expected, actual)
+
+				w.WriteHeader(test.Status)
+				if test.ExpectedReport != nil {
+					_ = json.NewEncoder(w).Encode(test.ExpectedReport)
+				}
+			}, WithCompression(test.Compression))
+
+			report, err := target.ImportRecordedData(context.Background(), expected, ImportOptions{KeepExisting: true})
+			require.NoError(t, err)
+			assert.Equal(t, test.ExpectedReport, report)
+		})
+	}
+}
+
+func TestClient_StartImportJob(t *testing.T) {
+	expected := dtos.JobStatus{Id: "1234", Kind: dtos.JobKindImport, State: dtos.JobStateRunning}
+	target := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("async"))
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(expected)
+	})
+
+	actual, err := target.StartImportJob(context.Background(), testRecordedData(), ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,63 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+// StartRecording starts a recording session, see POST /api/v3/record
+func (c *Client) StartRecording(ctx context.Context, request dtos.RecordRequest) error {
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: recordRoute, expected: []int{http.StatusAccepted}},
+		request, nil)
+	return err
+}
+
+// RecordingStatus returns the status of the current or last recording session, see GET /api/v3/record
+func (c *Client) RecordingStatus(ctx context.Context) (dtos.RecordStatus, error) {
+	status := dtos.RecordStatus{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: recordRoute, expected: []int{http.StatusOK}},
+		nil, &status)
+	return status, err
+}
+
+// WaitForRecording returns the status once the recording session and any queued recordings have ended, or once
+// the timeout elapses, see GET /api/v3/record?waitForCompletion=true. The status is still in progress if the timeout
+// elapsed first. The status is requested again each time ARR's bounded wait elapses, and until the context is done
+// when the timeout is 0.
+func (c *Client) WaitForRecording(ctx context.Context, timeout time.Duration) (dtos.RecordStatus, error) {
+	return waitForStatus(ctx, c, recordRoute, timeout, func(status dtos.RecordStatus) bool {
+		return !status.InProgress && len(status.Queue) == 0
+	})
+}
+
+// CancelRecording cancels the current recording session, see DELETE /api/v3/record
+func (c *Client) CancelRecording(ctx context.Context) error {
+	_, err := c.do(ctx, apiRequest{method: http.MethodDelete, path: recordRoute, expected: []int{http.StatusAccepted}})
+	return err
+}
+
+// PushRecordEvents includes the Events in the current recording session, see POST /api/v3/record/events
+func (c *Client) PushRecordEvents(ctx context.Context, request dtos.RecordPushRequest) error {
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: pushRoute, expected: []int{http.StatusAccepted}},
+		request, nil)
+	return err
+}
@@ -0,0 +1,162 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/edgexfoundry/app-record-replay/pkg/dtos"
+)
+
+// StartReplay starts a replay session, see POST /api/v3/replay
+func (c *Client) StartReplay(ctx context.Context, request dtos.ReplayRequest) error {
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: replayRoute, expected: []int{http.StatusAccepted}},
+		request, nil)
+	return err
+}
+
+// ReplayStatus returns the status of the current or last replay session, see GET /api/v3/replay
+func (c *Client) ReplayStatus(ctx context.Context) (dtos.ReplayStatus, error) {
+	status := dtos.ReplayStatus{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: replayRoute, expected: []int{http.StatusOK}},
+		nil, &status)
+	return status, err
+}
+
+// WaitForReplay returns the status once the replay session and any queued replays have ended, or once the timeout
+// elapses, see GET /api/v3/replay?waitForCompletion=true. The status is still running if the timeout elapsed first.
+// The status is requested again each time ARR's bounded wait elapses, and until the context is done when the timeout
+// is 0.
+func (c *Client) WaitForReplay(ctx context.Context, timeout time.Duration) (dtos.ReplayStatus, error) {
+	return waitForStatus(ctx, c, replayRoute, timeout, func(status dtos.ReplayStatus) bool {
+		return !status.Running && !status.Standby && len(status.Queue) == 0
+	})
+}
+
+// CancelReplay cancels the current replay session, see DELETE /api/v3/replay
+func (c *Client) CancelReplay(ctx context.Context) error {
+	_, err := c.do(ctx, apiRequest{method: http.MethodDelete, path: replayRoute, expected: []int{http.StatusAccepted}})
+	return err
+}
+
+// TriggerReplay starts publishing the replay session in standby, see POST /api/v3/replay/trigger
+func (c *Client) TriggerReplay(ctx context.Context) error {
+	_, err := c.do(ctx, apiRequest{method: http.MethodPost, path: triggerRoute, expected: []int{http.StatusAccepted}})
+	return err
+}
+
+// SeekReplay jumps the current replay session to the offset or annotation, see PATCH /api/v3/replay/seek
+func (c *Client) SeekReplay(ctx context.Context, request dtos.ReplaySeekRequest) (dtos.ReplaySeekResponse, error) {
+	response := dtos.ReplaySeekResponse{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPatch, path: seekRoute, expected: []int{http.StatusOK}},
+		request, &response)
+	return response, err
+}
+
+// ResumeReplay continues the paused replay session, see POST /api/v3/replay/resume
+func (c *Client) ResumeReplay(ctx context.Context) error {
+	_, err := c.do(ctx, apiRequest{method: http.MethodPost, path: resumeRoute, expected: []int{http.StatusAccepted}})
+	return err
+}
+
+// StepReplay publishes the next Event of the paused replay session, see POST /api/v3/replay/step
+func (c *Client) StepReplay(ctx context.Context) error {
+	_, err := c.do(ctx, apiRequest{method: http.MethodPost, path: stepRoute, expected: []int{http.StatusAccepted}})
+	return err
+}
+
+// ShadowReport returns the report of the current or last shadow mode replay session, see GET /api/v3/replay/shadow
+func (c *Client) ShadowReport(ctx context.Context) (dtos.ShadowReport, error) {
+	report := dtos.ShadowReport{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: shadowRoute, expected: []int{http.StatusOK}},
+		nil, &report)
+	return report, err
+}
+
+// LatencyReport returns the report of the current or last latency measurement replay session,
+// see GET /api/v3/replay/latency
+func (c *Client) LatencyReport(ctx context.Context) (dtos.LatencyReport, error) {
+	report := dtos.LatencyReport{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: latencyRoute, expected: []int{http.StatusOK}},
+		nil, &report)
+	return report, err
+}
+
+// StartPlaylist starts replaying the playlist, see POST /api/v3/replay/playlist
+func (c *Client) StartPlaylist(ctx context.Context, playlist dtos.Playlist) (dtos.PlaylistStatus, error) {
+	status := dtos.PlaylistStatus{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodPost, path: playlistRoute, expected: []int{http.StatusAccepted}},
+		playlist, &status)
+	return status, err
+}
+
+// PlaylistStatus returns the status of the running or last playlist, see GET /api/v3/replay/playlist
+func (c *Client) PlaylistStatus(ctx context.Context) (dtos.PlaylistStatus, error) {
+	status := dtos.PlaylistStatus{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: playlistRoute, expected: []int{http.StatusOK}},
+		nil, &status)
+	return status, err
+}
+
+// CancelPlaylist cancels the running playlist, see DELETE /api/v3/replay/playlist
+func (c *Client) CancelPlaylist(ctx context.Context) (dtos.PlaylistStatus, error) {
+	status := dtos.PlaylistStatus{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodDelete, path: playlistRoute, expected: []int{http.StatusAccepted}},
+		nil, &status)
+	return status, err
+}
+
+// ExportPlaylist returns the definition of the running or last playlist, with its recordings embedded when embed is
+// true, see GET /api/v3/replay/playlist/definition
+func (c *Client) ExportPlaylist(ctx context.Context, embed bool) (dtos.Playlist, error) {
+	playlist := dtos.Playlist{}
+	_, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: definitionRoute,
+		query: url.Values{"embed": {strconv.FormatBool(embed)}}, expected: []int{http.StatusOK}}, nil, &playlist)
+	return playlist, err
+}
+
+// waitForStatus requests the status at the route with waitForCompletion until ended returns true for it or the
+// timeout, if set, elapses. Each request waits at most statusWaitPoll, so it is answered before ARR's RequestTimeout.
+func waitForStatus[T any](ctx context.Context, c *Client, route string, timeout time.Duration,
+	ended func(status T) bool) (T, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		wait := statusWaitPoll
+		if !deadline.IsZero() {
+			wait = max(min(wait, time.Until(deadline)), time.Millisecond)
+		}
+
+		var status T
+		query := url.Values{"waitForCompletion": {"true"}, "timeout": {wait.String()}}
+		if _, err := c.doJSON(ctx, apiRequest{method: http.MethodGet, path: route, query: query,
+			expected: []int{http.StatusOK}}, nil, &status); err != nil {
+			return status, err
+		}
+
+		if ended(status) || (!deadline.IsZero() && !time.Now().Before(deadline)) {
+			return status, nil
+		}
+	}
+}
@@ -0,0 +1,52 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// Annotation DTO marks a point in a recording with a label, such as a bookmark for when a valve opened, so the
+// recording can be searched by label and replayed starting from the point. With an End it labels the time range
+// instead, such as when a fault was present, which is included as the label column of CSV exports.
+type Annotation struct {
+	// Label is the label of the annotation, which need not be unique
+	Label string `json:"label"`
+	// Timestamp is the point in the recording the annotation marks in nanoseconds since the epoch, on the same clock
+	// as the recorded Event Origins
+	Timestamp int64 `json:"timestamp"`
+	// End is the end of the time range the annotation labels, from the Timestamp, in nanoseconds since the epoch on
+	// the same clock. Zero for annotations marking a point.
+	End int64 `json:"end,omitempty"`
+	// Note is an optional free-form description of the annotation
+	Note string `json:"note,omitempty"`
+}
+
+// AnnotationSearchResult DTO contains the annotations matching an annotation search
+type AnnotationSearchResult struct {
+	// Matches is the list of matching annotations, with those of the recorded data held in memory first followed by
+	// those of the segment store in path order
+	Matches []AnnotationMatch `json:"matches"`
+}
+
+// AnnotationMatch DTO describes an annotation matching an annotation search and the recording it is in
+type AnnotationMatch struct {
+	Annotation
+	// RecordingName is the name of the recording the annotation is in, if named
+	RecordingName string `json:"recordingName,omitempty"`
+	// InProgress indicates the annotation is in the recording in progress
+	InProgress bool `json:"inProgress,omitempty"`
+	// SegmentPath is the path of the segment store segment the annotation is in. Empty for the recorded data held in
+	// memory, which can be replayed from the annotation using the replay request's StartAnnotation.
+	SegmentPath string `json:"segmentPath,omitempty"`
+}
@@ -0,0 +1,44 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// ArchiveManifest DTO is the manifest embedded in an .arr archive, describing the recording in the archive so the
+// import can check it is compatible and its dependencies are met before Core Metadata is changed
+type ArchiveManifest struct {
+	// FormatVersion is the version of the .arr archive format
+	FormatVersion int `json:"formatVersion"`
+	// EdgeXVersion is the version of the EdgeX contracts the recording conforms to, which the importing service must
+	// share the major version of. Unknown when empty.
+	EdgeXVersion string `json:"edgexVersion,omitempty"`
+	// Name is the name of the recording, if named
+	Name string `json:"name,omitempty"`
+	// Profiles is the sorted list of the names of the Device Profiles referenced by the recorded Events and Devices
+	Profiles []string `json:"profiles"`
+	// DeviceServices is the sorted list of the names of the Device Services referenced by the recorded Devices
+	DeviceServices []string `json:"deviceServices"`
+	// Checksums is the hex encoded SHA-256 checksum of each of the other entries in the archive, keyed by entry name
+	Checksums map[string]string `json:"checksums"`
+}
+
+// MissingDependencies DTO lists the dependencies of an imported archive which are neither in the archive nor in
+// Core Metadata
+type MissingDependencies struct {
+	// Profiles is the list of the names of the missing Device Profiles
+	Profiles []string `json:"profiles,omitempty"`
+	// DeviceServices is the list of the names of the missing Device Services
+	DeviceServices []string `json:"deviceServices,omitempty"`
+}
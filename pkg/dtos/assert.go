@@ -0,0 +1,76 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import "time"
+
+const (
+	// AssertMinValue asserts all numeric Readings matching the filters are greater than or equal to Value
+	AssertMinValue = "minValue"
+	// AssertMaxValue asserts all numeric Readings matching the filters are less than or equal to Value
+	AssertMaxValue = "maxValue"
+	// AssertExpectedDevices asserts all the Devices have at least one recorded Event
+	AssertExpectedDevices = "expectedDevices"
+	// AssertEventCount asserts the number of Events matching the DeviceName filter is between MinCount and MaxCount
+	AssertEventCount = "eventCount"
+	// AssertMaxGap asserts the time between consecutive Events matching the DeviceName filter doesn't exceed MaxGap
+	AssertMaxGap = "maxGap"
+)
+
+// AssertRequest DTO specifies the assertions to check against the recorded data
+type AssertRequest struct {
+	// Data is the optional recorded data to check. The last recorded or imported data is checked if not set.
+	Data *RecordedData `json:"data,omitempty"`
+	// Assertions is the list of assertions to check
+	Assertions []Assertion `json:"assertions"`
+}
+
+// Assertion DTO specifies a single declarative assertion. Which fields are used depends on the Type.
+type Assertion struct {
+	// Type is the type of assertion, i.e. minValue, maxValue, expectedDevices, eventCount or maxGap
+	Type string `json:"type"`
+	// DeviceName optionally limits the assertion to the Events/Readings for this Device
+	DeviceName string `json:"deviceName,omitempty"`
+	// ResourceName optionally limits the minValue and maxValue assertions to the Readings for this resource
+	ResourceName string `json:"resourceName,omitempty"`
+	// Value is the bound for the minValue and maxValue assertions
+	Value float64 `json:"value,omitempty"`
+	// Devices is the list of Device names for the expectedDevices assertion
+	Devices []string `json:"devices,omitempty"`
+	// MinCount is the minimum number of Events for the eventCount assertion
+	MinCount int `json:"minCount,omitempty"`
+	// MaxCount is the maximum number of Events for the eventCount assertion. Zero means no maximum.
+	MaxCount int `json:"maxCount,omitempty"`
+	// MaxGap is the maximum time between consecutive Events for the maxGap assertion
+	MaxGap time.Duration `json:"maxGap,omitempty"`
+}
+
+// AssertResponse DTO contains the results of checking the assertions against the recorded data
+type AssertResponse struct {
+	// Passed indicates if all the assertions passed
+	Passed bool `json:"passed"`
+	// Results is the result for each assertion in the same order as the request
+	Results []AssertionResult `json:"results"`
+}
+
+// AssertionResult DTO contains the result of checking a single assertion
+type AssertionResult struct {
+	Assertion Assertion `json:"assertion"`
+	Passed    bool      `json:"passed"`
+	// Message describes why the assertion failed
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,80 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// BackupManifest DTO is the manifest of a recording store backup archive, listing the recordings it holds so they
+// can be restored on another instance
+type BackupManifest struct {
+	// FormatVersion is the version of the backup archive format
+	FormatVersion int `json:"formatVersion"`
+	// CreatedAt is the time the backup was taken in nanoseconds since the epoch
+	CreatedAt int64 `json:"createdAt"`
+	// Current is true if the backup holds the recorded data held in memory
+	Current bool `json:"current"`
+	// Recordings is the list of the named recordings in the segment store, sorted by name
+	Recordings []BackupRecording `json:"recordings"`
+}
+
+// BackupRecording DTO describes a named recording in the segment store
+type BackupRecording struct {
+	// Name is the name of the recording, which is the name of its directory in the segment store
+	Name string `json:"name"`
+	// Segments is the sorted list of the file names of the recording's segments
+	Segments []string `json:"segments"`
+}
+
+// StoreBackup holds the content of the recording store taken by a backup, or read from a backup to restore
+type StoreBackup struct {
+	// CreatedAt is the time the backup was taken in nanoseconds since the epoch
+	CreatedAt int64
+	// Current is the recorded data held in memory, in the export format, or nil if there is none
+	Current *RecordedData
+	// Recordings is the list of the named recordings in the segment store
+	Recordings []RecordingBackup
+}
+
+// RecordingBackup holds the segments of a named recording in the segment store
+type RecordingBackup struct {
+	// Name is the name of the recording
+	Name string
+	// Segments is the list of the recording's segments
+	Segments []SegmentBackup
+}
+
+// SegmentBackup holds a segment of a named recording
+type SegmentBackup struct {
+	// FileName is the name of the segment's file within the recording's directory
+	FileName string
+	// Path is the path of the segment's file in the segment store when backed up, so it can be copied into the
+	// backup without being read into memory
+	Path string
+	// Data is the content of the segment's file, in the exported recorded data format, when read from a backup
+	Data []byte
+}
+
+// RestoreResult DTO is the response to restoring a recording store backup
+type RestoreResult struct {
+	// CurrentRestored is true if the backup held recorded data, which was imported in place of the recorded data
+	CurrentRestored bool `json:"currentRestored"`
+	// RecordingCount is the number of named recordings restored into the segment store
+	RecordingCount int `json:"recordingCount"`
+	// SegmentCount is the number of segments restored into the segment store
+	SegmentCount int `json:"segmentCount"`
+	// ProvisioningFailures lists the Device Profiles and Devices of the restored recorded data which failed to be
+	// provisioned
+	ProvisioningFailures []ProvisioningFailure `json:"provisioningFailures,omitempty"`
+}
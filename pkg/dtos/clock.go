@@ -0,0 +1,31 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import "time"
+
+// ClockAdvanceRequest DTO specifies how far to advance the virtual clock
+type ClockAdvanceRequest struct {
+	// Duration is the amount of virtual time to advance the clock by. Must be greater than 0.
+	Duration time.Duration `json:"duration"`
+}
+
+// ClockStatus DTO contains the current time of the virtual clock
+type ClockStatus struct {
+	// Now is the current virtual time in nanoseconds since the epoch
+	Now int64 `json:"now"`
+}
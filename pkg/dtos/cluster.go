@@ -0,0 +1,31 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import "encoding/json"
+
+// PeerResponse DTO contains the response from a peer ARR instance to a command fanned out by the coordinator
+type PeerResponse struct {
+	// Peer is the base URL of the peer instance
+	Peer string `json:"peer"`
+	// StatusCode is the HTTP status code returned by the peer. Zero if the peer couldn't be reached.
+	StatusCode int `json:"statusCode"`
+	// Error describes why the command failed on the peer
+	Error string `json:"error,omitempty"`
+	// Body is the JSON response returned by the peer, i.e. status or exported data
+	Body json.RawMessage `json:"body,omitempty"`
+}
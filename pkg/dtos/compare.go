@@ -0,0 +1,64 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import "time"
+
+// ComparisonSeries DTO contains the numeric Readings of a resource from two recordings as paired time series, for
+// plotting overlays of the recordings in external dashboards. The points are timed by their offsets from the start
+// of their recording, so recordings captured at different times line up.
+type ComparisonSeries struct {
+	// ResourceName is the name of the resource compared
+	ResourceName string `json:"resourceName"`
+	// A is the first recording compared
+	A ComparedRecording `json:"a"`
+	// B is the second recording compared
+	B ComparedRecording `json:"b"`
+	// Series is the pair of time series of each device with Readings of the resource in either recording, in device
+	// name order
+	Series []PairedSeries `json:"series"`
+}
+
+// ComparedRecording DTO describes a recording compared
+type ComparedRecording struct {
+	// Name is the name the recording was compared by
+	Name string `json:"name"`
+	// Start is the Origin of the recording's first Event in nanoseconds since the epoch, which the offsets of its
+	// points are from
+	Start int64 `json:"start"`
+	// PointCount is the number of points from the recording
+	PointCount int `json:"pointCount"`
+}
+
+// PairedSeries DTO contains the time series of a device's Readings of the resource from each recording
+type PairedSeries struct {
+	DeviceName string `json:"deviceName"`
+	// A is the time series from the first recording, in Origin order
+	A []SeriesPoint `json:"a"`
+	// B is the time series from the second recording, in Origin order
+	B []SeriesPoint `json:"b"`
+}
+
+// SeriesPoint DTO is a numeric Reading value in a time series
+type SeriesPoint struct {
+	// Offset is the duration from the start of the recording to the Reading's Origin
+	Offset time.Duration `json:"offset"`
+	// Origin is the Reading's Origin in nanoseconds since the epoch
+	Origin int64 `json:"origin"`
+	// Value is the Reading's value
+	Value float64 `json:"value"`
+}
@@ -0,0 +1,86 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+
+// RecordedDataDelta DTO holds recorded data stored as its differences against a baseline recording, so similar
+// captures of the same devices take a fraction of the space. The Events, Devices and Profiles of the recorded data
+// are reconstructed from the baseline when the data is imported.
+type RecordedDataDelta struct {
+	// Baseline is the path of the baseline recording file, which must be within one of the directories allow-listed
+	// by the ImportPaths App Setting when the data is imported
+	Baseline string `json:"baseline"`
+	// BaselineDigest is the hex encoded SHA-256 digest of the baseline's uncompressed data, so the data is never
+	// reconstructed from a baseline which has changed since the delta was stored
+	BaselineDigest string `json:"baselineDigest"`
+	// Events holds each recorded Event, in order, either against a similar baseline Event or in full
+	Events []EventDelta `json:"events"`
+	// Devices holds each recorded Device, in order, either by name when unchanged from the baseline or in full
+	Devices []DeviceDelta `json:"devices"`
+	// Profiles holds each recorded Device Profile, in order, either by name when unchanged from the baseline or in
+	// full
+	Profiles []ProfileDelta `json:"profiles"`
+}
+
+// EventDelta DTO holds a recorded Event as its differences against the baseline Event from the same device and
+// source, or in full when there isn't a similar one
+type EventDelta struct {
+	// Base is the position of the baseline Event the Event is stored against. Not set when stored in full.
+	Base *int `json:"base,omitempty"`
+	// Event is the Event in full, when not stored against a baseline Event
+	Event *coreDtos.Event `json:"event,omitempty"`
+	// Id is the Id of the Event
+	Id string `json:"id,omitempty"`
+	// Origin is the origin of the Event
+	Origin int64 `json:"origin,omitempty"`
+	// Tags are the Event's tags, when they differ from the baseline Event's
+	Tags coreDtos.Tags `json:"tags,omitempty"`
+	// Readings holds the differences of each of the Event's Readings against the baseline Event's, in order
+	Readings []ReadingDelta `json:"readings,omitempty"`
+}
+
+// ReadingDelta DTO holds the differences of a recorded Reading against the baseline Reading in the same position
+type ReadingDelta struct {
+	// Id is the Id of the Reading
+	Id string `json:"id,omitempty"`
+	// OriginOffset is the Reading's origin relative to its Event's origin, which is usually 0
+	OriginOffset int64 `json:"originOffset,omitempty"`
+	// Value is the Reading's value, when it differs from the baseline Reading's
+	Value *string `json:"value,omitempty"`
+	// BinaryValue is the Reading's binary value, when it differs from the baseline Reading's
+	BinaryValue []byte `json:"binaryValue,omitempty"`
+	// ObjectValue is the Reading's object value, when it differs from the baseline Reading's
+	ObjectValue any `json:"objectValue,omitempty"`
+	// Tags are the Reading's tags, when they differ from the baseline Reading's
+	Tags coreDtos.Tags `json:"tags,omitempty"`
+}
+
+// DeviceDelta DTO holds a recorded Device by name when unchanged from the baseline's Device, otherwise in full
+type DeviceDelta struct {
+	Name string `json:"name"`
+	// Device is the Device in full, when it isn't in the baseline or has changed
+	Device *coreDtos.Device `json:"device,omitempty"`
+}
+
+// ProfileDelta DTO holds a recorded Device Profile by name when unchanged from the baseline's Device Profile,
+// otherwise in full
+type ProfileDelta struct {
+	Name string `json:"name"`
+	// Profile is the Device Profile in full, when it isn't in the baseline or has changed
+	Profile *coreDtos.DeviceProfile `json:"profile,omitempty"`
+}
@@ -0,0 +1,65 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// DependencyGraph DTO describes the Device Profile and Device Service each recorded Device depends on, and which of
+// them are missing from Core Metadata, so the gaps can be resolved before the recording is replayed or imported
+type DependencyGraph struct {
+	// Devices is the list of the recorded Devices, and those only referenced by the recorded Events, sorted by name
+	Devices []DeviceDependencies `json:"devices"`
+	// Profiles is the list of the Device Profiles the Devices use, sorted by name
+	Profiles []ProfileDependency `json:"profiles"`
+	// DeviceServices is the list of the Device Services the Devices belong to, sorted by name
+	DeviceServices []DeviceServiceDependency `json:"deviceServices"`
+	// Missing lists the Device Profiles and Device Services which don't exist in Core Metadata
+	Missing MissingDependencies `json:"missing"`
+}
+
+// DeviceDependencies DTO describes the dependencies of a recorded Device
+type DeviceDependencies struct {
+	// DeviceName is the name of the Device
+	DeviceName string `json:"deviceName"`
+	// ProfileName is the name of the Device Profile the Device uses
+	ProfileName string `json:"profileName"`
+	// ServiceName is the name of the Device Service the Device belongs to. Empty if the Device wasn't recorded.
+	ServiceName string `json:"serviceName,omitempty"`
+	// Recorded is true if the Device is in the recorded data, so is provisioned when imported
+	Recorded bool `json:"recorded"`
+}
+
+// ProfileDependency DTO describes a Device Profile the recorded Devices use
+type ProfileDependency struct {
+	// Name is the name of the Device Profile
+	Name string `json:"name"`
+	// DeviceNames is the sorted list of the names of the Devices using the Device Profile
+	DeviceNames []string `json:"deviceNames"`
+	// Recorded is true if the Device Profile is in the recorded data, so is provisioned when imported
+	Recorded bool `json:"recorded"`
+	// Missing is true if the Device Profile doesn't exist in Core Metadata
+	Missing bool `json:"missing"`
+}
+
+// DeviceServiceDependency DTO describes a Device Service the recorded Devices belong to
+type DeviceServiceDependency struct {
+	// Name is the name of the Device Service
+	Name string `json:"name"`
+	// DeviceNames is the sorted list of the names of the Devices belonging to the Device Service
+	DeviceNames []string `json:"deviceNames"`
+	// Missing is true if the Device Service doesn't exist in Core Metadata. Device Services aren't recorded, so
+	// missing ones must be added before the recorded Devices can be provisioned.
+	Missing bool `json:"missing"`
+}
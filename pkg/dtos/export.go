@@ -0,0 +1,115 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import "time"
+
+// LocalExportRequest DTO specifies the local filesystem destination the recorded data is exported to, i.e. a USB
+// stick attached to the gateway, so it can be collected without a network transfer
+type LocalExportRequest struct {
+	// Path is the absolute path of the file the recorded data is written to, or of an existing directory, in which
+	// case the file is named after the recording. It must be within one of the directories allow-listed by the
+	// ExportPaths App Setting.
+	Path string `json:"path"`
+	// Compression is the optional compression applied to the exported data, either gzip or zlib
+	Compression string `json:"compression,omitempty"`
+	// Sign, if true, signs the exported data, writing the signature alongside it in a file with a .sig extension
+	Sign bool `json:"sign,omitempty"`
+	// Overwrite, if true, replaces the file if it already exists
+	Overwrite bool `json:"overwrite,omitempty"`
+	// Baseline is the optional path of a recording file the recorded data is stored against as a delta, storing only
+	// its differences. The baseline must be within one of the directories allow-listed by the ImportPaths App
+	// Setting, and must be unchanged and available there when the delta is imported.
+	Baseline string `json:"baseline,omitempty"`
+}
+
+// DownsampleRequest DTO specifies how the recorded data is resampled into a derived recording, which is written to
+// the local filesystem destination so long captures can be replayed quickly while the original stays intact
+type DownsampleRequest struct {
+	LocalExportRequest
+	// Interval is the length of the time buckets the Events of each device and source are resampled to, aligned to
+	// multiples of the interval. Must be greater than 0.
+	Interval time.Duration `json:"interval"`
+	// Aggregation is how the Events within a bucket are reduced to one, either first, last or avg. Defaults to first
+	// when empty. With avg the first Event is kept with its numeric Readings set to the average of the bucket's.
+	Aggregation string `json:"aggregation,omitempty"`
+	// Name is the name of the derived recording. Defaults to the recording's name with a -downsampled suffix.
+	Name string `json:"name,omitempty"`
+}
+
+// LocalExportResponse DTO describes the file the recorded data was exported to
+type LocalExportResponse struct {
+	// Path is the path of the file the recorded data was written to
+	Path string `json:"path"`
+	// Size is the size of the file in bytes
+	Size int `json:"size"`
+	// SignaturePath is the path of the file the signature was written to, if signed
+	SignaturePath string `json:"signaturePath,omitempty"`
+}
+
+// ExportLinkRequest DTO specifies the time-limited link to mint for downloading the recorded data, so the export
+// can be shared without sharing the API credentials
+type ExportLinkRequest struct {
+	// ExpiresIn is how long the link is valid for. Defaults to one hour when 0, up to a maximum of seven days.
+	ExpiresIn time.Duration `json:"expiresIn,omitempty"`
+	// MaxDownloads is the number of times the link can be used. Defaults to 1 when 0, making it a one-time link.
+	MaxDownloads int `json:"maxDownloads,omitempty"`
+	// Compression is the optional compression applied to the downloaded data, either gzip or zlib
+	Compression string `json:"compression,omitempty"`
+	// Format is the optional format of the downloaded data, either ekuiper or summary. The native format when empty.
+	Format string `json:"format,omitempty"`
+	// Sign, if true, signs the downloaded data, the same as the sign export query parameter
+	Sign bool `json:"sign,omitempty"`
+}
+
+// ExportLinkResponse DTO contains the minted export link
+type ExportLinkResponse struct {
+	// URL is the path and query of the link, relative to the service's base URL
+	URL string `json:"url"`
+	// Token is the signed token of the link, which is included in the URL
+	Token string `json:"token"`
+	// ExpiresAt is the time the link expires in nanoseconds since the epoch
+	ExpiresAt int64 `json:"expiresAt"`
+	// MaxDownloads is the number of times the link can be used
+	MaxDownloads int `json:"maxDownloads"`
+}
+
+// ExportSizeEstimate DTO contains the estimated size of the export of the recorded data in each format, so clients
+// can choose the format and plan the transfer without downloading it
+type ExportSizeEstimate struct {
+	// EventCount is the number of recorded Events
+	EventCount int `json:"eventCount"`
+	// SampledEventCount is the number of Events encoded for the estimate. The sizes are exact when it equals the
+	// EventCount.
+	SampledEventCount int `json:"sampledEventCount"`
+	// Compression is the compression the sizes are estimated with, if any
+	Compression string `json:"compression,omitempty"`
+	// Formats is the estimate for each format
+	Formats []ExportFormatEstimate `json:"formats"`
+}
+
+// ExportFormatEstimate DTO contains the estimated size of the export in a format
+type ExportFormatEstimate struct {
+	// Format is the export format, where json is the native format
+	Format string `json:"format"`
+	// Compressed indicates if the Size is of the compressed export. The arr format is never compressed.
+	Compressed bool `json:"compressed"`
+	// Size is the estimated size of the export in bytes
+	Size int64 `json:"size"`
+	// EncodeTime is the estimated time to encode, and compress, the export
+	EncodeTime time.Duration `json:"encodeTime"`
+}
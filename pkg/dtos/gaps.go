@@ -0,0 +1,53 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import "time"
+
+// GapReport DTO lists the periods of the recorded data where a device resource produced no Readings for longer than
+// the threshold, to help find connectivity dropouts captured in the data
+type GapReport struct {
+	// Threshold is the longest interval between Readings that isn't reported as a gap
+	Threshold time.Duration `json:"threshold"`
+	// ResourceCount is the number of device resources checked
+	ResourceCount int `json:"resourceCount"`
+	// GapCount is the number of gaps found, including any not listed
+	GapCount int `json:"gapCount"`
+	// Truncated is true when more gaps were found than are listed
+	Truncated bool `json:"truncated,omitempty"`
+	// Gaps lists the gaps found, in order of their start
+	Gaps []DataGap `json:"gaps"`
+}
+
+// DataGap DTO describes a period where a device resource produced no Readings
+type DataGap struct {
+	DeviceName   string `json:"deviceName"`
+	ResourceName string `json:"resourceName"`
+	// Start is the origin of the last Reading before the gap, or of the first Reading in the recording if Leading
+	Start int64 `json:"start"`
+	// End is the origin of the first Reading after the gap, or of the last Reading in the recording if Trailing
+	End int64 `json:"end"`
+	// Duration is the length of the gap
+	Duration time.Duration `json:"duration"`
+	// Leading is true when the gap is from the start of the recording to the resource's first Reading
+	Leading bool `json:"leading,omitempty"`
+	// Trailing is true when the gap is from the resource's last Reading to the end of the recording
+	Trailing bool `json:"trailing,omitempty"`
+	// Disconnected is true when the gap overlaps a period the MessageBus was disconnected during the recording, so
+	// the missing Readings may not have been lost by the device
+	Disconnected bool `json:"disconnected,omitempty"`
+}
@@ -0,0 +1,52 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// ImportTransform DTO specifies the transformations applied to the imported data while it is decoded, so recordings
+// from other sites fit the local naming conventions without being edited by hand
+type ImportTransform struct {
+	// DeviceNames maps the names of the recorded Devices to the local names they are renamed to
+	DeviceNames map[string]string `json:"deviceNames,omitempty"`
+	// ProfileNames maps the names of the recorded Device Profiles to the local names they are renamed to
+	ProfileNames map[string]string `json:"profileNames,omitempty"`
+	// Tags are added to every imported Event, replacing any recorded tags of the same name
+	Tags map[string]any `json:"tags,omitempty"`
+}
+
+const (
+	// ProvisioningKindProfile is the kind of a Device Profile which failed to be provisioned
+	ProvisioningKindProfile = "deviceProfile"
+	// ProvisioningKindDevice is the kind of a Device which failed to be provisioned
+	ProvisioningKindDevice = "device"
+)
+
+// ProvisioningReport DTO lists the Device Profiles and Devices of an import which failed to be added to or updated
+// in Core Metadata. The data is imported regardless, so those provisioned successfully aren't rolled back.
+type ProvisioningReport struct {
+	// Failures is the list of Device Profiles and Devices which failed, profiles first, each in the imported order
+	Failures []ProvisioningFailure `json:"failures"`
+}
+
+// ProvisioningFailure DTO describes a Device Profile or Device which failed to be provisioned
+type ProvisioningFailure struct {
+	// Kind is either deviceProfile or device
+	Kind string `json:"kind"`
+	// Name is the name of the Device Profile or Device
+	Name string `json:"name"`
+	// Error is the reason it failed
+	Error string `json:"error"`
+}
@@ -0,0 +1,57 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import (
+	"github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+)
+
+// InjectRequest DTO specifies the one-off Events and raw messages to publish immediately, e.g. to test the error
+// handling of the downstream services without building a recording
+type InjectRequest struct {
+	// Events are published as AddEventRequests to the MessageBus. Blank Event and Reading Ids and zero Origins are set
+	// to new Ids and the current time, all other fields are published as given.
+	Events []dtos.Event `json:"events,omitempty"`
+	// Topic is the optional topic, relative to the MessageBus base topic, the Events are published to. Defaults to
+	// each Event's topic built from ServiceName and the Event's profile, device and source names.
+	Topic string `json:"topic,omitempty"`
+	// ServiceName is the optional Device Service name used to build the Events' topics. Defaults to the service of the
+	// Event's device in the recorded data, or unknown-service if the device isn't recorded.
+	ServiceName string `json:"serviceName,omitempty"`
+	// Messages are raw payloads published verbatim, as when replaying opaque recordings, e.g. malformed Events
+	Messages []InjectMessage `json:"messages,omitempty"`
+	// CorrelationID is the correlation ID of the inject request, set by the service from the X-Correlation-ID header
+	// rather than the JSON. It is the RequestId of the injected AddEventRequests and the correlation ID of the
+	// injected messages.
+	CorrelationID string `json:"-"`
+}
+
+// InjectMessage DTO specifies a raw message to publish verbatim
+type InjectMessage struct {
+	// Topic is the topic, relative to the MessageBus base topic, the message is published to
+	Topic string `json:"topic"`
+	// ContentType is the optional content type of the payload. Defaults to application/json.
+	ContentType string `json:"contentType,omitempty"`
+	// Payload is the raw message payload, base64 encoded in JSON
+	Payload []byte `json:"payload"`
+}
+
+// InjectResponse DTO contains the number of Events and messages published by an inject request
+type InjectResponse struct {
+	PublishedEventCount   int `json:"publishedEventCount"`
+	PublishedMessageCount int `json:"publishedMessageCount"`
+}
@@ -0,0 +1,59 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import "encoding/json"
+
+const (
+	// JobKindImport is the kind of job which imports recorded data, see POST /api/v3/data
+	JobKindImport = "import"
+	// JobKindExport is the kind of job which exports recorded data to a local path, see POST /api/v3/data/export
+	// and POST /api/v3/data/downsample
+	JobKindExport = "export"
+)
+
+const (
+	// JobStateRunning is the state of a job whose operation is still running
+	JobStateRunning = "running"
+	// JobStateCompleted is the state of a job whose operation succeeded
+	JobStateCompleted = "completed"
+	// JobStateFailed is the state of a job whose operation failed
+	JobStateFailed = "failed"
+	// JobStateCanceled is the state of a job which was canceled before its operation completed
+	JobStateCanceled = "canceled"
+)
+
+// JobStatus DTO describes a long operation run as an asynchronous job, so HTTP clients don't time out waiting
+// for it. The result of the operation is the same as if it had been run synchronously.
+type JobStatus struct {
+	// Id identifies the job, i.e. for GET /api/v3/jobs/{id}
+	Id string `json:"id"`
+	// Kind is the kind of operation the job runs, either JobKindImport or JobKindExport
+	Kind string `json:"kind"`
+	// State is the state of the job, one of JobStateRunning, JobStateCompleted, JobStateFailed or JobStateCanceled
+	State string `json:"state"`
+	// CreatedAt is the time the job was created in nanoseconds since the epoch
+	CreatedAt int64 `json:"createdAt"`
+	// FinishedAt is the time the job finished in nanoseconds since the epoch, once it has finished
+	FinishedAt int64 `json:"finishedAt,omitempty"`
+	// StatusCode is the HTTP status code the operation returned, once the job has finished
+	StatusCode int `json:"statusCode,omitempty"`
+	// Result is the JSON response of the operation, if it returned one
+	Result json.RawMessage `json:"result,omitempty"`
+	// Message is the response message of the operation, i.e. the reason it failed, if it didn't return JSON
+	Message string `json:"message,omitempty"`
+}
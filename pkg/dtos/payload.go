@@ -0,0 +1,69 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// PayloadSizeReport DTO contains the distribution of the payload sizes of the Events captured by the current or last
+// recording, along with the devices and Readings contributing the most data, to help decide on recording filters
+type PayloadSizeReport struct {
+	// EventCount is the number of Events whose payload sizes were tracked
+	EventCount int `json:"eventCount"`
+	// TotalBytes is the total size in bytes of the Events' payloads as received
+	TotalBytes int64 `json:"totalBytes"`
+	// MinBytes is the size in bytes of the smallest payload
+	MinBytes int `json:"minBytes"`
+	// MaxBytes is the size in bytes of the largest payload
+	MaxBytes int `json:"maxBytes"`
+	// MeanBytes is the mean size in bytes of the payloads
+	MeanBytes int `json:"meanBytes"`
+	// Histogram is the count of payloads in each size bucket, from the smallest sizes up
+	Histogram []PayloadSizeBucket `json:"histogram"`
+	// LargestDevices lists the devices with the most payload bytes, largest first
+	LargestDevices []DevicePayloadSize `json:"largestDevices"`
+	// LargestReadings lists the largest Readings captured, largest first
+	LargestReadings []ReadingPayloadSize `json:"largestReadings"`
+}
+
+// PayloadSizeBucket DTO contains the count of payloads within a size range
+type PayloadSizeBucket struct {
+	// MaxBytes is the inclusive upper bound of the bucket's payload sizes. Not set for the last bucket, which holds
+	// all the larger payloads.
+	MaxBytes int `json:"maxBytes,omitempty"`
+	// Count is the number of payloads in the bucket
+	Count int `json:"count"`
+}
+
+// DevicePayloadSize DTO contains the payload sizes of a device's Events
+type DevicePayloadSize struct {
+	DeviceName string `json:"deviceName"`
+	// EventCount is the number of the device's Events
+	EventCount int `json:"eventCount"`
+	// TotalBytes is the total size in bytes of the device's payloads
+	TotalBytes int64 `json:"totalBytes"`
+	// MaxBytes is the size in bytes of the device's largest payload
+	MaxBytes int `json:"maxBytes"`
+}
+
+// ReadingPayloadSize DTO describes a Reading by the size of its value
+type ReadingPayloadSize struct {
+	DeviceName   string `json:"deviceName"`
+	ResourceName string `json:"resourceName"`
+	ValueType    string `json:"valueType"`
+	// Bytes is the size in bytes of the Reading's value, binary value or object value
+	Bytes int `json:"bytes"`
+	// Origin is the Reading's origin, which identifies it within the recording
+	Origin int64 `json:"origin"`
+}
@@ -0,0 +1,58 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// PipelineStats DTO contains the internal statistics of the recording pipeline and the replay publishing, to help
+// diagnose why a recording is dropping or lagging
+type PipelineStats struct {
+	// Recording indicates if a recording is in progress
+	Recording bool `json:"recording"`
+	// ReceivedMessageCount is the number of messages received by the session pipelines since the recording started
+	ReceivedMessageCount int `json:"receivedMessageCount"`
+	// RecordedEventCount is the number of Events, or messages, which passed the filters and were counted for the
+	// recording. The difference to ReceivedMessageCount was filtered out or failed in the pipeline.
+	RecordedEventCount int `json:"recordedEventCount"`
+	// PendingBatchEventCount is the number of recorded Events, or messages, waiting in the batch to be flushed
+	PendingBatchEventCount int `json:"pendingBatchEventCount"`
+	// BatchFlushCount is the number of batches flushed since the recording started, which is more than one for
+	// continuous recordings
+	BatchFlushCount int `json:"batchFlushCount"`
+	// LastBatchSize is the number of Events, or messages, in the last batch flushed
+	LastBatchSize int `json:"lastBatchSize"`
+	// LastBatchFlushedAt is the time the last batch was flushed in nanoseconds since the epoch
+	LastBatchFlushedAt int64 `json:"lastBatchFlushedAt,omitempty"`
+	// PublishQueueDepths is the number of replayed Events queued for each publish worker, while a replay with
+	// more than one publish worker is running
+	PublishQueueDepths []int `json:"publishQueueDepths,omitempty"`
+	// GoroutineCount is the number of goroutines currently running in the service
+	GoroutineCount int `json:"goroutineCount"`
+	// PipelineErrorCount is the number of errors returned by the session pipeline functions since the recording
+	// started
+	PipelineErrorCount int `json:"pipelineErrorCount"`
+	// LastPipelineErrors is the most recent errors returned by the session pipeline functions, oldest first
+	LastPipelineErrors []PipelineError `json:"lastPipelineErrors,omitempty"`
+}
+
+// PipelineError DTO describes an error returned by a session pipeline function
+type PipelineError struct {
+	// Function is the name of the pipeline function which returned the error
+	Function string `json:"function"`
+	// Error is the error message
+	Error string `json:"error"`
+	// Timestamp is the time the error occurred in nanoseconds since the epoch
+	Timestamp int64 `json:"timestamp"`
+}
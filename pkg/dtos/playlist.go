@@ -0,0 +1,115 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import "time"
+
+const (
+	// PlaylistStatePending is the state of a playlist entry which hasn't started
+	PlaylistStatePending = "pending"
+	// PlaylistStateRunning is the state of a playlist, or its entry, which is running
+	PlaylistStateRunning = "running"
+	// PlaylistStateCompleted is the state of a playlist, or its entry, which completed
+	PlaylistStateCompleted = "completed"
+	// PlaylistStateFailed is the state of a playlist, or its entry, which failed
+	PlaylistStateFailed = "failed"
+	// PlaylistStateCanceled is the state of a playlist, or its entry, which was canceled
+	PlaylistStateCanceled = "canceled"
+	// PlaylistStateTimedOut is the state of a playlist entry which wasn't triggered within its TriggerTimeout
+	PlaylistStateTimedOut = "timedOut"
+	// PlaylistStateSkipped is the state of a playlist entry which didn't run since the playlist branched past it
+	PlaylistStateSkipped = "skipped"
+)
+
+// Playlist DTO is an ordered list of recordings replayed one after the other as a single session, each with its own
+// replay options, for scripted demos and multi-phase load tests. Entries may wait for a trigger before replaying and
+// branch to other entries, for semi-interactive demo scripts.
+type Playlist struct {
+	// Name is the name of the playlist, if named
+	Name string `json:"name,omitempty"`
+	// Entries is the list of recordings replayed in order. At least one is required.
+	Entries []PlaylistEntry `json:"entries"`
+}
+
+// PlaylistEntry DTO is a recording in a playlist and how it is replayed
+type PlaylistEntry struct {
+	// Name identifies the entry in the playlist's status, which must be unique within the playlist. Defaults to
+	// entry-N, where N is the entry's position starting from 1.
+	Name string `json:"name,omitempty"`
+	// Path is the path of the recording file imported before the entry is replayed, which must exist within one of
+	// the directories allow-listed by the ImportPaths App Setting. The recorded data is replayed as is when neither
+	// Path nor Data are set.
+	Path string `json:"path,omitempty"`
+	// Data is the recording embedded in the playlist, in any of the formats the recorded data can be imported in,
+	// which is imported before the entry is replayed instead of a file, so the playlist can be shared on its own
+	Data []byte `json:"data,omitempty"`
+	// Signature is the signature of the embedded Data, if signed, which is verified when the Data is imported
+	Signature string `json:"signature,omitempty"`
+	// Replay is the request the entry's replay is started with. The label defaults to the playlist and entry names.
+	Replay ReplayRequest `json:"replay"`
+	// DelayAfter is how long to wait after the entry's replay completes before the next entry starts
+	DelayAfter time.Duration `json:"delayAfter,omitempty"`
+	// WaitForTrigger, if true, primes the entry's replay in standby so it only starts once triggered, either via the
+	// API or by a message on the topic set by the ReplayTriggerTopic App Setting. See ReplayRequest.Standby.
+	WaitForTrigger bool `json:"waitForTrigger,omitempty"`
+	// TriggerTimeout is how long to wait for the trigger when WaitForTrigger is set. The entry times out when not
+	// triggered in time, which fails it unless OnTimeout is set. Waits indefinitely when 0.
+	TriggerTimeout time.Duration `json:"triggerTimeout,omitempty"`
+	// Next is the name of the entry to continue with once the entry completes, which may be an earlier entry to loop.
+	// Defaults to the following entry, the playlist completing after the last.
+	Next string `json:"next,omitempty"`
+	// OnTimeout is the name of the entry to continue with when the entry times out waiting for its trigger
+	OnTimeout string `json:"onTimeout,omitempty"`
+	// OnFailure is the name of the entry to continue with when the entry fails, rather than failing the playlist
+	OnFailure string `json:"onFailure,omitempty"`
+}
+
+// PlaylistStatus DTO contains the status of the running or last playlist
+type PlaylistStatus struct {
+	// Name is the name of the playlist, if named
+	Name string `json:"name,omitempty"`
+	// State is the state of the playlist, which is one of running, completed, failed or canceled
+	State string `json:"state"`
+	// CurrentEntry is the position of the entry running or waiting for its delay to elapse, starting from 0
+	CurrentEntry int `json:"currentEntry"`
+	// StartedAt is the time the playlist started in nanoseconds since the epoch
+	StartedAt int64 `json:"startedAt"`
+	// FinishedAt is the time the playlist finished in nanoseconds since the epoch. Zero while running.
+	FinishedAt int64 `json:"finishedAt,omitempty"`
+	// Entries is the status of each entry of the playlist, in order
+	Entries []PlaylistEntryStatus `json:"entries"`
+	// Message describes why the playlist failed, if it did
+	Message string `json:"message,omitempty"`
+}
+
+// PlaylistEntryStatus DTO contains the status of an entry of a playlist
+type PlaylistEntryStatus struct {
+	Name string `json:"name"`
+	// State is the state of the entry's last run, which is one of pending, running, completed, failed, canceled,
+	// timedOut or skipped
+	State string `json:"state"`
+	// RunCount is the number of times the entry has run, which is more than once when the playlist loops
+	RunCount int `json:"runCount"`
+	// StartedAt is the time the entry's last run started in nanoseconds since the epoch. Zero while pending.
+	StartedAt int64 `json:"startedAt,omitempty"`
+	// FinishedAt is the time the entry's last run finished in nanoseconds since the epoch. Zero until finished.
+	FinishedAt int64 `json:"finishedAt,omitempty"`
+	// EventCount is the number of Events the entry's last replay published
+	EventCount int `json:"eventCount"`
+	// Message describes why the entry's last run failed or timed out, if it did
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,49 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import coreDtos "github.com/edgexfoundry/go-mod-core-contracts/v3/dtos"
+
+// ReadingsOnlyEvent DTO holds an Event of a readings-only recording as its device, profile, source and origin along
+// with its reading tuples, without the Ids, Tags and API version of the full Event envelope, which are reconstructed
+// when replayed. See RecordRequest.ReadingsOnly.
+type ReadingsOnlyEvent struct {
+	DeviceName  string `json:"deviceName"`
+	ProfileName string `json:"profileName"`
+	SourceName  string `json:"sourceName"`
+	Origin      int64  `json:"origin"`
+	// Readings holds the tuple of each of the Event's Readings, in order
+	Readings []ReadingTuple `json:"readings"`
+}
+
+// ReadingTuple DTO holds a Reading of a readings-only recording as its resource, value type, value and origin. The
+// Reading's device and profile are those of its Event unless set.
+type ReadingTuple struct {
+	ResourceName string `json:"resourceName"`
+	ValueType    string `json:"valueType"`
+	Value        string `json:"value,omitempty"`
+	// OriginOffset is the Reading's origin relative to its Event's origin, which is usually 0
+	OriginOffset int64  `json:"originOffset,omitempty"`
+	Units        string `json:"units,omitempty"`
+	// DeviceName is the Reading's device, when it differs from its Event's
+	DeviceName string `json:"deviceName,omitempty"`
+	// ProfileName is the Reading's profile, when it differs from its Event's
+	ProfileName string `json:"profileName,omitempty"`
+	// Reading is the Reading in full, when its value isn't a simple value, i.e. binary, object, null or empty
+	// readings, in which case the other fields aren't set
+	Reading *coreDtos.BaseReading `json:"reading,omitempty"`
+}
@@ -24,6 +24,15 @@ import (
 
 // RecordRequest DTO specifies the record parameters to start a recording session
 type RecordRequest struct {
+	// Name is the optional name of the recording. If not set the name is generated from the
+	// RecordingNameTemplate App Setting, when configured.
+	Name string `json:"name,omitempty"`
+	// Label is an optional free-form label identifying the recording session. It is included in the session's
+	// log messages and status, so the session can be correlated across observability tools.
+	Label string `json:"label,omitempty"`
+	// CorrelationID is the correlation ID of the request which started the recording, set by the service from the
+	// X-Correlation-ID header rather than the JSON. It is included in the session's log messages and status.
+	CorrelationID string `json:"-"`
 	// Duration is the amount of time to record. Required if EventLimit is 0.
 	Duration time.Duration `json:"duration"`
 	// EventLimit is the maximum number of Events to record. Required if Duration is 0.
@@ -42,24 +51,302 @@ type RecordRequest struct {
 	ExcludeDevices []string `json:"excludeDevices"`
 	// ExcludeSources is a list of Source names to Filter Out.
 	ExcludeSources []string `json:"excludeSources"`
+
+	// Opaque, if true, records the raw message payloads verbatim, whatever their content type, rather than decoding
+	// them as EdgeX Events. The device profile, device and source filters can't be used with opaque recordings and
+	// EventLimit limits the number of messages recorded.
+	Opaque bool `json:"opaque,omitempty"`
+
+	// ReadingsOnly, if true, strips the Events down to their readings as they are captured, dropping the Ids, Tags
+	// and API version of the Events and their Readings along with their MessageBus envelope metadata. The Events are
+	// batched, stored and exported as compact reading tuples of resource, value type, value and origin, which cuts
+	// the memory held and the size of exports of large recordings. The full Events are reconstructed, with new Ids,
+	// only when replayed. Can't be used with Opaque.
+	ReadingsOnly bool `json:"readingsOnly,omitempty"`
+
+	// Telemetry, if true, also records the service metrics telemetry messages received on the telemetry topics, i.e.
+	// telemetry/#, verbatim alongside the Events, so observability pipelines can be tested by replaying them. The
+	// telemetry topics must be covered by the service's Trigger.SubscribeTopics configuration to be received and the
+	// messages don't count towards EventLimit. Can't be used with Opaque, which records them anyway.
+	Telemetry bool `json:"telemetry,omitempty"`
+
+	// SystemEvents, if true, also records the device system events Core Metadata publishes when a device is added,
+	// updated or removed, i.e. on system-events/core-metadata/device/#, verbatim alongside the Events, so the
+	// reactions of downstream services to topology changes can be tested by replaying them. The topics must be
+	// covered by the service's Trigger.SubscribeTopics configuration to be received and the messages don't count
+	// towards EventLimit. Can't be used with Opaque, which records them anyway.
+	SystemEvents bool `json:"systemEvents,omitempty"`
+
+	// Topics is the optional list of topic rules restricting which received messages are recorded. When set, only
+	// messages received on a topic matching one of the rules, and passing that rule's filters, are recorded. Rules are
+	// evaluated in order and the first matching rule is used. The topics must be covered by the service's
+	// Trigger.SubscribeTopics configuration to be received.
+	Topics []TopicRule `json:"topics,omitempty"`
+
+	// ForwardTopic, if set, relays every message received while recording, unmodified, to this MessageBus topic, so
+	// the service can be inserted inline in a pipeline as a tap without disrupting downstream consumers. Messages are
+	// forwarded before any filtering and forwarding stops when the recording ends. The topic is relative to the
+	// MessageBus base topic. A topic ending in /# forwards each message to the topic with the /# replaced by the
+	// message's received topic, relative to the base topic. The topic must not be covered by the service's
+	// Trigger.SubscribeTopics configuration, otherwise the forwarded messages are received again.
+	ForwardTopic string `json:"forwardTopic,omitempty"`
+
+	// ForwardSink optionally relays every message received while recording, unmodified, to an external MQTT or HTTP
+	// sink, the same as ForwardTopic does to the MessageBus. It takes the same options as a replay sink. The
+	// {devicename}, {profilename} and {sourcename} placeholders aren't available since messages are forwarded before
+	// they are decoded.
+	ForwardSink *ReplaySink `json:"forwardSink,omitempty"`
+
+	// Rotation, if set, records continuously until the recording is canceled, rotating each completed segment into
+	// the segment store with the retention limits applied. Duration and EventLimit then limit each segment rather
+	// than the recording, so a segment is completed once either is reached. Requires the SegmentStoreDir App Setting.
+	Rotation *SegmentRotation `json:"rotation,omitempty"`
+}
+
+// SegmentRotation DTO specifies the retention limits for the segments of a continuous recording. The oldest segments
+// in the segment store are deleted once any of the limits is exceeded, except for the newest segment which is always
+// kept. Zero values are unlimited.
+type SegmentRotation struct {
+	// MaxSegments is the maximum number of segments kept
+	MaxSegments int `json:"maxSegments,omitempty"`
+	// MaxAge is the maximum age of the segments kept, based on the time each segment started
+	MaxAge time.Duration `json:"maxAge,omitempty"`
+	// MaxBytes is the maximum total size in bytes of the segments kept
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+}
+
+// TopicRule DTO specifies a topic pattern to record along with the filters applied to the Events received on it
+type TopicRule struct {
+	// Topic is the topic pattern, relative to the base topic prefix, i.e. "events/device/+/+/#". The MQTT style
+	// wildcards are supported, + matching a single topic level and # matching all remaining topic levels.
+	Topic string `json:"topic"`
+
+	// IncludeDeviceProfiles is a list of Device Profile names to Filter For on this topic.
+	IncludeDeviceProfiles []string `json:"includeDeviceProfiles,omitempty"`
+	// IncludeDevices is a list of Device names to Filter For on this topic.
+	IncludeDevices []string `json:"includeDevices,omitempty"`
+	// IncludeSources is a list of Source names to Filter For on this topic.
+	IncludeSources []string `json:"includeSources,omitempty"`
+
+	// ExcludeDeviceProfiles is a list of Device Profile names to Filter Out on this topic.
+	ExcludeDeviceProfiles []string `json:"excludeDeviceProfiles,omitempty"`
+	// ExcludeDevices is a list of Device names to Filter Out on this topic.
+	ExcludeDevices []string `json:"excludeDevices,omitempty"`
+	// ExcludeSources is a list of Source names to Filter Out on this topic.
+	ExcludeSources []string `json:"excludeSources,omitempty"`
+}
+
+// RecordPushRequest DTO contains the Events pushed over REST to include in the active recording, e.g. by scripts or
+// simulators that don't publish to the MessageBus
+type RecordPushRequest struct {
+	// Events are recorded as if received from the MessageBus, so they pass through the recording's topic rules and
+	// filters and count towards its EventLimit. Blank Event and Reading Ids and zero Origins are set to new Ids and
+	// the time the Events are pushed.
+	Events []coreDtos.Event `json:"events"`
+	// ReceivedTopic is the optional full topic the Events are recorded as received on, which the topic rules are
+	// matched against and the Events are replayed to. Events without a topic only match a "#" topic rule and are
+	// replayed to the topic built from their device's service.
+	ReceivedTopic string `json:"receivedTopic,omitempty"`
 }
 
 // RecordStatus DTO contains the data describing the status of a recording session
 type RecordStatus struct {
+	// Name is the name of the recording, if named
+	Name string `json:"name,omitempty"`
+	// Label is the label of the recording session, if labeled
+	Label string `json:"label,omitempty"`
+	// CorrelationID is the correlation ID of the request which started the recording in progress
+	CorrelationID string `json:"correlationId,omitempty"`
 	// InProgress indicates if the recording is currently in progress or not
 	InProgress bool `json:"inProgress"`
-	// EventCount is the count of Events batched so far (In Progress) or recorded (completed)
+	// EventCount is the count of Events, or messages for an opaque recording, batched so far (In Progress) or
+	// recorded (completed)
 	EventCount int `json:"eventCount"`
 	// Duration is the amount of time recording so far (In Progress) or recording took (completed)
 	Duration time.Duration `json:"duration"`
+	// Locked indicates if the recorded data is locked against being overwritten by a new recording or import
+	Locked bool `json:"locked"`
+	// DeadLetterCount is the count of messages captured so far (In Progress) or captured (completed) because they
+	// failed to decode as Events
+	DeadLetterCount int `json:"deadLetterCount"`
+	// TelemetryCount is the count of telemetry messages captured so far (In Progress) or captured (completed). See
+	// RecordRequest.Telemetry.
+	TelemetryCount int `json:"telemetryCount,omitempty"`
+	// SystemEventCount is the count of device system events captured so far (In Progress) or captured (completed).
+	// See RecordRequest.SystemEvents.
+	SystemEventCount int `json:"systemEventCount,omitempty"`
+	// Queue is the list of record sessions waiting to start. See the MaxQueuedSessions App Setting.
+	Queue []QueuedSession `json:"queue,omitempty"`
+	// Gaps is the list of intervals the MessageBus was disconnected during the recording. See the BusProbeInterval
+	// App Setting.
+	Gaps []RecordingGap `json:"gaps,omitempty"`
+	// ForwardedCount is the count of messages forwarded so far (In Progress) or forwarded (completed). See
+	// RecordRequest.ForwardTopic and RecordRequest.ForwardSink.
+	ForwardedCount int `json:"forwardedCount,omitempty"`
+	// ForwardFailedCount is the count of messages which failed to forward. Messages are still recorded when they fail
+	// to forward.
+	ForwardFailedCount int `json:"forwardFailedCount,omitempty"`
+	// SegmentCount is the count of segments rotated into the segment store so far (In Progress) or rotated
+	// (completed) by a continuous recording. See RecordRequest.Rotation.
+	SegmentCount int `json:"segmentCount,omitempty"`
+	// Message describes why the last recording was canceled by the service, if it was. See the SessionIdleTimeout
+	// App Setting.
+	Message string `json:"message,omitempty"`
 }
 
 // RecordedData DTO contains the data from a completed or imported recording
 type RecordedData struct {
+	// Name is the name of the recording, if named
+	Name string `json:"name,omitempty"`
 	// RecordedEvents is the list of Events that were recorded
 	RecordedEvents []coreDtos.Event `json:"recordedEvents"`
 	// Profiles is the list of Device Profiles that recorded Events referenced
 	Profiles []coreDtos.DeviceProfile `json:"profiles"`
 	// Devices is the list of Devices that that recorded Events referenced
 	Devices []coreDtos.Device `json:"devices"`
+	// Envelopes is the MessageBus envelope metadata received with each recorded Event, keyed by Event Id
+	Envelopes map[string]EnvelopeMetadata `json:"envelopes,omitempty"`
+	// Messages is the list of raw messages recorded by an opaque recording, in which case there are no Events
+	Messages []OpaqueMessage `json:"messages,omitempty"`
+	// DeadLetters is the list of messages received while recording that failed to decode as Events
+	DeadLetters []DeadLetter `json:"deadLetters,omitempty"`
+	// Telemetry is the list of service metrics telemetry messages recorded alongside the Events. See
+	// RecordRequest.Telemetry.
+	Telemetry []OpaqueMessage `json:"telemetry,omitempty"`
+	// SystemEvents is the list of device system events recorded alongside the Events. See
+	// RecordRequest.SystemEvents.
+	SystemEvents []OpaqueMessage `json:"systemEvents,omitempty"`
+	// Annotations is the list of annotations marking points in the recording, if any
+	Annotations []Annotation `json:"annotations,omitempty"`
+	// Metadata describes where and how the data was recorded, if known
+	Metadata *RecordingMetadata `json:"metadata,omitempty"`
+	// Delta holds the Events, Devices and Profiles as their differences against a baseline recording, when the data
+	// was stored as a delta, in which case RecordedEvents, Devices and Profiles are empty until it is imported
+	Delta *RecordedDataDelta `json:"delta,omitempty"`
+	// ReadingsOnlyEvents holds the Events of a readings-only recording as reading tuples, when the data was stored
+	// compacted, in which case RecordedEvents is empty until it is imported. See RecordRequest.ReadingsOnly.
+	ReadingsOnlyEvents []ReadingsOnlyEvent `json:"readingsOnlyEvents,omitempty"`
+}
+
+// RecordingMetadata DTO describes where and how a recording was captured, so recordings are self-describing
+type RecordingMetadata struct {
+	// Hostname is the name of the host the recording was captured on
+	Hostname string `json:"hostname"`
+	// ServiceVersion is the version of the app-record-replay service which captured the recording
+	ServiceVersion string `json:"serviceVersion"`
+	// SDKVersion is the version of the App Functions SDK the service was built with
+	SDKVersion string `json:"sdkVersion"`
+	// EdgeXVersion is the version of the EdgeX contracts the service was built with, which identifies the EdgeX
+	// stack version the recorded data conforms to
+	EdgeXVersion string `json:"edgexVersion"`
+	// StartedAt is the time the recording started in nanoseconds since the epoch
+	StartedAt int64 `json:"startedAt"`
+	// Request is the request the recording was started with, including the limits and filters used
+	Request RecordRequest `json:"request"`
+	// Gaps is the list of intervals the MessageBus was disconnected during the recording, if any
+	Gaps []RecordingGap `json:"gaps,omitempty"`
+	// ProfileHashes is the hash of the material parts of each Device Profile the Events were captured with, keyed by
+	// profile name. Set once the profiles are captured. See the ReplayProfileDriftPolicy App Setting.
+	ProfileHashes map[string]string `json:"profileHashes,omitempty"`
+	// ClockOffsets is the clock offset added to the Origins of the Events recorded from each Device Service, keyed by
+	// service name, if any. See the SourceClockOffsets App Setting.
+	ClockOffsets map[string]time.Duration `json:"clockOffsets,omitempty"`
+}
+
+// RecordingGap DTO describes an interval during a recording when the MessageBus was disconnected, so Events published
+// during it weren't recorded
+type RecordingGap struct {
+	// Start is the time the disconnect was detected in nanoseconds since the epoch
+	Start int64 `json:"start"`
+	// End is the time the reconnect was detected in nanoseconds since the epoch. Zero while still disconnected.
+	End int64 `json:"end"`
+}
+
+// OpaqueMessage DTO contains a message recorded verbatim by an opaque recording
+type OpaqueMessage struct {
+	// EnvelopeMetadata holds the MessageBus envelope fields received with the message
+	EnvelopeMetadata
+	// Payload is the raw message payload
+	Payload []byte `json:"payload"`
+}
+
+// DeadLetter DTO contains a message received while recording that failed to decode as an Event
+type DeadLetter struct {
+	// EnvelopeMetadata holds the MessageBus envelope fields received with the message
+	EnvelopeMetadata
+	// Payload is the raw message payload
+	Payload []byte `json:"payload"`
+	// Error is the reason the payload failed to decode
+	Error string `json:"error"`
+}
+
+// EnvelopeMetadata DTO contains the MessageBus envelope fields received with a recorded Event
+type EnvelopeMetadata struct {
+	// ReceivedTopic is the full topic the Event was received on
+	ReceivedTopic string `json:"receivedTopic"`
+	// CorrelationID is the correlation ID from the received envelope
+	CorrelationID string `json:"correlationId"`
+	// ContentType is the content type of the received envelope payload
+	ContentType string `json:"contentType"`
+	// ReceivedAt is the time the Event was received in nanoseconds since the epoch
+	ReceivedAt int64 `json:"receivedAt"`
+}
+
+// RecordedDataSummary DTO contains the recorded Readings aggregated into fixed time windows
+type RecordedDataSummary struct {
+	// Window is the size of each time window
+	Window time.Duration `json:"window"`
+	// EventCount is the total number of Events summarized
+	EventCount int `json:"eventCount"`
+	// Windows is the list of time windows, in time order, which contain at least one Reading
+	Windows []SummaryWindow `json:"windows"`
+}
+
+// SummaryWindow DTO contains the aggregated Readings for a single time window
+type SummaryWindow struct {
+	// Start is the start of the window in nanoseconds since the epoch
+	Start int64 `json:"start"`
+	// Resources is the aggregated Readings per device resource
+	Resources []ResourceSummary `json:"resources"`
+}
+
+// ResourceSummary DTO contains the aggregated Readings for a single device resource within a time window.
+// Min, Max and Avg only include Readings with numeric values.
+type ResourceSummary struct {
+	DeviceName   string  `json:"deviceName"`
+	ResourceName string  `json:"resourceName"`
+	Count        int     `json:"count"`
+	NumericCount int     `json:"numericCount"`
+	Min          float64 `json:"min"`
+	Max          float64 `json:"max"`
+	Avg          float64 `json:"avg"`
+}
+
+// FeatureVectors DTO contains the recorded numeric Readings aggregated into a fixed-length feature vector per time
+// window, for feeding ML feature stores and models
+type FeatureVectors struct {
+	// Window is the size of each time window
+	Window time.Duration `json:"window"`
+	// Features is the name of each feature, as deviceName/resourceName/aggregation, in the order of the vector values
+	Features []string `json:"features"`
+	// Vectors is the list of feature vectors, in time order, for the windows which contain at least one numeric
+	// Reading
+	Vectors []FeatureVector `json:"vectors"`
+}
+
+// FeatureVector DTO contains the features of a single time window
+type FeatureVector struct {
+	// Start is the start of the window in nanoseconds since the epoch
+	Start int64 `json:"start"`
+	// Values is the value of each feature, or null for those of resources without numeric Readings in the window
+	// other than counts, which are 0
+	Values []*float64 `json:"values"`
+}
+
+// DeleteEventsResult DTO describes the Events deleted from the recorded data
+type DeleteEventsResult struct {
+	// DeletedEventCount is the count of Events deleted
+	DeletedEventCount int `json:"deletedEventCount"`
+	// RemainingEventCount is the count of Events left in the recorded data
+	RemainingEventCount int `json:"remainingEventCount"`
 }
@@ -18,27 +18,498 @@ package dtos
 
 import "time"
 
+const (
+	// ReplayWarmupFull prepares all the Events before the replay starts
+	ReplayWarmupFull = "full"
+	// ReplayWarmupBackground prepares the Events in the background while the replay runs
+	ReplayWarmupBackground = "background"
+)
+
+const (
+	// ReplayPublishErrorAbort stops the replay on the first publish error
+	ReplayPublishErrorAbort = "abort"
+	// ReplayPublishErrorSkip skips the Event, or message, that failed to publish and continues the replay
+	ReplayPublishErrorSkip = "skip"
+	// ReplayPublishErrorRetry retries the publish with an exponential backoff, stopping the replay once the retries
+	// are exhausted
+	ReplayPublishErrorRetry = "retry"
+)
+
+const (
+	// ReplaySinkMessageBus publishes the replayed Events to the EdgeX MessageBus
+	ReplaySinkMessageBus = "messagebus"
+	// ReplaySinkMQTT publishes the replayed Events to an external MQTT broker
+	ReplaySinkMQTT = "mqtt"
+	// ReplaySinkHTTP posts the replayed Events to an HTTP endpoint
+	ReplaySinkHTTP = "http"
+	// ReplaySinkEdgeXMessageBus publishes the replayed Events to the MQTT MessageBus of a different EdgeX instance,
+	// wrapped in MessageBus envelopes on the instance's Event topics
+	ReplaySinkEdgeXMessageBus = "edgex-messagebus"
+	// ReplaySinkEdgeXCoreData posts the replayed Events to the Core Data service of a different EdgeX instance
+	ReplaySinkEdgeXCoreData = "edgex-coredata"
+)
+
+const (
+	// ReplayValueBoundsClamp clamps the numeric Reading values outside their resource's Minimum and Maximum to the
+	// bound they exceed
+	ReplayValueBoundsClamp = "clamp"
+	// ReplayValueBoundsReject drops the Readings whose numeric value is outside their resource's Minimum and Maximum
+	ReplayValueBoundsReject = "reject"
+)
+
+const (
+	// ReplayBreakpointEqual matches Readings whose value equals the breakpoint's Value
+	ReplayBreakpointEqual = "=="
+	// ReplayBreakpointNotEqual matches Readings whose value differs from the breakpoint's Value
+	ReplayBreakpointNotEqual = "!="
+	// ReplayBreakpointGreater matches Readings whose numeric value is greater than the breakpoint's Value
+	ReplayBreakpointGreater = ">"
+	// ReplayBreakpointGreaterOrEqual matches Readings whose numeric value is greater than or equal to the breakpoint's
+	// Value
+	ReplayBreakpointGreaterOrEqual = ">="
+	// ReplayBreakpointLess matches Readings whose numeric value is less than the breakpoint's Value
+	ReplayBreakpointLess = "<"
+	// ReplayBreakpointLessOrEqual matches Readings whose numeric value is less than or equal to the breakpoint's Value
+	ReplayBreakpointLessOrEqual = "<="
+)
+
 // ReplayRequest DTO specifies the replay parameters to start a replay session
 type ReplayRequest struct {
 	// ReplayRate is the rate at which to replay the data compared to the rate the data was recorded.
 	// Values must be greater than 0 where 1 is the same rate, less than 1 is slower rate and greater than 1 is
-	// faster rate than the rate the data was recorded.
+	// faster rate than the rate the data was recorded. Must not be set when TimeWarpDuration or AlignTimeOfDay is set.
 	ReplayRate float32 `json:"replayRate"`
 
-	// RepeatCount is the count of number of times to repeat the replay. Optional, defaults to 1 if value is less than 1.
+	// RepeatCount is the count of number of times to repeat the replay. Optional, defaults to 1 if value is less than 1,
+	// except when AlignTimeOfDay is set, in which case the replay loops until canceled.
 	RepeatCount int `json:"repeatCount"`
+
+	// MaxDuration optionally limits the wall-clock time the replay may run for. The replay is stopped once it is
+	// reached, regardless of the repeats remaining, protecting shared test environments from runaway sessions. The
+	// time of a replay in standby starts from the trigger. Optional, the replay isn't limited when 0.
+	MaxDuration time.Duration `json:"maxDuration,omitempty"`
+
+	// Script is an optional Lua script run against each Event before it is published, allowing ad-hoc filtering and
+	// modification without rebuilding the service. The script is given the Event as the event global table, with the
+	// same fields as its JSON. Returning false or nil skips the Event, true replays the event global, including any
+	// changes made to it, and a table replays that table as the Event. Only the base, table, string and math
+	// libraries are available and globals set by the script are kept between Events.
+	Script string `json:"script,omitempty"`
+
+	// ShadowMode, if true, records the live Events from the message bus while the replay is running and produces
+	// a ShadowReport comparing the live data against the replayed data once the replay ends.
+	ShadowMode bool `json:"shadowMode,omitempty"`
+
+	// Telemetry, if true, also replays the recorded service metrics telemetry messages verbatim to the topics they
+	// were received on, keeping their timing relative to the first Event at the replay rate. Requires background
+	// publishing. Can't be used with AlignTimeOfDay.
+	Telemetry bool `json:"telemetry,omitempty"`
+
+	// SystemEvents, if true, also replays the recorded device system events verbatim to the topics they were
+	// received on, in sequence with the Events, each published before the first Event recorded after it. The system
+	// events recorded after the last Event are published at the end of each iteration. Requires background
+	// publishing.
+	SystemEvents bool `json:"systemEvents,omitempty"`
+
+	// LatencyTopic, if set, measures the end-to-end latency of the replayed Events through the pipeline, producing a
+	// LatencyReport. Each replayed Event is tagged with the time it was published and the Events received back on
+	// topics matching LatencyTopic, i.e. the topic the pipeline publishes the Events to once processed, are timed
+	// against it. The topic is relative to the MessageBus base topic, may use the + and # wildcards, and must be
+	// covered by the service's Trigger.SubscribeTopics configuration. Can't be used with ShadowMode.
+	LatencyTopic string `json:"latencyTopic,omitempty"`
+	// LatencyTimeout is how long the replay waits, after the last Event is published, for the Events still in the
+	// pipeline to be received on the LatencyTopic. Defaults to 5 seconds when 0.
+	LatencyTimeout time.Duration `json:"latencyTimeout,omitempty"`
+
+	// UseEnvelopeTiming, if true, paces the replay using the times the Events were originally received from the
+	// message bus rather than the Event origins. Events without recorded envelope metadata use their origin.
+	UseEnvelopeTiming bool `json:"useEnvelopeTiming,omitempty"`
+
+	// StartAnnotation, if set, starts the replay from the first annotation with this label, skipping the Events
+	// recorded before it. Each repeat starts from the annotation. Can't be used with TimeWarpDuration or
+	// AlignTimeOfDay.
+	StartAnnotation string `json:"startAnnotation,omitempty"`
+
+	// ValueBounds optionally enforces the Minimum and Maximum of the Device Profile resources on the numeric Reading
+	// values as they are replayed, so hand-edited or synthetic recordings stay physically plausible. Valid values are
+	// ReplayValueBoundsClamp and ReplayValueBoundsReject, where an Event whose Readings are all rejected isn't
+	// published. The profiles are loaded from Core Metadata, falling back to the recorded profiles for those missing.
+	// Values aren't checked when not set. Can't be used with SourceURL or opaque recordings.
+	ValueBounds string `json:"valueBounds,omitempty"`
+
+	// Breakpoints optionally pause the replay before publishing an Event matching any of them, so the replay can be
+	// stepped through Event by Event, or resumed until the next match, via the replay step and resume APIs while
+	// debugging. Can't be used with TimeWarpDuration, AlignTimeOfDay, Telemetry, SourceURL or opaque recordings.
+	Breakpoints []ReplayBreakpoint `json:"breakpoints,omitempty"`
+
+	// Label is an optional free-form label identifying the replay session. It is included in the session's
+	// log messages and status, so the session can be correlated across observability tools.
+	Label string `json:"label,omitempty"`
+	// CorrelationID is the correlation ID of the request which started the replay, set by the service from the
+	// X-Correlation-ID header rather than the JSON. It is included in the session's log messages and status, and is
+	// the RequestId of the replayed AddEventRequests so the replayed Events can be traced back to the request.
+	CorrelationID string `json:"-"`
+
+	// DevicePriorities optionally assigns replay priorities keyed by device name or by device profile name, which
+	// is used as the device class. A device name entry takes precedence over its profile's entry and devices not
+	// matched have priority 0. When set, the replay is paced against a fixed schedule and, while it falls behind
+	// that schedule by more than MaxReplayLag, only Events with the highest assigned priority are published.
+	DevicePriorities map[string]int `json:"devicePriorities,omitempty"`
+
+	// MaxReplayLag is how far a prioritized replay may fall behind schedule before lower priority Events are
+	// dropped. Optional, defaults to 1s. Only used when DevicePriorities is set.
+	MaxReplayLag time.Duration `json:"maxReplayLag,omitempty"`
+
+	// Warmup optionally prepares the Events for replay ahead of publishing them, validating and marshaling each one so
+	// errors surface before or early in the replay rather than part way through, and reducing the publish jitter.
+	// ReplayWarmupFull prepares all the Events before the replay starts, failing the start on error, while
+	// ReplayWarmupBackground prepares them while the first Events are published, stopping the replay on error.
+	// The prepared Events are held in memory for the duration of the replay.
+	Warmup string `json:"warmup,omitempty"`
+
+	// OnPublishError is the policy applied when publishing an Event, or message, fails. Valid values are
+	// ReplayPublishErrorAbort, ReplayPublishErrorSkip and ReplayPublishErrorRetry. Optional, defaults to abort.
+	OnPublishError string `json:"onPublishError,omitempty"`
+
+	// MaxPublishRetries is the number of times a failed publish is retried before the replay is stopped.
+	// Optional, defaults to 5. Only used when OnPublishError is retry.
+	MaxPublishRetries int `json:"maxPublishRetries,omitempty"`
+
+	// PublishRetryInterval is the wait before the first retry of a failed publish, which doubles for each following
+	// retry up to MaxPublishRetryInterval. Optional, defaults to 1s. Only used when OnPublishError is retry.
+	PublishRetryInterval time.Duration `json:"publishRetryInterval,omitempty"`
+
+	// MaxPublishRetryInterval is the longest wait between retries of a failed publish. Optional, defaults to 30s.
+	// Only used when OnPublishError is retry.
+	MaxPublishRetryInterval time.Duration `json:"maxPublishRetryInterval,omitempty"`
+
+	// SimulationServiceName optionally registers the replayed devices under the named virtual device service in Core
+	// Metadata, e.g. device-replay, adding the service if needed, so replayed devices are distinguished from real ones.
+	// Devices that already exist in Core Metadata are left unchanged. The replayed Events are published to the
+	// service's topics.
+	SimulationServiceName string `json:"simulationServiceName,omitempty"`
+
+	// TimeWarpDuration optionally maps the wall-clock window of the recorded data onto a target window of this
+	// length, e.g. replaying a 24-hour recording within a 1-hour test slot. The spacing between Events and the
+	// rewritten Origins are scaled proportionally, so the replay rate is derived from the two windows and ReplayRate
+	// must not be set.
+	TimeWarpDuration time.Duration `json:"timeWarpDuration,omitempty"`
+
+	// TimeWarpStart is the start of the target window, in nanoseconds since the epoch, which the rewritten Origins
+	// are mapped onto. Optional, defaults to the time the replay starts. Each repeat maps onto the window following
+	// that of the previous repeat. Only used when TimeWarpDuration is set.
+	TimeWarpStart int64 `json:"timeWarpStart,omitempty"`
+
+	// AlignTimeOfDay, if true, replays the recording, typically of 24 hours, aligned with the wall-clock time-of-day
+	// and loops it daily, so the replayed data matches the current hour, e.g. for dashboards in demo environments.
+	// Each Event is replayed at its recorded time-of-day, relative to the start of the recording, in the service's
+	// local time zone and Events whose time-of-day has already passed when the replay starts wait for the next day.
+	// Only the first 24 hours of the recording are replayed each day. RepeatCount is the number of days to replay,
+	// where 0 loops until the replay is canceled. ReplayRate, TimeWarpDuration and DevicePriorities must not be set.
+	AlignTimeOfDay bool `json:"alignTimeOfDay,omitempty"`
+
+	// Sinks optionally lists the destinations the replayed Events are published to, so a single replay can feed
+	// mirrored test environments. Optional, defaults to publishing to the EdgeX MessageBus only. When set, the
+	// MessageBus is only published to if listed. Each Event is published as an AddEventRequest to every enabled
+	// sink in turn.
+	Sinks []ReplaySink `json:"sinks,omitempty"`
+
+	// SourceURL optionally streams the recording to replay from the http or https URL, e.g. an export link or an
+	// object store URL, publishing each Event as soon as it is downloaded and decoded rather than replaying the
+	// imported recording, which reduces the time to replay huge recordings. The recording must be in the JSON export
+	// format, optionally gzip or zlib compressed, and the URL must start with one of the URLs allow-listed by the
+	// ReplaySources App Setting. Events are paced using their origins and published to the topics of their devices'
+	// services from Core Metadata. Each repeat downloads the recording again. ShadowMode, UseEnvelopeTiming,
+	// DevicePriorities, Warmup, SimulationServiceName, TimeWarpDuration, AlignTimeOfDay, FanOut, Standby and
+	// PublishWorkers must not be set.
+	SourceURL string `json:"sourceUrl,omitempty"`
+
+	// FanOut optionally clones each recorded device this many times, replaying every Event once for each clone, so a
+	// recording of a single device simulates the traffic of a fleet. The clones of a device are named after it with
+	// the clone number appended, i.e. device-A-1 to device-A-N, and published under the same Device Service. With
+	// SimulationServiceName set the clones are registered rather than the recorded devices. Optional, 0 replays the
+	// recorded devices. Can't be used with SourceURL or opaque recordings.
+	FanOut int `json:"fanOut,omitempty"`
+
+	// FanOutOffset is the offset between the Origins of the clones of each Event, so the clones' Events are distinct
+	// in time. The clones are published one after another. Optional, defaults to 1ms. Only used when FanOut is set.
+	FanOutOffset time.Duration `json:"fanOutOffset,omitempty"`
+
+	// Standby, if true, loads and primes the replay, i.e. validates it, registers the simulation devices and runs
+	// the full warm-up, but holds it until it is triggered via the replay trigger API or a message on the topic set
+	// by the ReplayTriggerTopic App Setting, so publishing starts as soon as possible after the trigger. The replay's
+	// duration and timing start from the trigger. Can't be used with SourceURL or opaque recordings.
+	Standby bool `json:"standby,omitempty"`
+
+	// PublishWorkers optionally publishes the replayed Events using this many worker goroutines for a higher
+	// throughput on multi-core gateways. The Events are partitioned by device, so each device's Events are published
+	// in order, while the Events of different devices may be published out of their recorded order. Optional,
+	// defaults to the ReplayPublishWorkers App Setting. Events are published inline when 1. Can't be used with
+	// SourceURL or opaque recordings.
+	PublishWorkers int `json:"publishWorkers,omitempty"`
+}
+
+// ReplayBreakpoint DTO specifies the conditions an Event must all meet for the replay to pause before publishing it.
+// At least one of DeviceName, ResourceName, Operator or Offset must be set.
+type ReplayBreakpoint struct {
+	// DeviceName optionally limits the breakpoint to the Events of this Device
+	DeviceName string `json:"deviceName,omitempty"`
+	// ResourceName optionally limits the breakpoint to the Events with a Reading for this resource, which the
+	// Operator is applied to
+	ResourceName string `json:"resourceName,omitempty"`
+	// Operator optionally limits the breakpoint to the Events with a Reading whose value compares with Value by this
+	// operator, i.e. ReplayBreakpointEqual, ReplayBreakpointNotEqual, ReplayBreakpointGreater,
+	// ReplayBreakpointGreaterOrEqual, ReplayBreakpointLess or ReplayBreakpointLessOrEqual. Values are compared as
+	// numbers when both are numeric, otherwise only the equality operators match, comparing them as strings.
+	Operator string `json:"operator,omitempty"`
+	// Value is the value the Readings are compared with. Only used when Operator is set.
+	Value string `json:"value,omitempty"`
+	// Offset optionally limits the breakpoint to the Events at or after this duration from the first recorded Event,
+	// timed by the envelope receive times when the replay uses envelope timing. A breakpoint with only an Offset
+	// pauses once each time the replay reaches the offset.
+	Offset time.Duration `json:"offset,omitempty"`
+}
+
+// ReplaySink DTO specifies a destination the replayed Events are published to
+type ReplaySink struct {
+	// Name optionally identifies the sink in the replay status and log messages. Defaults to the sink's Type.
+	Name string `json:"name,omitempty"`
+	// Type is the type of the sink. Valid values are ReplaySinkMessageBus, ReplaySinkMQTT, ReplaySinkHTTP,
+	// ReplaySinkEdgeXMessageBus and ReplaySinkEdgeXCoreData.
+	Type string `json:"type"`
+	// Enabled indicates if the sink is published to. Optional, defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// OnPublishError is the policy applied when publishing to the sink fails. Optional, defaults to the request's
+	// OnPublishError. Retries use the request's retry options.
+	OnPublishError string `json:"onPublishError,omitempty"`
+
+	// BrokerAddress is the address of the MQTT broker, e.g. tcp://broker:1883. Required for MQTT and EdgeX
+	// MessageBus sinks.
+	BrokerAddress string `json:"brokerAddress,omitempty"`
+	// Topic is the MQTT topic the Events are published to. It may contain {servicename}, {devicename}, {profilename}
+	// and {sourcename} placeholders, which are replaced with the Event's values. Required for MQTT sinks.
+	Topic string `json:"topic,omitempty"`
+	// ClientId is the MQTT client id. Optional, defaults to app-record-replay- followed by the sink's name.
+	ClientId string `json:"clientId,omitempty"`
+	// QoS is the MQTT quality of service level. Optional, defaults to 0.
+	QoS byte `json:"qos,omitempty"`
+	// Retain sets the MQTT retain flag on the published Events
+	Retain bool `json:"retain,omitempty"`
+	// AuthMode is the MQTT authentication mode, one of none, usernamepassword, cacert or clientcert. Optional,
+	// defaults to none.
+	AuthMode string `json:"authMode,omitempty"`
+	// SecretName is the name of the secret holding the MQTT credentials. Required unless AuthMode is none. For
+	// EdgeX Core Data sinks it is the optional name of the secret whose authorization value, e.g. "Bearer <token>",
+	// is sent as the Authorization header.
+	SecretName string `json:"secretName,omitempty"`
+	// BaseTopicPrefix is the base topic prefix of the EdgeX instance of an EdgeX MessageBus sink. Optional,
+	// defaults to edgex.
+	BaseTopicPrefix string `json:"baseTopicPrefix,omitempty"`
+
+	// URL is the HTTP endpoint the Events are posted to. It may contain {servicename}, {devicename}, {profilename}
+	// and {sourcename} placeholders, which are replaced with the Event's values. Required for HTTP sinks. For EdgeX
+	// Core Data sinks it is the base URL of the Core Data service, e.g. http://lab-edgex:59880, and is required.
+	URL string `json:"url,omitempty"`
 }
 
 // ReplayStatus DTO contains the data describing the status of a replay session
 type ReplayStatus struct {
 	// Running indicates if the Replay is currently running or not
 	Running bool `json:"running"`
+	// Standby indicates if the Replay is primed and waiting to be triggered. See ReplayRequest.Standby.
+	Standby bool `json:"standby,omitempty"`
+	// Paused describes where the Replay is paused, if paused at a breakpoint or after a step. See
+	// ReplayRequest.Breakpoints.
+	Paused *ReplayPauseStatus `json:"paused,omitempty"`
 	// EventCount is the number of Events replayed
 	EventCount int `json:"eventCount"`
 	// Duration is the time the replay has been running or ran.
 	Duration time.Duration `json:"duration"`
 	// RepeatCount is the number of times the replay of the recorded data has been completed.
 	RepeatCount int `json:"repeatCount"`
+	// SkippedEventCount is the number of Events skipped because their device or resources no longer exist in
+	// Core Metadata. See the ReplayValidationPolicy App Setting.
+	SkippedEventCount int `json:"skippedEventCount"`
+	// DriftedProfiles is the list of names of the Device Profiles which differ materially from those the Events were
+	// captured with. See the ReplayProfileDriftPolicy App Setting.
+	DriftedProfiles []string `json:"driftedProfiles,omitempty"`
+	// DroppedEventCount is the number of lower priority Events dropped because a prioritized replay fell behind
+	// schedule. See ReplayRequest.DevicePriorities.
+	DroppedEventCount int `json:"droppedEventCount"`
+	// PublishRetryCount is the number of publish retries made after publish errors. See ReplayRequest.OnPublishError.
+	PublishRetryCount int `json:"publishRetryCount"`
+	// PublishFailedEventCount is the number of Events, or messages, skipped because they failed to publish.
+	// See ReplayRequest.OnPublishError.
+	PublishFailedEventCount int `json:"publishFailedEventCount"`
+	// ClampedReadingCount is the number of Reading values clamped to their resource's bounds. See
+	// ReplayRequest.ValueBounds.
+	ClampedReadingCount int `json:"clampedReadingCount,omitempty"`
+	// RejectedReadingCount is the number of Readings dropped because their values were outside their resource's
+	// bounds. See ReplayRequest.ValueBounds.
+	RejectedReadingCount int `json:"rejectedReadingCount,omitempty"`
+	// Label is the label of the replay session, if labeled
+	Label string `json:"label,omitempty"`
+	// CorrelationID is the correlation ID of the request which started the replay session, if known
+	CorrelationID string `json:"correlationId,omitempty"`
+	// Queue is the list of replay sessions waiting to start. See the MaxQueuedSessions App Setting.
+	Queue []QueuedSession `json:"queue,omitempty"`
+	// Sinks is the status of each sink of the replay, if the replay request sets its Sinks
+	Sinks []ReplaySinkStatus `json:"sinks,omitempty"`
+	// Iterations is the statistics of each completed iteration of the replay, up to the most recent 100, so drift
+	// across the iterations of a repeated replay can be analyzed
+	Iterations []ReplayIterationStatus `json:"iterations,omitempty"`
+	// SchedulingError summarizes how far from their scheduled time the Events were published, so the timing fidelity
+	// of the replay can be verified. Not set until an Event is published.
+	SchedulingError *ReplaySchedulingError `json:"schedulingError,omitempty"`
 	// Message, if set, contains the message describing the response.
 	Message string
 }
+
+// ReplayPauseStatus DTO describes the Event a paused replay session is waiting to publish
+type ReplayPauseStatus struct {
+	// EventIndex is the index in the recorded data of the Event published once the replay continues
+	EventIndex int `json:"eventIndex"`
+	// DeviceName is the name of the Device of the Event
+	DeviceName string `json:"deviceName"`
+	// Breakpoint is the index in the replay request's Breakpoints of the breakpoint the Event matched. Not set when
+	// Stepped.
+	Breakpoint int `json:"breakpoint"`
+	// Stepped indicates the replay paused after stepping over the previous Event rather than at a breakpoint
+	Stepped bool `json:"stepped,omitempty"`
+}
+
+// ReplayIterationStatus DTO contains the statistics of one completed iteration of a replay session
+type ReplayIterationStatus struct {
+	// Iteration is the number of the iteration, starting from 1
+	Iteration int `json:"iteration"`
+	// Duration is the actual time the iteration took
+	Duration time.Duration `json:"duration"`
+	// EventCount is the number of Events, or messages, replayed in the iteration
+	EventCount int `json:"eventCount"`
+	// SkippedEventCount is the number of Events skipped in the iteration by the ReplayValidationPolicy
+	SkippedEventCount int `json:"skippedEventCount"`
+	// PublishFailedEventCount is the number of Events, or messages, which failed to publish in the iteration
+	PublishFailedEventCount int `json:"publishFailedEventCount"`
+	// AverageLag is the average time the replayed Events were published after they were due at the replay rate
+	AverageLag time.Duration `json:"averageLag"`
+	// MaxLag is the longest time a replayed Event was published after it was due at the replay rate
+	MaxLag time.Duration `json:"maxLag"`
+}
+
+// ReplaySchedulingError DTO summarizes the scheduling errors of the Events, or messages, replayed. Each is the time
+// the Event was published less the time it was due at the replay rate, so a negative error is an Event published
+// early. The statistics other than Count are computed from a uniform sample of the errors.
+type ReplaySchedulingError struct {
+	// Count is the number of Events the scheduling error was measured for
+	Count int64 `json:"count"`
+	// Mean is the mean scheduling error
+	Mean time.Duration `json:"mean"`
+	// Min is the smallest scheduling error
+	Min time.Duration `json:"min"`
+	// Max is the largest scheduling error
+	Max time.Duration `json:"max"`
+	// P50 is the median scheduling error
+	P50 time.Duration `json:"p50"`
+	// P95 is the 95th percentile of the scheduling errors
+	P95 time.Duration `json:"p95"`
+	// P99 is the 99th percentile of the scheduling errors
+	P99 time.Duration `json:"p99"`
+}
+
+// ReplaySinkStatus DTO contains the status of a sink of a replay session
+type ReplaySinkStatus struct {
+	// Name is the name of the sink
+	Name string `json:"name"`
+	// Type is the type of the sink
+	Type string `json:"type"`
+	// Enabled indicates if the sink is published to
+	Enabled bool `json:"enabled"`
+	// PublishedEventCount is the number of Events published to the sink
+	PublishedEventCount int `json:"publishedEventCount"`
+	// FailedEventCount is the number of Events skipped for the sink because they failed to publish
+	FailedEventCount int `json:"failedEventCount"`
+}
+
+// ShadowReport DTO contains the comparison of the replayed data against the live data recorded during a shadow mode replay
+type ShadowReport struct {
+	// InProgress indicates if the shadow mode replay is still running, in which case the report is partial
+	InProgress bool `json:"inProgress"`
+	// ReplayedEventCount is the number of Events in the replayed data
+	ReplayedEventCount int `json:"replayedEventCount"`
+	// LiveEventCount is the number of live Events recorded while the replay was running
+	LiveEventCount int `json:"liveEventCount"`
+	// Resources is the per device resource comparison of the replayed and live Readings
+	Resources []ResourceComparison `json:"resources"`
+}
+
+// LatencyReport DTO contains the end-to-end latency of the Events replayed by a latency measurement replay, from
+// being published to being received back on the latency topic
+type LatencyReport struct {
+	// InProgress is true while the replay is running, including while waiting for the last Events to be received
+	InProgress bool `json:"inProgress"`
+	// Topic is the topic the Events were received back on
+	Topic string `json:"topic"`
+	// PublishedEventCount is the number of Events published by the replay
+	PublishedEventCount int64 `json:"publishedEventCount"`
+	// ReceivedEventCount is the number of the published Events received back, each counted once
+	ReceivedEventCount int64 `json:"receivedEventCount"`
+	// LostEventCount is the number of the published Events which weren't received back. Only set once finished.
+	LostEventCount int64 `json:"lostEventCount"`
+	// DuplicateEventCount is the number of times a published Event was received back again
+	DuplicateEventCount int64 `json:"duplicateEventCount"`
+	// Latency is the distribution of the latencies of the Events received. Not set until one has been received.
+	Latency *LatencyDistribution `json:"latency,omitempty"`
+}
+
+// LatencyDistribution DTO summarizes the end-to-end latencies of the Events received. The statistics other than
+// Count are computed from a uniform sample of the latencies.
+type LatencyDistribution struct {
+	// Count is the number of Events the latency was measured for
+	Count int64 `json:"count"`
+	// Mean is the mean latency
+	Mean time.Duration `json:"mean"`
+	// Min is the smallest latency
+	Min time.Duration `json:"min"`
+	// Max is the largest latency
+	Max time.Duration `json:"max"`
+	// P50 is the median latency
+	P50 time.Duration `json:"p50"`
+	// P90 is the 90th percentile of the latencies
+	P90 time.Duration `json:"p90"`
+	// P95 is the 95th percentile of the latencies
+	P95 time.Duration `json:"p95"`
+	// P99 is the 99th percentile of the latencies
+	P99 time.Duration `json:"p99"`
+}
+
+// ResourceComparison DTO contains the comparison of replayed and live Readings for a single device resource.
+// Value statistics only include Readings with numeric values.
+type ResourceComparison struct {
+	DeviceName           string        `json:"deviceName"`
+	ResourceName         string        `json:"resourceName"`
+	ReplayedReadingCount int           `json:"replayedReadingCount"`
+	LiveReadingCount     int           `json:"liveReadingCount"`
+	ReplayedMeanValue    float64       `json:"replayedMeanValue"`
+	LiveMeanValue        float64       `json:"liveMeanValue"`
+	MeanValueDelta       float64       `json:"meanValueDelta"`
+	ReplayedMeanInterval time.Duration `json:"replayedMeanInterval"`
+	LiveMeanInterval     time.Duration `json:"liveMeanInterval"`
+	MeanIntervalDelta    time.Duration `json:"meanIntervalDelta"`
+}
+
+// ReplaySeekRequest DTO contains the point an active replay session jumps to, which is either an offset or an
+// annotation
+type ReplaySeekRequest struct {
+	// Offset is the time offset from the first recorded Event to jump to, at the recorded rate
+	Offset time.Duration `json:"offset,omitempty"`
+	// Annotation is the label of the annotation to jump to. See Annotation.
+	Annotation string `json:"annotation,omitempty"`
+}
+
+// ReplaySeekResponse DTO describes where the replay session jumps to
+type ReplaySeekResponse struct {
+	// EventIndex is the index of the recorded Event the replay continues from
+	EventIndex int `json:"eventIndex"`
+	// Offset is the time offset of that Event from the first recorded Event
+	Offset time.Duration `json:"offset"`
+}
@@ -0,0 +1,54 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+import "time"
+
+// SelfTestRequest DTO specifies the synthetic Events of a self-test, which records and replays them through an
+// internal loopback pipeline to verify the round-trip fidelity of a deployment without touching the MessageBus or
+// the recorded data
+type SelfTestRequest struct {
+	// EventCount is the optional number of synthetic Events to generate. Defaults to 100, with a maximum of 10000.
+	EventCount int `json:"eventCount,omitempty"`
+	// Interval is the optional time between the Origins of the synthetic Events, which the replay reproduces.
+	// Defaults to 10ms.
+	Interval time.Duration `json:"interval,omitempty"`
+	// TimingTolerance, if set, fails the self-test when a replayed Event is published further than this from its
+	// scheduled time relative to the first Event
+	TimingTolerance time.Duration `json:"timingTolerance,omitempty"`
+}
+
+// SelfTestReport DTO contains the result of a self-test
+type SelfTestReport struct {
+	// Passed indicates if every generated Event was recorded and replayed unchanged and in order
+	Passed bool `json:"passed"`
+	// EventCount is the number of synthetic Events generated
+	EventCount int `json:"eventCount"`
+	// RecordedEventCount is the number of Events in the loopback recording
+	RecordedEventCount int `json:"recordedEventCount"`
+	// ReplayedEventCount is the number of Events published by the loopback replay
+	ReplayedEventCount int `json:"replayedEventCount"`
+	// MaxTimingError is the largest difference between when a replayed Event was published and when it was scheduled
+	// relative to the first Event
+	MaxTimingError time.Duration `json:"maxTimingError"`
+	// Duration is the time the self-test took
+	Duration time.Duration `json:"duration"`
+	// Mismatches describes the first differences found between the generated and replayed Events
+	Mismatches []string `json:"mismatches,omitempty"`
+	// Message, if set, describes why the self-test didn't complete
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,35 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// Kinds of sessions waiting in the session queue
+const (
+	SessionKindRecord = "record"
+	SessionKindReplay = "replay"
+)
+
+// QueuedSession DTO describes a record or replay session waiting in the session queue for the running session to end
+type QueuedSession struct {
+	// Position is the position of the session in the queue, starting at 1 for the next session to start
+	Position int `json:"position"`
+	// Kind is the kind of session, either record or replay
+	Kind string `json:"kind"`
+	// Label is the label of the session, if labeled
+	Label string `json:"label,omitempty"`
+	// QueuedAt is the time the session was queued in nanoseconds since the epoch
+	QueuedAt int64 `json:"queuedAt"`
+}
@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// StoreStats DTO contains the memory and storage statistics of the recording store, including how much of the
+// space allocated is unused and can be reclaimed by compacting the store
+type StoreStats struct {
+	// Memory contains the statistics of the recorded data held in memory
+	Memory MemoryStoreStats `json:"memory"`
+	// Segments contains the statistics of the segment store continuous recordings rotate their segments into, if
+	// the SegmentStoreDir App Setting is set
+	Segments *SegmentStoreStats `json:"segments,omitempty"`
+}
+
+// MemoryStoreStats DTO contains the statistics of the recorded data held in memory. Sizes are those of the
+// allocated arrays holding the recorded data and exclude the string and payload contents they reference.
+type MemoryStoreStats struct {
+	// EventCount is the count of recorded Events
+	EventCount int `json:"eventCount"`
+	// ReadingCount is the count of recorded Readings
+	ReadingCount int `json:"readingCount"`
+	// ResourceColumnCount is the count of per device resource columns the simple Readings are held in
+	ResourceColumnCount int `json:"resourceColumnCount"`
+	// WholeReadingCount is the count of Readings held whole, since they don't fit the resource columns
+	WholeReadingCount int `json:"wholeReadingCount"`
+	// InternedStringCount is the count of distinct names shared by the Events and Readings
+	InternedStringCount int `json:"internedStringCount"`
+	// MessageCount is the count of recorded opaque messages, including those captured by a recording in progress
+	MessageCount int `json:"messageCount"`
+	// UsedBytes is the size in bytes of the allocated space holding recorded data
+	UsedBytes int64 `json:"usedBytes"`
+	// AllocatedBytes is the size in bytes of the allocated space
+	AllocatedBytes int64 `json:"allocatedBytes"`
+	// Fragmentation is the fraction of the allocated space which is unused, from 0 to 1
+	Fragmentation float64 `json:"fragmentation"`
+}
+
+// SegmentStoreStats DTO contains the statistics of the segment store
+type SegmentStoreStats struct {
+	// RecordingCount is the count of recordings with a directory in the segment store
+	RecordingCount int `json:"recordingCount"`
+	// SegmentCount is the count of segments in the segment store
+	SegmentCount int `json:"segmentCount"`
+	// SegmentBytes is the total size in bytes of the segments
+	SegmentBytes int64 `json:"segmentBytes"`
+	// TempFileCount is the count of partially written segments left behind, i.e. by the service exiting during a
+	// rotation
+	TempFileCount int `json:"tempFileCount"`
+	// TempFileBytes is the total size in bytes of the partially written segments
+	TempFileBytes int64 `json:"tempFileBytes"`
+	// EmptyDirCount is the count of recording directories with no segments left, i.e. after retention deleted them
+	EmptyDirCount int `json:"emptyDirCount"`
+}
+
+// CompactResult DTO describes the space reclaimed by compacting the recording store
+type CompactResult struct {
+	// ReclaimedMemoryBytes is the size in bytes of the unused allocated memory which was released
+	ReclaimedMemoryBytes int64 `json:"reclaimedMemoryBytes"`
+	// ReclaimedStorageBytes is the size in bytes of the files deleted from the segment store
+	ReclaimedStorageBytes int64 `json:"reclaimedStorageBytes"`
+	// RemovedFileCount is the count of partially written segments deleted from the segment store
+	RemovedFileCount int `json:"removedFileCount"`
+	// RemovedDirCount is the count of empty recording directories deleted from the segment store
+	RemovedDirCount int `json:"removedDirCount"`
+	// Stats is the statistics of the recording store once compacted
+	Stats StoreStats `json:"stats"`
+}
@@ -0,0 +1,31 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// RecordingChunk DTO is a chunk of a completed recording published to the MessageBus for cloud sync. See the
+// CloudSyncTopic App Setting. A recording is reassembled by appending the RecordedEvents, or Messages, of its chunks
+// in Index order and merging their Envelopes. The other recorded data fields are only set in the first chunk.
+type RecordingChunk struct {
+	// RecordingId identifies the recording the chunk belongs to, the same for all the chunks of a recording
+	RecordingId string `json:"recordingId"`
+	// Index is the zero based position of the chunk in the recording
+	Index int `json:"index"`
+	// Count is the number of chunks the recording was published in
+	Count int `json:"count"`
+	// RecordedData is the chunk's share of the recorded data
+	RecordedData RecordedData `json:"recordedData"`
+}
@@ -0,0 +1,47 @@
+//
+// Copyright (c) 2023 Intel Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package dtos
+
+// ValidationReport DTO contains the result of validating the recorded Events against the EdgeX contract validation
+// rules and the recorded Device Profiles
+type ValidationReport struct {
+	// Valid indicates if all the Events passed validation
+	Valid bool `json:"valid"`
+	// EventCount is the number of Events validated
+	EventCount int `json:"eventCount"`
+	// InvalidEventCount is the number of Events which failed validation
+	InvalidEventCount int `json:"invalidEventCount"`
+	// ProfilesChecked indicates if the Events were checked against the recorded Device Profiles, which is only done
+	// when the recorded data includes them
+	ProfilesChecked bool `json:"profilesChecked"`
+	// InvalidEvents lists the Events which failed validation, in recorded order, up to the first 1000
+	InvalidEvents []EventValidation `json:"invalidEvents,omitempty"`
+	// Truncated indicates if more Events failed validation than are listed
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// EventValidation DTO contains the validation errors of a single recorded Event
+type EventValidation struct {
+	// Index is the position of the Event in the recorded data, starting from 0
+	Index int `json:"index"`
+	// Id is the Event's Id, which isn't set for readings-only recordings
+	Id string `json:"id,omitempty"`
+	// DeviceName is the name of the Event's device
+	DeviceName string `json:"deviceName"`
+	// Errors lists each validation error found for the Event
+	Errors []string `json:"errors"`
+}